@@ -71,6 +71,16 @@ type KubeletOptions struct {
 	// Flags are [command-line `kubelet`` arguments](https://kubernetes.io/docs/reference/command-line-tools-reference/kubelet/).
 	// that will be appended to the defaults.
 	Flags []string `json:"flags,omitempty"`
+
+	// ImageServiceEndpoint is the CRI image service endpoint kubelet pulls images through,
+	// for runtimes that serve it separately from the container runtime endpoint.
+	// Defaults to the container runtime endpoint.
+	ImageServiceEndpoint string `json:"imageServiceEndpoint,omitempty"`
+
+	// NodeIPInterface is the network interface, like eth1 or ens192, whose IP is used as the node IP
+	// on hybrid nodes with several interfaces. It takes precedence over resolving the node name and the
+	// default route, and a `--node-ip` flag must be one of its IPs.
+	NodeIPInterface string `json:"nodeIPInterface,omitempty"`
 }
 
 // ContainerdOptions are additional parameters passed to `containerd`.
@@ -79,6 +89,13 @@ type ContainerdOptions struct {
 	// that will be [imported](https://github.com/containerd/containerd/blob/32169d591dbc6133ef7411329b29d0c0433f8c4d/docs/man/containerd-config.toml.5.md?plain=1#L146-L154)
 	// by the default configuration file.
 	Config string `json:"config,omitempty"`
+	// MaxConcurrentDownloads is the maximum number of image layers containerd downloads at the same time
+	// for each pull. Lowering it helps pulls over slow links. Defaults to the containerd default of 3.
+	MaxConcurrentDownloads int32 `json:"maxConcurrentDownloads,omitempty"`
+	// ImagePullProgressTimeout is how long containerd waits for a registry to make progress on a pull
+	// before canceling it, as a duration like 15m. Raising it helps pulls over slow links.
+	// Defaults to the containerd default of 5m.
+	ImagePullProgressTimeout string `json:"imagePullProgressTimeout,omitempty"`
 }
 
 // InstanceOptions determines how the node's operating system and devices are configured.
@@ -157,6 +174,12 @@ type IAMRolesAnywhere struct {
 	// PrivateKeyPath is the location on disk for the certificate's private key.
 	// +optional
 	PrivateKeyPath string `json:"privateKeyPath,omitempty"`
+
+	// SessionDuration is how long, in seconds, the credentials written to the shared credentials file
+	// are valid for. The aws_signing_helper_update service refreshes them before they expire.
+	// Must be between 900 and 43200. Defaults to 3600.
+	// +optional
+	SessionDuration int32 `json:"sessionDuration,omitempty"`
 }
 
 // SSM defines Systems Manager specific configuration.
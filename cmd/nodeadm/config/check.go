@@ -1,24 +1,36 @@
 package config
 
 import (
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
+	"sigs.k8s.io/yaml"
 
+	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/configprovider"
 	"github.com/aws/eks-hybrid/internal/node"
 )
 
 type fileCmd struct {
-	cmd          *flaggy.Subcommand
-	configSource string
+	cmd           *flaggy.Subcommand
+	configSource  string
+	configOverlay string
+	print         bool
+	showSecrets   bool
 }
 
 func NewCheckCommand() cli.Command {
 	file := fileCmd{}
 	file.cmd = flaggy.NewSubcommand("check")
 	file.cmd.Description = "Verify configuration"
-	file.cmd.String(&file.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
+	file.cmd.String(&file.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	file.cmd.String(&file.configOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
+	file.cmd.Bool(&file.print, "", "print", "Print the resolved configuration, after merging the overlay, as YAML. Credentials like the SSM activation code and IAM Roles Anywhere ARNs are redacted.")
+	file.cmd.Bool(&file.showSecrets, "", "show-secrets", "Don't redact credentials when printing the configuration with --print.")
 	return &file
 }
 
@@ -27,17 +39,17 @@ func (c *fileCmd) Flaggy() *flaggy.Subcommand {
 }
 
 func (c *fileCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
-	log.Info("Checking configuration", zap.String("source", c.configSource))
-	provider, err := configprovider.BuildConfigProvider(c.configSource)
+	log.Info("Checking configuration", zap.String("source", c.configSource), zap.String("overlay", c.configOverlay))
+	provider, err := configprovider.BuildConfigProviderWithOverlay(c.configSource, c.configOverlay)
 	if err != nil {
 		return err
 	}
-	_, err = provider.Provide()
+	nodeConfig, err := provider.Provide()
 	if err != nil {
 		return err
 	}
 
-	nodeProvider, err := node.NewNodeProvider(c.configSource, []string{}, log)
+	nodeProvider, err := node.NewNodeProviderForConfig(nodeConfig, []string{}, log)
 	if err != nil {
 		return err
 	}
@@ -47,5 +59,21 @@ func (c *fileCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 
 	log.Info("Configuration is valid")
+	if c.print {
+		return printConfig(os.Stdout, nodeConfig, c.showSecrets)
+	}
 	return nil
 }
+
+// printConfig writes the node config as YAML, with its credentials redacted unless showSecrets is true.
+func printConfig(w io.Writer, nodeConfig *api.NodeConfig, showSecrets bool) error {
+	if !showSecrets {
+		nodeConfig = nodeConfig.Redacted()
+	}
+	data, err := yaml.Marshal(nodeConfig)
+	if err != nil {
+		return fmt.Errorf("marshalling node config: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
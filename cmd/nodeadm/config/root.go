@@ -7,6 +7,12 @@ import (
 const configHelpText = `Examples:
   # Check configuration file
   nodeadm config check --config-source file:///root/nodeConfig.yaml
+
+  # Check configuration file and print the resolved configuration with credentials redacted
+  nodeadm config check --config-source file:///root/nodeConfig.yaml --print
+
+  # Validate configuration file offline, reporting every problem found, before shipping it to the node
+  nodeadm config validate --config-source file:///root/nodeConfig.yaml
   
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_config_check`
@@ -15,5 +21,6 @@ func NewConfigCommand() cli.Command {
 	container := cli.NewCommandContainer("config", "Manage configuration")
 	container.Flaggy().AdditionalHelpAppend = configHelpText
 	container.AddCommand(NewCheckCommand())
+	container.AddCommand(NewValidateCommand())
 	return container.AsCommand()
 }
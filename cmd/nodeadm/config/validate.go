@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/configprovider"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+)
+
+type validateCmd struct {
+	cmd           *flaggy.Subcommand
+	configSource  string
+	configOverlay string
+}
+
+func NewValidateCommand() cli.Command {
+	validate := validateCmd{}
+	validate.cmd = flaggy.NewSubcommand("validate")
+	validate.cmd.Description = "Validate the configuration schema offline, without access to the node files or the cluster"
+	validate.cmd.String(&validate.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, stdin]. Use - to read from standard input.")
+	validate.cmd.String(&validate.configOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
+	return &validate
+}
+
+func (c *validateCmd) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *validateCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	log.Info("Validating configuration", zap.String("source", c.configSource), zap.String("overlay", c.configOverlay))
+	for _, source := range []string{c.configSource, c.configOverlay} {
+		if isIMDSSource(source) {
+			return fmt.Errorf("config source %s reads from the instance metadata service and can't be validated offline", source)
+		}
+	}
+	provider, err := configprovider.BuildConfigProviderWithOverlay(c.configSource, c.configOverlay)
+	if err != nil {
+		return err
+	}
+	nodeConfig, err := provider.Provide()
+	if err != nil {
+		return err
+	}
+
+	if err := validateConfig(os.Stdout, nodeConfig); err != nil {
+		return err
+	}
+
+	log.Info("Configuration is valid")
+	return nil
+}
+
+// validateConfig runs the static validations of the node config and writes every problem found to w.
+func validateConfig(w io.Writer, nodeConfig *api.NodeConfig) error {
+	if !nodeConfig.IsHybridNode() {
+		return fmt.Errorf("offline validation is only supported for hybrid node configurations")
+	}
+	hybrid.PopulateNodeConfigDefaults(nodeConfig)
+
+	err := hybrid.ValidateConfigFields(nodeConfig)
+	if err == nil {
+		return nil
+	}
+	problems := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		problems = joined.Unwrap()
+	}
+	for _, problem := range problems {
+		if _, err := fmt.Fprintf(w, "- %s\n", problem); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("found %d problem(s) in configuration", len(problems))
+}
+
+func isIMDSSource(source string) bool {
+	parsedURL, err := url.Parse(source)
+	return err == nil && parsedURL.Scheme == "imds"
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/configprovider"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name: "valid iam roles anywhere config with files not on this host",
+			config: `
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+    region: us-west-2
+    cidr: 10.100.0.0/16
+  hybrid:
+    iamRolesAnywhere:
+      nodeName: node-01
+      trustAnchorArn: arn:aws:rolesanywhere:us-west-2:123456789010:trust-anchor/anchor
+      profileArn: arn:aws:rolesanywhere:us-west-2:123456789010:profile/profile
+      roleArn: arn:aws:iam::123456789010:role/hybrid-node
+      certificatePath: /does/not/exist/server.pem
+`,
+		},
+		{
+			name: "every problem is reported",
+			config: `
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    cidr: 10.100.0.0/33
+  kubelet:
+    flags:
+      - --hostname-override=node-01
+      - --cloud-provider=aws
+      - --node-ip=10.0.0.300
+  hybrid:
+    ssm:
+      activationCode: too-short
+`,
+			wantOutput: `- Name is missing in cluster configuration
+- Region is missing in cluster configuration
+- invalid CIDR 10.100.0.0/33 in cluster configuration: invalid CIDR address: 10.100.0.0/33
+- hostname-override kubelet flag is not supported for hybrid nodes but found override: node-01
+- cloud-provider kubelet flag must be unset or external for hybrid nodes but found aws
+- invalid ip 10.0.0.300 in --node-ip flag. only 1 IPv4 and 1 IPv6 address are allowed
+- invalid ActivationCode format: too-short. Must be 20-250 characters
+- ActivationID is missing in hybrid ssm configuration
+`,
+			wantErr: "found 8 problem(s) in configuration",
+		},
+		{
+			name: "both credential providers",
+			config: `
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+    region: us-west-2
+  hybrid:
+    ssm:
+      activationCode: ABCDEFGHIJKLMNOPQRSTUVWXYZ
+      activationId: 1c2e3f4a-5b6c-7d8e-9f0a-1b2c3d4e5f6a
+    iamRolesAnywhere:
+      nodeName: node-01
+`,
+			wantOutput: `- Only one of IAMRolesAnywhere or SSM must be provided for hybrid node configuration
+- RoleARN is missing in hybrid iam roles anywhere configuration
+- ProfileARN is missing in hybrid iam roles anywhere configuration
+- TrustAnchorARN is missing in hybrid iam roles anywhere configuration
+`,
+			wantErr: "found 4 problem(s) in configuration",
+		},
+		{
+			name: "no credential provider",
+			config: `
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+    region: us-west-2
+  hybrid: {}
+`,
+			wantOutput: "- Either IAMRolesAnywhere or SSM must be provided for hybrid node configuration\n",
+			wantErr:    "found 1 problem(s) in configuration",
+		},
+		{
+			name: "not a hybrid node config",
+			config: `
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+`,
+			wantErr: "offline validation is only supported for hybrid node configurations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			g.Expect(os.WriteFile(path, []byte(tt.config), 0o644)).To(Succeed())
+			provider, err := configprovider.BuildConfigProvider("file://" + path)
+			g.Expect(err).NotTo(HaveOccurred())
+			nodeConfig, err := provider.Provide()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			var out bytes.Buffer
+			err = validateConfig(&out, nodeConfig)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tt.wantErr))
+			}
+			g.Expect(out.String()).To(Equal(tt.wantOutput))
+		})
+	}
+}
+
+func TestValidateCommandRejectsIMDSSource(t *testing.T) {
+	g := NewWithT(t)
+	cmd := &validateCmd{configSource: "imds://user-data"}
+	g.Expect(cmd.Run(zap.NewNop(), &cli.GlobalOptions{})).To(MatchError(ContainSubstring("can't be validated offline")))
+}
@@ -0,0 +1,343 @@
+package debug
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	apibridge "github.com/aws/eks-hybrid/internal/api/bridge"
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/nodevalidator"
+	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const (
+	defaultBundleLogsSince = 24 * time.Hour
+	// maxBundleFileSize bounds the size of the config files added to the bundle, larger files are skipped.
+	maxBundleFileSize  = 10 * 1024 * 1024
+	bundleManifestName = "manifest.json"
+)
+
+// bundleLogServices returns the systemd units whose logs are added to the bundle. The SSM agent
+// unit depends on the host OS, so it's only known once the bundle is collected.
+func bundleLogServices() []string {
+	return []string{kubelet.KubeletDaemonName, containerd.ContainerdDaemonName, ssm.DaemonName()}
+}
+
+// secretKey matches the names of keys and flags that hold secrets.
+const secretKey = `[\w.-]*(?:token|secret|password|passwd|activation-?code|key-data|private-?key)[\w.-]*`
+
+var (
+	// quotedSecretRegex matches quoted secret values anywhere in a line, like in JSON, capturing the key and separator.
+	quotedSecretRegex = regexp.MustCompile(`(?i)("?` + secretKey + `"?\s*[:=]\s*)"[^"]*"`)
+	// secretLineRegex matches YAML and INI lines that set an unquoted secret, capturing the key and separator.
+	secretLineRegex = regexp.MustCompile(`(?i)^(\s*-?\s*` + secretKey + `\s*[:=]\s*)[^"\s].*$`)
+	// secretFlagRegex matches command line flags that set a secret, like --token=abc.
+	secretFlagRegex = regexp.MustCompile(`(?i)(--[\w-]*(?:token|secret|password)[\w-]*[= ])[^"\s]+`)
+	// privateKeyRegex matches PEM encoded private keys.
+	privateKeyRegex = regexp.MustCompile(`(?s)-----BEGIN ([A-Z ]*)PRIVATE KEY-----.*?-----END ([A-Z ]*)PRIVATE KEY-----`)
+)
+
+const bundleHelpText = `Examples:
+  # Collect the node diagnostics to attach to a support case or GitHub issue
+  nodeadm debug bundle
+
+  # Collect the logs of the last 2 hours to a specific file
+  nodeadm debug bundle --since 2h --file /tmp/nodeadm-bundle.tar.gz
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
+
+func newBundleCommand() *bundleCommand {
+	cmd := bundleCommand{
+		since:     defaultBundleLogsSince,
+		collector: newBundleCollector(),
+	}
+	cmd.cmd = flaggy.NewSubcommand("bundle")
+	cmd.cmd.String(&cmd.file, "", "file", "Path of the tar.gz bundle to write. Defaults to nodeadm-bundle-<timestamp>.tar.gz in the current directory.")
+	cmd.cmd.Duration(&cmd.since, "", "since", "Collect the logs written in this window before now. Example: 2h")
+	cmd.cmd.Description = "Collect the node logs, configs and detected CNI into a tar.gz to attach to support cases, with secrets redacted"
+	cmd.cmd.AdditionalHelpPrepend = bundleHelpText
+	return &cmd
+}
+
+type bundleCommand struct {
+	cmd       *flaggy.Subcommand
+	file      string
+	since     time.Duration
+	collector *bundleCollector
+}
+
+func (c *bundleCommand) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *bundleCommand) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	if c.since <= 0 {
+		return fmt.Errorf("--since must be greater than 0, got %s", c.since)
+	}
+	path := c.file
+	if path == "" {
+		path = fmt.Sprintf("nodeadm-bundle-%s.tar.gz", c.collector.now().UTC().Format("20060102T150405Z"))
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating bundle: %w", err)
+	}
+	manifest, err := c.collector.write(context.Background(), file, c.since)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+	for _, collectErr := range manifest.Errors {
+		log.Warn("Failed to collect diagnostics", zap.String("error", collectErr))
+	}
+	log.Info("Wrote diagnostics bundle", zap.String("path", path), zap.Int("files", len(manifest.Files)))
+	return nil
+}
+
+// bundleManifest describes the contents of the bundle. Collection is best effort, so what couldn't be
+// collected is listed in Errors instead of failing the bundle.
+type bundleManifest struct {
+	CreatedAt time.Time    `json:"createdAt"`
+	CNI       string       `json:"cni"`
+	Files     []bundleFile `json:"files"`
+	Errors    []string     `json:"errors"`
+}
+
+type bundleFile struct {
+	// Name is the path of the file in the bundle.
+	Name string `json:"name"`
+	// Source is the file, unit or directory it was collected from.
+	Source   string `json:"source"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
+
+// bundleCollector collects the diagnostics of the node.
+type bundleCollector struct {
+	journal     journalReader
+	trackerFile string
+	// configDirs are added to the bundle with their secrets redacted.
+	configDirs []string
+	cniConfDir string
+	detectCNI  func() (string, error)
+	now        func() time.Time
+}
+
+func newBundleCollector() *bundleCollector {
+	return &bundleCollector{
+		journal:     journalctl{},
+		trackerFile: tracker.File(),
+		configDirs:  []string{"/etc/kubernetes", "/etc/eks"},
+		cniConfDir:  "/etc/cni/net.d",
+		detectCNI:   nodevalidator.DetectLocalCNI,
+		now:         time.Now,
+	}
+}
+
+// write collects the diagnostics into w as a tar.gz, with the manifest as its last entry.
+func (c *bundleCollector) write(ctx context.Context, w io.Writer, since time.Duration) (bundleManifest, error) {
+	manifest := bundleManifest{CreatedAt: c.now().UTC(), Files: []bundleFile{}, Errors: []string{}}
+	gzipWriter := gzip.NewWriter(w)
+	bundle := &bundleWriter{tar: tar.NewWriter(gzipWriter), manifest: &manifest, modTime: manifest.CreatedAt}
+
+	for _, unit := range bundleLogServices() {
+		logs, err := c.readLogs(ctx, unit, since)
+		if err != nil {
+			manifest.recordError(err)
+			continue
+		}
+		if err := bundle.add(bundleFile{Name: "logs/" + unit + ".log", Source: unit}, logs); err != nil {
+			return manifest, err
+		}
+	}
+
+	if err := c.addFile(bundle, c.trackerFile); err != nil {
+		return manifest, err
+	}
+	for _, dir := range c.configDirs {
+		if err := c.addDir(bundle, dir); err != nil {
+			return manifest, err
+		}
+	}
+
+	listing, err := listDir(c.cniConfDir)
+	if err != nil {
+		manifest.recordError(err)
+	} else if err := bundle.add(bundleFile{Name: "cni/net.d.txt", Source: c.cniConfDir}, listing); err != nil {
+		return manifest, err
+	}
+
+	cni, err := c.detectCNI()
+	if err != nil {
+		manifest.recordError(fmt.Errorf("detecting CNI: %w", err))
+	}
+	manifest.CNI = cni
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := bundle.write(bundleManifestName, append(data, '\n')); err != nil {
+		return manifest, err
+	}
+	if err := bundle.tar.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, gzipWriter.Close()
+}
+
+func (c *bundleCollector) readLogs(ctx context.Context, unit string, since time.Duration) ([]byte, error) {
+	logs, err := c.journal.Read(ctx, journalQuery{unit: unit, since: since})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s logs: %w", unit, err)
+	}
+	data, err := io.ReadAll(logs)
+	if closeErr := logs.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s logs: %w", unit, err)
+	}
+	return data, nil
+}
+
+// addDir adds the regular files under dir with their secrets redacted. A missing dir is recorded as an error.
+func (c *bundleCollector) addDir(bundle *bundleWriter, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			bundle.manifest.recordError(err)
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		return c.addFile(bundle, path)
+	})
+}
+
+// addFile adds the file with its secrets redacted. Files that can't be read are recorded as errors.
+func (c *bundleCollector) addFile(bundle *bundleWriter, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		bundle.manifest.recordError(err)
+		return nil
+	}
+	if info.Size() > maxBundleFileSize {
+		bundle.manifest.recordError(fmt.Errorf("skipping %s, it is larger than %d bytes", path, maxBundleFileSize))
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		bundle.manifest.recordError(err)
+		return nil
+	}
+	redactedData, redacted := redactFile(data)
+	return bundle.add(bundleFile{Name: "files" + filepath.ToSlash(path), Source: path, Redacted: redacted}, redactedData)
+}
+
+func (m *bundleManifest) recordError(err error) {
+	m.Errors = append(m.Errors, err.Error())
+}
+
+// bundleWriter writes files to the bundle and records them in the manifest.
+type bundleWriter struct {
+	tar      *tar.Writer
+	manifest *bundleManifest
+	modTime  time.Time
+}
+
+func (b *bundleWriter) add(file bundleFile, data []byte) error {
+	if err := b.write(file.Name, data); err != nil {
+		return err
+	}
+	b.manifest.Files = append(b.manifest.Files, file)
+	return nil
+}
+
+func (b *bundleWriter) write(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: b.modTime,
+	}
+	if err := b.tar.WriteHeader(header); err != nil {
+		return fmt.Errorf("adding %s: %w", name, err)
+	}
+	if _, err := b.tar.Write(data); err != nil {
+		return fmt.Errorf("adding %s: %w", name, err)
+	}
+	return nil
+}
+
+// listDir lists the entries of dir with their mode and size, like ls -l.
+func listDir(dir string) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var listing bytes.Buffer
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&listing, "%s %10d %s %s\n", info.Mode(), info.Size(), info.ModTime().UTC().Format(time.RFC3339), entry.Name())
+	}
+	return listing.Bytes(), nil
+}
+
+// redactFile redacts the secrets of a collected file. Node configs are redacted like
+// NodeConfig.Redacted does and written back as v1alpha1, on top of the secrets redactSecrets
+// masks in any file.
+func redactFile(data []byte) ([]byte, bool) {
+	nodeConfig, err := apibridge.DecodeNodeConfig(data)
+	if err != nil {
+		return redactSecrets(data)
+	}
+	redactedConfig, err := apibridge.EncodeNodeConfig(nodeConfig.Redacted())
+	if err != nil {
+		return redactSecrets(data)
+	}
+	redacted, _ := redactSecrets(redactedConfig)
+	return redacted, true
+}
+
+// redactSecrets replaces private keys, secret values and secret flags in data. It returns true
+// if anything was redacted.
+func redactSecrets(data []byte) ([]byte, bool) {
+	redacted := privateKeyRegex.ReplaceAll(data, []byte("-----BEGIN ${1}PRIVATE KEY-----\n"+api.RedactedValue+"\n-----END ${2}PRIVATE KEY-----"))
+	lines := strings.Split(string(redacted), "\n")
+	for i, line := range lines {
+		line = quotedSecretRegex.ReplaceAllString(line, `${1}"`+api.RedactedValue+`"`)
+		line = secretLineRegex.ReplaceAllString(line, "${1}"+api.RedactedValue)
+		lines[i] = secretFlagRegex.ReplaceAllString(line, "${1}"+api.RedactedValue)
+	}
+	redacted = []byte(strings.Join(lines, "\n"))
+	return redacted, !bytes.Equal(redacted, data)
+}
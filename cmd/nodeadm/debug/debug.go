@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/smithy-go/logging"
@@ -16,13 +18,17 @@ import (
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/configprovider"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/errors"
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/kubernetes"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/network"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
 	"github.com/aws/eks-hybrid/internal/nodevalidator"
 	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/tracker"
 	"github.com/aws/eks-hybrid/internal/validation"
 )
 
@@ -30,23 +36,91 @@ const debugHelpText = `Examples:
   # Debug using a local config file
   nodeadm debug --config-source file://nodeConfig.yaml
 
+  # Debug validating the node with a specific kubeconfig context
+  nodeadm debug --config-source file://nodeConfig.yaml --kubeconfig ~/.kube/config --context my-cluster
+
+  # Regenerate a corrupted or stale kubelet kubeconfig and restart kubelet
+  nodeadm debug regenerate-kubeconfig --config-source file://nodeConfig.yaml
+
+  # Apply and persist the sysctls required by the CNI running on the node
+  nodeadm debug --config-source file://nodeConfig.yaml --fix
+
+  # Debug a node whose datacenter network runs overlays at a 1400 MTU
+  nodeadm debug --config-source file://nodeConfig.yaml --mtu-range 1400-1500
+
+  # Debug a node of a site with jumbo frames, other sites keep the default MTU ranges
+  nodeadm debug --config-source file://nodeConfig.yaml --network-mtu-range 10.80.0.0/16=9000-9001
+
+  # Print the detected system facts as JSON for a support bundle
+  nodeadm debug facts
+
+  # Print the AWS identity the node credential provider assumes
+  nodeadm debug whoami --config-source file://nodeConfig.yaml
+
+  # Collect the node logs and configs, with secrets redacted, into a tar.gz for a support case
+  nodeadm debug bundle
+
+  # Follow the kubelet logs, starting with the last 10 minutes
+  nodeadm debug logs --service kubelet --follow
+
+  # Periodically run the node health checks and serve their status as JSON on port 10280
+  nodeadm debug watch --config-source file://nodeConfig.yaml --listen :10280
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
 
 func NewCommand() cli.Command {
-	debug := debug{}
+	debug := debug{
+		readinessStabilityPeriod: nodevalidator.RecommendedReadinessStabilityPeriod,
+	}
 	debug.cmd = flaggy.NewSubcommand("debug")
-	debug.cmd.String(&debug.nodeConfigSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
+	debug.cmd.String(&debug.nodeConfigSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	debug.cmd.String(&debug.nodeConfigOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
 	debug.cmd.Bool(&debug.noColor, "", "no-color", "If set, suppresses color output.")
+	debug.cmd.String(&debug.kubeconfig, "", "kubeconfig", "Path to the kubeconfig used to validate the node in the cluster. Defaults to the kubelet kubeconfig.")
+	debug.cmd.String(&debug.kubeconfigContext, "", "context", "Kubeconfig context used to validate the node in the cluster. Defaults to the kubeconfig current context.")
+	debug.cmd.Bool(&debug.fix, "", "fix", "Apply the sysctls required by the detected CNI and persist them under /etc/sysctl.d.")
+	debug.cmd.StringSlice(&debug.mtuRanges, "", "mtu-range", "Acceptable MTU range, in min-max form, for the network interface of the node IP. Can be repeated. Defaults to 68-1500 and 8000-9001.")
+	debug.cmd.StringSlice(&debug.networkMTURanges, "", "network-mtu-range", "Acceptable MTU range for the node IPs in a remote node network, in cidr=min-max form. Overrides --mtu-range for that network. Can be repeated.")
+	debug.cmd.Bool(&debug.skipMTUValidation, "", "skip-mtu-validation", "Skip the MTU checks of the network interface validation, keeping the rest of it.")
+	debug.cmd.Duration(&debug.readinessStabilityPeriod, "", "readiness-stability-period", "How long the node needs to stay ready without flapping back to NotReady for the node validation to pass. Use 0 to accept the first Ready observation.")
 	debug.cmd.Description = "Debug the node registration process"
 	debug.cmd.AdditionalHelpPrepend = debugHelpText
+	debug.regenerateKubeconfig = newRegenerateKubeconfigCommand()
+	debug.cmd.AttachSubcommand(debug.regenerateKubeconfig.Flaggy(), 1)
+	debug.facts = newFactsCommand()
+	debug.cmd.AttachSubcommand(debug.facts.Flaggy(), 1)
+	debug.logs = newLogsCommand()
+	debug.cmd.AttachSubcommand(debug.logs.Flaggy(), 1)
+	debug.watch = newWatchCommand()
+	debug.cmd.AttachSubcommand(debug.watch.Flaggy(), 1)
+	debug.whoami = newWhoamiCommand()
+	debug.cmd.AttachSubcommand(debug.whoami.Flaggy(), 1)
+	debug.bundle = newBundleCommand()
+	debug.cmd.AttachSubcommand(debug.bundle.Flaggy(), 1)
 	return &debug
 }
 
 type debug struct {
-	cmd              *flaggy.Subcommand
-	nodeConfigSource string
-	noColor          bool
+	cmd               *flaggy.Subcommand
+	nodeConfigSource  string
+	nodeConfigOverlay string
+	noColor           bool
+	kubeconfig        string
+	kubeconfigContext string
+	fix               bool
+	mtuRanges         []string
+	networkMTURanges  []string
+	skipMTUValidation bool
+
+	readinessStabilityPeriod time.Duration
+
+	regenerateKubeconfig *regenerateKubeconfig
+	facts                *factsCommand
+	logs                 *logsCommand
+	watch                *watchCommand
+	whoami               *whoamiCommand
+	bundle               *bundleCommand
 }
 
 func (c *debug) Flaggy() *flaggy.Subcommand {
@@ -54,15 +128,43 @@ func (c *debug) Flaggy() *flaggy.Subcommand {
 }
 
 func (c *debug) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	if c.regenerateKubeconfig.Flaggy().Used {
+		return c.regenerateKubeconfig.Run(log, opts)
+	}
+	if c.facts.Flaggy().Used {
+		return c.facts.Run(log, opts)
+	}
+	if c.logs.Flaggy().Used {
+		return c.logs.Run(log, opts)
+	}
+	if c.watch.Flaggy().Used {
+		return c.watch.Run(log, opts)
+	}
+	if c.whoami.Flaggy().Used {
+		return c.whoami.Run(log, opts)
+	}
+	if c.bundle.Flaggy().Used {
+		return c.bundle.Run(log, opts)
+	}
+
 	ctx := context.Background()
 	ctx = logger.NewContext(ctx, log)
 
 	if c.nodeConfigSource == "" {
-		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds]." +
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input." +
 			" For example on hybrid nodes --config-source file://nodeConfig.yaml")
 	}
 
-	provider, err := configprovider.BuildConfigProvider(c.nodeConfigSource)
+	mtuRanges, err := parseMTURanges(c.mtuRanges)
+	if err != nil {
+		return err
+	}
+	networkMTURanges, err := parseNetworkMTURanges(c.networkMTURanges)
+	if err != nil {
+		return err
+	}
+
+	provider, err := configprovider.BuildConfigProviderWithOverlay(c.nodeConfigSource, c.nodeConfigOverlay)
 	if err != nil {
 		return err
 	}
@@ -92,9 +194,18 @@ func (c *debug) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	defer func() { os.Stderr = originalStderr }()
 	os.Stderr = printer.File
 
+	if installed, err := tracker.GetInstalledArtifacts(); err == nil {
+		if err := skippedValidationsWarning(installed.SkippedValidations); err != nil {
+			printer.Starting(ctx, "skipped-validations", "Checking validations skipped when setting up the node")
+			printer.Done(ctx, "skipped-validations", err)
+		}
+	}
+
 	runner := validation.NewRunner[*api.NodeConfig](printer)
 	apiServerValidator := kubernetes.NewAPIServerValidator(kubelet.New())
-	clusterProvider := kubernetes.NewClusterProvider(awsConfig)
+	// Share the DescribeCluster response between the cluster details and the network validations.
+	clusterCache := eks.NewClusterCacheFromConfig(awsConfig)
+	clusterProvider := kubernetes.NewClusterProviderWithCache(clusterCache)
 
 	// Register validations that do not require cluster details first
 	runner.Register(creds.Validations(awsConfig, nodeConfig)...)
@@ -104,6 +215,7 @@ func (c *debug) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		validation.New("ulimit", system.NewUlimitValidator().Run),
 		validation.New("aws-auth", sts.NewAuthenticationValidator(awsConfig).Run),
 		validation.New("proxy-config", network.NewProxyValidator().Run),
+		validation.New("kubelet-dns", network.NewKubeletDNSValidator().Run),
 	)
 
 	clusterDetail, err := clusterProvider.ReadClusterDetails(ctx, nodeConfig)
@@ -125,12 +237,36 @@ func (c *debug) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 			validation.New("k8s-vpc-network", apiServerValidator.CheckVPCEndpointAccess),
 		),
 		validation.New("k8s-certificate", kubernetes.NewKubeletCertificateValidator(clusterDetail).Run),
+		validation.New("k8s-client-credential", kubernetes.NewKubeletClientCredentialValidator(clusterDetail).Run),
 	)
 
-	cluster, _ := eks.ReadCluster(ctx, awsConfig, nodeConfig)
-	runner.Register(validation.New("network-interface", network.NewNetworkInterfaceValidator(network.WithCluster(cluster)).Run))
+	cluster, _ := clusterCache.ReadCluster(ctx, nodeConfig)
+	runner.Register(
+		validation.New("network-interface", network.NewNetworkInterfaceValidator(
+			network.WithCluster(cluster),
+			network.WithMTUValidation(!c.skipMTUValidation),
+			network.WithMTURanges(mtuRanges...),
+			network.WithNetworkMTURanges(networkMTURanges...),
+			network.WithPathMTUProbe(network.NewPathMTUProber())).Run),
+		validation.New("default-routes", network.NewDefaultRoutesValidator().Run),
+		validation.New("cni-sysctls", system.NewCNISysctlValidator(nodevalidator.DetectLocalCNI, system.WithSysctlFix(c.fix)).Run),
+		validation.New("cni-configs", nodevalidator.NewCNIConfigValidator().Run),
+	)
 
-	runner.Register(validation.New("active-node-validation", nodevalidator.NewActiveNodeValidator().Run))
+	if manager, err := daemon.NewDaemonManager(); err != nil {
+		log.Warn("Skipping services enabled validation, failed to connect to systemd", zap.Error(err))
+	} else {
+		defer manager.Close()
+		runner.Register(validation.New("daemons-enabled",
+			daemon.NewEnabledValidator(manager, hybrid.BootDaemons(nodeConfig)...).Run))
+		runner.Register(validation.New("iam-ra-credentials-refresh",
+			iamrolesanywhere.NewCredentialsRefreshValidator(manager).Run))
+	}
+
+	runner.Register(validation.New("active-node-validation", nodevalidator.NewActiveNodeValidator(
+		nodevalidator.WithKubeconfig(c.kubeconfig, c.kubeconfigContext),
+		nodevalidator.WithReadinessStabilityPeriod(c.readinessStabilityPeriod),
+	).Run))
 
 	if err := runner.Sequentially(ctx, nodeConfig); err != nil {
 		fmt.Println("")
@@ -142,3 +278,41 @@ func (c *debug) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 
 	return nil
 }
+
+// parseMTURanges parses the --mtu-range values.
+func parseMTURanges(values []string) ([]network.MTURange, error) {
+	ranges := make([]network.MTURange, 0, len(values))
+	for _, value := range values {
+		r, err := network.ParseMTURange(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --mtu-range: %w", err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// parseNetworkMTURanges parses the --network-mtu-range values.
+func parseNetworkMTURanges(values []string) ([]network.NetworkMTURanges, error) {
+	networks := make([]network.NetworkMTURanges, 0, len(values))
+	for _, value := range values {
+		n, err := network.ParseNetworkMTURange(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --network-mtu-range: %w", err)
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// skippedValidationsWarning returns a warning listing the validations skipped the last
+// time the node was initialized or upgraded, or nil if none were skipped.
+func skippedValidationsWarning(skipped *tracker.SkippedValidations) error {
+	if skipped == nil || len(skipped.Validations) == 0 {
+		return nil
+	}
+	return validation.WithWarning(
+		fmt.Errorf("nodeadm %s on %s skipped validations: %s",
+			skipped.Command, skipped.Time.Format(time.RFC3339), strings.Join(skipped.Validations, ", ")),
+		"The node was set up with reduced checks. Run nodeadm "+skipped.Command+" again without --skip for these validations to confirm the node configuration.")
+}
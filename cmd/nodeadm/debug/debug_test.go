@@ -0,0 +1,27 @@
+package debug
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestSkippedValidationsWarning(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(skippedValidationsWarning(nil)).To(Succeed())
+	g.Expect(skippedValidationsWarning(&tracker.SkippedValidations{Command: "init"})).To(Succeed())
+
+	err := skippedValidationsWarning(&tracker.SkippedValidations{
+		Command:     "init",
+		Validations: []string{"node-ip-validation", "swap-validation"},
+		Time:        time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	})
+	g.Expect(err).To(MatchError("nodeadm init on 2024-05-01T10:00:00Z skipped validations: node-ip-validation, swap-validation"))
+	g.Expect(validation.IsWarning(err)).To(BeTrue())
+	g.Expect(validation.Remediation(err)).To(ContainSubstring("reduced checks"))
+}
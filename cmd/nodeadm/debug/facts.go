@@ -0,0 +1,46 @@
+package debug
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/facts"
+)
+
+const factsHelpText = `Examples:
+  # Print the detected system facts as JSON to attach to a support case
+  nodeadm debug facts > facts.json
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
+
+func newFactsCommand() *factsCommand {
+	cmd := factsCommand{}
+	cmd.cmd = flaggy.NewSubcommand("facts")
+	cmd.cmd.Description = "Print the system facts detected by nodeadm as JSON"
+	cmd.cmd.AdditionalHelpPrepend = factsHelpText
+	return &cmd
+}
+
+type factsCommand struct {
+	cmd *flaggy.Subcommand
+}
+
+func (c *factsCommand) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *factsCommand) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	return writeFacts(os.Stdout, facts.Collect(facts.NewDetectors()))
+}
+
+func writeFacts(w io.Writer, detected facts.Facts) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(detected)
+}
@@ -0,0 +1,216 @@
+package debug
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+)
+
+const (
+	defaultLogsSince = 10 * time.Minute
+	// maxLogLines bounds how many lines are kept in memory when printing the logs without --follow.
+	maxLogLines = 10000
+	// maxLogLineSize bounds the size of a single log line, longer lines fail the read.
+	maxLogLineSize = 64 * 1024
+)
+
+// logServices maps the --service values to their systemd units.
+var logServices = map[string]string{
+	"kubelet":    kubelet.KubeletDaemonName,
+	"containerd": containerd.ContainerdDaemonName,
+}
+
+const logsHelpText = `Examples:
+  # Print the kubelet logs of the last 10 minutes
+  nodeadm debug logs --service kubelet
+
+  # Follow the containerd logs, starting with the last 30 minutes
+  nodeadm debug logs --service containerd --since 30m --follow
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
+
+func newLogsCommand() *logsCommand {
+	cmd := logsCommand{
+		since:   defaultLogsSince,
+		journal: journalctl{},
+	}
+	cmd.cmd = flaggy.NewSubcommand("logs")
+	cmd.cmd.String(&cmd.service, "", "service", fmt.Sprintf("Service to print the logs of. Allowed values: [%s].", strings.Join(slices.Sorted(maps.Keys(logServices)), ", ")))
+	cmd.cmd.Duration(&cmd.since, "", "since", "Print the logs written in this window before now. Example: 10m")
+	cmd.cmd.Bool(&cmd.follow, "f", "follow", "Keep printing new logs as they are written until interrupted.")
+	cmd.cmd.Description = "Print the journald logs of the kubelet or containerd service"
+	cmd.cmd.AdditionalHelpPrepend = logsHelpText
+	return &cmd
+}
+
+type logsCommand struct {
+	cmd     *flaggy.Subcommand
+	service string
+	since   time.Duration
+	follow  bool
+	journal journalReader
+}
+
+func (c *logsCommand) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *logsCommand) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	query, err := c.query()
+	if err != nil {
+		return err
+	}
+	return printLogs(ctx, c.journal, query, os.Stdout)
+}
+
+// query validates the flags and builds the journal query from them.
+func (c *logsCommand) query() (journalQuery, error) {
+	unit, ok := logServices[c.service]
+	if !ok {
+		return journalQuery{}, fmt.Errorf("--service must be one of [%s], got %q", strings.Join(slices.Sorted(maps.Keys(logServices)), ", "), c.service)
+	}
+	if c.since <= 0 {
+		return journalQuery{}, fmt.Errorf("--since must be greater than 0, got %s", c.since)
+	}
+	return journalQuery{unit: unit, since: c.since, follow: c.follow}, nil
+}
+
+type journalQuery struct {
+	unit   string
+	since  time.Duration
+	follow bool
+}
+
+// journalReader returns the logs of a systemd unit, one entry per line.
+type journalReader interface {
+	Read(ctx context.Context, query journalQuery) (io.ReadCloser, error)
+}
+
+// printLogs writes the logs matching query to w. Without follow, only the last maxLogLines
+// lines are printed so memory stays bounded, with follow lines are written as they are read.
+func printLogs(ctx context.Context, journal journalReader, query journalQuery, w io.Writer) (err error) {
+	logs, err := journal.Read(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := logs.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 0, 4096), maxLogLineSize)
+
+	if query.follow {
+		for scanner.Scan() {
+			if _, err := fmt.Fprintln(w, scanner.Text()); err != nil {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			// Interrupted by the user, which is how --follow is expected to end.
+			return nil
+		}
+		return scanErr(scanner.Err(), query)
+	}
+
+	lines := make([]string, 0, 128)
+	dropped := 0
+	for scanner.Scan() {
+		if len(lines) == maxLogLines {
+			lines = lines[1:]
+			dropped++
+		}
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanErr(scanner.Err(), query); err != nil {
+		return err
+	}
+	if dropped > 0 {
+		if _, err := fmt.Fprintf(w, "-- %d earlier lines omitted, showing the last %d --\n", dropped, maxLogLines); err != nil {
+			return err
+		}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanErr(err error, query journalQuery) error {
+	if errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("reading %s logs: a log line is longer than %d bytes", query.unit, maxLogLineSize)
+	} else if err != nil {
+		return fmt.Errorf("reading %s logs: %w", query.unit, err)
+	}
+	return nil
+}
+
+// journalctl reads the logs with the journalctl CLI.
+type journalctl struct{}
+
+func (journalctl) Read(ctx context.Context, query journalQuery) (io.ReadCloser, error) {
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		return nil, fmt.Errorf("journald is not available on this host, journalctl was not found: %w", err)
+	}
+	args := []string{
+		"--unit", query.unit,
+		"--since", fmt.Sprintf("-%ds", int(query.since.Seconds())),
+		"--output", "short-iso",
+		"--no-pager",
+	}
+	if query.follow {
+		args = append(args, "--follow")
+	}
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("running journalctl: %w", err)
+	}
+	return &journalctlOutput{ReadCloser: stdout, ctx: ctx, cmd: cmd, stderr: &stderr}, nil
+}
+
+// journalctlOutput waits for journalctl to exit when closed, reporting why journald couldn't be read.
+type journalctlOutput struct {
+	io.ReadCloser
+	ctx    context.Context
+	cmd    *exec.Cmd
+	stderr *strings.Builder
+}
+
+func (o *journalctlOutput) Close() error {
+	o.ReadCloser.Close()
+	if err := o.cmd.Wait(); err != nil && o.ctx.Err() == nil {
+		return fmt.Errorf("reading journald logs, is systemd-journald running? %s: %w", strings.TrimSpace(o.stderr.String()), err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integrii/flaggy"
+	. "github.com/onsi/gomega"
+)
+
+type fakeJournal struct {
+	logs  string
+	err   error
+	query journalQuery
+}
+
+func (j *fakeJournal) Read(ctx context.Context, query journalQuery) (io.ReadCloser, error) {
+	j.query = query
+	if j.err != nil {
+		return nil, j.err
+	}
+	return io.NopCloser(strings.NewReader(j.logs)), nil
+}
+
+func parseLogsCommand(args ...string) (*logsCommand, error) {
+	cmd := newLogsCommand()
+	parser := flaggy.NewParser("debug")
+	parser.ShowHelpOnUnexpected = false
+	parser.AttachSubcommand(cmd.Flaggy(), 1)
+	return cmd, parser.ParseArgs(append([]string{"logs"}, args...))
+}
+
+func TestLogsCommandQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    journalQuery
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			args: []string{"--service", "kubelet"},
+			want: journalQuery{unit: "kubelet", since: 10 * time.Minute},
+		},
+		{
+			name: "follow containerd",
+			args: []string{"--service", "containerd", "--since", "1h", "-f"},
+			want: journalQuery{unit: "containerd", since: time.Hour, follow: true},
+		},
+		{
+			name:    "missing service",
+			args:    []string{},
+			wantErr: `--service must be one of [containerd, kubelet], got ""`,
+		},
+		{
+			name:    "unknown service",
+			args:    []string{"--service", "sshd"},
+			wantErr: `--service must be one of [containerd, kubelet], got "sshd"`,
+		},
+		{
+			name:    "non positive since",
+			args:    []string{"--service", "kubelet", "--since", "0s"},
+			wantErr: "--since must be greater than 0, got 0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cmd, err := parseLogsCommand(tt.args...)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			query, err := cmd.query()
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(tt.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(query).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestPrintLogs(t *testing.T) {
+	g := NewWithT(t)
+	journal := &fakeJournal{logs: "line 1\nline 2\n"}
+	query := journalQuery{unit: "kubelet", since: time.Minute}
+
+	var out bytes.Buffer
+	g.Expect(printLogs(context.Background(), journal, query, &out)).To(Succeed())
+	g.Expect(out.String()).To(Equal("line 1\nline 2\n"))
+	g.Expect(journal.query).To(Equal(query))
+}
+
+func TestPrintLogsKeepsLastLines(t *testing.T) {
+	g := NewWithT(t)
+	var logs strings.Builder
+	for i := range maxLogLines + 5 {
+		fmt.Fprintf(&logs, "line %d\n", i)
+	}
+	journal := &fakeJournal{logs: logs.String()}
+
+	var out bytes.Buffer
+	g.Expect(printLogs(context.Background(), journal, journalQuery{unit: "kubelet", since: time.Minute}, &out)).To(Succeed())
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	g.Expect(lines).To(HaveLen(maxLogLines + 1))
+	g.Expect(lines[0]).To(Equal(fmt.Sprintf("-- 5 earlier lines omitted, showing the last %d --", maxLogLines)))
+	g.Expect(lines[1]).To(Equal("line 5"))
+	g.Expect(lines[maxLogLines]).To(Equal(fmt.Sprintf("line %d", maxLogLines+4)))
+}
+
+func TestPrintLogsFollow(t *testing.T) {
+	g := NewWithT(t)
+	journal := &fakeJournal{logs: "line 1\nline 2\n"}
+
+	var out bytes.Buffer
+	g.Expect(printLogs(context.Background(), journal, journalQuery{unit: "kubelet", since: time.Minute, follow: true}, &out)).To(Succeed())
+	g.Expect(out.String()).To(Equal("line 1\nline 2\n"))
+}
+
+func TestPrintLogsLineTooLong(t *testing.T) {
+	g := NewWithT(t)
+	journal := &fakeJournal{logs: strings.Repeat("a", maxLogLineSize+1) + "\n"}
+
+	err := printLogs(context.Background(), journal, journalQuery{unit: "kubelet", since: time.Minute}, io.Discard)
+	g.Expect(err).To(MatchError(fmt.Sprintf("reading kubelet logs: a log line is longer than %d bytes", maxLogLineSize)))
+}
+
+func TestPrintLogsJournalUnavailable(t *testing.T) {
+	g := NewWithT(t)
+	journal := &fakeJournal{err: errors.New("journald is not available on this host")}
+
+	err := printLogs(context.Background(), journal, journalQuery{unit: "kubelet", since: time.Minute}, io.Discard)
+	g.Expect(err).To(MatchError("journald is not available on this host"))
+}
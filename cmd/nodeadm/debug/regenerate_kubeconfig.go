@@ -0,0 +1,147 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/logging"
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/configprovider"
+	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/kubernetes"
+	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const regenerateKubeconfigHelpText = `Examples:
+  # Regenerate the kubelet kubeconfig and restart kubelet
+  nodeadm debug regenerate-kubeconfig --config-source file://nodeConfig.yaml
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
+
+func newRegenerateKubeconfigCommand() *regenerateKubeconfig {
+	cmd := regenerateKubeconfig{}
+	cmd.cmd = flaggy.NewSubcommand("regenerate-kubeconfig")
+	cmd.cmd.String(&cmd.nodeConfigSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	cmd.cmd.String(&cmd.nodeConfigOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
+	cmd.cmd.Bool(&cmd.skipRestart, "", "skip-restart", "If set, kubelet is not restarted after regenerating the kubeconfig.")
+	cmd.cmd.Description = "Regenerate the kubelet kubeconfig from the node configuration and restart kubelet"
+	cmd.cmd.AdditionalHelpPrepend = regenerateKubeconfigHelpText
+	return &cmd
+}
+
+type regenerateKubeconfig struct {
+	cmd               *flaggy.Subcommand
+	nodeConfigSource  string
+	nodeConfigOverlay string
+	skipRestart       bool
+}
+
+func (c *regenerateKubeconfig) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *regenerateKubeconfig) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx := context.Background()
+	ctx = logger.NewContext(ctx, log)
+
+	root, err := cli.IsRunningAsRoot()
+	if err != nil {
+		return err
+	} else if !root {
+		return cli.ErrMustRunAsRoot
+	}
+
+	if c.nodeConfigSource == "" {
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	}
+
+	nodeConfig, err := loadNodeConfig(c.nodeConfigSource, c.nodeConfigOverlay)
+	if err != nil {
+		return err
+	}
+
+	// Use the same credentials kubelet uses so the cluster details are read
+	// with the node's current credential provider.
+	awsConfig, err := creds.ReadConfigAsKubelet(ctx, nodeConfig, config.WithLogger(logging.Nop{}))
+	if err != nil {
+		return err
+	}
+
+	log.Info("Reading cluster details...")
+	clusterDetails, err := kubernetes.NewClusterProvider(awsConfig).ReadClusterDetails(ctx, nodeConfig)
+	if err != nil {
+		return err
+	}
+	nodeConfig.Spec.Cluster = *clusterDetails
+
+	log.Info("Regenerating kubelet kubeconfig...")
+	regenerated, err := kubelet.RegenerateKubeconfig(nodeConfig)
+	if err != nil {
+		return err
+	}
+	log.Info("Wrote kubelet kubeconfig", zap.String("path", regenerated.Path))
+
+	runner := validation.NewRunner[*api.NodeConfig](validation.NewLoggerPrinterWithLogger(log))
+	runner.Register(
+		runner.UntilError(
+			validation.New("k8s-endpoint-network", kubernetes.NewAccessValidator(clusterDetails).Run),
+			validation.New("k8s-authentication", kubernetes.NewAPIServerValidator(kubelet.New()).MakeAuthenticatedRequest),
+		),
+	)
+	if err := runner.Sequentially(ctx, nodeConfig); err != nil {
+		// Don't leave kubelet with a kubeconfig that can't reach the cluster.
+		log.Info("Restoring previous kubelet kubeconfig...")
+		if restoreErr := regenerated.Restore(); restoreErr != nil {
+			return fmt.Errorf("validating regenerated kubelet kubeconfig: %w (restoring previous kubeconfig: %v)", err, restoreErr)
+		}
+		return fmt.Errorf("validating regenerated kubelet kubeconfig, previous kubeconfig restored: %w", err)
+	}
+
+	if c.skipRestart {
+		return nil
+	}
+
+	manager, err := daemon.NewDaemonManager()
+	if err != nil {
+		return err
+	}
+	defer manager.Close()
+
+	log.Info("Restarting kubelet...")
+	if err := manager.RestartDaemon(ctx, kubelet.KubeletDaemonName); err != nil {
+		return err
+	}
+	runningCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	if err := daemon.WaitForStatus(runningCtx, log, manager, kubelet.KubeletDaemonName, daemon.DaemonStatusRunning, 5*time.Second); err != nil {
+		return fmt.Errorf("waiting for kubelet to be running: %w", err)
+	}
+	log.Info("kubelet is running with the regenerated kubeconfig")
+	return nil
+}
+
+// loadNodeConfig reads the node config and populates the defaults kubelet relies on,
+// like the IAM Roles Anywhere AWS config path.
+func loadNodeConfig(source, overlay string) (*api.NodeConfig, error) {
+	provider, err := configprovider.BuildConfigProviderWithOverlay(source, overlay)
+	if err != nil {
+		return nil, err
+	}
+	nodeConfig, err := provider.Provide()
+	if err != nil {
+		return nil, err
+	}
+	hybrid.PopulateNodeConfigDefaults(nodeConfig)
+	return nodeConfig, nil
+}
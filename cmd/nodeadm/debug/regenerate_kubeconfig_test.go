@@ -0,0 +1,35 @@
+package debug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+)
+
+func TestLoadNodeConfigPopulatesDefaults(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "nodeConfig.yaml")
+	g.Expect(os.WriteFile(path, []byte(`---
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+    region: us-west-2
+  hybrid:
+    iamRolesAnywhere:
+      nodeName: my-node
+      trustAnchorArn: arn:aws:rolesanywhere:us-west-2:123456789010:trust-anchor/anchor
+      profileArn: arn:aws:rolesanywhere:us-west-2:123456789010:profile/profile
+      roleArn: arn:aws:iam::123456789010:role/hybrid-node
+`), 0o644)).To(Succeed())
+
+	nodeConfig, err := loadNodeConfig("file://"+path, "")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(nodeConfig.Spec.Hybrid.IAMRolesAnywhere.AwsConfigPath).To(Equal(iamrolesanywhere.DefaultAWSConfigPath))
+	g.Expect(nodeConfig.Status.Hybrid.NodeName).To(Equal("my-node"))
+}
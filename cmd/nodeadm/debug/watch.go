@@ -0,0 +1,216 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/logging"
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/kubernetes"
+	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/nodevalidator"
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	defaultWatchListenAddress = "127.0.0.1:10280"
+	defaultWatchInterval      = time.Minute
+	minWatchInterval          = 10 * time.Second
+	// watchReadinessTimeout bounds how long each run waits for the node to be ready,
+	// so a NotReady node is reported instead of blocking the run.
+	watchReadinessTimeout = 30 * time.Second
+	watchShutdownTimeout  = 5 * time.Second
+)
+
+const watchHelpText = `Examples:
+  # Re-run the node health checks every minute and serve the status on port 10280
+  nodeadm debug watch --config-source file://nodeConfig.yaml --listen :10280
+
+  # Read the current status
+  curl http://127.0.0.1:10280/status
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
+
+func newWatchCommand() *watchCommand {
+	cmd := watchCommand{
+		listenAddress: defaultWatchListenAddress,
+		interval:      defaultWatchInterval,
+	}
+	cmd.cmd = flaggy.NewSubcommand("watch")
+	cmd.cmd.String(&cmd.nodeConfigSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	cmd.cmd.String(&cmd.nodeConfigOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
+	cmd.cmd.String(&cmd.listenAddress, "", "listen", "Address the JSON status is served on. Use :PORT to listen on all interfaces.")
+	cmd.cmd.Duration(&cmd.interval, "", "interval", fmt.Sprintf("How often the node health checks are run. Must be at least %s.", minWatchInterval))
+	cmd.cmd.Description = "Periodically run the node health checks and serve their status over HTTP"
+	cmd.cmd.AdditionalHelpPrepend = watchHelpText
+	return &cmd
+}
+
+type watchCommand struct {
+	cmd               *flaggy.Subcommand
+	nodeConfigSource  string
+	nodeConfigOverlay string
+	listenAddress     string
+	interval          time.Duration
+}
+
+func (c *watchCommand) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *watchCommand) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx = logger.NewContext(ctx, log)
+
+	if c.nodeConfigSource == "" {
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	}
+	if c.interval < minWatchInterval {
+		return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+	}
+
+	nodeConfig, err := loadNodeConfig(c.nodeConfigSource, c.nodeConfigOverlay)
+	if err != nil {
+		return err
+	}
+	awsConfig, err := creds.ReadConfigAsKubelet(ctx, nodeConfig, config.WithLogger(logging.Nop{}))
+	if err != nil {
+		return err
+	}
+	clusterDetails, err := kubernetes.NewClusterProvider(awsConfig).ReadClusterDetails(ctx, nodeConfig)
+	if err != nil {
+		return err
+	}
+
+	monitor := newHealthMonitor(nodeConfig,
+		validation.New("node-readiness", nodevalidator.NewActiveNodeValidator(
+			nodevalidator.WithTimeout(watchReadinessTimeout),
+		).Run),
+		validation.New("cni-sysctls", system.NewCNISysctlValidator(nodevalidator.DetectLocalCNI).Run),
+		validation.New("k8s-certificate", kubernetes.NewKubeletCertificateValidator(clusterDetails).Run),
+		validation.New("k8s-client-credential", kubernetes.NewKubeletClientCredentialValidator(clusterDetails).Run),
+	)
+
+	server := &http.Server{
+		Addr:              c.listenAddress,
+		Handler:           monitor.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		MaxHeaderBytes:    8 << 10,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info("Serving node health status", zap.String("address", c.listenAddress))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		monitor.watch(ctx, c.interval)
+	}()
+
+	select {
+	case err = <-serverErr:
+		stop()
+		err = fmt.Errorf("serving node health status: %w", err)
+	case <-ctx.Done():
+		log.Info("Shutting down node health watch")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), watchShutdownTimeout)
+		defer cancel()
+		if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil && !errors.Is(shutdownErr, http.ErrServerClosed) {
+			err = fmt.Errorf("shutting down node health status server: %w", shutdownErr)
+		}
+	}
+	<-watchDone
+	return err
+}
+
+// healthMonitor runs the node health checks and keeps the status of the last run.
+type healthMonitor struct {
+	nodeConfig  *api.NodeConfig
+	validations []validation.Validation[*api.NodeConfig]
+
+	mu     sync.RWMutex
+	status *validation.Status
+}
+
+func newHealthMonitor(nodeConfig *api.NodeConfig, validations ...validation.Validation[*api.NodeConfig]) *healthMonitor {
+	return &healthMonitor{
+		nodeConfig:  nodeConfig,
+		validations: validations,
+	}
+}
+
+// watch runs the checks right away and then every interval until ctx is done.
+// Runs never overlap and each one is cancelled after interval, so a hanging check
+// can't pile up goroutines or connections.
+func (m *healthMonitor) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, interval)
+		m.check(runCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check runs all the checks once and replaces the current status with their results.
+func (m *healthMonitor) check(ctx context.Context) {
+	recorder := validation.NewStatusRecorder()
+	runner := validation.NewRunner[*api.NodeConfig](recorder)
+	runner.Register(m.validations...)
+	// Failures are reported through the recorder.
+	_ = runner.Sequentially(ctx, m.nodeConfig)
+
+	status := recorder.Status()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = &status
+}
+
+// handler serves the status of the last run as JSON. It responds with 503 until
+// the first run finishes and while the node is unhealthy, so it can back HTTP probes.
+func (m *healthMonitor) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.RLock()
+		status := m.status
+		m.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if status == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "node health checks haven't finished running yet"})
+			return
+		}
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+	return mux
+}
@@ -0,0 +1,98 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func fakeCheck(name string, err *error) validation.Validation[*api.NodeConfig] {
+	return validation.New(name, func(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+		informer.Starting(ctx, name, "Validating "+name)
+		informer.Done(ctx, name, *err)
+		return *err
+	})
+}
+
+func getStatus(g *WithT, monitor *healthMonitor) (int, validation.Status) {
+	recorder := httptest.NewRecorder()
+	monitor.handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	g.Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+	var status validation.Status
+	g.Expect(json.Unmarshal(recorder.Body.Bytes(), &status)).To(Succeed())
+	return recorder.Code, status
+}
+
+func TestHealthMonitorHandler(t *testing.T) {
+	g := NewWithT(t)
+	var readinessErr, certErr error
+	monitor := newHealthMonitor(&api.NodeConfig{},
+		fakeCheck("node-readiness", &readinessErr),
+		fakeCheck("k8s-certificate", &certErr),
+	)
+
+	code, _ := getStatus(g, monitor)
+	g.Expect(code).To(Equal(http.StatusServiceUnavailable))
+
+	monitor.check(context.Background())
+	code, status := getStatus(g, monitor)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(status.Healthy).To(BeTrue())
+	g.Expect(status.Checks).To(HaveLen(2))
+	g.Expect(status.Checks[0].Name).To(Equal("node-readiness"))
+	g.Expect(status.Checks[0].Status).To(Equal(validation.CheckPassed))
+
+	certErr = validation.WithWarning(errors.New("certificate expires in 5 days"), "Rotate the kubelet certificate")
+	monitor.check(context.Background())
+	code, status = getStatus(g, monitor)
+	g.Expect(code).To(Equal(http.StatusOK))
+	g.Expect(status.Checks[1].Status).To(Equal(validation.CheckWarning))
+
+	readinessErr = validation.WithRemediation(errors.New("node is NotReady"), "Check the CNI")
+	monitor.check(context.Background())
+	code, status = getStatus(g, monitor)
+	g.Expect(code).To(Equal(http.StatusServiceUnavailable))
+	g.Expect(status.Healthy).To(BeFalse())
+	g.Expect(status.Checks[0].Status).To(Equal(validation.CheckFailed))
+	g.Expect(status.Checks[0].Errors).To(ConsistOf("node is NotReady"))
+	g.Expect(status.Checks[0].Remediation).To(ConsistOf("Check the CNI"))
+}
+
+func TestHealthMonitorHandlerRejectsOtherMethods(t *testing.T) {
+	g := NewWithT(t)
+	monitor := newHealthMonitor(&api.NodeConfig{})
+
+	recorder := httptest.NewRecorder()
+	monitor.handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/status", nil))
+	g.Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+}
+
+func TestHealthMonitorWatch(t *testing.T) {
+	g := NewWithT(t)
+	var err error
+	monitor := newHealthMonitor(&api.NodeConfig{}, fakeCheck("node-readiness", &err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.watch(ctx, time.Hour)
+	}()
+
+	g.Eventually(func() int {
+		code, _ := getStatus(g, monitor)
+		return code
+	}).Should(Equal(http.StatusOK))
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
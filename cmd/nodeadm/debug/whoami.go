@@ -0,0 +1,160 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/logging"
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const whoamiHelpText = `Examples:
+  # Print the identity the node credential provider assumes
+  nodeadm debug whoami --config-source file://nodeConfig.yaml
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_debug`
+
+func newWhoamiCommand() *whoamiCommand {
+	cmd := whoamiCommand{}
+	cmd.cmd = flaggy.NewSubcommand("whoami")
+	cmd.cmd.String(&cmd.nodeConfigSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	cmd.cmd.String(&cmd.nodeConfigOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
+	cmd.cmd.Description = "Get credentials from the node credential provider and print the AWS identity they belong to as JSON"
+	cmd.cmd.AdditionalHelpPrepend = whoamiHelpText
+	return &cmd
+}
+
+type whoamiCommand struct {
+	cmd               *flaggy.Subcommand
+	nodeConfigSource  string
+	nodeConfigOverlay string
+}
+
+func (c *whoamiCommand) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *whoamiCommand) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx := context.Background()
+
+	if c.nodeConfigSource == "" {
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	}
+
+	nodeConfig, err := loadNodeConfig(c.nodeConfigSource, c.nodeConfigOverlay)
+	if err != nil {
+		return err
+	}
+	provider, err := creds.GetCredentialProviderFromNodeConfig(nodeConfig)
+	if err != nil {
+		return err
+	}
+
+	// Use the same credentials kubelet uses, so the identity is the one the node joins the cluster with.
+	awsConfig, err := creds.ReadConfigAsKubelet(ctx, nodeConfig, config.WithLogger(logging.Nop{}))
+	if err != nil {
+		return err
+	}
+
+	log.Info("Getting credentials from the node credential provider", zap.String("provider", string(provider)))
+	identity, err := whoami(ctx, provider, awsConfig.Credentials, sts.NewFromConfig(awsConfig))
+	if err != nil {
+		return err
+	}
+	return writeIdentity(os.Stdout, identity)
+}
+
+// callerIdentityAPI is the STS API used to get the identity of the node credentials.
+type callerIdentityAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// nodeIdentity is the AWS identity of the node credentials.
+type nodeIdentity struct {
+	CredentialProvider creds.CredentialProvider `json:"credentialProvider"`
+	Arn                string                   `json:"arn"`
+	Account            string                   `json:"account"`
+	UserID             string                   `json:"userId"`
+}
+
+// whoami gets credentials from the credential provider and the identity they belong to from STS.
+// Credentials are retrieved first so a failing provider is told apart from a rejected identity.
+func whoami(ctx context.Context, provider creds.CredentialProvider, credentials aws.CredentialsProvider, client callerIdentityAPI) (*nodeIdentity, error) {
+	if credentials == nil {
+		return nil, validation.WithRemediation(errors.New("no credentials configured for the node"), noCredentialsRemediation(provider))
+	}
+	if _, err := credentials.Retrieve(ctx); err != nil {
+		err = fmt.Errorf("getting credentials from the %s credential provider: %w", provider, err)
+		if isExpiredError(err) {
+			return nil, validation.WithRemediation(err, expiredCredentialsRemediation(provider))
+		}
+		return nil, validation.WithRemediation(err, noCredentialsRemediation(provider))
+	}
+
+	output, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		err = fmt.Errorf("getting caller identity: %w", err)
+		var apiErr smithy.APIError
+		switch {
+		case errors.As(err, &apiErr) && (apiErr.ErrorCode() == "ExpiredToken" || apiErr.ErrorCode() == "ExpiredTokenException"):
+			return nil, validation.WithRemediation(err, expiredCredentialsRemediation(provider))
+		case errors.As(err, &apiErr) && (apiErr.ErrorCode() == "AccessDenied" || apiErr.ErrorCode() == "InvalidClientTokenId" || apiErr.ErrorCode() == "SignatureDoesNotMatch"):
+			return nil, validation.WithRemediation(err, "AWS rejected the node credentials. Ensure they belong to the node IAM role and haven't been revoked, "+
+				"and that no policy or permissions boundary denies sts:GetCallerIdentity.")
+		default:
+			return nil, validation.WithRemediation(err, "Ensure the node can reach the STS endpoint of the cluster region.")
+		}
+	}
+
+	return &nodeIdentity{
+		CredentialProvider: provider,
+		Arn:                aws.ToString(output.Arn),
+		Account:            aws.ToString(output.Account),
+		UserID:             aws.ToString(output.UserId),
+	}, nil
+}
+
+// isExpiredError returns true if err is caused by expired credentials or an expired certificate,
+// which the IAM Roles Anywhere signing helper reports in the error message.
+func isExpiredError(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && strings.Contains(apiErr.ErrorCode(), "Expired") {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "expired")
+}
+
+func noCredentialsRemediation(provider creds.CredentialProvider) string {
+	if provider == creds.IamRolesAnywhereCredentialProvider {
+		return "Ensure the IAM Roles Anywhere certificate and key in the node config exist and the trust anchor, profile and role ARNs are correct."
+	}
+	return "Ensure the node is registered with SSM and the amazon-ssm-agent is running, it writes the node credentials once the registration completes."
+}
+
+func expiredCredentialsRemediation(provider creds.CredentialProvider) string {
+	if provider == creds.IamRolesAnywhereCredentialProvider {
+		return "The IAM Roles Anywhere certificate has expired or the system time is wrong. Renew the node certificate and verify the system time is correct."
+	}
+	return "The SSM credentials have expired. Ensure the amazon-ssm-agent is running to refresh them and verify the system time is correct."
+}
+
+func writeIdentity(w io.Writer, identity *nodeIdentity) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(identity)
+}
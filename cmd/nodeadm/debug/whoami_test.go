@@ -0,0 +1,128 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeCallerIdentity struct {
+	output *sts.GetCallerIdentityOutput
+	err    error
+	calls  int
+}
+
+func (f *fakeCallerIdentity) GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	f.calls++
+	return f.output, f.err
+}
+
+func credentialsProvider(err error) aws.CredentialsProvider {
+	return aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		if err != nil {
+			return aws.Credentials{}, err
+		}
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+	})
+}
+
+func TestWhoami(t *testing.T) {
+	g := NewWithT(t)
+	client := &fakeCallerIdentity{output: &sts.GetCallerIdentityOutput{
+		Arn:     aws.String("arn:aws:sts::123456789012:assumed-role/hybrid-node-role/mi-0123456789abcdef0"),
+		Account: aws.String("123456789012"),
+		UserId:  aws.String("AROAEXAMPLE:mi-0123456789abcdef0"),
+	}}
+
+	identity, err := whoami(context.Background(), creds.SsmCredentialProvider, credentialsProvider(nil), client)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var out bytes.Buffer
+	g.Expect(writeIdentity(&out, identity)).To(Succeed())
+	g.Expect(out.String()).To(MatchJSON(`{
+		"credentialProvider": "ssm",
+		"arn": "arn:aws:sts::123456789012:assumed-role/hybrid-node-role/mi-0123456789abcdef0",
+		"account": "123456789012",
+		"userId": "AROAEXAMPLE:mi-0123456789abcdef0"
+	}`))
+}
+
+func TestWhoamiErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		provider        creds.CredentialProvider
+		credentials     aws.CredentialsProvider
+		stsErr          error
+		wantErr         string
+		wantRemediation string
+		wantSTSCalls    int
+	}{
+		{
+			name:            "no credentials",
+			provider:        creds.SsmCredentialProvider,
+			credentials:     credentialsProvider(errors.New("failed to refresh cached credentials, no valid credential sources found")),
+			wantErr:         "getting credentials from the ssm credential provider: failed to refresh cached credentials",
+			wantRemediation: "Ensure the node is registered with SSM",
+		},
+		{
+			name:            "no credential provider configured",
+			provider:        creds.IamRolesAnywhereCredentialProvider,
+			wantErr:         "no credentials configured for the node",
+			wantRemediation: "Ensure the IAM Roles Anywhere certificate and key in the node config exist",
+		},
+		{
+			name:            "expired certificate",
+			provider:        creds.IamRolesAnywhereCredentialProvider,
+			credentials:     credentialsProvider(errors.New("credential_process: x509: certificate has expired or is not yet valid")),
+			wantErr:         "getting credentials from the iam-ra credential provider",
+			wantRemediation: "The IAM Roles Anywhere certificate has expired",
+		},
+		{
+			name:            "expired token",
+			provider:        creds.SsmCredentialProvider,
+			credentials:     credentialsProvider(nil),
+			stsErr:          &smithy.GenericAPIError{Code: "ExpiredToken", Message: "The security token included in the request is expired"},
+			wantErr:         "getting caller identity",
+			wantRemediation: "The SSM credentials have expired",
+			wantSTSCalls:    1,
+		},
+		{
+			name:            "access denied",
+			provider:        creds.IamRolesAnywhereCredentialProvider,
+			credentials:     credentialsProvider(nil),
+			stsErr:          &smithy.GenericAPIError{Code: "AccessDenied", Message: "explicit deny"},
+			wantErr:         "getting caller identity",
+			wantRemediation: "AWS rejected the node credentials",
+			wantSTSCalls:    1,
+		},
+		{
+			name:            "sts not reachable",
+			provider:        creds.SsmCredentialProvider,
+			credentials:     credentialsProvider(nil),
+			stsErr:          errors.New("dial tcp: i/o timeout"),
+			wantErr:         "getting caller identity: dial tcp: i/o timeout",
+			wantRemediation: "Ensure the node can reach the STS endpoint",
+			wantSTSCalls:    1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client := &fakeCallerIdentity{err: tc.stsErr}
+
+			_, err := whoami(context.Background(), tc.provider, tc.credentials, client)
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			g.Expect(validation.Remediation(err)).To(ContainSubstring(tc.wantRemediation))
+			g.Expect(client.calls).To(Equal(tc.wantSTSCalls))
+		})
+	}
+}
@@ -4,23 +4,31 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
 	"k8s.io/utils/strings/slices"
 
+	"github.com/aws/eks-hybrid/internal/callback"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/firewall"
 	"github.com/aws/eks-hybrid/internal/flows"
+	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
 	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
 const (
 	installValidation      = "install-validation"
+	configPhase            = "config"
 	cniPortCheckValidation = "cni-validation"
 	calicoVxLanPort        = "4789"
 	ciliumVxLanPort        = "8472"
@@ -32,21 +40,43 @@ func Phases() []string {
 	return []string{
 		"install-validation",
 		"cni-validation",
+		"cluster-remote-network-validation",
 		"node-ip-validation",
+		"node-ip-stability-validation",
+		"node-ip-route-validation",
+		"node-source-ip-validation",
+		"default-routes-validation",
 		"credentials-validation",
 		"kubelet-cert-validation",
+		"kubelet-client-credential-validation",
 		"ssm-api-network-validation",
 		"iam-ra-api-network-validation",
+		"iam-ra-certificate-chain-validation",
 		"aws-auth-validation",
 		"k8s-endpoint-network-validation",
 		"k8s-authentication-validation",
+		"image-credential-provider-validation",
 		"kubelet-version-skew-validation",
 		"api-server-endpoint-resolution-validation",
 		"proxy-validation",
+		"kubelet-dns-validation",
+		"swap-validation",
+		"disk-validation",
 		"node-inactive-validation",
+		"kubelet-process-validation",
+		"image-service-endpoint-validation",
+		"image-pull-settings-validation",
+		"cluster-details-validation",
+		"region-validation",
+		"cgroup-validation",
+		"kubelet-config-validation",
+		"clock-skew-validation",
 		"preprocess",
 		"config",
 		"run",
+		"container-runtime-validation",
+		"image-pull-validation",
+		"node-labels",
 	}
 }
 
@@ -54,17 +84,33 @@ const initHelpText = `Examples:
   # Initialize using configuration file
   nodeadm init --config-source file://nodeConfig.yaml
 
+  # Initialize using configuration piped through standard input
+  cat nodeConfig.yaml | nodeadm init --config-source -
+
+  # Initialize using a base configuration shared by a fleet and a per-node overlay
+  nodeadm init --config-source file://base.yaml --config-overlay file://node.yaml
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_init`
 
 func NewInitCommand() cli.Command {
 	init := initCmd{}
 	init.cmd = flaggy.NewSubcommand("init")
-	init.cmd.String(&init.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
+	init.cmd.String(&init.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	init.cmd.String(&init.configOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
 	init.cmd.StringSlice(&init.daemons, "d", "daemon", "Specify one or more of `containerd` and `kubelet`. This is intended for testing and should not be used in a production environment.")
 	init.cmd.StringSlice(&init.skipPhases, "s", "skip", fmt.Sprintf("Phases of the bootstrap to skip. Allowed values: [%s].", strings.Join(Phases(), ", ")))
+	init.cmd.Bool(&init.skipValidations, "", "skip-validations", "Skip all the validation phases. Skipped validations are recorded and reported by nodeadm debug.")
 	init.cmd.String(&init.manifestOverride, "m", "manifest-override", "URI to a manifest file containing custom artifact URLs. Supports file:// for local files and https:// for remote files.")
 	init.cmd.Bool(&init.privateMode, "", "private-mode", "Enable private init mode (requires --manifest-override for region config).")
+	init.cmd.StringSlice(&init.cniPorts, "", "cni-ports", "CNI overlay ports, in port/protocol form, of which at least one needs to be open in the host firewall. Can be repeated. Defaults to the Cilium (8472/udp) and Calico (4789/udp) VxLan ports.")
+	init.cmd.Int(&init.versionSkewOverride, "", "version-skew-override", fmt.Sprintf("Number of minor versions the kubelet is allowed to be behind the kube-apiserver, overriding the Kubernetes version skew policy of 3. Widening it is unsupported and meant for controlled migrations, the maximum is %d.", hybrid.MaxVersionSkewOverride))
+	init.cmd.Duration(&init.clockSkewTolerance, "", "clock-skew-tolerance", fmt.Sprintf("Maximum skew between the node clock and AWS time before init fails. Defaults to %s, the skew AWS tolerates for signed requests.", system.DefaultClockSkewTolerance))
+	init.cmd.Bool(&init.emitEvents, "", "emit-events", "Wait for the node to join the cluster and emit Kubernetes events for each join milestone (registered, CNI detected, ready). Init blocks for up to 5 minutes waiting for the node to be ready. Failures to emit events don't fail init.")
+	init.cmd.Bool(&init.waitForSystemPods, "", "wait-for-system-pods", "Wait for up to 10 minutes for the system DaemonSet pods to be scheduled and running on the node before completing init, failing if any is missing or unhealthy.")
+	init.cmd.StringSlice(&init.systemDaemonSets, "", "system-daemonsets", "DaemonSets, in namespace/name form or name for kube-system, --wait-for-system-pods waits for. Can be repeated. Defaults to all the DaemonSets in kube-system whose node selector matches the node.")
+	init.cmd.String(&init.callbackURL, "", "callback-url", "URL to POST a JSON status payload with the node name, init result and validation summary to once init finishes. The request is retried and honors the proxy configuration. Failures to notify don't fail init.")
+	init.cmd.String(&init.eksAssumeRoleARN, "", "eks-assume-role-arn", "ARN of an IAM role to assume for the EKS API requests made during init, like DescribeCluster, when the node credentials are not allowed to make them. The role trust policy must allow the node credentials to assume it.")
 	init.cmd.Description = "Initialize this instance as a node in an EKS cluster"
 	init.cmd.AdditionalHelpAppend = initHelpText
 	return &init
@@ -73,10 +119,24 @@ func NewInitCommand() cli.Command {
 type initCmd struct {
 	cmd              *flaggy.Subcommand
 	configSource     string
+	configOverlay    string
 	skipPhases       []string
+	skipValidations  bool
 	daemons          []string
 	manifestOverride string
 	privateMode      bool
+	emitEvents       bool
+	callbackURL      string
+	cniPorts         []string
+	// versionSkewOverride is zero when the version skew policy is not overridden.
+	versionSkewOverride int
+	// clockSkewTolerance is zero when the default clock skew tolerance is kept.
+	clockSkewTolerance time.Duration
+	// waitForSystemPods waits for the systemDaemonSets pods to run on the node.
+	waitForSystemPods bool
+	systemDaemonSets  []string
+	// eksAssumeRoleARN is the role assumed for the EKS API requests, the node credentials are used when empty.
+	eksAssumeRoleARN string
 }
 
 func (c *initCmd) Flaggy() *flaggy.Subcommand {
@@ -86,6 +146,8 @@ func (c *initCmd) Flaggy() *flaggy.Subcommand {
 func (c *initCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	ctx := context.Background()
 	ctx = logger.NewContext(ctx, log)
+	ctx = cli.WithResult(ctx, opts.Result)
+	defer opts.Result.RecordInstalledArtifacts()
 
 	log.Info("Checking user is root...")
 	root, err := cli.IsRunningAsRoot()
@@ -96,7 +158,7 @@ func (c *initCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 
 	if c.configSource == "" {
-		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds]." +
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input." +
 			" For example on hybrid nodes --config-source file://nodeConfig.yaml")
 	}
 
@@ -104,9 +166,45 @@ func (c *initCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		return fmt.Errorf("--private-mode requires --manifest-override to be specified")
 	}
 
+	if c.versionSkewOverride < 0 {
+		return fmt.Errorf("--version-skew-override must be a positive number of minor versions, got %d", c.versionSkewOverride)
+	}
+
+	if c.clockSkewTolerance < 0 {
+		return fmt.Errorf("--clock-skew-tolerance must be a positive duration, got %s", c.clockSkewTolerance)
+	}
+
+	if c.eksAssumeRoleARN != "" {
+		if _, err := arn.Parse(c.eksAssumeRoleARN); err != nil {
+			return fmt.Errorf("invalid --eks-assume-role-arn %q: %w", c.eksAssumeRoleARN, err)
+		}
+	}
+
+	if c.callbackURL != "" {
+		if err := callback.ValidateURL(c.callbackURL); err != nil {
+			return err
+		}
+	}
+
+	if c.skipValidations {
+		c.skipPhases = append(c.skipPhases, flows.SkippedValidations(Phases())...)
+	}
+	opts.Result.SkipPhases(c.skipPhases...)
+
+	nodeProvider, err := node.NewNodeProvider(c.configSource, c.configOverlay, c.skipPhases, log,
+		hybrid.WithVersionSkewOverride(c.versionSkewOverride),
+		hybrid.WithPrivateMode(c.privateMode),
+		hybrid.WithClockSkewTolerance(c.clockSkewTolerance),
+		hybrid.WithEKSAssumeRoleARN(c.eksAssumeRoleARN))
+	if err != nil {
+		return err
+	}
+
+	externalRuntime := false
+	var sandboxImage string
 	if !slices.Contains(c.skipPhases, installValidation) {
 		log.Info("Loading installed components")
-		_, err = tracker.GetInstalledArtifacts()
+		installed, err := tracker.GetInstalledArtifacts()
 		if err != nil && os.IsNotExist(err) {
 			log.Info("Nodeadm components are not installed. Please run `nodeadm install` before running init")
 			return nil
@@ -117,56 +215,173 @@ func (c *initCmd) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		if err := containerd.ValidateSystemdUnitFile(); err != nil {
 			return fmt.Errorf("a systemd unit file for containerd is required to init the node: %w", err)
 		}
+		// nodeadm writes the containerd config of the node config in the config phase, unless it's skipped.
+		if err := containerd.ValidateContainerdConfig(nodeProvider.GetNodeConfig(), slices.Contains(c.skipPhases, configPhase)); err != nil {
+			return fmt.Errorf("validating containerd config, it can be bypassed with --skip %s: %w", installValidation, err)
+		}
+		if err := containerd.ValidateSnapshotter(nodeProvider.GetNodeConfig(), slices.Contains(c.skipPhases, configPhase)); err != nil {
+			return fmt.Errorf("validating containerd snapshotter filesystem, it can be bypassed with --skip %s: %w", installValidation, err)
+		}
+		externalRuntime = installed.Artifacts.Containerd == tracker.ContainerdSourceNone
+		sandboxImage = installed.Artifacts.SandboxImage
+		cli.PhaseRun(ctx, installValidation)
+	} else if installed, err := tracker.GetInstalledArtifacts(); err != nil {
+		log.Warn("Unable to read installed components, using the default sandbox image", zap.Error(err))
+	} else {
+		sandboxImage = installed.Artifacts.SandboxImage
 	}
 
-	// Check if either of cilium or calico vxlan port are open
+	// Check if any of the CNI overlay ports, by default the cilium or calico vxlan ports, are open
 	if !slices.Contains(c.skipPhases, cniPortCheckValidation) {
-		log.Info("Validating firewall ports for cilium and calico")
-		if err := validateFirewallOpenPorts(); err != nil {
-			return fmt.Errorf("Cilium (%s/%s) or Calico (%s/%s) VxLan ports are not open on the host. If you are not using VxLan, this validation can by bypassed with --skip %s",
-				ciliumVxLanPort, vxLanProtocol, calicoVxLanPort, vxLanProtocol, cniPortCheckValidation)
+		ports := defaultCNIPorts
+		if len(c.cniPorts) > 0 {
+			if ports, err = parseCNIPorts(c.cniPorts); err != nil {
+				return err
+			}
 		}
+		log.Info("Validating firewall ports for CNI", zap.Stringers("ports", ports))
+		if _, err := validateFirewallOpenPorts(system.NewFirewallManager(), ports, log); err != nil {
+			return err
+		}
+		cli.PhaseRun(ctx, cniPortCheckValidation)
+	}
+
+	initer := &flows.Initer{
+		NodeProvider:      nodeProvider,
+		SkipPhases:        c.skipPhases,
+		Logger:            log,
+		ManifestOverride:  c.manifestOverride,
+		PrivateMode:       c.privateMode,
+		ExternalRuntime:   externalRuntime,
+		EmitJoinEvents:    c.emitEvents,
+		WaitForSystemPods: c.waitForSystemPods,
+		SystemDaemonSets:  c.systemDaemonSets,
+		OSInfo:            system.HostOSInfo(),
+		SandboxImage:      sandboxImage,
+	}
+
+	err = initer.Run(ctx)
+	if c.callbackURL != "" {
+		notifyCallback(ctx, c.callbackURL, opts.Result, err, log)
 	}
+	return err
+}
 
-	nodeProvider, err := node.NewNodeProvider(c.configSource, c.skipPhases, log)
+// notifyCallback posts the init result to the callback URL. Failures are logged and never fail init.
+func notifyCallback(ctx context.Context, callbackURL string, result *cli.Result, initErr error, log *zap.Logger) {
+	// The node name is only known once kubelet is configured, init might have failed before that.
+	nodeName, err := kubelet.GetNodeName()
 	if err != nil {
-		return err
+		log.Debug("Node name is not available for the callback payload", zap.Error(err))
 	}
+	payload := callback.NewPayload("init", nodeName, result.ValidationStatus(), initErr)
+	log.Info("Notifying init result to callback URL...", zap.String("url", callbackURL), zap.String("result", payload.Result))
+	if err := callback.NewNotifier(callbackURL).Notify(ctx, payload); err != nil {
+		log.Warn("Failed to notify init result to callback URL", zap.String("url", callbackURL), zap.Error(err))
+		return
+	}
+	log.Info("Notified init result to callback URL", zap.String("url", callbackURL))
+}
 
-	initer := &flows.Initer{
-		NodeProvider:     nodeProvider,
-		SkipPhases:       c.skipPhases,
-		Logger:           log,
-		ManifestOverride: c.manifestOverride,
-		PrivateMode:      c.privateMode,
+// cniPort is a port used by a CNI overlay.
+type cniPort struct {
+	// cni is the name of the CNI using the port, if known.
+	cni      string
+	port     string
+	protocol string
+}
+
+func (p cniPort) String() string {
+	if p.cni == "" {
+		return p.port + "/" + p.protocol
+	}
+	return fmt.Sprintf("%s (%s/%s)", p.cni, p.port, p.protocol)
+}
+
+var defaultCNIPorts = []cniPort{
+	{cni: "Cilium", port: ciliumVxLanPort, protocol: vxLanProtocol},
+	{cni: "Calico", port: calicoVxLanPort, protocol: vxLanProtocol},
+}
+
+// parseCNIPorts parses ports in port/protocol form, like 51871/udp.
+func parseCNIPorts(values []string) ([]cniPort, error) {
+	ports := make([]cniPort, 0, len(values))
+	for _, value := range values {
+		port, protocol, found := strings.Cut(value, "/")
+		if !found {
+			return nil, fmt.Errorf("invalid --cni-ports value %q, expected port/protocol, for example 8472/udp", value)
+		}
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return nil, fmt.Errorf("invalid --cni-ports value %q, port must be a number between 1 and 65535", value)
+		}
+		protocol = strings.ToLower(protocol)
+		if protocol != "udp" && protocol != "tcp" {
+			return nil, fmt.Errorf("invalid --cni-ports value %q, protocol must be udp or tcp", value)
+		}
+		ports = append(ports, cniPort{port: port, protocol: protocol})
 	}
+	return ports, nil
+}
 
-	return initer.Run(ctx)
+// cniPortProbe is the state of the CNI ports in the host firewall.
+type cniPortProbe struct {
+	// firewallEnabled is false when the host has no firewall, which leaves every port open.
+	firewallEnabled bool
+	open            []cniPort
+	closed          []cniPort
 }
 
-func validateFirewallOpenPorts() error {
-	firewallManager := system.NewFirewallManager()
+// log reports which of the CNI ports are open, so operators can confirm the firewall matches their CNI.
+func (p cniPortProbe) log(log *zap.Logger) {
+	if !p.firewallEnabled {
+		log.Info("Host firewall is not enabled, CNI ports are not restricted")
+		return
+	}
+	log.Info("Probed firewall ports for CNI", zap.Stringers("open", p.open), zap.Stringers("closed", p.closed))
+}
+
+// probeFirewallPorts checks which of the ports are open if the firewall is enabled.
+// It only reads the firewall rules, so it doesn't change the state of the firewall.
+func probeFirewallPorts(firewallManager firewall.Manager, ports []cniPort) (cniPortProbe, error) {
+	var probe cniPortProbe
 	enabled, err := firewallManager.IsEnabled()
 	if err != nil {
-		return err
+		return probe, err
 	}
 	if !enabled {
-		return nil
+		return probe, nil
 	}
-	if err := firewallManager.FlushRules(); err != nil {
-		return err
+	probe.firewallEnabled = true
+	for _, port := range ports {
+		open, err := firewallManager.IsPortOpen(port.port, port.protocol)
+		if err != nil {
+			return probe, err
+		}
+		if open {
+			probe.open = append(probe.open, port)
+		} else {
+			probe.closed = append(probe.closed, port)
+		}
 	}
-	ciliumVxlanPortOpen, err := firewallManager.IsPortOpen(ciliumVxLanPort, vxLanProtocol)
+	return probe, nil
+}
+
+// validateFirewallOpenPorts probes the ports, logs the result of the probe and returns an error
+// if the firewall is enabled and none of them are open.
+func validateFirewallOpenPorts(firewallManager firewall.Manager, ports []cniPort, log *zap.Logger) (cniPortProbe, error) {
+	probe, err := probeFirewallPorts(firewallManager, ports)
 	if err != nil {
-		return err
+		return probe, err
 	}
-	calicoVxlanPortOpen, err := firewallManager.IsPortOpen(calicoVxLanPort, vxLanProtocol)
-	if err != nil {
-		return err
+	probe.log(log)
+	if !probe.firewallEnabled || len(probe.open) > 0 {
+		return probe, nil
 	}
 
-	if !ciliumVxlanPortOpen && !calicoVxlanPortOpen {
-		return fmt.Errorf("both cilium and calico vxlan ports are closed")
+	names := make([]string, 0, len(ports))
+	for _, port := range ports {
+		names = append(names, port.String())
 	}
-	return nil
+	return probe, fmt.Errorf("%s ports are not open on the host. If your CNI doesn't use them, check other ports with --cni-ports or bypass this validation with --skip %s",
+		strings.Join(names, " or "), cniPortCheckValidation)
 }
@@ -0,0 +1,169 @@
+package init
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+)
+
+type fakeFirewall struct {
+	enabled    bool
+	enabledErr error
+	openPorts  map[string]bool
+	checked    []string
+}
+
+func (f *fakeFirewall) IsEnabled() (bool, error) { return f.enabled, f.enabledErr }
+
+func (f *fakeFirewall) AllowTcpPort(string) error { return nil }
+
+func (f *fakeFirewall) AllowTcpPortRange(string, string) error { return nil }
+
+func (f *fakeFirewall) FlushRules() error { return nil }
+
+func (f *fakeFirewall) IsPortOpen(port, protocol string) (bool, error) {
+	f.checked = append(f.checked, port+"/"+protocol)
+	return f.openPorts[port+"/"+protocol], nil
+}
+
+func TestParseCNIPorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []cniPort
+		wantErr string
+	}{
+		{
+			name:   "valid ports",
+			values: []string{"51871/udp", "179/TCP"},
+			want: []cniPort{
+				{port: "51871", protocol: "udp"},
+				{port: "179", protocol: "tcp"},
+			},
+		},
+		{
+			name:    "missing protocol",
+			values:  []string{"8472"},
+			wantErr: "expected port/protocol",
+		},
+		{
+			name:    "port out of range",
+			values:  []string{"70000/udp"},
+			wantErr: "port must be a number between 1 and 65535",
+		},
+		{
+			name:    "port not a number",
+			values:  []string{"vxlan/udp"},
+			wantErr: "port must be a number between 1 and 65535",
+		},
+		{
+			name:    "unknown protocol",
+			values:  []string{"8472/sctp"},
+			wantErr: "protocol must be udp or tcp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ports, err := parseCNIPorts(tt.values)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ports).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestValidateFirewallOpenPorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		firewall   *fakeFirewall
+		wantOpen   []cniPort
+		wantClosed []cniPort
+		wantErr    string
+	}{
+		{
+			name:       "cilium port open",
+			firewall:   &fakeFirewall{enabled: true, openPorts: map[string]bool{"8472/udp": true}},
+			wantOpen:   []cniPort{defaultCNIPorts[0]},
+			wantClosed: []cniPort{defaultCNIPorts[1]},
+		},
+		{
+			name:       "calico port open",
+			firewall:   &fakeFirewall{enabled: true, openPorts: map[string]bool{"4789/udp": true}},
+			wantOpen:   []cniPort{defaultCNIPorts[1]},
+			wantClosed: []cniPort{defaultCNIPorts[0]},
+		},
+		{
+			name:       "both ports closed",
+			firewall:   &fakeFirewall{enabled: true},
+			wantClosed: defaultCNIPorts,
+			wantErr:    "Cilium (8472/udp) or Calico (4789/udp) ports are not open on the host",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			probe, err := validateFirewallOpenPorts(tt.firewall, defaultCNIPorts, zap.NewNop())
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(probe.firewallEnabled).To(BeTrue())
+			g.Expect(probe.open).To(Equal(tt.wantOpen))
+			g.Expect(probe.closed).To(Equal(tt.wantClosed))
+			g.Expect(tt.firewall.checked).To(Equal([]string{"8472/udp", "4789/udp"}))
+		})
+	}
+}
+
+func TestValidateFirewallOpenPortsFirewallDisabled(t *testing.T) {
+	g := NewWithT(t)
+
+	firewall := &fakeFirewall{enabled: false}
+	probe, err := validateFirewallOpenPorts(firewall, defaultCNIPorts, zap.NewNop())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(probe.firewallEnabled).To(BeFalse())
+	g.Expect(firewall.checked).To(BeEmpty())
+}
+
+func TestValidateFirewallOpenPortsAllClosed(t *testing.T) {
+	g := NewWithT(t)
+
+	ports, err := parseCNIPorts([]string{"51871/udp", "179/tcp"})
+	g.Expect(err).NotTo(HaveOccurred())
+	firewall := &fakeFirewall{enabled: true}
+	_, err = validateFirewallOpenPorts(firewall, ports, zap.NewNop())
+	g.Expect(err).To(MatchError(ContainSubstring("51871/udp or 179/tcp ports are not open on the host")))
+	g.Expect(err).To(MatchError(ContainSubstring("--skip cni-validation")))
+	g.Expect(firewall.checked).To(Equal([]string{"51871/udp", "179/tcp"}))
+}
+
+func TestValidateFirewallOpenPortsLogsOnlySuccessfulProbes(t *testing.T) {
+	g := NewWithT(t)
+
+	core, logs := observer.New(zap.InfoLevel)
+	_, err := validateFirewallOpenPorts(&fakeFirewall{enabledErr: errors.New("firewalld not responding")}, defaultCNIPorts, zap.New(core))
+	g.Expect(err).To(MatchError(ContainSubstring("firewalld not responding")))
+	g.Expect(logs.All()).To(BeEmpty())
+
+	_, err = validateFirewallOpenPorts(&fakeFirewall{enabled: false}, defaultCNIPorts, zap.New(core))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(logs.FilterMessage("Host firewall is not enabled, CNI ports are not restricted").Len()).To(Equal(1))
+}
+
+func TestPhasesIncludeHybridValidations(t *testing.T) {
+	g := NewWithT(t)
+	// The cluster access validation isn't an init phase, so --skip-validations keeps it.
+	validations := slices.DeleteFunc(hybrid.Validations(), func(v string) bool { return v == "cluster-access-validation" })
+	g.Expect(Phases()).To(ContainElements(validations))
+}
@@ -8,14 +8,17 @@ import (
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/flows"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
 	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
@@ -32,6 +35,15 @@ const installHelpText = `Examples:
   # Install from a private installation using a remote custom manifest
   nodeadm install 1.31 --credential-provider ssm --manifest-override https://my-bucket.s3.us-west-2.amazonaws.com/manifests/manifest.yaml --private-mode
 
+  # Install on an air-gapped node from a local manifest and a local directory of artifacts
+  nodeadm install 1.31 --credential-provider ssm --manifest-override file:///opt/artifacts/manifest.yaml --artifact-dir /opt/artifacts --private-mode
+
+  # Install and pre-pull the pause image from a private registry, init configures containerd and kubelet with it
+  nodeadm install 1.31 --credential-provider ssm --pause-image registry.example.com/eks/pause:3.10
+
+  # Install with SSM, failing if the SSM installer isn't signed by the pinned key
+  nodeadm install 1.31 --credential-provider ssm --ssm-signing-key-fingerprint 0123456789ABCDEF0123456789ABCDEF01234567
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_install`
 
@@ -47,10 +59,16 @@ func NewCommand() cli.Command {
 	fc.AdditionalHelpAppend = installHelpText
 	fc.AddPositionalValue(&cmd.kubernetesVersion, "KUBERNETES_VERSION", 1, true, "The major[.minor[.patch]] version of Kubernetes to install.")
 	fc.String(&cmd.credentialProvider, "p", "credential-provider", "Credential process to install. Allowed values: [ssm, iam-ra].")
-	fc.String(&cmd.containerdSource, "s", "containerd-source", "Source for containerd artifact. Allowed values: [none, distro, docker].")
+	fc.String(&cmd.containerdSource, "s", "containerd-source", "Source for containerd artifact. Allowed values: [none, distro, docker]. With none, nodeadm doesn't install containerd and init validates a CRI compatible runtime is running.")
 	fc.String(&cmd.region, "r", "region", "AWS region for downloading regional artifacts.")
 	fc.String(&cmd.manifestOverride, "m", "manifest-override", "URI to a manifest file containing custom artifact URLs. Supports file:// for local files and https:// for remote files.")
 	fc.Bool(&cmd.privateMode, "", "private-mode", "Enable private installation mode (skips OS packages, requires --manifest-override).")
+	fc.String(&cmd.artifactDir, "", "artifact-dir", "Local directory to read the artifacts from instead of downloading them, for air-gapped nodes. Each artifact is stored under its manifest name next to its checksum file, like kubelet and kubelet.sha256, and the SSM installer as ssm-setup-cli and ssm-setup-cli.sig.")
+	fc.Int(&cmd.downloadConcurrency, "", "download-concurrency", "Maximum number of concurrent artifact downloads. Defaults to unlimited.")
+	fc.String(&cmd.downloadBandwidthLimit, "", "download-bandwidth-limit", "Maximum bandwidth in bytes per second for each artifact download, as a quantity. Example: 500Ki or 10M. Defaults to unlimited.")
+	fc.String(&cmd.pauseImage, "", "pause-image", "Pause (sandbox) image to configure containerd and kubelet with at init, instead of the EKS default. It's validated and pre-pulled through the container runtime once installed, install fails if it can't be pulled instead of kubelet failing to create the first pod. Useful with private registries and air-gapped nodes.")
+	fc.StringSlice(&cmd.ssmSigningKeyFingerprints, "", "ssm-signing-key-fingerprint", "OpenPGP fingerprint of a key allowed to sign the SSM installer. Install fails if the installer source's key doesn't match. Can be repeated.")
+	fc.String(&cmd.ssmSigningKeyring, "", "ssm-signing-keyring", "Path to a file with the armored public keys allowed to sign the SSM installer. Install fails if the installer source's key isn't one of them.")
 	fc.Duration(&cmd.timeout, "t", "timeout", "Maximum install command duration. Input follows duration format. Example: 1h23s")
 	cmd.flaggy = fc
 
@@ -58,14 +76,21 @@ func NewCommand() cli.Command {
 }
 
 type command struct {
-	flaggy             *flaggy.Subcommand
-	kubernetesVersion  string
-	credentialProvider string
-	containerdSource   string
-	region             string
-	manifestOverride   string
-	privateMode        bool
-	timeout            time.Duration
+	flaggy                 *flaggy.Subcommand
+	kubernetesVersion      string
+	credentialProvider     string
+	containerdSource       string
+	region                 string
+	manifestOverride       string
+	privateMode            bool
+	artifactDir            string
+	downloadConcurrency    int
+	downloadBandwidthLimit string
+	timeout                time.Duration
+	pauseImage             string
+
+	ssmSigningKeyFingerprints []string
+	ssmSigningKeyring         string
 }
 
 func (c *command) Flaggy() *flaggy.Subcommand {
@@ -75,6 +100,8 @@ func (c *command) Flaggy() *flaggy.Subcommand {
 func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	ctx := context.Background()
 	ctx = logger.NewContext(ctx, log)
+	ctx = cli.WithResult(ctx, opts.Result)
+	defer opts.Result.RecordInstalledArtifacts()
 
 	root, err := cli.IsRunningAsRoot()
 	if err != nil {
@@ -97,11 +124,16 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		return err
 	}
 
+	if credentialProvider != creds.SsmCredentialProvider && (len(c.ssmSigningKeyFingerprints) > 0 || c.ssmSigningKeyring != "") {
+		return fmt.Errorf("--ssm-signing-key-fingerprint and --ssm-signing-keyring require --credential-provider ssm")
+	}
+
 	containerdSource, err := tracker.ContainerdSource(c.containerdSource)
 	if err != nil {
 		return err
 	}
-	if err := containerd.ValidateContainerdSource(containerdSource); err != nil {
+	osInfo := system.HostOSInfo()
+	if err := containerd.ValidateContainerdSource(containerdSource, osInfo); err != nil {
 		return err
 	}
 
@@ -129,23 +161,54 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		log.Info("Using Kubernetes version", zap.String("version", awsSource.Eks.Version))
 	}
 
+	bandwidthLimit, err := artifact.ParseBandwidthLimit(c.downloadBandwidthLimit)
+	if err != nil {
+		return err
+	}
+	awsSource = awsSource.WithDownloadLimits(c.downloadConcurrency, bandwidthLimit)
+	if c.artifactDir != "" {
+		log.Info("Using local artifacts", zap.String("dir", c.artifactDir))
+		if awsSource, err = awsSource.WithArtifactDir(c.artifactDir); err != nil {
+			return err
+		}
+	}
+
 	// Create package manager unless in private mode
 	if !c.privateMode {
 		log.Info("Creating package manager...")
-		packageManager, err = packagemanager.New(containerdSource, log)
+		packageManager, err = packagemanager.New(containerdSource, osInfo, log)
 		if err != nil {
 			return err
 		}
 	}
 
+	// The daemon manager is used to verify the SSM agent is running after it's installed
+	// and to start containerd to pre-pull the pause image.
+	var daemonManager daemon.DaemonManager
+	if credentialProvider == creds.SsmCredentialProvider || c.pauseImage != "" {
+		daemonManager, err = daemon.NewDaemonManager()
+		if err != nil {
+			// Without a daemon manager, the install still succeeds but the SSM agent
+			// and containerd status can't be checked.
+			log.Warn("Unable to connect to systemd, skipping the SSM agent running check and the sandbox image pre-pull", zap.Error(err))
+		} else {
+			defer daemonManager.Close()
+		}
+	}
+
 	installer := &flows.Installer{
 		AwsSource:          awsSource,
 		PackageManager:     packageManager,
 		ContainerdSource:   containerdSource,
 		SsmRegion:          c.region,
 		CredentialProvider: credentialProvider,
+		DaemonManager:      daemonManager,
 		Logger:             log,
 		PrivateMode:        c.privateMode,
+		SandboxImage:       c.pauseImage,
+
+		SsmPinnedFingerprints: c.ssmSigningKeyFingerprints,
+		SsmPinnedKeyring:      c.ssmSigningKeyring,
 	}
 
 	return installer.Run(ctx)
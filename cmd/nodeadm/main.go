@@ -13,6 +13,7 @@ import (
 	"github.com/aws/eks-hybrid/cmd/nodeadm/sync_artifacts"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/uninstall"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/upgrade"
+	"github.com/aws/eks-hybrid/cmd/nodeadm/validate"
 	"github.com/aws/eks-hybrid/cmd/nodeadm/version"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/errors"
@@ -39,16 +40,31 @@ func main() {
 		uninstall.NewCommand(),
 		upgrade.NewUpgradeCommand(),
 		debug.NewCommand(),
+		validate.NewCommand(),
 	}
 
 	for _, cmd := range cmds {
 		flaggy.AttachSubcommand(cmd.Flaggy(), 1)
 	}
 	flaggy.Parse()
+	if err := opts.Validate(); err != nil {
+		flaggy.ShowHelpAndExit(err.Error())
+	}
 
 	for _, cmd := range cmds {
 		if cmd.Flaggy().Used {
+			opts.Result.Command = cmd.Flaggy().Name
 			err := cmd.Run(log, opts)
+			if opts.JSONOutput() && cli.WritesResult(opts.Result.Command) {
+				if writeErr := opts.Result.Write(os.Stdout, err); writeErr != nil {
+					log.Error("Failed to write result", zap.Error(writeErr))
+				}
+			}
+			if opts.ResultFile != "" {
+				if writeErr := opts.Result.WriteFile(opts.ResultFile, err); writeErr != nil {
+					log.Error("Failed to write result file", zap.Error(writeErr))
+				}
+			}
 			if err != nil {
 				if errors.IsSilent(err) {
 					os.Exit(1)
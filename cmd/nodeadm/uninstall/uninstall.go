@@ -2,22 +2,35 @@ package uninstall
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	ekssdk "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
 	"k8s.io/utils/strings/slices"
 
+	"github.com/aws/eks-hybrid/internal/aws/eks"
 	"github.com/aws/eks-hybrid/internal/cleanup"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/cni"
+	"github.com/aws/eks-hybrid/internal/configprovider"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/creds"
 	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/flows"
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
@@ -33,6 +46,9 @@ const forceWarningText = "Force delete additional directories that might contain
 	"as it may contain Pod volumes and volume-subpath directories that sometimes include the mounted node filesystem. " +
 	"SAFE-HANDLING-TIPS: Before manually deleting /var/lib/kubelet, carefully inspect all active mounts and unmount volumes safely to avoid data loss."
 
+const deleteAccessEntryHelpText = "Delete the EKS access entry of the node IAM role, read from the node credentials. Requires --config-source. " +
+	"WARNING: The access entry is shared by all the nodes using the same IAM role, only use this flag when uninstalling the last of them."
+
 const uninstallHelpText = `Examples:
   # Uninstall all components
   nodeadm uninstall
@@ -40,6 +56,27 @@ const uninstallHelpText = `Examples:
   # Uninstall all components and skip pod-validation and node-validation pre-flight validation
   nodeadm uninstall --skip node-validation,pod-validation
 
+  # Print what would be uninstalled without removing anything
+  nodeadm uninstall --dry-run
+
+  # Uninstall all components but the CNI plugins, before provisioning the node again
+  nodeadm uninstall --keep-cni
+
+  # Uninstall all components and delete the Node object from the cluster
+  nodeadm uninstall --delete-node
+
+  # Uninstall all components and remove the Kubernetes pods left in the kube-system namespace
+  nodeadm uninstall --remove-pods --remove-pods-namespaces kube-system
+
+  # Uninstall all components and force-remove the Kubernetes pods without draining the node first
+  nodeadm uninstall --remove-pods --skip-drain
+
+  # Uninstall all components and remove the container images, before provisioning the node again
+  nodeadm uninstall --prune-images
+
+  # Uninstall all components and record what was removed
+  nodeadm uninstall --manifest /var/log/nodeadm-uninstall.json
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_uninstall`
 
@@ -51,6 +88,19 @@ func NewCommand() cli.Command {
 	fc.AdditionalHelpAppend = uninstallHelpText
 	fc.StringSlice(&cmd.skipPhases, "s", "skip", "Phases of uninstall to skip. Allowed values: [pod-validation, node-validation].")
 	fc.Bool(&cmd.force, "f", "force", forceWarningText)
+	fc.Bool(&cmd.dryRun, "", "dry-run", "Log the daemons, components and directories that would be removed without removing them.")
+	fc.Bool(&cmd.keepCNI, "", "keep-cni", "Keep the cni-plugins binaries in /opt/cni/bin, to avoid pod network churn when the node is initialized again.")
+	fc.Bool(&cmd.unmount, "", "unmount", "Unmount file systems mounted under /etc/eks and /opt/nodeadm so they can be removed. Without it, uninstall refuses to remove them.")
+	fc.Bool(&cmd.deleteNode, "", "delete-node", "Delete the Node object from the cluster once the kubelet is stopped.")
+	fc.Bool(&cmd.deleteAccessEntry, "", "delete-access-entry", deleteAccessEntryHelpText)
+	fc.Bool(&cmd.removePods, "", "remove-pods", "Stop and remove the pods kubelet left in the container runtime once it is stopped. Containers not created by kubelet are left intact.")
+	fc.StringSlice(&cmd.removePodsNamespaces, "", "remove-pods-namespaces", "Only remove the pods in these Kubernetes namespaces with --remove-pods. Defaults to all namespaces.")
+	fc.StringSlice(&cmd.removePodsLabels, "", "remove-pods-labels", "Only remove the pods with all these labels, in key=value form, with --remove-pods.")
+	fc.Bool(&cmd.pruneImages, "", "prune-images", "Remove the container images pulled through the container runtime before stopping it, to free disk space on nodes that are provisioned again. Images in containerd namespaces other than k8s.io are left intact.")
+	fc.Bool(&cmd.skipDrain, "", "skip-drain", "With --remove-pods, don't cordon and drain the node before removing the pods. By default pods are evicted first so they are rescheduled cleanly, and only the ones left are force-removed.")
+	fc.Duration(&cmd.drainGracePeriod, "", "drain-grace-period", "With --remove-pods, the termination grace period of the pods evicted while draining the node. Defaults to the grace period of each pod.")
+	fc.String(&cmd.manifestPath, "", "manifest", "Path to write a JSON manifest of the daemons stopped, components and directories removed and registrations deleted. It is written even if uninstall fails, listing what was removed before the failure. With --output json, the manifest is also included in the result.")
+	fc.String(&cmd.configSource, "c", "config-source", "Source of node configuration, only used by --delete-access-entry. The format is a URI with supported schemes: [file, imds, stdin].")
 	cmd.flaggy = fc
 
 	return &cmd
@@ -60,6 +110,27 @@ type command struct {
 	flaggy     *flaggy.Subcommand
 	skipPhases []string
 	force      bool
+	dryRun     bool
+	unmount    bool
+	keepCNI    bool
+
+	deleteNode        bool
+	deleteAccessEntry bool
+	configSource      string
+	manifestPath      string
+
+	removePods           bool
+	removePodsNamespaces []string
+	removePodsLabels     []string
+	skipDrain            bool
+	drainGracePeriod     time.Duration
+	pruneImages          bool
+}
+
+// drains returns true if the node is cordoned and drained through the Kubernetes API
+// before the pods left in the container runtime are removed.
+func (c *command) drains() bool {
+	return c.removePods && !c.skipDrain
 }
 
 func (c *command) Flaggy() *flaggy.Subcommand {
@@ -69,6 +140,8 @@ func (c *command) Flaggy() *flaggy.Subcommand {
 func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	ctx := context.Background()
 	ctx = logger.NewContext(ctx, log)
+	ctx = cli.WithResult(ctx, opts.Result)
+	opts.Result.SkipPhases(c.skipPhases...)
 
 	root, err := cli.IsRunningAsRoot()
 	if err != nil {
@@ -86,6 +159,8 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	} else if err != nil {
 		return err
 	}
+	// The artifacts are reported as they were before being uninstalled.
+	opts.Result.SetArtifacts(installed.Artifacts)
 
 	log.Info("Creating daemon manager...")
 	daemonManager, err := daemon.NewDaemonManager()
@@ -94,12 +169,15 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 	defer daemonManager.Close()
 
+	kubeletRunning := false
 	if installed.Artifacts.Kubelet {
 		kubeletStatus, err := daemonManager.GetDaemonStatus(kubelet.KubeletDaemonName)
 		if err != nil {
 			return err
 		}
-		if kubeletStatus == daemon.DaemonStatusRunning {
+		kubeletRunning = kubeletStatus == daemon.DaemonStatusRunning
+		// With --remove-pods the node is cordoned and drained by nodeadm itself.
+		if kubeletRunning && !c.drains() {
 			if !slices.Contains(c.skipPhases, skipPodPreflightCheck) {
 				log.Info("Validating if node has been drained...")
 				if drained, err := node.IsDrained(ctx); err != nil {
@@ -108,12 +186,14 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 					return fmt.Errorf("only static pods and pods controlled by daemon-sets can be running on the node. Please move pods " +
 						"to different node or use --skip pod-validation")
 				}
+				cli.PhaseRun(ctx, skipPodPreflightCheck)
 			}
 			if !slices.Contains(c.skipPhases, skipNodePreflightCheck) {
 				log.Info("Validating if node has been marked unschedulable...")
 				if err := node.IsUnscheduled(ctx); err != nil {
 					return fmt.Errorf("please drain or cordon node to mark it unschedulable or use --skip node-validation: %w", err)
 				}
+				cli.PhaseRun(ctx, skipNodePreflightCheck)
 			}
 		}
 	}
@@ -121,30 +201,198 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	log.Info("Creating package manager...")
 	containerdSource := installed.Artifacts.Containerd
 	log.Info("Configuring package manager with", zap.Reflect("containerd source", string(containerdSource)))
-	packageManager, err := packagemanager.New(containerdSource, log)
+	packageManager, err := packagemanager.New(containerdSource, system.HostOSInfo(), log)
 	if err != nil {
 		return err
 	}
 
-	uninstaller := &flows.Uninstaller{
-		Artifacts:      installed.Artifacts,
-		DaemonManager:  daemonManager,
-		PackageManager: packageManager,
-		Logger:         log,
-		CNIUninstall:   cni.Uninstall,
+	decommission, decommissionTargets, err := c.decommission(ctx, log)
+	if err != nil {
+		return err
 	}
 
-	if err := uninstaller.Run(ctx); err != nil {
+	removePods, err := c.podRemoval(log)
+	if err != nil {
+		return err
+	}
+
+	drain, err := c.nodeDrain(log, kubeletRunning)
+	if err != nil {
 		return err
 	}
 
-	if c.force {
-		log.Info("Force mode enabled, cleaning up additional directories...")
-		cleanupManager := cleanup.New(log)
-		if err := cleanupManager.Cleanup(); err != nil {
-			return fmt.Errorf("cleaning up additional directories: %w", err)
+	uninstaller := &flows.Uninstaller{
+		Artifacts:           installed.Artifacts,
+		DaemonManager:       daemonManager,
+		PackageManager:      packageManager,
+		Logger:              log,
+		CNIUninstall:        cni.Uninstall,
+		Decommission:        decommission,
+		DecommissionTargets: decommissionTargets,
+		DryRun:              c.dryRun,
+		Unmount:             c.unmount,
+		KeepCNI:             c.keepCNI,
+		Drain:               drain,
+		RemovePods:          removePods,
+		PruneImages:         c.imagePrune(log),
+	}
+
+	summary, err := uninstaller.Run(ctx)
+	if err == nil {
+		err = c.forceCleanup(log, &summary)
+	}
+	opts.Result.SetRemoved(summary)
+	if c.manifestPath != "" {
+		if writeErr := summary.WriteManifest(c.manifestPath); writeErr != nil {
+			return errors.Join(err, writeErr)
 		}
+		log.Info("Wrote uninstall manifest", zap.String("path", c.manifestPath))
+	}
+	return err
+}
+
+// forceCleanup removes the additional directories with --force and records them in the summary.
+func (c *command) forceCleanup(log *zap.Logger, summary *flows.UninstallSummary) error {
+	if !c.force {
+		return nil
+	}
+	if c.dryRun {
+		log.Info("Dry run, skipping force cleanup of additional directories")
+		return nil
 	}
 
+	log.Info("Force mode enabled, cleaning up additional directories...")
+	cleanupManager := cleanup.New(log)
+	if err := cleanupManager.Cleanup(); err != nil {
+		return fmt.Errorf("cleaning up additional directories: %w", err)
+	}
+	summary.Paths = append(summary.Paths, cleanup.Dirs()...)
 	return nil
 }
+
+// podRemoval returns the function removing the pods in the --remove-pods scope from the container runtime.
+func (c *command) podRemoval(log *zap.Logger) (flows.PodRemoval, error) {
+	if !c.removePods {
+		if len(c.removePodsNamespaces) > 0 || len(c.removePodsLabels) > 0 {
+			return nil, fmt.Errorf("--remove-pods-namespaces and --remove-pods-labels require --remove-pods")
+		}
+		return nil, nil
+	}
+	scope := containerd.PodCleanupScope{Namespaces: c.removePodsNamespaces}
+	for _, label := range c.removePodsLabels {
+		key, value, ok := strings.Cut(label, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --remove-pods-labels %q, expected key=value", label)
+		}
+		if scope.Labels == nil {
+			scope.Labels = map[string]string{}
+		}
+		scope.Labels[key] = value
+	}
+	return func(ctx context.Context) ([]string, error) {
+		return containerd.RemovePods(ctx, containerd.ContainerRuntimeEndpoint, scope, log)
+	}, nil
+}
+
+// imagePrune returns the function removing the images from the container runtime with --prune-images.
+func (c *command) imagePrune(log *zap.Logger) flows.ImagePrune {
+	if !c.pruneImages {
+		return nil
+	}
+	return func(ctx context.Context) ([]string, error) {
+		return containerd.PruneImages(ctx, containerd.ContainerRuntimeEndpoint, log)
+	}
+}
+
+// nodeDrain returns the function cordoning and draining the node before the pods are removed with
+// --remove-pods. The kubelet has to be running to terminate the evicted pods, otherwise there is
+// nothing to drain and the pods are only removed from the container runtime.
+func (c *command) nodeDrain(log *zap.Logger, kubeletRunning bool) (flows.NodeDrain, error) {
+	if !c.removePods && (c.skipDrain || c.drainGracePeriod != 0) {
+		return nil, fmt.Errorf("--skip-drain and --drain-grace-period require --remove-pods")
+	}
+	if c.drainGracePeriod < 0 {
+		return nil, fmt.Errorf("--drain-grace-period can't be negative")
+	}
+	if !c.drains() {
+		return nil, nil
+	}
+	if !kubeletRunning {
+		log.Info("Kubelet is not running, skipping node drain")
+		return nil, nil
+	}
+	nodeName, err := kubelet.GetNodeName()
+	if err != nil {
+		return nil, fmt.Errorf("getting node name from kubelet: %w", err)
+	}
+	client, err := hybrid.BuildKubeClient()
+	if err != nil {
+		return nil, fmt.Errorf("creating kubernetes client: %w", err)
+	}
+	opts := node.CordonOptions{DrainGracePeriod: c.drainGracePeriod, Logger: log}
+	return func(ctx context.Context) error {
+		return node.CordonAndDrain(ctx, client, nodeName, opts)
+	}, nil
+}
+
+// decommission builds the clients used to remove the node from the cluster before
+// anything is uninstalled, while the kubeconfig and the node credentials are still in place.
+// It also returns the names of what is removed, for the uninstall summary.
+func (c *command) decommission(ctx context.Context, log *zap.Logger) (flows.NodeDecommission, []string, error) {
+	if !c.deleteNode && !c.deleteAccessEntry {
+		return nil, nil, nil
+	}
+	opts := node.DecommissionOptions{Logger: log}
+	var targets []string
+
+	if c.deleteNode {
+		nodeName, err := kubelet.GetNodeName()
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting node name from kubelet: %w", err)
+		}
+		client, err := hybrid.BuildKubeClient()
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating kubernetes client: %w", err)
+		}
+		opts.KubeClient, opts.NodeName = client, nodeName
+		targets = append(targets, "node/"+nodeName)
+	}
+
+	if c.deleteAccessEntry {
+		if c.configSource == "" {
+			return nil, nil, fmt.Errorf("--delete-access-entry requires --config-source to read the cluster name and node credentials")
+		}
+		provider, err := configprovider.BuildConfigProvider(c.configSource)
+		if err != nil {
+			return nil, nil, err
+		}
+		nodeConfig, err := provider.Provide()
+		if err != nil {
+			return nil, nil, err
+		}
+		hybrid.PopulateNodeConfigDefaults(nodeConfig)
+		awsConfig, err := creds.ReadConfigAsKubelet(ctx, nodeConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		identity, err := sts.NewFromConfig(awsConfig).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting caller identity: %w", err)
+		}
+		parsed, err := arn.Parse(aws.ToString(identity.Arn))
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing caller identity ARN: %w", err)
+		}
+		roleName, ok := eks.RoleNameFromARN(parsed)
+		if !ok {
+			return nil, nil, fmt.Errorf("node credentials %s are not an IAM role", parsed)
+		}
+		opts.EKSClient = ekssdk.NewFromConfig(awsConfig)
+		opts.ClusterName, opts.RoleName = nodeConfig.Spec.Cluster.Name, roleName
+		targets = append(targets, fmt.Sprintf("access-entry/%s/%s", opts.ClusterName, roleName))
+	}
+
+	return func(ctx context.Context) error {
+		return node.Decommission(ctx, opts)
+	}, targets, nil
+}
@@ -0,0 +1,37 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// clusterDescriber reads an EKS cluster.
+type clusterDescriber interface {
+	DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error)
+}
+
+// validateTargetVersion returns an error if targetVersion, the Kubernetes version the node is
+// upgraded to, doesn't follow the version skew policy with the cluster control plane version.
+func validateTargetVersion(ctx context.Context, client clusterDescriber, clusterName, targetVersion string) error {
+	output, err := client.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: &clusterName})
+	if err != nil {
+		return validation.WithRemediation(fmt.Errorf("reading cluster %s to validate the upgrade version: %w", clusterName, err),
+			"Ensure the node has access and permissions to call DescribeCluster EKS API or use --force to upgrade without validating the version.")
+	}
+	if output.Cluster == nil || output.Cluster.Version == nil {
+		return fmt.Errorf("cluster %s doesn't report its Kubernetes version", clusterName)
+	}
+
+	clusterVersion := *output.Cluster.Version
+	if err := hybrid.ValidateVersionSkew(targetVersion, clusterVersion); err != nil && !validation.IsWarning(err) {
+		return validation.WithRemediation(fmt.Errorf("upgrading to Kubernetes %s is not compatible with cluster %s version %s: %w", targetVersion, clusterName, clusterVersion, err),
+			fmt.Sprintf("Upgrade to a Kubernetes version no newer than %s and within the version skew policy, upgrading the cluster first if needed, "+
+				"or use --force to upgrade anyway. https://kubernetes.io/releases/version-skew-policy/#kubelet", clusterVersion))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeClusterDescriber struct {
+	version *string
+	err     error
+}
+
+func (f fakeClusterDescriber) DescribeCluster(_ context.Context, params *eks.DescribeClusterInput, _ ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &eks.DescribeClusterOutput{Cluster: &types.Cluster{Name: params.Name, Version: f.version}}, nil
+}
+
+func TestValidateTargetVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		clusterVersion *string
+		describeErr    error
+		targetVersion  string
+		wantErr        string
+	}{
+		{
+			name:           "same version",
+			clusterVersion: aws.String("1.31"),
+			targetVersion:  "1.31.2",
+		},
+		{
+			name:           "within the skew policy",
+			clusterVersion: aws.String("1.32"),
+			targetVersion:  "1.29.10",
+		},
+		{
+			name:           "newer than the cluster",
+			clusterVersion: aws.String("1.30"),
+			targetVersion:  "1.31.2",
+			wantErr:        "upgrading to Kubernetes 1.31.2 is not compatible with cluster my-cluster version 1.30: kubelet version 1.31.2 is newer than kube-apiserver version 1.30",
+		},
+		{
+			name:           "too old for the cluster",
+			clusterVersion: aws.String("1.33"),
+			targetVersion:  "1.29.10",
+			wantErr:        "upgrading to Kubernetes 1.29.10 is not compatible with cluster my-cluster version 1.33: kubelet version 1.29.10 is too old for kube-apiserver version 1.33",
+		},
+		{
+			name:          "describe cluster fails",
+			describeErr:   errors.New("AccessDeniedException"),
+			targetVersion: "1.31.2",
+			wantErr:       "reading cluster my-cluster to validate the upgrade version: AccessDeniedException",
+		},
+		{
+			name:          "cluster without version",
+			targetVersion: "1.31.2",
+			wantErr:       "cluster my-cluster doesn't report its Kubernetes version",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client := fakeClusterDescriber{version: tc.clusterVersion, err: tc.describeErr}
+
+			err := validateTargetVersion(context.Background(), client, "my-cluster", tc.targetVersion)
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			if tc.clusterVersion != nil {
+				g.Expect(validation.Remediation(err)).To(ContainSubstring("--force"))
+			}
+		})
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/integrii/flaggy"
 	"go.uber.org/zap"
 	"k8s.io/utils/strings/slices"
 
 	initCmd "github.com/aws/eks-hybrid/cmd/nodeadm/init"
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/creds"
@@ -20,8 +22,12 @@ import (
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/logger"
 	"github.com/aws/eks-hybrid/internal/node"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+	"github.com/aws/eks-hybrid/internal/nodevalidator"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 const (
@@ -52,44 +58,79 @@ const upgradeHelpText = `Examples:
   # Upgrade all components with a custom timeout
   nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --timeout 1h23s
 
+  # Drain the node before upgrading and uncordon it once the node is ready
+  nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --drain
+
+  # Upgrade even if 1.31 is not compatible with the cluster version
+  nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --force
+
+  # Upgrade an air-gapped node from a local manifest and a local directory of artifacts
+  nodeadm upgrade 1.31 --config-source file:///root/nodeConfig.yaml --manifest-override file:///opt/artifacts/manifest.yaml --artifact-dir /opt/artifacts --private-mode
+
 Documentation:
   https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html#_upgrade`
 
 func NewUpgradeCommand() cli.Command {
 	cmd := command{
-		timeout: 20 * time.Minute,
+		timeout:                  20 * time.Minute,
+		readinessStabilityPeriod: nodevalidator.RecommendedReadinessStabilityPeriod,
 	}
 
 	fc := flaggy.NewSubcommand("upgrade")
 	fc.Description = "Upgrade components installed using the install sub-command"
 	fc.AdditionalHelpAppend = upgradeHelpText
 	fc.AddPositionalValue(&cmd.kubernetesVersion, "KUBERNETES_VERSION", 1, true, "The major[.minor[.patch]] version of Kubernetes to install.")
-	fc.String(&cmd.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds].")
+	fc.String(&cmd.configSource, "c", "config-source", "Source of node configuration. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input.")
+	fc.String(&cmd.configOverlay, "", "config-overlay", "Source of a node configuration overlay deep-merged on top of --config-source, where values in the overlay win. Supports the same schemes as --config-source.")
 	fc.StringSlice(&cmd.skipPhases, "s", "skip", fmt.Sprintf("Phases of the upgrade to skip. Allowed values: [%s].", strings.Join(upgradePhases(), ", ")))
 	fc.String(&cmd.manifestOverride, "m", "manifest-override", "URI to a manifest file containing custom artifact URLs. Supports file:// for local files and https:// for remote files.")
 	fc.Bool(&cmd.privateMode, "", "private-mode", "Enable private upgrade mode (skips OS packages, requires --manifest-override).")
+	fc.Bool(&cmd.cordon, "", "cordon", "Cordon the node before upgrading and uncordon it once the upgraded node is ready. Pods keep running on the node during the upgrade. The node is left cordoned if the upgrade fails.")
+	fc.Bool(&cmd.drain, "", "drain", "Cordon and drain the node before upgrading and uncordon it once the upgraded node is ready. The node is left cordoned if the upgrade fails.")
+	fc.Duration(&cmd.readinessStabilityPeriod, "", "readiness-stability-period", "With --cordon or --drain, how long the upgraded node needs to stay ready before it's uncordoned. Use 0 to uncordon on the first Ready observation.")
+	fc.String(&cmd.artifactDir, "", "artifact-dir", "Local directory to read the artifacts from instead of downloading them, for air-gapped nodes. Each artifact is stored under its manifest name next to its checksum file, like kubelet and kubelet.sha256, and the SSM installer as ssm-setup-cli and ssm-setup-cli.sig.")
+	fc.Int(&cmd.downloadConcurrency, "", "download-concurrency", "Maximum number of concurrent artifact downloads. Defaults to unlimited.")
+	fc.String(&cmd.downloadBandwidthLimit, "", "download-bandwidth-limit", "Maximum bandwidth in bytes per second for each artifact download, as a quantity. Example: 500Ki or 10M. Defaults to unlimited.")
+	fc.Bool(&cmd.force, "", "force", "Upgrade even if the Kubernetes version is not compatible with the cluster version per the version skew policy, or the cluster version can't be read.")
 	fc.Duration(&cmd.timeout, "t", "timeout", "Maximum upgrade command duration. Input follows duration format. Example: 1h23s")
 	cmd.flaggy = fc
 	return &cmd
 }
 
 type command struct {
-	flaggy            *flaggy.Subcommand
-	configSource      string
-	skipPhases        []string
-	kubernetesVersion string
-	manifestOverride  string
-	privateMode       bool
-	timeout           time.Duration
+	flaggy                   *flaggy.Subcommand
+	configSource             string
+	configOverlay            string
+	skipPhases               []string
+	kubernetesVersion        string
+	manifestOverride         string
+	privateMode              bool
+	cordon                   bool
+	drain                    bool
+	readinessStabilityPeriod time.Duration
+	artifactDir              string
+	downloadConcurrency      int
+	downloadBandwidthLimit   string
+	timeout                  time.Duration
+	force                    bool
 }
 
 func (c *command) Flaggy() *flaggy.Subcommand {
 	return c.flaggy
 }
 
+// validatesDrained returns true if the upgrade has to check that only static pods and pods
+// controlled by daemon-sets are running on the node. With --drain nodeadm evicts the pods itself
+// and with --cordon the user opted to upgrade with the pods running, so the check is skipped.
+func (c *command) validatesDrained() bool {
+	return !c.cordon && !c.drain && !slices.Contains(c.skipPhases, skipPodPreflightCheck)
+}
+
 func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	ctx := context.Background()
 	ctx = logger.NewContext(ctx, log)
+	ctx = cli.WithResult(ctx, opts.Result)
+	defer opts.Result.RecordInstalledArtifacts()
 
 	root, err := cli.IsRunningAsRoot()
 	if err != nil {
@@ -100,7 +141,7 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 
 	if c.configSource == "" {
-		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds]." +
+		flaggy.ShowHelpAndExit("--config-source is a required flag. The format is a URI with supported schemes: [file, imds, stdin]. Use - to read from standard input." +
 			" For example on hybrid nodes --config-source file://nodeConfig.yaml")
 	}
 
@@ -108,6 +149,8 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		return fmt.Errorf("--private-mode requires --manifest-override to be specified")
 	}
 
+	opts.Result.SkipPhases(c.skipPhases...)
+
 	log.Info("Loading installed components")
 	installed, err := tracker.GetInstalledArtifacts()
 	if err != nil && os.IsNotExist(err) {
@@ -128,7 +171,7 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 
 	log.Info("Loading configuration...", zap.String("configSource", c.configSource))
-	nodeProvider, err := node.NewNodeProvider(c.configSource, c.skipPhases, log)
+	nodeProvider, err := node.NewNodeProvider(c.configSource, c.configOverlay, c.skipPhases, log)
 	if err != nil {
 		return err
 	}
@@ -176,6 +219,31 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 		log.Info("Using Kubernetes version", zap.Reflect("kubernetes version", awsSource.Eks.Version))
 	}
 
+	bandwidthLimit, err := artifact.ParseBandwidthLimit(c.downloadBandwidthLimit)
+	if err != nil {
+		return err
+	}
+	awsSource = awsSource.WithDownloadLimits(c.downloadConcurrency, bandwidthLimit)
+	if c.artifactDir != "" {
+		log.Info("Using local artifacts", zap.String("dir", c.artifactDir))
+		if awsSource, err = awsSource.WithArtifactDir(c.artifactDir); err != nil {
+			return err
+		}
+	}
+
+	if c.force {
+		log.Warn("Skipping Kubernetes version compatibility validation with the cluster", zap.String("version", awsSource.Eks.Version))
+	} else {
+		log.Info("Validating Kubernetes version is compatible with the cluster...", zap.String("version", awsSource.Eks.Version))
+		awsConfig, err := creds.ReadConfigAsKubelet(ctx, nodeConfig)
+		if err != nil {
+			return err
+		}
+		if err := validateTargetVersion(ctx, eks.NewFromConfig(awsConfig), nodeConfig.Spec.Cluster.Name, awsSource.Eks.Version); err != nil {
+			return err
+		}
+	}
+
 	log.Info("Creating daemon manager...")
 	daemonManager, err := daemon.NewDaemonManager()
 	if err != nil {
@@ -183,13 +251,18 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 	}
 	defer daemonManager.Close()
 
+	kubeletRunning := false
 	if installed.Artifacts.Kubelet {
 		kubeletStatus, err := daemonManager.GetDaemonStatus(kubelet.KubeletDaemonName)
 		if err != nil {
 			return err
 		}
-		if kubeletStatus == daemon.DaemonStatusRunning {
-			if !slices.Contains(c.skipPhases, skipPodPreflightCheck) {
+		kubeletRunning = kubeletStatus == daemon.DaemonStatusRunning
+	}
+
+	upgrade := func(ctx context.Context) error {
+		if kubeletRunning {
+			if c.validatesDrained() {
 				log.Info("Validating if node has been drained...")
 				if drained, err := node.IsDrained(ctx); err != nil {
 					return fmt.Errorf("validating if node has been drained: %w", err)
@@ -205,30 +278,55 @@ func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
 				}
 			}
 		}
-	}
 
-	var packageManager *packagemanager.DistroPackageManager
-	if !c.privateMode {
-		log.Info("Creating package manager...")
-		containerdSource := installed.Artifacts.Containerd
-		log.Info("Configuring package manager with", zap.Reflect("containerd source", string(containerdSource)))
-		packageManager, err = packagemanager.New(containerdSource, log)
-		if err != nil {
-			return err
+		var packageManager *packagemanager.DistroPackageManager
+		if !c.privateMode {
+			log.Info("Creating package manager...")
+			containerdSource := installed.Artifacts.Containerd
+			log.Info("Configuring package manager with", zap.Reflect("containerd source", string(containerdSource)))
+			var err error
+			packageManager, err = packagemanager.New(containerdSource, system.HostOSInfo(), log)
+			if err != nil {
+				return err
+			}
+		}
+
+		upgrader := &flows.Upgrader{
+			NodeProvider:       nodeProvider,
+			AwsSource:          awsSource,
+			PackageManager:     packageManager,
+			CredentialProvider: credsProvider,
+			Artifacts:          installed.Artifacts,
+			DaemonManager:      daemonManager,
+			SkipPhases:         c.skipPhases,
+			Logger:             log,
+			PrivateMode:        c.privateMode,
 		}
+
+		return upgrader.Run(ctx)
 	}
 
-	upgrader := &flows.Upgrader{
-		NodeProvider:       nodeProvider,
-		AwsSource:          awsSource,
-		PackageManager:     packageManager,
-		CredentialProvider: credsProvider,
-		Artifacts:          installed.Artifacts,
-		DaemonManager:      daemonManager,
-		SkipPhases:         c.skipPhases,
-		Logger:             log,
-		PrivateMode:        c.privateMode,
+	if !kubeletRunning || (!c.cordon && !c.drain) {
+		return upgrade(ctx)
 	}
 
-	return upgrader.Run(ctx)
+	nodeName, err := kubelet.GetNodeName()
+	if err != nil {
+		return fmt.Errorf("getting node name from kubelet: %w", err)
+	}
+	client, err := hybrid.BuildKubeClient()
+	if err != nil {
+		return fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	return node.RunCordoned(ctx, client, nodeName, node.CordonOptions{Drain: c.drain, Logger: log}, func(ctx context.Context) error {
+		if err := upgrade(ctx); err != nil {
+			return err
+		}
+		// Only uncordon the node once it's back and ready after the upgrade.
+		log.Info("Validating node is ready after upgrade...")
+		return nodevalidator.NewActiveNodeValidator(
+			nodevalidator.WithReadinessStabilityPeriod(c.readinessStabilityPeriod),
+		).Run(ctx, validation.NewLoggerPrinterWithLogger(log), nodeConfig)
+	})
 }
@@ -0,0 +1,53 @@
+package upgrade
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCommandValidatesDrained(t *testing.T) {
+	tests := []struct {
+		name    string
+		command command
+		want    bool
+	}{
+		{
+			name:    "default",
+			command: command{},
+			want:    true,
+		},
+		{
+			name:    "skip pod validation",
+			command: command{skipPhases: []string{skipPodPreflightCheck}},
+			want:    false,
+		},
+		{
+			name:    "cordon keeps pods running",
+			command: command{cordon: true},
+			want:    false,
+		},
+		{
+			name:    "drain evicts pods",
+			command: command{drain: true},
+			want:    false,
+		},
+		{
+			name:    "cordon and drain",
+			command: command{cordon: true, drain: true},
+			want:    false,
+		},
+		{
+			name:    "skip other phases",
+			command: command{skipPhases: []string{skipNodePreflightCheck}},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tt.command.validatesDrained()).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/integrii/flaggy"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/nodevalidator"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const defaultValidationTimeout = 5 * time.Minute
+
+const validateHelpText = `Examples:
+  # Validate the node registered with the cluster and is ready
+  nodeadm validate
+
+  # Wait up to 15 minutes for the node to become ready, for example while the CNI is being applied
+  nodeadm validate --validation-timeout 15m
+
+Documentation:
+  https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-nodeadm.html`
+
+func NewCommand() cli.Command {
+	cmd := command{
+		validationTimeout: defaultValidationTimeout,
+	}
+	cmd.cmd = flaggy.NewSubcommand("validate")
+	cmd.cmd.Duration(&cmd.validationTimeout, "t", "validation-timeout", "Maximum time to wait for the node to register and become ready. Input follows duration format. Example: 10m")
+	cmd.cmd.String(&cmd.kubeconfig, "", "kubeconfig", "Path to the kubeconfig used to validate the node in the cluster. Defaults to the kubelet kubeconfig.")
+	cmd.cmd.String(&cmd.kubeconfigContext, "", "context", "Kubeconfig context used to validate the node in the cluster. Defaults to the kubeconfig current context.")
+	cmd.cmd.Description = "Validate this node registered with the cluster and is ready"
+	cmd.cmd.AdditionalHelpAppend = validateHelpText
+	return &cmd
+}
+
+type command struct {
+	cmd               *flaggy.Subcommand
+	validationTimeout time.Duration
+	kubeconfig        string
+	kubeconfigContext string
+}
+
+func (c *command) Flaggy() *flaggy.Subcommand {
+	return c.cmd
+}
+
+func (c *command) Run(log *zap.Logger, opts *cli.GlobalOptions) error {
+	ctx := context.Background()
+	ctx = logger.NewContext(ctx, log)
+
+	root, err := cli.IsRunningAsRoot()
+	if err != nil {
+		return err
+	} else if !root {
+		return cli.ErrMustRunAsRoot
+	}
+
+	if err := c.validateFlags(); err != nil {
+		return err
+	}
+
+	validator := nodevalidator.NewActiveNodeValidator(
+		nodevalidator.WithTimeout(c.validationTimeout),
+		nodevalidator.WithKubeconfig(c.kubeconfig, c.kubeconfigContext),
+	)
+	// Unlike init, a node that doesn't become ready fails the command, since validating it is the only thing it does.
+	result, err := validator.Execute(ctx, validation.NewLoggerPrinterWithLogger(log), &api.NodeConfig{})
+	fmt.Printf("Node: %s\n", valueOrNone(result.NodeName))
+	fmt.Printf("CNI: %s\n", valueOrNone(result.CNI))
+	if err != nil {
+		return fmt.Errorf("validating node: %w", err)
+	}
+	log.Info("Node is ready", zap.Duration("duration", result.Duration))
+	return nil
+}
+
+func (c *command) validateFlags() error {
+	if c.validationTimeout <= 0 {
+		return fmt.Errorf("--validation-timeout must be greater than 0, got %s", c.validationTimeout)
+	}
+	return nil
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/integrii/flaggy"
+	. "github.com/onsi/gomega"
+)
+
+func parse(args ...string) (*command, error) {
+	cmd := NewCommand().(*command)
+	parser := flaggy.NewParser("nodeadm")
+	parser.ShowHelpOnUnexpected = false
+	parser.AttachSubcommand(cmd.Flaggy(), 1)
+	return cmd, parser.ParseArgs(append([]string{"validate"}, args...))
+}
+
+func TestCommandFlags(t *testing.T) {
+	g := NewWithT(t)
+
+	cmd, err := parse()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cmd.validationTimeout).To(Equal(defaultValidationTimeout))
+	g.Expect(cmd.validateFlags()).To(Succeed())
+
+	cmd, err = parse("--validation-timeout", "15m", "--kubeconfig", "/root/.kube/config", "--context", "my-cluster")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cmd.validationTimeout).To(Equal(15 * time.Minute))
+	g.Expect(cmd.kubeconfig).To(Equal("/root/.kube/config"))
+	g.Expect(cmd.kubeconfigContext).To(Equal("my-cluster"))
+	g.Expect(cmd.validateFlags()).To(Succeed())
+
+	_, err = parse("--validation-timeout", "soon")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestCommandValidateFlagsTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	cmd, err := parse("-t", "0s")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cmd.validateFlags()).To(MatchError("--validation-timeout must be greater than 0, got 0s"))
+
+	cmd, err = parse("--validation-timeout", "-1m")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cmd.validateFlags()).To(MatchError("--validation-timeout must be greater than 0, got -1m0s"))
+}
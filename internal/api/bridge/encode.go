@@ -0,0 +1,25 @@
+package bridge
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aws/eks-hybrid/api"
+	"github.com/aws/eks-hybrid/api/v1alpha1"
+	internalapi "github.com/aws/eks-hybrid/internal/api"
+)
+
+// EncodeNodeConfig marshals the internal NodeConfig as a v1alpha1 NodeConfig YAML document,
+// the format users write it in. Fields only the internal NodeConfig has are dropped.
+func EncodeNodeConfig(nodeConfig *internalapi.NodeConfig) ([]byte, error) {
+	scheme := runtime.NewScheme()
+	if err := localSchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	var external v1alpha1.NodeConfig
+	if err := scheme.Convert(nodeConfig, &external, nil); err != nil {
+		return nil, err
+	}
+	external.SetGroupVersionKind(v1alpha1.GroupVersion.WithKind(api.KindNodeConfig))
+	return yaml.Marshal(&external)
+}
@@ -169,6 +169,8 @@ func Convert_api_ClusterDetails_To_v1alpha1_ClusterDetails(in *api.ClusterDetail
 
 func autoConvert_v1alpha1_ContainerdOptions_To_api_ContainerdOptions(in *v1alpha1.ContainerdOptions, out *api.ContainerdOptions, s conversion.Scope) error {
 	out.Config = in.Config
+	out.MaxConcurrentDownloads = in.MaxConcurrentDownloads
+	out.ImagePullProgressTimeout = in.ImagePullProgressTimeout
 	return nil
 }
 
@@ -179,6 +181,8 @@ func Convert_v1alpha1_ContainerdOptions_To_api_ContainerdOptions(in *v1alpha1.Co
 
 func autoConvert_api_ContainerdOptions_To_v1alpha1_ContainerdOptions(in *api.ContainerdOptions, out *v1alpha1.ContainerdOptions, s conversion.Scope) error {
 	out.Config = in.Config
+	out.MaxConcurrentDownloads = in.MaxConcurrentDownloads
+	out.ImagePullProgressTimeout = in.ImagePullProgressTimeout
 	return nil
 }
 
@@ -219,6 +223,7 @@ func autoConvert_v1alpha1_IAMRolesAnywhere_To_api_IAMRolesAnywhere(in *v1alpha1.
 	out.AwsConfigPath = in.AwsConfigPath
 	out.CertificatePath = in.CertificatePath
 	out.PrivateKeyPath = in.PrivateKeyPath
+	out.SessionDuration = in.SessionDuration
 	return nil
 }
 
@@ -235,6 +240,7 @@ func autoConvert_api_IAMRolesAnywhere_To_v1alpha1_IAMRolesAnywhere(in *api.IAMRo
 	out.AwsConfigPath = in.AwsConfigPath
 	out.CertificatePath = in.CertificatePath
 	out.PrivateKeyPath = in.PrivateKeyPath
+	out.SessionDuration = in.SessionDuration
 	return nil
 }
 
@@ -270,6 +276,8 @@ func Convert_api_InstanceOptions_To_v1alpha1_InstanceOptions(in *api.InstanceOpt
 func autoConvert_v1alpha1_KubeletOptions_To_api_KubeletOptions(in *v1alpha1.KubeletOptions, out *api.KubeletOptions, s conversion.Scope) error {
 	out.Config = *(*api.InlineDocument)(unsafe.Pointer(&in.Config))
 	out.Flags = *(*[]string)(unsafe.Pointer(&in.Flags))
+	out.ImageServiceEndpoint = in.ImageServiceEndpoint
+	out.NodeIPInterface = in.NodeIPInterface
 	return nil
 }
 
@@ -281,6 +289,8 @@ func Convert_v1alpha1_KubeletOptions_To_api_KubeletOptions(in *v1alpha1.KubeletO
 func autoConvert_api_KubeletOptions_To_v1alpha1_KubeletOptions(in *api.KubeletOptions, out *v1alpha1.KubeletOptions, s conversion.Scope) error {
 	out.Config = *(*map[string]runtime.RawExtension)(unsafe.Pointer(&in.Config))
 	out.Flags = *(*[]string)(unsafe.Pointer(&in.Flags))
+	out.ImageServiceEndpoint = in.ImageServiceEndpoint
+	out.NodeIPInterface = in.NodeIPInterface
 	return nil
 }
 
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"regexp"
+)
+
+// RedactedValue replaces the sensitive values of a redacted NodeConfig.
+const RedactedValue = "<redacted>"
+
+// containerdSecretRegex matches containerd config lines that set registry credentials, like
+// password = "..." under a registry auth section, capturing the key and separator.
+var containerdSecretRegex = regexp.MustCompile(`(?im)^(\s*"?(?:password|auth|identitytoken|token)"?\s*=\s*).*$`)
+
+// Redacted returns a copy of the NodeConfig with the credential-bearing fields masked, so it
+// can be printed or added to diagnostics. The NodeConfig itself is not modified.
+func (nc *NodeConfig) Redacted() *NodeConfig {
+	redacted := nc.DeepCopy()
+	if hybrid := redacted.Spec.Hybrid; hybrid != nil {
+		if ssm := hybrid.SSM; ssm != nil {
+			ssm.ActivationCode = redact(ssm.ActivationCode)
+			ssm.ActivationID = redact(ssm.ActivationID)
+		}
+		if iamra := hybrid.IAMRolesAnywhere; iamra != nil {
+			iamra.TrustAnchorARN = redact(iamra.TrustAnchorARN)
+			iamra.ProfileARN = redact(iamra.ProfileARN)
+			iamra.RoleARN = redact(iamra.RoleARN)
+		}
+	}
+	redacted.Spec.Containerd.Config = containerdSecretRegex.ReplaceAllString(redacted.Spec.Containerd.Config, `${1}"`+RedactedValue+`"`)
+	return redacted
+}
+
+// redact masks value, leaving unset values empty so they are still omitted when printed.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return RedactedValue
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedacted(t *testing.T) {
+	containerdConfig := `[plugins."io.containerd.grpc.v1.cri".registry.configs."registry.example.com".auth]
+  username = "admin"
+  password = "hunter2"
+  auth = "YWRtaW46aHVudGVyMg=="
+[plugins."io.containerd.grpc.v1.cri".containerd]
+  discard_unpacked_layers = true`
+	nodeConfig := &NodeConfig{
+		Spec: NodeConfigSpec{
+			Cluster: ClusterDetails{
+				Name:   "my-cluster",
+				Region: "us-west-2",
+			},
+			Containerd: ContainerdOptions{Config: containerdConfig},
+			Kubelet: KubeletOptions{
+				Flags: []string{"--node-labels=abc=xyz"},
+			},
+			Hybrid: &HybridOptions{
+				SSM: &SSM{
+					ActivationCode: "activation-code",
+					ActivationID:   "activation-id",
+				},
+				IAMRolesAnywhere: &IAMRolesAnywhere{
+					NodeName:        "my-node",
+					TrustAnchorARN:  "arn:aws:rolesanywhere:us-west-2:123456789012:trust-anchor/abc",
+					ProfileARN:      "arn:aws:rolesanywhere:us-west-2:123456789012:profile/abc",
+					RoleARN:         "arn:aws:iam::123456789012:role/hybrid-node",
+					CertificatePath: "/etc/iam/pki/server.pem",
+				},
+			},
+		},
+	}
+	original := nodeConfig.DeepCopy()
+
+	redacted := nodeConfig.Redacted()
+
+	assert.Equal(t, &SSM{ActivationCode: RedactedValue, ActivationID: RedactedValue}, redacted.Spec.Hybrid.SSM)
+	assert.Equal(t, &IAMRolesAnywhere{
+		NodeName:        "my-node",
+		TrustAnchorARN:  RedactedValue,
+		ProfileARN:      RedactedValue,
+		RoleARN:         RedactedValue,
+		CertificatePath: "/etc/iam/pki/server.pem",
+	}, redacted.Spec.Hybrid.IAMRolesAnywhere)
+	assert.Equal(t, `[plugins."io.containerd.grpc.v1.cri".registry.configs."registry.example.com".auth]
+  username = "admin"
+  password = "<redacted>"
+  auth = "<redacted>"
+[plugins."io.containerd.grpc.v1.cri".containerd]
+  discard_unpacked_layers = true`, redacted.Spec.Containerd.Config)
+	assert.Equal(t, original.Spec.Cluster, redacted.Spec.Cluster)
+	assert.Equal(t, original.Spec.Kubelet, redacted.Spec.Kubelet)
+	assert.Equal(t, original, nodeConfig, "the original config shouldn't be modified")
+}
+
+func TestRedactedLeavesUnsetFieldsEmpty(t *testing.T) {
+	nodeConfig := &NodeConfig{
+		Spec: NodeConfigSpec{
+			Hybrid: &HybridOptions{
+				IAMRolesAnywhere: &IAMRolesAnywhere{RoleARN: "arn:aws:iam::123456789012:role/hybrid-node"},
+			},
+		},
+	}
+
+	redacted := nodeConfig.Redacted()
+
+	assert.Nil(t, redacted.Spec.Hybrid.SSM)
+	assert.Equal(t, &IAMRolesAnywhere{RoleARN: RedactedValue}, redacted.Spec.Hybrid.IAMRolesAnywhere)
+}
@@ -77,6 +77,11 @@ type KubeletOptions struct {
 	// amended to the generated defaults, and therefore will act as overrides
 	// https://kubernetes.io/docs/reference/command-line-tools-reference/kubelet/
 	Flags []string `json:"flags,omitempty"`
+	// ImageServiceEndpoint is the CRI image service endpoint kubelet pulls images through.
+	// Empty uses the container runtime endpoint.
+	ImageServiceEndpoint string `json:"imageServiceEndpoint,omitempty"`
+	// NodeIPInterface is the network interface the node IP is taken from on multi-homed hybrid nodes.
+	NodeIPInterface string `json:"nodeIPInterface,omitempty"`
 }
 
 // InlineDocument is an alias to a dynamically typed map. This allows using
@@ -88,6 +93,10 @@ type ContainerdOptions struct {
 	// by the user to override default generated configurations
 	// https://github.com/containerd/containerd/blob/main/docs/man/containerd-config.toml.5.md
 	Config string `json:"config,omitempty"`
+	// MaxConcurrentDownloads is the maximum number of image layers downloaded at the same time for each pull.
+	MaxConcurrentDownloads int32 `json:"maxConcurrentDownloads,omitempty"`
+	// ImagePullProgressTimeout is how long a pull can go without progress before it's canceled.
+	ImagePullProgressTimeout string `json:"imagePullProgressTimeout,omitempty"`
 }
 
 type IPFamily string
@@ -163,6 +172,7 @@ type IAMRolesAnywhere struct {
 	AwsConfigPath   string `json:"awsConfigPath,omitempty"`
 	CertificatePath string `json:"certificatePath,omitempty"`
 	PrivateKeyPath  string `json:"privateKeyPath,omitempty"`
+	SessionDuration int32  `json:"sessionDuration,omitempty"`
 }
 
 type SSM struct {
@@ -2,42 +2,200 @@ package artifact
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
 	"fmt"
+	"hash"
 	"io"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
+
+	"github.com/aws/eks-hybrid/internal/retry"
 )
 
 // DefaultDirPerms are the permissions assigned to a directory when an Install* func is called
 // and it has to create the parent directories for the destination.
 const DefaultDirPerms = fs.ModeDir | 0o755
 
+// FileSystem is the set of filesystem operations used to install files.
+type FileSystem interface {
+	RemoveAll(path string) error
+	MkdirAll(path string, perm fs.FileMode) error
+	OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error)
+}
+
+type osFileSystem struct{}
+
+func (osFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (osFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (osFileSystem) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+type installOptions struct {
+	fs       FileSystem
+	attempts int
+	backoff  time.Duration
+	// digest, when set, is fed everything written to the file.
+	digest hash.Hash
+}
+
+// InstallOption configures InstallFile.
+type InstallOption func(*installOptions)
+
+// WithFileSystem sets the filesystem used to install the file.
+func WithFileSystem(fs FileSystem) InstallOption {
+	return func(o *installOptions) {
+		o.fs = fs
+	}
+}
+
+// WithAttempts sets the maximum number of attempts when the filesystem returns transient errors.
+func WithAttempts(attempts int) InstallOption {
+	return func(o *installOptions) {
+		o.attempts = attempts
+	}
+}
+
+// WithBackoff sets the initial wait between attempts. It doubles after each attempt.
+func WithBackoff(backoff time.Duration) InstallOption {
+	return func(o *installOptions) {
+		o.backoff = backoff
+	}
+}
+
 // InstallFile installs src to dst with perms permissions. It ensures any base paths exist
 // before installing.
-func InstallFile(dst string, src io.Reader, perms fs.FileMode) error {
-	if err := os.RemoveAll(dst); err != nil {
+// Transient filesystem errors, common on network filesystems, are retried with backoff.
+// If src was partially consumed, the copy is only retried when src is an io.Seeker.
+func InstallFile(dst string, src io.Reader, perms fs.FileMode, opts ...InstallOption) error {
+	return installFileWithOptions(dst, src, perms, newInstallOptions(opts...))
+}
+
+// InstallFileWithChecksum installs src to dst like InstallFile, computing the sha256 of the data
+// while it's copied. If it doesn't match expectedSHA256, the installed file is removed and a
+// ChecksumError is returned, so a corrupted download never stays at dst.
+func InstallFileWithChecksum(dst string, src io.Reader, perms fs.FileMode, expectedSHA256 []byte, opts ...InstallOption) error {
+	if len(expectedSHA256) == 0 {
+		return fmt.Errorf("installing %s: no expected checksum", dst)
+	}
+	o := newInstallOptions(opts...)
+	o.digest = sha256.New()
+
+	if err := installFileWithOptions(dst, src, perms, o); err != nil {
+		_ = o.fs.RemoveAll(dst)
 		return err
 	}
-	if err := os.MkdirAll(path.Dir(dst), DefaultDirPerms); err != nil {
+	if actual := o.digest.Sum(nil); !bytes.Equal(actual, expectedSHA256) {
+		if err := o.fs.RemoveAll(dst); err != nil {
+			return fmt.Errorf("removing %s after checksum mismatch: %w", dst, err)
+		}
+		return ChecksumError{Expect: expectedSHA256, Actual: actual}
+	}
+	return nil
+}
+
+func newInstallOptions(opts ...InstallOption) *installOptions {
+	o := &installOptions{
+		fs:       osFileSystem{},
+		attempts: 3,
+		backoff:  100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func installFileWithOptions(dst string, src io.Reader, perms fs.FileMode, o *installOptions) error {
+	srcRead := false
+	retrier := retry.Retrier{
+		HandleError: func(err error) error {
+			if err == nil {
+				return nil
+			}
+			if !isTransientFSError(err) {
+				return err
+			}
+			// The error might come from src itself, so once src has been partially read
+			// and can't be rewound, surface the original error instead of retrying.
+			if _, seekable := src.(io.Seeker); srcRead && !seekable {
+				return errors.Wrapf(err, "installing %s", dst)
+			}
+			return nil
+		},
+		Backoff: retry.Backoff{
+			Duration: o.backoff,
+			Factor:   2,
+			Steps:    max(o.attempts, 1),
+		},
+	}
+
+	return retrier.Do(context.Background(), func(context.Context) (bool, error) {
+		if seeker, ok := src.(io.Seeker); ok && srcRead {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return false, fmt.Errorf("rewinding source for %s: %w", dst, err)
+			}
+		}
+		if err := installFile(o.fs, dst, src, perms, o.digest, &srcRead); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+func installFile(fsys FileSystem, dst string, src io.Reader, perms fs.FileMode, digest hash.Hash, srcRead *bool) error {
+	if err := fsys.RemoveAll(dst); err != nil {
+		return err
+	}
+	if err := fsys.MkdirAll(path.Dir(dst), DefaultDirPerms); err != nil {
 		return err
 	}
 
-	fh, err := os.OpenFile(dst, os.O_CREATE|os.O_RDWR|os.O_TRUNC, perms)
+	fh, err := fsys.OpenFile(dst, os.O_CREATE|os.O_RDWR|os.O_TRUNC, perms)
 	if err != nil {
 		return err
 	}
 	defer fh.Close()
 
-	_, err = io.Copy(fh, src)
+	var w io.Writer = fh
+	if digest != nil {
+		// A retried copy starts over from the beginning of src.
+		digest.Reset()
+		w = io.MultiWriter(fh, digest)
+	}
+
+	*srcRead = true
+	_, err = io.Copy(w, src)
 	return err
 }
 
+// isTransientFSError returns true for errors that might go away on retry, like the ones
+// returned by busy or network filesystems. Errors like ENOSPC or EROFS are permanent.
+func isTransientFSError(err error) bool {
+	for _, errno := range []syscall.Errno{syscall.ESTALE, syscall.EBUSY, syscall.EAGAIN, syscall.EINTR, syscall.ETIMEDOUT} {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
 // InstallTarGz untars the src file into the dst directory and deletes the src tgz file
 func InstallTarGz(dst, src string) error {
 	if err := os.MkdirAll(dst, DefaultDirPerms); err != nil {
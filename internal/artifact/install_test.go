@@ -4,10 +4,15 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"syscall"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -87,6 +92,218 @@ func TestInstallFile_DirNotExists(t *testing.T) {
 	}
 }
 
+// flakyFileSystem fails the first calls to each operation with the configured errors.
+type flakyFileSystem struct {
+	openErrs  []error
+	copyErrs  []error
+	openCalls int
+}
+
+func (f *flakyFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (f *flakyFileSystem) MkdirAll(path string, perm fs.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+func (f *flakyFileSystem) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	f.openCalls++
+	if len(f.openErrs) > 0 {
+		err := f.openErrs[0]
+		f.openErrs = f.openErrs[1:]
+		return nil, err
+	}
+	fh, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.copyErrs) > 0 {
+		err := f.copyErrs[0]
+		f.copyErrs = f.copyErrs[1:]
+		return failingWriter{WriteCloser: fh, err: err}, nil
+	}
+	return fh, nil
+}
+
+type failingWriter struct {
+	io.WriteCloser
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestInstallFile_Retries(t *testing.T) {
+	pathErr := func(errno syscall.Errno) error {
+		return &fs.PathError{Op: "open", Path: "file", Err: errno}
+	}
+
+	tests := []struct {
+		name          string
+		fs            *flakyFileSystem
+		src           func() io.Reader
+		wantErr       string
+		wantErrIs     error
+		wantOpenCalls int
+	}{
+		{
+			name:          "transient errors then success",
+			fs:            &flakyFileSystem{openErrs: []error{pathErr(syscall.ESTALE), pathErr(syscall.EBUSY)}},
+			wantOpenCalls: 3,
+		},
+		{
+			name:          "permanent error fails fast",
+			fs:            &flakyFileSystem{openErrs: []error{pathErr(syscall.EROFS)}},
+			wantErr:       "read-only file system",
+			wantOpenCalls: 1,
+		},
+		{
+			name:          "no space fails fast",
+			fs:            &flakyFileSystem{openErrs: []error{pathErr(syscall.ENOSPC)}},
+			wantErr:       "no space left on device",
+			wantOpenCalls: 1,
+		},
+		{
+			name:          "transient errors exhaust attempts",
+			fs:            &flakyFileSystem{openErrs: []error{pathErr(syscall.ESTALE), pathErr(syscall.ESTALE), pathErr(syscall.ESTALE)}},
+			wantErr:       "stale file handle",
+			wantOpenCalls: 3,
+		},
+		{
+			name:          "copy retried rewinding a seekable source",
+			fs:            &flakyFileSystem{copyErrs: []error{syscall.EAGAIN}},
+			src:           func() io.Reader { return bytes.NewReader([]byte("hello, world!")) },
+			wantOpenCalls: 2,
+		},
+		{
+			name:          "copy not retried with a non seekable source",
+			fs:            &flakyFileSystem{copyErrs: []error{syscall.EAGAIN}},
+			wantErr:       "resource temporarily unavailable",
+			wantErrIs:     syscall.EAGAIN,
+			wantOpenCalls: 1,
+		},
+		{
+			name:          "source read error surfaced",
+			fs:            &flakyFileSystem{},
+			src:           func() io.Reader { return failingReader{err: syscall.ETIMEDOUT} },
+			wantErr:       "connection timed out",
+			wantErrIs:     syscall.ETIMEDOUT,
+			wantOpenCalls: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dst := filepath.Join(t.TempDir(), "dir", "file")
+			var src io.Reader = bytes.NewBufferString("hello, world!")
+			if tc.src != nil {
+				src = tc.src()
+			}
+
+			err := artifact.InstallFile(dst, src, 0o644,
+				artifact.WithFileSystem(tc.fs),
+				artifact.WithAttempts(3),
+				artifact.WithBackoff(time.Millisecond),
+			)
+			g.Expect(tc.fs.openCalls).To(Equal(tc.wantOpenCalls))
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				if tc.wantErrIs != nil {
+					g.Expect(errors.Is(err, tc.wantErrIs)).To(BeTrue())
+				}
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(os.ReadFile(dst)).To(Equal([]byte("hello, world!")))
+		})
+	}
+}
+
+func TestInstallFileWithChecksum(t *testing.T) {
+	data := []byte("hello, world!")
+	checksum := sha256.Sum256(data)
+
+	tests := []struct {
+		name     string
+		src      func() io.Reader
+		fs       *flakyFileSystem
+		expected []byte
+		wantErr  string
+	}{
+		{
+			name:     "matching checksum",
+			expected: checksum[:],
+		},
+		{
+			name:     "matching checksum after a retried copy",
+			src:      func() io.Reader { return bytes.NewReader(data) },
+			fs:       &flakyFileSystem{copyErrs: []error{syscall.EAGAIN}},
+			expected: checksum[:],
+		},
+		{
+			name:     "mismatching checksum",
+			expected: []byte("not the checksum"),
+			wantErr:  "checksum mismatch",
+		},
+		{
+			name:     "partial source",
+			src:      func() io.Reader { return io.MultiReader(bytes.NewReader(data[:5]), failingReader{err: syscall.EIO}) },
+			expected: checksum[:],
+			wantErr:  "input/output error",
+		},
+		{
+			name:    "no expected checksum",
+			wantErr: "no expected checksum",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dst := filepath.Join(t.TempDir(), "file")
+			var src io.Reader = bytes.NewBuffer(data)
+			if tc.src != nil {
+				src = tc.src()
+			}
+			fsys := tc.fs
+			if fsys == nil {
+				fsys = &flakyFileSystem{}
+			}
+
+			err := artifact.InstallFileWithChecksum(dst, src, 0o644, tc.expected,
+				artifact.WithFileSystem(fsys),
+				artifact.WithBackoff(time.Millisecond),
+			)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				g.Expect(dst).NotTo(BeAnExistingFile(), "the partial file should be removed")
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(os.ReadFile(dst)).To(Equal(data))
+		})
+	}
+}
+
+func TestInstallFileWithChecksumMismatchIsChecksumError(t *testing.T) {
+	g := NewWithT(t)
+	dst := filepath.Join(t.TempDir(), "file")
+
+	err := artifact.InstallFileWithChecksum(dst, bytes.NewBufferString("corrupted"), 0o644, []byte("expected"))
+	g.Expect(errors.Is(err, artifact.ChecksumError{})).To(BeTrue())
+}
+
 func tarGzBytes(t *testing.T, files map[string]struct {
 	content string
 	mode    int64
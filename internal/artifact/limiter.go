@@ -0,0 +1,112 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DownloadLimiter bounds the number of concurrent downloads and the bandwidth used by each of them.
+// A nil DownloadLimiter doesn't limit anything.
+type DownloadLimiter struct {
+	slots          chan struct{}
+	bytesPerSecond int
+}
+
+// NewDownloadLimiter returns a DownloadLimiter. A concurrency or bytesPerSecond
+// less than or equal to zero means unlimited.
+func NewDownloadLimiter(concurrency, bytesPerSecond int) *DownloadLimiter {
+	l := &DownloadLimiter{bytesPerSecond: bytesPerSecond}
+	if concurrency > 0 {
+		l.slots = make(chan struct{}, concurrency)
+	}
+	return l
+}
+
+// Acquire blocks until a download slot is available or ctx is done.
+// The returned release func must be called once the download is finished.
+func (l *DownloadLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil || l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-l.slots }) }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for download slot: %w", ctx.Err())
+	}
+}
+
+// Wrap returns a ReadCloser that reads from rc at the configured bandwidth
+// and calls release when closed.
+func (l *DownloadLimiter) Wrap(ctx context.Context, rc io.ReadCloser, release func()) io.ReadCloser {
+	var r io.Reader = rc
+	if l != nil && l.bytesPerSecond > 0 {
+		r = NewRateLimitedReader(ctx, rc, l.bytesPerSecond)
+	}
+	return &releaseReadCloser{Reader: r, closer: rc, release: release}
+}
+
+type releaseReadCloser struct {
+	io.Reader
+	closer  io.Closer
+	release func()
+}
+
+func (r *releaseReadCloser) Close() error {
+	defer r.release()
+	return r.closer.Close()
+}
+
+// NewRateLimitedReader returns a reader that reads from r at most bytesPerSecond.
+func NewRateLimitedReader(ctx context.Context, r io.Reader, bytesPerSecond int) io.Reader {
+	// Allow bursts of a tenth of a second worth of data, which also bounds
+	// the size of each read to keep the throughput smooth.
+	burst := max(bytesPerSecond/10, 1)
+	return &rateLimitedReader{
+		ctx:     ctx,
+		reader:  r,
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), burst),
+	}
+}
+
+type rateLimitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *rate.Limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.Burst() {
+		p = p[:r.limiter.Burst()]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// ParseBandwidthLimit parses a bandwidth limit in bytes per second expressed as
+// a quantity, like 500Ki or 10M. An empty string means unlimited and returns 0.
+func ParseBandwidthLimit(limit string) (int, error) {
+	if limit == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(limit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth limit %q: %w", limit, err)
+	}
+	bytes, ok := q.AsInt64()
+	if !ok || bytes <= 0 {
+		return 0, fmt.Errorf("invalid bandwidth limit %q: must be a positive number of bytes per second", limit)
+	}
+	return int(bytes), nil
+}
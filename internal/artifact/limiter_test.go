@@ -0,0 +1,123 @@
+package artifact_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+func TestRateLimitedReaderThrottles(t *testing.T) {
+	g := NewWithT(t)
+	data := bytes.Repeat([]byte("a"), 5000)
+
+	start := time.Now()
+	// 10000 B/s allows a 1000 bytes burst, so the remaining 4000 bytes take at least 400ms.
+	read, err := io.ReadAll(artifact.NewRateLimitedReader(context.Background(), bytes.NewReader(data), 10000))
+	elapsed := time.Since(start)
+
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(read).To(Equal(data))
+	g.Expect(elapsed).To(BeNumerically(">=", 350*time.Millisecond))
+	g.Expect(elapsed).To(BeNumerically("<", 2*time.Second))
+}
+
+func TestRateLimitedReaderContextCancelled(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := io.ReadAll(artifact.NewRateLimitedReader(ctx, bytes.NewReader(make([]byte, 5000)), 1000))
+	g.Expect(err).To(MatchError(context.Canceled))
+}
+
+func TestDownloadLimiterBoundsConcurrency(t *testing.T) {
+	g := NewWithT(t)
+	limiter := artifact.NewDownloadLimiter(2, 0)
+
+	var inFlight, maxInFlight atomic.Int32
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background())
+			g.Expect(err).NotTo(HaveOccurred())
+			rc := limiter.Wrap(context.Background(), io.NopCloser(bytes.NewReader([]byte("data"))), release)
+
+			current := inFlight.Add(1)
+			for {
+				prev := maxInFlight.Load()
+				if current <= prev || maxInFlight.CompareAndSwap(prev, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			inFlight.Add(-1)
+
+			g.Expect(rc.Close()).To(Succeed())
+			// Closing twice must not release the slot twice.
+			g.Expect(rc.Close()).To(Succeed())
+		}()
+	}
+	wg.Wait()
+
+	g.Expect(maxInFlight.Load()).To(BeEquivalentTo(2))
+}
+
+func TestDownloadLimiterAcquireContextDone(t *testing.T) {
+	g := NewWithT(t)
+	limiter := artifact.NewDownloadLimiter(1, 0)
+	_, err := limiter.Acquire(context.Background())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(ctx)
+	g.Expect(err).To(MatchError(context.DeadlineExceeded))
+}
+
+func TestNilDownloadLimiterIsUnlimited(t *testing.T) {
+	g := NewWithT(t)
+	var limiter *artifact.DownloadLimiter
+	release, err := limiter.Acquire(context.Background())
+	g.Expect(err).NotTo(HaveOccurred())
+
+	rc := limiter.Wrap(context.Background(), io.NopCloser(bytes.NewReader([]byte("data"))), release)
+	g.Expect(io.ReadAll(rc)).To(Equal([]byte("data")))
+	g.Expect(rc.Close()).To(Succeed())
+}
+
+func TestParseBandwidthLimit(t *testing.T) {
+	testCases := []struct {
+		limit   string
+		want    int
+		wantErr string
+	}{
+		{limit: "", want: 0},
+		{limit: "1000", want: 1000},
+		{limit: "500Ki", want: 500 * 1024},
+		{limit: "10M", want: 10_000_000},
+		{limit: "0", wantErr: "must be a positive number"},
+		{limit: "fast", wantErr: `invalid bandwidth limit "fast"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.limit, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := artifact.ParseBandwidthLimit(tc.limit)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
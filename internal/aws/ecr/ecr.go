@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"regexp"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,6 +27,15 @@ func GetAuthorizationToken(awsConfig *aws.Config) (string, error) {
 	return *authData, nil
 }
 
+// ecrRegistryRegex matches the ECR registry hosts, the same ones the ECR image credential provider serves.
+var ecrRegistryRegex = regexp.MustCompile(`^[0-9]+\.dkr\.ecr(-fips)?\.[a-z0-9-]+\.(amazonaws\.com(\.cn)?|c2s\.ic\.gov|sc2s\.sgov\.gov)$`)
+
+// IsECRImage reports if the registry of image is an ECR registry.
+func IsECRImage(image string) bool {
+	registry, _, _ := strings.Cut(image, "/")
+	return ecrRegistryRegex.MatchString(registry)
+}
+
 func (r *ECRRegistry) GetSandboxImage() string {
 	return r.GetImageReference("eks/pause", "3.5")
 }
@@ -176,3 +176,25 @@ func TestGetEKSRegistryCoordinates(t *testing.T) {
 		})
 	}
 }
+
+func TestIsECRImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{image: "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5", want: true},
+		{image: "961992271922.dkr.ecr.cn-northwest-1.amazonaws.com.cn/eks/pause:3.5", want: true},
+		{image: "602401143452.dkr.ecr-fips.us-east-1.amazonaws.com/eks/pause:3.5", want: true},
+		{image: "725322719131.dkr.ecr.us-iso-east-1.c2s.ic.gov/eks/pause:3.5", want: true},
+		{image: "registry.example.com/eks/pause:3.10", want: false},
+		{image: "registry.k8s.io/pause:3.10", want: false},
+		{image: "public.ecr.aws/eks-distro/kubernetes/pause:3.10", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := IsECRImage(tt.image); got != tt.want {
+				t.Errorf("IsECRImage(%q) = %v, want %v", tt.image, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+// AccessEntryClient is the subset of the EKS API used to manage access entries.
+type AccessEntryClient interface {
+	ListAccessEntries(ctx context.Context, params *eks.ListAccessEntriesInput, optFns ...func(*eks.Options)) (*eks.ListAccessEntriesOutput, error)
+	DeleteAccessEntry(ctx context.Context, params *eks.DeleteAccessEntryInput, optFns ...func(*eks.Options)) (*eks.DeleteAccessEntryOutput, error)
+}
+
+// ListAccessEntries returns the principal ARNs of all the access entries of the cluster.
+func ListAccessEntries(ctx context.Context, client AccessEntryClient, clusterName string) ([]string, error) {
+	accessEntries := []string{}
+	var nextToken *string
+
+	for {
+		output, err := client.ListAccessEntries(ctx, &eks.ListAccessEntriesInput{
+			ClusterName: aws.String(clusterName),
+			NextToken:   nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list access entries: %w", err)
+		}
+
+		accessEntries = append(accessEntries, output.AccessEntries...)
+
+		if aws.ToString(output.NextToken) == "" {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return accessEntries, nil
+}
+
+// DeleteRoleAccessEntry deletes the access entry of the IAM role roleName from the cluster.
+// It returns the deleted principal ARN, or an empty string if the cluster has no access entry for the role.
+func DeleteRoleAccessEntry(ctx context.Context, client AccessEntryClient, clusterName, roleName string) (string, error) {
+	accessEntries, err := ListAccessEntries(ctx, client, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	for _, principalARN := range accessEntries {
+		parsed, err := arn.Parse(principalARN)
+		if err != nil {
+			continue
+		}
+		if name, ok := RoleNameFromARN(parsed); !ok || name != roleName || parsed.Service != "iam" {
+			continue
+		}
+
+		_, err = client.DeleteAccessEntry(ctx, &eks.DeleteAccessEntryInput{
+			ClusterName:  aws.String(clusterName),
+			PrincipalArn: aws.String(principalARN),
+		})
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("deleting access entry %s: %w", principalARN, err)
+		}
+		return principalARN, nil
+	}
+
+	return "", nil
+}
+
+// RoleNameFromARN extracts the role name from an IAM role or an STS assumed role ARN.
+// Returns the role name and a boolean indicating if extraction was successful
+func RoleNameFromARN(parsedARN arn.ARN) (string, bool) {
+	splitArn := strings.Split(parsedARN.Resource, "/")
+
+	// Handle assumed role ARN format: arn:aws:sts::123456789012:assumed-role/RoleName/session
+	if parsedARN.Service == "sts" && strings.HasPrefix(parsedARN.Resource, "assumed-role") && len(splitArn) >= 2 {
+		return splitArn[1], true
+	}
+
+	// Handle IAM role ARN format: arn:aws:iam::123456789012:role/RoleName
+	if parsedARN.Service == "iam" && strings.HasPrefix(parsedARN.Resource, "role") && len(splitArn) >= 2 {
+		return splitArn[len(splitArn)-1], true
+	}
+
+	return "", false
+}
@@ -0,0 +1,113 @@
+package eks_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	ekssdk "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+)
+
+type fakeAccessEntryClient struct {
+	pages     [][]string
+	deleteErr error
+	deleted   []string
+}
+
+func (f *fakeAccessEntryClient) ListAccessEntries(_ context.Context, params *ekssdk.ListAccessEntriesInput, _ ...func(*ekssdk.Options)) (*ekssdk.ListAccessEntriesOutput, error) {
+	page := 0
+	if params.NextToken != nil {
+		page, _ = strconv.Atoi(*params.NextToken)
+	}
+	output := &ekssdk.ListAccessEntriesOutput{AccessEntries: f.pages[page]}
+	if page+1 < len(f.pages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func (f *fakeAccessEntryClient) DeleteAccessEntry(_ context.Context, params *ekssdk.DeleteAccessEntryInput, _ ...func(*ekssdk.Options)) (*ekssdk.DeleteAccessEntryOutput, error) {
+	if f.deleteErr != nil {
+		return nil, f.deleteErr
+	}
+	f.deleted = append(f.deleted, *params.PrincipalArn)
+	return &ekssdk.DeleteAccessEntryOutput{}, nil
+}
+
+func TestDeleteRoleAccessEntry(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pages       [][]string
+		deleteErr   error
+		wantDeleted string
+		wantErr     string
+	}{
+		{
+			name: "present on second page",
+			pages: [][]string{
+				{"arn:aws:iam::123456789012:role/admin"},
+				{"arn:aws:iam::123456789012:role/nodes/HybridNodesRole"},
+			},
+			wantDeleted: "arn:aws:iam::123456789012:role/nodes/HybridNodesRole",
+		},
+		{
+			name:  "absent",
+			pages: [][]string{{"arn:aws:iam::123456789012:role/admin", "arn:aws:iam::123456789012:role/HybridNodesRoleOld"}},
+		},
+		{
+			name:      "deleted concurrently",
+			pages:     [][]string{{"arn:aws:iam::123456789012:role/HybridNodesRole"}},
+			deleteErr: &types.ResourceNotFoundException{},
+		},
+		{
+			name:      "delete fails",
+			pages:     [][]string{{"arn:aws:iam::123456789012:role/HybridNodesRole"}},
+			deleteErr: errors.New("access denied"),
+			wantErr:   "deleting access entry arn:aws:iam::123456789012:role/HybridNodesRole: access denied",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client := &fakeAccessEntryClient{pages: tc.pages, deleteErr: tc.deleteErr}
+
+			deleted, err := eks.DeleteRoleAccessEntry(context.Background(), client, "my-cluster", "HybridNodesRole")
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(tc.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(deleted).To(Equal(tc.wantDeleted))
+			if tc.wantDeleted != "" {
+				g.Expect(client.deleted).To(ConsistOf(tc.wantDeleted))
+			}
+		})
+	}
+}
+
+func TestRoleNameFromARN(t *testing.T) {
+	g := NewWithT(t)
+	for raw, want := range map[string]string{
+		"arn:aws:sts::123456789012:assumed-role/HybridNodesRole/mi-0123": "HybridNodesRole",
+		"arn:aws:iam::123456789012:role/path/HybridNodesRole":            "HybridNodesRole",
+	} {
+		parsed, err := arn.Parse(raw)
+		g.Expect(err).NotTo(HaveOccurred())
+		name, ok := eks.RoleNameFromARN(parsed)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(name).To(Equal(want))
+	}
+
+	parsed, err := arn.Parse("arn:aws:iam::123456789012:user/admin")
+	g.Expect(err).NotTo(HaveOccurred())
+	_, ok := eks.RoleNameFromARN(parsed)
+	g.Expect(ok).To(BeFalse())
+}
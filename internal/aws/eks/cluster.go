@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
@@ -13,31 +14,75 @@ import (
 	"github.com/aws/eks-hybrid/internal/validation"
 )
 
-// ReadCluster returns raw EKS cluster data from the AWS API with caching
-func ReadCluster(ctx context.Context, config aws.Config, node *api.NodeConfig) (*types.Cluster, error) {
-	client := eks.NewFromConfig(config)
-	input := &eks.DescribeClusterInput{
-		Name: &node.Spec.Cluster.Name,
+// DescribeClusterClient describes EKS clusters.
+type DescribeClusterClient interface {
+	DescribeCluster(ctx context.Context, params *eks.DescribeClusterInput, optFns ...func(*eks.Options)) (*eks.DescribeClusterOutput, error)
+}
+
+// ClusterCache caches the DescribeCluster response by cluster name, so the providers and validators
+// of a single command share one DescribeCluster call. It's safe for concurrent use.
+// Errors are not cached, the next read calls DescribeCluster again.
+type ClusterCache struct {
+	client   DescribeClusterClient
+	mu       sync.RWMutex
+	clusters map[string]*types.Cluster
+}
+
+// NewClusterCache creates an empty ClusterCache that describes clusters with client.
+func NewClusterCache(client DescribeClusterClient) *ClusterCache {
+	return &ClusterCache{
+		client:   client,
+		clusters: map[string]*types.Cluster{},
+	}
+}
+
+// NewClusterCacheFromConfig creates an empty ClusterCache with an EKS client built from config.
+func NewClusterCacheFromConfig(config aws.Config) *ClusterCache {
+	return NewClusterCache(eks.NewFromConfig(config))
+}
+
+// Cluster returns the cluster named name, only calling DescribeCluster if it's not cached.
+func (c *ClusterCache) Cluster(ctx context.Context, name string) (*types.Cluster, error) {
+	c.mu.RLock()
+	cluster, ok := c.clusters[name]
+	c.mu.RUnlock()
+	if ok {
+		return cluster, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Another caller might have described the cluster while waiting for the lock.
+	if cluster, ok := c.clusters[name]; ok {
+		return cluster, nil
+	}
+	output, err := c.client.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: &name})
+	if err != nil {
+		return nil, err
 	}
-	clusterOutput, err := client.DescribeCluster(ctx, input)
+	c.clusters[name] = output.Cluster
+	return output.Cluster, nil
+}
+
+// ReadCluster returns the cluster of the node, reading it from the EKS API if it's not cached.
+func (c *ClusterCache) ReadCluster(ctx context.Context, node *api.NodeConfig) (*types.Cluster, error) {
+	cluster, err := c.Cluster(ctx, node.Spec.Cluster.Name)
 	if err != nil {
 		return nil, validation.WithRemediation(err,
 			"Ensure the node has access and permissions to call DescribeCluster EKS API. "+
 				"Check AWS credentials and IAM permissions.")
 	}
-
-	return clusterOutput.Cluster, nil
+	return cluster, nil
 }
 
 // ReadClusterDetails returns ClusterDetails with the API server endpoint, certificate authority, and CIDR block.
-// If any of these are not set in the input node config, it retrieves them from the EKS API.
-func ReadClusterDetails(ctx context.Context, config aws.Config, node *api.NodeConfig) (*api.ClusterDetails, error) {
+// If any of these are not set in the input node config, it retrieves them from the EKS API if they are not cached.
+func (c *ClusterCache) ReadClusterDetails(ctx context.Context, node *api.NodeConfig) (*api.ClusterDetails, error) {
 	if node.Spec.Cluster.APIServerEndpoint != "" && node.Spec.Cluster.CertificateAuthority != nil && node.Spec.Cluster.CIDR != "" {
 		return node.Spec.Cluster.DeepCopy(), nil
 	}
 
-	// Use the internal ReadCluster function to get raw cluster data
-	cluster, err := ReadCluster(ctx, config, node)
+	cluster, err := c.ReadCluster(ctx, node)
 	if err != nil {
 		return nil, err
 	}
@@ -67,3 +112,15 @@ func ReadClusterDetails(ctx context.Context, config aws.Config, node *api.NodeCo
 
 	return clusterDetails, nil
 }
+
+// ReadCluster returns raw EKS cluster data from the AWS API.
+// Use a ClusterCache to share the response with other readers.
+func ReadCluster(ctx context.Context, config aws.Config, node *api.NodeConfig) (*types.Cluster, error) {
+	return NewClusterCacheFromConfig(config).ReadCluster(ctx, node)
+}
+
+// ReadClusterDetails returns ClusterDetails with the API server endpoint, certificate authority, and CIDR block.
+// If any of these are not set in the input node config, it retrieves them from the EKS API.
+func ReadClusterDetails(ctx context.Context, config aws.Config, node *api.NodeConfig) (*api.ClusterDetails, error) {
+	return NewClusterCacheFromConfig(config).ReadClusterDetails(ctx, node)
+}
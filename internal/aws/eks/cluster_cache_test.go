@@ -0,0 +1,90 @@
+package eks_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ekssdk "github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+)
+
+// countingDescribeClusterClient counts the DescribeCluster calls per cluster name.
+type countingDescribeClusterClient struct {
+	mu    sync.Mutex
+	calls map[string]int
+	err   error
+}
+
+func (c *countingDescribeClusterClient) DescribeCluster(_ context.Context, params *ekssdk.DescribeClusterInput, _ ...func(*ekssdk.Options)) (*ekssdk.DescribeClusterOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls == nil {
+		c.calls = map[string]int{}
+	}
+	c.calls[*params.Name]++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &ekssdk.DescribeClusterOutput{Cluster: &types.Cluster{Name: params.Name}}, nil
+}
+
+func TestClusterCacheCluster(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := &countingDescribeClusterClient{}
+	cache := eks.NewClusterCache(client)
+
+	for range 3 {
+		cluster, err := cache.Cluster(ctx, "my-cluster")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(cluster.Name).To(Equal(aws.String("my-cluster")))
+	}
+	cluster, err := cache.Cluster(ctx, "other-cluster")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cluster.Name).To(Equal(aws.String("other-cluster")))
+
+	g.Expect(client.calls).To(Equal(map[string]int{"my-cluster": 1, "other-cluster": 1}))
+}
+
+func TestClusterCacheClusterConcurrent(t *testing.T) {
+	g := NewWithT(t)
+	client := &countingDescribeClusterClient{}
+	cache := eks.NewClusterCache(client)
+
+	var wg sync.WaitGroup
+	var failures atomic.Int32
+	for range 20 {
+		wg.Go(func() {
+			if _, err := cache.Cluster(context.Background(), "my-cluster"); err != nil {
+				failures.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	g.Expect(failures.Load()).To(BeZero())
+	g.Expect(client.calls).To(Equal(map[string]int{"my-cluster": 1}))
+}
+
+func TestClusterCacheClusterErrorNotCached(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := &countingDescribeClusterClient{err: errors.New("throttled")}
+	cache := eks.NewClusterCache(client)
+
+	_, err := cache.Cluster(ctx, "my-cluster")
+	g.Expect(err).To(MatchError("throttled"))
+
+	client.err = nil
+	cluster, err := cache.Cluster(ctx, "my-cluster")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cluster.Name).To(Equal(aws.String("my-cluster")))
+	g.Expect(client.calls).To(Equal(map[string]int{"my-cluster": 2}))
+}
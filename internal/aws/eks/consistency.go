@@ -0,0 +1,75 @@
+package eks
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const clusterDetailsMismatchRemediation = "Ensure the cluster name, apiServerEndpoint and certificateAuthority in the NodeConfig belong to the same EKS cluster. " +
+	"They are often copied from another cluster's config. Remove apiServerEndpoint and certificateAuthority to read them from the EKS DescribeCluster API."
+
+// ValidateClusterDetailsMatch returns a warning if the API server endpoint or certificate authority
+// configured for the node don't match the ones of the cluster returned by DescribeCluster for the
+// configured cluster name. Details that are not configured are not checked.
+func ValidateClusterDetailsMatch(cluster *types.Cluster, details api.ClusterDetails) error {
+	var mismatches []string
+
+	if details.APIServerEndpoint != "" && cluster.Endpoint != nil &&
+		endpointHost(details.APIServerEndpoint) != endpointHost(*cluster.Endpoint) {
+		mismatches = append(mismatches, fmt.Sprintf("apiServerEndpoint %s doesn't match the cluster endpoint %s", details.APIServerEndpoint, *cluster.Endpoint))
+	}
+
+	if len(details.CertificateAuthority) > 0 && cluster.CertificateAuthority != nil && cluster.CertificateAuthority.Data != nil {
+		clusterCA, err := base64.StdEncoding.DecodeString(*cluster.CertificateAuthority.Data)
+		if err != nil {
+			return fmt.Errorf("decoding certificate authority of cluster %s: %w", details.Name, err)
+		}
+		configured, actual := caFingerprint(details.CertificateAuthority), caFingerprint(clusterCA)
+		if configured != actual {
+			mismatches = append(mismatches, fmt.Sprintf("certificateAuthority with fingerprint %s doesn't match the cluster certificate authority with fingerprint %s", configured, actual))
+		}
+	}
+
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return validation.WithWarning(
+		fmt.Errorf("node config for cluster %s looks inconsistent with the cluster: %s", details.Name, strings.Join(mismatches, "; ")),
+		clusterDetailsMismatchRemediation,
+	)
+}
+
+// endpointHost returns the lower case host of an API server endpoint, which can be configured with or without scheme.
+func endpointHost(endpoint string) string {
+	endpoint = strings.ToLower(strings.TrimSpace(endpoint))
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	return u.Hostname()
+}
+
+// caFingerprint returns the SHA-256 fingerprint of the first certificate in a PEM bundle.
+// Data that isn't PEM is fingerprinted as is, so it only matches identical data.
+func caFingerprint(ca []byte) string {
+	data := bytes.TrimSpace(ca)
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,105 @@
+package eks_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestValidateClusterDetailsMatch(t *testing.T) {
+	g := NewWithT(t)
+	clusterCA, _, _ := test.GenerateCA(g)
+	otherCA, _, _ := test.GenerateCA(g)
+
+	cluster := &types.Cluster{
+		Name:     aws.String("my-cluster"),
+		Endpoint: aws.String("https://ABC123.gr7.us-west-2.eks.amazonaws.com"),
+		CertificateAuthority: &types.Certificate{
+			Data: aws.String(base64.StdEncoding.EncodeToString(clusterCA)),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		details api.ClusterDetails
+		wantErr string
+	}{
+		{
+			name: "matching endpoint and ca",
+			details: api.ClusterDetails{
+				Name:                 "my-cluster",
+				APIServerEndpoint:    "https://abc123.gr7.us-west-2.eks.amazonaws.com/",
+				CertificateAuthority: append([]byte("\n"), clusterCA...),
+			},
+		},
+		{
+			name: "endpoint without scheme",
+			details: api.ClusterDetails{
+				Name:              "my-cluster",
+				APIServerEndpoint: "abc123.gr7.us-west-2.eks.amazonaws.com",
+			},
+		},
+		{
+			name:    "nothing configured",
+			details: api.ClusterDetails{Name: "my-cluster"},
+		},
+		{
+			name: "ca from another cluster",
+			details: api.ClusterDetails{
+				Name:                 "my-cluster",
+				APIServerEndpoint:    "https://abc123.gr7.us-west-2.eks.amazonaws.com",
+				CertificateAuthority: otherCA,
+			},
+			wantErr: "certificateAuthority with fingerprint",
+		},
+		{
+			name: "endpoint from another cluster",
+			details: api.ClusterDetails{
+				Name:                 "my-cluster",
+				APIServerEndpoint:    "https://def456.gr7.us-west-2.eks.amazonaws.com",
+				CertificateAuthority: clusterCA,
+			},
+			wantErr: "apiServerEndpoint https://def456.gr7.us-west-2.eks.amazonaws.com doesn't match the cluster endpoint",
+		},
+		{
+			name: "ca that is not pem",
+			details: api.ClusterDetails{
+				Name:                 "my-cluster",
+				CertificateAuthority: []byte("not-a-ca"),
+			},
+			wantErr: "node config for cluster my-cluster looks inconsistent with the cluster",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := eks.ValidateClusterDetailsMatch(cluster, tt.details)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(validation.IsWarning(err)).To(BeTrue())
+			g.Expect(validation.Remediation(err)).To(ContainSubstring("same EKS cluster"))
+		})
+	}
+}
+
+func TestValidateClusterDetailsMatchInvalidClusterCA(t *testing.T) {
+	g := NewWithT(t)
+	cluster := &types.Cluster{
+		CertificateAuthority: &types.Certificate{Data: aws.String("not base64!")},
+	}
+
+	err := eks.ValidateClusterDetailsMatch(cluster, api.ClusterDetails{Name: "my-cluster", CertificateAuthority: []byte("ca")})
+	g.Expect(err).To(MatchError(ContainSubstring("decoding certificate authority of cluster my-cluster")))
+}
@@ -0,0 +1,79 @@
+package eks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// endpointRegionRegex matches the region in EKS API server endpoints, like
+// https://0123456789ABCDEF.gr7.us-west-2.eks.amazonaws.com.
+var endpointRegionRegex = regexp.MustCompile(`^[a-z0-9]+\.[a-z0-9]+\.([a-z0-9-]+)\.eks\.amazonaws\.com(\.cn)?$`)
+
+// ClusterRegion returns the region of the cluster from its ARN or, when the cluster couldn't be
+// described, from the configured API server endpoint. It returns an empty string if neither
+// identifies the region.
+func ClusterRegion(cluster *types.Cluster, apiServerEndpoint string) (string, error) {
+	if cluster != nil && cluster.Arn != nil {
+		parsed, err := arn.Parse(*cluster.Arn)
+		if err != nil {
+			return "", fmt.Errorf("parsing cluster ARN %s: %w", *cluster.Arn, err)
+		}
+		return parsed.Region, nil
+	}
+	if matches := endpointRegionRegex.FindStringSubmatch(endpointHost(apiServerEndpoint)); matches != nil {
+		return matches[1], nil
+	}
+	return "", nil
+}
+
+// ValidateRegionMatch returns an error if the region configured for the node, the region of the
+// loaded AWS config or the region of the IAM Roles Anywhere resources differ from clusterRegion.
+// A different region sends the EKS, STS and credential calls to the wrong regional endpoints.
+func ValidateRegionMatch(clusterRegion, awsConfigRegion string, nodeConfig *api.NodeConfig) error {
+	configured := []regionSetting{
+		{name: "cluster.region", region: nodeConfig.Spec.Cluster.Region},
+		{name: "AWS config region", region: awsConfigRegion},
+	}
+	if nodeConfig.IsIAMRolesAnywhere() {
+		iamRA := nodeConfig.Spec.Hybrid.IAMRolesAnywhere
+		configured = append(configured,
+			regionSetting{name: "hybrid.iamRolesAnywhere.trustAnchorArn", region: arnRegion(iamRA.TrustAnchorARN)},
+			regionSetting{name: "hybrid.iamRolesAnywhere.profileArn", region: arnRegion(iamRA.ProfileARN)},
+		)
+	}
+
+	var mismatches []string
+	for _, c := range configured {
+		if c.region != "" && c.region != clusterRegion {
+			mismatches = append(mismatches, fmt.Sprintf("%s is %s", c.name, c.region))
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+	return validation.WithRemediation(
+		fmt.Errorf("cluster %s is in region %s, but %s", nodeConfig.Spec.Cluster.Name, clusterRegion, strings.Join(mismatches, ", ")),
+		fmt.Sprintf("Set cluster.region to %s in the node config and create the SSM hybrid activation or IAM Roles Anywhere trust anchor and profile in the same region.", clusterRegion),
+	)
+}
+
+// regionSetting is a region configured for the node and where it comes from.
+type regionSetting struct {
+	name, region string
+}
+
+// arnRegion returns the region of resourceARN, or an empty string if it isn't a valid ARN.
+func arnRegion(resourceARN string) string {
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil {
+		return ""
+	}
+	return parsed.Region
+}
@@ -0,0 +1,124 @@
+package eks_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestClusterRegion(t *testing.T) {
+	tests := []struct {
+		name       string
+		cluster    *types.Cluster
+		endpoint   string
+		wantRegion string
+		wantErr    string
+	}{
+		{
+			name:       "from cluster ARN",
+			cluster:    &types.Cluster{Arn: aws.String("arn:aws:eks:us-west-2:123456789012:cluster/my-cluster")},
+			endpoint:   "https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com",
+			wantRegion: "us-west-2",
+		},
+		{
+			name:       "from API server endpoint",
+			endpoint:   "https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com",
+			wantRegion: "eu-west-1",
+		},
+		{
+			name:       "from China API server endpoint",
+			endpoint:   "https://0123456789ABCDEF.yl4.cn-north-1.eks.amazonaws.com.cn",
+			wantRegion: "cn-north-1",
+		},
+		{
+			name:     "unknown",
+			endpoint: "https://kubernetes.example.com",
+		},
+		{
+			name:    "invalid cluster ARN",
+			cluster: &types.Cluster{Arn: aws.String("my-cluster")},
+			wantErr: "parsing cluster ARN my-cluster",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			region, err := eks.ClusterRegion(tc.cluster, tc.endpoint)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(region).To(Equal(tc.wantRegion))
+		})
+	}
+}
+
+func TestValidateRegionMatch(t *testing.T) {
+	clusterARN := "arn:aws:eks:us-west-2:123456789012:cluster/my-cluster"
+
+	tests := []struct {
+		name            string
+		awsConfigRegion string
+		nodeConfig      *api.NodeConfig
+		wantErr         string
+	}{
+		{
+			name: "matching regions",
+			nodeConfig: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{Name: "my-cluster", Region: "us-west-2"},
+					Hybrid:  &api.HybridOptions{SSM: &api.SSM{ActivationID: "id", ActivationCode: "code"}},
+				},
+			},
+			awsConfigRegion: "us-west-2",
+		},
+		{
+			name: "mismatched node config region",
+			nodeConfig: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{Name: "my-cluster", Region: "us-east-1"},
+					Hybrid:  &api.HybridOptions{SSM: &api.SSM{ActivationID: "id", ActivationCode: "code"}},
+				},
+			},
+			awsConfigRegion: "us-east-1",
+			wantErr:         "cluster my-cluster is in region us-west-2, but cluster.region is us-east-1, AWS config region is us-east-1",
+		},
+		{
+			name: "mismatched IAM Roles Anywhere trust anchor",
+			nodeConfig: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{Name: "my-cluster", Region: "us-west-2"},
+					Hybrid: &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{
+						TrustAnchorARN: "arn:aws:rolesanywhere:eu-central-1:123456789012:trust-anchor/anchor",
+						ProfileARN:     "arn:aws:rolesanywhere:us-west-2:123456789012:profile/profile",
+					}},
+				},
+			},
+			awsConfigRegion: "us-west-2",
+			wantErr:         "cluster my-cluster is in region us-west-2, but hybrid.iamRolesAnywhere.trustAnchorArn is eu-central-1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterRegion, err := eks.ClusterRegion(&types.Cluster{Arn: aws.String(clusterARN)}, "")
+			g.Expect(err).NotTo(HaveOccurred())
+
+			err = eks.ValidateRegionMatch(clusterRegion, tc.awsConfigRegion, tc.nodeConfig)
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tc.wantErr))
+			g.Expect(validation.IsWarning(err)).To(BeFalse())
+			g.Expect(validation.Remediation(err)).To(ContainSubstring("Set cluster.region to us-west-2"))
+		})
+	}
+}
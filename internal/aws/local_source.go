@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+// getLocalSource serves artifactName from dir, where it's stored as a file of the same name next
+// to its checksum file, <artifactName>.sha256, in the format of the release checksum files.
+// The checksum is verified the same way as for downloaded artifacts.
+func getLocalSource(dir, artifactName string) (artifact.Source, error) {
+	path := filepath.Join(dir, artifactName)
+	artifactChecksum, err := os.ReadFile(path + ".sha256")
+	if err != nil {
+		return nil, fmt.Errorf("reading artifact checksum file: %w", err)
+	}
+	obj, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening artifact file: %w", err)
+	}
+	source, err := artifact.WithChecksum(obj, sha256.New(), artifactChecksum)
+	if err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("getting artifact with checksum: %w", err)
+	}
+	return source, nil
+}
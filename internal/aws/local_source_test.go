@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+func writeLocalArtifact(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checksum := fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte(content)), name)
+	if err := os.WriteFile(filepath.Join(dir, name+".sha256"), []byte(checksum), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readSource(t *testing.T, source artifact.Source) string {
+	t.Helper()
+	defer source.Close()
+	data, err := io.ReadAll(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !source.VerifyChecksum() {
+		t.Fatalf("checksum mismatch, expected %x got %x", source.ExpectedChecksum(), source.ActualChecksum())
+	}
+	return string(data)
+}
+
+func TestSourceWithArtifactDir(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := map[string]func(Source, context.Context) (artifact.Source, error){
+		"kubelet":                 Source.GetKubelet,
+		"kubectl":                 Source.GetKubectl,
+		"cni-plugins":             Source.GetCniPlugins,
+		"ecr-credential-provider": Source.GetImageCredentialProvider,
+		"aws-iam-authenticator":   Source.GetIAMAuthenticator,
+		"aws_signing_helper":      Source.GetSigningHelper,
+	}
+	for name := range artifacts {
+		writeLocalArtifact(t, dir, name, name+" binary")
+	}
+
+	// The release artifacts point to an unreachable location, they must not be used.
+	source := Source{Eks: EksPatchRelease{Artifacts: []Artifact{{Name: "kubelet", URI: "https://unreachable.invalid/kubelet"}}}}
+	source, err := source.WithArtifactDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.ArtifactDir() != dir {
+		t.Errorf("ArtifactDir() = %q, want %q", source.ArtifactDir(), dir)
+	}
+
+	for name, get := range artifacts {
+		t.Run(name, func(t *testing.T) {
+			artifactSource, err := get(source, context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readSource(t, artifactSource); got != name+" binary" {
+				t.Errorf("read %q, want %q", got, name+" binary")
+			}
+		})
+	}
+}
+
+func TestSourceWithArtifactDirErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalArtifact(t, dir, "kubelet", "kubelet binary")
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte("kubectl binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The checksum of a different binary.
+	writeLocalArtifact(t, dir, "aws-iam-authenticator", "aws-iam-authenticator binary")
+	if err := os.WriteFile(filepath.Join(dir, "aws-iam-authenticator"), []byte("tampered binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (Source{}).WithArtifactDir(filepath.Join(dir, "kubelet")); err == nil || !strings.Contains(err.Error(), "is not a directory") {
+		t.Errorf("WithArtifactDir(file) error = %v, want not a directory", err)
+	}
+	if _, err := (Source{}).WithArtifactDir(filepath.Join(dir, "missing")); err == nil || !strings.Contains(err.Error(), "reading artifact directory") {
+		t.Errorf("WithArtifactDir(missing) error = %v, want reading artifact directory", err)
+	}
+
+	source, err := Source{}.WithArtifactDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.GetKubectl(context.Background()); err == nil || !strings.Contains(err.Error(), "reading artifact checksum file") {
+		t.Errorf("GetKubectl() error = %v, want missing checksum file", err)
+	}
+	if _, err := source.GetCniPlugins(context.Background()); err == nil || !strings.Contains(err.Error(), "reading artifact checksum file") {
+		t.Errorf("GetCniPlugins() error = %v, want missing checksum file", err)
+	}
+
+	authenticator, err := source.GetIAMAuthenticator(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer authenticator.Close()
+	if _, err := io.ReadAll(authenticator); err != nil {
+		t.Fatal(err)
+	}
+	if authenticator.VerifyChecksum() {
+		t.Error("expected a checksum mismatch for a tampered artifact")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"os"
 	"runtime"
 	"strings"
 	"time"
@@ -20,6 +21,38 @@ type Source struct {
 	Eks        EksPatchRelease
 	Iam        IamRolesAnywhereRelease
 	RegionInfo RegionData
+
+	downloads *artifact.DownloadLimiter
+	// artifactDir is a local directory the artifacts are read from instead of being downloaded.
+	artifactDir string
+}
+
+// WithDownloadLimits returns a copy of the Source that limits the number of concurrent
+// artifact downloads and the bandwidth of each download. Zero values mean unlimited.
+func (as Source) WithDownloadLimits(concurrency, bytesPerSecond int) Source {
+	as.downloads = artifact.NewDownloadLimiter(concurrency, bytesPerSecond)
+	return as
+}
+
+// WithArtifactDir returns a copy of the Source that reads the artifacts from dir instead of
+// downloading them, for nodes that can't reach the release locations. Each artifact is stored
+// under its release name with its sha256 checksum file, like kubelet and kubelet.sha256.
+// It returns an error if dir is not a directory.
+func (as Source) WithArtifactDir(dir string) (Source, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return as, fmt.Errorf("reading artifact directory: %w", err)
+	}
+	if !info.IsDir() {
+		return as, fmt.Errorf("artifact directory %s is not a directory", dir)
+	}
+	as.artifactDir = dir
+	return as, nil
+}
+
+// ArtifactDir returns the local directory the artifacts are read from, empty when they are downloaded.
+func (as Source) ArtifactDir() string {
+	return as.artifactDir
 }
 
 // GetLatestSource gets the source for latest version of aws provided artifacts from the
@@ -214,15 +247,21 @@ func (as Source) GetCniPlugins(ctx context.Context) (artifact.Source, error) {
 }
 
 func (as Source) getEksSource(ctx context.Context, artifactName string) (artifact.Source, error) {
-	return getSource(ctx, artifactName, as.Eks.Artifacts)
+	if as.artifactDir != "" {
+		return getLocalSource(as.artifactDir, artifactName)
+	}
+	return getSource(ctx, artifactName, as.Eks.Artifacts, as.downloads)
 }
 
 // GetSingingHelper satisfies iamrolesanywhere.SigningHelperSource
 func (as Source) GetSigningHelper(ctx context.Context) (artifact.Source, error) {
-	return getSource(ctx, "aws_signing_helper", as.Iam.Artifacts)
+	if as.artifactDir != "" {
+		return getLocalSource(as.artifactDir, "aws_signing_helper")
+	}
+	return getSource(ctx, "aws_signing_helper", as.Iam.Artifacts, as.downloads)
 }
 
-func getSource(ctx context.Context, artifactName string, availableArtifacts []Artifact) (artifact.Source, error) {
+func getSource(ctx context.Context, artifactName string, availableArtifacts []Artifact, downloads *artifact.DownloadLimiter) (artifact.Source, error) {
 	for _, releaseArtifact := range availableArtifacts {
 		if releaseArtifact.Name == artifactName && releaseArtifact.Arch == runtime.GOARCH && releaseArtifact.OS == runtime.GOOS {
 			uri := releaseArtifact.URI
@@ -231,10 +270,17 @@ func getSource(ctx context.Context, artifactName string, availableArtifacts []Ar
 				// gzip decompression will happen before checksum verification
 				uri = releaseArtifact.GzipURI
 			}
+			release, err := downloads.Acquire(ctx)
+			if err != nil {
+				return nil, err
+			}
 			obj, err := util.GetHttpFileReader(ctx, uri)
 			if err != nil {
+				release()
 				return nil, fmt.Errorf("getting artifact file reader: %w", err)
 			}
+			// The download slot is released when the caller closes the source.
+			obj = downloads.Wrap(ctx, obj, release)
 
 			artifactChecksum, err := util.GetHttpFile(ctx, releaseArtifact.ChecksumURI)
 			if err != nil {
@@ -0,0 +1,181 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aws/eks-hybrid/internal/retry"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+
+	requestTimeout = 10 * time.Second
+	notifyTimeout  = time.Minute
+)
+
+// Payload is the JSON body posted to the callback URL once a command finishes.
+type Payload struct {
+	NodeName    string            `json:"nodeName,omitempty"`
+	Command     string            `json:"command"`
+	Result      string            `json:"result"`
+	Error       string            `json:"error,omitempty"`
+	Validations ValidationSummary `json:"validations"`
+	Time        time.Time         `json:"time"`
+}
+
+// ValidationSummary counts the validations run by the command by status.
+type ValidationSummary struct {
+	Passed       int      `json:"passed"`
+	Warnings     int      `json:"warnings"`
+	Failed       int      `json:"failed"`
+	FailedChecks []string `json:"failedChecks,omitempty"`
+}
+
+// NewPayload builds the payload for command with the error it returned and the validations it ran.
+func NewPayload(command, nodeName string, status validation.Status, err error) Payload {
+	payload := Payload{
+		NodeName: nodeName,
+		Command:  command,
+		Result:   ResultSuccess,
+		Time:     time.Now(),
+	}
+	if err != nil {
+		payload.Result = ResultFailure
+		payload.Error = err.Error()
+	}
+	for _, check := range status.Checks {
+		switch check.Status {
+		case validation.CheckPassed:
+			payload.Validations.Passed++
+		case validation.CheckWarning:
+			payload.Validations.Warnings++
+		case validation.CheckFailed:
+			payload.Validations.Failed++
+			payload.Validations.FailedChecks = append(payload.Validations.FailedChecks, check.Name)
+		}
+	}
+	return payload
+}
+
+// Notifier posts command results to a callback URL so external automation knows when a node is done.
+type Notifier struct {
+	url     string
+	client  *http.Client
+	retrier retry.Retrier
+}
+
+// NotifierOption configures a Notifier.
+type NotifierOption func(*Notifier)
+
+// WithBackoff sets the backoff between notification attempts.
+func WithBackoff(backoff retry.Backoff) NotifierOption {
+	return func(n *Notifier) {
+		n.retrier.Backoff = backoff
+	}
+}
+
+// NewNotifier creates a Notifier that posts to callbackURL.
+func NewNotifier(callbackURL string, opts ...NotifierOption) *Notifier {
+	n := &Notifier{
+		url: callbackURL,
+		// ensure proxy configuration is inherited from the default transport
+		client: &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()},
+		retrier: retry.Retrier{
+			Timeout:          notifyTimeout,
+			OperationTimeout: requestTimeout,
+			Backoff: retry.Backoff{
+				Duration: 2 * time.Second,
+				Factor:   2,
+				Jitter:   0.1,
+				Steps:    5,
+			},
+		},
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// ValidateURL checks callbackURL is an absolute http or https URL.
+func ValidateURL(callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL %q: %w", callbackURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid callback URL %q, expected an http or https URL", callbackURL)
+	}
+	return nil
+}
+
+// Notify posts payload to the callback URL, retrying connection errors and server errors.
+// Client errors are not retried, the same payload would be rejected again.
+func (n *Notifier) Notify(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling callback payload: %w", err)
+	}
+
+	retrier := n.retrier
+	retrier.HandleError = func(err error) error {
+		var clientErr *clientError
+		if errors.As(err, &clientErr) {
+			return err
+		}
+		return nil
+	}
+	return retrier.Do(ctx, func(ctx context.Context) (bool, error) {
+		if err := n.post(ctx, body); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return &clientError{err: fmt.Errorf("creating callback request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to callback URL: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return &clientError{err: fmt.Errorf("callback URL responded with status %d", resp.StatusCode)}
+	default:
+		return fmt.Errorf("callback URL responded with status %d", resp.StatusCode)
+	}
+}
+
+// clientError is a notification error that won't succeed on retry.
+type clientError struct {
+	err error
+}
+
+func (e *clientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *clientError) Unwrap() error {
+	return e.err
+}
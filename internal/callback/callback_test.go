@@ -0,0 +1,136 @@
+package callback_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/callback"
+	"github.com/aws/eks-hybrid/internal/retry"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+var fastBackoff = retry.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+
+func TestNewPayload(t *testing.T) {
+	g := NewWithT(t)
+	status := validation.Status{
+		Checks: []validation.CheckStatus{
+			{Name: "ntp", Status: validation.CheckPassed},
+			{Name: "swap", Status: validation.CheckWarning},
+			{Name: "node-ip", Status: validation.CheckFailed},
+			{Name: "kubelet-cert", Status: validation.CheckPassed},
+		},
+	}
+
+	payload := callback.NewPayload("init", "mi-123", status, errors.New("node ip is invalid"))
+
+	g.Expect(payload.NodeName).To(Equal("mi-123"))
+	g.Expect(payload.Command).To(Equal("init"))
+	g.Expect(payload.Result).To(Equal(callback.ResultFailure))
+	g.Expect(payload.Error).To(Equal("node ip is invalid"))
+	g.Expect(payload.Validations).To(Equal(callback.ValidationSummary{
+		Passed:       2,
+		Warnings:     1,
+		Failed:       1,
+		FailedChecks: []string{"node-ip"},
+	}))
+
+	g.Expect(callback.NewPayload("init", "mi-123", validation.Status{}, nil).Result).To(Equal(callback.ResultSuccess))
+}
+
+func TestNotifierNotify(t *testing.T) {
+	g := NewWithT(t)
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.Method).To(Equal(http.MethodPost))
+		g.Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+		g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := callback.NewPayload("init", "mi-123", validation.Status{
+		Checks: []validation.CheckStatus{{Name: "ntp", Status: validation.CheckPassed}},
+	}, nil)
+	g.Expect(callback.NewNotifier(server.URL, callback.WithBackoff(fastBackoff)).Notify(context.Background(), payload)).To(Succeed())
+
+	g.Expect(body).To(HaveKeyWithValue("nodeName", "mi-123"))
+	g.Expect(body).To(HaveKeyWithValue("command", "init"))
+	g.Expect(body).To(HaveKeyWithValue("result", "success"))
+	g.Expect(body).NotTo(HaveKey("error"))
+	g.Expect(body).To(HaveKey("time"))
+	g.Expect(body).To(HaveKeyWithValue("validations", map[string]any{
+		"passed":   float64(1),
+		"warnings": float64(0),
+		"failed":   float64(0),
+	}))
+}
+
+func TestNotifierNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int32
+		wantErr      string
+	}{
+		{
+			name:         "succeeds after server errors",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK},
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries throttling",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusAccepted},
+			wantAttempts: 2,
+		},
+		{
+			name:         "gives up after the backoff steps",
+			statuses:     []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+			wantErr:      "callback URL responded with status 502",
+		},
+		{
+			name:         "doesn't retry client errors",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      "callback URL responded with status 400",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := attempts.Add(1)
+				w.WriteHeader(tc.statuses[attempt-1])
+			}))
+			defer server.Close()
+
+			err := callback.NewNotifier(server.URL, callback.WithBackoff(fastBackoff)).
+				Notify(context.Background(), callback.NewPayload("init", "mi-123", validation.Status{}, nil))
+
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(attempts.Load()).To(Equal(tc.wantAttempts))
+		})
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(callback.ValidateURL("https://automation.example.com/nodes")).To(Succeed())
+	g.Expect(callback.ValidateURL("http://10.0.0.1:8080/joined")).To(Succeed())
+	g.Expect(callback.ValidateURL("file:///tmp/result")).To(MatchError(ContainSubstring("expected an http or https URL")))
+	g.Expect(callback.ValidateURL("automation.example.com")).To(MatchError(ContainSubstring("expected an http or https URL")))
+}
@@ -61,6 +61,11 @@ type CertInvalidCAError struct {
 	baseError
 }
 
+// NewCertNotFoundError returns an error for a missing certificate or credential, identified by IsNoCertError.
+func NewCertNotFoundError(message string, cause error) error {
+	return &CertNotFoundError{baseError{message: message, cause: cause}}
+}
+
 func IsDateValidationError(err error) bool {
 	var clockSkew *CertClockSkewError
 	var expiredCrt *CertExpiredError
@@ -86,6 +91,17 @@ func Validate(certPath string, ca []byte) error {
 		return &CertReadError{baseError{message: "reading certificate", cause: err}}
 	}
 
+	return validateCertificate(certData, ca, "server", x509.ExtKeyUsageServerAuth)
+}
+
+// ValidateClientData validates the PEM encoded client certificate data is in its validity
+// window and, if ca is not empty, that it's issued by ca for client authentication.
+func ValidateClientData(certData, ca []byte) error {
+	return validateCertificate(certData, ca, "client", x509.ExtKeyUsageClientAuth)
+}
+
+// validateCertificate validates a PEM encoded certificate of kind, server or client, for usage.
+func validateCertificate(certData, ca []byte, kind string, usage x509.ExtKeyUsage) error {
 	block, _ := pem.Decode(certData)
 	if block == nil {
 		return &CertInvalidFormatError{baseError{message: "parsing certificate"}}
@@ -98,11 +114,11 @@ func Validate(certPath string, ca []byte) error {
 
 	now := time.Now()
 	if now.Before(cert.NotBefore) {
-		return &CertClockSkewError{baseError{message: "server certificate is not yet valid"}}
+		return &CertClockSkewError{baseError{message: kind + " certificate is not yet valid"}}
 	}
 
 	if now.After(cert.NotAfter) {
-		return &CertExpiredError{baseError{message: "server certificate has expired"}}
+		return &CertExpiredError{baseError{message: kind + " certificate has expired"}}
 	}
 
 	if len(ca) > 0 {
@@ -114,6 +130,7 @@ func Validate(certPath string, ca []byte) error {
 		opts := x509.VerifyOptions{
 			Roots:       caPool,
 			CurrentTime: now,
+			KeyUsages:   []x509.ExtKeyUsage{usage},
 		}
 
 		if _, err := cert.Verify(opts); err != nil {
@@ -145,3 +162,26 @@ func AddKubeletRemediation(certPath string, err error) error {
 
 	return errWithContext
 }
+
+// AddKubeletClientRemediation adds remediation messages for the kubelet client certificate read from source
+// based on error type, telling apart a kubelet that hasn't bootstrapped from an expired credential.
+func AddKubeletClientRemediation(source string, err error) error {
+	errWithContext := fmt.Errorf("validating kubelet client certificate: %w", err)
+
+	switch err.(type) {
+	case *CertNotFoundError, *CertFileError, *CertReadError:
+		return validation.WithRemediation(errWithContext, "Kubelet bootstrap has not completed. The kubelet client credential is created when kubelet first authenticates with the API server, run nodeadm init to complete the bootstrap.")
+	case *CertInvalidFormatError:
+		return validation.WithRemediation(errWithContext, fmt.Sprintf("Delete the kubelet client certificate %s and restart kubelet, or regenerate the kubelet kubeconfig with nodeadm debug regenerate-kubeconfig", source))
+	case *CertClockSkewError:
+		return validation.WithRemediation(errWithContext, "Verify the system time is correct and restart the kubelet.")
+	case *CertExpiredError:
+		return validation.WithRemediation(errWithContext, fmt.Sprintf("The kubelet client credential expired. Regenerate the kubelet kubeconfig with nodeadm debug regenerate-kubeconfig, or delete the kubelet client certificate %s and restart kubelet to bootstrap a new one.", source))
+	case *CertParseCAError:
+		return validation.WithRemediation(errWithContext, "Ensure the cluster CA certificate is valid")
+	case *CertInvalidCAError:
+		return validation.WithRemediation(errWithContext, fmt.Sprintf("The kubelet client certificate %s was not issued by the current cluster, regenerate the kubelet kubeconfig with nodeadm debug regenerate-kubeconfig", source))
+	}
+
+	return errWithContext
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"go.uber.org/zap"
@@ -15,6 +16,11 @@ var cleanupDirs = []string{
 	"/etc/cni/net.d",
 }
 
+// Dirs returns the directories removed by Cleanup.
+func Dirs() []string {
+	return slices.Clone(cleanupDirs)
+}
+
 // Force handles the cleanup of leftover directories.
 type Force struct {
 	logger  *zap.Logger
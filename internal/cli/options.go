@@ -1,15 +1,42 @@
 package cli
 
-import "github.com/integrii/flaggy"
+import (
+	"fmt"
+
+	"github.com/integrii/flaggy"
+)
 
 type GlobalOptions struct {
 	DevelopmentMode bool
+	// Output is the format of the command result, one of OutputText or OutputJSON.
+	Output string
+	// ResultFile is the path the Result is written to once the command finishes, regardless of Output.
+	ResultFile string
+	// Result collects the outcome of the command when Output is OutputJSON or ResultFile is set.
+	Result *Result
 }
 
 func NewGlobalOptions() *GlobalOptions {
 	opts := GlobalOptions{
 		DevelopmentMode: false,
+		Output:          OutputText,
+		Result:          NewResult(""),
 	}
 	flaggy.Bool(&opts.DevelopmentMode, "d", "development", "Enable development mode for logging.")
+	flaggy.String(&opts.Output, "o", "output", fmt.Sprintf("Output format. Allowed values: [%s, %s]. With %s, a summary of init, install, uninstall and upgrade is written to stdout as JSON when they finish, logs are always written to stderr.", OutputText, OutputJSON, OutputJSON))
+	flaggy.String(&opts.ResultFile, "", "result-file", fmt.Sprintf("Write a JSON summary of the command to this file when it finishes, whether it succeeds or fails. The file is replaced atomically, so later cloud-init stages or monitoring can read it to gate provisioning. Use %s for the well-known location.", DefaultResultFile))
 	return &opts
 }
+
+// Validate returns an error if the options are invalid.
+func (o *GlobalOptions) Validate() error {
+	if o.Output != OutputText && o.Output != OutputJSON {
+		return fmt.Errorf("--output must be one of [%s, %s], got %q", OutputText, OutputJSON, o.Output)
+	}
+	return nil
+}
+
+// JSONOutput returns true when the command result should be written as JSON.
+func (o *GlobalOptions) JSONOutput() bool {
+	return o.Output == OutputJSON
+}
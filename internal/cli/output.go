@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/util"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	// OutputText only writes logs, to stderr.
+	OutputText = "text"
+	// OutputJSON also writes a Result to stdout once the command finishes, for the
+	// commands in resultCommands.
+	OutputJSON = "json"
+
+	// DefaultResultFile is the well-known path for --result-file, for cloud-init stages and
+	// monitoring agents that gate provisioning on the outcome of nodeadm.
+	DefaultResultFile = "/run/nodeadm/result.json"
+)
+
+// resultCommands are the commands that write their Result to stdout with --output json, the
+// other commands print their own output to stdout.
+var resultCommands = []string{"init", "install", "uninstall", "upgrade"}
+
+// WritesResult returns true if command writes its Result to stdout with --output json.
+func WritesResult(command string) bool {
+	return slices.Contains(resultCommands, command)
+}
+
+// Result is the machine-readable outcome of a command, written to stdout with --output json.
+type Result struct {
+	Command       string                      `json:"command"`
+	Success       bool                        `json:"success"`
+	PhasesRun     []string                    `json:"phasesRun"`
+	PhasesSkipped []string                    `json:"phasesSkipped"`
+	Artifacts     *tracker.InstalledArtifacts `json:"artifacts,omitempty"`
+	Removed       any                         `json:"removed,omitempty"`
+	Validations   []validation.CheckStatus    `json:"validations"`
+	Error         string                      `json:"error,omitempty"`
+
+	mu       sync.Mutex
+	recorder *validation.StatusRecorder
+}
+
+// NewResult creates an empty Result for command.
+func NewResult(command string) *Result {
+	return &Result{
+		Command:       command,
+		PhasesRun:     []string{},
+		PhasesSkipped: []string{},
+		recorder:      validation.NewStatusRecorder(),
+	}
+}
+
+type resultKey struct{}
+
+// WithResult returns a copy of ctx that records the phases and validations run with it into result.
+func WithResult(ctx context.Context, result *Result) context.Context {
+	ctx = validation.WithInformer(ctx, result.recorder)
+	return context.WithValue(ctx, resultKey{}, result)
+}
+
+// PhaseRun records phase as run in the Result of ctx, if any.
+func PhaseRun(ctx context.Context, phase string) {
+	if result, ok := ctx.Value(resultKey{}).(*Result); ok {
+		result.mu.Lock()
+		defer result.mu.Unlock()
+		result.PhasesRun = append(result.PhasesRun, phase)
+	}
+}
+
+// SkipPhases records phases as skipped.
+func (r *Result) SkipPhases(phases ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.PhasesSkipped = append(r.PhasesSkipped, phases...)
+}
+
+// SetArtifacts records the artifacts installed on the node once the command finishes.
+func (r *Result) SetArtifacts(artifacts *tracker.InstalledArtifacts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Artifacts = artifacts
+}
+
+// SetRemoved records the manifest of what the command removed from the node.
+func (r *Result) SetRemoved(removed any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Removed = removed
+}
+
+// RecordInstalledArtifacts records the artifacts in the tracker file, if the node has one.
+func (r *Result) RecordInstalledArtifacts() {
+	installed, err := tracker.GetInstalledArtifacts()
+	if err != nil {
+		return
+	}
+	r.SetArtifacts(installed.Artifacts)
+}
+
+// ValidationStatus returns the validations recorded so far.
+func (r *Result) ValidationStatus() validation.Status {
+	return r.recorder.Status()
+}
+
+// Write completes the result with the error the command returned and writes it to w as JSON.
+func (r *Result) Write(w io.Writer, err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Success = err == nil
+	r.Error = ""
+	if err != nil {
+		r.Error = err.Error()
+	}
+	r.Validations = r.recorder.Status().Checks
+	if r.Validations == nil {
+		r.Validations = []validation.CheckStatus{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("writing %s result: %w", r.Command, err)
+	}
+	return nil
+}
+
+// WriteFile completes the result with the error the command returned and writes it to path as JSON.
+// The file is replaced atomically, so readers polling it never see a partial result.
+func (r *Result) WriteFile(path string, err error) error {
+	var buf bytes.Buffer
+	if err := r.Write(&buf, err); err != nil {
+		return err
+	}
+	if err := util.WriteFileAtomic(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing %s result to %s: %w", r.Command, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/cli"
+	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type validatable struct{}
+
+func (v *validatable) DeepCopy() *validatable {
+	return &validatable{}
+}
+
+func runValidation(ctx context.Context, name string, err error) error {
+	runner := validation.NewRunner[*validatable](validation.NewPrinter())
+	runner.Register(validation.New(name, func(ctx context.Context, informer validation.Informer, _ *validatable) error {
+		informer.Starting(ctx, name, "Validating "+name)
+		informer.Done(ctx, name, err)
+		return err
+	}))
+	return runner.Sequentially(ctx, &validatable{})
+}
+
+func TestResultWriteSuccessfulInstall(t *testing.T) {
+	g := NewWithT(t)
+	result := cli.NewResult("install")
+	result.SetArtifacts(&tracker.InstalledArtifacts{
+		Containerd: tracker.ContainerdSourceDistro,
+		Kubelet:    true,
+		Kubectl:    true,
+	})
+
+	var out bytes.Buffer
+	g.Expect(result.Write(&out, nil)).To(Succeed())
+
+	var written map[string]any
+	g.Expect(json.Unmarshal(out.Bytes(), &written)).To(Succeed())
+	g.Expect(written).To(HaveKeyWithValue("command", "install"))
+	g.Expect(written).To(HaveKeyWithValue("success", true))
+	g.Expect(written).To(HaveKeyWithValue("phasesRun", BeEmpty()))
+	g.Expect(written).To(HaveKeyWithValue("phasesSkipped", BeEmpty()))
+	g.Expect(written).To(HaveKeyWithValue("validations", BeEmpty()))
+	g.Expect(written).NotTo(HaveKey("error"))
+	g.Expect(written).To(HaveKeyWithValue("artifacts", SatisfyAll(
+		HaveKeyWithValue("Containerd", "distro"),
+		HaveKeyWithValue("Kubelet", true),
+		HaveKeyWithValue("Kubectl", true),
+		HaveKeyWithValue("Ssm", false),
+	)))
+}
+
+func TestResultWriteFailedValidation(t *testing.T) {
+	g := NewWithT(t)
+	result := cli.NewResult("init")
+	ctx := cli.WithResult(context.Background(), result)
+	result.SkipPhases("cni-validation")
+
+	cli.PhaseRun(ctx, "install-validation")
+	g.Expect(runValidation(ctx, "swap-validation", nil)).To(Succeed())
+	err := runValidation(ctx, "kubelet-process-validation",
+		validation.WithRemediation(errors.New("kubelet is running outside of kubelet.service"), "kill 1234"))
+	g.Expect(err).To(HaveOccurred())
+
+	var out bytes.Buffer
+	g.Expect(result.Write(&out, err)).To(Succeed())
+
+	var written cli.Result
+	g.Expect(json.Unmarshal(out.Bytes(), &written)).To(Succeed())
+	g.Expect(written.Command).To(Equal("init"))
+	g.Expect(written.Success).To(BeFalse())
+	g.Expect(written.Error).To(Equal("kubelet is running outside of kubelet.service"))
+	g.Expect(written.PhasesRun).To(Equal([]string{"install-validation"}))
+	g.Expect(written.PhasesSkipped).To(Equal([]string{"cni-validation"}))
+	g.Expect(written.Artifacts).To(BeNil())
+	g.Expect(written.Validations).To(HaveLen(2))
+	g.Expect(written.Validations[0].Name).To(Equal("swap-validation"))
+	g.Expect(written.Validations[0].Status).To(Equal(validation.CheckPassed))
+	g.Expect(written.Validations[1].Name).To(Equal("kubelet-process-validation"))
+	g.Expect(written.Validations[1].Status).To(Equal(validation.CheckFailed))
+	g.Expect(written.Validations[1].Errors).To(ConsistOf("kubelet is running outside of kubelet.service"))
+	g.Expect(written.Validations[1].Remediation).To(ConsistOf("kill 1234"))
+}
+
+func TestResultWriteFile(t *testing.T) {
+	testCases := []struct {
+		name        string
+		err         error
+		wantSuccess bool
+		wantError   string
+	}{
+		{
+			name:        "success",
+			wantSuccess: true,
+		},
+		{
+			name:      "failure",
+			err:       errors.New("kubelet is running outside of kubelet.service"),
+			wantError: "kubelet is running outside of kubelet.service",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dir := t.TempDir()
+			path := filepath.Join(dir, "nodeadm", "result.json")
+			// A previous result is replaced.
+			g.Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+			g.Expect(os.WriteFile(path, []byte(`{"command":"install"}`), 0o644)).To(Succeed())
+
+			result := cli.NewResult("init")
+			ctx := cli.WithResult(context.Background(), result)
+			cli.PhaseRun(ctx, "config")
+			_ = runValidation(ctx, "swap-validation", tc.err)
+
+			g.Expect(result.WriteFile(path, tc.err)).To(Succeed())
+
+			data, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			var written cli.Result
+			g.Expect(json.Unmarshal(data, &written)).To(Succeed())
+			g.Expect(written.Command).To(Equal("init"))
+			g.Expect(written.Success).To(Equal(tc.wantSuccess))
+			g.Expect(written.Error).To(Equal(tc.wantError))
+			g.Expect(written.PhasesRun).To(Equal([]string{"config"}))
+			g.Expect(written.Validations).To(HaveLen(1))
+			g.Expect(written.Validations[0].Name).To(Equal("swap-validation"))
+
+			// The temporary file is renamed over the result, nothing else is left behind.
+			entries, err := os.ReadDir(filepath.Dir(path))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(entries).To(HaveLen(1))
+		})
+	}
+}
+
+func TestWritesResult(t *testing.T) {
+	g := NewWithT(t)
+	for _, command := range []string{"init", "install", "uninstall", "upgrade"} {
+		g.Expect(cli.WritesResult(command)).To(BeTrue(), command)
+	}
+	// These commands print their own output to stdout.
+	for _, command := range []string{"debug", "config", "validate"} {
+		g.Expect(cli.WritesResult(command)).To(BeFalse(), command)
+	}
+}
@@ -35,6 +35,9 @@ type InstallOptions struct {
 	Logger      *zap.Logger
 	Source      Source
 	Tracker     *tracker.Tracker
+	// ExpectedChecksum optionally pins the sha256 digest the cni-plugins archive must match.
+	// If empty, the checksum published by the source is used.
+	ExpectedChecksum []byte
 }
 
 func Install(ctx context.Context, opts InstallOptions) error {
@@ -81,12 +84,12 @@ func downloadFileTo(ctx context.Context, opts InstallOptions) error {
 	}
 	defer cniPlugins.Close()
 
-	if err := artifact.InstallFile(filepath.Join(opts.InstallRoot, TgzPath), cniPlugins, 0o755); err != nil {
-		return errors.Wrap(err, "installing cni-plugins archive")
+	expectedChecksum := opts.ExpectedChecksum
+	if len(expectedChecksum) == 0 {
+		expectedChecksum = cniPlugins.ExpectedChecksum()
 	}
-
-	if !cniPlugins.VerifyChecksum() {
-		return errors.Errorf("cni-plugins checksum mismatch: %v", artifact.NewChecksumError(cniPlugins))
+	if err := artifact.InstallFileWithChecksum(filepath.Join(opts.InstallRoot, TgzPath), cniPlugins, 0o755, expectedChecksum); err != nil {
+		return errors.Wrap(err, "installing cni-plugins archive")
 	}
 
 	return nil
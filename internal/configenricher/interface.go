@@ -9,6 +9,8 @@ import (
 // ConfigEnricherConfig holds the configuration options
 type ConfigEnricherConfig struct {
 	RegionConfig *aws.RegionData
+	// SandboxImage replaces the default sandbox image when set.
+	SandboxImage string
 }
 
 // ConfigEnricherOption is a function that modifies ConfigEnricherConfig
@@ -21,6 +23,13 @@ func WithRegionConfig(regionConfig *aws.RegionData) ConfigEnricherOption {
 	}
 }
 
+// WithSandboxImage creates a ConfigEnricherOption that sets the sandbox image used instead of the default
+func WithSandboxImage(image string) ConfigEnricherOption {
+	return func(config *ConfigEnricherConfig) {
+		config.SandboxImage = image
+	}
+}
+
 type ConfigEnricher interface {
 	Enrich(ctx context.Context, opts ...ConfigEnricherOption) error
 }
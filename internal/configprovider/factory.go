@@ -9,7 +9,11 @@ import (
 // The source URL must have a scheme, and the supported schemes are:
 // - `file`. To use configuration from the filesystem: `file:///path/to/file/or/directory`.
 // - `imds`. To use configuration from the instance's user data: `imds://user-data`.
+// - `stdin`. To read the configuration from standard input: `stdin://` or simply `-`.
 func BuildConfigProvider(rawConfigSourceURL string) (ConfigProvider, error) {
+	if rawConfigSourceURL == StdinSource {
+		return NewStdinConfigProvider(), nil
+	}
 	parsedURL, err := url.Parse(rawConfigSourceURL)
 	if err != nil {
 		return nil, err
@@ -17,6 +21,8 @@ func BuildConfigProvider(rawConfigSourceURL string) (ConfigProvider, error) {
 	switch parsedURL.Scheme {
 	case "imds":
 		return NewUserDataConfigProvider(), nil
+	case "stdin":
+		return NewStdinConfigProvider(), nil
 	case "file":
 		source := getURLWithoutScheme(parsedURL)
 		return NewFileConfigProvider(source), nil
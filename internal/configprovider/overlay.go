@@ -0,0 +1,65 @@
+package configprovider
+
+import (
+	"fmt"
+
+	internalapi "github.com/aws/eks-hybrid/internal/api"
+)
+
+type overlayConfigProvider struct {
+	base    ConfigProvider
+	overlay ConfigProvider
+}
+
+// NewOverlayConfigProvider returns a ConfigProvider that deep-merges the overlay
+// node configuration on top of the base one, following the same rules used to merge
+// multiple NodeConfigs in user data: values set in the overlay win, maps and the
+// kubelet config are merged key by key, kubelet flags are appended and any other
+// list is replaced.
+func NewOverlayConfigProvider(base, overlay ConfigProvider) ConfigProvider {
+	return &overlayConfigProvider{
+		base:    base,
+		overlay: overlay,
+	}
+}
+
+func (ocp *overlayConfigProvider) Provide() (*internalapi.NodeConfig, error) {
+	config, err := ocp.base.Provide()
+	if err != nil {
+		return nil, fmt.Errorf("reading base node config: %w", err)
+	}
+	overlay, err := ocp.overlay.Provide()
+	if err != nil {
+		return nil, fmt.Errorf("reading node config overlay: %w", err)
+	}
+	if err := config.Merge(overlay); err != nil {
+		return nil, fmt.Errorf("merging node config overlay: %w", err)
+	}
+	return config, nil
+}
+
+// BuildConfigProviderWithOverlay returns a ConfigProvider for the source URL that,
+// if overlaySourceURL is not empty, merges the configuration from the overlay source
+// on top of it. Both sources support the same schemes as BuildConfigProvider.
+func BuildConfigProviderWithOverlay(rawConfigSourceURL, overlaySourceURL string) (ConfigProvider, error) {
+	base, err := BuildConfigProvider(rawConfigSourceURL)
+	if err != nil {
+		return nil, err
+	}
+	if overlaySourceURL == "" {
+		return base, nil
+	}
+	overlay, err := BuildConfigProvider(overlaySourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("config overlay: %w", err)
+	}
+	if isStdin(base) && isStdin(overlay) {
+		return nil, fmt.Errorf("config source and config overlay can't both read from standard input")
+	}
+	return NewOverlayConfigProvider(base, overlay), nil
+}
+
+func isStdin(provider ConfigProvider) bool {
+	_, ok := provider.(*readerConfigProvider)
+	return ok
+}
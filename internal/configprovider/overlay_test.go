@@ -0,0 +1,228 @@
+package configprovider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const baseHybridNodeConfig = `---
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+    region: us-west-2
+  hybrid:
+    iamRolesAnywhere:
+      nodeName: placeholder
+      trustAnchorArn: arn:aws:rolesanywhere:us-west-2:123456789010:trust-anchor/anchor
+      profileArn: arn:aws:rolesanywhere:us-west-2:123456789010:profile/profile
+      roleArn: arn:aws:iam::123456789010:role/hybrid-node
+  kubelet:
+    config:
+      maxPods: 110
+      clusterDNS:
+      - 10.100.0.10
+      evictionHard:
+        memory.available: 100Mi
+        nodefs.available: 10%
+    flags:
+      - --node-labels=fleet=edge
+`
+
+const nodeOverlayConfig = `---
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  hybrid:
+    iamRolesAnywhere:
+      nodeName: node-01
+  kubelet:
+    config:
+      clusterDNS:
+      - 10.100.0.53
+      evictionHard:
+        memory.available: 500Mi
+    flags:
+      - --node-ip=10.0.0.21
+`
+
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return "file://" + path
+}
+
+func TestBuildConfigProviderWithOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := writeConfig(t, dir, "base.yaml", baseHybridNodeConfig)
+	overlay := writeConfig(t, dir, "node.yaml", nodeOverlayConfig)
+
+	provider, err := BuildConfigProviderWithOverlay(base, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := provider.Provide()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedHybrid := &api.HybridOptions{
+		IAMRolesAnywhere: &api.IAMRolesAnywhere{
+			NodeName:       "node-01",
+			TrustAnchorARN: "arn:aws:rolesanywhere:us-west-2:123456789010:trust-anchor/anchor",
+			ProfileARN:     "arn:aws:rolesanywhere:us-west-2:123456789010:profile/profile",
+			RoleARN:        "arn:aws:iam::123456789010:role/hybrid-node",
+		},
+	}
+	if !reflect.DeepEqual(config.Spec.Hybrid, expectedHybrid) {
+		t.Errorf("\nexpected hybrid: %+v\n\ngot:             %+v", expectedHybrid.IAMRolesAnywhere, config.Spec.Hybrid.IAMRolesAnywhere)
+	}
+	if config.Spec.Cluster.Name != "my-cluster" || config.Spec.Cluster.Region != "us-west-2" {
+		t.Errorf("expected cluster details from base, got %+v", config.Spec.Cluster)
+	}
+
+	expectedKubelet := api.KubeletOptions{
+		Config: api.InlineDocument{
+			// Values only in the base are kept.
+			"maxPods": runtime.RawExtension{Raw: []byte("110")},
+			// Lists are replaced by the overlay.
+			"clusterDNS": runtime.RawExtension{Raw: []byte(`["10.100.0.53"]`)},
+			// Maps are merged key by key with the overlay winning.
+			"evictionHard": runtime.RawExtension{Raw: []byte(`{"memory.available":"500Mi","nodefs.available":"10%"}`)},
+		},
+		// Kubelet flags are appended so flags from both sources apply.
+		Flags: []string{"--node-labels=fleet=edge", "--node-ip=10.0.0.21"},
+	}
+	if !reflect.DeepEqual(config.Spec.Kubelet.Flags, expectedKubelet.Flags) {
+		t.Errorf("expected kubelet flags %v, got %v", expectedKubelet.Flags, config.Spec.Kubelet.Flags)
+	}
+	for key, expected := range expectedKubelet.Config {
+		if got := string(config.Spec.Kubelet.Config[key].Raw); got != string(expected.Raw) {
+			t.Errorf("expected kubelet config %s to be %s, got %s", key, expected.Raw, got)
+		}
+	}
+	if len(config.Spec.Kubelet.Config) != len(expectedKubelet.Config) {
+		t.Errorf("expected kubelet config keys %d, got %d", len(expectedKubelet.Config), len(config.Spec.Kubelet.Config))
+	}
+}
+
+func TestBuildConfigProviderWithOverlaySwitchesCredentialProvider(t *testing.T) {
+	dir := t.TempDir()
+	base := writeConfig(t, dir, "base.yaml", `---
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  cluster:
+    name: my-cluster
+    region: us-west-2
+`)
+	overlay := writeConfig(t, dir, "node.yaml", `---
+apiVersion: node.eks.aws/v1alpha1
+kind: NodeConfig
+spec:
+  hybrid:
+    ssm:
+      activationCode: code
+      activationId: id
+`)
+
+	provider, err := BuildConfigProviderWithOverlay(base, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := provider.Provide()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.IsSSM() || config.Spec.Hybrid.SSM.ActivationID != "id" {
+		t.Errorf("expected ssm config from overlay, got %+v", config.Spec.Hybrid)
+	}
+	if config.Spec.Cluster.Name != "my-cluster" {
+		t.Errorf("expected cluster name from base, got %s", config.Spec.Cluster.Name)
+	}
+}
+
+func TestBuildConfigProviderWithoutOverlay(t *testing.T) {
+	base := writeConfig(t, t.TempDir(), "base.yaml", baseHybridNodeConfig)
+	provider, err := BuildConfigProviderWithOverlay(base, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := provider.(*fileConfigProvider); !ok {
+		t.Errorf("expected file config provider, got %T", provider)
+	}
+}
+
+func TestBuildConfigProviderWithOverlayErrors(t *testing.T) {
+	dir := t.TempDir()
+	base := writeConfig(t, dir, "base.yaml", baseHybridNodeConfig)
+	invalid := writeConfig(t, dir, "invalid.yaml", nodeOverlayConfig+"  unknownField: true\n")
+
+	tests := []struct {
+		name          string
+		source        string
+		overlay       string
+		buildErr      string
+		provideErrMsg string
+	}{
+		{
+			name:     "both from stdin",
+			source:   "-",
+			overlay:  "stdin://",
+			buildErr: "can't both read from standard input",
+		},
+		{
+			name:     "unsupported overlay scheme",
+			source:   base,
+			overlay:  "https://example.com/node.yaml",
+			buildErr: "config overlay: unsupported scheme: https",
+		},
+		{
+			name:          "missing overlay",
+			source:        base,
+			overlay:       "file://" + filepath.Join(dir, "missing.yaml"),
+			provideErrMsg: "reading node config overlay",
+		},
+		{
+			name:          "invalid overlay",
+			source:        base,
+			overlay:       invalid,
+			provideErrMsg: "reading node config overlay",
+		},
+		{
+			name:          "missing base",
+			source:        "file://" + filepath.Join(dir, "missing.yaml"),
+			overlay:       base,
+			provideErrMsg: "reading base node config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := BuildConfigProviderWithOverlay(tt.source, tt.overlay)
+			if tt.buildErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.buildErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.buildErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := provider.Provide(); err == nil || !strings.Contains(err.Error(), tt.provideErrMsg) {
+				t.Fatalf("expected error containing %q, got %v", tt.provideErrMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package configprovider
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	internalapi "github.com/aws/eks-hybrid/internal/api"
+	apibridge "github.com/aws/eks-hybrid/internal/api/bridge"
+)
+
+// StdinSource is the config source value that reads the node configuration from standard input.
+const StdinSource = "-"
+
+type readerConfigProvider struct {
+	reader io.Reader
+}
+
+// NewReaderConfigProvider returns a ConfigProvider that reads the node configuration from r.
+func NewReaderConfigProvider(r io.Reader) ConfigProvider {
+	return &readerConfigProvider{
+		reader: r,
+	}
+}
+
+// NewStdinConfigProvider returns a ConfigProvider that reads the node configuration from standard input.
+func NewStdinConfigProvider() ConfigProvider {
+	return NewReaderConfigProvider(os.Stdin)
+}
+
+func (rcp *readerConfigProvider) Provide() (*internalapi.NodeConfig, error) {
+	data, err := io.ReadAll(rcp.reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading node config: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("node config input is empty")
+	}
+	config, err := apibridge.DecodeStrictNodeConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
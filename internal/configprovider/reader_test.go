@@ -0,0 +1,47 @@
+package configprovider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReaderConfigProvider(t *testing.T) {
+	provider := NewReaderConfigProvider(strings.NewReader(completeNodeConfig))
+	config, err := provider.Provide()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Spec.Cluster.Name != "autofill" {
+		t.Errorf("expected cluster name autofill, got %s", config.Spec.Cluster.Name)
+	}
+	if config.Spec.Cluster.CIDR != "10.100.0.0/16" {
+		t.Errorf("expected cluster cidr 10.100.0.0/16, got %s", config.Spec.Cluster.CIDR)
+	}
+}
+
+func TestReaderConfigProviderEmptyInput(t *testing.T) {
+	provider := NewReaderConfigProvider(strings.NewReader(""))
+	if _, err := provider.Provide(); err == nil {
+		t.Fatalf("expected err for empty input")
+	}
+}
+
+func TestReaderConfigProviderStrictDecoding(t *testing.T) {
+	config := completeNodeConfig + "  unknownField: true\n"
+	provider := NewReaderConfigProvider(strings.NewReader(config))
+	if _, err := provider.Provide(); err == nil {
+		t.Fatalf("expected err for unknown field")
+	}
+}
+
+func TestBuildConfigProviderStdin(t *testing.T) {
+	for _, source := range []string{"-", "stdin://"} {
+		provider, err := BuildConfigProvider(source)
+		if err != nil {
+			t.Fatalf("source %q: %v", source, err)
+		}
+		if _, ok := provider.(*readerConfigProvider); !ok {
+			t.Errorf("source %q: expected reader config provider, got %T", source, provider)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package containerd
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+// Cgroup drivers of the runc runtime, named like the kubelet cgroupDriver values.
+const (
+	CgroupDriverSystemd  = "systemd"
+	CgroupDriverCgroupfs = "cgroupfs"
+)
+
+// nodeadmDropInConfig is the drop-in config init writes the containerd config of the node config to.
+const nodeadmDropInConfig = "00-nodeadm.toml"
+
+// CgroupDriver returns the cgroup driver containerd runs runc containers with once init writes
+// cfg: the main config nodeadm generates or, when configMain is true because nodeadm won't write
+// it, the one on disk, followed by the drop-in configs with the one from cfg.
+func CgroupDriver(cfg *api.NodeConfig, configMain bool) (string, error) {
+	files, err := loadContainerdConfigFiles(cfg, configMain)
+	if err != nil {
+		return "", err
+	}
+	return cgroupDriver(files...), nil
+}
+
+// withDropInConfig replaces the drop-in config at the path of dropIn in files, or adds it
+// where containerd would import it.
+func withDropInConfig(files []configFile, dropIn configFile) []configFile {
+	files = slices.DeleteFunc(files, func(f configFile) bool { return f.path == dropIn.path })
+	i := slices.IndexFunc(files, func(f configFile) bool {
+		return filepath.Dir(f.path) == containerdConfigImportDir && f.path > dropIn.path
+	})
+	if i < 0 {
+		return append(files, dropIn)
+	}
+	return slices.Insert(files, i, dropIn)
+}
+
+// cgroupDriver returns the cgroup driver of the runc runtime for files as merged by containerd,
+// where the last SystemdCgroup wins and containerd defaults to cgroupfs.
+func cgroupDriver(files ...configFile) string {
+	systemdCgroup := false
+	for _, file := range files {
+		if config := parseContainerdConfig(file.data); config.systemdCgroup != nil {
+			systemdCgroup = *config.systemdCgroup
+		}
+	}
+	if systemdCgroup {
+		return CgroupDriverSystemd
+	}
+	return CgroupDriverCgroupfs
+}
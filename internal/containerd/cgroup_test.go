@@ -0,0 +1,85 @@
+package containerd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestCgroupDriver(t *testing.T) {
+	nodeadmConfig, err := generateContainerdConfig(&api.NodeConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		files []configFile
+		want  string
+	}{
+		{
+			name: "containerd default",
+			want: CgroupDriverCgroupfs,
+		},
+		{
+			name:  "nodeadm config",
+			files: []configFile{{path: containerdConfigFile, data: nodeadmConfig}},
+			want:  CgroupDriverSystemd,
+		},
+		{
+			name: "drop-in disables systemd cgroup",
+			files: []configFile{
+				{path: containerdConfigFile, data: nodeadmConfig},
+				{path: "/etc/containerd/config.d/10-cgroupfs.toml", data: []byte(`
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc.options]
+  SystemdCgroup = false
+`)},
+			},
+			want: CgroupDriverCgroupfs,
+		},
+		{
+			name: "containerd 2 config enables systemd cgroup",
+			files: []configFile{{path: containerdConfigFile, data: []byte(`version = 3
+[plugins.'io.containerd.cri.v1.runtime'.containerd.runtimes.'runc'.options]
+  SystemdCgroup = true # kubelet uses systemd
+`)}},
+			want: CgroupDriverSystemd,
+		},
+		{
+			name: "other runtime options are ignored",
+			files: []configFile{
+				{path: containerdConfigFile, data: nodeadmConfig},
+				{path: "/etc/containerd/config.d/10-kata.toml", data: []byte(`
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.kata.options]
+  SystemdCgroup = false
+`)},
+			},
+			want: CgroupDriverSystemd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cgroupDriver(tt.files...))
+		})
+	}
+}
+
+func TestWithDropInConfig(t *testing.T) {
+	main := configFile{path: containerdConfigFile}
+	dropIn := configFile{path: "/etc/containerd/config.d/00-nodeadm.toml", data: []byte("new")}
+
+	files := withDropInConfig([]configFile{
+		main,
+		{path: "/etc/containerd/config.d/00-nodeadm.toml", data: []byte("old")},
+		{path: "/etc/containerd/config.d/10-user.toml"},
+	}, dropIn)
+	assert.Equal(t, []configFile{main, dropIn, {path: "/etc/containerd/config.d/10-user.toml"}}, files)
+
+	files = withDropInConfig([]configFile{main, {path: "/etc/containerd/config.d/10-user.toml"}}, dropIn)
+	assert.Equal(t, []configFile{main, dropIn, {path: "/etc/containerd/config.d/10-user.toml"}}, files)
+
+	files = withDropInConfig([]configFile{main}, dropIn)
+	assert.Equal(t, []configFile{main, dropIn}, files)
+}
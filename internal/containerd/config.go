@@ -32,7 +32,9 @@ var (
 )
 
 type containerdTemplateVars struct {
-	SandboxImage string
+	SandboxImage             string
+	MaxConcurrentDownloads   int32
+	ImagePullProgressTimeout string
 }
 
 func writeContainerdConfig(cfg *api.NodeConfig) error {
@@ -55,7 +57,9 @@ func writeContainerdConfig(cfg *api.NodeConfig) error {
 
 func generateContainerdConfig(cfg *api.NodeConfig) ([]byte, error) {
 	configVars := containerdTemplateVars{
-		SandboxImage: cfg.Status.Defaults.SandboxImage,
+		SandboxImage:             cfg.Status.Defaults.SandboxImage,
+		MaxConcurrentDownloads:   cfg.Spec.Containerd.MaxConcurrentDownloads,
+		ImagePullProgressTimeout: cfg.Spec.Containerd.ImagePullProgressTimeout,
 	}
 	var buf bytes.Buffer
 	if err := containerdConfigTemplate.Execute(&buf, configVars); err != nil {
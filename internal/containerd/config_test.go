@@ -0,0 +1,75 @@
+package containerd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+func TestGenerateContainerdConfigImagePullSettings(t *testing.T) {
+	tests := []struct {
+		name       string
+		containerd api.ContainerdOptions
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:    "containerd defaults",
+			notWant: []string{"max_concurrent_downloads", "image_pull_progress_timeout"},
+		},
+		{
+			name: "tuned for a slow link",
+			containerd: api.ContainerdOptions{
+				MaxConcurrentDownloads:   1,
+				ImagePullProgressTimeout: "30m",
+			},
+			want: []string{
+				`    sandbox_image = "registry.example.com/eks/pause:3.10"
+    max_concurrent_downloads = 1
+    image_pull_progress_timeout = "30m"
+  [plugins."io.containerd.grpc.v1.cri".registry]`,
+			},
+		},
+		{
+			name:       "only the progress timeout",
+			containerd: api.ContainerdOptions{ImagePullProgressTimeout: "15m"},
+			want:       []string{`image_pull_progress_timeout = "15m"`},
+			notWant:    []string{"max_concurrent_downloads"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &api.NodeConfig{
+				Spec:   api.NodeConfigSpec{Containerd: tc.containerd},
+				Status: api.NodeConfigStatus{Defaults: api.DefaultOptions{SandboxImage: "registry.example.com/eks/pause:3.10"}},
+			}
+
+			config, err := generateContainerdConfig(cfg)
+			assert.NoError(t, err)
+			for _, want := range tc.want {
+				assert.Contains(t, string(config), want)
+			}
+			for _, notWant := range tc.notWant {
+				assert.NotContains(t, string(config), notWant)
+			}
+			// The rendered config stays valid for the settings nodeadm validates.
+			assert.NoError(t, validateContainerdConfig(configFile{path: containerdConfigFile, data: config}))
+		})
+	}
+}
+
+func TestGenerateContainerdConfigSandboxImage(t *testing.T) {
+	cfg := &api.NodeConfig{
+		Status: api.NodeConfigStatus{Defaults: api.DefaultOptions{SandboxImage: "mirror.example.com:5000/eks/pause:3.10"}},
+	}
+
+	config, err := generateContainerdConfig(cfg)
+	assert.NoError(t, err)
+	assert.Contains(t, string(config), `
+  [plugins."io.containerd.grpc.v1.cri"]
+    sandbox_image = "mirror.example.com:5000/eks/pause:3.10"
+`)
+	assert.NoError(t, validateContainerdConfig(configFile{path: containerdConfigFile, data: config}))
+}
@@ -0,0 +1,248 @@
+package containerd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	runcRuntimeName = "runc"
+	runcRuntimeType = "io.containerd.runc.v2"
+)
+
+// criPluginNames are the names the CRI plugin can be disabled with, for containerd 1.x and 2.x configs.
+var criPluginNames = []string{
+	"cri",
+	"io.containerd.grpc.v1.cri",
+	"io.containerd.cri.v1.runtime",
+	"io.containerd.cri.v1.images",
+}
+
+// criRuntimeTables are the tables holding the CRI runtime config, for version 2 and 3 configs.
+var criRuntimeTables = []string{
+	`plugins."io.containerd.grpc.v1.cri".containerd`,
+	`plugins."io.containerd.cri.v1.runtime".containerd`,
+}
+
+// criSnapshotterTables are the tables holding the CRI snapshotter, for version 2 and 3 configs.
+var criSnapshotterTables = []string{
+	`plugins."io.containerd.grpc.v1.cri".containerd`,
+	`plugins."io.containerd.cri.v1.images"`,
+}
+
+var (
+	tomlTableRegex    = regexp.MustCompile(`^\[\s*([^\[\]]+?)\s*\]$`)
+	tomlKeyValueRegex = regexp.MustCompile(`^([A-Za-z0-9_.-]+)\s*=\s*(.*)$`)
+	tomlStringRegex   = regexp.MustCompile(`['"]([^'"]*)['"]`)
+)
+
+// configFile is a containerd config file and its content.
+type configFile struct {
+	path string
+	data []byte
+}
+
+// ValidateContainerdConfig validates the containerd config once init writes cfg: the main config
+// nodeadm generates or, when configMain is true because nodeadm won't write it, the one on disk,
+// followed by the drop-in configs with the one from cfg. It returns an error if they disable the
+// CRI plugin or configure a default runtime other than runc, which makes kubelet fail to run pods.
+func ValidateContainerdConfig(cfg *api.NodeConfig, configMain bool) error {
+	files, err := loadContainerdConfigFiles(cfg, configMain)
+	if err != nil {
+		return err
+	}
+	return validateContainerdConfig(files...)
+}
+
+// loadContainerdConfigFiles returns the containerd config files active once init writes cfg,
+// in the order containerd merges them.
+func loadContainerdConfigFiles(cfg *api.NodeConfig, configMain bool) ([]configFile, error) {
+	files, err := readContainerdConfigFiles(configMain)
+	if err != nil {
+		return nil, err
+	}
+	return withNodeConfig(cfg, configMain, files)
+}
+
+// withNodeConfig returns files, the containerd configs on disk, with the ones init writes for cfg:
+// the generated main config, unless configMain is true, and the drop-in config of cfg.
+func withNodeConfig(cfg *api.NodeConfig, configMain bool, files []configFile) ([]configFile, error) {
+	if !configMain {
+		mainConfig, err := generateContainerdConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		files = append([]configFile{{path: containerdConfigFile, data: mainConfig}}, files...)
+	}
+	if cfg.Spec.Containerd.Config != "" {
+		files = withDropInConfig(files, configFile{
+			path: filepath.Join(containerdConfigImportDir, nodeadmDropInConfig),
+			data: []byte(cfg.Spec.Containerd.Config),
+		})
+	}
+	return files, nil
+}
+
+// readContainerdConfigFiles reads the drop-in configs and, when configMain is true, the main
+// config, in the order containerd merges them. Missing files are ignored.
+func readContainerdConfigFiles(configMain bool) ([]configFile, error) {
+	paths, err := filepath.Glob(filepath.Join(containerdConfigImportDir, "*.toml"))
+	if err != nil {
+		return nil, err
+	}
+	if configMain {
+		paths = append([]string{containerdConfigFile}, paths...)
+	}
+
+	files := make([]configFile, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("reading containerd config %s: %w", path, err)
+		}
+		files = append(files, configFile{path: path, data: data})
+	}
+	return files, nil
+}
+
+// validateContainerdConfig validates files as merged by containerd, where disabled plugins
+// are added up and later files override the runtime settings of earlier ones.
+func validateContainerdConfig(files ...configFile) error {
+	var errs []error
+	defaultRuntime, defaultRuntimeFile := runcRuntimeName, ""
+	runtimeTypes := map[string]string{}
+	runtimeTypeFiles := map[string]string{}
+
+	for _, file := range files {
+		config := parseContainerdConfig(file.data)
+		for _, plugin := range config.disabledPlugins {
+			if slices.Contains(criPluginNames, plugin) {
+				errs = append(errs, validation.WithRemediation(
+					fmt.Errorf("containerd config %s disables the CRI plugin %s, kubelet can't run pods without it", file.path, plugin),
+					fmt.Sprintf("Remove %q from disabled_plugins in %s, it is often left by the docker package config.", plugin, file.path),
+				))
+			}
+		}
+		if config.defaultRuntime != "" {
+			defaultRuntime, defaultRuntimeFile = config.defaultRuntime, file.path
+		}
+		for name, runtimeType := range config.runtimeTypes {
+			runtimeTypes[name], runtimeTypeFiles[name] = runtimeType, file.path
+		}
+	}
+
+	if defaultRuntime != runcRuntimeName {
+		errs = append(errs, validation.WithRemediation(
+			fmt.Errorf("containerd config %s sets the default CRI runtime to %s, nodeadm configures kubelet for %s", defaultRuntimeFile, defaultRuntime, runcRuntimeName),
+			fmt.Sprintf("Set default_runtime_name to %q in %s or remove it to use the nodeadm default.", runcRuntimeName, defaultRuntimeFile),
+		))
+	} else if runtimeType, ok := runtimeTypes[runcRuntimeName]; ok && runtimeType != runcRuntimeType {
+		errs = append(errs, validation.WithRemediation(
+			fmt.Errorf("containerd config %s sets the runtime_type of the %s runtime to %s, expected %s", runtimeTypeFiles[runcRuntimeName], runcRuntimeName, runtimeType, runcRuntimeType),
+			fmt.Sprintf("Set runtime_type to %q for the %s runtime in %s or remove it to use the nodeadm default.", runcRuntimeType, runcRuntimeName, runtimeTypeFiles[runcRuntimeName]),
+		))
+	}
+
+	return errors.Join(errs...)
+}
+
+// containerdConfig is the part of a containerd config validated by nodeadm.
+type containerdConfig struct {
+	disabledPlugins []string
+	defaultRuntime  string
+	// runtimeTypes maps the CRI runtime names to their runtime_type.
+	runtimeTypes map[string]string
+	root         string
+	snapshotter  string
+	// systemdCgroup is the SystemdCgroup option of the runc runtime, nil when not set.
+	systemdCgroup *bool
+}
+
+// parseContainerdConfig reads the disabled plugins, root and CRI snapshotter, runtime and runc cgroup settings from
+// a containerd TOML config. It only understands the table and key = value forms containerd configs use.
+func parseContainerdConfig(data []byte) containerdConfig {
+	config := containerdConfig{runtimeTypes: map[string]string{}}
+	table := ""
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := stripTOMLComment(lines[i])
+		if line == "" {
+			continue
+		}
+		if matches := tomlTableRegex.FindStringSubmatch(line); matches != nil {
+			// Keys can be quoted with either quote, the tables are compared with double quotes.
+			table = strings.ReplaceAll(strings.Join(strings.Fields(matches[1]), ""), "'", `"`)
+			continue
+		}
+		matches := tomlKeyValueRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		key, value := matches[1], matches[2]
+		// Arrays can span several lines.
+		for strings.HasPrefix(value, "[") && !strings.Contains(value, "]") && i+1 < len(lines) {
+			i++
+			value += " " + stripTOMLComment(lines[i])
+		}
+
+		switch {
+		case table == "" && key == "disabled_plugins":
+			for _, plugin := range tomlStringRegex.FindAllStringSubmatch(value, -1) {
+				config.disabledPlugins = append(config.disabledPlugins, plugin[1])
+			}
+		case table == "" && key == "root":
+			config.root = tomlString(value)
+		case slices.Contains(criSnapshotterTables, table) && key == "snapshotter":
+			config.snapshotter = tomlString(value)
+		case slices.Contains(criRuntimeTables, table) && key == "default_runtime_name":
+			config.defaultRuntime = tomlString(value)
+		case key == "runtime_type":
+			for _, runtimeTable := range criRuntimeTables {
+				if name, ok := strings.CutPrefix(table, runtimeTable+".runtimes."); ok {
+					config.runtimeTypes[strings.Trim(name, `"'`)] = tomlString(value)
+				}
+			}
+		case key == "SystemdCgroup":
+			for _, runtimeTable := range criRuntimeTables {
+				if table == runtimeTable+".runtimes.runc.options" || table == runtimeTable+`.runtimes."runc".options` {
+					systemdCgroup := value == "true"
+					config.systemdCgroup = &systemdCgroup
+				}
+			}
+		}
+	}
+	return config
+}
+
+// stripTOMLComment trims line and removes its comment, unless the # is in a string.
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return strings.TrimSpace(line[:i])
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+func tomlString(value string) string {
+	if matches := tomlStringRegex.FindStringSubmatch(value); matches != nil {
+		return matches[1]
+	}
+	return value
+}
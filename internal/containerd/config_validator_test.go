@@ -0,0 +1,158 @@
+package containerd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestValidateContainerdConfig(t *testing.T) {
+	nodeadmConfig, err := generateContainerdConfig(&api.NodeConfig{})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name                string
+		files               []configFile
+		wantErrs            []string
+		wantRemediationPart string
+	}{
+		{
+			name:  "nodeadm config",
+			files: []configFile{{path: containerdConfigFile, data: nodeadmConfig}},
+		},
+		{
+			name: "drop-in with other settings",
+			files: []configFile{
+				{path: containerdConfigFile, data: nodeadmConfig},
+				{path: "/etc/containerd/config.d/00-nodeadm.toml", data: []byte(`
+[plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+  endpoint = ["https://mirror.example.com"] # disabled_plugins = ["cri"]
+`)},
+			},
+		},
+		{
+			name: "docker package config disables cri",
+			files: []configFile{{path: containerdConfigFile, data: []byte(`#   Copyright 2018-2022 Docker Inc.
+disabled_plugins = ["cri"]
+
+#root = "/var/lib/containerd"
+`)}},
+			wantErrs:            []string{"containerd config /etc/containerd/config.toml disables the CRI plugin cri, kubelet can't run pods without it"},
+			wantRemediationPart: `Remove "cri" from disabled_plugins in /etc/containerd/config.toml`,
+		},
+		{
+			name: "drop-in disables cri plugin in multi-line array",
+			files: []configFile{
+				{path: containerdConfigFile, data: nodeadmConfig},
+				{path: "/etc/containerd/config.d/10-stale.toml", data: []byte(`version = 2
+disabled_plugins = [
+  "io.containerd.internal.v1.opt",
+  "io.containerd.grpc.v1.cri",
+]
+`)},
+			},
+			wantErrs: []string{"containerd config /etc/containerd/config.d/10-stale.toml disables the CRI plugin io.containerd.grpc.v1.cri, kubelet can't run pods without it"},
+		},
+		{
+			name: "drop-in overrides default runtime",
+			files: []configFile{
+				{path: containerdConfigFile, data: nodeadmConfig},
+				{path: "/etc/containerd/config.d/10-kata.toml", data: []byte(`[plugins."io.containerd.grpc.v1.cri".containerd]
+  default_runtime_name = "kata"
+`)},
+			},
+			wantErrs:            []string{"containerd config /etc/containerd/config.d/10-kata.toml sets the default CRI runtime to kata, nodeadm configures kubelet for runc"},
+			wantRemediationPart: `Set default_runtime_name to "runc"`,
+		},
+		{
+			name: "version 3 config with wrong runc runtime type",
+			files: []configFile{{path: containerdConfigFile, data: []byte(`version = 3
+disabled_plugins = ['io.containerd.cri.v1.images']
+[plugins.'io.containerd.cri.v1.runtime'.containerd]
+  default_runtime_name = 'runc'
+  [plugins.'io.containerd.cri.v1.runtime'.containerd.runtimes.runc]
+    runtime_type = 'io.containerd.runsc.v1'
+`)}},
+			wantErrs: []string{
+				"containerd config /etc/containerd/config.toml disables the CRI plugin io.containerd.cri.v1.images, kubelet can't run pods without it",
+				"containerd config /etc/containerd/config.toml sets the runtime_type of the runc runtime to io.containerd.runsc.v1, expected io.containerd.runc.v2",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateContainerdConfig(tc.files...)
+			if len(tc.wantErrs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			errs := validation.Unwrap(err)
+			var messages []string
+			for _, e := range errs {
+				messages = append(messages, e.Error())
+			}
+			assert.Equal(t, tc.wantErrs, messages)
+			if tc.wantRemediationPart != "" {
+				assert.Contains(t, validation.Remediation(errs[0]), tc.wantRemediationPart)
+			}
+		})
+	}
+}
+
+func TestWithNodeConfig(t *testing.T) {
+	nodeadmConfig, err := generateContainerdConfig(&api.NodeConfig{})
+	require.NoError(t, err)
+	onDiskMain := configFile{path: containerdConfigFile, data: []byte(`disabled_plugins = ["cri"]`)}
+	onDiskDropIn := configFile{path: "/etc/containerd/config.d/00-nodeadm.toml", data: []byte(`disabled_plugins = ["cri"]`)}
+	userDropIn := configFile{path: "/etc/containerd/config.d/10-user.toml"}
+	nodeConfigDropIn := configFile{path: "/etc/containerd/config.d/00-nodeadm.toml", data: []byte("# node config")}
+
+	withDropIn := &api.NodeConfig{}
+	withDropIn.Spec.Containerd.Config = "# node config"
+
+	tests := []struct {
+		name       string
+		cfg        *api.NodeConfig
+		configMain bool
+		files      []configFile
+		want       []configFile
+	}{
+		{
+			name:  "generated main config",
+			cfg:   &api.NodeConfig{},
+			files: []configFile{userDropIn},
+			want:  []configFile{{path: containerdConfigFile, data: nodeadmConfig}, userDropIn},
+		},
+		{
+			name:       "main config on disk",
+			cfg:        &api.NodeConfig{},
+			configMain: true,
+			files:      []configFile{onDiskMain, userDropIn},
+			want:       []configFile{onDiskMain, userDropIn},
+		},
+		{
+			name:  "node config drop-in replaces the one on disk",
+			cfg:   withDropIn,
+			files: []configFile{onDiskDropIn, userDropIn},
+			want:  []configFile{{path: containerdConfigFile, data: nodeadmConfig}, nodeConfigDropIn, userDropIn},
+		},
+		{
+			name:       "node config drop-in with the main config on disk",
+			cfg:        withDropIn,
+			configMain: true,
+			files:      []configFile{onDiskMain, userDropIn},
+			want:       []configFile{onDiskMain, nodeConfigDropIn, userDropIn},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := withNodeConfig(tc.cfg, tc.configMain, tc.files)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, files)
+		})
+	}
+}
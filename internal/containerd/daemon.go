@@ -71,7 +71,7 @@ func (cd *containerd) EnsureRunning(ctx context.Context) error {
 }
 
 func (cd *containerd) PostLaunch() error {
-	return cacheSandboxImage(cd.awsConfig)
+	return cacheSandboxImage(cd.awsConfig, ImageServiceEndpoint(cd.nodeConfig))
 }
 
 func (cd *containerd) Stop() error {
@@ -0,0 +1,92 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/retry"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const imagePullAuthRemediation = "Ensure the node credentials are allowed to pull from ECR, with ecr:GetAuthorizationToken, " +
+	"ecr:BatchGetImage and ecr:GetDownloadUrlForLayer permissions, and the image credential provider config points to the installed provider."
+
+// ImagePuller pulls images through the CRI image service.
+type ImagePuller interface {
+	PullImage(ctx context.Context, in *v1.PullImageRequest, opts ...grpc.CallOption) (*v1.PullImageResponse, error)
+}
+
+// ImagePullSmokeTest pulls image through the runtime serving endpoint with the given registry
+// credentials, retrying transient failures until ctx is done.
+func ImagePullSmokeTest(ctx context.Context, endpoint, image string, auth *v1.AuthConfig, logger *zap.Logger) error {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("connecting to container runtime at %s: %w", endpoint, err)
+	}
+	defer conn.Close()
+	return PullImageWithRetry(ctx, v1.NewImageServiceClient(conn), image, auth, 2*time.Second, logger)
+}
+
+// PullImageWithRetry pulls image, retrying with an exponential backoff starting at backoff until
+// the pull succeeds or ctx is done. Authentication failures are not retried.
+func PullImageWithRetry(ctx context.Context, puller ImagePuller, image string, auth *v1.AuthConfig, backoff time.Duration, logger *zap.Logger) error {
+	request := &v1.PullImageRequest{
+		Image: &v1.ImageSpec{Image: image},
+		Auth:  auth,
+	}
+	var pullErr error
+	retrier := retry.Retrier{
+		HandleError: func(err error) error {
+			if err != nil && isImagePullAuthError(err) {
+				return validation.WithRemediation(fmt.Errorf("pulling image %s, registry denied the credentials: %w", image, err), imagePullAuthRemediation)
+			}
+			return nil
+		},
+		Backoff: retry.Backoff{
+			Duration: backoff,
+			Factor:   2,
+			Jitter:   0.1,
+		},
+	}
+	err := retrier.Do(ctx, func(ctx context.Context) (bool, error) {
+		response, err := puller.PullImage(ctx, request)
+		if err != nil {
+			pullErr = err
+			logger.Info("Failed to pull image, retrying", zap.String("image", image), zap.Error(err))
+			return false, err
+		}
+		logger.Info("Pulled image", zap.String("image", image), zap.String("image-ref", response.ImageRef))
+		return true, nil
+	})
+	if err == nil || validation.IsRemediable(err) {
+		return err
+	}
+	if pullErr != nil {
+		err = pullErr
+	}
+	return validation.WithRemediation(fmt.Errorf("pulling image %s: %w", image, err),
+		"Ensure the node can reach ECR and the container runtime is healthy.")
+}
+
+// isImagePullAuthError reports if the pull failed because the registry rejected the credentials.
+// containerd surfaces registry errors in the message with an Unknown code.
+func isImagePullAuthError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "401 unauthorized") ||
+		strings.Contains(message, "403 forbidden") ||
+		strings.Contains(message, "authorization failed") ||
+		strings.Contains(message, "no basic auth credentials")
+}
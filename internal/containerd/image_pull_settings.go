@@ -0,0 +1,115 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	imagePullSettingsValidation = "image-pull-settings-validation"
+
+	// defaultMaxConcurrentDownloads and defaultImagePullProgressTimeout are the containerd
+	// defaults, used when the node config doesn't set them.
+	defaultMaxConcurrentDownloads   = 3
+	defaultImagePullProgressTimeout = 5 * time.Minute
+
+	// slowLinkMaxConcurrentDownloads and slowLinkMinImagePullProgressTimeout are the settings
+	// above and below which pulls are likely to time out over slow air-gapped links.
+	slowLinkMaxConcurrentDownloads      = 2
+	slowLinkMinImagePullProgressTimeout = 10 * time.Minute
+)
+
+// ValidateImagePullOptions returns an error if the containerd image pull settings of
+// the node config are invalid.
+func ValidateImagePullOptions(opts api.ContainerdOptions) error {
+	if opts.MaxConcurrentDownloads < 0 {
+		return fmt.Errorf("maxConcurrentDownloads in containerd configuration must be positive, got %d", opts.MaxConcurrentDownloads)
+	}
+	if opts.ImagePullProgressTimeout != "" {
+		timeout, err := time.ParseDuration(opts.ImagePullProgressTimeout)
+		if err != nil {
+			return fmt.Errorf("imagePullProgressTimeout in containerd configuration must be a duration like 15m: %w", err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("imagePullProgressTimeout in containerd configuration must be positive, got %s", opts.ImagePullProgressTimeout)
+		}
+	}
+	return nil
+}
+
+// imagePullSettings returns the containerd image pull settings for opts, with the
+// containerd defaults for the ones that aren't set. opts must be valid.
+func imagePullSettings(opts api.ContainerdOptions) (maxConcurrentDownloads int32, progressTimeout time.Duration) {
+	maxConcurrentDownloads, progressTimeout = defaultMaxConcurrentDownloads, defaultImagePullProgressTimeout
+	if opts.MaxConcurrentDownloads > 0 {
+		maxConcurrentDownloads = opts.MaxConcurrentDownloads
+	}
+	if timeout, err := time.ParseDuration(opts.ImagePullProgressTimeout); err == nil && timeout > 0 {
+		progressTimeout = timeout
+	}
+	return maxConcurrentDownloads, progressTimeout
+}
+
+// ImagePullSettingsValidator warns when the containerd image pull settings are likely
+// too aggressive for the link the node pulls images over.
+type ImagePullSettingsValidator struct {
+	slowLink bool
+}
+
+// ImagePullSettingsValidatorOption configures an ImagePullSettingsValidator.
+type ImagePullSettingsValidatorOption func(*ImagePullSettingsValidator)
+
+// WithSlowLink sets whether the node pulls images over a slow link, like the ones
+// air-gapped nodes reach their private registry through.
+func WithSlowLink(slowLink bool) ImagePullSettingsValidatorOption {
+	return func(v *ImagePullSettingsValidator) {
+		v.slowLink = slowLink
+	}
+}
+
+// NewImagePullSettingsValidator creates a new ImagePullSettingsValidator.
+func NewImagePullSettingsValidator(opts ...ImagePullSettingsValidatorOption) *ImagePullSettingsValidator {
+	v := &ImagePullSettingsValidator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Run validates the image pull settings of the node config. It does nothing unless the
+// node pulls images over a slow link, the settings suit other nodes.
+func (v *ImagePullSettingsValidator) Run(ctx context.Context, informer validation.Informer, cfg *api.NodeConfig) error {
+	if !v.slowLink {
+		return nil
+	}
+
+	var err error
+	informer.Starting(ctx, imagePullSettingsValidation, "Validating containerd image pull settings for slow links")
+	defer func() {
+		informer.Done(ctx, imagePullSettingsValidation, err)
+	}()
+	err = v.Validate(cfg)
+	return err
+}
+
+// Validate returns a warning if the image pull settings set in cfg are likely to make pulls fail
+// over a slow link. The containerd defaults aren't warned about, the node config doesn't choose them.
+func (v *ImagePullSettingsValidator) Validate(cfg *api.NodeConfig) error {
+	opts := cfg.Spec.Containerd
+	maxConcurrentDownloads, progressTimeout := imagePullSettings(opts)
+	tooManyDownloads := opts.MaxConcurrentDownloads > 0 && maxConcurrentDownloads > slowLinkMaxConcurrentDownloads
+	tooShortTimeout := opts.ImagePullProgressTimeout != "" && progressTimeout < slowLinkMinImagePullProgressTimeout
+	if !tooManyDownloads && !tooShortTimeout {
+		return nil
+	}
+	return validation.WithWarning(
+		fmt.Errorf("containerd pulls images with %d concurrent downloads and a %s progress timeout, which can make pulls fail over slow links",
+			maxConcurrentDownloads, progressTimeout),
+		fmt.Sprintf("Set spec.containerd.maxConcurrentDownloads to %d or less and spec.containerd.imagePullProgressTimeout to %s or more in the node config.",
+			slowLinkMaxConcurrentDownloads, slowLinkMinImagePullProgressTimeout),
+	)
+}
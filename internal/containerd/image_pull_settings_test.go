@@ -0,0 +1,106 @@
+package containerd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestValidateImagePullOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    api.ContainerdOptions
+		wantErr string
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "tuned",
+			opts: api.ContainerdOptions{MaxConcurrentDownloads: 1, ImagePullProgressTimeout: "30m"},
+		},
+		{
+			name:    "negative max concurrent downloads",
+			opts:    api.ContainerdOptions{MaxConcurrentDownloads: -1},
+			wantErr: "maxConcurrentDownloads in containerd configuration must be positive, got -1",
+		},
+		{
+			name:    "invalid progress timeout",
+			opts:    api.ContainerdOptions{ImagePullProgressTimeout: "30"},
+			wantErr: "imagePullProgressTimeout in containerd configuration must be a duration like 15m",
+		},
+		{
+			name:    "negative progress timeout",
+			opts:    api.ContainerdOptions{ImagePullProgressTimeout: "-5m"},
+			wantErr: "imagePullProgressTimeout in containerd configuration must be positive, got -5m",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateImagePullOptions(tc.opts)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestImagePullSettingsValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		slowLink    bool
+		opts        api.ContainerdOptions
+		wantWarning string
+	}{
+		{
+			name: "defaults without a slow link",
+		},
+		{
+			name:     "defaults over a slow link",
+			slowLink: true,
+		},
+		{
+			name:     "only fewer concurrent downloads over a slow link",
+			slowLink: true,
+			opts:     api.ContainerdOptions{MaxConcurrentDownloads: 1},
+		},
+		{
+			name:        "more concurrent downloads over a slow link",
+			slowLink:    true,
+			opts:        api.ContainerdOptions{MaxConcurrentDownloads: 5},
+			wantWarning: "containerd pulls images with 5 concurrent downloads and a 5m0s progress timeout, which can make pulls fail over slow links",
+		},
+		{
+			name:        "short progress timeout over a slow link",
+			slowLink:    true,
+			opts:        api.ContainerdOptions{MaxConcurrentDownloads: 1, ImagePullProgressTimeout: "2m"},
+			wantWarning: "containerd pulls images with 1 concurrent downloads and a 2m0s progress timeout",
+		},
+		{
+			name:     "tuned for a slow link",
+			slowLink: true,
+			opts:     api.ContainerdOptions{MaxConcurrentDownloads: 2, ImagePullProgressTimeout: "10m"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &api.NodeConfig{Spec: api.NodeConfigSpec{Containerd: tc.opts}}
+			validator := NewImagePullSettingsValidator(WithSlowLink(tc.slowLink))
+
+			err := validator.Run(context.Background(), validation.NewPrinter(), cfg)
+			if tc.wantWarning == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantWarning)
+			assert.True(t, validation.IsWarning(err))
+			assert.Contains(t, validation.Remediation(err), "spec.containerd.maxConcurrentDownloads to 2 or less")
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package containerd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeImagePuller struct {
+	errs     []error
+	calls    int
+	requests []*v1.PullImageRequest
+}
+
+func (f *fakeImagePuller) PullImage(_ context.Context, in *v1.PullImageRequest, _ ...grpc.CallOption) (*v1.PullImageResponse, error) {
+	f.requests = append(f.requests, in)
+	f.calls++
+	if len(f.errs) >= f.calls {
+		return nil, f.errs[f.calls-1]
+	}
+	return &v1.PullImageResponse{ImageRef: "sha256:abc"}, nil
+}
+
+func TestPullImageWithRetry(t *testing.T) {
+	const image = "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5"
+	auth := &v1.AuthConfig{Username: "AWS", Password: "token"}
+
+	tests := []struct {
+		name                string
+		errs                []error
+		timeout             time.Duration
+		wantCalls           int
+		wantErr             string
+		wantRemediationPart string
+	}{
+		{
+			name:      "pull succeeds",
+			wantCalls: 1,
+		},
+		{
+			name:      "transient failure retried",
+			errs:      []error{status.Error(codes.Unavailable, "connection refused"), errors.New("i/o timeout")},
+			wantCalls: 3,
+		},
+		{
+			name:                "registry rejects credentials",
+			errs:                []error{errors.New(`failed to pull and unpack image: failed to resolve reference: pulling from host 602401143452.dkr.ecr.us-west-2.amazonaws.com failed with status code [manifests 3.5]: 401 Unauthorized`)},
+			wantCalls:           1,
+			wantErr:             "registry denied the credentials",
+			wantRemediationPart: "ecr:GetAuthorizationToken",
+		},
+		{
+			name:                "permission denied",
+			errs:                []error{status.Error(codes.PermissionDenied, "denied")},
+			wantCalls:           1,
+			wantErr:             "registry denied the credentials",
+			wantRemediationPart: "ecr:GetAuthorizationToken",
+		},
+		{
+			name:                "timeout",
+			errs:                []error{errors.New("i/o timeout"), errors.New("i/o timeout"), errors.New("i/o timeout"), errors.New("i/o timeout"), errors.New("i/o timeout"), errors.New("i/o timeout")},
+			timeout:             5 * time.Millisecond,
+			wantErr:             "pulling image " + image + ": i/o timeout",
+			wantRemediationPart: "reach ECR",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			puller := &fakeImagePuller{errs: tc.errs}
+
+			err := PullImageWithRetry(ctx, puller, image, auth, time.Millisecond, zap.NewNop())
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				assert.Contains(t, validation.Remediation(err), tc.wantRemediationPart)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.wantCalls > 0 {
+				assert.Equal(t, tc.wantCalls, puller.calls)
+			}
+			assert.Equal(t, image, puller.requests[0].Image.Image)
+			assert.Equal(t, auth, puller.requests[0].Auth)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	imageServiceValidation        = "image-service-endpoint-validation"
+	imageServiceValidationTimeout = 10 * time.Second
+	imageServiceRemediation       = "Ensure the image service is running and listening on the kubelet imageServiceEndpoint, " +
+		"or remove imageServiceEndpoint to pull images through the container runtime endpoint."
+)
+
+// ImageServiceEndpoint returns the CRI image service endpoint kubelet pulls images through,
+// which is the container runtime endpoint unless the node config sets a separate one.
+func ImageServiceEndpoint(cfg *api.NodeConfig) string {
+	if cfg.Spec.Kubelet.ImageServiceEndpoint != "" {
+		return cfg.Spec.Kubelet.ImageServiceEndpoint
+	}
+	return ContainerRuntimeEndpoint
+}
+
+// ValidateImageService checks a CRI image service is serving endpoint.
+func ValidateImageService(ctx context.Context, endpoint string) error {
+	if socket, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		if _, err := os.Stat(socket); err != nil {
+			return validation.WithRemediation(
+				fmt.Errorf("no image service found, socket %s is not available: %w", socket, err),
+				imageServiceRemediation)
+		}
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return validation.WithRemediation(
+			fmt.Errorf("connecting to image service at %s: %w", endpoint, err),
+			imageServiceRemediation)
+	}
+	defer conn.Close()
+
+	if _, err := v1.NewImageServiceClient(conn).ImageFsInfo(ctx, &v1.ImageFsInfoRequest{}); err != nil {
+		return validation.WithRemediation(
+			fmt.Errorf("image service at %s didn't answer the CRI image filesystem info request: %w", endpoint, err),
+			imageServiceRemediation)
+	}
+	return nil
+}
+
+// ImageServiceValidator validates the separate image service endpoint configured for kubelet is reachable.
+type ImageServiceValidator struct {
+	timeout time.Duration
+}
+
+// NewImageServiceValidator creates a new ImageServiceValidator.
+func NewImageServiceValidator() *ImageServiceValidator {
+	return &ImageServiceValidator{timeout: imageServiceValidationTimeout}
+}
+
+// Run validates the image service endpoint of the node config. It does nothing if the
+// node config doesn't set one, the container runtime endpoint is validated on its own.
+func (v *ImageServiceValidator) Run(ctx context.Context, informer validation.Informer, cfg *api.NodeConfig) error {
+	endpoint := cfg.Spec.Kubelet.ImageServiceEndpoint
+	if endpoint == "" {
+		return nil
+	}
+
+	var err error
+	informer.Starting(ctx, imageServiceValidation, "Validating kubelet image service endpoint "+endpoint)
+	defer func() {
+		informer.Done(ctx, imageServiceValidation, err)
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	err = ValidateImageService(ctx, endpoint)
+	return err
+}
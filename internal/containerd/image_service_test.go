@@ -0,0 +1,108 @@
+package containerd
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeImageService struct {
+	v1.UnimplementedImageServiceServer
+	err error
+}
+
+func (f *fakeImageService) ImageFsInfo(context.Context, *v1.ImageFsInfoRequest) (*v1.ImageFsInfoResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &v1.ImageFsInfoResponse{}, nil
+}
+
+// serveImageService serves service on a unix socket in a temp dir and returns its endpoint.
+func serveImageService(t *testing.T, service v1.ImageServiceServer) string {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "image.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	v1.RegisterImageServiceServer(server, service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	return "unix://" + socket
+}
+
+func TestImageServiceEndpoint(t *testing.T) {
+	assert.Equal(t, ContainerRuntimeEndpoint, ImageServiceEndpoint(&api.NodeConfig{}))
+
+	cfg := &api.NodeConfig{Spec: api.NodeConfigSpec{Kubelet: api.KubeletOptions{ImageServiceEndpoint: "unix:///run/stargz.sock"}}}
+	assert.Equal(t, "unix:///run/stargz.sock", ImageServiceEndpoint(cfg))
+}
+
+func TestImageServiceValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint func(t *testing.T) string
+		wantErr  string
+	}{
+		{
+			name:     "not configured",
+			endpoint: func(t *testing.T) string { return "" },
+		},
+		{
+			name: "image service reachable",
+			endpoint: func(t *testing.T) string {
+				return serveImageService(t, &fakeImageService{})
+			},
+		},
+		{
+			name: "image service fails",
+			endpoint: func(t *testing.T) string {
+				return serveImageService(t, &fakeImageService{err: status.Error(codes.Unavailable, "snapshotter not ready")})
+			},
+			wantErr: "didn't answer the CRI image filesystem info request",
+		},
+		{
+			name: "only a runtime service is served",
+			endpoint: func(t *testing.T) string {
+				return serveImageService(t, &v1.UnimplementedImageServiceServer{})
+			},
+			wantErr: "Unimplemented",
+		},
+		{
+			name: "no socket",
+			endpoint: func(t *testing.T) string {
+				return "unix://" + filepath.Join(t.TempDir(), "missing.sock")
+			},
+			wantErr: "no image service found",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &api.NodeConfig{Spec: api.NodeConfigSpec{Kubelet: api.KubeletOptions{ImageServiceEndpoint: tc.endpoint(t)}}}
+			validator := NewImageServiceValidator()
+			validator.timeout = 5 * time.Second
+
+			err := validator.Run(context.Background(), validation.NewPrinter(), cfg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+			assert.Contains(t, validation.Remediation(err), "imageServiceEndpoint")
+		})
+	}
+}
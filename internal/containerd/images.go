@@ -0,0 +1,73 @@
+package containerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// PruneImages removes all the images from the CRI image service serving endpoint and returns
+// the images removed. containerd serves the images in its k8s.io namespace through CRI, so
+// images pulled with ctr or nerdctl in other namespaces are left intact.
+// If the runtime isn't running there is nothing to prune.
+func PruneImages(ctx context.Context, endpoint string, logger *zap.Logger) ([]string, error) {
+	if socket, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		if _, err := os.Stat(socket); os.IsNotExist(err) {
+			logger.Info("Container runtime is not running, no images to prune", zap.String("endpoint", endpoint))
+			return nil, nil
+		}
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to container runtime at %s: %w", endpoint, err)
+	}
+	defer conn.Close()
+
+	return pruneImages(ctx, v1.NewImageServiceClient(conn), logger)
+}
+
+func pruneImages(ctx context.Context, client v1.ImageServiceClient, logger *zap.Logger) ([]string, error) {
+	resp, err := client.ListImages(ctx, &v1.ListImagesRequest{})
+	if status.Code(err) == codes.Unavailable {
+		// The socket is left behind when the runtime stops.
+		logger.Info("Container runtime is not available, skipping image pruning", zap.Error(err))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing images: %w", err)
+	}
+
+	removed := []string{}
+	var errs []error
+	for _, image := range resp.GetImages() {
+		name := imageName(image)
+		logger.Info("Removing image", zap.String("image", name))
+		if _, err := client.RemoveImage(ctx, &v1.RemoveImageRequest{Image: &v1.ImageSpec{Image: image.GetId()}}); err != nil {
+			errs = append(errs, fmt.Errorf("removing image %s: %w", name, err))
+			continue
+		}
+		removed = append(removed, name)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// imageName returns the first tag of the image, falling back to its digest and id for untagged images.
+func imageName(image *v1.Image) string {
+	if tags := image.GetRepoTags(); len(tags) > 0 {
+		return tags[0]
+	}
+	if digests := image.GetRepoDigests(); len(digests) > 0 {
+		return digests[0]
+	}
+	return image.GetId()
+}
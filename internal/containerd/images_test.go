@@ -0,0 +1,92 @@
+package containerd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// fakeImageStore is a CRI image service holding images, failing to remove the ones in removeErrs.
+type fakeImageStore struct {
+	v1.UnimplementedImageServiceServer
+	images     map[string]*v1.Image
+	removeErrs map[string]error
+}
+
+func (f *fakeImageStore) ListImages(context.Context, *v1.ListImagesRequest) (*v1.ListImagesResponse, error) {
+	resp := &v1.ListImagesResponse{}
+	for _, image := range f.images {
+		resp.Images = append(resp.Images, image)
+	}
+	return resp, nil
+}
+
+func (f *fakeImageStore) RemoveImage(_ context.Context, req *v1.RemoveImageRequest) (*v1.RemoveImageResponse, error) {
+	if err := f.removeErrs[req.Image.Image]; err != nil {
+		return nil, err
+	}
+	delete(f.images, req.Image.Image)
+	return &v1.RemoveImageResponse{}, nil
+}
+
+func newFakeImageStore() *fakeImageStore {
+	return &fakeImageStore{images: map[string]*v1.Image{
+		"sha256:pause": {Id: "sha256:pause", RepoTags: []string{"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5"}},
+		"sha256:cilium": {
+			Id:          "sha256:cilium",
+			RepoDigests: []string{"quay.io/cilium/cilium@sha256:cilium"},
+		},
+		"sha256:untagged": {Id: "sha256:untagged"},
+	}}
+}
+
+func TestPruneImages(t *testing.T) {
+	store := newFakeImageStore()
+	endpoint := serveImageService(t, store)
+
+	removed, err := PruneImages(context.Background(), endpoint, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5",
+		"quay.io/cilium/cilium@sha256:cilium",
+		"sha256:untagged",
+	}, removed)
+	assert.Empty(t, store.images)
+}
+
+func TestPruneImagesRemoveError(t *testing.T) {
+	store := newFakeImageStore()
+	store.removeErrs = map[string]error{"sha256:cilium": status.Error(codes.FailedPrecondition, "image is in use")}
+	endpoint := serveImageService(t, store)
+
+	removed, err := PruneImages(context.Background(), endpoint, zaptest.NewLogger(t))
+	assert.ErrorContains(t, err, "removing image quay.io/cilium/cilium@sha256:cilium")
+	assert.ElementsMatch(t, []string{"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5", "sha256:untagged"}, removed)
+	assert.Contains(t, store.images, "sha256:cilium")
+}
+
+func TestPruneImagesRuntimeNotRunning(t *testing.T) {
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "containerd.sock")
+
+	removed, err := PruneImages(context.Background(), endpoint, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestPruneImagesRuntimeUnavailable(t *testing.T) {
+	// The socket is left behind, but nothing is listening on it.
+	socket := filepath.Join(t.TempDir(), "containerd.sock")
+	require.NoError(t, os.WriteFile(socket, nil, 0o600))
+
+	removed, err := PruneImages(context.Background(), "unix://"+socket, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
@@ -96,8 +96,9 @@ func Upgrade(ctx context.Context, source Source, kubernetesVersion string, skipC
 	return nil
 }
 
-func ValidateContainerdSource(source tracker.ContainerdSourceName) error {
-	osName := system.GetOsName()
+// ValidateContainerdSource returns an error if containerd can't be installed from source on the host OS.
+func ValidateContainerdSource(source tracker.ContainerdSourceName, osInfo system.OSInfo) error {
+	osName := osInfo.ID
 	switch source {
 	case tracker.ContainerdSourceNone:
 		return nil
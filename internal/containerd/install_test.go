@@ -4,6 +4,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
 func TestDetermineContainerdVersionConstraint(t *testing.T) {
@@ -68,3 +71,31 @@ func TestDetermineContainerdVersionConstraint(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateContainerdSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  tracker.ContainerdSourceName
+		osInfo  system.OSInfo
+		wantErr string
+	}{
+		{name: "none on AL2023", source: tracker.ContainerdSourceNone, osInfo: system.OSInfo{ID: system.AmazonOsName}},
+		{name: "distro on AL2023", source: tracker.ContainerdSourceDistro, osInfo: system.OSInfo{ID: system.AmazonOsName}},
+		{name: "docker on AL2023", source: tracker.ContainerdSourceDocker, osInfo: system.OSInfo{ID: system.AmazonOsName}, wantErr: "not supported on AL2023"},
+		{name: "docker on RHEL", source: tracker.ContainerdSourceDocker, osInfo: system.OSInfo{ID: system.RhelOsName}},
+		{name: "distro on RHEL", source: tracker.ContainerdSourceDistro, osInfo: system.OSInfo{ID: system.RhelOsName}, wantErr: "not supported on RHEL"},
+		{name: "docker on Ubuntu", source: tracker.ContainerdSourceDocker, osInfo: system.OSInfo{ID: system.UbuntuOsName}},
+		{name: "distro on Ubuntu", source: tracker.ContainerdSourceDistro, osInfo: system.OSInfo{ID: system.UbuntuOsName}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateContainerdSource(tt.source, tt.osInfo)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const sandboxImagePullRemediation = "Ensure the node can reach the registry of the sandbox image, directly or through a registry mirror configured in containerd, " +
+	"and that containerd has credentials for it if the registry is private."
+
+// imageReferenceRegex matches image references in the [registry[:port]/]repository[:tag][@digest] form.
+var imageReferenceRegex = regexp.MustCompile(`^` +
+	`(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?` +
+	`(?:@[a-zA-Z][a-zA-Z0-9]*(?:[-_+.][a-zA-Z][a-zA-Z0-9]*)*:[0-9a-fA-F]{32,})?` +
+	`$`)
+
+// ValidateImageReference returns an error if image is not a valid image reference,
+// like registry.example.com/eks/pause:3.10.
+func ValidateImageReference(image string) error {
+	if len(image) > 255 || !imageReferenceRegex.MatchString(image) {
+		return fmt.Errorf("invalid image reference %q, expected [registry/]repository[:tag][@digest], for example registry.example.com/eks/pause:3.10", image)
+	}
+	return nil
+}
+
+// PrePullSandboxImage validates the sandbox image reference and pulls it through the runtime serving
+// endpoint, so a sandbox image kubelet can't fetch is reported before any pod is scheduled.
+func PrePullSandboxImage(ctx context.Context, endpoint, image string, logger *zap.Logger) error {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("connecting to container runtime at %s: %w", endpoint, err)
+	}
+	defer conn.Close()
+	return prePullSandboxImage(ctx, v1.NewImageServiceClient(conn), image, 2*time.Second, logger)
+}
+
+func prePullSandboxImage(ctx context.Context, puller ImagePuller, image string, backoff time.Duration, logger *zap.Logger) error {
+	if err := ValidateImageReference(image); err != nil {
+		return err
+	}
+	// No credentials are sent, containerd resolves them from its own registry configuration.
+	if err := PullImageWithRetry(ctx, puller, image, nil, backoff, logger); err != nil {
+		return validation.WithRemediation(fmt.Errorf("pre-pulling sandbox image, kubelet won't be able to create pods: %w", err), sandboxImagePullRemediation)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package containerd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestValidateImageReference(t *testing.T) {
+	valid := []string{
+		"pause",
+		"eks/pause:3.10",
+		"registry.example.com/eks/pause:3.10",
+		"registry.example.com:5000/eks/pause:3.10",
+		"602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5",
+		"localhost:5000/pause@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+	for _, image := range valid {
+		assert.NoError(t, ValidateImageReference(image), image)
+	}
+
+	invalid := []string{
+		"",
+		"registry.example.com/EKS/pause:3.10",
+		"registry.example.com/eks/pause:",
+		"registry.example.com/eks/pause:3.10 ",
+		"https://registry.example.com/eks/pause:3.10",
+		"registry.example.com/eks/pause@sha256:abc",
+	}
+	for _, image := range invalid {
+		assert.ErrorContains(t, ValidateImageReference(image), "invalid image reference", image)
+	}
+}
+
+func TestPrePullSandboxImage(t *testing.T) {
+	const image = "registry.example.com/eks/pause:3.10"
+
+	tests := []struct {
+		name                string
+		image               string
+		errs                []error
+		timeout             time.Duration
+		wantCalls           int
+		wantErr             string
+		wantRemediationPart string
+	}{
+		{
+			name:      "pull succeeds",
+			image:     image,
+			wantCalls: 1,
+		},
+		{
+			name:                "registry rejects credentials",
+			image:               image,
+			errs:                []error{status.Error(codes.Unauthenticated, "401 Unauthorized")},
+			wantCalls:           1,
+			wantErr:             "pre-pulling sandbox image, kubelet won't be able to create pods: pulling image " + image + ", registry denied the credentials",
+			wantRemediationPart: "credentials for it",
+		},
+		{
+			name:                "registry unreachable",
+			image:               image,
+			errs:                []error{errors.New("dial tcp: i/o timeout"), errors.New("dial tcp: i/o timeout"), errors.New("dial tcp: i/o timeout"), errors.New("dial tcp: i/o timeout"), errors.New("dial tcp: i/o timeout"), errors.New("dial tcp: i/o timeout")},
+			timeout:             5 * time.Millisecond,
+			wantErr:             "pulling image " + image + ": dial tcp: i/o timeout",
+			wantRemediationPart: "registry mirror",
+		},
+		{
+			name:      "invalid image reference",
+			image:     "registry.example.com/EKS/pause",
+			wantCalls: 0,
+			wantErr:   `invalid image reference "registry.example.com/EKS/pause"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			puller := &fakeImagePuller{errs: tc.errs}
+
+			err := prePullSandboxImage(ctx, puller, tc.image, time.Millisecond, zap.NewNop())
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				if tc.wantRemediationPart != "" {
+					assert.Contains(t, validation.Remediation(err), tc.wantRemediationPart)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.timeout == 0 {
+				assert.Equal(t, tc.wantCalls, puller.calls)
+			}
+			for _, request := range puller.requests {
+				assert.Equal(t, tc.image, request.Image.Image)
+				assert.Nil(t, request.Auth)
+			}
+		})
+	}
+}
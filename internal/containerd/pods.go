@@ -0,0 +1,98 @@
+package containerd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// kubernetesPodNamespaceLabel is set by kubelet on the pod sandboxes it creates.
+// Sandboxes without it were not created by kubelet.
+const kubernetesPodNamespaceLabel = "io.kubernetes.pod.namespace"
+
+// PodCleanupScope selects the pod sandboxes removed from the container runtime. Only sandboxes
+// created by kubelet are ever in scope, containers run on the node by other CRI clients are left intact.
+type PodCleanupScope struct {
+	// Namespaces limits the removal to pods in these Kubernetes namespaces. Empty means all namespaces.
+	Namespaces []string
+	// Labels limits the removal to pod sandboxes with all these labels.
+	Labels map[string]string
+}
+
+// Contains returns true if the pod sandbox is managed by kubelet and matches the scope.
+func (s PodCleanupScope) Contains(sandbox *v1.PodSandbox) bool {
+	labels := sandbox.GetLabels()
+	namespace, ok := labels[kubernetesPodNamespaceLabel]
+	if !ok {
+		return false
+	}
+	if len(s.Namespaces) > 0 && !slices.Contains(s.Namespaces, namespace) {
+		return false
+	}
+	for key, value := range s.Labels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// RemovePods stops and removes the pod sandboxes in scope from the CRI runtime serving endpoint,
+// along with their containers. It returns the pods removed, in namespace/name form.
+// If the runtime isn't running there is nothing to remove.
+func RemovePods(ctx context.Context, endpoint string, scope PodCleanupScope, logger *zap.Logger) ([]string, error) {
+	if socket, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		if _, err := os.Stat(socket); os.IsNotExist(err) {
+			logger.Info("Container runtime is not running, no pods to remove", zap.String("endpoint", endpoint))
+			return nil, nil
+		}
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to container runtime at %s: %w", endpoint, err)
+	}
+	defer conn.Close()
+
+	return removePods(ctx, v1.NewRuntimeServiceClient(conn), scope, logger)
+}
+
+func removePods(ctx context.Context, client v1.RuntimeServiceClient, scope PodCleanupScope, logger *zap.Logger) ([]string, error) {
+	resp, err := client.ListPodSandbox(ctx, &v1.ListPodSandboxRequest{
+		Filter: &v1.PodSandboxFilter{LabelSelector: scope.Labels},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing pod sandboxes: %w", err)
+	}
+
+	removed := []string{}
+	var errs []error
+	for _, sandbox := range resp.GetItems() {
+		// The label selector is not honored by every runtime, so the scope is checked again.
+		if !scope.Contains(sandbox) {
+			logger.Debug("Keeping pod sandbox out of the cleanup scope", zap.String("id", sandbox.GetId()),
+				zap.Any("labels", sandbox.GetLabels()))
+			continue
+		}
+		name := sandbox.GetMetadata().GetNamespace() + "/" + sandbox.GetMetadata().GetName()
+		logger.Info("Removing pod", zap.String("pod", name), zap.String("id", sandbox.GetId()))
+		if _, err := client.StopPodSandbox(ctx, &v1.StopPodSandboxRequest{PodSandboxId: sandbox.GetId()}); err != nil {
+			errs = append(errs, fmt.Errorf("stopping pod %s: %w", name, err))
+			continue
+		}
+		if _, err := client.RemovePodSandbox(ctx, &v1.RemovePodSandboxRequest{PodSandboxId: sandbox.GetId()}); err != nil {
+			errs = append(errs, fmt.Errorf("removing pod %s: %w", name, err))
+			continue
+		}
+		removed = append(removed, name)
+	}
+	return removed, errors.Join(errs...)
+}
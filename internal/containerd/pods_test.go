@@ -0,0 +1,118 @@
+package containerd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// fakePodRuntime is a CRI runtime service holding pod sandboxes. It ignores the
+// list filter, like some runtimes do.
+type fakePodRuntime struct {
+	v1.UnimplementedRuntimeServiceServer
+	sandboxes map[string]*v1.PodSandbox
+	stopped   []string
+}
+
+func (f *fakePodRuntime) ListPodSandbox(context.Context, *v1.ListPodSandboxRequest) (*v1.ListPodSandboxResponse, error) {
+	resp := &v1.ListPodSandboxResponse{}
+	for _, sandbox := range f.sandboxes {
+		resp.Items = append(resp.Items, sandbox)
+	}
+	return resp, nil
+}
+
+func (f *fakePodRuntime) StopPodSandbox(_ context.Context, req *v1.StopPodSandboxRequest) (*v1.StopPodSandboxResponse, error) {
+	f.stopped = append(f.stopped, req.PodSandboxId)
+	return &v1.StopPodSandboxResponse{}, nil
+}
+
+func (f *fakePodRuntime) RemovePodSandbox(_ context.Context, req *v1.RemovePodSandboxRequest) (*v1.RemovePodSandboxResponse, error) {
+	delete(f.sandboxes, req.PodSandboxId)
+	return &v1.RemovePodSandboxResponse{}, nil
+}
+
+func kubernetesSandbox(id, namespace, name string, labels map[string]string) *v1.PodSandbox {
+	sandboxLabels := map[string]string{
+		kubernetesPodNamespaceLabel: namespace,
+		"io.kubernetes.pod.name":    name,
+	}
+	for key, value := range labels {
+		sandboxLabels[key] = value
+	}
+	return &v1.PodSandbox{
+		Id:       id,
+		Metadata: &v1.PodSandboxMetadata{Name: name, Namespace: namespace},
+		Labels:   sandboxLabels,
+	}
+}
+
+func newFakePodRuntime() *fakePodRuntime {
+	return &fakePodRuntime{sandboxes: map[string]*v1.PodSandbox{
+		"kube-proxy": kubernetesSandbox("kube-proxy", "kube-system", "kube-proxy-abc", map[string]string{"k8s-app": "kube-proxy"}),
+		"cilium":     kubernetesSandbox("cilium", "kube-system", "cilium-abc", map[string]string{"k8s-app": "cilium"}),
+		"app":        kubernetesSandbox("app", "default", "app-abc", nil),
+		// Created with crictl, not by kubelet.
+		"debug": {
+			Id:       "debug",
+			Metadata: &v1.PodSandboxMetadata{Name: "debug", Namespace: "default"},
+			Labels:   map[string]string{"k8s-app": "cilium"},
+		},
+	}}
+}
+
+func TestRemovePods(t *testing.T) {
+	tests := []struct {
+		name        string
+		scope       PodCleanupScope
+		wantRemoved []string
+		wantKept    []string
+	}{
+		{
+			name:        "kubernetes pods only",
+			wantRemoved: []string{"kube-system/kube-proxy-abc", "kube-system/cilium-abc", "default/app-abc"},
+			wantKept:    []string{"debug"},
+		},
+		{
+			name:        "namespaces",
+			scope:       PodCleanupScope{Namespaces: []string{"default"}},
+			wantRemoved: []string{"default/app-abc"},
+			wantKept:    []string{"kube-proxy", "cilium", "debug"},
+		},
+		{
+			name:        "labels",
+			scope:       PodCleanupScope{Labels: map[string]string{"k8s-app": "cilium"}},
+			wantRemoved: []string{"kube-system/cilium-abc"},
+			wantKept:    []string{"kube-proxy", "app", "debug"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runtime := newFakePodRuntime()
+			endpoint := serveCRI(t, runtime)
+
+			removed, err := RemovePods(context.Background(), endpoint, tc.scope, zaptest.NewLogger(t))
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantRemoved, removed)
+			assert.Len(t, runtime.stopped, len(tc.wantRemoved))
+			kept := make([]string, 0, len(runtime.sandboxes))
+			for id := range runtime.sandboxes {
+				kept = append(kept, id)
+			}
+			assert.ElementsMatch(t, tc.wantKept, kept)
+		})
+	}
+}
+
+func TestRemovePodsRuntimeNotRunning(t *testing.T) {
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "containerd.sock")
+
+	removed, err := RemovePods(context.Background(), endpoint, PodCleanupScope{}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,81 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const externalRuntimeRemediation = "With --containerd-source none, nodeadm doesn't install containerd. " +
+	"Ensure a CRI compatible runtime is installed, running and listening on " + ContainerRuntimeEndpoint + " with its CRI plugin enabled."
+
+// RuntimeVersion identifies the CRI runtime serving a container runtime endpoint.
+type RuntimeVersion struct {
+	Name       string
+	Version    string
+	APIVersion string
+}
+
+// ValidateCRIRuntime checks a CRI compatible runtime is serving endpoint and reports itself ready.
+// It's used to validate runtimes nodeadm doesn't manage, installed with --containerd-source none.
+func ValidateCRIRuntime(ctx context.Context, endpoint string) (*RuntimeVersion, error) {
+	if socket, ok := strings.CutPrefix(endpoint, "unix://"); ok {
+		if _, err := os.Stat(socket); err != nil {
+			return nil, validation.WithRemediation(
+				fmt.Errorf("no container runtime found, socket %s is not available: %w", socket, err),
+				externalRuntimeRemediation)
+		}
+	}
+
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, validation.WithRemediation(
+			fmt.Errorf("connecting to container runtime at %s: %w", endpoint, err),
+			externalRuntimeRemediation)
+	}
+	defer conn.Close()
+	client := v1.NewRuntimeServiceClient(conn)
+
+	version, err := client.Version(ctx, &v1.VersionRequest{Version: "v1"})
+	if err != nil {
+		return nil, validation.WithRemediation(
+			fmt.Errorf("container runtime at %s didn't answer the CRI version request: %w", endpoint, err),
+			externalRuntimeRemediation)
+	}
+
+	// The NetworkReady condition is expected to be false until a CNI is installed,
+	// so only the RuntimeReady condition is checked.
+	status, err := client.Status(ctx, &v1.StatusRequest{})
+	if err != nil {
+		return nil, validation.WithRemediation(
+			fmt.Errorf("getting container runtime status from %s: %w", endpoint, err),
+			externalRuntimeRemediation)
+	}
+	if !runtimeReady(status.GetStatus()) {
+		return nil, validation.WithRemediation(
+			fmt.Errorf("container runtime %s %s at %s is not ready", version.RuntimeName, version.RuntimeVersion, endpoint),
+			"Check the container runtime logs for errors.")
+	}
+
+	return &RuntimeVersion{
+		Name:       version.RuntimeName,
+		Version:    version.RuntimeVersion,
+		APIVersion: version.RuntimeApiVersion,
+	}, nil
+}
+
+func runtimeReady(status *v1.RuntimeStatus) bool {
+	for _, condition := range status.GetConditions() {
+		if condition.Type == v1.RuntimeReady {
+			return condition.Status
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package containerd
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// fakeRuntime is a CRI runtime service serving the version and status requests.
+type fakeRuntime struct {
+	v1.UnimplementedRuntimeServiceServer
+	runtimeReady bool
+}
+
+func (f *fakeRuntime) Version(context.Context, *v1.VersionRequest) (*v1.VersionResponse, error) {
+	return &v1.VersionResponse{
+		Version:           "0.1.0",
+		RuntimeName:       "containerd",
+		RuntimeVersion:    "v1.7.27",
+		RuntimeApiVersion: "v1",
+	}, nil
+}
+
+func (f *fakeRuntime) Status(context.Context, *v1.StatusRequest) (*v1.StatusResponse, error) {
+	// NetworkReady is false, as it is before a CNI is installed.
+	return &v1.StatusResponse{Status: &v1.RuntimeStatus{Conditions: []*v1.RuntimeCondition{
+		{Type: v1.RuntimeReady, Status: f.runtimeReady},
+		{Type: v1.NetworkReady, Status: false},
+	}}}, nil
+}
+
+// serveCRI serves the runtime service on a unix socket and returns its endpoint.
+// A nil service serves a runtime with the CRI plugin disabled.
+func serveCRI(t *testing.T, service v1.RuntimeServiceServer) string {
+	socket := filepath.Join(t.TempDir(), "containerd.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	if service != nil {
+		v1.RegisterRuntimeServiceServer(server, service)
+	}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return "unix://" + socket
+}
+
+func TestValidateCRIRuntime(t *testing.T) {
+	endpoint := serveCRI(t, &fakeRuntime{runtimeReady: true})
+
+	version, err := ValidateCRIRuntime(context.Background(), endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, &RuntimeVersion{Name: "containerd", Version: "v1.7.27", APIVersion: "v1"}, version)
+}
+
+func TestValidateCRIRuntimeErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      func(t *testing.T) string
+		errorContains string
+	}{
+		{
+			name: "no runtime",
+			endpoint: func(t *testing.T) string {
+				return "unix://" + filepath.Join(t.TempDir(), "containerd.sock")
+			},
+			errorContains: "no container runtime found",
+		},
+		{
+			name: "cri plugin disabled",
+			endpoint: func(t *testing.T) string {
+				return serveCRI(t, nil)
+			},
+			errorContains: "didn't answer the CRI version request",
+		},
+		{
+			name: "runtime not ready",
+			endpoint: func(t *testing.T) string {
+				return serveCRI(t, &fakeRuntime{runtimeReady: false})
+			},
+			errorContains: "container runtime containerd v1.7.27",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateCRIRuntime(context.Background(), tt.endpoint(t))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorContains)
+			assert.NotEmpty(t, validation.Remediation(err))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package containerd
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"regexp"
@@ -13,7 +14,7 @@ import (
 	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 
 	"github.com/aws/eks-hybrid/internal/aws/ecr"
-	"github.com/aws/eks-hybrid/internal/util"
+	"github.com/aws/eks-hybrid/internal/retry"
 )
 
 var (
@@ -25,7 +26,7 @@ var (
 	containerdSandboxImageV3Regex = regexp.MustCompile(`sandbox = ['"]([^'"]*)['"]`)
 )
 
-func cacheSandboxImage(awsConfig *aws.Config) error {
+func cacheSandboxImage(awsConfig *aws.Config, imageServiceEndpoint string) error {
 	zap.L().Info("Looking up current sandbox image in containerd config...")
 	// capture the output of a `containerd config dump`, which is the final
 	// containerd configuration used after all of the applied transformations
@@ -58,27 +59,40 @@ func cacheSandboxImage(awsConfig *aws.Config) error {
 	sandboxImage := string(matches[1])
 	zap.L().Info("Found sandbox image", zap.String("image", sandboxImage))
 
-	zap.L().Info("Fetching ECR authorization token...")
-	ecrUserToken, err := ecr.GetAuthorizationToken(awsConfig)
-	if err != nil {
-		return err
+	// Only ECR registries take the ECR authorization token, other registries are pulled
+	// with the containerd registry config.
+	var authConfig *v1.AuthConfig
+	if ecr.IsECRImage(sandboxImage) {
+		zap.L().Info("Fetching ECR authorization token...")
+		ecrUserToken, err := ecr.GetAuthorizationToken(awsConfig)
+		if err != nil {
+			return err
+		}
+		authConfig = &v1.AuthConfig{Auth: ecrUserToken}
 	}
 
-	client, err := remote.NewImageService(ContainerRuntimeEndpoint, 5*time.Second)
+	client, err := remote.NewImageService(imageServiceEndpoint, 5*time.Second)
 	if err != nil {
 		return err
 	}
 	imageSpec := &v1.ImageSpec{Image: sandboxImage}
-	authConfig := &v1.AuthConfig{Auth: ecrUserToken}
 
-	return util.RetryExponentialBackoff(3, 2*time.Second, func() error {
+	retrier := retry.Retrier{
+		Backoff: retry.Backoff{
+			Duration: 2 * time.Second,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    3,
+		},
+	}
+	return retrier.Do(context.Background(), func(ctx context.Context) (bool, error) {
 		zap.L().Info("Pulling sandbox image...", zap.String("image", sandboxImage))
 		imageRef, err := client.PullImage(imageSpec, authConfig, nil)
 		if err != nil {
-			return err
+			return false, err
 		}
 		zap.L().Info("Finished pulling sandbox image", zap.String("image-ref", imageRef))
-		return nil
+		return true, nil
 	})
 }
 
@@ -0,0 +1,217 @@
+package containerd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	defaultContainerdRoot = "/var/lib/containerd"
+	defaultSnapshotter    = "overlayfs"
+)
+
+// Filesystem types, named after their statfs magic numbers.
+const (
+	filesystemXFS     = "xfs"
+	filesystemExt4    = "ext4"
+	filesystemBtrfs   = "btrfs"
+	filesystemZFS     = "zfs"
+	filesystemOverlay = "overlay"
+	filesystemTmpfs   = "tmpfs"
+	filesystemNFS     = "nfs"
+)
+
+var filesystemMagics = map[int64]string{
+	0x58465342: filesystemXFS,
+	0xef53:     filesystemExt4,
+	0x9123683e: filesystemBtrfs,
+	0x2fc12fc1: filesystemZFS,
+	0x794c7630: filesystemOverlay,
+	0x01021994: filesystemTmpfs,
+	0x6969:     filesystemNFS,
+}
+
+// filesystemInspector reads the attributes of the filesystem holding a path that matter to snapshotters.
+type filesystemInspector interface {
+	// FilesystemType returns the type of the filesystem, like xfs or ext4.
+	FilesystemType(path string) (string, error)
+	// SupportsDType returns true if the filesystem reports file types in directory entries,
+	// which overlayfs requires. XFS only does when formatted with ftype=1.
+	SupportsDType(path string) (bool, error)
+}
+
+// ValidateSnapshotter validates the filesystem under the containerd root can back the CRI
+// snapshotter, as configured once init writes cfg: the main config nodeadm generates or, when
+// configMain is true because nodeadm won't write it, the one on disk, followed by the drop-in
+// configs with the one from cfg. A mismatch makes image extraction fail at pod start.
+func ValidateSnapshotter(cfg *api.NodeConfig, configMain bool) error {
+	files, err := loadContainerdConfigFiles(cfg, configMain)
+	if err != nil {
+		return err
+	}
+	return validateSnapshotter(statfsInspector{}, files...)
+}
+
+func validateSnapshotter(inspector filesystemInspector, files ...configFile) error {
+	root, snapshotter := defaultContainerdRoot, defaultSnapshotter
+	for _, file := range files {
+		config := parseContainerdConfig(file.data)
+		if config.root != "" {
+			root = config.root
+		}
+		if config.snapshotter != "" {
+			snapshotter = config.snapshotter
+		}
+	}
+
+	// The snapshotter directory doesn't exist until containerd starts, the closest existing
+	// parent is on the filesystem it will be created on.
+	path, err := closestExistingDir(filepath.Join(root, "io.containerd.snapshotter.v1."+snapshotter))
+	if err != nil {
+		return err
+	}
+
+	switch snapshotter {
+	case "overlayfs":
+		return validateOverlayfsFilesystem(inspector, path)
+	case "btrfs", "zfs":
+		fsType, err := inspector.FilesystemType(path)
+		if err != nil {
+			return fmt.Errorf("reading filesystem type of %s: %w", path, err)
+		}
+		if fsType != snapshotter {
+			return validation.WithRemediation(
+				fmt.Errorf("containerd snapshotter %s requires a %s filesystem, but %s is on %s", snapshotter, snapshotter, path, fsType),
+				fmt.Sprintf("Mount a %s filesystem at %s or configure the overlayfs snapshotter.", snapshotter, root),
+			)
+		}
+	}
+	// native and devmapper work on any filesystem, other snapshotters are proxy plugins nodeadm doesn't know about.
+	return nil
+}
+
+func validateOverlayfsFilesystem(inspector filesystemInspector, path string) error {
+	fsType, err := inspector.FilesystemType(path)
+	if err != nil {
+		return fmt.Errorf("reading filesystem type of %s: %w", path, err)
+	}
+	if fsType == filesystemOverlay {
+		return validation.WithRemediation(
+			fmt.Errorf("containerd overlayfs snapshotter can't use %s, it is on an overlay filesystem", path),
+			"Mount a volume formatted with ext4 or xfs at the containerd root, or configure the native snapshotter.",
+		)
+	}
+
+	dType, err := inspector.SupportsDType(path)
+	if err != nil {
+		return fmt.Errorf("checking d_type support of %s: %w", path, err)
+	}
+	if dType {
+		return nil
+	}
+	if fsType == filesystemXFS {
+		return validation.WithRemediation(
+			fmt.Errorf("containerd overlayfs snapshotter requires d_type support, but the xfs filesystem of %s is formatted with ftype=0", path),
+			"Reformat the filesystem with 'mkfs.xfs -n ftype=1' or mount a separate volume formatted with ftype=1 at the containerd root.",
+		)
+	}
+	return validation.WithRemediation(
+		fmt.Errorf("containerd overlayfs snapshotter requires d_type support, but the %s filesystem of %s doesn't support it", fsType, path),
+		"Mount a volume formatted with ext4 or xfs with ftype=1 at the containerd root, or configure the native snapshotter.",
+	)
+}
+
+func closestExistingDir(path string) (string, error) {
+	for {
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			return path, nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("no existing directory found for %s", path)
+		}
+		path = parent
+	}
+}
+
+// statfsInspector reads filesystem attributes from the kernel.
+type statfsInspector struct{}
+
+func (statfsInspector) FilesystemType(path string) (string, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return "", err
+	}
+	if fsType, ok := filesystemMagics[int64(stat.Type)]; ok {
+		return fsType, nil
+	}
+	return fmt.Sprintf("0x%x", stat.Type), nil
+}
+
+// SupportsDType creates a file in a temporary directory under path and reads back its raw
+// directory entry, the same way containerd checks d_type support.
+func (statfsInspector) SupportsDType(path string) (bool, error) {
+	dir, err := os.MkdirTemp(path, "d_type-check")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(dir)
+
+	const fileName = "file"
+	if err := os.WriteFile(filepath.Join(dir, fileName), nil, 0o600); err != nil {
+		return false, err
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := syscall.ReadDirent(int(f.Fd()), buf)
+		if err != nil {
+			return false, err
+		}
+		if n == 0 {
+			return false, errors.New("created file not found in directory entries")
+		}
+		if dType, ok := direntType(buf[:n], fileName); ok {
+			return dType != syscall.DT_UNKNOWN, nil
+		}
+	}
+}
+
+// direntType returns the d_type of the linux_dirent64 entry of name in buf.
+func direntType(buf []byte, name string) (uint8, bool) {
+	// struct linux_dirent64 { u64 d_ino; s64 d_off; u16 d_reclen; u8 d_type; char d_name[]; }
+	const nameOffset = 19
+	for len(buf) >= nameOffset {
+		reclen := int(binary.NativeEndian.Uint16(buf[16:18]))
+		if reclen < nameOffset || reclen > len(buf) {
+			return 0, false
+		}
+		entryName := buf[nameOffset:reclen]
+		for i, c := range entryName {
+			if c == 0 {
+				entryName = entryName[:i]
+				break
+			}
+		}
+		if string(entryName) == name {
+			return buf[18], true
+		}
+		buf = buf[reclen:]
+	}
+	return 0, false
+}
@@ -0,0 +1,152 @@
+package containerd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeFilesystemInspector struct {
+	fsType    string
+	dType     bool
+	err       error
+	inspected []string
+}
+
+func (f *fakeFilesystemInspector) FilesystemType(path string) (string, error) {
+	f.inspected = append(f.inspected, path)
+	return f.fsType, f.err
+}
+
+func (f *fakeFilesystemInspector) SupportsDType(path string) (bool, error) {
+	return f.dType, f.err
+}
+
+func TestValidateSnapshotter(t *testing.T) {
+	nodeadmConfig, err := generateContainerdConfig(&api.NodeConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name                string
+		dropIn              string
+		inspector           *fakeFilesystemInspector
+		wantErr             string
+		wantRemediationPart string
+	}{
+		{
+			name:      "overlayfs on xfs with ftype=1",
+			inspector: &fakeFilesystemInspector{fsType: filesystemXFS, dType: true},
+		},
+		{
+			name:      "overlayfs on ext4",
+			inspector: &fakeFilesystemInspector{fsType: filesystemExt4, dType: true},
+		},
+		{
+			name:                "overlayfs on xfs with ftype=0",
+			inspector:           &fakeFilesystemInspector{fsType: filesystemXFS, dType: false},
+			wantErr:             "containerd overlayfs snapshotter requires d_type support, but the xfs filesystem of %s is formatted with ftype=0",
+			wantRemediationPart: "mkfs.xfs -n ftype=1",
+		},
+		{
+			name:                "overlayfs without d_type",
+			inspector:           &fakeFilesystemInspector{fsType: filesystemNFS, dType: false},
+			wantErr:             "containerd overlayfs snapshotter requires d_type support, but the nfs filesystem of %s doesn't support it",
+			wantRemediationPart: "configure the native snapshotter",
+		},
+		{
+			name:                "overlayfs on overlay",
+			inspector:           &fakeFilesystemInspector{fsType: filesystemOverlay, dType: true},
+			wantErr:             "containerd overlayfs snapshotter can't use %s, it is on an overlay filesystem",
+			wantRemediationPart: "configure the native snapshotter",
+		},
+		{
+			name: "native snapshotter on xfs with ftype=0",
+			dropIn: `[plugins."io.containerd.grpc.v1.cri".containerd]
+  snapshotter = "native"
+`,
+			inspector: &fakeFilesystemInspector{fsType: filesystemXFS, dType: false},
+		},
+		{
+			name: "btrfs snapshotter on btrfs",
+			dropIn: `[plugins.'io.containerd.cri.v1.images']
+  snapshotter = 'btrfs'
+`,
+			inspector: &fakeFilesystemInspector{fsType: filesystemBtrfs, dType: true},
+		},
+		{
+			name: "btrfs snapshotter on ext4",
+			dropIn: `[plugins."io.containerd.grpc.v1.cri".containerd]
+  snapshotter = "btrfs"
+`,
+			inspector:           &fakeFilesystemInspector{fsType: filesystemExt4, dType: true},
+			wantErr:             "containerd snapshotter btrfs requires a btrfs filesystem, but %s is on ext4",
+			wantRemediationPart: "Mount a btrfs filesystem",
+		},
+		{
+			name:      "inspection fails",
+			inspector: &fakeFilesystemInspector{err: errors.New("permission denied")},
+			wantErr:   "reading filesystem type of %s: permission denied",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "containerd")
+			require.NoError(t, os.Mkdir(root, 0o755))
+			files := []configFile{
+				{path: containerdConfigFile, data: nodeadmConfig},
+				{path: "/etc/containerd/config.d/00-root.toml", data: []byte(fmt.Sprintf("root = %q\n", root))},
+			}
+			if tc.dropIn != "" {
+				files = append(files, configFile{path: "/etc/containerd/config.d/10-snapshotter.toml", data: []byte(tc.dropIn)})
+			}
+
+			err := validateSnapshotter(tc.inspector, files...)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, fmt.Sprintf(tc.wantErr, root))
+			if tc.wantRemediationPart != "" {
+				assert.Contains(t, validation.Remediation(err), tc.wantRemediationPart)
+			}
+		})
+	}
+}
+
+func TestValidateSnapshotterInspectsClosestExistingDir(t *testing.T) {
+	root := t.TempDir()
+	snapshotterDir := filepath.Join(root, "io.containerd.snapshotter.v1.overlayfs")
+	inspector := &fakeFilesystemInspector{fsType: filesystemXFS, dType: true}
+
+	files := []configFile{{path: containerdConfigFile, data: []byte(fmt.Sprintf("root = %q\n", filepath.Join(root, "missing")))}}
+	assert.NoError(t, validateSnapshotter(inspector, files...))
+
+	require.NoError(t, os.MkdirAll(snapshotterDir, 0o755))
+	files = []configFile{{path: containerdConfigFile, data: []byte(fmt.Sprintf("root = %q\n", root))}}
+	assert.NoError(t, validateSnapshotter(inspector, files...))
+
+	assert.Equal(t, []string{root, snapshotterDir}, inspector.inspected)
+}
+
+func TestStatfsInspector(t *testing.T) {
+	dir := t.TempDir()
+	inspector := statfsInspector{}
+
+	fsType, err := inspector.FilesystemType(dir)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fsType)
+
+	_, err = inspector.SupportsDType(dir)
+	assert.NoError(t, err)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries, "the d_type check should clean up after itself")
+}
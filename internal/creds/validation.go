@@ -18,6 +18,7 @@ func Validations(config aws.Config, node *api.NodeConfig) []validation.Validatio
 	if node.IsIAMRolesAnywhere() {
 		return []validation.Validation[*api.NodeConfig]{
 			validation.New("iam-ra-api-network", iamrolesanywhere.NewAccessValidator(config).Run),
+			validation.New(iamrolesanywhere.CertificateChainValidation, iamrolesanywhere.NewCertificateChainValidatorFromConfig(config).Run),
 		}
 	}
 
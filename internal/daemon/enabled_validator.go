@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// EnabledValidator validates that daemons are enabled to start on boot.
+// A daemon that is running but not enabled won't be started after a reboot,
+// and the node won't rejoin the cluster.
+type EnabledValidator struct {
+	manager DaemonManager
+	daemons []string
+}
+
+// NewEnabledValidator returns a new EnabledValidator for the given daemons.
+func NewEnabledValidator(manager DaemonManager, daemons ...string) EnabledValidator {
+	return EnabledValidator{
+		manager: manager,
+		daemons: daemons,
+	}
+}
+
+// Run validates all daemons are enabled.
+func (v EnabledValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	name := "daemons-enabled-validation"
+	informer.Starting(ctx, name, "Validating required services are enabled to start on boot")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+	err = v.Validate()
+	return err
+}
+
+// Validate returns an error listing all the daemons that are not enabled.
+func (v EnabledValidator) Validate() error {
+	var disabled []string
+	for _, daemon := range v.daemons {
+		enabled, err := v.manager.IsDaemonEnabled(daemon)
+		if err != nil {
+			return validation.WithRemediation(fmt.Errorf("checking if %s is enabled: %w", daemon, err),
+				"Ensure systemd is running and the service is installed.")
+		}
+		if !enabled {
+			disabled = append(disabled, daemon)
+		}
+	}
+	if len(disabled) == 0 {
+		return nil
+	}
+	return validation.WithRemediation(
+		fmt.Errorf("services %s are not enabled and won't start after a reboot", strings.Join(disabled, ", ")),
+		fmt.Sprintf("Enable the services to start on boot by running 'systemctl enable %s'.", strings.Join(disabled, " ")))
+}
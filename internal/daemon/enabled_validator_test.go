@@ -0,0 +1,77 @@
+package daemon_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// enabledManager reports the enabled state per daemon.
+type enabledManager struct {
+	daemon.DaemonManager
+	enabled map[string]bool
+	err     error
+}
+
+func (m enabledManager) IsDaemonEnabled(name string) (bool, error) {
+	return m.enabled[name], m.err
+}
+
+func TestEnabledValidator(t *testing.T) {
+	testCases := []struct {
+		name            string
+		manager         enabledManager
+		wantErr         string
+		wantRemediation string
+	}{
+		{
+			name: "all enabled",
+			manager: enabledManager{enabled: map[string]bool{
+				"containerd": true, "kubelet": true, "amazon-ssm-agent": true,
+			}},
+		},
+		{
+			name: "kubelet disabled",
+			manager: enabledManager{enabled: map[string]bool{
+				"containerd": true, "kubelet": false, "amazon-ssm-agent": true,
+			}},
+			wantErr:         "services kubelet are not enabled and won't start after a reboot",
+			wantRemediation: "systemctl enable kubelet",
+		},
+		{
+			name: "multiple disabled",
+			manager: enabledManager{enabled: map[string]bool{
+				"containerd": false, "kubelet": true, "amazon-ssm-agent": false,
+			}},
+			wantErr:         "services containerd, amazon-ssm-agent are not enabled",
+			wantRemediation: "systemctl enable containerd amazon-ssm-agent",
+		},
+		{
+			name:            "error reading state",
+			manager:         enabledManager{err: errors.New("dbus error")},
+			wantErr:         "checking if containerd is enabled: dbus error",
+			wantRemediation: "Ensure systemd is running",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			v := daemon.NewEnabledValidator(tc.manager, "containerd", "kubelet", "amazon-ssm-agent")
+
+			err := v.Run(context.Background(), validation.NoOpInformer{}, &api.NodeConfig{})
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			g.Expect(validation.Remediation(err)).To(ContainSubstring(tc.wantRemediation))
+		})
+	}
+}
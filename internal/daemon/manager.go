@@ -25,6 +25,9 @@ type DaemonManager interface {
 	// EnableDaemon enables the daemon with the given name.
 	// If the daemon is already enabled, this is a no-op.
 	EnableDaemon(name string) error
+	// IsDaemonEnabled returns true if the daemon with the given name is enabled
+	// to start on boot.
+	IsDaemonEnabled(name string) (bool, error)
 	// DisableDaemon disables the daemon with the given name.
 	// If the daemon is not enabled, this is a no-op.
 	DisableDaemon(name string) error
@@ -32,6 +32,10 @@ func (m *noopDaemonManager) EnableDaemon(name string) error {
 	return nil
 }
 
+func (m *noopDaemonManager) IsDaemonEnabled(name string) (bool, error) {
+	return false, nil
+}
+
 func (m *noopDaemonManager) DisableDaemon(name string) error {
 	return nil
 }
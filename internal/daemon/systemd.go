@@ -101,6 +101,22 @@ func (m *systemdDaemonManager) EnableDaemon(name string) error {
 	return nil
 }
 
+// IsDaemonEnabled returns true if the daemon unit is enabled to start on boot.
+// Units enabled only for the current boot (enabled-runtime) are not considered enabled.
+func (m *systemdDaemonManager) IsDaemonEnabled(name string) (bool, error) {
+	unitName := getServiceUnitName(name)
+	state, err := m.conn.GetUnitPropertyContext(context.TODO(), unitName, "UnitFileState")
+	if err != nil {
+		return false, err
+	}
+	switch state.Value.String() {
+	case "\"enabled\"", "\"static\"", "\"alias\"":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 func (m *systemdDaemonManager) DisableDaemon(name string) error {
 	unitName := getServiceUnitName(name)
 	changes, err := m.conn.DisableUnitFilesContext(context.TODO(), []string{unitName}, false)
@@ -170,6 +170,10 @@ func (f *fakeManager) GetDaemonStatus(name string) (daemon.DaemonStatus, error)
 	return f.status, nil
 }
 
+func (f *fakeManager) IsDaemonEnabled(name string) (bool, error) {
+	return true, nil
+}
+
 func (f *fakeManager) DisableDaemon(name string) error {
 	return nil
 }
@@ -0,0 +1,162 @@
+package facts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/firewall"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/packagemanager"
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const (
+	kernelReleasePath       = "/proc/sys/kernel/osrelease"
+	cgroupV2ControllersPath = "/sys/fs/cgroup/cgroup.controllers"
+	selinuxEnforcePath      = "/sys/fs/selinux/enforce"
+	apparmorEnabledPath     = "/sys/module/apparmor/parameters/enabled"
+)
+
+// ntpDaemons are the systemd units of the supported NTP daemons, in detection order.
+var ntpDaemons = []string{"chronyd", "chrony", "systemd-timesyncd", "ntpd", "ntp"}
+
+// NewDetectors returns the detectors for the host nodeadm is running on.
+func NewDetectors() Detectors {
+	return Detectors{
+		OSName:          nonEmpty("os name", func() string { return system.HostOSInfo().ID }),
+		OSVersion:       nonEmpty("os version", func() string { return system.HostOSInfo().VersionID }),
+		KernelVersion:   func() (string, error) { return readTrimmed(kernelReleasePath) },
+		PackageManager:  packagemanager.DetectPackageManager,
+		FirewallBackend: detectFirewallBackend,
+		IptablesBackend: func() (string, error) {
+			out, err := exec.Command("iptables", "--version").Output()
+			if err != nil {
+				return "", fmt.Errorf("getting iptables version: %w", err)
+			}
+			return parseIptablesBackend(string(out)), nil
+		},
+		CgroupVersion: func() (string, error) {
+			return cgroupVersion(fileExists(cgroupV2ControllersPath)), nil
+		},
+		CgroupDriver: kubelet.GetCgroupDriver,
+		NTPDaemon: func() (string, error) {
+			return firstActiveDaemon(ntpDaemons, isDaemonActive), nil
+		},
+		SELinuxMode: func() (string, error) {
+			return selinuxMode(os.ReadFile(selinuxEnforcePath))
+		},
+		AppArmorMode: func() (string, error) {
+			return apparmorMode(os.ReadFile(apparmorEnabledPath))
+		},
+		ContainerdVersion:  containerd.GetContainerdVersion,
+		CredentialProvider: detectCredentialProvider,
+	}
+}
+
+func nonEmpty(name string, get func() string) Detector {
+	return func() (string, error) {
+		value := get()
+		if value == "" {
+			return "", fmt.Errorf("%s not found in /etc/os-release", name)
+		}
+		return value, nil
+	}
+}
+
+func readTrimmed(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func detectFirewallBackend() (string, error) {
+	manager := system.NewFirewallManager()
+	enabled, err := manager.IsEnabled()
+	if err != nil {
+		return "", fmt.Errorf("getting firewall status: %w", err)
+	}
+	if !enabled {
+		return "none", nil
+	}
+	return firewall.Backend(manager), nil
+}
+
+func detectCredentialProvider() (string, error) {
+	installed, err := tracker.GetInstalledArtifacts()
+	if err != nil {
+		return "", fmt.Errorf("loading installed components: %w", err)
+	}
+	provider, err := creds.GetCredentialProviderFromInstalledArtifacts(installed.Artifacts)
+	if err != nil {
+		return "", err
+	}
+	return string(provider), nil
+}
+
+func isDaemonActive(name string) bool {
+	return exec.Command("systemctl", "is-active", "--quiet", name).Run() == nil
+}
+
+// parseIptablesBackend returns the backend from the iptables --version output,
+// like "iptables v1.8.7 (nf_tables)". Old versions only support the legacy backend.
+func parseIptablesBackend(version string) string {
+	switch {
+	case strings.Contains(version, "(nf_tables)"):
+		return "nft"
+	default:
+		return "legacy"
+	}
+}
+
+func cgroupVersion(unified bool) string {
+	if unified {
+		return "v2"
+	}
+	return "v1"
+}
+
+func firstActiveDaemon(daemons []string, isActive func(string) bool) string {
+	for _, daemon := range daemons {
+		if isActive(daemon) {
+			return daemon
+		}
+	}
+	return "none"
+}
+
+func selinuxMode(enforce []byte, err error) (string, error) {
+	if errors.Is(err, os.ErrNotExist) {
+		return "disabled", nil
+	} else if err != nil {
+		return "", fmt.Errorf("reading SELinux mode: %w", err)
+	}
+	if strings.TrimSpace(string(enforce)) == "1" {
+		return "enforcing", nil
+	}
+	return "permissive", nil
+}
+
+func apparmorMode(enabled []byte, err error) (string, error) {
+	if errors.Is(err, os.ErrNotExist) {
+		return "disabled", nil
+	} else if err != nil {
+		return "", fmt.Errorf("reading AppArmor mode: %w", err)
+	}
+	if strings.TrimSpace(string(enabled)) == "Y" {
+		return "enabled", nil
+	}
+	return "disabled", nil
+}
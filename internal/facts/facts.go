@@ -0,0 +1,80 @@
+package facts
+
+// Facts is a snapshot of the system configuration detected on the node.
+// It gives support a consistent document to triage issues.
+type Facts struct {
+	OSName             string `json:"osName"`
+	OSVersion          string `json:"osVersion"`
+	KernelVersion      string `json:"kernelVersion"`
+	PackageManager     string `json:"packageManager"`
+	FirewallBackend    string `json:"firewallBackend"`
+	IptablesBackend    string `json:"iptablesBackend"`
+	CgroupVersion      string `json:"cgroupVersion"`
+	CgroupDriver       string `json:"cgroupDriver"`
+	NTPDaemon          string `json:"ntpDaemon"`
+	SELinuxMode        string `json:"selinuxMode"`
+	AppArmorMode       string `json:"apparmorMode"`
+	ContainerdVersion  string `json:"containerdVersion"`
+	CredentialProvider string `json:"credentialProvider"`
+	// Errors holds the detection failures by fact, so a partial snapshot is still useful.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Detector detects the value of a single fact.
+type Detector func() (string, error)
+
+// Detectors detect each of the facts. A nil detector leaves its fact empty.
+type Detectors struct {
+	OSName             Detector
+	OSVersion          Detector
+	KernelVersion      Detector
+	PackageManager     Detector
+	FirewallBackend    Detector
+	IptablesBackend    Detector
+	CgroupVersion      Detector
+	CgroupDriver       Detector
+	NTPDaemon          Detector
+	SELinuxMode        Detector
+	AppArmorMode       Detector
+	ContainerdVersion  Detector
+	CredentialProvider Detector
+}
+
+// Collect runs all the detectors and returns the detected facts.
+// A failing detector doesn't stop the collection, its error is recorded instead.
+func Collect(detectors Detectors) Facts {
+	facts := Facts{}
+	for _, fact := range []struct {
+		name   string
+		detect Detector
+		value  *string
+	}{
+		{"osName", detectors.OSName, &facts.OSName},
+		{"osVersion", detectors.OSVersion, &facts.OSVersion},
+		{"kernelVersion", detectors.KernelVersion, &facts.KernelVersion},
+		{"packageManager", detectors.PackageManager, &facts.PackageManager},
+		{"firewallBackend", detectors.FirewallBackend, &facts.FirewallBackend},
+		{"iptablesBackend", detectors.IptablesBackend, &facts.IptablesBackend},
+		{"cgroupVersion", detectors.CgroupVersion, &facts.CgroupVersion},
+		{"cgroupDriver", detectors.CgroupDriver, &facts.CgroupDriver},
+		{"ntpDaemon", detectors.NTPDaemon, &facts.NTPDaemon},
+		{"selinuxMode", detectors.SELinuxMode, &facts.SELinuxMode},
+		{"apparmorMode", detectors.AppArmorMode, &facts.AppArmorMode},
+		{"containerdVersion", detectors.ContainerdVersion, &facts.ContainerdVersion},
+		{"credentialProvider", detectors.CredentialProvider, &facts.CredentialProvider},
+	} {
+		if fact.detect == nil {
+			continue
+		}
+		value, err := fact.detect()
+		if err != nil {
+			if facts.Errors == nil {
+				facts.Errors = map[string]string{}
+			}
+			facts.Errors[fact.name] = err.Error()
+			continue
+		}
+		*fact.value = value
+	}
+	return facts
+}
@@ -0,0 +1,115 @@
+package facts
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func value(v string) Detector {
+	return func() (string, error) { return v, nil }
+}
+
+func TestCollect(t *testing.T) {
+	g := NewWithT(t)
+
+	facts := Collect(Detectors{
+		OSName:          value("ubuntu"),
+		OSVersion:       value("22.04"),
+		KernelVersion:   value("5.15.0-1051-aws"),
+		FirewallBackend: func() (string, error) { return "", errors.New("ufw not found") },
+		CgroupVersion:   value("v2"),
+	})
+
+	g.Expect(facts.OSName).To(Equal("ubuntu"))
+	g.Expect(facts.OSVersion).To(Equal("22.04"))
+	g.Expect(facts.KernelVersion).To(Equal("5.15.0-1051-aws"))
+	g.Expect(facts.CgroupVersion).To(Equal("v2"))
+	g.Expect(facts.FirewallBackend).To(BeEmpty())
+	g.Expect(facts.PackageManager).To(BeEmpty())
+	g.Expect(facts.Errors).To(Equal(map[string]string{"firewallBackend": "ufw not found"}))
+}
+
+func TestCollectJSON(t *testing.T) {
+	g := NewWithT(t)
+
+	content, err := json.Marshal(Collect(Detectors{OSName: value("rhel")}))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	var document map[string]any
+	g.Expect(json.Unmarshal(content, &document)).To(Succeed())
+	g.Expect(document).To(HaveKeyWithValue("osName", "rhel"))
+	g.Expect(document).To(HaveKeyWithValue("cgroupDriver", ""))
+	g.Expect(document).NotTo(HaveKey("errors"))
+}
+
+func TestParseIptablesBackend(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "iptables v1.8.7 (nf_tables)", want: "nft"},
+		{version: "iptables v1.8.7 (legacy)", want: "legacy"},
+		{version: "iptables v1.4.21", want: "legacy"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			NewWithT(t).Expect(parseIptablesBackend(tc.version)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestFirstActiveDaemon(t *testing.T) {
+	g := NewWithT(t)
+	active := func(names ...string) func(string) bool {
+		return func(name string) bool {
+			for _, n := range names {
+				if n == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	g.Expect(firstActiveDaemon(ntpDaemons, active("ntpd", "systemd-timesyncd"))).To(Equal("systemd-timesyncd"))
+	g.Expect(firstActiveDaemon(ntpDaemons, active())).To(Equal("none"))
+}
+
+func TestSELinuxMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     error
+		want    string
+		wantErr string
+	}{
+		{name: "enforcing", content: "1\n", want: "enforcing"},
+		{name: "permissive", content: "0\n", want: "permissive"},
+		{name: "disabled", err: os.ErrNotExist, want: "disabled"},
+		{name: "read error", err: os.ErrPermission, wantErr: "reading SELinux mode: permission denied"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := selinuxMode([]byte(tc.content), tc.err)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(tc.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestAppArmorMode(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(apparmorMode([]byte("Y\n"), nil)).To(Equal("enabled"))
+	g.Expect(apparmorMode([]byte("N\n"), nil)).To(Equal("disabled"))
+	g.Expect(apparmorMode(nil, os.ErrNotExist)).To(Equal("disabled"))
+}
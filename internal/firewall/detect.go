@@ -0,0 +1,53 @@
+package firewall
+
+// Detect returns the Manager of the firewall backend filtering traffic on the node.
+// Firewall daemons are preferred over the rulesets they manage, so rules added by nodeadm
+// aren't overwritten the next time the daemon reloads. The nftables and iptables managers only
+// read the rulesets, see ReadOnly. If no backend is filtering traffic, it returns ufw if preferUFW
+// is set, and firewalld otherwise.
+func Detect(preferUFW bool) Manager {
+	return detect(execRunner{}, preferUFW)
+}
+
+func detect(runner commandRunner, preferUFW bool) Manager {
+	ufw := newUncomplicatedFirewall(runner)
+	fd := newFirewalld(runner)
+	for _, candidate := range []Manager{fd, ufw, newNftables(runner), newIptables(runner)} {
+		// Backends that fail to report their status are skipped, the fallback
+		// surfaces the error when its status is checked again.
+		if enabled, err := candidate.IsEnabled(); err == nil && enabled {
+			return candidate
+		}
+	}
+	if preferUFW {
+		return ufw
+	}
+	return fd
+}
+
+// ReadOnly returns true if nodeadm only reads the rules of the firewall backend managed by m,
+// its ports have to be opened by the operator.
+func ReadOnly(m Manager) bool {
+	switch m.(type) {
+	case *nftables, *iptables:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backend returns the name of the firewall backend managed by m, like ufw or nftables.
+func Backend(m Manager) string {
+	switch m.(type) {
+	case *firewalld:
+		return "firewalld"
+	case *UncomplicatedFireWall:
+		return "ufw"
+	case *nftables:
+		return "nftables"
+	case *iptables:
+		return "iptables"
+	default:
+		return "unknown"
+	}
+}
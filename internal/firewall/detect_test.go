@@ -0,0 +1,75 @@
+package firewall
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name         string
+		runner       *fakeRunner
+		preferUFW    bool
+		want         Manager
+		wantBackend  string
+		wantReadOnly bool
+	}{
+		{
+			name: "firewalld manages the nftables ruleset",
+			runner: newFakeRunner(firewalldBinary, nftBinary).
+				on("firewall-cmd --state", "running\n", nil).
+				on("nft list ruleset", nftFilteredRuleset, nil),
+			want:        &firewalld{},
+			wantBackend: "firewalld",
+		},
+		{
+			name: "ufw active",
+			runner: newFakeRunner(ufwBinary, iptablesBinary).
+				on("ufw status", ufwActiveStatus, nil).
+				on("iptables -S INPUT", iptablesFilteredInput, nil),
+			want:        &UncomplicatedFireWall{},
+			wantBackend: "ufw",
+		},
+		{
+			name: "nftables without a firewall daemon",
+			runner: newFakeRunner(firewalldBinary, nftBinary, iptablesBinary).
+				on("firewall-cmd --state", "not running\n", fakeExitError{code: notRunningExitCode}).
+				on("nft list ruleset", nftFilteredRuleset, nil),
+			want:         &nftables{},
+			wantBackend:  "nftables",
+			wantReadOnly: true,
+		},
+		{
+			name: "iptables without a firewall daemon",
+			runner: newFakeRunner(nftBinary, iptablesBinary).
+				on("nft list ruleset", "", nil).
+				on("iptables -S INPUT", iptablesFilteredInput, nil),
+			want:         &iptables{},
+			wantBackend:  "iptables",
+			wantReadOnly: true,
+		},
+		{
+			name:        "nothing filtering on ubuntu",
+			runner:      newFakeRunner(ufwBinary).on("ufw status", "Status: inactive\n", nil),
+			preferUFW:   true,
+			want:        &UncomplicatedFireWall{},
+			wantBackend: "ufw",
+		},
+		{
+			name:        "nothing filtering",
+			runner:      newFakeRunner(),
+			want:        &firewalld{},
+			wantBackend: "firewalld",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			manager := detect(tt.runner, tt.preferUFW)
+			g.Expect(manager).To(BeAssignableToTypeOf(tt.want))
+			g.Expect(Backend(manager)).To(Equal(tt.wantBackend))
+			g.Expect(ReadOnly(manager)).To(Equal(tt.wantReadOnly))
+		})
+	}
+}
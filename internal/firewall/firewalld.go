@@ -1,8 +1,8 @@
 package firewall
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
 	"regexp"
 )
 
@@ -20,12 +20,18 @@ var (
 
 type firewalld struct {
 	binPath string
+	runner  commandRunner
 }
 
 func NewFirewalld() Manager {
-	path, _ := exec.LookPath(firewalldBinary)
+	return newFirewalld(execRunner{})
+}
+
+func newFirewalld(runner commandRunner) *firewalld {
+	path, _ := runner.LookPath(firewalldBinary)
 	return &firewalld{
 		binPath: path,
+		runner:  runner,
 	}
 }
 
@@ -33,12 +39,11 @@ func NewFirewalld() Manager {
 func (fd *firewalld) IsEnabled() (bool, error) {
 	// Check if firewalld is installed
 	if fd.binPath != "" {
-		enabledCmd := exec.Command(fd.binPath, "--state")
-		out, err := enabledCmd.CombinedOutput()
+		out, err := fd.runner.CombinedOutput(fd.binPath, "--state")
 		if err != nil {
-			exitError, ok := err.(*exec.ExitError)
+			var exitErr exitCoder
 			// firewall-cmd returns non-zero exit codes for states other than running
-			if ok && (exitError.ExitCode() == runningButFailedExitCode || exitError.ExitCode() == notRunningExitCode) {
+			if errors.As(err, &exitErr) && (exitErr.ExitCode() == runningButFailedExitCode || exitErr.ExitCode() == notRunningExitCode) {
 				return false, nil
 			}
 			return false, err
@@ -51,51 +56,54 @@ func (fd *firewalld) IsEnabled() (bool, error) {
 	return false, nil
 }
 
-// AllowTcpPort adds a rule to the firewall to open input port
+// AllowTcpPort adds a rule to the runtime and permanent firewall configurations to open input port
 func (fd *firewalld) AllowTcpPort(port string) error {
-	portAddCmd := exec.Command(fd.binPath, "--permanent", fmt.Sprintf("--add-port=%s/tcp", port))
-	out, err := portAddCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to allow port %s in firewall: %s, error: %v", port, out, err)
+	if err := fd.addPort(fmt.Sprintf("--add-port=%s/tcp", port)); err != nil {
+		return fmt.Errorf("failed to allow port %s in firewall: %w", port, err)
 	}
 	return nil
 }
 
-// AllowTcpPortRange adds a rule to the firewall to open the range of input port
+// AllowTcpPortRange adds a rule to the runtime and permanent firewall configurations to open the range of input port
 func (fd *firewalld) AllowTcpPortRange(startPort, endPort string) error {
-	portAddCmd := exec.Command(fd.binPath, "--permanent", fmt.Sprintf("--add-port=%s-%s/tcp", startPort, endPort))
-	out, err := portAddCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to allow ports in firewall: %s, error: %v", out, err)
+	if err := fd.addPort(fmt.Sprintf("--add-port=%s-%s/tcp", startPort, endPort)); err != nil {
+		return fmt.Errorf("failed to allow ports in firewall: %w", err)
 	}
 	return nil
 }
 
-// FlushRules flushes the rules and reloads the firewall to enforce the rules
-func (fd *firewalld) FlushRules() error {
-	reloadCmd := exec.Command(fd.binPath, "--reload")
-	out, err := reloadCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to reload firewall: %s, error: %v", out, err)
+// addPort applies the rule to the runtime configuration, so it doesn't need a reload, and persists it.
+func (fd *firewalld) addPort(addPortFlag string) error {
+	for _, args := range [][]string{{addPortFlag}, {"--permanent", addPortFlag}} {
+		if out, err := fd.runner.CombinedOutput(fd.binPath, args...); err != nil {
+			return fmt.Errorf("%s, error: %v", out, err)
+		}
 	}
+	return nil
+}
 
-	persistCmd := exec.Command(fd.binPath, "--runtime-to-permanent")
-	out, err = persistCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to persist firewall rules: %s, error: %v", out, err)
-	}
+// FlushRules is a no-op, rules are added to both the runtime and permanent configurations.
+// Reloading firewalld would drop the runtime rules other tools on the node added.
+func (fd *firewalld) FlushRules() error {
 	return nil
 }
 
+// IsPortOpen returns true if port/protocol is open in the runtime or the permanent configuration.
+// Ports only open in the permanent configuration are open the next time firewalld reloads.
 func (fd *firewalld) IsPortOpen(port, protocol string) (bool, error) {
-	queryCmd := exec.Command(fd.binPath, fmt.Sprintf("--query-port=%s/%s", port, protocol))
-	out, err := queryCmd.CombinedOutput()
-	if err != nil {
-		// firewall-cmd returns an error if the port is not open
-		return false, nil
-	}
-	if match := firewallPortOpenRegex.MatchString(string(out)); match {
-		return true, nil
+	for _, args := range [][]string{
+		{fmt.Sprintf("--query-port=%s/%s", port, protocol)},
+		{"--permanent", fmt.Sprintf("--query-port=%s/%s", port, protocol)},
+	} {
+		out, err := fd.runner.CombinedOutput(fd.binPath, args...)
+		if err != nil {
+			// firewall-cmd returns an error if the port is not open
+			continue
+		}
+		if match := firewallPortOpenRegex.MatchString(string(out)); match {
+			return true, nil
+		}
+		return false, fmt.Errorf("unsupported firewall port status")
 	}
-	return false, fmt.Errorf("unsupported firewall port status")
+	return false, nil
 }
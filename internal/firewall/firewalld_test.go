@@ -0,0 +1,104 @@
+package firewall
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFirewalldIsEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		runner  *fakeRunner
+		want    bool
+		wantErr string
+	}{
+		{
+			name:   "not installed",
+			runner: newFakeRunner(),
+		},
+		{
+			name:   "running",
+			runner: newFakeRunner(firewalldBinary).on("firewall-cmd --state", "running\n", nil),
+			want:   true,
+		},
+		{
+			name:   "not running",
+			runner: newFakeRunner(firewalldBinary).on("firewall-cmd --state", "not running\n", fakeExitError{code: notRunningExitCode}),
+		},
+		{
+			name:    "unexpected error",
+			runner:  newFakeRunner(firewalldBinary).on("firewall-cmd --state", "", fakeExitError{code: 1}),
+			wantErr: "exit status 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			enabled, err := newFirewalld(tt.runner).IsEnabled()
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(enabled).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestFirewalldIsPortOpen(t *testing.T) {
+	notOpen := fakeExitError{code: 1}
+	tests := []struct {
+		name   string
+		runner *fakeRunner
+		want   bool
+	}{
+		{
+			name:   "open in runtime",
+			runner: newFakeRunner(firewalldBinary).on("firewall-cmd --query-port=8472/udp", "yes\n", nil),
+			want:   true,
+		},
+		{
+			name: "open in permanent configuration",
+			runner: newFakeRunner(firewalldBinary).
+				on("firewall-cmd --query-port=8472/udp", "no\n", notOpen).
+				on("firewall-cmd --permanent --query-port=8472/udp", "yes\n", nil),
+			want: true,
+		},
+		{
+			name: "closed",
+			runner: newFakeRunner(firewalldBinary).
+				on("firewall-cmd --query-port=8472/udp", "no\n", notOpen).
+				on("firewall-cmd --permanent --query-port=8472/udp", "no\n", notOpen),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			open, err := newFirewalld(tt.runner).IsPortOpen("8472", "udp")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(open).To(Equal(tt.want))
+			g.Expect(tt.runner.ran).NotTo(ContainElement(ContainSubstring("--reload")))
+		})
+	}
+}
+
+func TestFirewalldAllowTcpPortDoesNotReload(t *testing.T) {
+	g := NewWithT(t)
+	runner := newFakeRunner(firewalldBinary).
+		on("firewall-cmd --add-port=10250/tcp", "success\n", nil).
+		on("firewall-cmd --permanent --add-port=10250/tcp", "success\n", nil).
+		on("firewall-cmd --add-port=30000-32767/tcp", "success\n", nil).
+		on("firewall-cmd --permanent --add-port=30000-32767/tcp", "success\n", nil)
+	fd := newFirewalld(runner)
+
+	g.Expect(fd.AllowTcpPort("10250")).To(Succeed())
+	g.Expect(fd.AllowTcpPortRange("30000", "32767")).To(Succeed())
+	g.Expect(fd.FlushRules()).To(Succeed())
+	g.Expect(runner.ran).To(Equal([]string{
+		"firewall-cmd --add-port=10250/tcp",
+		"firewall-cmd --permanent --add-port=10250/tcp",
+		"firewall-cmd --add-port=30000-32767/tcp",
+		"firewall-cmd --permanent --add-port=30000-32767/tcp",
+	}))
+}
@@ -1,5 +1,11 @@
 package firewall
 
+import "errors"
+
+// ErrReadOnly is returned when adding rules to a firewall backend nodeadm only reads, the raw
+// nftables and iptables rulesets managed by operators or kube-proxy.
+var ErrReadOnly = errors.New("nodeadm doesn't change the rules of this firewall backend")
+
 // Manager is an interface for providing firewall functionalities
 type Manager interface {
 	// IsEnabled returns if firewall is enabled
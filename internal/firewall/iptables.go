@@ -0,0 +1,154 @@
+package firewall
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const iptablesBinary = "iptables"
+
+// iptables reads the rules of the iptables INPUT chain on nodes without a firewall daemon.
+// The ruleset is managed by operators or kube-proxy, so nodeadm never changes it.
+type iptables struct {
+	binPath string
+	runner  commandRunner
+	// rules are the INPUT chain rules in iptables -S format, read once.
+	rules []string
+}
+
+func newIptables(runner commandRunner) *iptables {
+	path, _ := runner.LookPath(iptablesBinary)
+	return &iptables{
+		binPath: path,
+		runner:  runner,
+	}
+}
+
+// IsEnabled returns true if the INPUT chain drops or rejects the traffic its rules don't accept:
+// its policy is DROP or it has an unconditional DROP or REJECT rule.
+func (ipt *iptables) IsEnabled() (bool, error) {
+	if ipt.binPath == "" {
+		return false, nil
+	}
+	if err := ipt.refreshRules(); err != nil {
+		return false, err
+	}
+	for _, rule := range ipt.rules {
+		fields := strings.Fields(rule)
+		if len(fields) >= 3 && fields[0] == "-P" && (fields[2] == "DROP" || fields[2] == "REJECT") {
+			return true, nil
+		}
+		if iptablesUnconditionalDrop(fields) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AllowTcpPort returns ErrReadOnly, nodeadm doesn't change the iptables rules
+func (ipt *iptables) AllowTcpPort(string) error {
+	return ErrReadOnly
+}
+
+// AllowTcpPortRange returns ErrReadOnly, nodeadm doesn't change the iptables rules
+func (ipt *iptables) AllowTcpPortRange(string, string) error {
+	return ErrReadOnly
+}
+
+// FlushRules is a no-op, nodeadm doesn't change the iptables rules
+func (ipt *iptables) FlushRules() error {
+	return nil
+}
+
+// IsPortOpen returns true if the INPUT chain accepts port/protocol, explicitly or by policy.
+// Rules are evaluated in order like iptables does, so an ACCEPT for the port wins over a later
+// unconditional DROP or REJECT, and rules matching other traffic are ignored. It only reads the ruleset.
+func (ipt *iptables) IsPortOpen(port, protocol string) (bool, error) {
+	if ipt.rules == nil {
+		if err := ipt.refreshRules(); err != nil {
+			return false, err
+		}
+	}
+	policyAccept := true
+	for _, rule := range ipt.rules {
+		fields := strings.Fields(rule)
+		switch {
+		case len(fields) >= 3 && fields[0] == "-P":
+			policyAccept = fields[2] == "ACCEPT"
+		case fields[0] == "-A" && ruleTarget(fields) == "ACCEPT" && iptablesRuleMatchesPort(fields, port, protocol):
+			return true, nil
+		case iptablesUnconditionalDrop(fields):
+			return false, nil
+		}
+	}
+	return policyAccept, nil
+}
+
+func (ipt *iptables) refreshRules() error {
+	out, err := ipt.runner.CombinedOutput(ipt.binPath, "-S", "INPUT")
+	if err != nil {
+		return fmt.Errorf("failed to list iptables INPUT rules: %s, error: %v", out, err)
+	}
+	ipt.rules = []string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			ipt.rules = append(ipt.rules, line)
+		}
+	}
+	return scanner.Err()
+}
+
+// ruleTarget returns the target the rule jumps to.
+func ruleTarget(fields []string) string {
+	if i := slices.Index(fields, "-j"); i >= 0 && i+1 < len(fields) {
+		return fields[i+1]
+	}
+	return ""
+}
+
+// iptablesUnconditionalDrop returns true if the rule drops or rejects all the traffic reaching it,
+// like -A INPUT -j REJECT --reject-with icmp-host-prohibited.
+func iptablesUnconditionalDrop(fields []string) bool {
+	return len(fields) >= 4 && fields[0] == "-A" && fields[2] == "-j" && (fields[3] == "DROP" || fields[3] == "REJECT")
+}
+
+// iptablesRuleMatchesPort returns true if the rule matches port/protocol with --dport or multiport --dports.
+func iptablesRuleMatchesPort(fields []string, port, protocol string) bool {
+	if i := slices.Index(fields, "-p"); i < 0 || i+1 >= len(fields) || fields[i+1] != protocol {
+		return false
+	}
+	for _, flag := range []string{"--dport", "--dports"} {
+		if i := slices.Index(fields, flag); i >= 0 && i+1 < len(fields) {
+			return portInList(port, strings.Split(fields[i+1], ","), ":")
+		}
+	}
+	return false
+}
+
+// portInList returns true if port is in the list of ports and port ranges, whose bounds are split by rangeSep.
+func portInList(port string, ports []string, rangeSep string) bool {
+	number, err := strconv.Atoi(port)
+	if err != nil {
+		return false
+	}
+	for _, entry := range ports {
+		entry = strings.TrimSpace(entry)
+		start, end, isRange := strings.Cut(entry, rangeSep)
+		if !isRange {
+			if entry == port {
+				return true
+			}
+			continue
+		}
+		startNumber, startErr := strconv.Atoi(start)
+		endNumber, endErr := strconv.Atoi(end)
+		if startErr == nil && endErr == nil && number >= startNumber && number <= endNumber {
+			return true
+		}
+	}
+	return false
+}
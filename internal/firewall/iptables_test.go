@@ -0,0 +1,145 @@
+package firewall
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const iptablesFilteredInput = `-P INPUT DROP
+-A INPUT -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A INPUT -p tcp -m tcp --dport 22 -j ACCEPT
+-A INPUT -p udp -m udp --dport 8472 -j ACCEPT
+-A INPUT -p tcp -m multiport --dports 179,5473,30000:32767 -j ACCEPT
+`
+
+func TestIptablesIsEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner *fakeRunner
+		want   bool
+	}{
+		{
+			name:   "not installed",
+			runner: newFakeRunner(),
+		},
+		{
+			name:   "drop policy",
+			runner: newFakeRunner(iptablesBinary).on("iptables -S INPUT", iptablesFilteredInput, nil),
+			want:   true,
+		},
+		{
+			name:   "reject rule",
+			runner: newFakeRunner(iptablesBinary).on("iptables -S INPUT", "-P INPUT ACCEPT\n-A INPUT -j REJECT --reject-with icmp-host-prohibited\n", nil),
+			want:   true,
+		},
+		{
+			name:   "conditional drop rule",
+			runner: newFakeRunner(iptablesBinary).on("iptables -S INPUT", "-P INPUT ACCEPT\n-A INPUT -s 203.0.113.0/24 -j DROP\n", nil),
+		},
+		{
+			name:   "accept policy without filtering rules",
+			runner: newFakeRunner(iptablesBinary).on("iptables -S INPUT", "-P INPUT ACCEPT\n-A INPUT -j KUBE-FIREWALL\n", nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			enabled, err := newIptables(tt.runner).IsEnabled()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(enabled).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestIptablesIsPortOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		port     string
+		protocol string
+		want     bool
+	}{
+		{
+			name:     "dport",
+			rules:    iptablesFilteredInput,
+			port:     "8472",
+			protocol: "udp",
+			want:     true,
+		},
+		{
+			name:     "dport with a different protocol",
+			rules:    iptablesFilteredInput,
+			port:     "8472",
+			protocol: "tcp",
+		},
+		{
+			name:     "multiport",
+			rules:    iptablesFilteredInput,
+			port:     "5473",
+			protocol: "tcp",
+			want:     true,
+		},
+		{
+			name:     "multiport range",
+			rules:    iptablesFilteredInput,
+			port:     "31000",
+			protocol: "tcp",
+			want:     true,
+		},
+		{
+			name:     "not allowed",
+			rules:    iptablesFilteredInput,
+			port:     "4240",
+			protocol: "tcp",
+		},
+		{
+			name:     "accept policy",
+			rules:    "-P INPUT ACCEPT\n",
+			port:     "4240",
+			protocol: "tcp",
+			want:     true,
+		},
+		{
+			name:     "accepted before a final reject",
+			rules:    "-P INPUT ACCEPT\n-A INPUT -p udp -m udp --dport 8472 -j ACCEPT\n-A INPUT -j REJECT --reject-with icmp-host-prohibited\n",
+			port:     "8472",
+			protocol: "udp",
+			want:     true,
+		},
+		{
+			name:     "accepted after an unconditional reject",
+			rules:    "-P INPUT ACCEPT\n-A INPUT -j REJECT --reject-with icmp-host-prohibited\n-A INPUT -p udp -m udp --dport 8472 -j ACCEPT\n",
+			port:     "8472",
+			protocol: "udp",
+		},
+		{
+			name:     "conditional drop with accept policy",
+			rules:    "-P INPUT ACCEPT\n-A INPUT -s 203.0.113.0/24 -j DROP\n",
+			port:     "8472",
+			protocol: "udp",
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			runner := newFakeRunner(iptablesBinary).on("iptables -S INPUT", tt.rules, nil)
+			open, err := newIptables(runner).IsPortOpen(tt.port, tt.protocol)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(open).To(Equal(tt.want))
+			g.Expect(runner.ran).To(Equal([]string{"iptables -S INPUT"}))
+		})
+	}
+}
+
+func TestIptablesIsReadOnly(t *testing.T) {
+	g := NewWithT(t)
+	runner := newFakeRunner(iptablesBinary)
+	ipt := newIptables(runner)
+
+	g.Expect(ipt.AllowTcpPort("10250")).To(MatchError(ErrReadOnly))
+	g.Expect(ipt.AllowTcpPortRange("30000", "32767")).To(MatchError(ErrReadOnly))
+	g.Expect(ipt.FlushRules()).To(Succeed())
+	g.Expect(runner.ran).To(BeEmpty())
+}
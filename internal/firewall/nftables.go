@@ -0,0 +1,201 @@
+package firewall
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const nftBinary = "nft"
+
+var (
+	nftTableRegex     = regexp.MustCompile(`^table\s+(\S+)\s+(\S+)\s*{`)
+	nftChainRegex     = regexp.MustCompile(`^chain\s+(\S+)\s*{`)
+	nftInputHookRegex = regexp.MustCompile(`hook\s+input\b.*policy\s+(\w+)`)
+	nftDportRegex     = regexp.MustCompile(`\b(tcp|udp)\s+dport\s+({[^}]*}|\S+)`)
+)
+
+// nftChain is a base chain filtering the node input traffic.
+type nftChain struct {
+	family string
+	table  string
+	name   string
+	policy string
+	rules  []string
+}
+
+// nftables reads the rules of the nftables input chains on nodes without a firewall daemon.
+// The ruleset is managed by operators or kube-proxy, so nodeadm never changes it.
+type nftables struct {
+	binPath string
+	runner  commandRunner
+	// chains are the input base chains of the ruleset, read once.
+	chains []nftChain
+}
+
+func newNftables(runner commandRunner) *nftables {
+	path, _ := runner.LookPath(nftBinary)
+	return &nftables{
+		binPath: path,
+		runner:  runner,
+	}
+}
+
+// IsEnabled returns true if an input chain drops or rejects the traffic its rules don't accept
+func (nft *nftables) IsEnabled() (bool, error) {
+	if nft.binPath == "" {
+		return false, nil
+	}
+	if err := nft.refreshRuleset(); err != nil {
+		return false, err
+	}
+	for _, chain := range nft.chains {
+		if chain.filters() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AllowTcpPort returns ErrReadOnly, nodeadm doesn't change the nftables ruleset
+func (nft *nftables) AllowTcpPort(string) error {
+	return ErrReadOnly
+}
+
+// AllowTcpPortRange returns ErrReadOnly, nodeadm doesn't change the nftables ruleset
+func (nft *nftables) AllowTcpPortRange(string, string) error {
+	return ErrReadOnly
+}
+
+// FlushRules is a no-op, nodeadm doesn't change the nftables ruleset
+func (nft *nftables) FlushRules() error {
+	return nil
+}
+
+// IsPortOpen returns true if every input chain accepts port/protocol, explicitly or by policy.
+// It only reads the ruleset.
+func (nft *nftables) IsPortOpen(port, protocol string) (bool, error) {
+	if nft.chains == nil {
+		if err := nft.refreshRuleset(); err != nil {
+			return false, err
+		}
+	}
+	for _, chain := range nft.chains {
+		if !chain.accepts(port, protocol) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (nft *nftables) refreshRuleset() error {
+	out, err := nft.runner.CombinedOutput(nft.binPath, "list", "ruleset")
+	if err != nil {
+		return fmt.Errorf("failed to list nftables ruleset: %s, error: %v", out, err)
+	}
+	nft.chains = parseNftInputChains(string(out))
+	return nil
+}
+
+// filters returns true if the chain drops or rejects the traffic its rules don't accept: its
+// policy is drop or it has an unconditional drop or reject rule.
+func (c nftChain) filters() bool {
+	if c.policy == "drop" {
+		return true
+	}
+	for _, rule := range c.rules {
+		if nftUnconditionalDrop(rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// accepts returns true if the chain accepts port/protocol, explicitly or by policy. Rules are
+// evaluated in order like nftables does, so an accept for the port wins over a later unconditional
+// drop or reject, and rules matching other traffic are ignored.
+func (c nftChain) accepts(port, protocol string) bool {
+	for _, rule := range c.rules {
+		if nftVerdict(rule) == "accept" && nftRuleMatchesPort(rule, port, protocol) {
+			return true
+		}
+		if nftUnconditionalDrop(rule) {
+			return false
+		}
+	}
+	return c.policy != "drop"
+}
+
+// nftRuleMatchesPort returns true if the rule matches port/protocol with a dport, set or range.
+func nftRuleMatchesPort(rule, port, protocol string) bool {
+	for _, match := range nftDportRegex.FindAllStringSubmatch(rule, -1) {
+		if match[1] != protocol {
+			continue
+		}
+		ports := strings.Split(strings.Trim(match[2], "{} "), ",")
+		if portInList(port, ports, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+// nftUnconditionalDrop returns true if the rule drops or rejects all the traffic reaching it,
+// optionally counting it, like counter packets 0 bytes 0 reject with icmpx admin-prohibited.
+func nftUnconditionalDrop(rule string) bool {
+	fields := strings.Fields(rule)
+	if len(fields) > 0 && fields[0] == "counter" {
+		fields = fields[1:]
+		if len(fields) >= 4 && fields[0] == "packets" && fields[2] == "bytes" {
+			fields = fields[4:]
+		}
+	}
+	return len(fields) > 0 && (fields[0] == "drop" || fields[0] == "reject")
+}
+
+// nftVerdict returns the last word of the rule, which is its verdict for the rules this checks.
+func nftVerdict(rule string) string {
+	fields := strings.Fields(rule)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// parseNftInputChains parses the base chains hooked to input from the nft list ruleset output.
+func parseNftInputChains(ruleset string) []nftChain {
+	chains := []nftChain{}
+	var family, table string
+	var current *nftChain
+	scanner := bufio.NewScanner(strings.NewReader(ruleset))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if match := nftTableRegex.FindStringSubmatch(line); match != nil {
+			family, table = match[1], match[2]
+			continue
+		}
+		if match := nftChainRegex.FindStringSubmatch(line); match != nil {
+			current = &nftChain{family: family, table: table, name: match[1]}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if line == "}" {
+			if current.policy != "" {
+				chains = append(chains, *current)
+			}
+			current = nil
+			continue
+		}
+		if match := nftInputHookRegex.FindStringSubmatch(line); match != nil {
+			current.policy = match[1]
+			continue
+		}
+		if line != "" {
+			current.rules = append(current.rules, line)
+		}
+	}
+	return chains
+}
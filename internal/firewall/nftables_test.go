@@ -0,0 +1,172 @@
+package firewall
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const nftFilteredRuleset = `table inet filter {
+	chain input {
+		type filter hook input priority filter; policy drop;
+		ct state established,related accept
+		tcp dport 22 accept
+		udp dport 8472 accept
+		tcp dport { 179, 5473 } accept
+		tcp dport 30000-32767 accept
+	}
+
+	chain forward {
+		type filter hook forward priority filter; policy accept;
+	}
+}
+table ip nat {
+	chain postrouting {
+		type nat hook postrouting priority srcnat; policy accept;
+		oifname "eth0" masquerade
+	}
+}
+`
+
+func TestNftablesIsEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner *fakeRunner
+		want   bool
+	}{
+		{
+			name:   "not installed",
+			runner: newFakeRunner(),
+		},
+		{
+			name:   "drop policy",
+			runner: newFakeRunner(nftBinary).on("nft list ruleset", nftFilteredRuleset, nil),
+			want:   true,
+		},
+		{
+			name: "reject rule",
+			runner: newFakeRunner(nftBinary).on("nft list ruleset", `table inet filter {
+	chain input {
+		type filter hook input priority filter; policy accept;
+		tcp dport 22 accept
+		reject
+	}
+}
+`, nil),
+			want: true,
+		},
+		{
+			name: "conditional drop rule",
+			runner: newFakeRunner(nftBinary).on("nft list ruleset", `table inet filter {
+	chain input {
+		type filter hook input priority filter; policy accept;
+		ip saddr 203.0.113.0/24 counter packets 0 bytes 0 drop
+	}
+}
+`, nil),
+		},
+		{
+			name:   "empty ruleset",
+			runner: newFakeRunner(nftBinary).on("nft list ruleset", "", nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			enabled, err := newNftables(tt.runner).IsEnabled()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(enabled).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestNftablesIsPortOpen(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		protocol string
+		want     bool
+	}{
+		{
+			name:     "dport",
+			port:     "8472",
+			protocol: "udp",
+			want:     true,
+		},
+		{
+			name:     "dport with a different protocol",
+			port:     "8472",
+			protocol: "tcp",
+		},
+		{
+			name:     "set",
+			port:     "5473",
+			protocol: "tcp",
+			want:     true,
+		},
+		{
+			name:     "range",
+			port:     "31000",
+			protocol: "tcp",
+			want:     true,
+		},
+		{
+			name:     "not allowed",
+			port:     "4240",
+			protocol: "tcp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			runner := newFakeRunner(nftBinary).on("nft list ruleset", nftFilteredRuleset, nil)
+			open, err := newNftables(runner).IsPortOpen(tt.port, tt.protocol)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(open).To(Equal(tt.want))
+			g.Expect(runner.ran).To(Equal([]string{"nft list ruleset"}))
+		})
+	}
+}
+
+func TestNftablesIsPortOpenEvaluatesRulesInOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  bool
+	}{
+		{
+			name:  "accepted before a final reject",
+			rules: "udp dport 8472 accept\n\t\tcounter packets 12 bytes 720 reject with icmpx admin-prohibited",
+			want:  true,
+		},
+		{
+			name:  "accepted after an unconditional drop",
+			rules: "drop\n\t\tudp dport 8472 accept",
+		},
+		{
+			name:  "conditional drop",
+			rules: "ip saddr 203.0.113.0/24 drop",
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ruleset := "table inet filter {\n\tchain input {\n\t\ttype filter hook input priority filter; policy accept;\n\t\t" + tt.rules + "\n\t}\n}\n"
+			open, err := newNftables(newFakeRunner(nftBinary).on("nft list ruleset", ruleset, nil)).IsPortOpen("8472", "udp")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(open).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestNftablesIsReadOnly(t *testing.T) {
+	g := NewWithT(t)
+	runner := newFakeRunner(nftBinary)
+	nft := newNftables(runner)
+
+	g.Expect(nft.AllowTcpPort("10250")).To(MatchError(ErrReadOnly))
+	g.Expect(nft.AllowTcpPortRange("30000", "32767")).To(MatchError(ErrReadOnly))
+	g.Expect(nft.FlushRules()).To(Succeed())
+	g.Expect(runner.ran).To(BeEmpty())
+}
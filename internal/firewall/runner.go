@@ -0,0 +1,26 @@
+package firewall
+
+import "os/exec"
+
+// commandRunner runs the firewall commands.
+type commandRunner interface {
+	// LookPath returns the path of the binary, or an error if it's not installed.
+	LookPath(file string) (string, error)
+	// CombinedOutput runs the command and returns its combined stdout and stderr.
+	CombinedOutput(name string, args ...string) ([]byte, error)
+}
+
+// exitCoder is implemented by the errors of commands that exit with a non-zero code.
+type exitCoder interface {
+	ExitCode() int
+}
+
+type execRunner struct{}
+
+func (execRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+func (execRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
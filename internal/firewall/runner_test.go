@@ -0,0 +1,65 @@
+package firewall
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fakeExitError is returned by fakeRunner for commands that exit with a non-zero code.
+type fakeExitError struct {
+	code int
+}
+
+func (e fakeExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func (e fakeExitError) ExitCode() int {
+	return e.code
+}
+
+type fakeResult struct {
+	output string
+	err    error
+}
+
+// fakeRunner returns canned results for command lines, and records the commands it ran.
+// Commands without a result fail as if they were not found.
+type fakeRunner struct {
+	binaries map[string]bool
+	results  map[string]fakeResult
+	ran      []string
+}
+
+func newFakeRunner(binaries ...string) *fakeRunner {
+	r := &fakeRunner{
+		binaries: map[string]bool{},
+		results:  map[string]fakeResult{},
+	}
+	for _, binary := range binaries {
+		r.binaries[binary] = true
+	}
+	return r
+}
+
+func (r *fakeRunner) on(command string, output string, err error) *fakeRunner {
+	r.results[command] = fakeResult{output: output, err: err}
+	return r
+}
+
+func (r *fakeRunner) LookPath(file string) (string, error) {
+	if !r.binaries[file] {
+		return "", fmt.Errorf("executable file not found in $PATH: %s", file)
+	}
+	return file, nil
+}
+
+func (r *fakeRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	command := strings.Join(append([]string{name}, args...), " ")
+	r.ran = append(r.ran, command)
+	result, ok := r.results[command]
+	if !ok {
+		return nil, fmt.Errorf("unexpected command: %s", command)
+	}
+	return []byte(result.output), result.err
+}
@@ -3,7 +3,6 @@ package firewall
 import (
 	"bufio"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
 )
@@ -21,6 +20,7 @@ var (
 
 type UncomplicatedFireWall struct {
 	binPath string
+	runner  commandRunner
 	rules   []rule
 }
 
@@ -31,9 +31,14 @@ type rule struct {
 }
 
 func NewUncomplicatedFirewall() Manager {
-	path, _ := exec.LookPath(ufwBinary)
+	return newUncomplicatedFirewall(execRunner{})
+}
+
+func newUncomplicatedFirewall(runner commandRunner) *UncomplicatedFireWall {
+	path, _ := runner.LookPath(ufwBinary)
 	return &UncomplicatedFireWall{
 		binPath: path,
+		runner:  runner,
 	}
 }
 
@@ -41,8 +46,7 @@ func NewUncomplicatedFirewall() Manager {
 func (ufw *UncomplicatedFireWall) IsEnabled() (bool, error) {
 	// Check if ufw is installed
 	if ufw.binPath != "" {
-		statusCmd := exec.Command(ufw.binPath, "status")
-		out, err := statusCmd.CombinedOutput()
+		out, err := ufw.runner.CombinedOutput(ufw.binPath, "status")
 		if err != nil {
 			return false, fmt.Errorf("failed to get status of uncomplicated firewall: %s, error: %v", out, err)
 		}
@@ -56,8 +60,7 @@ func (ufw *UncomplicatedFireWall) IsEnabled() (bool, error) {
 
 // AllowTcpPort adds a rule to the firewall to open input port
 func (ufw *UncomplicatedFireWall) AllowTcpPort(port string) error {
-	portAddCmd := exec.Command(ufw.binPath, "allow", fmt.Sprintf("%s/tcp", port))
-	out, err := portAddCmd.CombinedOutput()
+	out, err := ufw.runner.CombinedOutput(ufw.binPath, "allow", fmt.Sprintf("%s/tcp", port))
 	if err != nil {
 		return fmt.Errorf("failed to allow port %s in firewall: %s, error: %v", port, out, err)
 	}
@@ -66,8 +69,7 @@ func (ufw *UncomplicatedFireWall) AllowTcpPort(port string) error {
 
 // AllowTcpPortRange adds a rule to the firewall to open the range of input port
 func (ufw *UncomplicatedFireWall) AllowTcpPortRange(startPort, endPort string) error {
-	portAddCmd := exec.Command(ufw.binPath, "allow", fmt.Sprintf("%s:%s/tcp", startPort, endPort))
-	out, err := portAddCmd.CombinedOutput()
+	out, err := ufw.runner.CombinedOutput(ufw.binPath, "allow", fmt.Sprintf("%s:%s/tcp", startPort, endPort))
 	if err != nil {
 		return fmt.Errorf("failed to allow ports in firewall: %s, error: %v", out, err)
 	}
@@ -98,8 +100,7 @@ func (ufw *UncomplicatedFireWall) IsPortOpen(port, protocol string) (bool, error
 }
 
 func (ufw *UncomplicatedFireWall) refreshActiveRules() error {
-	statusCmd := exec.Command(ufw.binPath, "status")
-	out, err := statusCmd.CombinedOutput()
+	out, err := ufw.runner.CombinedOutput(ufw.binPath, "status")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,37 @@
+package firewall
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const ufwActiveStatus = `Status: active
+
+To                         Action      From
+--                         ------      ----
+22/tcp                     ALLOW       Anywhere
+8472/udp                   ALLOW       Anywhere
+4240/tcp                   DENY        Anywhere
+`
+
+func TestUncomplicatedFirewallIsEnabled(t *testing.T) {
+	g := NewWithT(t)
+
+	enabled, err := newUncomplicatedFirewall(newFakeRunner(ufwBinary).on("ufw status", ufwActiveStatus, nil)).IsEnabled()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(enabled).To(BeTrue())
+
+	enabled, err = newUncomplicatedFirewall(newFakeRunner(ufwBinary).on("ufw status", "Status: inactive\n", nil)).IsEnabled()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(enabled).To(BeFalse())
+}
+
+func TestUncomplicatedFirewallIsPortOpen(t *testing.T) {
+	g := NewWithT(t)
+	ufw := newUncomplicatedFirewall(newFakeRunner(ufwBinary).on("ufw status", ufwActiveStatus, nil))
+
+	g.Expect(ufw.IsPortOpen("8472", "udp")).To(BeTrue())
+	g.Expect(ufw.IsPortOpen("4240", "tcp")).To(BeFalse())
+	g.Expect(ufw.IsPortOpen("179", "tcp")).To(BeFalse())
+}
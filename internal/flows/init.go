@@ -2,19 +2,45 @@ package flows
 
 import (
 	"context"
+	"fmt"
+	"runtime"
+	"time"
 
 	"go.uber.org/zap"
+	v1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/utils/strings/slices"
 
+	"github.com/aws/eks-hybrid/cmd/nodeadm/version"
 	"github.com/aws/eks-hybrid/internal/aws"
+	"github.com/aws/eks-hybrid/internal/aws/ecr"
+	"github.com/aws/eks-hybrid/internal/cli"
 	"github.com/aws/eks-hybrid/internal/configenricher"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/node"
 	"github.com/aws/eks-hybrid/internal/nodeprovider"
+	"github.com/aws/eks-hybrid/internal/nodevalidator"
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 const (
 	preprocessPhase = "preprocess"
 	configPhase     = "config"
 	runPhase        = "run"
+	nodeLabelsPhase = "node-labels"
+	// containerRuntimePhase validates the container runtime nodeadm doesn't manage, installed with --containerd-source none.
+	containerRuntimePhase = "container-runtime-validation"
+	// imagePullPhase pulls the sandbox image from ECR with the image credential provider credentials
+	// to validate the credential provider, container runtime and ECR chain kubelet uses for pods.
+	// A sandbox image outside ECR is pulled with the containerd registry config instead.
+	imagePullPhase = "image-pull-validation"
+
+	containerRuntimeValidationTimeout = 30 * time.Second
+	imagePullValidationTimeout        = 2 * time.Minute
+
+	nodeLabelsRegistrationTimeout = 2 * time.Minute
+	systemPodsTimeout             = 10 * time.Minute
 )
 
 type Initer struct {
@@ -23,6 +49,23 @@ type Initer struct {
 	Logger           *zap.Logger
 	ManifestOverride string
 	PrivateMode      bool
+	// ExternalRuntime is true when containerd is managed outside of nodeadm. The runtime
+	// is then validated to be CRI compatible and healthy before starting kubelet.
+	ExternalRuntime bool
+	// EmitJoinEvents waits for the node to join the cluster after starting the daemons
+	// and emits Kubernetes events for each milestone. It never fails init.
+	EmitJoinEvents bool
+	// WaitForSystemPods waits for the system DaemonSet pods to be scheduled and running on the node
+	// after starting the daemons, failing init if they don't within the timeout.
+	WaitForSystemPods bool
+	// SystemDaemonSets are the DaemonSets, in namespace/name form, expected to run on the node.
+	// When empty, all the DaemonSets in kube-system selecting the node are expected.
+	SystemDaemonSets []string
+	// OSInfo is the OS of the host, reported in the node labels.
+	OSInfo system.OSInfo
+	// SandboxImage is the sandbox image configured at install, containerd and kubelet
+	// are configured with it instead of the EKS default when set.
+	SandboxImage string
 }
 
 func (i *Initer) Run(ctx context.Context) error {
@@ -55,7 +98,7 @@ func (i *Initer) Run(ctx context.Context) error {
 		}
 	}
 
-	if err := i.NodeProvider.Enrich(ctx, configenricher.WithRegionConfig(regionConfig)); err != nil {
+	if err := i.NodeProvider.Enrich(ctx, configenricher.WithRegionConfig(regionConfig), configenricher.WithSandboxImage(i.SandboxImage)); err != nil {
 		return err
 	}
 
@@ -74,19 +117,97 @@ func (i *Initer) Run(ctx context.Context) error {
 		i.Logger.Info("Finished setting up system aspect", nameField)
 	}
 
-	if err := initDaemons(ctx, i.NodeProvider, i.SkipPhases, i.Logger); err != nil {
+	if err := initDaemons(ctx, i.NodeProvider, i.SkipPhases, i.ExternalRuntime, i.Logger); err != nil {
 		return err
 	}
 
+	if !slices.Contains(i.SkipPhases, runPhase) && !slices.Contains(i.SkipPhases, imagePullPhase) {
+		nodeConfig := i.NodeProvider.GetNodeConfig()
+		if err := validateImagePull(ctx, containerd.ImageServiceEndpoint(nodeConfig), nodeConfig.Status.Defaults.SandboxImage, i.Logger); err != nil {
+			return err
+		}
+		cli.PhaseRun(ctx, imagePullPhase)
+	}
+
+	if (i.EmitJoinEvents || i.WaitForSystemPods) && !slices.Contains(i.SkipPhases, runPhase) {
+		if err := i.validateActiveNode(ctx); err != nil {
+			return err
+		}
+	}
+
+	if i.NodeProvider.GetNodeConfig().IsHybridNode() &&
+		!slices.Contains(i.SkipPhases, runPhase) && !slices.Contains(i.SkipPhases, nodeLabelsPhase) {
+		i.labelNode(ctx)
+		cli.PhaseRun(ctx, nodeLabelsPhase)
+	}
+
+	recordSkippedValidations("init", i.SkipPhases, i.Logger)
+
 	return i.NodeProvider.Cleanup()
 }
 
-func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, skipPhases []string, logger *zap.Logger) error {
+// validateActiveNode waits for the node to join the cluster once, emitting the join events and
+// waiting for the system DaemonSet pods to run on it as configured. Missing system pods, which
+// catch DaemonSets that don't tolerate the node taints, fail init, failing to emit the join events doesn't.
+func (i *Initer) validateActiveNode(ctx context.Context) error {
+	opts := []func(*nodevalidator.ActiveNodeValidator){nodevalidator.WithJoinEvents(i.EmitJoinEvents)}
+	if i.EmitJoinEvents {
+		i.Logger.Info("Waiting for node to join the cluster to emit join events...")
+	}
+	if i.WaitForSystemPods {
+		i.Logger.Info("Waiting for system DaemonSet pods to run on the node...", zap.Strings("daemonSets", i.SystemDaemonSets))
+		opts = append(opts,
+			nodevalidator.WithTimeout(systemPodsTimeout),
+			nodevalidator.WithDaemonSetPods(true, i.SystemDaemonSets...),
+		)
+	}
+	validator := nodevalidator.NewActiveNodeValidator(opts...)
+	result, err := validator.Execute(ctx, validation.NewLoggerPrinterWithLogger(i.Logger), i.NodeProvider.GetNodeConfig())
+	i.Logger.Info("Active node validation result",
+		zap.String("node", result.NodeName),
+		zap.String("cni", result.CNI),
+		zap.Bool("ready", result.Ready),
+		zap.Duration("duration", result.Duration),
+	)
+	if err != nil && i.WaitForSystemPods {
+		return fmt.Errorf("waiting for system pods: %w", err)
+	} else if err != nil {
+		i.Logger.Warn("Failed to emit all node join events", zap.Error(err))
+	} else if i.WaitForSystemPods {
+		i.Logger.Info("System DaemonSet pods are running on the node", zap.String("node", result.NodeName))
+	}
+	return nil
+}
+
+// labelNode labels the node with the nodeadm version, OS, architecture and credential provider
+// so the fleet can be queried by them. Failures are logged and never fail init.
+func (i *Initer) labelNode(ctx context.Context) {
+	i.Logger.Info("Labeling node with nodeadm details...")
+	client, err := kubelet.New().BuildClient()
+	if err != nil {
+		i.Logger.Warn("Failed to label node, building Kubernetes client", zap.Error(err))
+		return
+	}
+	nodeName, err := nodevalidator.NewNodeRegistrationChecker(client, nodeLabelsRegistrationTimeout, i.Logger).WaitForNodeRegistration(ctx)
+	if err != nil {
+		i.Logger.Warn("Failed to label node", zap.Error(err))
+		return
+	}
+	labels := node.JoinLabels(i.NodeProvider.GetNodeConfig(), version.GitVersion, i.OSInfo.ID, runtime.GOARCH)
+	if err := node.LabelNode(ctx, client, nodeName, labels); err != nil {
+		i.Logger.Warn("Failed to label node", zap.Error(err))
+		return
+	}
+	i.Logger.Info("Labeled node", zap.String("node", nodeName), zap.Any("labels", labels))
+}
+
+func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, skipPhases []string, externalRuntime bool, logger *zap.Logger) error {
 	if !slices.Contains(skipPhases, preprocessPhase) {
 		logger.Info("Configuring Pre-process daemons...")
 		if err := nodeProvider.PreProcessDaemon(ctx); err != nil {
 			return err
 		}
+		cli.PhaseRun(ctx, preprocessPhase)
 	}
 
 	daemons, err := nodeProvider.GetDaemons()
@@ -104,6 +225,7 @@ func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, sk
 			}
 			logger.Info("Configured daemon", nameField)
 		}
+		cli.PhaseRun(ctx, configPhase)
 	}
 
 	if !slices.Contains(skipPhases, runPhase) {
@@ -116,12 +238,69 @@ func initDaemons(ctx context.Context, nodeProvider nodeprovider.NodeProvider, sk
 			}
 			logger.Info("Daemon is running", nameField)
 
+			if daemon.Name() == containerd.ContainerdDaemonName && externalRuntime && !slices.Contains(skipPhases, containerRuntimePhase) {
+				if err := validateExternalRuntime(ctx, logger); err != nil {
+					return err
+				}
+				cli.PhaseRun(ctx, containerRuntimePhase)
+			}
+
 			logger.Info("Running post-launch tasks...", nameField)
 			if err := daemon.PostLaunch(); err != nil {
 				return err
 			}
 			logger.Info("Finished post-launch tasks", nameField)
 		}
+		cli.PhaseRun(ctx, runPhase)
+	}
+	return nil
+}
+
+// validateExternalRuntime checks the runtime serving the CRI endpoint is healthy and reports its version.
+func validateExternalRuntime(ctx context.Context, logger *zap.Logger) error {
+	logger.Info("Validating container runtime not managed by nodeadm...")
+	ctx, cancel := context.WithTimeout(ctx, containerRuntimeValidationTimeout)
+	defer cancel()
+	version, err := containerd.ValidateCRIRuntime(ctx, containerd.ContainerRuntimeEndpoint)
+	if err != nil {
+		return fmt.Errorf("validating container runtime, it can be bypassed with --skip %s: %w", containerRuntimePhase, err)
+	}
+	logger.Info("Container runtime is healthy",
+		zap.String("runtime", version.Name),
+		zap.String("version", version.Version),
+		zap.String("criVersion", version.APIVersion))
+	return nil
+}
+
+// validateImagePull pulls image with the credentials from the image credential provider
+// kubelet uses, so credential problems surface at init instead of on the first pod.
+func validateImagePull(ctx context.Context, endpoint, image string, logger *zap.Logger) error {
+	if image == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, imagePullValidationTimeout)
+	defer cancel()
+
+	if !ecr.IsECRImage(image) {
+		// The image credential provider only serves ECR, like kubelet the runtime pulls other
+		// registries with its own registry config.
+		logger.Info("Validating image pull with the container runtime registry config...", zap.String("image", image))
+		if err := containerd.ImagePullSmokeTest(ctx, endpoint, image, nil, logger); err != nil {
+			return fmt.Errorf("validating image pull, it can be bypassed with --skip %s: %w", imagePullPhase, err)
+		}
+		return nil
+	}
+
+	logger.Info("Validating image pull with the image credential provider...", zap.String("image", image))
+
+	credentials, err := kubelet.GetImageCredentials(ctx, image)
+	if err != nil {
+		return fmt.Errorf("validating image pull, it can be bypassed with --skip %s: %w", imagePullPhase, err)
+	}
+	auth := &v1.AuthConfig{Username: credentials.Username, Password: credentials.Password}
+	if err := containerd.ImagePullSmokeTest(ctx, endpoint, image, auth, logger); err != nil {
+		return fmt.Errorf("validating image pull, it can be bypassed with --skip %s: %w", imagePullPhase, err)
 	}
+	logger.Info("Image pull with the image credential provider succeeded", zap.String("provider", credentials.Provider))
 	return nil
 }
@@ -3,13 +3,16 @@ package flows
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/cni"
 	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
+	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/iamauthenticator"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/imagecredentialprovider"
@@ -21,23 +24,47 @@ import (
 	"github.com/aws/eks-hybrid/internal/tracker"
 )
 
+const (
+	sandboxImagePullTimeout = 2 * time.Minute
+	// eksArtifactInstallConcurrency is the maximum number of EKS artifacts installed at the same time.
+	eksArtifactInstallConcurrency = 3
+)
+
 type Installer struct {
 	AwsSource          aws.Source
 	ContainerdSource   tracker.ContainerdSourceName
 	PackageManager     *packagemanager.DistroPackageManager
 	CredentialProvider creds.CredentialProvider
+	DaemonManager      daemon.DaemonManager
 	SsmRegion          string
-	Tracker            *tracker.Tracker
-	Logger             *zap.Logger
-	PrivateMode        bool
+	// SsmPinnedFingerprints and SsmPinnedKeyring pin the keys allowed to sign the SSM installer.
+	SsmPinnedFingerprints []string
+	SsmPinnedKeyring      string
+	Tracker               *tracker.Tracker
+	Logger                *zap.Logger
+	PrivateMode           bool
+	// SandboxImage is pulled through the container runtime once installed when set,
+	// so a sandbox image kubelet can't fetch fails install instead of the first pod.
+	// It's recorded in the tracker for init to configure containerd and kubelet with it.
+	SandboxImage string
+
+	// installRoot is the root directory the EKS artifacts are installed under, / when empty.
+	installRoot string
 }
 
 func (i *Installer) Run(ctx context.Context) error {
+	if i.SandboxImage != "" {
+		if err := containerd.ValidateImageReference(i.SandboxImage); err != nil {
+			return err
+		}
+	}
+
 	var err error
 	i.Tracker, err = tracker.GetCurrentState()
 	if err != nil {
 		return err
 	}
+	i.Tracker.Artifacts.SandboxImage = i.SandboxImage
 
 	if i.PrivateMode {
 		i.Logger.Info("Private mode: Skipping OS package installation")
@@ -53,7 +80,10 @@ func (i *Installer) Run(ctx context.Context) error {
 		}
 
 		i.Logger.Info("Private mode install completed")
-		return i.Tracker.Save()
+		if err := i.Tracker.Save(); err != nil {
+			return err
+		}
+		return i.prePullSandboxImage(ctx)
 	}
 
 	// Normal installation flow
@@ -77,7 +107,43 @@ func (i *Installer) Run(ctx context.Context) error {
 	}
 
 	i.Logger.Info("Finishing up install...")
-	return i.Tracker.Save()
+	if err := i.Tracker.Save(); err != nil {
+		return err
+	}
+	return i.prePullSandboxImage(ctx)
+}
+
+// prePullSandboxImage pulls the sandbox image through the container runtime, starting
+// containerd first if nodeadm manages it and it isn't running yet.
+func (i *Installer) prePullSandboxImage(ctx context.Context) error {
+	if i.SandboxImage == "" {
+		return nil
+	}
+
+	if i.ContainerdSource != tracker.ContainerdSourceNone {
+		if i.DaemonManager == nil {
+			i.Logger.Warn("Skipping sandbox image pre-pull, containerd status can't be checked", zap.String("image", i.SandboxImage))
+			return nil
+		}
+		status, err := i.DaemonManager.GetDaemonStatus(containerd.ContainerdDaemonName)
+		if err != nil {
+			return err
+		}
+		if status != daemon.DaemonStatusRunning {
+			i.Logger.Info("Starting containerd to pre-pull the sandbox image...")
+			if err := i.DaemonManager.StartDaemon(containerd.ContainerdDaemonName); err != nil {
+				return err
+			}
+			if err := daemon.WaitForStatus(ctx, i.Logger, i.DaemonManager, containerd.ContainerdDaemonName, daemon.DaemonStatusRunning, time.Second); err != nil {
+				return err
+			}
+		}
+	}
+
+	i.Logger.Info("Pre-pulling sandbox image...", zap.String("image", i.SandboxImage))
+	ctx, cancel := context.WithTimeout(ctx, sandboxImagePullTimeout)
+	defer cancel()
+	return containerd.PrePullSandboxImage(ctx, containerd.ContainerRuntimeEndpoint, i.SandboxImage, i.Logger)
 }
 
 func (i *Installer) installDistroPackages(ctx context.Context) error {
@@ -112,14 +178,19 @@ func (i *Installer) installCredentialProcess(ctx context.Context) error {
 			i.Logger,
 			i.SsmRegion,
 			ssm.WithDnsSuffix(i.AwsSource.RegionInfo.DnsSuffix),
+			ssm.WithInstallerDir(i.AwsSource.ArtifactDir()),
 		)
 
 		i.Logger.Info("Installing SSM agent installer...")
 		if err := ssm.Install(ctx, ssm.InstallOptions{
-			Tracker: i.Tracker,
-			Source:  ssmInstaller,
-			Logger:  i.Logger,
-			Region:  i.SsmRegion,
+			Tracker:       i.Tracker,
+			Source:        ssmInstaller,
+			Logger:        i.Logger,
+			Region:        i.SsmRegion,
+			DaemonManager: i.DaemonManager,
+
+			PinnedFingerprints: i.SsmPinnedFingerprints,
+			PinnedKeyring:      i.SsmPinnedKeyring,
 		}); err != nil {
 			return err
 		}
@@ -129,47 +200,80 @@ func (i *Installer) installCredentialProcess(ctx context.Context) error {
 	return nil
 }
 
+// installEksArtifacts downloads and installs the EKS artifacts concurrently, they are independent
+// binaries unlike the distro packages, which the package manager installs one at a time.
 func (i *Installer) installEksArtifacts(ctx context.Context) error {
-	i.Logger.Info("Installing kubelet...")
-	if err := kubelet.Install(ctx, kubelet.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
+	installs := []struct {
+		name    string
+		install func(context.Context) error
+	}{
+		{
+			name: "kubelet",
+			install: func(ctx context.Context) error {
+				return kubelet.Install(ctx, kubelet.InstallOptions{
+					InstallRoot:     i.installRoot,
+					Tracker:         i.Tracker,
+					Source:          i.AwsSource,
+					Logger:          i.Logger,
+					ExpectedVersion: i.AwsSource.Eks.Version,
+				})
+			},
+		},
+		{
+			name: "kubectl",
+			install: func(ctx context.Context) error {
+				return kubectl.Install(ctx, kubectl.InstallOptions{
+					InstallRoot: i.installRoot,
+					Tracker:     i.Tracker,
+					Source:      i.AwsSource,
+					Logger:      i.Logger,
+				})
+			},
+		},
+		{
+			name: "cni-plugins",
+			install: func(ctx context.Context) error {
+				return cni.Install(ctx, cni.InstallOptions{
+					InstallRoot: i.installRoot,
+					Tracker:     i.Tracker,
+					Source:      i.AwsSource,
+					Logger:      i.Logger,
+				})
+			},
+		},
+		{
+			name: "image credential provider",
+			install: func(ctx context.Context) error {
+				return imagecredentialprovider.Install(ctx, imagecredentialprovider.InstallOptions{
+					InstallRoot: i.installRoot,
+					Tracker:     i.Tracker,
+					Source:      i.AwsSource,
+					Logger:      i.Logger,
+				})
+			},
+		},
+		{
+			name: "AWS IAM authenticator",
+			install: func(ctx context.Context) error {
+				return iamauthenticator.Install(ctx, iamauthenticator.InstallOptions{
+					InstallRoot: i.installRoot,
+					Tracker:     i.Tracker,
+					Source:      i.AwsSource,
+					Logger:      i.Logger,
+				})
+			},
+		},
 	}
 
-	i.Logger.Info("Installing kubectl...")
-	if err := kubectl.Install(ctx, kubectl.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
-	}
-
-	i.Logger.Info("Installing cni-plugins...")
-	if err := cni.Install(ctx, cni.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
+	// The first failure cancels the other installs and is returned, each install error
+	// already names its artifact.
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(eksArtifactInstallConcurrency)
+	for _, artifact := range installs {
+		group.Go(func() error {
+			i.Logger.Info(fmt.Sprintf("Installing %s...", artifact.name))
+			return artifact.install(ctx)
+		})
 	}
-
-	i.Logger.Info("Installing image credential provider...")
-	if err := imagecredentialprovider.Install(ctx, imagecredentialprovider.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	}); err != nil {
-		return err
-	}
-
-	i.Logger.Info("Installing AWS IAM authenticator...")
-	return iamauthenticator.Install(ctx, iamauthenticator.InstallOptions{
-		Tracker: i.Tracker,
-		Source:  i.AwsSource,
-		Logger:  i.Logger,
-	})
+	return group.Wait()
 }
@@ -0,0 +1,128 @@
+package flows
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/aws"
+	"github.com/aws/eks-hybrid/internal/cni"
+	"github.com/aws/eks-hybrid/internal/iamauthenticator"
+	"github.com/aws/eks-hybrid/internal/imagecredentialprovider"
+	"github.com/aws/eks-hybrid/internal/kubectl"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+func cniPluginsArchive(g *GomegaWithT) []byte {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	g.Expect(tw.WriteHeader(&tar.Header{Name: "fake-plugin", Mode: 0o755})).To(Succeed())
+	g.Expect(tw.Close()).To(Succeed())
+	g.Expect(gw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+// eksArtifactsSource serves the EKS artifacts from a test server, failing the downloads of failArtifact.
+func eksArtifactsSource(t *testing.T, g *GomegaWithT, failArtifact string) aws.Source {
+	artifacts := map[string][]byte{
+		"kubelet":                 []byte("kubelet"),
+		"kubectl":                 []byte("kubectl"),
+		"cni-plugins":             cniPluginsArchive(g),
+		"ecr-credential-provider": []byte("ecr-credential-provider"),
+		"aws-iam-authenticator":   []byte("aws-iam-authenticator"),
+	}
+	server := test.NewHTTPServer(t, func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(filepath.Base(r.URL.Path), ".sha256")
+		data, ok := artifacts[name]
+		if !ok || name == failArtifact {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ".sha256") {
+			data = []byte(fmt.Sprintf("%x %s", sha256.Sum256(data), name))
+		}
+		_, _ = w.Write(data)
+	})
+
+	source := aws.Source{}
+	for name := range artifacts {
+		source.Eks.Artifacts = append(source.Eks.Artifacts, aws.Artifact{
+			Arch:        runtime.GOARCH,
+			OS:          runtime.GOOS,
+			Name:        name,
+			URI:         server.URL + "/" + name,
+			ChecksumURI: server.URL + "/" + name + ".sha256",
+		})
+	}
+	return source
+}
+
+func TestInstallerInstallEksArtifacts(t *testing.T) {
+	g := NewWithT(t)
+	installer := &Installer{
+		AwsSource:   eksArtifactsSource(t, g, ""),
+		Tracker:     &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+		Logger:      zap.NewNop(),
+		installRoot: t.TempDir(),
+	}
+
+	g.Expect(installer.installEksArtifacts(context.Background())).To(Succeed())
+	g.Expect(*installer.Tracker.Artifacts).To(Equal(tracker.InstalledArtifacts{
+		Kubelet:                 true,
+		Kubectl:                 true,
+		CniPlugins:              true,
+		ImageCredentialProvider: true,
+		IamAuthenticator:        true,
+	}))
+	for _, path := range []string{
+		kubelet.BinPath,
+		kubelet.UnitPath,
+		kubectl.BinPath,
+		filepath.Join(cni.BinPath, "fake-plugin"),
+		imagecredentialprovider.BinPath,
+		iamauthenticator.IAMAuthenticatorBinPath,
+	} {
+		g.Expect(filepath.Join(installer.installRoot, path)).To(BeAnExistingFile())
+	}
+}
+
+func TestInstallerInstallEksArtifactsFailure(t *testing.T) {
+	g := NewWithT(t)
+	installer := &Installer{
+		AwsSource:   eksArtifactsSource(t, g, "cni-plugins"),
+		Tracker:     &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+		Logger:      zap.NewNop(),
+		installRoot: t.TempDir(),
+	}
+
+	err := installer.installEksArtifacts(context.Background())
+	g.Expect(err).To(MatchError(ContainSubstring("installing cni-plugins")))
+	g.Expect(err).To(MatchError(ContainSubstring("unexpected status code: 500")))
+	g.Expect(installer.Tracker.Artifacts.CniPlugins).To(BeFalse())
+}
+
+func TestInstallerRunInvalidSandboxImage(t *testing.T) {
+	g := NewWithT(t)
+	installer := &Installer{
+		SandboxImage: "registry.example.com/eks/Pause:3.10",
+		Logger:       zap.NewNop(),
+	}
+
+	err := installer.Run(context.Background())
+	g.Expect(err).To(MatchError(ContainSubstring(`invalid image reference "registry.example.com/eks/Pause:3.10"`)))
+	g.Expect(installer.Tracker).To(BeNil(), "the tracker shouldn't be read before the sandbox image is validated")
+}
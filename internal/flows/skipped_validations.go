@@ -0,0 +1,36 @@
+package flows
+
+import (
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+const validationPhaseSuffix = "-validation"
+
+// SkippedValidations returns the validations in the skipped phases, sorted and without duplicates.
+func SkippedValidations(skipPhases []string) []string {
+	var validations []string
+	for _, phase := range skipPhases {
+		if strings.HasSuffix(phase, validationPhaseSuffix) {
+			validations = append(validations, phase)
+		}
+	}
+	slices.Sort(validations)
+	return slices.Compact(validations)
+}
+
+// recordSkippedValidations saves the validations skipped by command in the tracker so
+// nodeadm debug can report the node was set up with reduced checks. Failures are only logged.
+func recordSkippedValidations(command string, skipPhases []string, logger *zap.Logger) {
+	validations := SkippedValidations(skipPhases)
+	if len(validations) > 0 {
+		logger.Warn("Validations were skipped, the node was set up with reduced checks", zap.Strings("validations", validations))
+	}
+	if err := tracker.RecordSkippedValidations(command, validations); err != nil {
+		logger.Warn("Failed to record skipped validations", zap.Error(err))
+	}
+}
@@ -0,0 +1,15 @@
+package flows
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSkippedValidations(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(SkippedValidations([]string{"swap-validation", "run", "node-ip-validation", "swap-validation", "preprocess"})).
+		To(Equal([]string{"node-ip-validation", "swap-validation"}))
+	g.Expect(SkippedValidations(nil)).To(BeEmpty())
+}
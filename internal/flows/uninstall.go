@@ -2,8 +2,8 @@ package flows
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
@@ -21,13 +21,25 @@ import (
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/packagemanager"
 	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/util"
 )
 
 const eksConfigDir = "/etc/eks"
 
 type (
 	CNIUninstall func() error
+	// NodeDecommission removes the node from the cluster once the kubelet is stopped.
+	NodeDecommission func(ctx context.Context) error
+	// NodeDrain evicts the pods running on the node through the Kubernetes API.
+	NodeDrain func(ctx context.Context) error
+	// PodRemoval removes the pods left in the container runtime once the kubelet is stopped
+	// and returns the pods removed.
+	PodRemoval func(ctx context.Context) ([]string, error)
+	// ImagePrune removes the images from the container runtime before it is stopped and
+	// returns the images removed.
+	ImagePrune func(ctx context.Context) ([]string, error)
 )
 
 type Uninstaller struct {
@@ -36,125 +48,274 @@ type Uninstaller struct {
 	PackageManager *packagemanager.DistroPackageManager
 	Logger         *zap.Logger
 	CNIUninstall   CNIUninstall
+	// Decommission is optional.
+	Decommission NodeDecommission
+	// DecommissionTargets name what Decommission removes from the cluster, like node/<name>,
+	// to record them in the summary.
+	DecommissionTargets []string
+	// DryRun logs what would be removed without stopping daemons or removing anything.
+	DryRun bool
+	// Unmount unmounts file systems mounted under the directories removed during cleanup.
+	// Without it, those directories aren't removed and the uninstall fails.
+	Unmount bool
+	// KeepCNI leaves the cni-plugins binaries in place, to avoid pod network churn when the node
+	// is provisioned again. They are still removed from the tracker, like every other artifact.
+	KeepCNI bool
+	// Drain is optional. It runs before the kubelet is stopped, so the evicted pods terminate
+	// gracefully and are rescheduled before RemovePods force-removes what is left.
+	Drain NodeDrain
+	// RemovePods is optional.
+	RemovePods PodRemoval
+	// PruneImages is optional. It runs after RemovePods, so the images of the removed pods are no longer in use.
+	PruneImages ImagePrune
+
+	summary UninstallSummary
 }
 
-func (u *Uninstaller) Run(ctx context.Context) error {
-	if err := u.uninstallDaemons(ctx); err != nil {
+// UninstallSummary lists what an uninstall removed, or would remove in dry-run mode. Actions
+// are only recorded once they succeed, so a failed uninstall lists what was done before it failed.
+type UninstallSummary struct {
+	DryRun bool `json:"dryRun"`
+	// Daemons are the systemd units stopped.
+	Daemons []string `json:"daemons"`
+	// Components are the artifacts from the tracker that are uninstalled.
+	Components []string `json:"components"`
+	// Paths are the directories removed that aren't owned by a single component.
+	Paths []string `json:"paths"`
+	// Deregistrations are the registrations of the node removed from SSM and the cluster.
+	Deregistrations []string `json:"deregistrations"`
+	// Pods are the pods removed from the container runtime, in namespace/name form.
+	Pods []string `json:"pods,omitempty"`
+	// Images are the images pruned from the container runtime.
+	Images []string `json:"images,omitempty"`
+}
+
+// WriteManifest writes the summary as JSON to path, for auditing what the uninstall removed.
+func (s UninstallSummary) WriteManifest(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
 		return err
 	}
+	if err := util.WriteFileWithDir(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing uninstall manifest: %w", err)
+	}
+	return nil
+}
+
+// Run uninstalls the tracked artifacts and returns a summary of what was removed.
+func (u *Uninstaller) Run(ctx context.Context) (UninstallSummary, error) {
+	u.summary = UninstallSummary{
+		DryRun:          u.DryRun,
+		Daemons:         []string{},
+		Components:      []string{},
+		Paths:           []string{},
+		Deregistrations: []string{},
+	}
+	if err := u.uninstallDaemons(ctx); err != nil {
+		return u.summary, err
+	}
 
 	if err := u.uninstallBinaries(ctx); err != nil {
-		return err
+		return u.summary, err
 	}
 
 	if err := u.cleanup(); err != nil {
-		return err
+		return u.summary, err
+	}
+
+	if u.DryRun {
+		u.Logger.Info("Dry run finished, nothing was removed",
+			zap.Strings("daemons", u.summary.Daemons),
+			zap.Strings("components", u.summary.Components),
+			zap.Strings("paths", u.summary.Paths),
+		)
+		return u.summary, nil
 	}
 
 	u.Logger.Info("Finished uninstallation tasks...")
 
-	return tracker.Clear()
+	if err := tracker.Clear(u.Unmount); err != nil {
+		return u.summary, err
+	}
+	u.summary.Paths = append(u.summary.Paths, tracker.Dir())
+	return u.summary, nil
+}
+
+// stopDaemon stops the daemon and records it in the summary. In dry-run mode, the daemon is
+// only logged and recorded.
+func (u *Uninstaller) stopDaemon(name string) error {
+	if u.DryRun {
+		u.Logger.Info("Would stop daemon", zap.String("daemon", name))
+	} else if err := u.DaemonManager.StopDaemon(name); err != nil {
+		return err
+	}
+	u.summary.Daemons = append(u.summary.Daemons, name)
+	return nil
+}
+
+// uninstall logs message, removes the component and records it in the summary once removed.
+// In dry-run mode, the component is only logged and recorded.
+func (u *Uninstaller) uninstall(component, message string, remove func() error) error {
+	if u.DryRun {
+		u.Logger.Info("Would uninstall component", zap.String("component", component))
+	} else {
+		u.Logger.Info(message)
+		if err := remove(); err != nil {
+			return err
+		}
+	}
+	u.summary.Components = append(u.summary.Components, component)
+	return nil
 }
 
 func (u *Uninstaller) uninstallDaemons(ctx context.Context) error {
+	if u.Drain != nil {
+		if u.DryRun {
+			u.Logger.Info("Would cordon and drain the node")
+		} else if err := u.Drain(ctx); err != nil {
+			return fmt.Errorf("draining node: %w", err)
+		}
+	}
 	if u.Artifacts.Kubelet {
-		u.Logger.Info("Uninstalling kubelet...")
-		if err := u.DaemonManager.StopDaemon(kubelet.KubeletDaemonName); err != nil {
+		if err := u.stopDaemon(kubelet.KubeletDaemonName); err != nil {
 			return err
 		}
-		if err := kubelet.Uninstall(kubelet.UninstallOptions{}); err != nil {
+		if err := u.uninstall("kubelet", "Uninstalling kubelet...", func() error {
+			return kubelet.Uninstall(kubelet.UninstallOptions{})
+		}); err != nil {
 			return err
 		}
 	}
-	if u.Artifacts.Ssm {
-		u.Logger.Info("Stopping SSM daemon...")
-		if err := u.DaemonManager.StopDaemon(ssm.SsmDaemonName); err != nil {
-			return err
+	if u.RemovePods != nil {
+		if u.DryRun {
+			u.Logger.Info("Would remove the Kubernetes pods from the container runtime")
+		} else {
+			pods, err := u.RemovePods(ctx)
+			u.summary.Pods = append(u.summary.Pods, pods...)
+			if err != nil {
+				return fmt.Errorf("removing pods: %w", err)
+			}
 		}
-
-		ssmRegistration := ssm.NewSSMRegistration()
-		region := ssmRegistration.GetRegion()
-		opts := []func(*config.LoadOptions) error{}
-		if region != "" {
-			opts = append(opts, config.WithRegion(region))
+	}
+	if u.PruneImages != nil {
+		if u.DryRun {
+			u.Logger.Info("Would prune the images from the container runtime")
+		} else {
+			images, err := u.PruneImages(ctx)
+			u.summary.Images = append(u.summary.Images, images...)
+			if err != nil {
+				return fmt.Errorf("pruning images: %w", err)
+			}
 		}
-
-		awsConfig, err := config.LoadDefaultConfig(ctx, opts...)
-		if err != nil {
+	}
+	if u.Decommission != nil {
+		if u.DryRun {
+			u.Logger.Info("Would decommission the node from the cluster")
+		} else if err := u.Decommission(ctx); err != nil {
+			return fmt.Errorf("decommissioning node: %w", err)
+		}
+		u.summary.Deregistrations = append(u.summary.Deregistrations, u.DecommissionTargets...)
+	}
+	if u.Artifacts.Ssm {
+		if !u.DryRun {
+			u.Logger.Info("Stopping SSM daemon...")
+		}
+		if err := u.stopDaemon(ssm.SsmDaemonName); err != nil {
 			return err
 		}
-
-		ssmClient := awsSsm.NewFromConfig(awsConfig, func(o *awsSsm.Options) {
-			// intentionally long max backoff and number of retry attempts as we want to optimize for success
-			// vs flaky fails during deregistering due to connection reset (and the like) errors from the ssm endpoint
-			// we would rather longer run time than flaky failures
-			o.Retryer = retry.AddWithMaxAttempts(o.Retryer, 12)
-			o.Retryer = retry.AddWithMaxBackoffDelay(o.Retryer, 1*time.Minute)
-		})
-		if err := ssm.Uninstall(ctx, ssm.UninstallOptions{
-			Logger:          u.Logger,
-			SSMRegistration: ssmRegistration,
-			PkgSource:       u.PackageManager,
-			SSMClient:       ssmClient,
+		if err := u.uninstall("ssm", "Uninstalling SSM...", func() error {
+			return u.uninstallSSM(ctx)
 		}); err != nil {
-			return fmt.Errorf("uninstalling SSM: %w", err)
+			return err
 		}
 	}
 	if u.Artifacts.IamRolesAnywhere {
-		u.Logger.Info("Removing aws_signing_helper_update daemon...")
-		if status, err := u.DaemonManager.GetDaemonStatus(iamrolesanywhere.DaemonName); err == nil || status != daemon.DaemonStatusUnknown {
-			if err = u.DaemonManager.StopDaemon(iamrolesanywhere.DaemonName); err != nil {
-				u.Logger.Info("Stopping aws_signing_helper_update daemon...")
-				return err
+		if u.DryRun {
+			u.summary.Daemons = append(u.summary.Daemons, iamrolesanywhere.DaemonName)
+			u.Logger.Info("Would stop daemon", zap.String("daemon", iamrolesanywhere.DaemonName))
+		} else {
+			u.Logger.Info("Removing aws_signing_helper_update daemon...")
+			if status, err := u.DaemonManager.GetDaemonStatus(iamrolesanywhere.DaemonName); err == nil || status != daemon.DaemonStatusUnknown {
+				if err = u.stopDaemon(iamrolesanywhere.DaemonName); err != nil {
+					u.Logger.Info("Stopping aws_signing_helper_update daemon...")
+					return err
+				}
 			}
 		}
 	}
 	if u.Artifacts.Containerd != tracker.ContainerdSourceNone {
-		u.Logger.Info("Uninstalling containerd...")
-		if err := u.DaemonManager.StopDaemon(containerd.ContainerdDaemonName); err != nil {
+		if err := u.stopDaemon(containerd.ContainerdDaemonName); err != nil {
 			return err
 		}
-		if err := containerd.Uninstall(ctx, u.PackageManager); err != nil {
+		if err := u.uninstall("containerd", "Uninstalling containerd...", func() error {
+			return containerd.Uninstall(ctx, u.PackageManager)
+		}); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (u *Uninstaller) uninstallBinaries(ctx context.Context) error {
-	if u.Artifacts.Kubectl {
-		u.Logger.Info("Uninstalling kubectl...")
-		if err := kubectl.Uninstall(); err != nil {
-			return err
-		}
+func (u *Uninstaller) uninstallSSM(ctx context.Context) error {
+	ssmRegistration := ssm.NewSSMRegistration()
+	// The registration file is removed by the uninstall, the instance id is read first for the summary.
+	instanceID, _ := ssmRegistration.GetManagedHybridInstanceId()
+	region := ssmRegistration.GetRegion()
+	opts := []func(*config.LoadOptions) error{}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
 	}
-	if u.Artifacts.CniPlugins {
-		u.Logger.Info("Uninstalling cni-plugins...")
-		if err := u.CNIUninstall(); err != nil {
-			return err
-		}
+
+	awsConfig, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return err
 	}
-	if u.Artifacts.IamAuthenticator {
-		u.Logger.Info("Uninstalling IAM authenticator...")
-		if err := iamauthenticator.Uninstall(); err != nil {
-			return err
-		}
+
+	ssmClient := awsSsm.NewFromConfig(awsConfig, func(o *awsSsm.Options) {
+		// intentionally long max backoff and number of retry attempts as we want to optimize for success
+		// vs flaky fails during deregistering due to connection reset (and the like) errors from the ssm endpoint
+		// we would rather longer run time than flaky failures
+		o.Retryer = retry.AddWithMaxAttempts(o.Retryer, 12)
+		o.Retryer = retry.AddWithMaxBackoffDelay(o.Retryer, 1*time.Minute)
+	})
+	if err := ssm.Uninstall(ctx, ssm.UninstallOptions{
+		Logger:          u.Logger,
+		SSMRegistration: ssmRegistration,
+		PkgSource:       u.PackageManager,
+		SSMClient:       ssmClient,
+	}); err != nil {
+		return fmt.Errorf("uninstalling SSM: %w", err)
 	}
-	if u.Artifacts.IamRolesAnywhere {
-		u.Logger.Info("Uninstalling AWS signing helper...")
-		if err := iamrolesanywhere.Uninstall(); err != nil {
-			return err
-		}
+	if instanceID != "" {
+		u.summary.Deregistrations = append(u.summary.Deregistrations, "ssm-managed-instance/"+instanceID)
 	}
-	if u.Artifacts.ImageCredentialProvider {
-		u.Logger.Info("Uninstalling image credential provider...")
-		if err := imagecredentialprovider.Uninstall(); err != nil {
-			return err
-		}
+	return nil
+}
+
+func (u *Uninstaller) uninstallBinaries(ctx context.Context) error {
+	binaries := []struct {
+		installed bool
+		component string
+		message   string
+		remove    func() error
+	}{
+		{u.Artifacts.Kubectl, "kubectl", "Uninstalling kubectl...", kubectl.Uninstall},
+		{u.Artifacts.CniPlugins && !u.KeepCNI, "cni-plugins", "Uninstalling cni-plugins...", u.CNIUninstall},
+		{u.Artifacts.IamAuthenticator, "iam-authenticator", "Uninstalling IAM authenticator...", iamauthenticator.Uninstall},
+		{u.Artifacts.IamRolesAnywhere, "aws-signing-helper", "Uninstalling AWS signing helper...", iamrolesanywhere.Uninstall},
+		{u.Artifacts.ImageCredentialProvider, "image-credential-provider", "Uninstalling image credential provider...", imagecredentialprovider.Uninstall},
+		{u.Artifacts.Iptables, "iptables", "Uninstalling iptables...", func() error {
+			return iptables.Uninstall(ctx, u.PackageManager)
+		}},
 	}
-	if u.Artifacts.Iptables {
-		u.Logger.Info("Uninstalling iptables...")
-		if err := iptables.Uninstall(ctx, u.PackageManager); err != nil {
+	if u.Artifacts.CniPlugins && u.KeepCNI {
+		u.Logger.Info("Keeping cni-plugins")
+	}
+	for _, binary := range binaries {
+		if !binary.installed {
+			continue
+		}
+		if err := u.uninstall(binary.component, binary.message, binary.remove); err != nil {
 			return err
 		}
 	}
@@ -163,13 +324,20 @@ func (u *Uninstaller) uninstallBinaries(ctx context.Context) error {
 
 // cleanup removes directories or files that are not individually owned by single component
 func (u *Uninstaller) cleanup() error {
+	if u.DryRun {
+		u.summary.Paths = append(u.summary.Paths, eksConfigDir, tracker.Dir())
+		u.Logger.Info("Would remove directories", zap.Strings("paths", u.summary.Paths))
+		return nil
+	}
+
 	if err := u.PackageManager.Cleanup(); err != nil {
 		return err
 	}
 
-	if err := os.RemoveAll(eksConfigDir); err != nil {
+	if err := system.SafeRemoveAll(eksConfigDir, u.Unmount, false); err != nil {
 		return err
 	}
+	u.summary.Paths = append(u.summary.Paths, eksConfigDir)
 
 	return nil
 }
@@ -0,0 +1,384 @@
+package flows
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testingk8s "k8s.io/client-go/testing"
+
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/node"
+	"github.com/aws/eks-hybrid/internal/ssm"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+// noopDaemonManager panics on any call, so dry runs can't touch the daemons.
+type noopDaemonManager struct {
+	daemon.DaemonManager
+}
+
+// fakeDaemonManager reports all daemons as running and fails to stop stopErrs.
+type fakeDaemonManager struct {
+	daemon.DaemonManager
+	stopErrs map[string]error
+	stopped  []string
+}
+
+func (f *fakeDaemonManager) GetDaemonStatus(string) (daemon.DaemonStatus, error) {
+	return daemon.DaemonStatusRunning, nil
+}
+
+func (f *fakeDaemonManager) StopDaemon(name string) error {
+	if err := f.stopErrs[name]; err != nil {
+		return err
+	}
+	f.stopped = append(f.stopped, name)
+	return nil
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestUninstallerDryRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		artifacts      tracker.InstalledArtifacts
+		wantDaemons    []string
+		wantComponents []string
+	}{
+		{
+			name: "all artifacts",
+			artifacts: tracker.InstalledArtifacts{
+				Containerd:              tracker.ContainerdSourceDistro,
+				CniPlugins:              true,
+				IamAuthenticator:        true,
+				IamRolesAnywhere:        true,
+				ImageCredentialProvider: true,
+				Kubectl:                 true,
+				Kubelet:                 true,
+				Ssm:                     true,
+				Iptables:                true,
+			},
+			wantDaemons: []string{
+				kubelet.KubeletDaemonName,
+				ssm.SsmDaemonName,
+				iamrolesanywhere.DaemonName,
+				containerd.ContainerdDaemonName,
+			},
+			wantComponents: []string{
+				"kubelet", "ssm", "containerd", "kubectl", "cni-plugins", "iam-authenticator",
+				"aws-signing-helper", "image-credential-provider", "iptables",
+			},
+		},
+		{
+			name: "kubelet and kubectl",
+			artifacts: tracker.InstalledArtifacts{
+				Containerd: tracker.ContainerdSourceNone,
+				Kubelet:    true,
+				Kubectl:    true,
+			},
+			wantDaemons:    []string{kubelet.KubeletDaemonName},
+			wantComponents: []string{"kubelet", "kubectl"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			eksConfigDirExists, trackerDirExists := pathExists(eksConfigDir), pathExists(tracker.Dir())
+			kubeletBinExists := pathExists(kubelet.BinPath)
+
+			uninstaller := &Uninstaller{
+				Artifacts:     &tc.artifacts,
+				DaemonManager: noopDaemonManager{},
+				Logger:        zap.NewNop(),
+				CNIUninstall: func() error {
+					t.Fatal("cni-plugins must not be uninstalled in dry-run mode")
+					return nil
+				},
+				Decommission: func(context.Context) error {
+					t.Fatal("the node must not be decommissioned in dry-run mode")
+					return nil
+				},
+				DecommissionTargets: []string{"node/mock-hybrid-node"},
+				RemovePods: func(context.Context) ([]string, error) {
+					t.Fatal("pods must not be removed in dry-run mode")
+					return nil, nil
+				},
+				DryRun: true,
+			}
+
+			summary, err := uninstaller.Run(context.Background())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(summary.DryRun).To(BeTrue())
+			g.Expect(summary.Daemons).To(Equal(tc.wantDaemons))
+			g.Expect(summary.Components).To(Equal(tc.wantComponents))
+			g.Expect(summary.Paths).To(Equal([]string{eksConfigDir, tracker.Dir()}))
+			g.Expect(summary.Deregistrations).To(Equal([]string{"node/mock-hybrid-node"}))
+
+			g.Expect(pathExists(eksConfigDir)).To(Equal(eksConfigDirExists))
+			g.Expect(pathExists(tracker.Dir())).To(Equal(trackerDirExists))
+			g.Expect(pathExists(kubelet.BinPath)).To(Equal(kubeletBinExists))
+		})
+	}
+}
+
+func TestUninstallerSummaryListsActionsTaken(t *testing.T) {
+	tests := []struct {
+		name                string
+		decommissionErr     error
+		wantErr             string
+		wantDaemons         []string
+		wantDeregistrations []string
+	}{
+		{
+			name:                "fails stopping containerd after decommission",
+			wantErr:             "containerd won't stop",
+			wantDaemons:         []string{iamrolesanywhere.DaemonName},
+			wantDeregistrations: []string{"node/mock-hybrid-node", "access-entry/my-cluster/node-role"},
+		},
+		{
+			name:                "fails decommissioning",
+			decommissionErr:     errors.New("forbidden"),
+			wantErr:             "decommissioning node: forbidden",
+			wantDaemons:         []string{},
+			wantDeregistrations: []string{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			daemonManager := &fakeDaemonManager{
+				stopErrs: map[string]error{containerd.ContainerdDaemonName: errors.New("containerd won't stop")},
+				stopped:  []string{},
+			}
+			uninstaller := &Uninstaller{
+				Artifacts: &tracker.InstalledArtifacts{
+					Containerd:       tracker.ContainerdSourceDistro,
+					IamRolesAnywhere: true,
+				},
+				DaemonManager: daemonManager,
+				Logger:        zap.NewNop(),
+				Decommission: func(context.Context) error {
+					return tc.decommissionErr
+				},
+				DecommissionTargets: []string{"node/mock-hybrid-node", "access-entry/my-cluster/node-role"},
+			}
+
+			summary, err := uninstaller.Run(context.Background())
+			g.Expect(err).To(MatchError(tc.wantErr))
+			g.Expect(summary.DryRun).To(BeFalse())
+			g.Expect(summary.Daemons).To(Equal(tc.wantDaemons))
+			g.Expect(summary.Daemons).To(Equal(daemonManager.stopped))
+			g.Expect(summary.Components).To(BeEmpty())
+			g.Expect(summary.Paths).To(BeEmpty())
+			g.Expect(summary.Deregistrations).To(Equal(tc.wantDeregistrations))
+		})
+	}
+}
+
+func TestUninstallerRemovePods(t *testing.T) {
+	g := NewWithT(t)
+	daemonManager := &fakeDaemonManager{
+		stopErrs: map[string]error{containerd.ContainerdDaemonName: errors.New("containerd won't stop")},
+		stopped:  []string{},
+	}
+	uninstaller := &Uninstaller{
+		Artifacts:     &tracker.InstalledArtifacts{Containerd: tracker.ContainerdSourceDistro},
+		DaemonManager: daemonManager,
+		Logger:        zap.NewNop(),
+		RemovePods: func(context.Context) ([]string, error) {
+			// The runtime must still be running to remove the pods.
+			g.Expect(daemonManager.stopped).NotTo(ContainElement(containerd.ContainerdDaemonName))
+			return []string{"kube-system/kube-proxy-abc"}, errors.New("stopping pod kube-system/cilium-abc: timeout")
+		},
+	}
+
+	summary, err := uninstaller.Run(context.Background())
+	g.Expect(err).To(MatchError("removing pods: stopping pod kube-system/cilium-abc: timeout"))
+	g.Expect(summary.Pods).To(Equal([]string{"kube-system/kube-proxy-abc"}))
+	g.Expect(daemonManager.stopped).To(BeEmpty())
+}
+
+func TestUninstallerPruneImages(t *testing.T) {
+	g := NewWithT(t)
+	daemonManager := &fakeDaemonManager{
+		stopErrs: map[string]error{containerd.ContainerdDaemonName: errors.New("containerd won't stop")},
+		stopped:  []string{},
+	}
+	podsRemoved := false
+	uninstaller := &Uninstaller{
+		Artifacts:     &tracker.InstalledArtifacts{Containerd: tracker.ContainerdSourceDistro},
+		DaemonManager: daemonManager,
+		Logger:        zap.NewNop(),
+		RemovePods: func(context.Context) ([]string, error) {
+			podsRemoved = true
+			return []string{"kube-system/kube-proxy-abc"}, nil
+		},
+		PruneImages: func(context.Context) ([]string, error) {
+			// Images are pruned once no pod uses them, while the runtime is still running.
+			g.Expect(podsRemoved).To(BeTrue())
+			g.Expect(daemonManager.stopped).NotTo(ContainElement(containerd.ContainerdDaemonName))
+			return []string{"registry.k8s.io/pause:3.10"}, errors.New("removing image quay.io/cilium/cilium:v1.16: in use")
+		},
+	}
+
+	summary, err := uninstaller.Run(context.Background())
+	g.Expect(err).To(MatchError("pruning images: removing image quay.io/cilium/cilium:v1.16: in use"))
+	g.Expect(summary.Pods).To(Equal([]string{"kube-system/kube-proxy-abc"}))
+	g.Expect(summary.Images).To(Equal([]string{"registry.k8s.io/pause:3.10"}))
+	g.Expect(daemonManager.stopped).To(BeEmpty())
+}
+
+func TestUninstallerDrainsBeforeRemovingPods(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "my-node"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+	var evictions []*policyv1.Eviction
+	client.PrependReactor("create", "pods", func(action testingk8s.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(testingk8s.CreateAction).GetObject().(*policyv1.Eviction)
+		evictions = append(evictions, eviction)
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, client.Tracker().Delete(gvr, eviction.Namespace, eviction.Name)
+	})
+	daemonManager := &fakeDaemonManager{
+		stopErrs: map[string]error{containerd.ContainerdDaemonName: errors.New("containerd won't stop")},
+		stopped:  []string{},
+	}
+
+	uninstaller := &Uninstaller{
+		Artifacts:     &tracker.InstalledArtifacts{Containerd: tracker.ContainerdSourceDistro},
+		DaemonManager: daemonManager,
+		Logger:        zap.NewNop(),
+		Drain: func(ctx context.Context) error {
+			return node.CordonAndDrain(ctx, client, "my-node", node.CordonOptions{
+				DrainGracePeriod:  30 * time.Second,
+				DrainPollInterval: time.Millisecond,
+			})
+		},
+		RemovePods: func(context.Context) ([]string, error) {
+			n, err := client.CoreV1().Nodes().Get(ctx, "my-node", metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(n.Spec.Unschedulable).To(BeTrue())
+			g.Expect(evictions).To(HaveLen(1))
+			return nil, nil
+		},
+	}
+
+	_, err := uninstaller.Run(ctx)
+	g.Expect(err).To(MatchError("containerd won't stop"))
+	g.Expect(evictions).To(HaveLen(1))
+	g.Expect(evictions[0].Name).To(Equal("app"))
+	g.Expect(*evictions[0].DeleteOptions.GracePeriodSeconds).To(BeEquivalentTo(30))
+}
+
+func TestUninstallerDrainFailureStopsUninstall(t *testing.T) {
+	g := NewWithT(t)
+	daemonManager := &fakeDaemonManager{stopped: []string{}}
+	uninstaller := &Uninstaller{
+		Artifacts:     &tracker.InstalledArtifacts{Kubelet: true},
+		DaemonManager: daemonManager,
+		Logger:        zap.NewNop(),
+		Drain: func(context.Context) error {
+			// The kubelet has to be running to terminate the evicted pods.
+			return errors.New("timed out evicting pods")
+		},
+		RemovePods: func(context.Context) ([]string, error) {
+			t.Fatal("pods must not be removed if the drain fails")
+			return nil, nil
+		},
+	}
+
+	_, err := uninstaller.Run(context.Background())
+	g.Expect(err).To(MatchError("draining node: timed out evicting pods"))
+	g.Expect(daemonManager.stopped).To(BeEmpty())
+}
+
+func TestUninstallerKeepCNI(t *testing.T) {
+	tests := []struct {
+		name           string
+		keepCNI        bool
+		wantCNIRemoved bool
+		wantComponents []string
+	}{
+		{
+			name:           "removes cni-plugins",
+			wantCNIRemoved: true,
+			wantComponents: []string{"cni-plugins"},
+		},
+		{
+			name:           "keeps cni-plugins",
+			keepCNI:        true,
+			wantComponents: []string{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cniRemoved := false
+			uninstaller := &Uninstaller{
+				Artifacts: &tracker.InstalledArtifacts{CniPlugins: true},
+				Logger:    zap.NewNop(),
+				CNIUninstall: func() error {
+					cniRemoved = true
+					return nil
+				},
+				KeepCNI: tc.keepCNI,
+				summary: UninstallSummary{Components: []string{}},
+			}
+
+			g.Expect(uninstaller.uninstallBinaries(context.Background())).To(Succeed())
+			g.Expect(cniRemoved).To(Equal(tc.wantCNIRemoved))
+			g.Expect(uninstaller.summary.Components).To(Equal(tc.wantComponents))
+		})
+	}
+}
+
+func TestUninstallSummaryWriteManifest(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "logs", "uninstall.json")
+	summary := UninstallSummary{
+		Daemons:         []string{kubelet.KubeletDaemonName},
+		Components:      []string{"kubelet", "kubectl"},
+		Paths:           []string{eksConfigDir, tracker.Dir()},
+		Deregistrations: []string{"ssm-managed-instance/mi-0123456789abcdef0"},
+	}
+
+	g.Expect(summary.WriteManifest(path)).To(Succeed())
+
+	data, err := os.ReadFile(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(MatchJSON(`{
+		"dryRun": false,
+		"daemons": ["kubelet"],
+		"components": ["kubelet", "kubectl"],
+		"paths": ["/etc/eks", "/opt/nodeadm"],
+		"deregistrations": ["ssm-managed-instance/mi-0123456789abcdef0"]
+	}`))
+}
@@ -62,10 +62,13 @@ func (u *Upgrader) Run(ctx context.Context) error {
 	if err := u.NodeProvider.Enrich(ctx, configenricher.WithRegionConfig(&u.AwsSource.RegionInfo)); err != nil {
 		return err
 	}
-	if err := initDaemons(ctx, u.NodeProvider, u.SkipPhases, u.Logger); err != nil {
+	externalRuntime := u.Artifacts.Containerd == tracker.ContainerdSourceNone
+	if err := initDaemons(ctx, u.NodeProvider, u.SkipPhases, externalRuntime, u.Logger); err != nil {
 		return err
 	}
 
+	recordSkippedValidations("upgrade", u.SkipPhases, u.Logger)
+
 	return u.NodeProvider.Cleanup()
 }
 
@@ -109,13 +112,15 @@ func (u *Upgrader) upgradeCredentialProvider(ctx context.Context) error {
 			u.Logger,
 			nodeConfig.Spec.Cluster.Region,
 			ssm.WithDnsSuffix(u.AwsSource.RegionInfo.DnsSuffix),
+			ssm.WithInstallerDir(u.AwsSource.ArtifactDir()),
 		)
 
 		u.Logger.Info("Upgrading SSM agent installer...")
 		if err := ssm.Upgrade(ctx, ssm.InstallOptions{
-			Source: ssmInstaller,
-			Logger: u.Logger,
-			Region: nodeConfig.Spec.Cluster.Region,
+			Source:        ssmInstaller,
+			Logger:        u.Logger,
+			Region:        nodeConfig.Spec.Cluster.Region,
+			DaemonManager: u.DaemonManager,
 		}); err != nil {
 			return err
 		}
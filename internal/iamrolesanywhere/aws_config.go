@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strings"
 	"text/template"
 
 	"github.com/aws/eks-hybrid/internal/network"
@@ -58,6 +59,9 @@ type AWSConfig struct {
 
 	// ProxyEnabled marks if proxy is enabled on the host
 	ProxyEnabled bool `json:"proxyEnabled,omitempty"`
+
+	// NoProxy is the comma separated list of hosts the signing helper reaches without the proxy.
+	NoProxy string `json:"noProxy,omitempty"`
 }
 
 // WriteAWSConfig writes an AWS configuration file with contents appropriate for node config
@@ -67,6 +71,9 @@ func WriteAWSConfig(cfg AWSConfig) error {
 	}
 
 	cfg.ProxyEnabled = network.IsProxyEnabled()
+	if cfg.ProxyEnabled {
+		cfg.NoProxy = network.NoProxy()
+	}
 
 	if err := validateAWSConfig(cfg); err != nil {
 		return err
@@ -110,6 +117,11 @@ func validateAWSConfig(cfg AWSConfig) error {
 		errs = append(errs, errors.New("PrivateKeyPath cannot be empty"))
 	}
 
+	// NoProxy is rendered single quoted in the credential process command.
+	if strings.Contains(cfg.NoProxy, "'") {
+		errs = append(errs, fmt.Errorf("NO_PROXY cannot contain single quotes, got %s", cfg.NoProxy))
+	}
+
 	return errors.Join(errs...)
 }
 
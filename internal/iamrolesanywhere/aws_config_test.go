@@ -239,3 +239,50 @@ func TestWriteAWSConfigProxy(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteAWSConfigNoProxy(t *testing.T) {
+	testCases := []struct {
+		name            string
+		noProxy         string
+		expectedCommand string
+	}{
+		{
+			name:            "no proxy entries preserved verbatim",
+			noProxy:         "localhost,127.0.0.1,169.254.169.254,.eks.amazonaws.com,10.0.0.0/8",
+			expectedCommand: "credential_process = /usr/bin/env 'NO_PROXY=localhost,127.0.0.1,169.254.169.254,.eks.amazonaws.com,10.0.0.0/8' 'no_proxy=localhost,127.0.0.1,169.254.169.254,.eks.amazonaws.com,10.0.0.0/8' /random/path credential-process",
+		},
+		{
+			name:            "empty no proxy",
+			noProxy:         "",
+			expectedCommand: "credential_process = /random/path credential-process",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Setenv("HTTPS_PROXY", "https://proxy.example.com:8080")
+			t.Setenv("NO_PROXY", tc.noProxy)
+			t.Setenv("no_proxy", "")
+
+			path := filepath.Join(t.TempDir(), "aws-config")
+			cfg := iamrolesanywhere.AWSConfig{
+				TrustAnchorARN:       "trust-anchor",
+				ProfileARN:           "profile",
+				RoleARN:              "role",
+				Region:               "region",
+				NodeName:             "test01",
+				ConfigPath:           path,
+				SigningHelperBinPath: "/random/path",
+				CertificatePath:      "/etc/certificates/iam/pki/my-server.crt",
+				PrivateKeyPath:       "/etc/certificates/iam/pki/my-server.key",
+			}
+			g.Expect(iamrolesanywhere.WriteAWSConfig(cfg)).To(Succeed())
+
+			content, err := os.ReadFile(path)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(bytes.Count(content, []byte(tc.expectedCommand))).To(Equal(2), "both profiles should use the same command")
+			g.Expect(string(content)).To(ContainSubstring("--with-proxy"))
+		})
+	}
+}
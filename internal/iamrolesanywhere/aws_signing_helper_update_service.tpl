@@ -11,7 +11,8 @@ ExecStart={{ .SigningHelperBinPath }} update \
         --profile-arn {{ .ProfileARN }} \
         --role-arn {{ .RoleARN }} \
         --role-session-name {{ .NodeName }} \
-        --region {{ .Region }}{{ if .ProxyEnabled }} --with-proxy{{end}}
+        --region {{ .Region }}{{ if .SessionDuration }} \
+        --session-duration {{ .SessionDuration }}{{ end }}{{ if .ProxyEnabled }} --with-proxy{{end}}
 StandardOutput=journal
 StandardError=journal
 Restart=always
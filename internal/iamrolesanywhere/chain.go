@@ -0,0 +1,201 @@
+package iamrolesanywhere
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/rolesanywhere"
+	"github.com/aws/aws-sdk-go-v2/service/rolesanywhere/types"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	// CertificateChainValidation is the name of the certificate chain validation.
+	CertificateChainValidation = "iam-ra-certificate-chain-validation"
+
+	// maxChainLength bounds the chain walk, so a loop in the provided certificates can't hang it.
+	maxChainLength = 10
+)
+
+// TrustAnchorClient reads IAM Roles Anywhere trust anchors.
+type TrustAnchorClient interface {
+	GetTrustAnchor(ctx context.Context, params *rolesanywhere.GetTrustAnchorInput, optFns ...func(*rolesanywhere.Options)) (*rolesanywhere.GetTrustAnchorOutput, error)
+}
+
+// CertificateChainValidator validates the node certificate chains up to the CA of the
+// IAM Roles Anywhere trust anchor, since a missing intermediate makes every
+// aws_signing_helper request fail.
+type CertificateChainValidator struct {
+	client TrustAnchorClient
+}
+
+// NewCertificateChainValidator returns a new CertificateChainValidator.
+func NewCertificateChainValidator(client TrustAnchorClient) CertificateChainValidator {
+	return CertificateChainValidator{
+		client: client,
+	}
+}
+
+// NewCertificateChainValidatorFromConfig returns a CertificateChainValidator that reads the
+// trust anchor with the IAM Roles Anywhere API.
+func NewCertificateChainValidatorFromConfig(config aws.Config) CertificateChainValidator {
+	return NewCertificateChainValidator(rolesanywhere.NewFromConfig(config))
+}
+
+func (v CertificateChainValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, CertificateChainValidation, "Validating the node certificate chains up to the IAM Roles Anywhere trust anchor")
+	defer func() {
+		informer.Done(ctx, CertificateChainValidation, err)
+	}()
+
+	err = v.Validate(ctx, node)
+	return err
+}
+
+// Validate checks the certificate configured for the node, followed by any intermediates
+// in the same file, chains up to a CA of the configured trust anchor.
+func (v CertificateChainValidator) Validate(ctx context.Context, node *api.NodeConfig) error {
+	iamRA := node.Spec.Hybrid.IAMRolesAnywhere
+	certPEM, err := os.ReadFile(iamRA.CertificatePath)
+	if err != nil {
+		return validation.WithRemediation(fmt.Errorf("reading IAM Roles Anywhere certificate: %w", err),
+			"Ensure the certificatePath in the NodeConfig points to the node certificate.")
+	}
+
+	anchors, err := v.trustAnchorCAs(ctx, iamRA.TrustAnchorARN)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("reading trust anchor %s: %w", iamRA.TrustAnchorARN, err),
+			"The certificate chain can't be verified without reading the trust anchor. Ensure the node role allows rolesanywhere:GetTrustAnchor.")
+	}
+	if len(anchors) == 0 {
+		// Trust anchors backed by AWS Private CA don't expose the CA certificate.
+		return nil
+	}
+
+	if err := ValidateCertificateChain(certPEM, anchors, time.Now()); err != nil {
+		return validation.WithRemediation(err,
+			fmt.Sprintf("Append the missing intermediate certificates to %s after the node certificate, "+
+				"or issue the node certificate from the CA registered in trust anchor %s.", iamRA.CertificatePath, iamRA.TrustAnchorARN))
+	}
+	return nil
+}
+
+// trustAnchorCAs returns the CA certificates of the trust anchor. It returns none for
+// trust anchors that don't include the certificates.
+func (v CertificateChainValidator) trustAnchorCAs(ctx context.Context, trustAnchorARN string) ([]*x509.Certificate, error) {
+	parsedARN, err := arn.Parse(trustAnchorARN)
+	if err != nil {
+		return nil, fmt.Errorf("parsing trust anchor ARN: %w", err)
+	}
+	id := strings.TrimPrefix(parsedARN.Resource, "trust-anchor/")
+	out, err := v.client.GetTrustAnchor(ctx, &rolesanywhere.GetTrustAnchorInput{TrustAnchorId: aws.String(id)},
+		func(o *rolesanywhere.Options) {
+			o.Region = parsedARN.Region
+		})
+	if err != nil {
+		return nil, err
+	}
+	if out.TrustAnchor == nil || out.TrustAnchor.Source == nil {
+		return nil, nil
+	}
+	data, ok := out.TrustAnchor.Source.SourceData.(*types.SourceDataMemberX509CertificateData)
+	if !ok {
+		return nil, nil
+	}
+	return parseCertificates([]byte(data.Value))
+}
+
+// ValidateCertificateChain checks the first certificate in certPEM chains up to one of the
+// anchors through the other certificates in certPEM. When it doesn't, the error names the
+// issuer that is missing from the chain.
+func ValidateCertificateChain(certPEM []byte, anchors []*x509.Certificate, now time.Time) error {
+	certs, err := parseCertificates(certPEM)
+	if err != nil {
+		return fmt.Errorf("parsing IAM Roles Anywhere certificate: %w", err)
+	}
+	if len(certs) == 0 {
+		return errors.New("IAM Roles Anywhere certificate file doesn't contain any certificate")
+	}
+	leaf, intermediates := certs[0], certs[1:]
+
+	current := leaf
+	for range maxChainLength {
+		if issuedByAny(current, anchors) {
+			return verifyChain(leaf, intermediates, anchors, now)
+		}
+		next := issuerOf(current, intermediates)
+		if next == nil {
+			return fmt.Errorf("certificate chain is incomplete: certificate %q is issued by %q, which is neither in the certificate file nor a CA of the trust anchor",
+				current.Subject.String(), current.Issuer.String())
+		}
+		current = next
+	}
+	return fmt.Errorf("certificate chain is longer than %d certificates", maxChainLength)
+}
+
+// verifyChain runs the full x509 verification once the chain is known to be complete,
+// which also checks validity dates and CA constraints.
+func verifyChain(leaf *x509.Certificate, intermediates, anchors []*x509.Certificate, now time.Time) error {
+	opts := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, anchor := range anchors {
+		opts.Roots.AddCert(anchor)
+	}
+	for _, intermediate := range intermediates {
+		opts.Intermediates.AddCert(intermediate)
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return fmt.Errorf("verifying certificate chain: %w", err)
+	}
+	return nil
+}
+
+func issuedByAny(cert *x509.Certificate, issuers []*x509.Certificate) bool {
+	return issuerOf(cert, issuers) != nil
+}
+
+func issuerOf(cert *x509.Certificate, candidates []*x509.Certificate) *x509.Certificate {
+	for _, candidate := range candidates {
+		if candidate == cert {
+			continue
+		}
+		if cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+}
@@ -0,0 +1,186 @@
+package iamrolesanywhere_test
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rolesanywhere"
+	"github.com/aws/aws-sdk-go-v2/service/rolesanywhere/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestValidateCertificateChain(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	rootPEM, rootTemplate, rootKey := test.GenerateCA(g)
+	root := parseCertificate(g, rootPEM)
+	intermediatePEM, intermediate, intermediateKey := test.GenerateIntermediateCA(g, rootTemplate, rootKey)
+	leafFromIntermediate := test.GenerateKubeletCert(g, intermediate, intermediateKey, now.Add(-time.Hour), now.Add(time.Hour))
+	leafFromRoot := test.GenerateKubeletCert(g, rootTemplate, rootKey, now.Add(-time.Hour), now.Add(time.Hour))
+	otherRootPEM, _, _ := test.GenerateCA(g)
+	otherRoot := parseCertificate(g, otherRootPEM)
+
+	tests := []struct {
+		name    string
+		certPEM []byte
+		anchors []*x509.Certificate
+		wantErr string
+	}{
+		{
+			name:    "leaf issued by the trust anchor",
+			certPEM: leafFromRoot,
+			anchors: []*x509.Certificate{root},
+		},
+		{
+			name:    "leaf with its intermediate",
+			certPEM: append(append([]byte{}, leafFromIntermediate...), intermediatePEM...),
+			anchors: []*x509.Certificate{root},
+		},
+		{
+			name:    "intermediate is the trust anchor",
+			certPEM: leafFromIntermediate,
+			anchors: []*x509.Certificate{intermediate},
+		},
+		{
+			name:    "missing intermediate",
+			certPEM: leafFromIntermediate,
+			anchors: []*x509.Certificate{root},
+			wantErr: `certificate chain is incomplete: certificate "CN=test-kubelet,O=Test Kubelet" is issued by "CN=test-intermediate-ca,O=Test CA"`,
+		},
+		{
+			name:    "chain ends at another CA",
+			certPEM: append(append([]byte{}, leafFromIntermediate...), intermediatePEM...),
+			anchors: []*x509.Certificate{otherRoot},
+			wantErr: `certificate "CN=test-intermediate-ca,O=Test CA" is issued by "CN=test-ca,O=Test CA", which is neither in the certificate file nor a CA of the trust anchor`,
+		},
+		{
+			name:    "no certificate",
+			certPEM: []byte("not a certificate"),
+			anchors: []*x509.Certificate{root},
+			wantErr: "IAM Roles Anywhere certificate file doesn't contain any certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := iamrolesanywhere.ValidateCertificateChain(tt.certPEM, tt.anchors, now)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+		})
+	}
+}
+
+func parseCertificate(g *WithT, certPEM []byte) *x509.Certificate {
+	block, _ := pem.Decode(certPEM)
+	g.Expect(block).NotTo(BeNil())
+	cert, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	return cert
+}
+
+type fakeTrustAnchorClient struct {
+	source *types.Source
+	err    error
+	region string
+	id     string
+}
+
+func (f *fakeTrustAnchorClient) GetTrustAnchor(_ context.Context, params *rolesanywhere.GetTrustAnchorInput, optFns ...func(*rolesanywhere.Options)) (*rolesanywhere.GetTrustAnchorOutput, error) {
+	opts := rolesanywhere.Options{}
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+	f.region = opts.Region
+	f.id = aws.ToString(params.TrustAnchorId)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &rolesanywhere.GetTrustAnchorOutput{TrustAnchor: &types.TrustAnchorDetail{Source: f.source}}, nil
+}
+
+func TestCertificateChainValidator(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	rootPEM, root, rootKey := test.GenerateCA(g)
+	intermediatePEM, intermediate, intermediateKey := test.GenerateIntermediateCA(g, root, rootKey)
+	leaf := test.GenerateKubeletCert(g, intermediate, intermediateKey, now.Add(-time.Hour), now.Add(time.Hour))
+	x509Source := &types.Source{
+		SourceType: types.TrustAnchorTypeCertificateBundle,
+		SourceData: &types.SourceDataMemberX509CertificateData{Value: string(rootPEM)},
+	}
+
+	tests := []struct {
+		name        string
+		certPEM     []byte
+		client      *fakeTrustAnchorClient
+		wantErr     string
+		wantWarning bool
+	}{
+		{
+			name:    "complete chain",
+			certPEM: append(append([]byte{}, leaf...), intermediatePEM...),
+			client:  &fakeTrustAnchorClient{source: x509Source},
+		},
+		{
+			name:    "broken chain",
+			certPEM: leaf,
+			client:  &fakeTrustAnchorClient{source: x509Source},
+			wantErr: "certificate chain is incomplete",
+		},
+		{
+			name:    "private CA trust anchor can't be verified",
+			certPEM: leaf,
+			client: &fakeTrustAnchorClient{source: &types.Source{
+				SourceType: types.TrustAnchorTypeAwsAcmPca,
+				SourceData: &types.SourceDataMemberAcmPcaArn{Value: "arn:aws:acm-pca:us-west-2:123456789010:certificate-authority/abc"},
+			}},
+		},
+		{
+			name:        "trust anchor can't be read",
+			certPEM:     leaf,
+			client:      &fakeTrustAnchorClient{err: errors.New("AccessDeniedException")},
+			wantErr:     "reading trust anchor arn:aws:rolesanywhere:us-east-1:123456789010:trust-anchor/ta-id: AccessDeniedException",
+			wantWarning: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			certPath := filepath.Join(t.TempDir(), "node.crt")
+			g.Expect(os.WriteFile(certPath, tt.certPEM, 0o600)).To(Succeed())
+			node := &api.NodeConfig{}
+			node.Spec.Hybrid = &api.HybridOptions{
+				IAMRolesAnywhere: &api.IAMRolesAnywhere{
+					TrustAnchorARN:  "arn:aws:rolesanywhere:us-east-1:123456789010:trust-anchor/ta-id",
+					CertificatePath: certPath,
+				},
+			}
+
+			err := iamrolesanywhere.NewCertificateChainValidator(tt.client).Validate(context.Background(), node)
+			g.Expect(tt.client.id).To(Equal("ta-id"))
+			g.Expect(tt.client.region).To(Equal("us-east-1"))
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(validation.IsWarning(err)).To(Equal(tt.wantWarning))
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
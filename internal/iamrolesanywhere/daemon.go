@@ -122,6 +122,7 @@ func GenerateUpdateSystemdService(node *api.NodeConfig) ([]byte, error) {
 		"NodeName":                  node.Spec.Hybrid.IAMRolesAnywhere.NodeName,
 		"CertificatePath":           node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath,
 		"PrivateKeyPath":            node.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath,
+		"SessionDuration":           node.Spec.Hybrid.IAMRolesAnywhere.SessionDuration,
 		"ProxyEnabled":              network.IsProxyEnabled(),
 	}
 
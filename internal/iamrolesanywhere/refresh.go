@@ -0,0 +1,124 @@
+package iamrolesanywhere
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	// MinSessionDuration and MaxSessionDuration are the session durations, in seconds,
+	// accepted by aws_signing_helper.
+	MinSessionDuration = 900
+	MaxSessionDuration = 43200
+	// DefaultSessionDuration is the session duration aws_signing_helper uses when none is configured.
+	DefaultSessionDuration = 3600
+
+	credentialsRefreshValidation = "iam-ra-credentials-refresh"
+)
+
+// CredentialsCache reports when the shared credentials file was last refreshed.
+type CredentialsCache interface {
+	LastRefresh() (time.Time, error)
+}
+
+// CredentialsFile is the shared credentials file written by the aws_signing_helper_update service.
+// Its modification time is the last time the credentials were refreshed.
+type CredentialsFile string
+
+func (f CredentialsFile) LastRefresh() (time.Time, error) {
+	info, err := os.Stat(string(f))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// CredentialsRefreshValidator validates that the aws_signing_helper_update service is running
+// and keeps the shared credentials file from expiring.
+type CredentialsRefreshValidator struct {
+	daemonManager daemon.DaemonManager
+	credentials   CredentialsCache
+	now           func() time.Time
+}
+
+// CredentialsRefreshValidatorOpt configures a CredentialsRefreshValidator.
+type CredentialsRefreshValidatorOpt func(*CredentialsRefreshValidator)
+
+// WithCredentialsCache overrides where the last refresh is read from.
+// It defaults to the shared credentials file.
+func WithCredentialsCache(cache CredentialsCache) CredentialsRefreshValidatorOpt {
+	return func(v *CredentialsRefreshValidator) {
+		v.credentials = cache
+	}
+}
+
+// NewCredentialsRefreshValidator returns a new CredentialsRefreshValidator.
+func NewCredentialsRefreshValidator(daemonManager daemon.DaemonManager, opts ...CredentialsRefreshValidatorOpt) CredentialsRefreshValidator {
+	v := CredentialsRefreshValidator{
+		daemonManager: daemonManager,
+		credentials:   CredentialsFile(EksHybridAwsCredentialsPath),
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(&v)
+	}
+	return v
+}
+
+// Run validates the credentials refresh for IAM Roles Anywhere nodes with the shared credentials file enabled.
+func (v CredentialsRefreshValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	if !node.IsIAMRolesAnywhere() || !node.Spec.Hybrid.EnableCredentialsFile {
+		return nil
+	}
+
+	var err error
+	informer.Starting(ctx, credentialsRefreshValidation, "Validating the IAM Roles Anywhere credentials are being refreshed")
+	defer func() {
+		informer.Done(ctx, credentialsRefreshValidation, err)
+	}()
+	err = v.Validate(node)
+	return err
+}
+
+// Validate returns an error if the aws_signing_helper_update service isn't running or the
+// credentials were last refreshed longer than a session duration ago, which means they have expired.
+func (v CredentialsRefreshValidator) Validate(node *api.NodeConfig) error {
+	remediation := fmt.Sprintf("Check the %s service logs with 'journalctl -u %s'. "+
+		"Ensure the IAM Roles Anywhere certificate is valid and the node can reach the IAM Roles Anywhere endpoint.", DaemonName, DaemonName)
+
+	status, err := v.daemonManager.GetDaemonStatus(DaemonName)
+	if err != nil {
+		return validation.WithRemediation(fmt.Errorf("getting %s service status: %w", DaemonName, err), remediation)
+	}
+	if status != daemon.DaemonStatusRunning {
+		return validation.WithRemediation(fmt.Errorf("%s service is %s, the AWS credentials won't be refreshed", DaemonName, status), remediation)
+	}
+
+	lastRefresh, err := v.credentials.LastRefresh()
+	if err != nil {
+		return validation.WithRemediation(fmt.Errorf("reading last credentials refresh: %w", err), remediation)
+	}
+
+	sessionDuration := time.Duration(SessionDuration(node)) * time.Second
+	if age := v.now().Sub(lastRefresh); age > sessionDuration {
+		return validation.WithRemediation(
+			fmt.Errorf("AWS credentials were last refreshed %s ago, longer than the %s session duration, so they have expired", age.Round(time.Second), sessionDuration),
+			remediation,
+		)
+	}
+	return nil
+}
+
+// SessionDuration returns the configured session duration in seconds, or the default if not configured.
+func SessionDuration(node *api.NodeConfig) int32 {
+	if node.Spec.Hybrid.IAMRolesAnywhere.SessionDuration == 0 {
+		return DefaultSessionDuration
+	}
+	return node.Spec.Hybrid.IAMRolesAnywhere.SessionDuration
+}
@@ -0,0 +1,130 @@
+package iamrolesanywhere_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeDaemonManager struct {
+	daemon.DaemonManager
+	status daemon.DaemonStatus
+	err    error
+}
+
+func (f fakeDaemonManager) GetDaemonStatus(string) (daemon.DaemonStatus, error) {
+	return f.status, f.err
+}
+
+type fakeCredentialsCache struct {
+	lastRefresh time.Time
+	err         error
+}
+
+func (f fakeCredentialsCache) LastRefresh() (time.Time, error) {
+	return f.lastRefresh, f.err
+}
+
+func credentialsFileNode(sessionDuration int32) *api.NodeConfig {
+	return &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Hybrid: &api.HybridOptions{
+				EnableCredentialsFile: true,
+				IAMRolesAnywhere: &api.IAMRolesAnywhere{
+					SessionDuration: sessionDuration,
+				},
+			},
+		},
+	}
+}
+
+func TestCredentialsRefreshValidatorValidate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		daemon          fakeDaemonManager
+		cache           fakeCredentialsCache
+		sessionDuration int32
+		wantErr         string
+	}{
+		{
+			name:   "fresh credentials",
+			daemon: fakeDaemonManager{status: daemon.DaemonStatusRunning},
+			cache:  fakeCredentialsCache{lastRefresh: time.Now().Add(-30 * time.Minute)},
+		},
+		{
+			name:            "fresh credentials with longer session duration",
+			daemon:          fakeDaemonManager{status: daemon.DaemonStatusRunning},
+			cache:           fakeCredentialsCache{lastRefresh: time.Now().Add(-90 * time.Minute)},
+			sessionDuration: 7200,
+		},
+		{
+			name:    "stale credentials",
+			daemon:  fakeDaemonManager{status: daemon.DaemonStatusRunning},
+			cache:   fakeCredentialsCache{lastRefresh: time.Now().Add(-90 * time.Minute)},
+			wantErr: "AWS credentials were last refreshed 1h30m0s ago, longer than the 1h0m0s session duration, so they have expired",
+		},
+		{
+			name:    "daemon stopped",
+			daemon:  fakeDaemonManager{status: daemon.DaemonStatusStopped},
+			cache:   fakeCredentialsCache{lastRefresh: time.Now()},
+			wantErr: "aws_signing_helper_update service is stopped, the AWS credentials won't be refreshed",
+		},
+		{
+			name:    "daemon status error",
+			daemon:  fakeDaemonManager{err: errors.New("dbus unavailable")},
+			wantErr: "getting aws_signing_helper_update service status: dbus unavailable",
+		},
+		{
+			name:    "credentials file missing",
+			daemon:  fakeDaemonManager{status: daemon.DaemonStatusRunning},
+			cache:   fakeCredentialsCache{err: os.ErrNotExist},
+			wantErr: "reading last credentials refresh: file does not exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			v := iamrolesanywhere.NewCredentialsRefreshValidator(tc.daemon, iamrolesanywhere.WithCredentialsCache(tc.cache))
+
+			err := v.Validate(credentialsFileNode(tc.sessionDuration))
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tc.wantErr))
+			g.Expect(validation.Remediation(err)).To(ContainSubstring("journalctl -u aws_signing_helper_update"))
+		})
+	}
+}
+
+func TestCredentialsRefreshValidatorRunSkipsWithoutCredentialsFile(t *testing.T) {
+	g := NewWithT(t)
+	node := credentialsFileNode(0)
+	node.Spec.Hybrid.EnableCredentialsFile = false
+	v := iamrolesanywhere.NewCredentialsRefreshValidator(fakeDaemonManager{status: daemon.DaemonStatusStopped})
+
+	g.Expect(v.Run(context.Background(), validation.NewStatusRecorder(), node)).To(Succeed())
+}
+
+func TestCredentialsFileLastRefresh(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "credentials")
+	g.Expect(os.WriteFile(path, []byte("[default]"), 0o600)).To(Succeed())
+	refreshed := time.Now().Add(-time.Hour).Truncate(time.Second)
+	g.Expect(os.Chtimes(path, refreshed, refreshed)).To(Succeed())
+
+	lastRefresh, err := iamrolesanywhere.CredentialsFile(path).LastRefresh()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(lastRefresh).To(BeTemporally("==", refreshed))
+}
@@ -31,6 +31,9 @@ type InstallOptions struct {
 	Tracker     *tracker.Tracker
 	Source      Source
 	Logger      *zap.Logger
+	// ExpectedChecksum optionally pins the sha256 digest the kubectl binary must match.
+	// If empty, the checksum published by the source is used.
+	ExpectedChecksum []byte
 }
 
 // Install installs kubectl at BinPath.
@@ -74,12 +77,12 @@ func downloadFileTo(ctx context.Context, opts InstallOptions) error {
 	}
 	defer kubectl.Close()
 
-	if err := artifact.InstallFile(filepath.Join(opts.InstallRoot, BinPath), kubectl, artifactFilePerms); err != nil {
-		return errors.Wrap(err, "installing kubectl")
+	expectedChecksum := opts.ExpectedChecksum
+	if len(expectedChecksum) == 0 {
+		expectedChecksum = kubectl.ExpectedChecksum()
 	}
-
-	if !kubectl.VerifyChecksum() {
-		return errors.Errorf("kubectl checksum mismatch: %v", artifact.NewChecksumError(kubectl))
+	if err := artifact.InstallFileWithChecksum(filepath.Join(opts.InstallRoot, BinPath), kubectl, artifactFilePerms, expectedChecksum); err != nil {
+		return errors.Wrap(err, "installing kubectl")
 	}
 
 	return nil
@@ -0,0 +1,227 @@
+package kubelet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	cgroupValidation = "cgroup-validation"
+	cgroupMountPoint = "/sys/fs/cgroup"
+	// cgroupV2ControllersFile only exists at the root of the unified cgroup v2 hierarchy.
+	cgroupV2ControllersFile = "cgroup.controllers"
+	// kubeletDefaultCgroupDriver is the cgroup driver kubelet uses when its config doesn't set one.
+	kubeletDefaultCgroupDriver = containerd.CgroupDriverCgroupfs
+)
+
+// cgroupV1Controllers are the cgroup v1 hierarchies kubelet requires the cgroup root in.
+var cgroupV1Controllers = []string{"cpu", "memory"}
+
+// CgroupValidator validates before init that kubelet and containerd are configured with the
+// same cgroup driver, that the driver suits the cgroup version of the host and that the
+// kubelet cgroup root exists. Mismatches make kubelet fail to start or account pod resources
+// in the wrong cgroups.
+type CgroupValidator struct {
+	// cgroupRoot is where the cgroup hierarchies are mounted.
+	cgroupRoot string
+	// kubeletConfigRoot is the directory of the kubelet config file and its drop-in directory.
+	kubeletConfigRoot string
+	// kubeletConfigOnDisk is whether the kubelet config on disk stays active because nodeadm won't write it.
+	kubeletConfigOnDisk    bool
+	containerdCgroupDriver func(cfg *api.NodeConfig) (string, error)
+}
+
+// CgroupValidatorOpt allows to configure the CgroupValidator.
+type CgroupValidatorOpt func(*CgroupValidator)
+
+// WithContainerdMainConfig sets whether the main containerd config and the kubelet config on
+// disk stay active because nodeadm won't write them.
+func WithContainerdMainConfig(configMain bool) CgroupValidatorOpt {
+	return func(v *CgroupValidator) {
+		v.kubeletConfigOnDisk = configMain
+		v.containerdCgroupDriver = func(cfg *api.NodeConfig) (string, error) {
+			return containerd.CgroupDriver(cfg, configMain)
+		}
+	}
+}
+
+// NewCgroupValidator returns a validator for the cgroup configuration of kubelet and containerd.
+func NewCgroupValidator(opts ...CgroupValidatorOpt) *CgroupValidator {
+	v := &CgroupValidator{cgroupRoot: cgroupMountPoint, kubeletConfigRoot: kubeletConfigRoot}
+	WithContainerdMainConfig(false)(v)
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Run validates the cgroup configuration of kubelet and containerd.
+func (v *CgroupValidator) Run(ctx context.Context, informer validation.Informer, cfg *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, cgroupValidation, "Validating kubelet and containerd cgroup configuration")
+	defer func() {
+		informer.Done(ctx, cgroupValidation, err)
+	}()
+	err = v.Validate(cfg)
+	return err
+}
+
+// Validate returns an error if the kubelet and containerd cgroup drivers differ, if they use
+// cgroupfs on a cgroup v2 host or if the kubelet cgroup root doesn't exist on the host.
+func (v *CgroupValidator) Validate(cfg *api.NodeConfig) error {
+	kubeletConfig, err := v.kubeletCgroupConfig(cfg)
+	if err != nil {
+		return validation.WithRemediation(err, "Ensure cgroupDriver and cgroupRoot are strings in the kubelet config.")
+	}
+	containerdDriver, err := v.containerdCgroupDriver(cfg)
+	if err != nil {
+		return fmt.Errorf("reading containerd cgroup driver: %w", err)
+	}
+
+	if kubeletConfig.driver != containerdDriver {
+		return validation.WithRemediation(
+			fmt.Errorf("kubelet uses the %s cgroup driver but containerd uses %s", kubeletConfig.driver, containerdDriver),
+			fmt.Sprintf("Configure both with the %s cgroup driver: set cgroupDriver in the kubelet config and SystemdCgroup "+
+				"for the runc runtime in the containerd config.", containerd.CgroupDriverSystemd),
+		)
+	}
+
+	v2 := v.unifiedHierarchy()
+	if v2 && kubeletConfig.driver == containerd.CgroupDriverCgroupfs {
+		return validation.WithRemediation(
+			errors.New("kubelet and containerd use the cgroupfs cgroup driver on a cgroup v2 host managed by systemd"),
+			fmt.Sprintf("Set cgroupDriver to %s in the kubelet config and SystemdCgroup = true for the runc runtime in the containerd config, "+
+				"or remove both overrides to use the nodeadm defaults.", containerd.CgroupDriverSystemd),
+		)
+	}
+
+	return v.validateCgroupRoot(kubeletConfig, v2)
+}
+
+func (v *CgroupValidator) unifiedHierarchy() bool {
+	_, err := os.Stat(filepath.Join(v.cgroupRoot, cgroupV2ControllersFile))
+	return err == nil
+}
+
+// validateCgroupRoot returns an error if the kubelet cgroup root is missing from the host
+// cgroup hierarchies, kubelet only creates the cgroups below it.
+func (v *CgroupValidator) validateCgroupRoot(config kubeletCgroupConfig, v2 bool) error {
+	if config.root == "/" {
+		return nil
+	}
+	path := config.root
+	if config.driver == containerd.CgroupDriverSystemd {
+		path = systemdCgroupPath(config.root)
+	}
+
+	hierarchies := []string{v.cgroupRoot}
+	if !v2 {
+		hierarchies = nil
+		for _, controller := range cgroupV1Controllers {
+			hierarchies = append(hierarchies, filepath.Join(v.cgroupRoot, controller))
+		}
+	}
+	for _, hierarchy := range hierarchies {
+		if _, err := os.Stat(filepath.Join(hierarchy, path)); os.IsNotExist(err) {
+			return validation.WithRemediation(
+				fmt.Errorf("kubelet cgroup root %s doesn't exist in %s", config.root, hierarchy),
+				"Create the cgroup, for example with a systemd slice unit when using the systemd cgroup driver, "+
+					"or set cgroupRoot to / in the kubelet config.",
+			)
+		} else if err != nil {
+			return fmt.Errorf("checking kubelet cgroup root %s: %w", config.root, err)
+		}
+	}
+	return nil
+}
+
+// systemdCgroupPath returns the path of the cgroup kubelet uses for root with the systemd cgroup
+// driver, where /a/b is the b slice nested in the a slice: /a.slice/a-b.slice. Roots already
+// named after slices are used as they are.
+func systemdCgroupPath(root string) string {
+	if strings.HasSuffix(root, ".slice") {
+		return root
+	}
+	var path, slice string
+	for _, part := range strings.Split(strings.Trim(root, "/"), "/") {
+		if slice == "" {
+			slice = part
+		} else {
+			slice += "-" + part
+		}
+		path += "/" + slice + ".slice"
+	}
+	return path
+}
+
+// kubeletCgroupConfig holds the kubelet config fields that control its cgroups.
+type kubeletCgroupConfig struct {
+	driver string
+	root   string
+}
+
+// kubeletCgroupConfig returns the cgroup config kubelet runs with, read from the kubelet config
+// on disk when nodeadm won't write it. Otherwise, or without a kubelet config on disk, it's the
+// one nodeadm generates for cfg.
+func (v *CgroupValidator) kubeletCgroupConfig(cfg *api.NodeConfig) (kubeletCgroupConfig, error) {
+	if v.kubeletConfigOnDisk {
+		config, err := kubeletCgroupConfigOnDisk(v.kubeletConfigRoot)
+		if !os.IsNotExist(err) {
+			return config, err
+		}
+	}
+	return kubeletCgroupConfigFor(cfg)
+}
+
+// kubeletCgroupConfigOnDisk returns the kubelet cgroup config of the kubelet config file in
+// configRoot with its drop-in configs applied, unset fields take the kubelet defaults.
+func kubeletCgroupConfigOnDisk(configRoot string) (kubeletCgroupConfig, error) {
+	var fileConfig struct {
+		CgroupDriver string `json:"cgroupDriver"`
+		CgroupRoot   string `json:"cgroupRoot"`
+	}
+	config := kubeletCgroupConfig{driver: kubeletDefaultCgroupDriver, root: "/"}
+	if err := readKubeletConfig(filepath.Join(configRoot, kubeletConfigFile), filepath.Join(configRoot, kubeletConfigDir), &fileConfig); err != nil {
+		return config, err
+	}
+	if fileConfig.CgroupDriver != "" {
+		config.driver = fileConfig.CgroupDriver
+	}
+	if fileConfig.CgroupRoot != "" {
+		config.root = fileConfig.CgroupRoot
+	}
+	return config, nil
+}
+
+// kubeletCgroupConfigFor returns the kubelet cgroup config nodeadm generates for cfg,
+// with the user kubelet config and flags applied on top.
+func kubeletCgroupConfigFor(cfg *api.NodeConfig) (kubeletCgroupConfig, error) {
+	config := kubeletCgroupConfig{driver: containerd.CgroupDriverSystemd, root: "/"}
+	for key, value := range map[string]*string{"cgroupDriver": &config.driver, "cgroupRoot": &config.root} {
+		if raw, ok := cfg.Spec.Kubelet.Config[key]; ok {
+			if err := json.Unmarshal(raw.Raw, value); err != nil {
+				return config, fmt.Errorf("parsing kubelet config %s: %w", key, err)
+			}
+		}
+	}
+	// Flags are passed after the config file, so they take precedence.
+	for _, flag := range cfg.Spec.Kubelet.Flags {
+		name, value, _ := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		switch name {
+		case "cgroup-driver":
+			config.driver = value
+		case "cgroup-root":
+			config.root = value
+		}
+	}
+	return config, nil
+}
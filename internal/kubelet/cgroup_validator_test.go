@@ -0,0 +1,222 @@
+package kubelet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// fakeCgroupHierarchy creates a cgroup mount point for version, v1 or v2, with the cgroups in paths.
+func fakeCgroupHierarchy(g *WithT, root, version string, paths ...string) {
+	hierarchies := []string{root}
+	if version == "v2" {
+		g.Expect(os.WriteFile(filepath.Join(root, cgroupV2ControllersFile), []byte("cpu memory pids\n"), 0o644)).To(Succeed())
+	} else {
+		hierarchies = nil
+		for _, controller := range cgroupV1Controllers {
+			hierarchies = append(hierarchies, filepath.Join(root, controller))
+		}
+	}
+	for _, hierarchy := range hierarchies {
+		for _, path := range append(paths, "/") {
+			g.Expect(os.MkdirAll(filepath.Join(hierarchy, path), 0o755)).To(Succeed())
+		}
+	}
+}
+
+func TestCgroupValidator(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cgroupVersion    string
+		cgroups          []string
+		kubeletConfig    map[string]string
+		kubeletFlags     []string
+		containerdDriver string
+		wantErr          string
+	}{
+		{
+			name:             "nodeadm defaults on cgroup v2",
+			cgroupVersion:    "v2",
+			containerdDriver: containerd.CgroupDriverSystemd,
+		},
+		{
+			name:             "nodeadm defaults on cgroup v1",
+			cgroupVersion:    "v1",
+			containerdDriver: containerd.CgroupDriverSystemd,
+		},
+		{
+			name:             "cgroupfs on cgroup v1",
+			cgroupVersion:    "v1",
+			kubeletConfig:    map[string]string{"cgroupDriver": `"cgroupfs"`},
+			containerdDriver: containerd.CgroupDriverCgroupfs,
+		},
+		{
+			name:             "cgroupfs on cgroup v2",
+			cgroupVersion:    "v2",
+			kubeletConfig:    map[string]string{"cgroupDriver": `"cgroupfs"`},
+			containerdDriver: containerd.CgroupDriverCgroupfs,
+			wantErr:          "kubelet and containerd use the cgroupfs cgroup driver on a cgroup v2 host managed by systemd",
+		},
+		{
+			name:             "containerd uses cgroupfs",
+			cgroupVersion:    "v2",
+			containerdDriver: containerd.CgroupDriverCgroupfs,
+			wantErr:          "kubelet uses the systemd cgroup driver but containerd uses cgroupfs",
+		},
+		{
+			name:             "kubelet flag overrides the driver",
+			cgroupVersion:    "v1",
+			kubeletFlags:     []string{"--cgroup-driver=cgroupfs"},
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErr:          "kubelet uses the cgroupfs cgroup driver but containerd uses systemd",
+		},
+		{
+			name:             "existing slice cgroup root",
+			cgroupVersion:    "v2",
+			cgroups:          []string{"/kube.slice/kube-pods.slice"},
+			kubeletConfig:    map[string]string{"cgroupRoot": `"/kube/pods"`},
+			containerdDriver: containerd.CgroupDriverSystemd,
+		},
+		{
+			name:             "existing cgroupfs cgroup root on cgroup v1",
+			cgroupVersion:    "v1",
+			cgroups:          []string{"/kube"},
+			kubeletFlags:     []string{"--cgroup-driver=cgroupfs", "--cgroup-root=/kube"},
+			containerdDriver: containerd.CgroupDriverCgroupfs,
+		},
+		{
+			name:             "missing cgroup root",
+			cgroupVersion:    "v2",
+			kubeletConfig:    map[string]string{"cgroupRoot": `"/kube.slice"`},
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErr:          "kubelet cgroup root /kube.slice doesn't exist in",
+		},
+		{
+			name:             "invalid kubelet config",
+			cgroupVersion:    "v2",
+			kubeletConfig:    map[string]string{"cgroupDriver": `true`},
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErr:          "parsing kubelet config cgroupDriver: json: cannot unmarshal bool into Go value of type string",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			root := t.TempDir()
+			fakeCgroupHierarchy(g, root, tc.cgroupVersion, tc.cgroups...)
+
+			cfg := &api.NodeConfig{}
+			cfg.Spec.Kubelet.Flags = tc.kubeletFlags
+			cfg.Spec.Kubelet.Config = api.InlineDocument{}
+			for k, v := range tc.kubeletConfig {
+				cfg.Spec.Kubelet.Config[k] = runtime.RawExtension{Raw: []byte(v)}
+			}
+
+			validator := NewCgroupValidator()
+			validator.cgroupRoot = root
+			validator.containerdCgroupDriver = func(*api.NodeConfig) (string, error) {
+				return tc.containerdDriver, nil
+			}
+
+			err := validator.Validate(cfg)
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestCgroupValidatorKubeletConfigOnDisk(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           string
+		dropIns          map[string]string
+		containerdDriver string
+		wantErr          string
+	}{
+		{
+			name:             "no kubelet config uses the nodeadm defaults",
+			containerdDriver: containerd.CgroupDriverSystemd,
+		},
+		{
+			name:             "config written by nodeadm",
+			config:           `{"cgroupDriver": "systemd"}`,
+			containerdDriver: containerd.CgroupDriverSystemd,
+		},
+		{
+			name:             "config driver differs from containerd",
+			config:           `{"cgroupDriver": "cgroupfs"}`,
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErr:          "kubelet uses the cgroupfs cgroup driver but containerd uses systemd",
+		},
+		{
+			name:             "unset cgroup driver is cgroupfs",
+			config:           `{}`,
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErr:          "kubelet uses the cgroupfs cgroup driver but containerd uses systemd",
+		},
+		{
+			name:             "drop-in config sets the driver",
+			config:           `{"cgroupDriver": "cgroupfs"}`,
+			dropIns:          map[string]string{"00-nodeadm.conf": "cgroupDriver: systemd\n"},
+			containerdDriver: containerd.CgroupDriverSystemd,
+		},
+		{
+			name:             "missing cgroup root",
+			config:           `{"cgroupDriver": "systemd", "cgroupRoot": "/kube.slice"}`,
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErr:          "kubelet cgroup root /kube.slice doesn't exist in",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			root := t.TempDir()
+			fakeCgroupHierarchy(g, root, "v2")
+			configRoot := t.TempDir()
+			if tc.config != "" {
+				g.Expect(os.WriteFile(filepath.Join(configRoot, kubeletConfigFile), []byte(tc.config), 0o644)).To(Succeed())
+			}
+			if len(tc.dropIns) > 0 {
+				g.Expect(os.MkdirAll(filepath.Join(configRoot, kubeletConfigDir), 0o755)).To(Succeed())
+			}
+			for name, data := range tc.dropIns {
+				g.Expect(os.WriteFile(filepath.Join(configRoot, kubeletConfigDir, name), []byte(data), 0o644)).To(Succeed())
+			}
+
+			validator := NewCgroupValidator(WithContainerdMainConfig(true))
+			validator.cgroupRoot = root
+			validator.kubeletConfigRoot = configRoot
+			validator.containerdCgroupDriver = func(*api.NodeConfig) (string, error) {
+				return tc.containerdDriver, nil
+			}
+
+			err := validator.Validate(&api.NodeConfig{})
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestSystemdCgroupPath(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(systemdCgroupPath("/kube")).To(Equal("/kube.slice"))
+	g.Expect(systemdCgroupPath("/kube/pods")).To(Equal("/kube.slice/kube-pods.slice"))
+	g.Expect(systemdCgroupPath("/kube.slice/kube-pods.slice")).To(Equal("/kube.slice/kube-pods.slice"))
+}
@@ -29,6 +29,7 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/network"
 	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/util"
 )
@@ -39,8 +40,11 @@ const (
 	kubeletConfigDir  = "config.json.d"
 	kubeletConfigPerm = 0o644
 
-	hybridNodeLabel            = "eks.amazonaws.com/compute-type=hybrid"
-	credentialProviderLabelKey = "eks.amazonaws.com/hybrid-credential-provider"
+	// ComputeTypeLabelKey is the node label with the EKS compute type, hybrid for hybrid nodes.
+	ComputeTypeLabelKey = "eks.amazonaws.com/compute-type"
+	hybridComputeType   = "hybrid"
+	// CredentialProviderLabelKey is the node label with the credential provider used by a hybrid node.
+	CredentialProviderLabelKey = "eks.amazonaws.com/hybrid-credential-provider"
 
 	hybridProviderIdPrefix = "eks-hybrid"
 )
@@ -74,6 +78,7 @@ type kubeletConfig struct {
 	ClusterDNS               []string                         `json:"clusterDNS"`
 	ClusterDomain            string                           `json:"clusterDomain"`
 	ContainerRuntimeEndpoint string                           `json:"containerRuntimeEndpoint"`
+	ImageServiceEndpoint     string                           `json:"imageServiceEndpoint,omitempty"`
 	EvictionHard             map[string]string                `json:"evictionHard,omitempty"`
 	FeatureGates             map[string]bool                  `json:"featureGates"`
 	HairpinMode              string                           `json:"hairpinMode"`
@@ -252,13 +257,73 @@ func (ksc *kubeletConfig) withHybridCloudProvider(cfg *api.NodeConfig, flags map
 	flags["hostname-override"] = cfg.Status.Hybrid.NodeName
 }
 
+// withHybridNodeIPInterface pins the node IP to the IP of the configured node IP interface, so kubelet
+// doesn't pick the IP of another interface on multi-homed nodes.
+func (ksc *kubeletConfig) withHybridNodeIPInterface(cfg *api.NodeConfig, flags map[string]string) error {
+	if cfg.Spec.Kubelet.NodeIPInterface == "" {
+		return nil
+	}
+	nodeIP, err := network.GetNodeIP(cfg.Spec.Kubelet.Flags, "", network.NewDefaultNetwork(), network.WithNodeIPInterface(cfg.Spec.Kubelet.NodeIPInterface))
+	if err != nil {
+		return err
+	}
+	flags["node-ip"] = nodeIP.String()
+	zap.L().Info("Setup IP for node from interface", zap.String("interface", cfg.Spec.Kubelet.NodeIPInterface), zap.String("ip", nodeIP.String()))
+	return nil
+}
+
 func (ksc *kubeletConfig) withHybridNodeLabels(cfg *api.NodeConfig, flags map[string]string) {
 	var labels []string
-	labels = append(labels, hybridNodeLabel)
-	labels = append(labels, fmt.Sprintf("%s=%s", credentialProviderLabelKey, cfg.GetNodeType()))
+	for _, label := range hybridNodeLabels(cfg) {
+		labels = append(labels, fmt.Sprintf("%s=%s", label.key, label.value))
+	}
 	flags["node-labels"] = strings.Join(labels, ",")
 }
 
+type nodeLabel struct {
+	key, value string
+}
+
+// hybridNodeLabels are the labels every hybrid node registers with.
+func hybridNodeLabels(cfg *api.NodeConfig) []nodeLabel {
+	return []nodeLabel{
+		{key: ComputeTypeLabelKey, value: hybridComputeType},
+		{key: CredentialProviderLabelKey, value: string(cfg.GetNodeType())},
+	}
+}
+
+// ValidateHybridKubeletFlags rejects user kubelet flags that conflict with the cloud provider
+// and node labels nodeadm configures for hybrid nodes. User flags are passed after nodeadm's
+// so they would silently take precedence.
+func ValidateHybridKubeletFlags(cfg *api.NodeConfig) error {
+	for _, flag := range cfg.Spec.Kubelet.Flags {
+		name, value, _ := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		switch name {
+		case "cloud-provider":
+			if value != "" && value != "external" {
+				return fmt.Errorf("cloud-provider kubelet flag must be unset or external for hybrid nodes but found %s", value)
+			}
+		case "node-labels":
+			if err := validateHybridNodeLabels(cfg, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateHybridNodeLabels(cfg *api.NodeConfig, labels string) error {
+	for _, label := range strings.Split(labels, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(label), "=")
+		for _, required := range hybridNodeLabels(cfg) {
+			if key == required.key && value != required.value {
+				return fmt.Errorf("node-labels kubelet flag sets %s=%s but hybrid nodes require %s=%s", key, value, required.key, required.value)
+			}
+		}
+	}
+	return nil
+}
+
 // When the DefaultReservedResources flag is enabled, override the kubelet
 // config with reserved cgroup values on behalf of the user
 func (ksc *kubeletConfig) withDefaultReservedResources(cfg *api.NodeConfig) {
@@ -359,10 +424,14 @@ func (k *kubelet) GenerateKubeletConfig() (*kubeletConfig, error) {
 	}
 
 	kubeletConfig.withVersionToggles(kubeletVersion, k.flags)
+	kubeletConfig.ImageServiceEndpoint = k.nodeConfig.Spec.Kubelet.ImageServiceEndpoint
 
 	if k.nodeConfig.IsHybridNode() {
 		kubeletConfig.withHybridCloudProvider(k.nodeConfig, k.flags)
 		kubeletConfig.withHybridNodeLabels(k.nodeConfig, k.flags)
+		if err := kubeletConfig.withHybridNodeIPInterface(k.nodeConfig, k.flags); err != nil {
+			return nil, err
+		}
 		if err := kubeletConfig.withHybridReservedResources(); err != nil {
 			return nil, err
 		}
@@ -371,7 +440,7 @@ func (k *kubelet) GenerateKubeletConfig() (*kubeletConfig, error) {
 		// This causes pods not being able to do successful dns lookups
 		// Setting Kubelet config to point to the right resolv.conf file
 		// https://coredns.io/plugins/loop/#troubleshooting-loops-in-kubernetes-clusters
-		if system.GetOsName() == system.UbuntuOsName {
+		if system.HostOSInfo().ID == system.UbuntuOsName {
 			kubeletConfig.withResolvConf(system.UbuntuResolvConfPath)
 		}
 	} else {
@@ -559,12 +628,24 @@ func getKubeletConfigFromDisk() (*kubeletConfig, error) {
 	return &kubeletConf, nil
 }
 
+// GetCgroupDriver gets the cgroup driver from the kubelet config on disk
+func GetCgroupDriver() (string, error) {
+	kubeletConf, err := getKubeletConfigFromDisk()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get kubelet configuration from disk")
+	}
+	return kubeletConf.CgroupDriver, nil
+}
+
 // GetNodeName gets the current node name from the providerId in kubelet config
 func GetNodeName() (string, error) {
 	kubeletConf, err := getKubeletConfigFromDisk()
 	if err != nil {
 		return "", errors.Wrap(err, "failed to get kubelet configuration from disk")
 	}
+	if kubeletConf.ProviderID == nil {
+		return "", errors.New("kubelet configuration has no provider id")
+	}
 	matches := nodeNameProviderIdRegexPattern.FindStringSubmatch(*kubeletConf.ProviderID)
 	// matches have entire string, 1st match, 2nd match, etc
 	if len(matches) > 1 {
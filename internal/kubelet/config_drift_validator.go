@@ -0,0 +1,190 @@
+package kubelet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const kubeletConfigValidation = "kubelet-config-validation"
+
+// ConfigDriftValidator validates the kubelet config keeps the settings hybrid nodes rely on:
+// serverTLSBootstrap, so kubelet requests and rotates its serving certificate, and clusterDNS, so
+// pods resolve cluster services. A cgroupDriver override must also match containerd's driver, the
+// CgroupValidator validates the cgroup driver of the kubelet config on disk.
+type ConfigDriftValidator struct {
+	// configRoot is the directory of the kubelet config file and its drop-in directory.
+	configRoot string
+	// configOnDisk is whether the kubelet config on disk stays active because nodeadm won't write it.
+	configOnDisk           bool
+	containerdCgroupDriver func(cfg *api.NodeConfig) (string, error)
+}
+
+// ConfigDriftValidatorOpt allows to configure the ConfigDriftValidator.
+type ConfigDriftValidatorOpt func(*ConfigDriftValidator)
+
+// WithKubeletConfigOnDisk sets whether the kubelet config on disk stays active because nodeadm
+// won't write it. Otherwise, only the kubelet config overrides of the node config are validated,
+// since the nodeadm defaults keep these settings.
+func WithKubeletConfigOnDisk(onDisk bool) ConfigDriftValidatorOpt {
+	return func(v *ConfigDriftValidator) {
+		v.configOnDisk = onDisk
+	}
+}
+
+// NewConfigDriftValidator returns a validator for the kubelet config settings.
+func NewConfigDriftValidator(opts ...ConfigDriftValidatorOpt) *ConfigDriftValidator {
+	v := &ConfigDriftValidator{
+		configRoot: kubeletConfigRoot,
+		containerdCgroupDriver: func(cfg *api.NodeConfig) (string, error) {
+			return containerd.CgroupDriver(cfg, false)
+		},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Run validates the kubelet config settings.
+func (v *ConfigDriftValidator) Run(ctx context.Context, informer validation.Informer, cfg *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, kubeletConfigValidation, "Validating kubelet config")
+	defer func() {
+		informer.Done(ctx, kubeletConfigValidation, err)
+	}()
+	err = v.Validate(cfg)
+	return err
+}
+
+// Validate returns the errors of every kubelet config setting that drifted from what hybrid nodes
+// rely on, each with its remediation.
+func (v *ConfigDriftValidator) Validate(cfg *api.NodeConfig) error {
+	if v.configOnDisk {
+		return v.validateConfigOnDisk()
+	}
+	return v.validateConfigOverrides(cfg)
+}
+
+// driftConfig holds the kubelet config fields validated for drift, unset fields are nil.
+type driftConfig struct {
+	ServerTLSBootstrap *bool    `json:"serverTLSBootstrap"`
+	ClusterDNS         []string `json:"clusterDNS"`
+	CgroupDriver       *string  `json:"cgroupDriver"`
+}
+
+// validateConfigOverrides validates the kubelet config overrides in cfg, which nodeadm writes on
+// top of its defaults, next to the containerd config it generates.
+func (v *ConfigDriftValidator) validateConfigOverrides(cfg *api.NodeConfig) error {
+	if len(cfg.Spec.Kubelet.Config) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(cfg.Spec.Kubelet.Config)
+	if err != nil {
+		return fmt.Errorf("marshalling kubelet config overrides: %w", err)
+	}
+	var config driftConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return validation.WithRemediation(fmt.Errorf("parsing kubelet config overrides: %w", err),
+			"Ensure serverTLSBootstrap is a boolean, clusterDNS a list of IPs and cgroupDriver a string in the kubelet config of the node config.")
+	}
+
+	source := "the node config kubelet.config"
+	var errs []error
+	if config.ServerTLSBootstrap != nil && !*config.ServerTLSBootstrap {
+		errs = append(errs, serverTLSBootstrapError(source,
+			"Remove the serverTLSBootstrap override from kubelet.config in the node config or set it to true."))
+	}
+	if config.ClusterDNS != nil && len(config.ClusterDNS) == 0 {
+		errs = append(errs, clusterDNSError(source,
+			"Remove the clusterDNS override from kubelet.config in the node config so nodeadm sets it from the cluster service CIDR, "+
+				"or set it to the cluster DNS service IP."))
+	}
+	if config.CgroupDriver != nil && *config.CgroupDriver != "" {
+		containerdDriver, err := v.containerdCgroupDriver(cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading containerd cgroup driver: %w", err))
+		} else if *config.CgroupDriver != containerdDriver {
+			errs = append(errs, validation.WithRemediation(
+				fmt.Errorf("%s sets the %s cgroup driver but containerd uses %s", source, *config.CgroupDriver, containerdDriver),
+				fmt.Sprintf("Remove the cgroupDriver override from kubelet.config in the node config or set it to %s.", containerdDriver),
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateConfigOnDisk validates the kubelet config file and its drop-in configs, as kubelet
+// merges them. A node without a kubelet config has nothing to validate.
+func (v *ConfigDriftValidator) validateConfigOnDisk() error {
+	configPath := filepath.Join(v.configRoot, kubeletConfigFile)
+	dropInDir := filepath.Join(v.configRoot, kubeletConfigDir)
+	var config driftConfig
+	err := readKubeletConfig(configPath, dropInDir, &config)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return validation.WithRemediation(err, fmt.Sprintf("Ensure %s and the configs in %s are valid kubelet configs, "+
+			"or run nodeadm init without skipping the config phase to regenerate them.", configPath, dropInDir))
+	}
+
+	restart := fmt.Sprintf(" in %s, or its drop-in configs, and restart kubelet.", configPath)
+	var errs []error
+	if config.ServerTLSBootstrap == nil || !*config.ServerTLSBootstrap {
+		errs = append(errs, serverTLSBootstrapError(configPath, "Set serverTLSBootstrap to true"+restart))
+	}
+	if len(config.ClusterDNS) == 0 {
+		errs = append(errs, clusterDNSError(configPath, "Set clusterDNS to the cluster DNS service IP"+restart))
+	}
+	return errors.Join(errs...)
+}
+
+// readKubeletConfig reads the kubelet config at configPath into config, with the drop-in configs in
+// dropInDir applied on top in lexical order like kubelet does.
+func readKubeletConfig(configPath, dropInDir string, config any) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("parsing kubelet config %s: %w", configPath, err)
+	}
+
+	dropIns, err := filepath.Glob(filepath.Join(dropInDir, "*.conf"))
+	if err != nil {
+		return err
+	}
+	for _, dropIn := range dropIns {
+		data, err := os.ReadFile(dropIn)
+		if err != nil {
+			return fmt.Errorf("reading kubelet drop-in config %s: %w", dropIn, err)
+		}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("parsing kubelet drop-in config %s: %w", dropIn, err)
+		}
+	}
+	return nil
+}
+
+func serverTLSBootstrapError(source, remediation string) error {
+	return validation.WithRemediation(
+		fmt.Errorf("serverTLSBootstrap is not enabled in %s, kubelet won't request its serving certificate from the cluster nor rotate it", source),
+		remediation+" Kubelet serving certificates are rotated only when serverTLSBootstrap is true.",
+	)
+}
+
+func clusterDNSError(source, remediation string) error {
+	return validation.WithRemediation(
+		fmt.Errorf("clusterDNS is not set in %s, pods won't be able to resolve cluster services", source),
+		remediation,
+	)
+}
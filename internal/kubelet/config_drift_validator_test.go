@@ -0,0 +1,183 @@
+package kubelet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestConfigDriftValidatorOnDisk(t *testing.T) {
+	testCases := []struct {
+		name             string
+		config           string
+		dropIns          map[string]string
+		wantErrs         []string
+		wantRemediations []string
+	}{
+		{
+			name:   "config written by nodeadm",
+			config: "testdata/kubelet-config.json",
+		},
+		{
+			name: "no kubelet config",
+		},
+		{
+			name:   "drifted config",
+			config: "testdata/kubelet-config-drifted.json",
+			wantErrs: []string{
+				"serverTLSBootstrap is not enabled in",
+				"clusterDNS is not set in",
+			},
+			wantRemediations: []string{
+				"Set serverTLSBootstrap to true in",
+				"Set clusterDNS to the cluster DNS service IP in",
+			},
+		},
+		{
+			name:    "drop-in config fixes the drifted config",
+			config:  "testdata/kubelet-config-drifted.json",
+			dropIns: map[string]string{"00-nodeadm.conf": `{"serverTLSBootstrap": true, "clusterDNS": ["172.16.0.10"]}`},
+		},
+		{
+			name:   "drop-in config drifts",
+			config: "testdata/kubelet-config.json",
+			dropIns: map[string]string{
+				"00-nodeadm.conf": `{"serverTLSBootstrap": false}`,
+				"10-user.conf":    "clusterDNS: []\n",
+			},
+			wantErrs: []string{
+				"serverTLSBootstrap is not enabled in",
+				"clusterDNS is not set in",
+			},
+			wantRemediations: []string{
+				"Set serverTLSBootstrap to true in",
+				"Set clusterDNS to the cluster DNS service IP in",
+			},
+		},
+		{
+			name:             "invalid drop-in config",
+			config:           "testdata/kubelet-config.json",
+			dropIns:          map[string]string{"00-nodeadm.conf": `{"serverTLSBootstrap": "yes"}`},
+			wantErrs:         []string{"parsing kubelet drop-in config"},
+			wantRemediations: []string{"are valid kubelet configs"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			configRoot := t.TempDir()
+			if tc.config != "" {
+				data, err := os.ReadFile(tc.config)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(os.WriteFile(filepath.Join(configRoot, kubeletConfigFile), data, 0o644)).To(Succeed())
+			}
+			if len(tc.dropIns) > 0 {
+				g.Expect(os.MkdirAll(filepath.Join(configRoot, kubeletConfigDir), 0o755)).To(Succeed())
+			}
+			for name, data := range tc.dropIns {
+				g.Expect(os.WriteFile(filepath.Join(configRoot, kubeletConfigDir, name), []byte(data), 0o644)).To(Succeed())
+			}
+
+			v := NewConfigDriftValidator(WithKubeletConfigOnDisk(true))
+			v.configRoot = configRoot
+
+			err := v.Validate(&api.NodeConfig{})
+			if len(tc.wantErrs) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			errs := validation.Unwrap(err)
+			g.Expect(errs).To(HaveLen(len(tc.wantErrs)))
+			for i, e := range errs {
+				g.Expect(e.Error()).To(ContainSubstring(tc.wantErrs[i]))
+				g.Expect(validation.Remediation(e)).To(ContainSubstring(tc.wantRemediations[i]))
+			}
+		})
+	}
+}
+
+func TestConfigDriftValidatorOverrides(t *testing.T) {
+	testCases := []struct {
+		name             string
+		kubeletConfig    map[string]string
+		containerdDriver string
+		wantErrs         []string
+		wantRemediations []string
+	}{
+		{
+			name: "no overrides",
+		},
+		{
+			name:          "overrides keeping the settings",
+			kubeletConfig: map[string]string{"serverTLSBootstrap": "true", "clusterDNS": `["10.100.0.10"]`, "maxPods": "110"},
+		},
+		{
+			name:          "overrides drifting the settings",
+			kubeletConfig: map[string]string{"serverTLSBootstrap": "false", "clusterDNS": "[]"},
+			wantErrs: []string{
+				"serverTLSBootstrap is not enabled in the node config kubelet.config",
+				"clusterDNS is not set in the node config kubelet.config",
+			},
+			wantRemediations: []string{
+				"Remove the serverTLSBootstrap override from kubelet.config in the node config",
+				"Remove the clusterDNS override from kubelet.config in the node config",
+			},
+		},
+		{
+			name:             "cgroup driver override matching containerd",
+			kubeletConfig:    map[string]string{"cgroupDriver": `"cgroupfs"`},
+			containerdDriver: containerd.CgroupDriverCgroupfs,
+		},
+		{
+			name:             "cgroup driver override drifting from containerd",
+			kubeletConfig:    map[string]string{"cgroupDriver": `"cgroupfs"`},
+			containerdDriver: containerd.CgroupDriverSystemd,
+			wantErrs:         []string{"the node config kubelet.config sets the cgroupfs cgroup driver but containerd uses systemd"},
+			wantRemediations: []string{"Remove the cgroupDriver override from kubelet.config in the node config or set it to systemd."},
+		},
+		{
+			name:             "invalid override",
+			kubeletConfig:    map[string]string{"serverTLSBootstrap": `"false"`},
+			wantErrs:         []string{"parsing kubelet config overrides"},
+			wantRemediations: []string{"Ensure serverTLSBootstrap is a boolean"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cfg := &api.NodeConfig{}
+			if len(tc.kubeletConfig) > 0 {
+				cfg.Spec.Kubelet.Config = api.InlineDocument{}
+			}
+			for key, value := range tc.kubeletConfig {
+				cfg.Spec.Kubelet.Config[key] = runtime.RawExtension{Raw: []byte(value)}
+			}
+
+			v := NewConfigDriftValidator()
+			// The kubelet config on disk is rewritten by nodeadm, so it's not read.
+			v.configRoot = filepath.Join(t.TempDir(), "missing")
+			v.containerdCgroupDriver = func(*api.NodeConfig) (string, error) { return tc.containerdDriver, nil }
+
+			err := v.Validate(cfg)
+			if len(tc.wantErrs) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			errs := validation.Unwrap(err)
+			g.Expect(errs).To(HaveLen(len(tc.wantErrs)))
+			for i, e := range errs {
+				g.Expect(e.Error()).To(ContainSubstring(tc.wantErrs[i]))
+				g.Expect(validation.Remediation(e)).To(ContainSubstring(tc.wantRemediations[i]))
+			}
+		})
+	}
+}
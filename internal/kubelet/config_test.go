@@ -63,6 +63,59 @@ func TestHybridLabels(t *testing.T) {
 	assert.Equal(t, kubeletArgs["node-labels"], expectedLabels)
 }
 
+func TestValidateHybridKubeletFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		wantErr string
+	}{
+		{
+			name: "no flags",
+		},
+		{
+			name:  "external cloud provider and custom labels",
+			flags: []string{"--cloud-provider=external", "--node-labels=team=ml,topology.kubernetes.io/zone=rack-1"},
+		},
+		{
+			name:  "unset cloud provider and matching required labels",
+			flags: []string{"--cloud-provider=", "--node-labels=eks.amazonaws.com/compute-type=hybrid,eks.amazonaws.com/hybrid-credential-provider=ssm"},
+		},
+		{
+			name:    "aws cloud provider",
+			flags:   []string{"--cloud-provider=aws"},
+			wantErr: "cloud-provider kubelet flag must be unset or external for hybrid nodes but found aws",
+		},
+		{
+			name:    "conflicting compute type",
+			flags:   []string{"--node-labels=team=ml", "--node-labels=eks.amazonaws.com/compute-type=ec2"},
+			wantErr: "node-labels kubelet flag sets eks.amazonaws.com/compute-type=ec2 but hybrid nodes require eks.amazonaws.com/compute-type=hybrid",
+		},
+		{
+			name:    "conflicting credential provider",
+			flags:   []string{"--node-labels=eks.amazonaws.com/hybrid-credential-provider=iam-ra"},
+			wantErr: "node-labels kubelet flag sets eks.amazonaws.com/hybrid-credential-provider=iam-ra but hybrid nodes require eks.amazonaws.com/hybrid-credential-provider=ssm",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeConfig := api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Hybrid: &api.HybridOptions{
+						SSM: &api.SSM{ActivationCode: "code", ActivationID: "id"},
+					},
+					Kubelet: api.KubeletOptions{Flags: tc.flags},
+				},
+			}
+			err := ValidateHybridKubeletFlags(&nodeConfig)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestResolvConf(t *testing.T) {
 	resolvConfPath := "/dummy/path/to/resolv.conf"
 	kubeletConfig := defaultKubeletSubConfig()
@@ -76,6 +76,7 @@ func (k *kubelet) Configure(ctx context.Context) error {
 
 	if k.validationRunner != nil {
 		k.validationRunner.Register(
+			validation.New(imageCredentialProviderValidation, NewImageCredentialProviderValidator().Run),
 			validation.New(kubernetesAuthenticationValidation, kubernetes.NewAPIServerValidator(New()).MakeAuthenticatedRequest),
 		)
 		if err := k.validationRunner.Sequentially(ctx, k.nodeConfig); err != nil {
@@ -30,11 +30,9 @@ const (
 var imageCredentialProviderConfigPath = path.Join(imageCredentialProviderRoot, imageCredentialProviderConfig)
 
 func (k *kubelet) writeImageCredentialProviderConfig() error {
-	// fallback default for image credential provider binary if not overridden
-	ecrCredentialProviderBinPath := path.Join(imageCredentialProviderRoot, "ecr-credential-provider")
-	if binPath, set := os.LookupEnv(ecrCredentialProviderBinPathEnvironmentName); set {
-		zap.L().Info("picked up image credential provider binary path from environment", zap.String("bin-path", binPath))
-		ecrCredentialProviderBinPath = binPath
+	ecrCredentialProviderBinPath := resolveEcrCredentialProviderBinPath()
+	if _, set := os.LookupEnv(ecrCredentialProviderBinPathEnvironmentName); set {
+		zap.L().Info("picked up image credential provider binary path from environment", zap.String("bin-path", ecrCredentialProviderBinPath))
 	}
 	if err := ensureCredentialProviderBinaryExists(ecrCredentialProviderBinPath); err != nil {
 		return err
@@ -51,6 +49,16 @@ func (k *kubelet) writeImageCredentialProviderConfig() error {
 	return util.WriteFileWithDir(imageCredentialProviderConfigPath, credentialProviderConfig, imageCredentialProviderPerm)
 }
 
+// resolveEcrCredentialProviderBinPath returns the image credential provider binary path,
+// overridden by the environment.
+func resolveEcrCredentialProviderBinPath() string {
+	if binPath, set := os.LookupEnv(ecrCredentialProviderBinPathEnvironmentName); set {
+		return binPath
+	}
+	// fallback default for image credential provider binary if not overridden
+	return path.Join(imageCredentialProviderRoot, "ecr-credential-provider")
+}
+
 func generateImageCredentialProviderConfig(cfg *api.NodeConfig, ecrCredentialProviderBinPath string, kubeletCredentialProviderAwsConfig CredentialProviderAwsConfig) ([]byte, error) {
 	configApiVersion := "kubelet.config.k8s.io/v1"
 	providerApiVersion := "credentialprovider.kubelet.k8s.io/v1"
@@ -0,0 +1,117 @@
+package kubelet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	config "k8s.io/kubelet/config/v1"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const imageCredentialProviderValidation = "image-credential-provider-validation"
+
+var (
+	supportedCredentialProviderConfigAPIVersions = []string{"kubelet.config.k8s.io/v1", "kubelet.config.k8s.io/v1beta1"}
+	supportedCredentialProviderAPIVersions       = []string{"credentialprovider.kubelet.k8s.io/v1", "credentialprovider.kubelet.k8s.io/v1beta1"}
+)
+
+// ImageCredentialProviderValidator validates the image credential provider config
+// kubelet is configured with references an installed provider binary with a supported apiVersion.
+type ImageCredentialProviderValidator struct {
+	configPath string
+	binDir     string
+}
+
+// ImageCredentialProviderValidatorOpt allows to configure the ImageCredentialProviderValidator.
+type ImageCredentialProviderValidatorOpt func(*ImageCredentialProviderValidator)
+
+// WithImageCredentialProviderPaths overrides the config path and the directory the provider binaries are looked up in.
+func WithImageCredentialProviderPaths(configPath, binDir string) ImageCredentialProviderValidatorOpt {
+	return func(v *ImageCredentialProviderValidator) {
+		v.configPath = configPath
+		v.binDir = binDir
+	}
+}
+
+// NewImageCredentialProviderValidator returns a validator for the image credential provider config written by nodeadm.
+func NewImageCredentialProviderValidator(opts ...ImageCredentialProviderValidatorOpt) *ImageCredentialProviderValidator {
+	v := &ImageCredentialProviderValidator{
+		configPath: imageCredentialProviderConfigPath,
+		binDir:     filepath.Dir(resolveEcrCredentialProviderBinPath()),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Run validates the image credential provider config.
+func (v *ImageCredentialProviderValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, imageCredentialProviderValidation, "Validating image credential provider config")
+	defer func() {
+		informer.Done(ctx, imageCredentialProviderValidation, err)
+	}()
+	err = v.Validate()
+	return err
+}
+
+// Validate checks every provider in the config has an executable binary in the
+// provider bin dir and that the config and the providers use supported apiVersions.
+func (v *ImageCredentialProviderValidator) Validate() error {
+	content, err := os.ReadFile(v.configPath)
+	if err != nil {
+		return validation.WithRemediation(
+			fmt.Errorf("reading image credential provider config %s: %w", v.configPath, err),
+			"Run 'nodeadm init' to write the image credential provider config.")
+	}
+
+	var providerConfig config.CredentialProviderConfig
+	if err := json.Unmarshal(content, &providerConfig); err != nil {
+		return validation.WithRemediation(
+			fmt.Errorf("parsing image credential provider config %s: %w", v.configPath, err),
+			"Run 'nodeadm init' to regenerate the image credential provider config.")
+	}
+
+	if !slices.Contains(supportedCredentialProviderConfigAPIVersions, providerConfig.APIVersion) {
+		return validation.WithRemediation(
+			fmt.Errorf("image credential provider config %s has unsupported apiVersion %q", v.configPath, providerConfig.APIVersion),
+			fmt.Sprintf("Set the config apiVersion to one of [%s].", strings.Join(supportedCredentialProviderConfigAPIVersions, ", ")))
+	}
+
+	if len(providerConfig.Providers) == 0 {
+		return validation.WithRemediation(
+			fmt.Errorf("image credential provider config %s doesn't configure any provider", v.configPath),
+			"Run 'nodeadm init' to regenerate the image credential provider config.")
+	}
+
+	for _, provider := range providerConfig.Providers {
+		if !slices.Contains(supportedCredentialProviderAPIVersions, provider.APIVersion) {
+			return validation.WithRemediation(
+				fmt.Errorf("image credential provider %s has unsupported apiVersion %q", provider.Name, provider.APIVersion),
+				fmt.Sprintf("Set the provider apiVersion to one of [%s].", strings.Join(supportedCredentialProviderAPIVersions, ", ")))
+		}
+
+		binPath := filepath.Join(v.binDir, provider.Name)
+		info, err := os.Stat(binPath)
+		if err != nil {
+			return validation.WithRemediation(
+				fmt.Errorf("image credential provider %s binary not found at %s: %w", provider.Name, binPath, err),
+				"Run 'nodeadm install' to install the image credential provider, or set "+ecrCredentialProviderBinPathEnvironmentName+" to the installed binary.")
+		}
+		if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+			return validation.WithRemediation(
+				fmt.Errorf("image credential provider %s binary at %s is not executable", provider.Name, binPath),
+				fmt.Sprintf("Run 'sudo chmod +x %s'.", binPath))
+		}
+	}
+
+	return nil
+}
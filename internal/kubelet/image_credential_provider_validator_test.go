@@ -0,0 +1,110 @@
+package kubelet
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestImageCredentialProviderValidatorValidate(t *testing.T) {
+	tests := []struct {
+		name                   string
+		configAPIVersion       string
+		providerAPIVersion     string
+		binaryMode             os.FileMode
+		noBinary               bool
+		wantErr                string
+		wantRemediationContain string
+	}{
+		{
+			name:               "valid config",
+			configAPIVersion:   "kubelet.config.k8s.io/v1",
+			providerAPIVersion: "credentialprovider.kubelet.k8s.io/v1",
+			binaryMode:         0o755,
+		},
+		{
+			name:               "v1beta1 provider",
+			configAPIVersion:   "kubelet.config.k8s.io/v1",
+			providerAPIVersion: "credentialprovider.kubelet.k8s.io/v1beta1",
+			binaryMode:         0o755,
+		},
+		{
+			name:                   "missing binary",
+			configAPIVersion:       "kubelet.config.k8s.io/v1",
+			providerAPIVersion:     "credentialprovider.kubelet.k8s.io/v1",
+			noBinary:               true,
+			wantErr:                "image credential provider ecr-credential-provider binary not found",
+			wantRemediationContain: "nodeadm install",
+		},
+		{
+			name:                   "binary not executable",
+			configAPIVersion:       "kubelet.config.k8s.io/v1",
+			providerAPIVersion:     "credentialprovider.kubelet.k8s.io/v1",
+			binaryMode:             0o644,
+			wantErr:                "is not executable",
+			wantRemediationContain: "chmod +x",
+		},
+		{
+			name:                   "unsupported provider apiVersion",
+			configAPIVersion:       "kubelet.config.k8s.io/v1",
+			providerAPIVersion:     "credentialprovider.kubelet.k8s.io/v1alpha1",
+			binaryMode:             0o755,
+			wantErr:                `image credential provider ecr-credential-provider has unsupported apiVersion "credentialprovider.kubelet.k8s.io/v1alpha1"`,
+			wantRemediationContain: "credentialprovider.kubelet.k8s.io/v1",
+		},
+		{
+			name:                   "unsupported config apiVersion",
+			configAPIVersion:       "kubelet.config.k8s.io/v1alpha1",
+			providerAPIVersion:     "credentialprovider.kubelet.k8s.io/v1",
+			binaryMode:             0o755,
+			wantErr:                `has unsupported apiVersion "kubelet.config.k8s.io/v1alpha1"`,
+			wantRemediationContain: "kubelet.config.k8s.io/v1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			binDir := t.TempDir()
+			binPath := filepath.Join(binDir, "ecr-credential-provider")
+			if !tc.noBinary {
+				assert.NoError(t, os.WriteFile(binPath, []byte("#!/bin/sh"), tc.binaryMode))
+			}
+
+			data, err := generateImageCredentialProviderConfig(&api.NodeConfig{}, binPath, CredentialProviderAwsConfig{})
+			assert.NoError(t, err)
+			content := string(data)
+			content = replaceOnce(t, content, `"apiVersion": "kubelet.config.k8s.io/v1"`, `"apiVersion": "`+tc.configAPIVersion+`"`)
+			content = replaceOnce(t, content, `"apiVersion": "credentialprovider.kubelet.k8s.io/v1"`, `"apiVersion": "`+tc.providerAPIVersion+`"`)
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+
+			err = NewImageCredentialProviderValidator(WithImageCredentialProviderPaths(configPath, binDir)).Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+			assert.Contains(t, validation.Remediation(err), tc.wantRemediationContain)
+		})
+	}
+}
+
+func TestImageCredentialProviderValidatorMissingConfig(t *testing.T) {
+	err := NewImageCredentialProviderValidator(
+		WithImageCredentialProviderPaths(filepath.Join(t.TempDir(), "config.json"), t.TempDir()),
+	).Validate()
+	assert.ErrorContains(t, err, "reading image credential provider config")
+	assert.Contains(t, validation.Remediation(err), "nodeadm init")
+}
+
+func replaceOnce(t *testing.T, s, old, new string) string {
+	t.Helper()
+	before, after, found := strings.Cut(s, old)
+	assert.True(t, found, "%q not found", old)
+	return before + new + after
+}
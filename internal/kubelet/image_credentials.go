@@ -0,0 +1,126 @@
+package kubelet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	config "k8s.io/kubelet/config/v1"
+	credentialprovider "k8s.io/kubelet/pkg/apis/credentialprovider/v1"
+)
+
+// ImageCredentials are the registry credentials returned by an image credential provider.
+type ImageCredentials struct {
+	Provider string
+	Username string
+	Password string
+}
+
+// GetImageCredentials runs the image credential provider kubelet would use for image,
+// following the kubelet exec plugin protocol, and returns the credentials it provides.
+func GetImageCredentials(ctx context.Context, image string) (*ImageCredentials, error) {
+	return getImageCredentials(ctx, imageCredentialProviderConfigPath, filepath.Dir(resolveEcrCredentialProviderBinPath()), image)
+}
+
+func getImageCredentials(ctx context.Context, configPath, binDir, image string) (*ImageCredentials, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading image credential provider config %s: %w", configPath, err)
+	}
+	var providerConfig config.CredentialProviderConfig
+	if err := json.Unmarshal(content, &providerConfig); err != nil {
+		return nil, fmt.Errorf("parsing image credential provider config %s: %w", configPath, err)
+	}
+
+	registry := imageRegistry(image)
+	for _, provider := range providerConfig.Providers {
+		if !matchesAny(registry, provider.MatchImages) {
+			continue
+		}
+		auth, err := execCredentialProvider(ctx, filepath.Join(binDir, provider.Name), provider, image)
+		if err != nil {
+			return nil, fmt.Errorf("getting credentials for %s from image credential provider %s: %w", image, provider.Name, err)
+		}
+		return &ImageCredentials{
+			Provider: provider.Name,
+			Username: auth.Username,
+			Password: auth.Password,
+		}, nil
+	}
+	return nil, fmt.Errorf("no image credential provider in %s matches image %s", configPath, image)
+}
+
+func execCredentialProvider(ctx context.Context, binPath string, provider config.CredentialProvider, image string) (*credentialprovider.AuthConfig, error) {
+	request, err := json.Marshal(credentialprovider.CredentialProviderRequest{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: provider.APIVersion,
+			Kind:       "CredentialProviderRequest",
+		},
+		Image: image,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshalling credential provider request: %w", err)
+	}
+
+	// #nosec G204 // the binary and args come from the credential provider config kubelet runs
+	cmd := exec.CommandContext(ctx, binPath, provider.Args...)
+	cmd.Env = os.Environ()
+	for _, env := range provider.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	cmd.Stdin = bytes.NewReader(request)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response credentialprovider.CredentialProviderResponse
+	if err := json.Unmarshal(out, &response); err != nil {
+		return nil, fmt.Errorf("parsing credential provider response: %w", err)
+	}
+	registry := imageRegistry(image)
+	for pattern, auth := range response.Auth {
+		if matchesAny(registry, []string{imageRegistry(pattern)}) {
+			return &auth, nil
+		}
+	}
+	return nil, fmt.Errorf("credential provider response has no credentials for %s", registry)
+}
+
+// imageRegistry returns the registry host of an image reference.
+func imageRegistry(image string) string {
+	registry, _, _ := strings.Cut(image, "/")
+	return registry
+}
+
+// matchesAny reports if the registry matches one of the glob patterns,
+// where a wildcard matches a single domain label like kubelet matchImages.
+func matchesAny(registry string, patterns []string) bool {
+	registryLabels := strings.Split(registry, ".")
+	for _, pattern := range patterns {
+		patternLabels := strings.Split(imageRegistry(pattern), ".")
+		if len(patternLabels) != len(registryLabels) {
+			continue
+		}
+		matched := true
+		for i := range patternLabels {
+			if ok, err := path.Match(patternLabels[i], registryLabels[i]); err != nil || !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
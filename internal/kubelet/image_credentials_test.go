@@ -0,0 +1,80 @@
+package kubelet
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+)
+
+const ecrImage = "602401143452.dkr.ecr.us-west-2.amazonaws.com/eks/pause:3.5"
+
+func TestGetImageCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		image        string
+		wantUsername string
+		wantPassword string
+		wantErr      string
+	}{
+		{
+			name: "provider returns credentials",
+			provider: `#!/bin/sh
+read request
+echo '{"apiVersion":"credentialprovider.kubelet.k8s.io/v1","kind":"CredentialProviderResponse","cacheKeyType":"Registry","auth":{"*.dkr.ecr.*.amazonaws.com":{"username":"AWS","password":"token"}}}'
+`,
+			image:        ecrImage,
+			wantUsername: "AWS",
+			wantPassword: "token",
+		},
+		{
+			name: "provider fails",
+			provider: `#!/bin/sh
+echo "no EC2 IMDS role found" >&2
+exit 1
+`,
+			image:   ecrImage,
+			wantErr: "getting credentials for " + ecrImage + " from image credential provider ecr-credential-provider: exit status 1: no EC2 IMDS role found",
+		},
+		{
+			name:     "image not matched by any provider",
+			provider: "#!/bin/sh\n",
+			image:    "public.ecr.aws/eks/pause:3.5",
+			wantErr:  "no image credential provider",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			binDir := t.TempDir()
+			binPath := filepath.Join(binDir, "ecr-credential-provider")
+			assert.NoError(t, os.WriteFile(binPath, []byte(tc.provider), 0o755))
+			data, err := generateImageCredentialProviderConfig(&api.NodeConfig{}, binPath, CredentialProviderAwsConfig{})
+			assert.NoError(t, err)
+			configPath := filepath.Join(t.TempDir(), "config.json")
+			assert.NoError(t, os.WriteFile(configPath, data, 0o644))
+
+			credentials, err := getImageCredentials(context.Background(), configPath, binDir, tc.image)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "ecr-credential-provider", credentials.Provider)
+			assert.Equal(t, tc.wantUsername, credentials.Username)
+			assert.Equal(t, tc.wantPassword, credentials.Password)
+		})
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	patterns := []string{"*.dkr.ecr.*.amazonaws.com", "*.dkr.ecr.*.amazonaws.com.cn"}
+	assert.True(t, matchesAny("602401143452.dkr.ecr.us-west-2.amazonaws.com", patterns))
+	assert.True(t, matchesAny("602401143452.dkr.ecr.cn-north-1.amazonaws.com.cn", patterns))
+	assert.False(t, matchesAny("public.ecr.aws", patterns))
+	assert.False(t, matchesAny("a.b.dkr.ecr.us-west-2.amazonaws.com", patterns))
+}
@@ -42,6 +42,13 @@ type InstallOptions struct {
 	Tracker     *tracker.Tracker
 	Source      Source
 	Logger      *zap.Logger
+	// ExpectedChecksum optionally pins the sha256 digest the kubelet binary must match.
+	// If empty, the checksum published by the source is used.
+	ExpectedChecksum []byte
+	// ExpectedVersion is the Kubernetes version the source resolved, like 1.31.13. When set, the
+	// installed binary must report it with --version, so a stale binary left by a failed replace
+	// fails the install. The verified version is recorded in the tracker.
+	ExpectedVersion string
 }
 
 // Install installs kubelet at BinPath and installs a systemd unit file at UnitPath. The systemd
@@ -51,6 +58,14 @@ func Install(ctx context.Context, opts InstallOptions) error {
 		return errors.Wrap(err, "installing kubelet")
 	}
 
+	if opts.ExpectedVersion != "" {
+		version, err := verifyBinaryVersion(filepath.Join(opts.InstallRoot, BinPath), opts.ExpectedVersion)
+		if err != nil {
+			return errors.Wrap(err, "verifying installed kubelet version")
+		}
+		opts.Tracker.SetKubeletVersion(version)
+	}
+
 	if err := installSystemdUnit(filepath.Join(opts.InstallRoot, UnitPath)); err != nil {
 		return errors.Wrap(err, "installing systemd unit")
 	}
@@ -82,12 +97,12 @@ func downloadFileTo(ctx context.Context, opts InstallOptions) error {
 	}
 	defer kubelet.Close()
 
-	if err := artifact.InstallFile(filepath.Join(opts.InstallRoot, BinPath), kubelet, artifactFilePerms); err != nil {
-		return errors.Wrap(err, "installing kubelet")
+	expectedChecksum := opts.ExpectedChecksum
+	if len(expectedChecksum) == 0 {
+		expectedChecksum = kubelet.ExpectedChecksum()
 	}
-
-	if !kubelet.VerifyChecksum() {
-		return errors.Errorf("kubelet checksum mismatch: %v", artifact.NewChecksumError(kubelet))
+	if err := artifact.InstallFileWithChecksum(filepath.Join(opts.InstallRoot, BinPath), kubelet, artifactFilePerms, expectedChecksum); err != nil {
+		return errors.Wrap(err, "installing kubelet")
 	}
 
 	return nil
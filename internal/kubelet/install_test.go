@@ -2,13 +2,18 @@ package kubelet_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/artifact"
 	"github.com/aws/eks-hybrid/internal/aws"
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/test"
@@ -130,3 +135,83 @@ func TestInstall(t *testing.T) {
 		VerifyFilePaths: []string{kubelet.BinPath, kubelet.UnitPath},
 	})
 }
+
+// scriptSource serves a shell script as the kubelet binary.
+type scriptSource struct {
+	script string
+}
+
+func (s scriptSource) GetKubelet(context.Context) (artifact.Source, error) {
+	checksum := fmt.Sprintf("%x  kubelet", sha256.Sum256([]byte(s.script)))
+	return artifact.WithChecksum(io.NopCloser(strings.NewReader(s.script)), sha256.New(), []byte(checksum))
+}
+
+func TestInstallVerifiesVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		script          string
+		expectedVersion string
+		wantVersion     string
+		wantErr         string
+	}{
+		{
+			name:            "matching version",
+			script:          "#!/bin/sh\necho 'Kubernetes v1.31.13-eks-5e0fdde'\n",
+			expectedVersion: "1.31.13",
+			wantVersion:     "v1.31.13",
+		},
+		{
+			name:            "matching version with v prefix",
+			script:          "#!/bin/sh\necho 'Kubernetes v1.31.13-eks-5e0fdde'\n",
+			expectedVersion: "v1.31.13",
+			wantVersion:     "v1.31.13",
+		},
+		{
+			name:            "mismatched version",
+			script:          "#!/bin/sh\necho 'Kubernetes v1.30.9-eks-5e0fdde'\n",
+			expectedVersion: "1.31.13",
+			wantErr:         "reports version v1.30.9, expected v1.31.13",
+		},
+		{
+			name:            "no version in output",
+			script:          "#!/bin/sh\necho 'unknown'\n",
+			expectedVersion: "1.31.13",
+			wantErr:         `no version found in`,
+		},
+		{
+			name:            "binary fails",
+			script:          "#!/bin/sh\nexit 1\n",
+			expectedVersion: "1.31.13",
+			wantErr:         "--version: exit status 1",
+		},
+		{
+			name:   "no expected version",
+			script: "#!/bin/sh\nexit 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tr := &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}}
+
+			err := kubelet.Install(context.Background(), kubelet.InstallOptions{
+				InstallRoot:     t.TempDir(),
+				Tracker:         tr,
+				Source:          scriptSource{script: tt.script},
+				Logger:          zap.NewNop(),
+				ExpectedVersion: tt.expectedVersion,
+			})
+
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				g.Expect(err).To(MatchError(ContainSubstring("verifying installed kubelet version")))
+				g.Expect(tr.Artifacts.Kubelet).To(BeFalse())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(tr.Artifacts.Kubelet).To(BeTrue())
+			g.Expect(tr.Artifacts.KubeletVersion).To(Equal(tt.wantVersion))
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
@@ -119,7 +120,9 @@ func generateKubeconfig(cfg *api.NodeConfig) ([]byte, error) {
 }
 
 type KubeClientOptions struct {
-	awsEnvVars map[string]string
+	awsEnvVars     map[string]string
+	kubeconfigPath string
+	context        string
 }
 
 type KubeClientOption func(*KubeClientOptions)
@@ -130,21 +133,52 @@ func WithAwsEnvironmentVariables(envVars map[string]string) KubeClientOption {
 	}
 }
 
+// WithKubeconfigPath sets the kubeconfig file used to build the client.
+// Defaults to the kubelet kubeconfig.
+func WithKubeconfigPath(path string) KubeClientOption {
+	return func(o *KubeClientOptions) {
+		o.kubeconfigPath = path
+	}
+}
+
+// WithKubeconfigContext sets the kubeconfig context used to build the client.
+// Defaults to the kubeconfig's current context.
+func WithKubeconfigContext(context string) KubeClientOption {
+	return func(o *KubeClientOptions) {
+		o.context = context
+	}
+}
+
 // GetKubeClientFromKubeConfig gets kubernetes client from kubeconfig on the disk
 func GetKubeClientFromKubeConfig(opts ...KubeClientOption) (kubernetes.Interface, error) {
-	options := &KubeClientOptions{}
+	restConfig, err := restConfigFromKubeconfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
+
+func restConfigFromKubeconfig(opts ...KubeClientOption) (*rest.Config, error) {
+	options := &KubeClientOptions{
+		kubeconfigPath: KubeconfigPath(),
+	}
 	for _, opt := range opts {
 		opt(options)
 	}
 
-	// Use the current context in the kubeconfig file
-	config, err := clientcmd.LoadFromFile(KubeconfigPath())
+	config, err := clientcmd.LoadFromFile(options.kubeconfigPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "loading kubeconfig")
 	}
 
+	if options.context != "" {
+		if _, ok := config.Contexts[options.context]; !ok {
+			return nil, errors.Errorf("context %s not found in kubeconfig %s", options.context, options.kubeconfigPath)
+		}
+	}
+
 	// Apply AWS environment variables if provided
-	if len(options.awsEnvVars) > 0 {
+	if authInfo, ok := config.AuthInfos["kubelet"]; ok && authInfo.Exec != nil && len(options.awsEnvVars) > 0 {
 		envVars := make([]clientcmdapi.ExecEnvVar, 0, len(options.awsEnvVars))
 		for name, value := range options.awsEnvVars {
 			envVars = append(envVars, clientcmdapi.ExecEnvVar{
@@ -152,16 +186,17 @@ func GetKubeClientFromKubeConfig(opts ...KubeClientOption) (kubernetes.Interface
 				Value: value,
 			})
 		}
-		config.AuthInfos["kubelet"].Exec.Env = append(config.AuthInfos["kubelet"].Exec.Env, envVars...)
+		authInfo.Exec.Env = append(authInfo.Exec.Env, envVars...)
 	}
 
-	clientConfig := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{})
+	// Use the current context in the kubeconfig file unless one is provided
+	clientConfig := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{CurrentContext: options.context})
 	restConfig, err := clientConfig.ClientConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "building config from kubeconfig")
 	}
 
-	return kubernetes.NewForConfig(restConfig)
+	return restConfig, nil
 }
 
 // KubeconfigPath returns the path to the kubeconfig file used by the kubelet.
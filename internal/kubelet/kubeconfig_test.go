@@ -0,0 +1,71 @@
+package kubelet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiContextKubeconfig = "testdata/multi-context-kubeconfig.yaml"
+
+func TestRestConfigFromKubeconfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []KubeClientOption
+		wantHost  string
+		wantToken string
+		wantErr   string
+	}{
+		{
+			name:      "current context",
+			opts:      []KubeClientOption{WithKubeconfigPath(multiContextKubeconfig)},
+			wantHost:  "https://hybrid.example.com",
+			wantToken: "kubelet-token",
+		},
+		{
+			name:      "explicit context",
+			opts:      []KubeClientOption{WithKubeconfigPath(multiContextKubeconfig), WithKubeconfigContext("other")},
+			wantHost:  "https://other.example.com",
+			wantToken: "admin-token",
+		},
+		{
+			name:    "missing context",
+			opts:    []KubeClientOption{WithKubeconfigPath(multiContextKubeconfig), WithKubeconfigContext("missing")},
+			wantErr: "context missing not found in kubeconfig testdata/multi-context-kubeconfig.yaml",
+		},
+		{
+			name:    "missing kubeconfig",
+			opts:    []KubeClientOption{WithKubeconfigPath("testdata/missing-kubeconfig.yaml")},
+			wantErr: "loading kubeconfig",
+		},
+		{
+			name: "aws env vars are ignored without kubelet exec auth",
+			opts: []KubeClientOption{
+				WithKubeconfigPath(multiContextKubeconfig),
+				WithAwsEnvironmentVariables(map[string]string{"AWS_SHARED_CREDENTIALS_FILE": "/creds"}),
+			},
+			wantHost:  "https://hybrid.example.com",
+			wantToken: "kubelet-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := restConfigFromKubeconfig(tt.opts...)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHost, config.Host)
+			assert.Equal(t, tt.wantToken, config.BearerToken)
+		})
+	}
+}
+
+func TestGetKubeClientFromKubeConfigWithContext(t *testing.T) {
+	client, err := GetKubeClientFromKubeConfig(WithKubeconfigPath(multiContextKubeconfig), WithKubeconfigContext("other"))
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+}
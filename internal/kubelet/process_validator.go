@@ -0,0 +1,139 @@
+package kubelet
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	kubeletProcessValidation = "kubelet-process-validation"
+	kubeletUnit              = KubeletDaemonName + ".service"
+	procRoot                 = "/proc"
+)
+
+// Process is a process running on the host.
+type Process struct {
+	PID int
+	// Unit is the systemd unit that owns the process, empty if it's not part of a service.
+	Unit string
+}
+
+// ProcessProber finds the kubelet processes running on the host.
+type ProcessProber interface {
+	KubeletProcesses() ([]Process, error)
+}
+
+// ProcessValidator validates before init that no kubelet is running outside of the kubelet
+// systemd unit, which would hold the kubelet ports and conflict with the kubelet started by nodeadm.
+type ProcessValidator struct {
+	prober ProcessProber
+}
+
+// NewProcessValidator returns a validator for conflicting kubelet processes.
+func NewProcessValidator(prober ProcessProber) *ProcessValidator {
+	return &ProcessValidator{prober: prober}
+}
+
+// NewProcProber returns a ProcessProber that reads the processes from /proc.
+func NewProcProber() ProcessProber {
+	return procProber{root: procRoot}
+}
+
+// Run validates there are no conflicting kubelet processes.
+func (v *ProcessValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, kubeletProcessValidation, "Validating no other kubelet is running")
+	defer func() {
+		informer.Done(ctx, kubeletProcessValidation, err)
+	}()
+	err = v.Validate()
+	return err
+}
+
+// Validate returns an error if a kubelet process not owned by the kubelet unit is running.
+func (v *ProcessValidator) Validate() error {
+	processes, err := v.prober.KubeletProcesses()
+	if err != nil {
+		return fmt.Errorf("listing kubelet processes: %w", err)
+	}
+
+	var conflicts []string
+	var pids []string
+	for _, p := range processes {
+		if p.Unit == kubeletUnit {
+			// The kubelet nodeadm manages, init restarts it.
+			continue
+		}
+		owner := "no systemd unit"
+		if p.Unit != "" {
+			owner = "unit " + p.Unit
+		}
+		conflicts = append(conflicts, fmt.Sprintf("pid %d (%s)", p.PID, owner))
+		pids = append(pids, strconv.Itoa(p.PID))
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return validation.WithRemediation(
+		fmt.Errorf("kubelet is already running outside of the %s unit: %s", kubeletUnit, strings.Join(conflicts, ", ")),
+		fmt.Sprintf("Stop the other kubelet before running init, for example with 'systemctl stop <unit>' or 'kill %s'. "+
+			"Otherwise the kubelet started by nodeadm fails with address already in use errors.", strings.Join(pids, " ")),
+	)
+}
+
+// procProber finds the kubelet processes by their command name and reads their systemd unit from their cgroup.
+type procProber struct {
+	root string
+}
+
+func (p procProber) KubeletProcesses() ([]Process, error) {
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		// Processes can exit while reading them, so read errors skip the process.
+		comm, err := os.ReadFile(filepath.Join(p.root, entry.Name(), "comm"))
+		if err != nil || strings.TrimSpace(string(comm)) != KubeletDaemonName {
+			continue
+		}
+		cgroup, err := os.ReadFile(filepath.Join(p.root, entry.Name(), "cgroup"))
+		if err != nil {
+			continue
+		}
+		processes = append(processes, Process{PID: pid, Unit: unitFromCgroup(string(cgroup))})
+	}
+	return processes, nil
+}
+
+// unitFromCgroup returns the systemd service a process belongs to from the content of
+// /proc/<pid>/cgroup, for example kubelet.service for 0::/system.slice/kubelet.service.
+func unitFromCgroup(cgroup string) string {
+	for _, line := range strings.Split(cgroup, "\n") {
+		// Lines are hierarchy-ID:controllers:path, with name=systemd holding the unit on cgroup v1.
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || (parts[0] != "0" && parts[1] != "name=systemd") {
+			continue
+		}
+		for _, elem := range strings.Split(parts[2], "/") {
+			if strings.HasSuffix(elem, ".service") {
+				return elem
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package kubelet
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakeProcessProber struct {
+	processes []Process
+	err       error
+}
+
+func (f fakeProcessProber) KubeletProcesses() ([]Process, error) {
+	return f.processes, f.err
+}
+
+func TestProcessValidator(t *testing.T) {
+	testCases := []struct {
+		name    string
+		prober  fakeProcessProber
+		wantErr string
+	}{
+		{
+			name: "no kubelet running",
+		},
+		{
+			name:   "kubelet owned by the kubelet unit",
+			prober: fakeProcessProber{processes: []Process{{PID: 42, Unit: "kubelet.service"}}},
+		},
+		{
+			name:    "kubelet started by hand",
+			prober:  fakeProcessProber{processes: []Process{{PID: 42}}},
+			wantErr: "kubelet is already running outside of the kubelet.service unit: pid 42 (no systemd unit)",
+		},
+		{
+			name: "kubelet owned by another unit",
+			prober: fakeProcessProber{processes: []Process{
+				{PID: 42, Unit: "kubelet.service"},
+				{PID: 43, Unit: "k3s.service"},
+			}},
+			wantErr: "kubelet is already running outside of the kubelet.service unit: pid 43 (unit k3s.service)",
+		},
+		{
+			name:    "prober error",
+			prober:  fakeProcessProber{err: errors.New("permission denied")},
+			wantErr: "listing kubelet processes: permission denied",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := NewProcessValidator(tc.prober).Validate()
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tc.wantErr))
+		})
+	}
+}
+
+func TestProcessValidatorRemediation(t *testing.T) {
+	g := NewWithT(t)
+	err := NewProcessValidator(fakeProcessProber{processes: []Process{{PID: 42}, {PID: 43}}}).Validate()
+	g.Expect(validation.Remediation(err)).To(ContainSubstring("kill 42 43"))
+}
+
+func TestProcProber(t *testing.T) {
+	g := NewWithT(t)
+	root := t.TempDir()
+	writeProc := func(pid, comm, cgroup string) {
+		g.Expect(os.MkdirAll(filepath.Join(root, pid), 0o755)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(root, pid, "comm"), []byte(comm+"\n"), 0o644)).To(Succeed())
+		g.Expect(os.WriteFile(filepath.Join(root, pid, "cgroup"), []byte(cgroup), 0o644)).To(Succeed())
+	}
+	writeProc("10", "kubelet", "0::/system.slice/kubelet.service\n")
+	writeProc("11", "kubelet", "12:cpu:/\n1:name=systemd:/user.slice/user-1000.slice/session-3.scope\n")
+	writeProc("12", "containerd", "0::/system.slice/containerd.service\n")
+	g.Expect(os.MkdirAll(filepath.Join(root, "self"), 0o755)).To(Succeed())
+
+	processes, err := procProber{root: root}.KubeletProcesses()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(processes).To(ConsistOf(
+		Process{PID: 10, Unit: "kubelet.service"},
+		Process{PID: 11},
+	))
+}
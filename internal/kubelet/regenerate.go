@@ -0,0 +1,160 @@
+package kubelet
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+// RegeneratedKubeconfig is a kubelet kubeconfig written by RegenerateKubeconfig.
+// It keeps the files it replaced so they can be restored if the new kubeconfig
+// doesn't work against the cluster.
+type RegeneratedKubeconfig struct {
+	// Path is the path of the written kubeconfig.
+	Path string
+
+	files   regenerateFiles
+	backups []fileBackup
+}
+
+// fileBackup is the content of a file before it was replaced.
+// A nil content means the file didn't exist.
+type fileBackup struct {
+	path    string
+	content []byte
+	perm    fs.FileMode
+}
+
+// regenerateFiles are the filesystem operations used to replace the kubeconfig files.
+type regenerateFiles struct {
+	readFile  func(path string) ([]byte, error)
+	writeFile func(path string, data []byte, perm fs.FileMode) error
+	remove    func(path string) error
+}
+
+// RegenerateKubeconfig rewrites the kubelet kubeconfig and the cluster CA certificate
+// from the node config, without touching the rest of the kubelet configuration.
+// The node config must include the cluster API server endpoint and CA and have its
+// defaults populated. The previous files can be put back with Restore.
+func RegenerateKubeconfig(cfg *api.NodeConfig) (*RegeneratedKubeconfig, error) {
+	return regenerateKubeconfig(cfg, regenerateFiles{
+		readFile:  os.ReadFile,
+		writeFile: util.WriteFileWithDir,
+		remove:    os.Remove,
+	})
+}
+
+func regenerateKubeconfig(cfg *api.NodeConfig, files regenerateFiles) (*RegeneratedKubeconfig, error) {
+	if err := validateClusterCA(cfg.Spec.Cluster.CertificateAuthority); err != nil {
+		return nil, err
+	}
+	// Without the AWS config path, the IAM Roles Anywhere kubeconfig can't authenticate as the node.
+	if cfg.IsIAMRolesAnywhere() && cfg.Spec.Hybrid.IAMRolesAnywhere.AwsConfigPath == "" {
+		return nil, errors.New("IAM Roles Anywhere AWS config path is empty")
+	}
+
+	kubeconfig, err := generateKubeconfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("generating kubelet kubeconfig: %w", err)
+	}
+	if err := validateKubeconfig(kubeconfig, cfg.Spec.Cluster.APIServerEndpoint); err != nil {
+		return nil, err
+	}
+
+	path := kubeconfigPath
+	if cfg.IsOutpostNode() {
+		path = kubeconfigBootstrapPath
+	}
+	regenerated := &RegeneratedKubeconfig{Path: path, files: files}
+	if err := regenerated.replace(caCertificatePath, cfg.Spec.Cluster.CertificateAuthority, kubeletConfigPerm); err != nil {
+		return nil, fmt.Errorf("writing cluster CA certificate: %w", err)
+	}
+	if err := regenerated.replace(path, kubeconfig, kubeconfigPerm); err != nil {
+		err = fmt.Errorf("writing kubelet kubeconfig: %w", err)
+		if restoreErr := regenerated.Restore(); restoreErr != nil {
+			return nil, fmt.Errorf("%w (restoring previous files: %v)", err, restoreErr)
+		}
+		return nil, err
+	}
+	return regenerated, nil
+}
+
+// replace backs up the current content of path and writes data to it.
+func (r *RegeneratedKubeconfig) replace(path string, data []byte, perm fs.FileMode) error {
+	content, err := r.files.readFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("backing up %s: %w", path, err)
+	}
+	r.backups = append(r.backups, fileBackup{path: path, content: content, perm: perm})
+	return r.files.writeFile(path, data, perm)
+}
+
+// Restore puts back the files replaced when regenerating the kubeconfig,
+// removing the ones that didn't exist before. It tries to restore every file
+// even if some of them fail.
+func (r *RegeneratedKubeconfig) Restore() error {
+	var errs []error
+	for i := len(r.backups) - 1; i >= 0; i-- {
+		backup := r.backups[i]
+		if backup.content == nil {
+			if err := r.files.remove(backup.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("removing %s: %w", backup.path, err))
+			}
+			continue
+		}
+		if err := r.files.writeFile(backup.path, backup.content, backup.perm); err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", backup.path, err))
+		}
+	}
+	r.backups = nil
+	return errors.Join(errs...)
+}
+
+func validateClusterCA(ca []byte) error {
+	block, _ := pem.Decode(ca)
+	if block == nil {
+		return errors.New("cluster certificate authority is not a PEM encoded certificate")
+	}
+	if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+		return fmt.Errorf("parsing cluster certificate authority: %w", err)
+	}
+	return nil
+}
+
+// validateKubeconfig checks the generated kubeconfig can be loaded and that its
+// current context points to the cluster endpoint using the cluster CA file.
+func validateKubeconfig(kubeconfig []byte, endpoint string) error {
+	if endpoint == "" {
+		return errors.New("cluster API server endpoint is empty")
+	}
+	config, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("loading generated kubelet kubeconfig: %w", err)
+	}
+	context, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return fmt.Errorf("generated kubelet kubeconfig is missing current context %s", config.CurrentContext)
+	}
+	cluster, ok := config.Clusters[context.Cluster]
+	if !ok {
+		return fmt.Errorf("generated kubelet kubeconfig is missing cluster %s", context.Cluster)
+	}
+	if cluster.Server != endpoint {
+		return fmt.Errorf("generated kubelet kubeconfig server %s doesn't match cluster endpoint %s", cluster.Server, endpoint)
+	}
+	if cluster.CertificateAuthority != caCertificatePath {
+		return fmt.Errorf("generated kubelet kubeconfig certificate authority %s doesn't match %s", cluster.CertificateAuthority, caCertificatePath)
+	}
+	if _, ok := config.AuthInfos[context.AuthInfo]; !ok {
+		return fmt.Errorf("generated kubelet kubeconfig is missing user %s", context.AuthInfo)
+	}
+	return nil
+}
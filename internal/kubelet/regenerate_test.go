@@ -0,0 +1,251 @@
+package kubelet
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/iamauthenticator"
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+	"github.com/aws/eks-hybrid/internal/test"
+)
+
+const regenerateTestEndpoint = "https://my-cluster.example.com"
+
+func regenerateNodeConfig(ca []byte) *api.NodeConfig {
+	return &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{
+				Name:                 "my-cluster",
+				Region:               "us-west-2",
+				APIServerEndpoint:    regenerateTestEndpoint,
+				CertificateAuthority: ca,
+			},
+		},
+		Status: api.NodeConfigStatus{
+			Instance: api.InstanceDetails{Region: "us-east-1"},
+		},
+	}
+}
+
+// memFiles is an in-memory filesystem for the regenerated kubeconfig files.
+type memFiles struct {
+	files    map[string][]byte
+	writeErr map[string]error
+	writes   int
+}
+
+func newMemFiles(files map[string][]byte) *memFiles {
+	if files == nil {
+		files = map[string][]byte{}
+	}
+	return &memFiles{files: files, writeErr: map[string]error{}}
+}
+
+func (m *memFiles) regenerateFiles() regenerateFiles {
+	return regenerateFiles{
+		readFile: func(path string) ([]byte, error) {
+			content, ok := m.files[path]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return content, nil
+		},
+		writeFile: func(path string, data []byte, _ fs.FileMode) error {
+			m.writes++
+			if err := m.writeErr[path]; err != nil {
+				return err
+			}
+			m.files[path] = data
+			return nil
+		},
+		remove: func(path string) error {
+			delete(m.files, path)
+			return nil
+		},
+	}
+}
+
+func TestRegenerateKubeconfig(t *testing.T) {
+	ca, _, _ := test.GenerateCA(NewWithT(t))
+	outpost := true
+
+	testCases := []struct {
+		name        string
+		configure   func(*api.NodeConfig)
+		wantPath    string
+		wantCommand string
+		wantArgs    []string
+		wantEnv     []clientcmdapi.ExecEnvVar
+	}{
+		{
+			name: "ssm",
+			configure: func(cfg *api.NodeConfig) {
+				cfg.Spec.Hybrid = &api.HybridOptions{SSM: &api.SSM{ActivationCode: "code", ActivationID: "id"}}
+			},
+			wantPath:    kubeconfigPath,
+			wantCommand: iamauthenticator.IAMAuthenticatorBinPath,
+			wantArgs:    []string{"token", "--cluster-id", "my-cluster", "--region", "us-west-2"},
+		},
+		{
+			name: "iam roles anywhere",
+			configure: func(cfg *api.NodeConfig) {
+				cfg.Spec.Hybrid = &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{
+					NodeName:      "my-node",
+					AwsConfigPath: "/etc/aws/hybrid/config",
+				}}
+			},
+			wantPath:    kubeconfigPath,
+			wantCommand: iamauthenticator.IAMAuthenticatorBinPath,
+			wantArgs:    []string{"token", "--cluster-id", "my-cluster", "--region", "us-west-2"},
+			wantEnv: []clientcmdapi.ExecEnvVar{
+				{Name: "AWS_PROFILE", Value: iamrolesanywhere.ProfileName},
+				{Name: "AWS_CONFIG_FILE", Value: "/etc/aws/hybrid/config"},
+			},
+		},
+		{
+			name:        "ec2 instance credentials",
+			configure:   func(cfg *api.NodeConfig) {},
+			wantPath:    kubeconfigPath,
+			wantCommand: "aws",
+			wantArgs:    []string{"eks", "get-token", "--cluster-name", "my-cluster", "--region", "us-east-1"},
+		},
+		{
+			name: "outpost",
+			configure: func(cfg *api.NodeConfig) {
+				cfg.Spec.Cluster.EnableOutpost = &outpost
+				cfg.Spec.Cluster.ID = "cluster-id"
+			},
+			wantPath:    kubeconfigBootstrapPath,
+			wantCommand: "aws",
+			wantArgs:    []string{"eks", "get-token", "--cluster-name", "cluster-id", "--region", "us-east-1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cfg := regenerateNodeConfig(ca)
+			tc.configure(cfg)
+
+			files := newMemFiles(nil)
+			regenerated, err := regenerateKubeconfig(cfg, files.regenerateFiles())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(regenerated.Path).To(Equal(tc.wantPath))
+			written := files.files
+			g.Expect(written).To(HaveKeyWithValue(caCertificatePath, ca))
+			g.Expect(written).To(HaveKey(tc.wantPath))
+
+			kubeconfig, err := clientcmd.Load(written[tc.wantPath])
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(kubeconfig.Clusters["kubernetes"].Server).To(Equal(regenerateTestEndpoint))
+			g.Expect(kubeconfig.Clusters["kubernetes"].CertificateAuthority).To(Equal(caCertificatePath))
+			exec := kubeconfig.AuthInfos["kubelet"].Exec
+			g.Expect(exec).NotTo(BeNil())
+			g.Expect(exec.Command).To(Equal(tc.wantCommand))
+			g.Expect(exec.Args).To(Equal(tc.wantArgs))
+			if tc.wantEnv == nil {
+				g.Expect(exec.Env).To(BeEmpty())
+			} else {
+				g.Expect(exec.Env).To(Equal(tc.wantEnv))
+			}
+		})
+	}
+}
+
+func TestRegenerateKubeconfigErrors(t *testing.T) {
+	ca, _, _ := test.GenerateCA(NewWithT(t))
+
+	testCases := []struct {
+		name      string
+		configure func(*api.NodeConfig)
+		writeErr  map[string]error
+		wantErr   string
+	}{
+		{
+			name:      "missing CA",
+			configure: func(cfg *api.NodeConfig) { cfg.Spec.Cluster.CertificateAuthority = nil },
+			wantErr:   "cluster certificate authority is not a PEM encoded certificate",
+		},
+		{
+			name: "invalid CA",
+			configure: func(cfg *api.NodeConfig) {
+				cfg.Spec.Cluster.CertificateAuthority = []byte("-----BEGIN CERTIFICATE-----\nZm9v\n-----END CERTIFICATE-----\n")
+			},
+			wantErr: "parsing cluster certificate authority",
+		},
+		{
+			name:      "missing endpoint",
+			configure: func(cfg *api.NodeConfig) { cfg.Spec.Cluster.APIServerEndpoint = "" },
+			wantErr:   "cluster API server endpoint is empty",
+		},
+		{
+			// The command populates the node config defaults, including the AWS config path.
+			name: "iam roles anywhere without aws config path",
+			configure: func(cfg *api.NodeConfig) {
+				cfg.Spec.Hybrid = &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"}}
+			},
+			wantErr: "IAM Roles Anywhere AWS config path is empty",
+		},
+		{
+			name:      "CA write failure",
+			configure: func(cfg *api.NodeConfig) {},
+			writeErr:  map[string]error{caCertificatePath: errors.New("read-only file system")},
+			wantErr:   "writing cluster CA certificate: read-only file system",
+		},
+		{
+			name:      "kubeconfig write failure",
+			configure: func(cfg *api.NodeConfig) {},
+			writeErr:  map[string]error{kubeconfigPath: errors.New("read-only file system")},
+			wantErr:   "writing kubelet kubeconfig: read-only file system",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cfg := regenerateNodeConfig(ca)
+			cfg.Spec.Hybrid = &api.HybridOptions{SSM: &api.SSM{ActivationCode: "code", ActivationID: "id"}}
+			tc.configure(cfg)
+
+			previous := map[string][]byte{
+				caCertificatePath: []byte("old-ca"),
+				kubeconfigPath:    []byte("old-kubeconfig"),
+			}
+			files := newMemFiles(map[string][]byte{
+				caCertificatePath: previous[caCertificatePath],
+				kubeconfigPath:    previous[kubeconfigPath],
+			})
+			files.writeErr = tc.writeErr
+			_, err := regenerateKubeconfig(cfg, files.regenerateFiles())
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			if tc.writeErr == nil {
+				g.Expect(files.writes).To(BeZero())
+			}
+			// A failed regeneration never leaves a partial update behind.
+			g.Expect(files.files).To(Equal(previous))
+		})
+	}
+}
+
+func TestRegeneratedKubeconfigRestore(t *testing.T) {
+	g := NewWithT(t)
+	ca, _, _ := test.GenerateCA(g)
+	cfg := regenerateNodeConfig(ca)
+	cfg.Spec.Hybrid = &api.HybridOptions{SSM: &api.SSM{ActivationCode: "code", ActivationID: "id"}}
+
+	// The CA didn't exist before, the kubeconfig did.
+	files := newMemFiles(map[string][]byte{kubeconfigPath: []byte("old-kubeconfig")})
+	regenerated, err := regenerateKubeconfig(cfg, files.regenerateFiles())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(files.files).To(HaveKeyWithValue(caCertificatePath, ca))
+	g.Expect(files.files[kubeconfigPath]).NotTo(Equal([]byte("old-kubeconfig")))
+
+	g.Expect(regenerated.Restore()).To(Succeed())
+	g.Expect(files.files).To(Equal(map[string][]byte{kubeconfigPath: []byte("old-kubeconfig")}))
+}
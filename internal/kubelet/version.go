@@ -1,8 +1,10 @@
 package kubelet
 
 import (
+	"fmt"
 	"os/exec"
 	"regexp"
+	"strings"
 )
 
 func GetKubeletVersion() (string, error) {
@@ -28,3 +30,20 @@ var semVerRegex = regexp.MustCompile(`v[0-9]+\.[0-9]+.[0-9]+`)
 func parseSemVer(rawVersion string) string {
 	return semVerRegex.FindString(rawVersion)
 }
+
+// verifyBinaryVersion runs the kubelet binary at binPath with --version and checks it reports
+// expectedVersion. It returns the reported version.
+func verifyBinaryVersion(binPath, expectedVersion string) (string, error) {
+	output, err := exec.Command(binPath, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("running %s --version: %w", binPath, err)
+	}
+	version := parseSemVer(string(output))
+	if version == "" {
+		return "", fmt.Errorf("no version found in %s --version output %q", binPath, strings.TrimSpace(string(output)))
+	}
+	if expected := parseSemVer("v" + strings.TrimPrefix(expectedVersion, "v")); version != expected {
+		return "", fmt.Errorf("%s reports version %s, expected %s", binPath, version, expected)
+	}
+	return version, nil
+}
@@ -0,0 +1,128 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/certificate"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const kubeletKubeconfigPath = "/var/lib/kubelet/kubeconfig"
+
+// KubeletClientCredentialValidator validates the credential kubelet authenticates to the API server with,
+// read from the kubelet kubeconfig. Client certificates need to be in their validity window and issued by
+// the cluster CA, exec plugins need their command installed.
+type KubeletClientCredentialValidator struct {
+	kubeconfigPath string
+	cluster        *api.ClusterDetails
+	// ignoreDateAndNoCertErrors controls whether to ignore date validation and missing credential errors
+	ignoreDateAndNoCertErrors bool
+}
+
+func WithKubeconfigPath(kubeconfigPath string) func(*KubeletClientCredentialValidator) {
+	return func(v *KubeletClientCredentialValidator) {
+		v.kubeconfigPath = kubeconfigPath
+	}
+}
+
+func WithIgnoreClientDateAndNoCertErrors(ignore bool) func(*KubeletClientCredentialValidator) {
+	return func(v *KubeletClientCredentialValidator) {
+		v.ignoreDateAndNoCertErrors = ignore
+	}
+}
+
+func NewKubeletClientCredentialValidator(cluster *api.ClusterDetails, opts ...func(*KubeletClientCredentialValidator)) KubeletClientCredentialValidator {
+	v := &KubeletClientCredentialValidator{
+		cluster:        cluster,
+		kubeconfigPath: kubeletKubeconfigPath,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return *v
+}
+
+// Run validates the kubelet client credential against the cluster CA
+// This function conforms to the validation framework signature
+func (v KubeletClientCredentialValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	name := "kubernetes-kubelet-client-credential"
+	informer.Starting(ctx, name, "Validating kubelet client credential")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+	source, err := v.validate()
+	if err != nil {
+		if v.ignoreDateAndNoCertErrors && (certificate.IsDateValidationError(err) || certificate.IsNoCertError(err)) {
+			// set error to nil for the informer to collect so that this validation does not error in the case
+			// of a no-op handled error
+			err = nil
+			return err
+		}
+		err = certificate.AddKubeletClientRemediation(source, err)
+		return err
+	}
+
+	return nil
+}
+
+// validate validates the client credential of the kubeconfig current context and returns where
+// the credential was read from, for remediation.
+func (v KubeletClientCredentialValidator) validate() (source string, err error) {
+	config, err := clientcmd.LoadFromFile(v.kubeconfigPath)
+	if os.IsNotExist(err) {
+		return v.kubeconfigPath, certificate.NewCertNotFoundError(fmt.Sprintf("kubelet kubeconfig %s not found", v.kubeconfigPath), err)
+	} else if err != nil {
+		return v.kubeconfigPath, fmt.Errorf("loading kubelet kubeconfig %s: %w", v.kubeconfigPath, err)
+	}
+
+	context, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return v.kubeconfigPath, fmt.Errorf("current context %q not found in kubelet kubeconfig %s", config.CurrentContext, v.kubeconfigPath)
+	}
+	user, ok := config.AuthInfos[context.AuthInfo]
+	if !ok {
+		return v.kubeconfigPath, certificate.NewCertNotFoundError(fmt.Sprintf("user %q not found in kubelet kubeconfig %s", context.AuthInfo, v.kubeconfigPath), nil)
+	}
+
+	switch {
+	case len(user.ClientCertificateData) > 0:
+		return v.kubeconfigPath, certificate.ValidateClientData(user.ClientCertificateData, v.caData())
+	case user.ClientCertificate != "":
+		certPath := user.ClientCertificate
+		if !filepath.IsAbs(certPath) {
+			certPath = filepath.Join(filepath.Dir(v.kubeconfigPath), certPath)
+		}
+		certData, err := os.ReadFile(certPath)
+		if os.IsNotExist(err) {
+			return certPath, certificate.NewCertNotFoundError(fmt.Sprintf("kubelet client certificate %s not found", certPath), err)
+		} else if err != nil {
+			return certPath, fmt.Errorf("reading kubelet client certificate %s: %w", certPath, err)
+		}
+		return certPath, certificate.ValidateClientData(certData, v.caData())
+	case user.Exec != nil:
+		// Exec plugins get short lived credentials on demand, they only need to be installed.
+		if filepath.IsAbs(user.Exec.Command) {
+			if _, err := os.Stat(user.Exec.Command); err != nil {
+				return v.kubeconfigPath, certificate.NewCertNotFoundError(fmt.Sprintf("kubelet credential plugin %s not found", user.Exec.Command), err)
+			}
+		}
+		return v.kubeconfigPath, nil
+	case user.Token != "" || user.TokenFile != "":
+		return v.kubeconfigPath, nil
+	}
+	return v.kubeconfigPath, certificate.NewCertNotFoundError(fmt.Sprintf("user %q in kubelet kubeconfig %s has no client credential", context.AuthInfo, v.kubeconfigPath), nil)
+}
+
+func (v KubeletClientCredentialValidator) caData() []byte {
+	if v.cluster == nil {
+		return nil
+	}
+	return v.cluster.CertificateAuthority
+}
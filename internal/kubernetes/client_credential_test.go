@@ -0,0 +1,183 @@
+package kubernetes_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/kubernetes"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const kubeconfigWithCertFile = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://my-endpoint.example.com
+  name: kubernetes
+contexts:
+- context:
+    cluster: kubernetes
+    user: kubelet
+  name: kubelet
+current-context: kubelet
+users:
+- name: kubelet
+  user:
+    client-certificate: pki/kubelet-client-current.pem
+    client-key: pki/kubelet-client-current.pem
+`
+
+const kubeconfigWithExec = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://my-endpoint.example.com
+  name: kubernetes
+contexts:
+- context:
+    cluster: kubernetes
+    user: kubelet
+  name: kubelet
+current-context: kubelet
+users:
+- name: kubelet
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      args: ["eks", "get-token", "--cluster-name", "test-cluster"]
+`
+
+const kubeconfigWithoutCredential = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://my-endpoint.example.com
+  name: kubernetes
+contexts:
+- context:
+    cluster: kubernetes
+    user: kubelet
+  name: kubelet
+current-context: kubelet
+users:
+- name: kubelet
+  user: {}
+`
+
+func TestKubeletClientCredentialValidator(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx := context.Background()
+
+	caBytes, ca, caKey := test.GenerateCA(g)
+	_, wrongCA, wrongCAKey := test.GenerateCA(g)
+
+	tests := []struct {
+		name                string
+		kubeconfig          string
+		cert                []byte
+		ignoreErrors        bool
+		expectedError       string
+		expectedRemediation string
+	}{
+		{
+			name:       "valid client certificate",
+			kubeconfig: kubeconfigWithCertFile,
+			cert:       test.GenerateKubeletClientCert(g, ca, caKey, time.Now().Add(-time.Hour), time.Now().AddDate(1, 0, 0)),
+		},
+		{
+			name:                "expired client certificate",
+			kubeconfig:          kubeconfigWithCertFile,
+			cert:                test.GenerateKubeletClientCert(g, ca, caKey, time.Now().AddDate(-1, 0, 0), time.Now().Add(-time.Hour)),
+			expectedError:       "client certificate has expired",
+			expectedRemediation: "The kubelet client credential expired",
+		},
+		{
+			name:                "client certificate not yet valid",
+			kubeconfig:          kubeconfigWithCertFile,
+			cert:                test.GenerateKubeletClientCert(g, ca, caKey, time.Now().Add(time.Hour), time.Now().AddDate(1, 0, 0)),
+			expectedError:       "client certificate is not yet valid",
+			expectedRemediation: "Verify the system time is correct",
+		},
+		{
+			name:                "client certificate from another cluster",
+			kubeconfig:          kubeconfigWithCertFile,
+			cert:                test.GenerateKubeletClientCert(g, wrongCA, wrongCAKey, time.Now().Add(-time.Hour), time.Now().AddDate(1, 0, 0)),
+			expectedError:       "certificate is not valid for the current cluster",
+			expectedRemediation: "was not issued by the current cluster",
+		},
+		{
+			name:                "server certificate used as client credential",
+			kubeconfig:          kubeconfigWithCertFile,
+			cert:                test.GenerateKubeletCert(g, ca, caKey, time.Now().Add(-time.Hour), time.Now().AddDate(1, 0, 0)),
+			expectedError:       "certificate is not valid for the current cluster",
+			expectedRemediation: "was not issued by the current cluster",
+		},
+		{
+			name:                "client certificate not written yet",
+			kubeconfig:          kubeconfigWithCertFile,
+			expectedError:       "kubelet client certificate",
+			expectedRemediation: "Kubelet bootstrap has not completed",
+		},
+		{
+			name:                "kubeconfig not written yet",
+			expectedError:       "kubelet kubeconfig",
+			expectedRemediation: "Kubelet bootstrap has not completed",
+		},
+		{
+			name:                "kubeconfig without client credential",
+			kubeconfig:          kubeconfigWithoutCredential,
+			expectedError:       "has no client credential",
+			expectedRemediation: "Kubelet bootstrap has not completed",
+		},
+		{
+			name:         "kubeconfig not written yet ignored",
+			ignoreErrors: true,
+		},
+		{
+			name:         "expired client certificate ignored",
+			kubeconfig:   kubeconfigWithCertFile,
+			cert:         test.GenerateKubeletClientCert(g, ca, caKey, time.Now().AddDate(-1, 0, 0), time.Now().Add(-time.Hour)),
+			ignoreErrors: true,
+		},
+		{
+			name:       "exec credential plugin",
+			kubeconfig: kubeconfigWithExec,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tmpDir := t.TempDir()
+			kubeconfigPath := filepath.Join(tmpDir, "kubeconfig")
+			if tc.kubeconfig != "" {
+				g.Expect(os.WriteFile(kubeconfigPath, []byte(tc.kubeconfig), 0o600)).To(Succeed())
+			}
+			if tc.cert != nil {
+				g.Expect(os.MkdirAll(filepath.Join(tmpDir, "pki"), 0o755)).To(Succeed())
+				g.Expect(os.WriteFile(filepath.Join(tmpDir, "pki", "kubelet-client-current.pem"), tc.cert, 0o600)).To(Succeed())
+			}
+
+			v := kubernetes.NewKubeletClientCredentialValidator(
+				&api.ClusterDetails{CertificateAuthority: caBytes},
+				kubernetes.WithKubeconfigPath(kubeconfigPath),
+				kubernetes.WithIgnoreClientDateAndNoCertErrors(tc.ignoreErrors),
+			)
+			err := v.Run(ctx, test.NewFakeInformer(), &api.NodeConfig{})
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tc.expectedError)))
+			g.Expect(validation.Remediation(err)).To(ContainSubstring(tc.expectedRemediation))
+		})
+	}
+}
@@ -15,17 +15,23 @@ type ClusterProvider interface {
 }
 
 type clusterProvider struct {
-	aws   aws.Config
-	cache *api.ClusterDetails
+	clusters *eks.ClusterCache
+	cache    *api.ClusterDetails
 }
 
 func NewClusterProvider(config aws.Config) ClusterProvider {
+	return NewClusterProviderWithCache(eks.NewClusterCacheFromConfig(config))
+}
+
+// NewClusterProviderWithCache returns a ClusterProvider that reads the cluster from clusters,
+// sharing the DescribeCluster call with the other readers of the cache.
+func NewClusterProviderWithCache(clusters *eks.ClusterCache) ClusterProvider {
 	return &clusterProvider{
-		aws: config,
+		clusters: clusters,
 	}
 }
 
-// ReadClusterDetails returns ClusterDetails with caching, delegating to eks.ReadClusterDetails for the actual API call
+// ReadClusterDetails returns ClusterDetails with caching, delegating to the cluster cache for the actual API call
 func (p *clusterProvider) ReadClusterDetails(ctx context.Context, node *api.NodeConfig) (*api.ClusterDetails, error) {
 	if node.Spec.Cluster.APIServerEndpoint != "" && node.Spec.Cluster.CertificateAuthority != nil && node.Spec.Cluster.CIDR != "" {
 		return node.Spec.Cluster.DeepCopy(), nil
@@ -35,7 +41,7 @@ func (p *clusterProvider) ReadClusterDetails(ctx context.Context, node *api.Node
 		return p.cache.DeepCopy(), nil
 	}
 
-	cluster, err := eks.ReadClusterDetails(ctx, p.aws, node)
+	cluster, err := p.clusters.ReadClusterDetails(ctx, node)
 	if err != nil {
 		return nil, validation.WithRemediation(err,
 			"Either provide the Kubernetes API server endpoint or ensure the node has access and permissions to call DescribeCluster EKS API.",
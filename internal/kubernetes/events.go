@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EventSourceComponent is the component reported as source of the events emitted by nodeadm.
+	EventSourceComponent = "nodeadm"
+
+	// NodeRegisteredReason is the event reason used when the node is registered in the cluster.
+	NodeRegisteredReason = "HybridNodeRegistered"
+	// NodeCNIDetectedReason is the event reason used when a CNI is detected on the node.
+	NodeCNIDetectedReason = "HybridNodeCNIDetected"
+	// NodeReadyReason is the event reason used when the node becomes ready.
+	NodeReadyReason = "HybridNodeReady"
+)
+
+// NodeEventRecorder emits Kubernetes events against a Node object.
+// Failing to emit an event is never considered an error for the caller,
+// failures are just logged.
+type NodeEventRecorder struct {
+	client kubernetes.Interface
+	logger *zap.Logger
+}
+
+// NewNodeEventRecorder returns a new NodeEventRecorder.
+func NewNodeEventRecorder(client kubernetes.Interface, logger *zap.Logger) NodeEventRecorder {
+	return NodeEventRecorder{
+		client: client,
+		logger: logger,
+	}
+}
+
+// Normal emits an event of type Normal for the node.
+func (r NodeEventRecorder) Normal(ctx context.Context, nodeName, reason, message string) {
+	if err := r.emit(ctx, nodeName, corev1.EventTypeNormal, reason, message); err != nil {
+		r.logger.Warn("Failed to emit node event",
+			zap.String("node", nodeName),
+			zap.String("reason", reason),
+			zap.Error(err),
+		)
+	}
+}
+
+func (r NodeEventRecorder) emit(ctx context.Context, nodeName, eventType, reason, message string) error {
+	node, err := GetRetry(ctx, r.client.CoreV1().Nodes(), nodeName)
+	if err != nil {
+		return fmt.Errorf("getting node %s: %w", nodeName, err)
+	}
+
+	t := time.Now()
+	now := metav1.NewTime(t)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// Same naming as client-go's event recorder.
+			Name: fmt.Sprintf("%v.%x", nodeName, t.UnixNano()),
+			// Node events are created in the default namespace, same as the kubelet does.
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "Node",
+			APIVersion: "v1",
+			Name:       node.Name,
+			UID:        node.UID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: EventSourceComponent, Host: nodeName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	_, err = r.client.CoreV1().Events(metav1.NamespaceDefault).Create(ctx, event, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating event: %w", err)
+	}
+	return nil
+}
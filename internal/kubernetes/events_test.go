@@ -0,0 +1,55 @@
+package kubernetes_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aws/eks-hybrid/internal/kubernetes"
+)
+
+func TestNodeEventRecorderNormal(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-node", UID: "node-uid"},
+	}
+	client := fake.NewSimpleClientset(node)
+
+	recorder := kubernetes.NewNodeEventRecorder(client, zap.NewNop())
+	recorder.Normal(ctx, "my-node", kubernetes.NodeRegisteredReason, "registered")
+
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(events.Items).To(HaveLen(1))
+	event := events.Items[0]
+	g.Expect(event.Reason).To(Equal(kubernetes.NodeRegisteredReason))
+	g.Expect(event.Message).To(Equal("registered"))
+	g.Expect(event.Type).To(Equal(corev1.EventTypeNormal))
+	g.Expect(event.Source.Component).To(Equal(kubernetes.EventSourceComponent))
+	g.Expect(event.InvolvedObject.Kind).To(Equal("Node"))
+	g.Expect(event.InvolvedObject.Name).To(Equal("my-node"))
+	g.Expect(event.InvolvedObject.UID).To(BeEquivalentTo("node-uid"))
+}
+
+func TestNodeEventRecorderNormalNodeNotFound(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	// GetRetry retries not found errors until the context is done.
+	cancel()
+	client := fake.NewSimpleClientset()
+
+	recorder := kubernetes.NewNodeEventRecorder(client, zap.NewNop())
+	g.Expect(func() {
+		recorder.Normal(ctx, "missing-node", kubernetes.NodeReadyReason, "ready")
+	}).NotTo(Panic())
+
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(events.Items).To(BeEmpty())
+}
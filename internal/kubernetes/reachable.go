@@ -0,0 +1,130 @@
+package kubernetes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aws/eks-hybrid/internal/retry"
+)
+
+// APIServerFailure is the reason an authenticated request to the API server failed.
+type APIServerFailure string
+
+const (
+	// APIServerUnreachable means the request didn't get a response, the API server
+	// can't be resolved or connected to.
+	APIServerUnreachable APIServerFailure = "Unreachable"
+	// APIServerTLSFailure means the API server certificate couldn't be verified.
+	APIServerTLSFailure APIServerFailure = "TLS"
+	// APIServerUnauthorized means the API server didn't authenticate the node credentials,
+	// or the credential provider failed to produce them.
+	APIServerUnauthorized APIServerFailure = "Unauthorized"
+	// APIServerForbidden means the node identity isn't allowed to make the request.
+	APIServerForbidden APIServerFailure = "Forbidden"
+	// APIServerError means the API server answered with any other error.
+	APIServerError APIServerFailure = "Error"
+)
+
+// credentialProviderErrorPrefix is how client-go prefixes the errors of exec credential plugins.
+const credentialProviderErrorPrefix = "getting credentials:"
+
+var apiServerFailureRemediations = map[APIServerFailure]string{
+	APIServerUnreachable:  "Ensure your network configuration allows the node to access the Kubernetes API endpoint.",
+	APIServerTLSFailure:   "Ensure the certificate authority in the kubeconfig is the cluster CA and the Kubernetes API endpoint is not intercepted by a proxy.",
+	APIServerUnauthorized: "Ensure the node credentials can be retrieved and the IAM role of the node is mapped to a Kubernetes identity through Access Entries or the aws-auth ConfigMap.",
+	APIServerForbidden:    badPermissionsRemediation,
+	APIServerError:        "Check the health of the Kubernetes API server.",
+}
+
+// APIServerReachabilityError is returned when the API server can't be reached with the node credentials.
+type APIServerReachabilityError struct {
+	Failure APIServerFailure
+	Err     error
+}
+
+func (e *APIServerReachabilityError) Error() string {
+	return fmt.Sprintf("making authenticated request to the Kubernetes API server (%s): %s", e.Failure, e.Err)
+}
+
+func (e *APIServerReachabilityError) Unwrap() error {
+	return e.Err
+}
+
+// Remediation returns the remediation for the failure.
+func (e *APIServerReachabilityError) Remediation() string {
+	return apiServerFailureRemediations[e.Failure]
+}
+
+// WaitForAPIServer retries a lightweight authenticated request to the API server with the
+// client credentials until it succeeds or the retry limit is reached, and returns the default
+// kubernetes endpoints it read, nil if they don't exist. Any failure is returned as an
+// [APIServerReachabilityError]. TLS failures aren't retried, they don't go away by themselves.
+func WaitForAPIServer(ctx context.Context, client kubernetes.Interface, opts ...retry.RetrierOption) (*corev1.Endpoints, error) {
+	retrier := defaultRetrier()
+	retrier.HandleError = func(err error) error {
+		if err != nil && classifyAPIServerError(err) == APIServerTLSFailure {
+			return err
+		}
+		return nil
+	}
+	for _, opt := range opts {
+		opt(retrier)
+	}
+
+	var endpoints *corev1.Endpoints
+	err := retrier.Do(ctx, func(ctx context.Context) (bool, error) {
+		// Nodes are allowed to read the default kubernetes endpoints in every Kubernetes version,
+		// unlike SelfSubjectReviews which require 1.28. A NotFound still proves the request
+		// was authenticated and authorized.
+		var err error
+		endpoints, err = client.CoreV1().Endpoints("default").Get(ctx, "kubernetes", metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			endpoints = nil
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, &APIServerReachabilityError{Failure: classifyAPIServerError(err), Err: err}
+	}
+	return endpoints, nil
+}
+
+func classifyAPIServerError(err error) APIServerFailure {
+	var (
+		certVerificationErr *tls.CertificateVerificationError
+		recordHeaderErr     tls.RecordHeaderError
+		unknownAuthorityErr x509.UnknownAuthorityError
+		hostnameErr         x509.HostnameError
+		certInvalidErr      x509.CertificateInvalidError
+	)
+
+	switch {
+	case apierrors.IsUnauthorized(err):
+		return APIServerUnauthorized
+	case apierrors.IsForbidden(err):
+		return APIServerForbidden
+	case errors.As(err, new(apierrors.APIStatus)):
+		return APIServerError
+	case errors.As(err, &certVerificationErr), errors.As(err, &recordHeaderErr),
+		errors.As(err, &unknownAuthorityErr), errors.As(err, &hostnameErr), errors.As(err, &certInvalidErr):
+		return APIServerTLSFailure
+	case strings.Contains(err.Error(), credentialProviderErrorPrefix):
+		// client-go doesn't wrap the credential plugin errors, only their message is left.
+		return APIServerUnauthorized
+	default:
+		return APIServerUnreachable
+	}
+}
@@ -0,0 +1,161 @@
+package kubernetes_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgo "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/aws/eks-hybrid/internal/kubernetes"
+	"github.com/aws/eks-hybrid/internal/retry"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+var statusReasons = map[int]metav1.StatusReason{
+	http.StatusUnauthorized:        metav1.StatusReasonUnauthorized,
+	http.StatusForbidden:           metav1.StatusReasonForbidden,
+	http.StatusNotFound:            metav1.StatusReasonNotFound,
+	http.StatusInternalServerError: metav1.StatusReasonInternalError,
+}
+
+// apiServerForStatus fakes an API server answering the kubernetes endpoints requests with status.
+func apiServerForStatus(t *testing.T, status int) test.TestServer {
+	return test.NewHTTPSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body any = &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"}}
+		if status != http.StatusOK {
+			body = &metav1.Status{Status: metav1.StatusFailure, Code: int32(status), Reason: statusReasons[status]}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
+
+func restConfigFor(server test.TestServer) *rest.Config {
+	return &rest.Config{
+		Host:            server.URL,
+		BearerToken:     "token",
+		TLSClientConfig: rest.TLSClientConfig{CAData: server.CAPEM()},
+	}
+}
+
+func TestWaitForAPIServer(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        func(t *testing.T, g *WithT) *rest.Config
+		wantEndpoints bool
+		wantFailure   kubernetes.APIServerFailure
+	}{
+		{
+			name: "success",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				return restConfigFor(apiServerForStatus(t, http.StatusOK))
+			},
+			wantEndpoints: true,
+		},
+		{
+			name: "authorized but not found",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				return restConfigFor(apiServerForStatus(t, http.StatusNotFound))
+			},
+		},
+		{
+			name: "unreachable",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				server := apiServerForStatus(t, http.StatusOK)
+				server.Close()
+				return restConfigFor(server)
+			},
+			wantFailure: kubernetes.APIServerUnreachable,
+		},
+		{
+			name: "unknown certificate authority",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				config := restConfigFor(apiServerForStatus(t, http.StatusOK))
+				config.CAData, _, _ = test.GenerateCA(g)
+				return config
+			},
+			wantFailure: kubernetes.APIServerTLSFailure,
+		},
+		{
+			name: "unauthorized",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				return restConfigFor(apiServerForStatus(t, http.StatusUnauthorized))
+			},
+			wantFailure: kubernetes.APIServerUnauthorized,
+		},
+		{
+			name: "credential provider fails",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				config := restConfigFor(apiServerForStatus(t, http.StatusOK))
+				config.BearerToken = ""
+				config.ExecProvider = &clientcmdapi.ExecConfig{
+					APIVersion:      "client.authentication.k8s.io/v1beta1",
+					Command:         "/nonexistent/credential-provider",
+					InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+				}
+				return config
+			},
+			wantFailure: kubernetes.APIServerUnauthorized,
+		},
+		{
+			name: "forbidden",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				return restConfigFor(apiServerForStatus(t, http.StatusForbidden))
+			},
+			wantFailure: kubernetes.APIServerForbidden,
+		},
+		{
+			name: "server error",
+			config: func(t *testing.T, g *WithT) *rest.Config {
+				return restConfigFor(apiServerForStatus(t, http.StatusInternalServerError))
+			},
+			wantFailure: kubernetes.APIServerError,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client, err := clientgo.NewForConfig(tc.config(t, g))
+			g.Expect(err).NotTo(HaveOccurred())
+
+			endpoints, err := kubernetes.WaitForAPIServer(context.Background(), client, retry.WithBackoffDuration(time.Millisecond))
+			if tc.wantFailure == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				if tc.wantEndpoints {
+					g.Expect(endpoints.Name).To(Equal("kubernetes"))
+				} else {
+					g.Expect(endpoints).To(BeNil())
+				}
+				return
+			}
+
+			reachabilityErr := &kubernetes.APIServerReachabilityError{}
+			g.Expect(errors.As(err, &reachabilityErr)).To(BeTrue())
+			g.Expect(reachabilityErr.Failure).To(Equal(tc.wantFailure))
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestWaitForAPIServerDoesNotRetryTLSFailures(t *testing.T) {
+	g := NewWithT(t)
+	config := restConfigFor(apiServerForStatus(t, http.StatusOK))
+	config.CAData, _, _ = test.GenerateCA(g)
+	client, err := clientgo.NewForConfig(config)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	_, err = kubernetes.WaitForAPIServer(context.Background(), client, retry.WithBackoffDuration(time.Hour))
+	g.Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+	g.Expect(err).NotTo(MatchError(ContainSubstring("while retrying")))
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/mod/semver"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
@@ -53,13 +55,8 @@ func (a APIServerValidator) MakeAuthenticatedRequest(ctx context.Context, inform
 		return err
 	}
 
-	_, err = GetRetry(ctx, client.CoreV1().Endpoints("default"), "kubernetes")
-	if err != nil {
-		err = validation.WithRemediation(err, badPermissionsRemediation)
-		return err
-	}
-
-	return nil
+	_, err = kubernetesEndpoints(ctx, client)
+	return err
 }
 
 func (a APIServerValidator) CheckIdentity(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
@@ -145,9 +142,8 @@ func (a APIServerValidator) CheckVPCEndpointAccess(ctx context.Context, informer
 		return err
 	}
 
-	kubeEndpoint, err := GetRetry(ctx, client.CoreV1().Endpoints("default"), "kubernetes")
+	kubeEndpoint, err := kubernetesEndpoints(ctx, client)
 	if err != nil {
-		err = validation.WithRemediation(err, badPermissionsRemediation)
 		return err
 	}
 
@@ -192,6 +188,19 @@ func (a APIServerValidator) CheckVPCEndpointAccess(ctx context.Context, informer
 	return nil
 }
 
+// kubernetesEndpoints waits for the API server and returns the default kubernetes endpoints
+// read while waiting, which must exist.
+func kubernetesEndpoints(ctx context.Context, client kubernetes.Interface) (*corev1.Endpoints, error) {
+	endpoints, err := WaitForAPIServer(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if endpoints == nil {
+		return nil, validation.WithRemediation(apierrors.NewNotFound(corev1.Resource("endpoints"), "kubernetes"), badPermissionsRemediation)
+	}
+	return endpoints, nil
+}
+
 func (a APIServerValidator) client() (kubernetes.Interface, error) {
 	client, err := a.kubelet.BuildClient()
 	if err != nil {
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	ipv4RouteTablePath = "/proc/net/route"
+	ipv6RouteTablePath = "/proc/net/ipv6_route"
+
+	// rtfUp is the RTF_UP route flag, set for usable routes.
+	rtfUp = 0x1
+)
+
+// DefaultRoute is a default route in the host routing table.
+type DefaultRoute struct {
+	Interface string
+	Gateway   net.IP
+	Metric    int
+	IPv6      bool
+}
+
+func (r DefaultRoute) String() string {
+	return fmt.Sprintf("via %s dev %s metric %d", r.Gateway, r.Interface, r.Metric)
+}
+
+// DefaultRoutesValidator warns when the host has several default routes with the same metric
+// for an IP family. The kernel then picks one of them for each connection, which makes egress
+// nondeterministic on multi-homed nodes and the connectivity to the control plane intermittent.
+type DefaultRoutesValidator struct {
+	defaultRoutes func() ([]DefaultRoute, error)
+}
+
+// DefaultRoutesValidatorOpt allows to configure the DefaultRoutesValidator.
+type DefaultRoutesValidatorOpt func(*DefaultRoutesValidator)
+
+// NewDefaultRoutesValidator returns a new DefaultRoutesValidator.
+func NewDefaultRoutesValidator(opts ...DefaultRoutesValidatorOpt) DefaultRoutesValidator {
+	v := &DefaultRoutesValidator{
+		defaultRoutes: DefaultRoutes,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return *v
+}
+
+// WithDefaultRoutes sets the function used to read the default routes from the host routing table.
+func WithDefaultRoutes(defaultRoutes func() ([]DefaultRoute, error)) DefaultRoutesValidatorOpt {
+	return func(v *DefaultRoutesValidator) {
+		v.defaultRoutes = defaultRoutes
+	}
+}
+
+// Run validates the host doesn't have several default routes with the same metric.
+func (v DefaultRoutesValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	name := "default-routes-validation"
+	informer.Starting(ctx, name, "Validating the host has a single preferred default route")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	err = v.Validate()
+	return err
+}
+
+// Validate checks the default routes of each IP family. Any failure is returned as a warning.
+func (v DefaultRoutesValidator) Validate() error {
+	routes, err := v.defaultRoutes()
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("reading default routes: %w", err), "Ensure the host routing table is readable.")
+	}
+
+	for _, ipv6 := range []bool{false, true} {
+		preferred := preferredDefaultRoutes(routes, ipv6)
+		if len(preferred) < 2 {
+			continue
+		}
+		family := "IPv4"
+		if ipv6 {
+			family = "IPv6"
+		}
+		descriptions := make([]string, 0, len(preferred))
+		for _, route := range preferred {
+			descriptions = append(descriptions, route.String())
+		}
+		return validation.WithWarning(
+			fmt.Errorf("found %d %s default routes with the same metric %d: %s",
+				len(preferred), family, preferred[0].Metric, strings.Join(descriptions, ", ")),
+			"Traffic to the cluster can egress through any of these routes, which causes intermittent connectivity with the control plane. "+
+				"Keep a single default route, or give the default route of the interface used to reach the cluster a lower metric, "+
+				"for example with 'ip route replace default via <gateway> dev <interface> metric 100'.")
+	}
+	return nil
+}
+
+// preferredDefaultRoutes returns the default routes of the IP family with the lowest metric.
+func preferredDefaultRoutes(routes []DefaultRoute, ipv6 bool) []DefaultRoute {
+	var preferred []DefaultRoute
+	for _, route := range routes {
+		if route.IPv6 != ipv6 {
+			continue
+		}
+		if len(preferred) == 0 || route.Metric < preferred[0].Metric {
+			preferred = []DefaultRoute{route}
+		} else if route.Metric == preferred[0].Metric {
+			preferred = append(preferred, route)
+		}
+	}
+	return preferred
+}
+
+// DefaultRoutes returns the usable IPv4 and IPv6 default routes in the host main routing table.
+func DefaultRoutes() ([]DefaultRoute, error) {
+	var routes []DefaultRoute
+	for _, table := range []struct {
+		path  string
+		parse func(io.Reader) ([]DefaultRoute, error)
+	}{
+		{ipv4RouteTablePath, parseIPv4DefaultRoutes},
+		{ipv6RouteTablePath, parseIPv6DefaultRoutes},
+	} {
+		file, err := os.Open(table.path)
+		if os.IsNotExist(err) {
+			// IPv6 can be disabled in the kernel.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		tableRoutes, err := table.parse(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", table.path, err)
+		}
+		routes = append(routes, tableRoutes...)
+	}
+	return routes, nil
+}
+
+// parseIPv4DefaultRoutes parses the default routes from the /proc/net/route format, where
+// addresses are hex encoded in host byte order.
+func parseIPv4DefaultRoutes(r io.Reader) ([]DefaultRoute, error) {
+	var routes []DefaultRoute
+	scanner := bufio.NewScanner(r)
+	// Skip the header.
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		iface, destination, gateway, flags, metric, mask := fields[0], fields[1], fields[2], fields[3], fields[6], fields[7]
+		if destination != "00000000" || mask != "00000000" {
+			continue
+		}
+		if up, err := hasFlag(flags, rtfUp); err != nil {
+			return nil, err
+		} else if !up {
+			continue
+		}
+		gatewayValue, err := strconv.ParseUint(gateway, 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway %q: %w", gateway, err)
+		}
+		gatewayIP := make(net.IP, net.IPv4len)
+		binary.LittleEndian.PutUint32(gatewayIP, uint32(gatewayValue))
+		metricValue, err := strconv.Atoi(metric)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric %q: %w", metric, err)
+		}
+		routes = append(routes, DefaultRoute{Interface: iface, Gateway: gatewayIP, Metric: metricValue})
+	}
+	return routes, scanner.Err()
+}
+
+// parseIPv6DefaultRoutes parses the default routes from the /proc/net/ipv6_route format, where
+// addresses are hex encoded in network byte order and the metric is hex encoded.
+func parseIPv6DefaultRoutes(r io.Reader) ([]DefaultRoute, error) {
+	var routes []DefaultRoute
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		destination, prefix, nextHop, metric, flags, iface := fields[0], fields[1], fields[4], fields[5], fields[8], fields[9]
+		// The kernel adds an unreachable default route on the loopback interface.
+		if strings.Trim(destination, "0") != "" || prefix != "00" || iface == "lo" {
+			continue
+		}
+		if up, err := hasFlag(flags, rtfUp); err != nil {
+			return nil, err
+		} else if !up {
+			continue
+		}
+		gateway, err := hex.DecodeString(nextHop)
+		if err != nil || len(gateway) != net.IPv6len {
+			return nil, fmt.Errorf("invalid next hop %q", nextHop)
+		}
+		metricValue, err := strconv.ParseUint(metric, 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric %q: %w", metric, err)
+		}
+		routes = append(routes, DefaultRoute{Interface: iface, Gateway: net.IP(gateway), Metric: int(metricValue), IPv6: true})
+	}
+	return routes, scanner.Err()
+}
+
+func hasFlag(flags string, flag uint64) (bool, error) {
+	value, err := strconv.ParseUint(flags, 16, 32)
+	if err != nil {
+		return false, fmt.Errorf("invalid route flags %q: %w", flags, err)
+	}
+	return value&flag != 0, nil
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestDefaultRoutesValidatorRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		routes  []DefaultRoute
+		err     error
+		wantErr string
+	}{
+		{
+			name:   "single default route",
+			routes: []DefaultRoute{{Interface: "eth0", Gateway: net.ParseIP("10.0.0.1"), Metric: 100}},
+		},
+		{
+			name: "default routes with different metrics",
+			routes: []DefaultRoute{
+				{Interface: "eth0", Gateway: net.ParseIP("10.0.0.1"), Metric: 100},
+				{Interface: "eth1", Gateway: net.ParseIP("192.168.1.1"), Metric: 200},
+			},
+		},
+		{
+			name: "one default route per family",
+			routes: []DefaultRoute{
+				{Interface: "eth0", Gateway: net.ParseIP("10.0.0.1"), Metric: 100},
+				{Interface: "eth0", Gateway: net.ParseIP("fe80::1"), Metric: 100, IPv6: true},
+			},
+		},
+		{
+			name: "default routes with the same metric",
+			routes: []DefaultRoute{
+				{Interface: "eth0", Gateway: net.ParseIP("10.0.0.1"), Metric: 100},
+				{Interface: "eth1", Gateway: net.ParseIP("192.168.1.1"), Metric: 100},
+				{Interface: "eth2", Gateway: net.ParseIP("172.16.0.1"), Metric: 300},
+			},
+			wantErr: "found 2 IPv4 default routes with the same metric 100: via 10.0.0.1 dev eth0 metric 100, via 192.168.1.1 dev eth1 metric 100",
+		},
+		{
+			name: "IPv6 default routes with the same metric",
+			routes: []DefaultRoute{
+				{Interface: "eth0", Gateway: net.ParseIP("fe80::1"), Metric: 1024, IPv6: true},
+				{Interface: "eth1", Gateway: net.ParseIP("fe80::2"), Metric: 1024, IPv6: true},
+			},
+			wantErr: "found 2 IPv6 default routes with the same metric 1024",
+		},
+		{
+			name:    "routing table not readable",
+			err:     errors.New("permission denied"),
+			wantErr: "reading default routes: permission denied",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			informer := test.NewFakeInformer()
+			v := NewDefaultRoutesValidator(WithDefaultRoutes(func() ([]DefaultRoute, error) {
+				return tc.routes, tc.err
+			}))
+
+			err := v.Run(context.Background(), informer, &api.NodeConfig{})
+			g.Expect(informer.Started).To(BeTrue())
+			if tc.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(informer.DoneWith).To(BeNil())
+				return
+			}
+			g.Expect(informer.DoneWith).To(Equal(err))
+			g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+			g.Expect(validation.IsWarning(err)).To(BeTrue())
+		})
+	}
+}
+
+func TestParseIPv4DefaultRoutes(t *testing.T) {
+	g := NewWithT(t)
+	table := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0100000A	0003	0	0	100	00000000	0	0	0
+eth1	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+eth2	00000000	010010AC	0002	0	0	50	00000000	0	0	0
+eth0	0000000A	00000000	0001	0	0	100	00FFFFFF	0	0	0
+`
+	routes, err := parseIPv4DefaultRoutes(strings.NewReader(table))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(routes).To(HaveLen(2))
+	g.Expect(routes[0].String()).To(Equal("via 10.0.0.1 dev eth0 metric 100"))
+	g.Expect(routes[1].String()).To(Equal("via 192.168.1.1 dev eth1 metric 100"))
+}
+
+func TestParseIPv6DefaultRoutes(t *testing.T) {
+	g := NewWithT(t)
+	table := `00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00000003     eth0
+00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200       lo
+fe800000000000000000000000000000 40 00000000000000000000000000000000 00 00000000000000000000000000000000 00000100 00000001 00000000 00000001     eth0
+`
+	routes, err := parseIPv6DefaultRoutes(strings.NewReader(table))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(routes).To(Equal([]DefaultRoute{
+		{Interface: "eth0", Gateway: net.ParseIP("fe80::1"), Metric: 1024, IPv6: true},
+	}))
+}
@@ -0,0 +1,193 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-ini/ini"
+	"sigs.k8s.io/yaml"
+)
+
+// InterfaceConfig reads how a network interface is configured by the host's distro.
+type InterfaceConfig interface {
+	// IsDHCP returns true if the interface obtains its address through DHCP,
+	// along with the config file where this was detected.
+	IsDHCP(iface string) (bool, string, error)
+}
+
+// DistroInterfaceConfig inspects the common distro network configuration
+// locations (netplan, ifupdown, ifcfg, NetworkManager and systemd-networkd).
+type DistroInterfaceConfig struct {
+	// Root is prepended to every config path. Empty means "/".
+	Root string
+}
+
+// NewDistroInterfaceConfig returns an InterfaceConfig reading from the host root filesystem.
+func NewDistroInterfaceConfig() DistroInterfaceConfig {
+	return DistroInterfaceConfig{Root: "/"}
+}
+
+// IsDHCP checks all known config locations in order and returns on the first
+// one that configures iface.
+func (c DistroInterfaceConfig) IsDHCP(iface string) (bool, string, error) {
+	checks := []func(string) (bool, string, error){
+		c.netplanDHCP,
+		c.ifupdownDHCP,
+		c.ifcfgDHCP,
+		c.networkManagerDHCP,
+		c.networkdDHCP,
+	}
+	for _, check := range checks {
+		dhcp, source, err := check(iface)
+		if err != nil {
+			return false, "", err
+		}
+		if source != "" {
+			return dhcp, source, nil
+		}
+	}
+	return false, "", nil
+}
+
+func (c DistroInterfaceConfig) path(p string) string {
+	return filepath.Join(c.Root, p)
+}
+
+func (c DistroInterfaceConfig) netplanDHCP(iface string) (bool, string, error) {
+	files, err := filepath.Glob(c.path("/etc/netplan/*.yaml"))
+	if err != nil {
+		return false, "", err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return false, "", fmt.Errorf("reading netplan config %s: %w", file, err)
+		}
+		var netplan struct {
+			Network map[string]any `json:"network"`
+		}
+		if err := yaml.Unmarshal(data, &netplan); err != nil {
+			return false, "", fmt.Errorf("parsing netplan config %s: %w", file, err)
+		}
+		// network contains non-device keys like "version" and "renderer",
+		// so only look at the maps of devices (ethernets, bonds, vlans...).
+		for _, section := range netplan.Network {
+			devices, ok := section.(map[string]any)
+			if !ok {
+				continue
+			}
+			device, ok := devices[iface].(map[string]any)
+			if !ok {
+				continue
+			}
+			return isTruthy(device["dhcp4"]), file, nil
+		}
+	}
+	return false, "", nil
+}
+
+func (c DistroInterfaceConfig) ifupdownDHCP(iface string) (bool, string, error) {
+	file := c.path("/etc/network/interfaces")
+	fh, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return false, "", nil
+	} else if err != nil {
+		return false, "", fmt.Errorf("reading %s: %w", file, err)
+	}
+	defer fh.Close()
+
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// iface <name> inet <method>
+		if len(fields) >= 4 && fields[0] == "iface" && fields[1] == iface && fields[2] == "inet" {
+			return fields[3] == "dhcp", file, nil
+		}
+	}
+	return false, "", scanner.Err()
+}
+
+func (c DistroInterfaceConfig) ifcfgDHCP(iface string) (bool, string, error) {
+	file := c.path(filepath.Join("/etc/sysconfig/network-scripts", "ifcfg-"+iface))
+	cfg, err := loadIni(file)
+	if err != nil || cfg == nil {
+		return false, "", err
+	}
+	bootProto := strings.Trim(cfg.Section("").Key("BOOTPROTO").String(), `"'`)
+	return strings.EqualFold(bootProto, "dhcp"), file, nil
+}
+
+func (c DistroInterfaceConfig) networkManagerDHCP(iface string) (bool, string, error) {
+	files, err := filepath.Glob(c.path("/etc/NetworkManager/system-connections/*.nmconnection"))
+	if err != nil {
+		return false, "", err
+	}
+	for _, file := range files {
+		cfg, err := loadIni(file)
+		if err != nil {
+			return false, "", err
+		}
+		if cfg.Section("connection").Key("interface-name").String() != iface {
+			continue
+		}
+		return cfg.Section("ipv4").Key("method").String() == "auto", file, nil
+	}
+	return false, "", nil
+}
+
+func (c DistroInterfaceConfig) networkdDHCP(iface string) (bool, string, error) {
+	files, err := filepath.Glob(c.path("/etc/systemd/network/*.network"))
+	if err != nil {
+		return false, "", err
+	}
+	for _, file := range files {
+		cfg, err := loadIni(file)
+		if err != nil {
+			return false, "", err
+		}
+		if !containsField(cfg.Section("Match").Key("Name").String(), iface) {
+			continue
+		}
+		switch strings.ToLower(cfg.Section("Network").Key("DHCP").String()) {
+		case "yes", "true", "ipv4":
+			return true, file, nil
+		default:
+			return false, file, nil
+		}
+	}
+	return false, "", nil
+}
+
+// loadIni loads an ini file, returning nil without error if it doesn't exist.
+func loadIni(file string) (*ini.File, error) {
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, nil
+	}
+	cfg, err := ini.Load(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return cfg, nil
+}
+
+func containsField(value, field string) bool {
+	for _, f := range strings.Fields(value) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isTruthy(v any) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case string:
+		return val == "true" || val == "yes"
+	}
+	return false
+}
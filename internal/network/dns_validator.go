@@ -0,0 +1,259 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	defaultResolvConfPath = "/etc/resolv.conf"
+	dnsProbeHost          = "amazonaws.com"
+	dnsProbeTimeout       = 3 * time.Second
+)
+
+// KubeletDNSValidator validates that the resolv.conf used by kubelet to configure
+// pod DNS has nameservers reachable from pods and that the cluster DNS configured
+// for kubelet points to the cluster DNS service.
+// All findings are reported as warnings since pods can still run without DNS.
+type KubeletDNSValidator struct {
+	readFile func(path string) ([]byte, error)
+	osInfo   func() system.OSInfo
+	probe    func(ctx context.Context, nameserver string) error
+}
+
+// KubeletDNSValidatorOpt allows to configure the KubeletDNSValidator.
+type KubeletDNSValidatorOpt func(*KubeletDNSValidator)
+
+// NewKubeletDNSValidator returns a new KubeletDNSValidator.
+func NewKubeletDNSValidator(opts ...KubeletDNSValidatorOpt) KubeletDNSValidator {
+	v := &KubeletDNSValidator{
+		readFile: os.ReadFile,
+		osInfo:   system.HostOSInfo,
+		probe:    probeNameserver,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return *v
+}
+
+// WithResolvConfReader sets the function used to read kubelet's resolv.conf.
+func WithResolvConfReader(readFile func(path string) ([]byte, error)) KubeletDNSValidatorOpt {
+	return func(v *KubeletDNSValidator) {
+		v.readFile = readFile
+	}
+}
+
+// WithNameserverProbe sets the function used to check if a nameserver is reachable.
+func WithNameserverProbe(probe func(ctx context.Context, nameserver string) error) KubeletDNSValidatorOpt {
+	return func(v *KubeletDNSValidator) {
+		v.probe = probe
+	}
+}
+
+// Run validates kubelet's DNS configuration.
+func (v KubeletDNSValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	name := "kubelet-dns-validation"
+	informer.Starting(ctx, name, "Validating kubelet DNS configuration for pods")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+	err = v.Validate(ctx, node)
+	return err
+}
+
+// Validate checks kubelet's resolv.conf and cluster DNS configuration.
+func (v KubeletDNSValidator) Validate(ctx context.Context, node *api.NodeConfig) error {
+	return errors.Join(v.validateResolvConf(ctx, node), validateClusterDNS(node))
+}
+
+func (v KubeletDNSValidator) validateResolvConf(ctx context.Context, node *api.NodeConfig) error {
+	path, err := v.resolvConfPath(node)
+	if err != nil {
+		return validation.WithWarning(err, "Ensure the kubelet config resolvConf is a string.")
+	}
+	if path == "" {
+		// An empty resolvConf disables inheriting the host DNS configuration in pods.
+		return nil
+	}
+
+	content, err := v.readFile(path)
+	if os.IsNotExist(err) {
+		return validation.WithWarning(fmt.Errorf("kubelet resolv.conf %s does not exist", path),
+			"Create the file or set the kubelet config resolvConf to a file containing the nameservers pods should use.")
+	} else if err != nil {
+		return validation.WithWarning(fmt.Errorf("reading kubelet resolv.conf %s: %w", path, err),
+			"Ensure the kubelet resolv.conf is readable.")
+	}
+
+	nameservers := parseNameservers(content)
+	if len(nameservers) == 0 {
+		return validation.WithWarning(fmt.Errorf("kubelet resolv.conf %s does not contain any nameservers", path),
+			"Add at least one nameserver reachable from pods to the kubelet resolv.conf.")
+	}
+
+	var podNameservers []string
+	for _, ns := range nameservers {
+		if ip := net.ParseIP(ns); ip != nil && ip.IsLoopback() {
+			continue
+		}
+		podNameservers = append(podNameservers, ns)
+	}
+	if len(podNameservers) == 0 {
+		return validation.WithWarning(
+			fmt.Errorf("kubelet resolv.conf %s only contains loopback nameservers %v, which pods can't reach", path, nameservers),
+			"Set the kubelet config resolvConf to a file with the upstream nameservers, "+
+				"for example /run/systemd/resolve/resolv.conf when using systemd-resolved. "+
+				"See https://coredns.io/plugins/loop/#troubleshooting-loops-in-kubernetes-clusters")
+	}
+
+	var probeErrs []string
+	for _, ns := range podNameservers {
+		err := v.probe(ctx, ns)
+		if err == nil {
+			return nil
+		}
+		probeErrs = append(probeErrs, fmt.Sprintf("%s: %s", ns, err))
+	}
+	return validation.WithWarning(
+		fmt.Errorf("none of the nameservers in kubelet resolv.conf %s are reachable: %s", path, strings.Join(probeErrs, "; ")),
+		"Ensure the nameservers in the kubelet resolv.conf are reachable from this node on port 53.")
+}
+
+// resolvConfPath returns the resolv.conf path kubelet will be configured with,
+// mirroring the defaults nodeadm applies to the kubelet config.
+func (v KubeletDNSValidator) resolvConfPath(node *api.NodeConfig) (string, error) {
+	if raw, ok := node.Spec.Kubelet.Config["resolvConf"]; ok {
+		var path string
+		if err := json.Unmarshal(raw.Raw, &path); err != nil {
+			return "", fmt.Errorf("parsing kubelet config resolvConf: %w", err)
+		}
+		return path, nil
+	}
+	if node.IsHybridNode() && v.osInfo().ID == system.UbuntuOsName {
+		return system.UbuntuResolvConfPath, nil
+	}
+	return defaultResolvConfPath, nil
+}
+
+// validateClusterDNS checks the cluster DNS IPs set in the kubelet config include
+// the IP of the cluster DNS service derived from the service CIDR.
+func validateClusterDNS(node *api.NodeConfig) error {
+	raw, ok := node.Spec.Kubelet.Config["clusterDNS"]
+	if !ok || node.Spec.Cluster.CIDR == "" {
+		// nodeadm derives clusterDNS from the service CIDR when not overridden.
+		return nil
+	}
+	var clusterDNS []string
+	if err := json.Unmarshal(raw.Raw, &clusterDNS); err != nil {
+		return validation.WithWarning(fmt.Errorf("parsing kubelet config clusterDNS: %w", err),
+			"Ensure the kubelet config clusterDNS is a list of IPs.")
+	}
+	for _, ip := range clusterDNS {
+		if net.ParseIP(ip) == nil {
+			return validation.WithWarning(fmt.Errorf("kubelet config clusterDNS contains invalid IP %q", ip),
+				"Ensure the kubelet config clusterDNS is a list of IPs.")
+		}
+	}
+	expected, err := node.Spec.Cluster.GetClusterDns()
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("deriving cluster DNS IP: %w", err),
+			"Ensure the cluster CIDR in the node config is valid.")
+	}
+	if !slices.Contains(clusterDNS, expected) {
+		return validation.WithWarning(
+			fmt.Errorf("kubelet config clusterDNS %v does not include the cluster DNS service IP %s", clusterDNS, expected),
+			"Remove clusterDNS from the kubelet config to use the default, or set it to the IP of the cluster DNS service. "+
+				"This can be ignored if pods intentionally use a different DNS, like a node local DNS cache.")
+	}
+	return nil
+}
+
+func parseNameservers(resolvConf []byte) []string {
+	var nameservers []string
+	scanner := bufio.NewScanner(bytes.NewReader(resolvConf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			nameservers = append(nameservers, fields[1])
+		}
+	}
+	return nameservers
+}
+
+// probeNameserver sends a DNS query to the nameserver. Any answer, including
+// errors like NXDOMAIN, means the nameserver is reachable.
+func probeNameserver(ctx context.Context, nameserver string) error {
+	return probeDNSServer(ctx, net.JoinHostPort(nameserver, "53"))
+}
+
+// probeDNSServer queries the DNS server at address, it succeeds if the server answers even with an error.
+func probeDNSServer(ctx context.Context, address string) error {
+	// The resolver queries A and AAAA records concurrently, each on its own connection.
+	var responded atomic.Bool
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: dnsProbeTimeout}
+			conn, err := d.DialContext(ctx, network, address)
+			if err != nil {
+				return nil, err
+			}
+			// The resolver uses DNS over UDP framing only for packet connections.
+			if udpConn, ok := conn.(*net.UDPConn); ok {
+				return &respondedUDPConn{UDPConn: udpConn, responded: &responded}, nil
+			}
+			return &respondedConn{Conn: conn, responded: &responded}, nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(ctx, dnsProbeTimeout)
+	defer cancel()
+	_, err := resolver.LookupHost(ctx, dnsProbeHost)
+	if err != nil && !responded.Load() {
+		return err
+	}
+	return nil
+}
+
+// respondedConn records if any data was read from the connection.
+type respondedConn struct {
+	net.Conn
+	responded *atomic.Bool
+}
+
+func (c *respondedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.responded.Store(true)
+	}
+	return n, err
+}
+
+// respondedUDPConn records if any data was read from the UDP connection.
+type respondedUDPConn struct {
+	*net.UDPConn
+	responded *atomic.Bool
+}
+
+func (c *respondedUDPConn) Read(b []byte) (int, error) {
+	n, err := c.UDPConn.Read(b)
+	if n > 0 {
+		c.responded.Store(true)
+	}
+	return n, err
+}
@@ -0,0 +1,209 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func dnsNodeConfig(kubeletConfig map[string]string) *api.NodeConfig {
+	node := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{CIDR: "172.16.0.0/16"},
+			Hybrid:  &api.HybridOptions{},
+		},
+	}
+	if kubeletConfig != nil {
+		node.Spec.Kubelet.Config = api.InlineDocument{}
+		for k, v := range kubeletConfig {
+			node.Spec.Kubelet.Config[k] = runtime.RawExtension{Raw: []byte(v)}
+		}
+	}
+	return node
+}
+
+func resolvConfReader(files map[string]string) func(string) ([]byte, error) {
+	return func(path string) ([]byte, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+}
+
+func TestKubeletDNSValidatorValidate(t *testing.T) {
+	unreachable := map[string]bool{"10.0.0.3": true}
+	probe := func(_ context.Context, nameserver string) error {
+		if unreachable[nameserver] {
+			return errors.New("i/o timeout")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name          string
+		osName        string
+		files         map[string]string
+		kubeletConfig map[string]string
+		wantErr       []string
+	}{
+		{
+			name:  "valid default resolv.conf",
+			files: map[string]string{"/etc/resolv.conf": "search example.com\nnameserver 10.0.0.2\n"},
+		},
+		{
+			name:   "ubuntu uses systemd-resolved upstream resolv.conf",
+			osName: system.UbuntuOsName,
+			files: map[string]string{
+				"/etc/resolv.conf":          "nameserver 127.0.0.53\n",
+				system.UbuntuResolvConfPath: "nameserver 10.0.0.2\n",
+			},
+		},
+		{
+			name:          "resolv.conf from kubelet config",
+			files:         map[string]string{"/custom/resolv.conf": "nameserver 10.0.0.2\n"},
+			kubeletConfig: map[string]string{"resolvConf": `"/custom/resolv.conf"`},
+		},
+		{
+			name:          "empty resolvConf disables the check",
+			kubeletConfig: map[string]string{"resolvConf": `""`},
+		},
+		{
+			name:    "missing resolv.conf",
+			wantErr: []string{"kubelet resolv.conf /etc/resolv.conf does not exist"},
+		},
+		{
+			name:    "no nameservers",
+			files:   map[string]string{"/etc/resolv.conf": "search example.com\n"},
+			wantErr: []string{"does not contain any nameservers"},
+		},
+		{
+			name:    "only loopback nameservers",
+			files:   map[string]string{"/etc/resolv.conf": "nameserver 127.0.0.53\nnameserver ::1\n"},
+			wantErr: []string{"only contains loopback nameservers [127.0.0.53 ::1]"},
+		},
+		{
+			name:    "unreachable nameservers",
+			files:   map[string]string{"/etc/resolv.conf": "nameserver 10.0.0.3\n"},
+			wantErr: []string{"none of the nameservers in kubelet resolv.conf /etc/resolv.conf are reachable: 10.0.0.3: i/o timeout"},
+		},
+		{
+			name:  "one reachable nameserver is enough",
+			files: map[string]string{"/etc/resolv.conf": "nameserver 10.0.0.3\nnameserver 10.0.0.2\n"},
+		},
+		{
+			name:          "cluster DNS matches service CIDR",
+			files:         map[string]string{"/etc/resolv.conf": "nameserver 10.0.0.2\n"},
+			kubeletConfig: map[string]string{"clusterDNS": `["172.16.0.10"]`},
+		},
+		{
+			name:          "wrong cluster DNS",
+			files:         map[string]string{"/etc/resolv.conf": "nameserver 10.0.0.2\n"},
+			kubeletConfig: map[string]string{"clusterDNS": `["10.100.0.10"]`},
+			wantErr:       []string{"kubelet config clusterDNS [10.100.0.10] does not include the cluster DNS service IP 172.16.0.10"},
+		},
+		{
+			name:          "invalid cluster DNS",
+			files:         map[string]string{"/etc/resolv.conf": "nameserver 10.0.0.2\n"},
+			kubeletConfig: map[string]string{"clusterDNS": `["not-an-ip"]`},
+			wantErr:       []string{`kubelet config clusterDNS contains invalid IP "not-an-ip"`},
+		},
+		{
+			name:          "resolv.conf warning does not hide wrong cluster DNS",
+			files:         map[string]string{"/etc/resolv.conf": "nameserver 10.0.0.3\n"},
+			kubeletConfig: map[string]string{"clusterDNS": `["10.100.0.10"]`},
+			wantErr: []string{
+				"none of the nameservers in kubelet resolv.conf /etc/resolv.conf are reachable",
+				"kubelet config clusterDNS [10.100.0.10] does not include the cluster DNS service IP 172.16.0.10",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			v := NewKubeletDNSValidator(
+				WithResolvConfReader(resolvConfReader(tc.files)),
+				WithNameserverProbe(probe),
+			)
+			v.osInfo = func() system.OSInfo { return system.OSInfo{ID: tc.osName} }
+
+			err := v.Validate(context.Background(), dnsNodeConfig(tc.kubeletConfig))
+			if len(tc.wantErr) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			errs := validation.Unwrap(err)
+			g.Expect(errs).To(HaveLen(len(tc.wantErr)))
+			for i, e := range errs {
+				g.Expect(e).To(MatchError(ContainSubstring(tc.wantErr[i])))
+				g.Expect(validation.IsWarning(e)).To(BeTrue())
+				g.Expect(validation.Remediation(e)).NotTo(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestParseNameservers(t *testing.T) {
+	g := NewWithT(t)
+	content := "# comment\nnameserver 10.0.0.2\noptions ndots:5\nnameserver\tfd00::2\nsearch cluster.local\n"
+	g.Expect(parseNameservers([]byte(content))).To(Equal([]string{"10.0.0.2", "fd00::2"}))
+}
+
+// fakeDNSServer answers every query on a local UDP port with SERVFAIL and returns its address.
+func fakeDNSServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 12 {
+				continue
+			}
+			// Keep the query ID and question, set the response flag and the SERVFAIL rcode.
+			resp := append([]byte{}, buf[:n]...)
+			resp[2] |= 0x80
+			resp[3] = (resp[3] & 0xf0) | 0x02
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+// TestProbeDNSServerConcurrentQueries runs the concurrent A and AAAA queries of the
+// resolver, run it with -race to check the answers are recorded safely.
+func TestProbeDNSServerConcurrentQueries(t *testing.T) {
+	g := NewWithT(t)
+	address := fakeDNSServer(t)
+	for range 5 {
+		g.Expect(probeDNSServer(context.Background(), address)).To(Succeed())
+	}
+}
+
+func TestProbeDNSServerNoAnswer(t *testing.T) {
+	g := NewWithT(t)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	g.Expect(err).NotTo(HaveOccurred())
+	address := conn.LocalAddr().String()
+	g.Expect(conn.Close()).To(Succeed())
+
+	g.Expect(probeDNSServer(context.Background(), address)).NotTo(Succeed())
+}
@@ -2,6 +2,9 @@ package network
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 
@@ -13,12 +16,21 @@ type NetworkInterfaceValidator struct {
 	network     Network
 	validateMTU bool
 	cluster     *types.Cluster
+	// pathMTUProber is used to verify the path MTU to the cluster endpoint, the check is skipped when nil.
+	pathMTUProber PathMTUProber
+	// mtuRanges are the acceptable MTUs for the node IP interface.
+	mtuRanges []MTURange
+	// networkMTURanges override mtuRanges for the node IPs in their networks.
+	networkMTURanges []NetworkMTURanges
+	interfaceForIP   func(nodeIP net.IP) (*net.Interface, error)
 }
 
 func NewNetworkInterfaceValidator(opts ...func(*NetworkInterfaceValidator)) NetworkInterfaceValidator {
 	v := &NetworkInterfaceValidator{
-		network:     NewDefaultNetwork(),
-		validateMTU: true, // Default to true
+		network:        NewDefaultNetwork(),
+		validateMTU:    true, // Default to true
+		mtuRanges:      DefaultMTURanges,
+		interfaceForIP: FindNetworkInterfaceForIP,
 	}
 	for _, opt := range opts {
 		opt(v)
@@ -38,12 +50,46 @@ func WithMTUValidation(validate bool) func(*NetworkInterfaceValidator) {
 	}
 }
 
+// WithMTURanges overrides the acceptable MTUs for the node IP interface, for networks where
+// overlays work with MTUs outside of the default ranges. No ranges keeps the defaults.
+func WithMTURanges(ranges ...MTURange) func(*NetworkInterfaceValidator) {
+	return func(v *NetworkInterfaceValidator) {
+		if len(ranges) > 0 {
+			v.mtuRanges = ranges
+		}
+	}
+}
+
+// WithNetworkMTURanges sets the acceptable MTUs for the node IP interface per remote node network, so sites
+// with different MTUs are each validated against their own. Node IPs outside these networks are validated
+// against the global ranges. The ranges of a network configured several times are combined.
+func WithNetworkMTURanges(networks ...NetworkMTURanges) func(*NetworkInterfaceValidator) {
+	return func(v *NetworkInterfaceValidator) {
+		for _, network := range networks {
+			i := slices.IndexFunc(v.networkMTURanges, func(n NetworkMTURanges) bool { return n.CIDR == network.CIDR })
+			if i < 0 {
+				v.networkMTURanges = append(v.networkMTURanges, NetworkMTURanges{CIDR: network.CIDR})
+				i = len(v.networkMTURanges) - 1
+			}
+			v.networkMTURanges[i].Ranges = append(v.networkMTURanges[i].Ranges, network.Ranges...)
+		}
+	}
+}
+
 func WithCluster(cluster *types.Cluster) func(*NetworkInterfaceValidator) {
 	return func(v *NetworkInterfaceValidator) {
 		v.cluster = cluster
 	}
 }
 
+// WithPathMTUProbe enables verifying the path MTU to the cluster endpoint is not lower than
+// the node interface MTU, using prober to discover it. It requires MTU validation.
+func WithPathMTUProbe(prober PathMTUProber) func(*NetworkInterfaceValidator) {
+	return func(v *NetworkInterfaceValidator) {
+		v.pathMTUProber = prober
+	}
+}
+
 func (v NetworkInterfaceValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
 	var err error
 	name := "network-interface-validation"
@@ -67,19 +113,13 @@ func (v NetworkInterfaceValidator) Run(ctx context.Context, informer validation.
 		return err
 	}
 
-	// Get kubelet arguments from the node configuration
-	kubeletArgs := node.Spec.Kubelet.Flags
-	var iamNodeName string
-	if node.IsIAMRolesAnywhere() {
-		iamNodeName = node.Status.Hybrid.NodeName
-	}
-
 	// Get the node IP using the shared utility function
-	nodeIP, err := GetNodeIP(kubeletArgs, iamNodeName, v.network)
+	nodeIP, err := GetNodeConfigIP(node, v.network)
 	if err != nil {
 		err = validation.WithRemediation(err,
 			"Ensure the node has a valid network interface configuration. "+
-				"Check that the node can resolve its hostname or has a valid --node-ip flag set. "+
+				"Check that the node can resolve its hostname or has a valid --node-ip flag set, and that "+
+				"kubelet.nodeIPInterface, if set, names an interface with an IP on the node. "+
 				"See https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-troubleshooting.html")
 		return err
 	}
@@ -95,15 +135,59 @@ func (v NetworkInterfaceValidator) Run(ctx context.Context, informer validation.
 
 	// Validate MTU for the network interface associated with the node IP (if enabled)
 	if v.validateMTU {
-		if err = ValidateNetworkInterfaceMTUForIP(nodeIP); err != nil {
-			err = validation.WithRemediation(err,
-				"Ensure the network interface with the node IP has a valid MTU value. "+
-					"MTU should be <= 1500 (standard Ethernet) or between 8000-9001 (jumbo frames). "+
-					"Update the network interface configuration to use acceptable MTU values. "+
-					"See https://docs.aws.amazon.com/vpc/latest/tgw/transit-gateway-quotas.html#mtu-quotas")
+		if err = v.validateMTUForIP(nodeIP); err != nil {
 			return err
 		}
+
+		if v.pathMTUProber != nil {
+			if err = v.validatePathMTU(ctx, node, nodeIP); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+// validateMTUForIP checks the MTU of the node IP interface is in the acceptable ranges.
+func (v NetworkInterfaceValidator) validateMTUForIP(nodeIP net.IP) error {
+	ranges, network := MTURangesForIP(nodeIP, v.networkMTURanges, v.mtuRanges)
+	iface, err := v.interfaceForIP(nodeIP)
+	if err == nil {
+		err = validateInterfaceMTU(iface, nodeIP, ranges)
+	}
+	if err != nil {
+		remediation := "MTU should be <= 1500 (standard Ethernet) or between 8000-9001 (jumbo frames). "
+		if network != nil {
+			remediation = fmt.Sprintf("MTU should be in the ranges %s configured for the node network %s. ", joinMTURanges(ranges), network.CIDR)
+		} else if !slices.Equal(ranges, DefaultMTURanges) {
+			remediation = fmt.Sprintf("MTU should be in the configured ranges %s. ", joinMTURanges(ranges))
+		}
+		return validation.WithRemediation(err,
+			"Ensure the network interface with the node IP has a valid MTU value. "+
+				remediation+
+				"Update the network interface configuration to use acceptable MTU values. "+
+				"See https://docs.aws.amazon.com/vpc/latest/tgw/transit-gateway-quotas.html#mtu-quotas")
+	}
+	return nil
+}
+
+// validatePathMTU checks the path MTU to the cluster endpoint against the MTU of the node IP interface.
+func (v NetworkInterfaceValidator) validatePathMTU(ctx context.Context, node *api.NodeConfig, nodeIP net.IP) error {
+	endpoint := node.Spec.Cluster.APIServerEndpoint
+	if endpoint == "" && v.cluster.Endpoint != nil {
+		endpoint = *v.cluster.Endpoint
+	}
+	if endpoint == "" {
+		return nil
+	}
+	endpointIP, err := resolveClusterEndpoint(v.network, endpoint)
+	if err != nil {
+		return validation.WithWarning(err, "Ensure the cluster endpoint resolves from this node.")
+	}
+	iface, err := v.interfaceForIP(nodeIP)
+	if err != nil {
+		return err
+	}
+	return ValidatePathMTU(ctx, v.pathMTUProber, endpointIP, iface.Name, iface.MTU)
+}
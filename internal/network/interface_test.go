@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/netip"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -82,6 +83,35 @@ func TestNetworkInterfaceValidator_Run(t *testing.T) {
 			},
 			expectSuccess: true,
 		},
+		{
+			name: "IPv6 node IP in IPv6 remote node network",
+			nodeConfig: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{
+						Name: "test-cluster",
+					},
+					Kubelet: api.KubeletOptions{
+						Flags: []string{"--node-ip=2001:db8:1::10"},
+					},
+				},
+			},
+			mockEKSClient: &mockEKSClient{
+				cluster: &types.Cluster{
+					Name: aws.String("test-cluster"),
+					RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+						RemoteNodeNetworks: []types.RemoteNodeNetwork{
+							{Cidrs: []string{"10.0.0.0/24", "2001:db8:1::/64"}},
+						},
+					},
+				},
+			},
+			mockNetwork: &mockNetwork{
+				NetworkInterfaces: []net.Addr{
+					&net.IPNet{IP: net.ParseIP("2001:db8:1::10"), Mask: net.CIDRMask(64, 128)},
+				},
+			},
+			expectSuccess: true,
+		},
 		{
 			name: "EKS API error",
 			nodeConfig: &api.NodeConfig{
@@ -676,3 +706,221 @@ func TestMTUValidationIntegrationWithNetworkValidator(t *testing.T) {
 	validator := NewNetworkInterfaceValidator()
 	g.Expect(validator.network).NotTo(BeNil())
 }
+
+func TestNetworkInterfaceValidator_RunMTU(t *testing.T) {
+	tests := []struct {
+		name            string
+		mtu             int
+		opts            []func(*NetworkInterfaceValidator)
+		expectedErr     string
+		wantRemediation string
+	}{
+		{
+			name: "default range",
+			mtu:  1450,
+		},
+		{
+			name:            "between the default ranges",
+			mtu:             1501,
+			expectedErr:     "interface eth0 (IP: 10.0.0.1) has invalid MTU 1501: MTU 1501 is not in acceptable ranges: 68-1500 (standard) or 8000-9001 (jumbo frames)",
+			wantRemediation: "MTU should be <= 1500 (standard Ethernet) or between 8000-9001 (jumbo frames).",
+		},
+		{
+			name:        "jumbo frames out of range",
+			mtu:         9216,
+			expectedErr: "MTU 9216 is not in acceptable ranges",
+		},
+		{
+			name: "in a custom range",
+			mtu:  9216,
+			opts: []func(*NetworkInterfaceValidator){WithMTURanges(MTURange{Min: 1400, Max: 1500}, MTURange{Min: 9000, Max: 9216})},
+		},
+		{
+			name:            "out of a custom range",
+			mtu:             1300,
+			opts:            []func(*NetworkInterfaceValidator){WithMTURanges(MTURange{Min: 1400, Max: 1500})},
+			expectedErr:     "MTU 1300 is not in acceptable ranges: 1400-1500",
+			wantRemediation: "MTU should be in the configured ranges 1400-1500.",
+		},
+		{
+			name: "no custom ranges keeps the defaults",
+			mtu:  1300,
+			opts: []func(*NetworkInterfaceValidator){WithMTURanges()},
+		},
+		{
+			name: "skipped",
+			mtu:  65536,
+			opts: []func(*NetworkInterfaceValidator){WithMTUValidation(false)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			nodeConfig := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{Name: "test-cluster"},
+					Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=10.0.0.1"}},
+				},
+			}
+			opts := append([]func(*NetworkInterfaceValidator){
+				WithNetwork(&mockNetwork{}),
+				WithCluster(&types.Cluster{
+					Name: aws.String("test-cluster"),
+					RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+						RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.0.0.0/24"}}},
+					},
+				}),
+			}, tt.opts...)
+			validator := NewNetworkInterfaceValidator(opts...)
+			validator.interfaceForIP = func(net.IP) (*net.Interface, error) {
+				return &net.Interface{Name: "eth0", MTU: tt.mtu}, nil
+			}
+
+			err := validator.Run(context.Background(), &mockInformer{}, nodeConfig)
+
+			if tt.expectedErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.expectedErr)))
+			g.Expect(validation.Remediation(err)).To(ContainSubstring(tt.wantRemediation))
+		})
+	}
+}
+
+func TestParseMTURange(t *testing.T) {
+	g := NewWithT(t)
+
+	r, err := ParseMTURange("1400-1500")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(r).To(Equal(MTURange{Min: 1400, Max: 1500}))
+	g.Expect(r.String()).To(Equal("1400-1500"))
+
+	for _, invalid := range []string{"1400", "a-1500", "1400-b", "1500-1400", "0-1500"} {
+		_, err := ParseMTURange(invalid)
+		g.Expect(err).To(HaveOccurred(), invalid)
+	}
+}
+
+func TestNetworkInterfaceValidator_RunNetworkMTU(t *testing.T) {
+	jumboSite := NetworkMTURanges{CIDR: netip.MustParsePrefix("10.80.0.0/16"), Ranges: []MTURange{{Min: 9000, Max: 9001}}}
+	standardSite := NetworkMTURanges{CIDR: netip.MustParsePrefix("10.90.0.0/16"), Ranges: []MTURange{{Min: 1400, Max: 1500}}}
+
+	tests := []struct {
+		name            string
+		nodeIP          string
+		mtu             int
+		networks        []NetworkMTURanges
+		expectedErr     string
+		wantRemediation string
+	}{
+		{
+			name:     "jumbo frames site",
+			nodeIP:   "10.80.0.10",
+			mtu:      9001,
+			networks: []NetworkMTURanges{jumboSite, standardSite},
+		},
+		{
+			name:            "standard MTU in the jumbo frames site",
+			nodeIP:          "10.80.0.10",
+			mtu:             1500,
+			networks:        []NetworkMTURanges{jumboSite, standardSite},
+			expectedErr:     "MTU 1500 is not in acceptable ranges: 9000-9001",
+			wantRemediation: "MTU should be in the ranges 9000-9001 configured for the node network 10.80.0.0/16.",
+		},
+		{
+			name:     "standard site",
+			nodeIP:   "10.90.0.10",
+			mtu:      1450,
+			networks: []NetworkMTURanges{jumboSite, standardSite},
+		},
+		{
+			name:            "jumbo frames in the standard site",
+			nodeIP:          "10.90.0.10",
+			mtu:             9001,
+			networks:        []NetworkMTURanges{jumboSite, standardSite},
+			expectedErr:     "MTU 9001 is not in acceptable ranges: 1400-1500",
+			wantRemediation: "configured for the node network 10.90.0.0/16",
+		},
+		{
+			name:     "site without MTU ranges uses the defaults",
+			nodeIP:   "10.70.0.10",
+			mtu:      8500,
+			networks: []NetworkMTURanges{jumboSite, standardSite},
+		},
+		{
+			name:            "site without MTU ranges out of the defaults",
+			nodeIP:          "10.70.0.10",
+			mtu:             1501,
+			networks:        []NetworkMTURanges{jumboSite, standardSite},
+			expectedErr:     "MTU 1501 is not in acceptable ranges: 68-1500 (standard) or 8000-9001 (jumbo frames)",
+			wantRemediation: "MTU should be <= 1500 (standard Ethernet) or between 8000-9001 (jumbo frames).",
+		},
+		{
+			name:   "most specific network",
+			nodeIP: "10.80.1.10",
+			mtu:    1500,
+			networks: []NetworkMTURanges{
+				jumboSite,
+				{CIDR: netip.MustParsePrefix("10.80.1.0/24"), Ranges: []MTURange{{Min: 1400, Max: 1500}}},
+			},
+		},
+		{
+			name:   "ranges of a network configured twice are combined",
+			nodeIP: "10.80.0.10",
+			mtu:    1500,
+			networks: []NetworkMTURanges{
+				jumboSite,
+				{CIDR: netip.MustParsePrefix("10.80.0.0/16"), Ranges: []MTURange{{Min: 1400, Max: 1500}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			nodeConfig := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{Name: "test-cluster"},
+					Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=" + tt.nodeIP}},
+				},
+			}
+			validator := NewNetworkInterfaceValidator(
+				WithNetwork(&mockNetwork{}),
+				WithCluster(&types.Cluster{
+					Name: aws.String("test-cluster"),
+					RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+						RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.70.0.0/16", "10.80.0.0/16", "10.90.0.0/16"}}},
+					},
+				}),
+				WithNetworkMTURanges(tt.networks...),
+			)
+			validator.interfaceForIP = func(net.IP) (*net.Interface, error) {
+				return &net.Interface{Name: "eth0", MTU: tt.mtu}, nil
+			}
+
+			err := validator.Run(context.Background(), &mockInformer{}, nodeConfig)
+
+			if tt.expectedErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.expectedErr)))
+			g.Expect(validation.Remediation(err)).To(ContainSubstring(tt.wantRemediation))
+		})
+	}
+}
+
+func TestParseNetworkMTURange(t *testing.T) {
+	g := NewWithT(t)
+
+	n, err := ParseNetworkMTURange("10.80.1.0/16=9000-9001")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(n).To(Equal(NetworkMTURanges{CIDR: netip.MustParsePrefix("10.80.0.0/16"), Ranges: []MTURange{{Min: 9000, Max: 9001}}}))
+
+	for _, invalid := range []string{"10.80.0.0/16", "10.80.0.0=9000-9001", "10.80.0.0/16=9001-9000", "=1400-1500"} {
+		_, err := ParseNetworkMTURange(invalid)
+		g.Expect(err).To(HaveOccurred(), invalid)
+	}
+}
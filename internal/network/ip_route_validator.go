@@ -0,0 +1,168 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// NodeIPRouteValidator warns when the route to the cluster endpoint doesn't egress
+// through the interface holding the node IP. This happens on multi-homed nodes where
+// the node IP is on one NIC and the default route on another, which can break the
+// connectivity between the control plane and the node.
+type NodeIPRouteValidator struct {
+	network        Network
+	routeSourceIP  func(dst net.IP) (net.IP, error)
+	interfaceForIP func(ip net.IP) (string, error)
+}
+
+// NodeIPRouteValidatorOpt allows to configure the NodeIPRouteValidator.
+type NodeIPRouteValidatorOpt func(*NodeIPRouteValidator)
+
+// NewNodeIPRouteValidator returns a new NodeIPRouteValidator.
+func NewNodeIPRouteValidator(opts ...NodeIPRouteValidatorOpt) NodeIPRouteValidator {
+	v := &NodeIPRouteValidator{
+		network:       NewDefaultNetwork(),
+		routeSourceIP: RouteSourceIP,
+		interfaceForIP: func(ip net.IP) (string, error) {
+			iface, err := FindNetworkInterfaceForIP(ip)
+			if err != nil {
+				return "", err
+			}
+			return iface.Name, nil
+		},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return *v
+}
+
+// WithRouteNetwork sets the network util functions used to determine the node IP
+// and resolve the cluster endpoint.
+func WithRouteNetwork(network Network) NodeIPRouteValidatorOpt {
+	return func(v *NodeIPRouteValidator) {
+		v.network = network
+	}
+}
+
+// WithRouteSourceIP sets the function used to find the source IP the host uses to reach a destination.
+func WithRouteSourceIP(routeSourceIP func(dst net.IP) (net.IP, error)) NodeIPRouteValidatorOpt {
+	return func(v *NodeIPRouteValidator) {
+		v.routeSourceIP = routeSourceIP
+	}
+}
+
+// WithRouteInterfaceLookup sets the function used to find the interface name holding an IP.
+func WithRouteInterfaceLookup(lookup func(ip net.IP) (string, error)) NodeIPRouteValidatorOpt {
+	return func(v *NodeIPRouteValidator) {
+		v.interfaceForIP = lookup
+	}
+}
+
+// Run validates the route to the cluster endpoint egresses through the node IP interface.
+func (v NodeIPRouteValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	name := "node-ip-route-validation"
+	informer.Starting(ctx, name, "Validating the route to the cluster endpoint uses the node IP interface")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	err = v.Validate(node)
+	return err
+}
+
+// Validate checks the interface the host routes the cluster endpoint traffic through.
+// Any failure is returned as a warning.
+func (v NodeIPRouteValidator) Validate(node *api.NodeConfig) error {
+	if node.Spec.Cluster.APIServerEndpoint == "" {
+		return nil
+	}
+
+	nodeIP, err := GetNodeConfigIP(node, v.network)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("determining node IP: %w", err), nodeIPRouteRemediation(nil, ""))
+	}
+
+	endpointIP, err := resolveClusterEndpoint(v.network, node.Spec.Cluster.APIServerEndpoint)
+	if err != nil {
+		return validation.WithWarning(err, "Ensure the cluster endpoint resolves from this node.")
+	}
+
+	sourceIP, err := v.routeSourceIP(endpointIP)
+	if err != nil {
+		return validation.WithWarning(
+			fmt.Errorf("finding route to the cluster endpoint %s: %w", endpointIP, err),
+			nodeIPRouteRemediation(nodeIP, ""))
+	}
+	if sourceIP.Equal(nodeIP) {
+		return nil
+	}
+
+	nodeIface, err := v.interfaceForIP(nodeIP)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("finding interface for node IP %s: %w", nodeIP, err), nodeIPRouteRemediation(nodeIP, ""))
+	}
+	routeIface, err := v.interfaceForIP(sourceIP)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("finding interface for route source IP %s: %w", sourceIP, err), nodeIPRouteRemediation(nodeIP, nodeIface))
+	}
+	if routeIface == nodeIface {
+		return nil
+	}
+
+	return validation.WithWarning(
+		fmt.Errorf("the route to the cluster endpoint %s egresses interface %s with source IP %s, but node IP %s is on interface %s",
+			endpointIP, routeIface, sourceIP, nodeIP, nodeIface),
+		nodeIPRouteRemediation(nodeIP, nodeIface))
+}
+
+// resolveClusterEndpoint returns the first IP of the cluster endpoint host.
+func resolveClusterEndpoint(network Network, endpoint string) (net.IP, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cluster endpoint %s: %w", endpoint, err)
+	}
+	host := endpointURL.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+	ips, err := network.LookupIP(host)
+	if err != nil {
+		return nil, fmt.Errorf("resolving cluster endpoint %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("cluster endpoint %s doesn't resolve to any IP", host)
+	}
+	return ips[0], nil
+}
+
+func nodeIPRouteRemediation(nodeIP net.IP, nodeIface string) string {
+	if nodeIP == nil || nodeIface == "" {
+		return "Ensure the node IP set with the kubelet --node-ip flag is on an interface with a route to the cluster endpoint. " +
+			"If the node has several interfaces, set --node-ip to the IP of the interface used to reach the cluster or configure policy routing for the node IP."
+	}
+	return fmt.Sprintf("Set the kubelet --node-ip flag to the IP of the interface used to reach the cluster, "+
+		"or configure policy routing so traffic from the node IP egresses through %[2]s, for example: "+
+		"'ip rule add from %[1]s table 100' and 'ip route add default via <gateway> dev %[2]s table 100'.", nodeIP, nodeIface)
+}
+
+// RouteSourceIP returns the source IP the host routing table selects to reach dst.
+// It connects a UDP socket, which resolves the route without sending any packet.
+func RouteSourceIP(dst net.IP) (net.IP, error) {
+	conn, err := net.Dial("udp", net.JoinHostPort(dst.String(), "443"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("unexpected local address type %T", conn.LocalAddr())
+	}
+	return addr.IP, nil
+}
@@ -0,0 +1,162 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// fakeRoutes is a multi-homed host where each interface holds one IP
+// and the routing table maps destination IPs to the source IP used to reach them.
+type fakeRoutes struct {
+	interfaces map[string]string
+	routes     map[string]string
+}
+
+func (f fakeRoutes) sourceIP(dst net.IP) (net.IP, error) {
+	src, ok := f.routes[dst.String()]
+	if !ok {
+		return nil, fmt.Errorf("network is unreachable")
+	}
+	return net.ParseIP(src), nil
+}
+
+func (f fakeRoutes) interfaceForIP(ip net.IP) (string, error) {
+	iface, ok := f.interfaces[ip.String()]
+	if !ok {
+		return "", fmt.Errorf("no active network interface found with IP %s", ip)
+	}
+	return iface, nil
+}
+
+func TestNodeIPRouteValidatorRun(t *testing.T) {
+	// eth0 is on the on-prem node network, eth1 on a management network with the default route.
+	multiHomed := map[string]string{
+		"10.0.0.10":    "eth0",
+		"192.168.1.10": "eth1",
+		"192.168.1.11": "eth1",
+	}
+	network := &mockNetwork{
+		DNSRecords: map[string][]net.IP{
+			"abc.gr7.us-west-2.eks.amazonaws.com": {net.ParseIP("172.31.10.5"), net.ParseIP("172.31.20.5")},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		nodeIP   string
+		routes   fakeRoutes
+		wantErr  string
+	}{
+		{
+			name:     "route egresses node IP",
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			nodeIP:   "10.0.0.10",
+			routes:   fakeRoutes{interfaces: multiHomed, routes: map[string]string{"172.31.10.5": "10.0.0.10"}},
+		},
+		{
+			name:     "route egresses another IP on the node IP interface",
+			endpoint: "https://172.31.10.5:443",
+			nodeIP:   "192.168.1.10",
+			routes:   fakeRoutes{interfaces: multiHomed, routes: map[string]string{"172.31.10.5": "192.168.1.11"}},
+		},
+		{
+			name:     "default route on another interface",
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			nodeIP:   "10.0.0.10",
+			routes:   fakeRoutes{interfaces: multiHomed, routes: map[string]string{"172.31.10.5": "192.168.1.10"}},
+			wantErr:  "the route to the cluster endpoint 172.31.10.5 egresses interface eth1 with source IP 192.168.1.10, but node IP 10.0.0.10 is on interface eth0",
+		},
+		{
+			name:     "no route to endpoint",
+			endpoint: "https://172.31.10.5",
+			nodeIP:   "10.0.0.10",
+			routes:   fakeRoutes{interfaces: multiHomed},
+			wantErr:  "finding route to the cluster endpoint 172.31.10.5: network is unreachable",
+		},
+		{
+			name:     "route source IP not on any interface",
+			endpoint: "https://172.31.10.5",
+			nodeIP:   "10.0.0.10",
+			routes:   fakeRoutes{interfaces: multiHomed, routes: map[string]string{"172.31.10.5": "10.1.0.10"}},
+			wantErr:  "finding interface for route source IP 10.1.0.10",
+		},
+		{
+			name:     "endpoint doesn't resolve",
+			endpoint: "https://missing.example.com",
+			nodeIP:   "10.0.0.10",
+			routes:   fakeRoutes{interfaces: multiHomed},
+			wantErr:  "resolving cluster endpoint missing.example.com",
+		},
+		{
+			name:   "no endpoint yet",
+			nodeIP: "10.0.0.10",
+			routes: fakeRoutes{interfaces: multiHomed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			informer := test.NewFakeInformer()
+			node := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{APIServerEndpoint: tt.endpoint},
+					Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=" + tt.nodeIP}},
+				},
+			}
+			v := NewNodeIPRouteValidator(
+				WithRouteNetwork(network),
+				WithRouteSourceIP(tt.routes.sourceIP),
+				WithRouteInterfaceLookup(tt.routes.interfaceForIP),
+			)
+
+			err := v.Run(context.Background(), informer, node)
+			g.Expect(informer.Started).To(BeTrue())
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(validation.IsWarning(err)).To(BeTrue())
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestNodeIPRouteValidatorPolicyRoutingRemediation(t *testing.T) {
+	g := NewWithT(t)
+	routes := fakeRoutes{
+		interfaces: map[string]string{"10.0.0.10": "eth0", "192.168.1.10": "eth1"},
+		routes:     map[string]string{"172.31.10.5": "192.168.1.10"},
+	}
+	node := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{APIServerEndpoint: "https://172.31.10.5"},
+			Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=10.0.0.10"}},
+		},
+	}
+
+	err := NewNodeIPRouteValidator(
+		WithRouteSourceIP(routes.sourceIP),
+		WithRouteInterfaceLookup(routes.interfaceForIP),
+	).Validate(node)
+
+	g.Expect(validation.Remediation(err)).To(ContainSubstring("'ip rule add from 10.0.0.10 table 100'"))
+	g.Expect(validation.Remediation(err)).To(ContainSubstring("dev eth0 table 100"))
+}
+
+func TestRouteSourceIPLoopback(t *testing.T) {
+	g := NewWithT(t)
+	ip, err := RouteSourceIP(net.ParseIP("127.0.0.1"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ip.IsLoopback()).To(BeTrue())
+}
@@ -0,0 +1,107 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const nodeIPStabilityRemediation = "Hybrid nodes must keep the same IP across reboots to remain part of the cluster. " +
+	"Configure a static IP for this interface or a DHCP reservation for this node. " +
+	"If a reservation is already in place, this warning can be ignored or skipped with --skip node-ip-stability-validation."
+
+// NodeIPStabilityValidator warns when the node IP is assigned through DHCP,
+// since the IP might change across reboots if it is not reserved.
+// This is a best effort validation based on the distro's network configuration.
+type NodeIPStabilityValidator struct {
+	network         Network
+	interfaceConfig InterfaceConfig
+	interfaceForIP  func(ip net.IP) (string, error)
+}
+
+// NodeIPStabilityValidatorOpt allows to configure the NodeIPStabilityValidator.
+type NodeIPStabilityValidatorOpt func(*NodeIPStabilityValidator)
+
+// NewNodeIPStabilityValidator returns a new NodeIPStabilityValidator.
+func NewNodeIPStabilityValidator(opts ...NodeIPStabilityValidatorOpt) NodeIPStabilityValidator {
+	v := &NodeIPStabilityValidator{
+		network:         NewDefaultNetwork(),
+		interfaceConfig: NewDistroInterfaceConfig(),
+		interfaceForIP: func(ip net.IP) (string, error) {
+			iface, err := FindNetworkInterfaceForIP(ip)
+			if err != nil {
+				return "", err
+			}
+			return iface.Name, nil
+		},
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return *v
+}
+
+// WithStabilityNetwork sets the network used to resolve the node IP.
+func WithStabilityNetwork(network Network) NodeIPStabilityValidatorOpt {
+	return func(v *NodeIPStabilityValidator) {
+		v.network = network
+	}
+}
+
+// WithInterfaceConfig sets the source of the interfaces configuration.
+func WithInterfaceConfig(config InterfaceConfig) NodeIPStabilityValidatorOpt {
+	return func(v *NodeIPStabilityValidator) {
+		v.interfaceConfig = config
+	}
+}
+
+// WithInterfaceLookup sets the function used to find the interface name for the node IP.
+func WithInterfaceLookup(lookup func(ip net.IP) (string, error)) NodeIPStabilityValidatorOpt {
+	return func(v *NodeIPStabilityValidator) {
+		v.interfaceForIP = lookup
+	}
+}
+
+// Run validates the node IP is not assigned through DHCP.
+func (v NodeIPStabilityValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	name := "node-ip-stability-validation"
+	informer.Starting(ctx, name, "Validating node IP is stable across reboots")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	err = v.Validate(node)
+	return err
+}
+
+// Validate checks the configuration of the interface holding the node IP.
+// Any failure is returned as a warning.
+func (v NodeIPStabilityValidator) Validate(node *api.NodeConfig) error {
+	nodeIP, err := GetNodeConfigIP(node, v.network)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("determining node IP: %w", err), nodeIPStabilityRemediation)
+	}
+
+	iface, err := v.interfaceForIP(nodeIP)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("finding interface for node IP %s: %w", nodeIP, err), nodeIPStabilityRemediation)
+	}
+
+	dhcp, source, err := v.interfaceConfig.IsDHCP(iface)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("reading network configuration for interface %s: %w", iface, err), nodeIPStabilityRemediation)
+	}
+
+	if dhcp {
+		return validation.WithWarning(
+			fmt.Errorf("node IP %s is assigned through DHCP on interface %s (configured in %s) and might change across reboots", nodeIP, iface, source),
+			nodeIPStabilityRemediation,
+		)
+	}
+
+	return nil
+}
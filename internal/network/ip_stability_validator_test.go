@@ -0,0 +1,204 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func writeHostFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	full := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDistroInterfaceConfigIsDHCP(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantDHCP   bool
+		wantSource string
+	}{
+		{
+			name: "netplan dhcp",
+			files: map[string]string{
+				"etc/netplan/50-cloud-init.yaml": "network:\n  version: 2\n  ethernets:\n    eth0:\n      dhcp4: true\n",
+			},
+			wantDHCP:   true,
+			wantSource: "etc/netplan/50-cloud-init.yaml",
+		},
+		{
+			name: "netplan static",
+			files: map[string]string{
+				"etc/netplan/01-static.yaml": "network:\n  version: 2\n  ethernets:\n    eth0:\n      addresses: [10.0.0.10/24]\n",
+			},
+			wantDHCP:   false,
+			wantSource: "etc/netplan/01-static.yaml",
+		},
+		{
+			name: "ifupdown dhcp",
+			files: map[string]string{
+				"etc/network/interfaces": "auto eth0\niface eth0 inet dhcp\n",
+			},
+			wantDHCP:   true,
+			wantSource: "etc/network/interfaces",
+		},
+		{
+			name: "ifcfg static",
+			files: map[string]string{
+				"etc/sysconfig/network-scripts/ifcfg-eth0": "DEVICE=eth0\nBOOTPROTO=\"none\"\nIPADDR=10.0.0.10\n",
+			},
+			wantDHCP:   false,
+			wantSource: "etc/sysconfig/network-scripts/ifcfg-eth0",
+		},
+		{
+			name: "ifcfg dhcp",
+			files: map[string]string{
+				"etc/sysconfig/network-scripts/ifcfg-eth0": "DEVICE=eth0\nBOOTPROTO=dhcp\n",
+			},
+			wantDHCP:   true,
+			wantSource: "etc/sysconfig/network-scripts/ifcfg-eth0",
+		},
+		{
+			name: "network manager dhcp",
+			files: map[string]string{
+				"etc/NetworkManager/system-connections/eth0.nmconnection": "[connection]\nid=eth0\ninterface-name=eth0\n\n[ipv4]\nmethod=auto\n",
+			},
+			wantDHCP:   true,
+			wantSource: "etc/NetworkManager/system-connections/eth0.nmconnection",
+		},
+		{
+			name: "networkd static",
+			files: map[string]string{
+				"etc/systemd/network/10-eth0.network": "[Match]\nName=eth0\n\n[Network]\nAddress=10.0.0.10/24\n",
+			},
+			wantDHCP:   false,
+			wantSource: "etc/systemd/network/10-eth0.network",
+		},
+		{
+			name: "networkd dhcp",
+			files: map[string]string{
+				"etc/systemd/network/10-eth0.network": "[Match]\nName=eth0\n\n[Network]\nDHCP=yes\n",
+			},
+			wantDHCP:   true,
+			wantSource: "etc/systemd/network/10-eth0.network",
+		},
+		{
+			name: "other interface configured",
+			files: map[string]string{
+				"etc/network/interfaces": "iface eth1 inet dhcp\n",
+			},
+			wantDHCP: false,
+		},
+		{
+			name:     "no config",
+			wantDHCP: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			root := t.TempDir()
+			for path, content := range tt.files {
+				writeHostFile(t, root, path, content)
+			}
+
+			dhcp, source, err := DistroInterfaceConfig{Root: root}.IsDHCP("eth0")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(dhcp).To(Equal(tt.wantDHCP))
+			if tt.wantSource == "" {
+				g.Expect(source).To(BeEmpty())
+			} else {
+				g.Expect(source).To(Equal(filepath.Join(root, tt.wantSource)))
+			}
+		})
+	}
+}
+
+type fakeInterfaceConfig struct {
+	dhcp bool
+	err  error
+}
+
+func (f fakeInterfaceConfig) IsDHCP(iface string) (bool, string, error) {
+	return f.dhcp, "/etc/netplan/fake.yaml", f.err
+}
+
+func TestNodeIPStabilityValidatorRun(t *testing.T) {
+	node := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Kubelet: api.KubeletOptions{
+				Flags: []string{"--node-ip=10.0.0.10"},
+			},
+		},
+	}
+	lookup := func(ip net.IP) (string, error) { return "eth0", nil }
+
+	tests := []struct {
+		name    string
+		config  InterfaceConfig
+		lookup  func(ip net.IP) (string, error)
+		wantErr string
+	}{
+		{
+			name:   "static ip",
+			config: fakeInterfaceConfig{dhcp: false},
+			lookup: lookup,
+		},
+		{
+			name:    "dhcp ip",
+			config:  fakeInterfaceConfig{dhcp: true},
+			lookup:  lookup,
+			wantErr: "node IP 10.0.0.10 is assigned through DHCP on interface eth0",
+		},
+		{
+			name:    "error reading config",
+			config:  fakeInterfaceConfig{err: fmt.Errorf("permission denied")},
+			lookup:  lookup,
+			wantErr: "reading network configuration for interface eth0: permission denied",
+		},
+		{
+			name:    "interface not found",
+			config:  fakeInterfaceConfig{},
+			lookup:  func(ip net.IP) (string, error) { return "", fmt.Errorf("not found") },
+			wantErr: "finding interface for node IP 10.0.0.10: not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			informer := test.NewFakeInformer()
+			v := NewNodeIPStabilityValidator(
+				WithInterfaceConfig(tt.config),
+				WithInterfaceLookup(tt.lookup),
+			)
+
+			err := v.Run(context.Background(), informer, node)
+			g.Expect(informer.Started).To(BeTrue())
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(validation.IsWarning(err)).To(BeTrue())
+			g.Expect(validation.Remediation(err)).To(ContainSubstring("DHCP reservation"))
+			g.Expect(informer.DoneWith).To(Equal(err))
+		})
+	}
+}
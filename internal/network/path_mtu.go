@@ -0,0 +1,127 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	// pathMTUProbeAttempts is how many full size probes are sent, giving routers on the
+	// path a few chances to report a lower MTU.
+	pathMTUProbeAttempts = 3
+	pathMTUProbeWait     = 200 * time.Millisecond
+	ipv4UDPHeaders       = 20 + 8
+	ipv6UDPHeaders       = 40 + 8
+)
+
+// PathMTUProber discovers the path MTU to a destination.
+type PathMTUProber interface {
+	// ProbePathMTU returns the path MTU to dst, sending packets of at most mtu bytes.
+	ProbePathMTU(ctx context.Context, dst net.IP, mtu int) (int, error)
+}
+
+// kernelPathMTUProber discovers the path MTU with the kernel path MTU discovery. It sends
+// UDP datagrams with the don't fragment bit set to the destination and reads the MTU
+// the kernel learned from the ICMP "fragmentation needed" replies of the routers on the path.
+// Paths that drop those ICMP replies can't be detected.
+type kernelPathMTUProber struct{}
+
+// NewPathMTUProber returns the default PathMTUProber.
+func NewPathMTUProber() PathMTUProber {
+	return kernelPathMTUProber{}
+}
+
+// ProbePathMTU returns the path MTU to dst.
+func (kernelPathMTUProber) ProbePathMTU(ctx context.Context, dst net.IP, mtu int) (int, error) {
+	level, discoverOpt, discoverDo, mtuOpt, headers := syscall.IPPROTO_IP, syscall.IP_MTU_DISCOVER, syscall.IP_PMTUDISC_DO, syscall.IP_MTU, ipv4UDPHeaders
+	if dst.To4() == nil {
+		level, discoverOpt, discoverDo, mtuOpt, headers = syscall.IPPROTO_IPV6, syscall.IPV6_MTU_DISCOVER, syscall.IPV6_PMTUDISC_DO, syscall.IPV6_MTU, ipv6UDPHeaders
+	}
+
+	dialer := net.Dialer{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				sockErr = syscall.SetsockoptInt(int(fd), level, discoverOpt, discoverDo)
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(dst.String(), "443"))
+	if err != nil {
+		return 0, fmt.Errorf("opening probe socket to %s: %w", dst, err)
+	}
+	defer conn.Close()
+	rawConn, err := conn.(*net.UDPConn).SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	kernelMTU := func() (int, error) {
+		var value int
+		var sockErr error
+		if err := rawConn.Control(func(fd uintptr) {
+			value, sockErr = syscall.GetsockoptInt(int(fd), level, mtuOpt)
+		}); err != nil {
+			return 0, err
+		}
+		return value, sockErr
+	}
+
+	for range pathMTUProbeAttempts {
+		current, err := kernelMTU()
+		if err != nil {
+			return 0, fmt.Errorf("reading path MTU to %s: %w", dst, err)
+		}
+		size := min(current, mtu) - headers
+		if size <= 0 {
+			break
+		}
+		_, err = conn.Write(make([]byte, size))
+		if errors.Is(err, syscall.EMSGSIZE) {
+			// The kernel already knows a lower MTU, probe again with it.
+			continue
+		}
+		// Nothing is expected to listen on the probed port, so the destination can refuse the probes.
+		if err != nil && !errors.Is(err, syscall.ECONNREFUSED) {
+			return 0, fmt.Errorf("sending path MTU probe to %s: %w", dst, err)
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(pathMTUProbeWait):
+		}
+	}
+
+	current, err := kernelMTU()
+	if err != nil {
+		return 0, fmt.Errorf("reading path MTU to %s: %w", dst, err)
+	}
+	return min(current, mtu), nil
+}
+
+// ValidatePathMTU returns a warning if the path MTU to dst is lower than the MTU of the
+// interface the node sends the traffic through. Packets between both sizes are silently
+// dropped when the path blocks ICMP, which makes large responses from the cluster hang.
+func ValidatePathMTU(ctx context.Context, prober PathMTUProber, dst net.IP, iface string, interfaceMTU int) error {
+	pathMTU, err := prober.ProbePathMTU(ctx, dst, interfaceMTU)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("probing path MTU to the cluster endpoint %s: %w", dst, err),
+			"Ensure UDP traffic to the cluster endpoint is allowed so the path MTU can be verified.")
+	}
+	if pathMTU >= interfaceMTU {
+		return nil
+	}
+	return validation.WithWarning(
+		fmt.Errorf("path MTU to the cluster endpoint %s is %d, lower than the MTU %d of interface %s", dst, pathMTU, interfaceMTU, iface),
+		fmt.Sprintf("A VPN or tunnel between the node and the cluster reduces the MTU, so large packets can be dropped and requests to the cluster hang. "+
+			"Set the MTU of interface %s to %d or lower, or configure TCP MSS clamping on the VPN or tunnel gateway, "+
+			"and ensure ICMP 'fragmentation needed' messages are not blocked on the path.", iface, pathMTU))
+}
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+type fakePathMTUProber struct {
+	pathMTU int
+	err     error
+}
+
+func (f fakePathMTUProber) ProbePathMTU(_ context.Context, _ net.IP, mtu int) (int, error) {
+	return min(f.pathMTU, mtu), f.err
+}
+
+func TestValidatePathMTU(t *testing.T) {
+	endpoint := net.ParseIP("10.0.0.10")
+	tests := []struct {
+		name    string
+		prober  fakePathMTUProber
+		wantErr string
+	}{
+		{
+			name:   "path MTU matches the interface MTU",
+			prober: fakePathMTUProber{pathMTU: 1500},
+		},
+		{
+			name:    "path MTU reduced by a tunnel",
+			prober:  fakePathMTUProber{pathMTU: 1400},
+			wantErr: "path MTU to the cluster endpoint 10.0.0.10 is 1400, lower than the MTU 1500 of interface eth0",
+		},
+		{
+			name:    "probe fails",
+			prober:  fakePathMTUProber{err: errors.New("network is unreachable")},
+			wantErr: "probing path MTU to the cluster endpoint 10.0.0.10: network is unreachable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidatePathMTU(context.Background(), tt.prober, endpoint, "eth0", 1500)
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(tt.wantErr))
+			g.Expect(validation.IsWarning(err)).To(BeTrue())
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestValidatePathMTURemediationSuggestsPathMTU(t *testing.T) {
+	g := NewWithT(t)
+	err := ValidatePathMTU(context.Background(), fakePathMTUProber{pathMTU: 1380}, net.ParseIP("10.0.0.10"), "eth0", 1500)
+	g.Expect(validation.Remediation(err)).To(ContainSubstring("Set the MTU of interface eth0 to 1380 or lower"))
+}
+
+func TestKernelPathMTUProberLoopback(t *testing.T) {
+	g := NewWithT(t)
+	mtu, err := NewPathMTUProber().ProbePathMTU(context.Background(), net.ParseIP("127.0.0.1"), 1500)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mtu).To(Equal(1500))
+}
@@ -1,14 +1,88 @@
 package network
 
 import (
+	"bufio"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"golang.org/x/net/http/httpproxy"
 )
 
+const (
+	kubeletProxyDropInPath    = "/etc/systemd/system/kubelet.service.d/http-proxy.conf"
+	containerdProxyDropInPath = "/usr/lib/systemd/system/containerd.service.d/http-proxy.conf"
+)
+
 func IsProxyEnabled() bool {
 	proxyEnv := httpproxy.FromEnvironment()
 	return proxyEnv.HTTPProxy != "" || proxyEnv.HTTPSProxy != "" ||
 		os.Getenv("HTTP_PROXY") != "" || os.Getenv("HTTPS_PROXY") != "" ||
 		os.Getenv("http_proxy") != "" || os.Getenv("https_proxy") != ""
 }
+
+// NoProxy returns the hosts that bypass the proxy, read from NO_PROXY or no_proxy.
+func NoProxy() string {
+	return httpproxy.FromEnvironment().NoProxy
+}
+
+// NodeProxyConfig returns the proxy the node uses. It's read from the environment and, when
+// the environment doesn't set a proxy, like when running from cloud-init, from the systemd
+// drop-ins that configure the proxy for kubelet and containerd.
+func NodeProxyConfig() *httpproxy.Config {
+	return nodeProxyConfig(kubeletProxyDropInPath, containerdProxyDropInPath)
+}
+
+func nodeProxyConfig(dropInPaths ...string) *httpproxy.Config {
+	config := httpproxy.FromEnvironment()
+	if config.HTTPProxy != "" || config.HTTPSProxy != "" {
+		return config
+	}
+	for _, path := range dropInPaths {
+		if dropIn, err := readProxyDropIn(path); err == nil && (dropIn.HTTPProxy != "" || dropIn.HTTPSProxy != "") {
+			return dropIn
+		}
+	}
+	return config
+}
+
+// readProxyDropIn reads the proxy variables from the Environment entries of a systemd drop-in.
+func readProxyDropIn(path string) (*httpproxy.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	config := &httpproxy.Config{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Environment=")
+		if !found {
+			continue
+		}
+		for _, assignment := range strings.Fields(entry) {
+			name, value, _ := strings.Cut(strings.Trim(assignment, `"'`), "=")
+			switch strings.ToUpper(name) {
+			case "HTTP_PROXY":
+				config.HTTPProxy = value
+			case "HTTPS_PROXY":
+				config.HTTPSProxy = value
+			case "NO_PROXY":
+				config.NoProxy = value
+			}
+		}
+	}
+	return config, scanner.Err()
+}
+
+// NewProxyHTTPClient returns an HTTP client that sends requests through the proxy in config.
+func NewProxyHTTPClient(config *httpproxy.Config) *http.Client {
+	proxy := config.ProxyFunc()
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+	return &http.Client{Transport: tr}
+}
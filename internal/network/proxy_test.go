@@ -0,0 +1,86 @@
+package network
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http/httpproxy"
+)
+
+func unsetProxyEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestNodeProxyConfig(t *testing.T) {
+	dir := t.TempDir()
+	kubeletDropIn := filepath.Join(dir, "kubelet-http-proxy.conf")
+	require.NoError(t, os.WriteFile(kubeletDropIn, []byte(`[Service]
+Environment="HTTP_PROXY=http://proxy.example.com:3128"
+Environment="HTTPS_PROXY=http://proxy.example.com:3129" "NO_PROXY=localhost,10.0.0.0/8"
+`), 0o644))
+	emptyDropIn := filepath.Join(dir, "empty-http-proxy.conf")
+	require.NoError(t, os.WriteFile(emptyDropIn, []byte("[Service]\n"), 0o644))
+	missingDropIn := filepath.Join(dir, "missing.conf")
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		dropInPaths []string
+		want        *httpproxy.Config
+	}{
+		{
+			name:        "from environment",
+			env:         map[string]string{"HTTPS_PROXY": "http://env-proxy:8080", "NO_PROXY": "localhost"},
+			dropInPaths: []string{kubeletDropIn},
+			want:        &httpproxy.Config{HTTPSProxy: "http://env-proxy:8080", NoProxy: "localhost"},
+		},
+		{
+			name:        "from drop-in when environment is not set",
+			dropInPaths: []string{missingDropIn, emptyDropIn, kubeletDropIn},
+			want: &httpproxy.Config{
+				HTTPProxy:  "http://proxy.example.com:3128",
+				HTTPSProxy: "http://proxy.example.com:3129",
+				NoProxy:    "localhost,10.0.0.0/8",
+			},
+		},
+		{
+			name:        "no proxy",
+			dropInPaths: []string{missingDropIn, emptyDropIn},
+			want:        &httpproxy.Config{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unsetProxyEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			assert.Equal(t, tt.want, nodeProxyConfig(tt.dropInPaths...))
+		})
+	}
+}
+
+func TestNewProxyHTTPClient(t *testing.T) {
+	client := NewProxyHTTPClient(&httpproxy.Config{
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    "internal.example.com",
+	})
+	tr, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+
+	proxied, err := tr.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "eks.us-west-2.amazonaws.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", proxied.String())
+
+	direct, err := tr.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "internal.example.com"}})
+	require.NoError(t, err)
+	assert.Nil(t, direct)
+}
@@ -44,7 +44,7 @@ func (v ProxyValidator) Validate(node *api.NodeConfig) error {
 	}
 
 	// Skip OS-specific validations in test environment
-	osName := system.GetOsName()
+	osName := system.HostOSInfo().ID
 	if osName != "" {
 		if err := validatePackageManagerProxyConfig(osName); err != nil {
 			return err
@@ -164,14 +164,12 @@ func validateSystemdServiceProxyConfig(serviceName, servicePath string) error {
 
 // validateKubeletProxyConfig checks if Kubelet has valid proxy configuration systemd unit
 func validateKubeletProxyConfig() error {
-	kubeletServicePath := "/etc/systemd/system/kubelet.service.d/http-proxy.conf"
-	return validateSystemdServiceProxyConfig("kubelet", kubeletServicePath)
+	return validateSystemdServiceProxyConfig("kubelet", kubeletProxyDropInPath)
 }
 
 // validateContainerdProxyConfig checks if Containerd has valid proxy configuration systemd unit
 func validateContainerdProxyConfig() error {
-	containerdServicePath := "/usr/lib/systemd/system/containerd.service.d/http-proxy.conf"
-	return validateSystemdServiceProxyConfig("containerd", containerdServicePath)
+	return validateSystemdServiceProxyConfig("containerd", containerdProxyDropInPath)
 }
 
 // validateSSMProxyConfig checks if SSM has valid proxy configuration systemd unit
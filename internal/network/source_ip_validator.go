@@ -0,0 +1,176 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const checkIPURL = "https://checkip.amazonaws.com"
+
+// ObservedSourceIP returns the source IP the cluster endpoint at endpointIP sees for connections
+// from this node. It returns a nil IP when the observed IP can't be determined.
+type ObservedSourceIP func(ctx context.Context, endpointIP net.IP) (net.IP, error)
+
+// NodeSourceIPValidator warns when the node traffic reaches the cluster endpoint with a source IP
+// other than the node IP, outside of the remote node networks. This happens when a NAT between
+// the node and the cluster rewrites the node IP, so the control plane sees an unexpected source.
+// Only publicly routable node IPs are compared: private node IPs are always translated on the way
+// to the internet, so the source IP observed there doesn't tell how the node reaches the cluster.
+type NodeSourceIPValidator struct {
+	cluster          *types.Cluster
+	network          Network
+	observedSourceIP ObservedSourceIP
+	privateMode      bool
+}
+
+// NodeSourceIPValidatorOpt allows to configure the NodeSourceIPValidator.
+type NodeSourceIPValidatorOpt func(*NodeSourceIPValidator)
+
+// NewNodeSourceIPValidator returns a new NodeSourceIPValidator.
+func NewNodeSourceIPValidator(opts ...NodeSourceIPValidatorOpt) NodeSourceIPValidator {
+	v := &NodeSourceIPValidator{
+		network:          NewDefaultNetwork(),
+		observedSourceIP: CheckIPObservedSourceIP,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return *v
+}
+
+// WithSourceIPCluster sets the EKS cluster with the remote node networks.
+func WithSourceIPCluster(cluster *types.Cluster) NodeSourceIPValidatorOpt {
+	return func(v *NodeSourceIPValidator) {
+		v.cluster = cluster
+	}
+}
+
+// WithSourceIPNetwork sets the network util functions used to determine the node IP
+// and resolve the cluster endpoint.
+func WithSourceIPNetwork(network Network) NodeSourceIPValidatorOpt {
+	return func(v *NodeSourceIPValidator) {
+		v.network = network
+	}
+}
+
+// WithObservedSourceIP sets the function used to find the node source IP seen by the cluster endpoint.
+func WithObservedSourceIP(observedSourceIP ObservedSourceIP) NodeSourceIPValidatorOpt {
+	return func(v *NodeSourceIPValidator) {
+		v.observedSourceIP = observedSourceIP
+	}
+}
+
+// WithSourceIPPrivateMode skips the validation for nodes without internet access, since the
+// observed source IP is looked up through a public endpoint.
+func WithSourceIPPrivateMode(privateMode bool) NodeSourceIPValidatorOpt {
+	return func(v *NodeSourceIPValidator) {
+		v.privateMode = privateMode
+	}
+}
+
+// Run validates the node source IP seen by the cluster endpoint.
+func (v NodeSourceIPValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	name := "node-source-ip-validation"
+	informer.Starting(ctx, name, "Validating the source IP the cluster endpoint sees for the node")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+
+	err = v.Validate(ctx, node)
+	return err
+}
+
+// Validate compares the observed source IP with the node IP and the remote node networks.
+// Any failure is returned as a warning.
+func (v NodeSourceIPValidator) Validate(ctx context.Context, node *api.NodeConfig) error {
+	if v.privateMode || node.Spec.Cluster.APIServerEndpoint == "" || v.cluster == nil || v.cluster.RemoteNetworkConfig == nil {
+		return nil
+	}
+
+	nodeIP, err := GetNodeConfigIP(node, v.network)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("determining node IP: %w", err),
+			"Ensure the node can resolve its hostname or has a valid --node-ip flag set.")
+	}
+	if !isPublicIP(nodeIP) {
+		return nil
+	}
+
+	endpointIP, err := resolveClusterEndpoint(v.network, node.Spec.Cluster.APIServerEndpoint)
+	if err != nil {
+		return validation.WithWarning(err, "Ensure the cluster endpoint resolves from this node.")
+	}
+
+	observedIP, err := v.observedSourceIP(ctx, endpointIP)
+	if err != nil {
+		return validation.WithWarning(fmt.Errorf("determining the source IP seen by the cluster endpoint: %w", err),
+			"Ensure the node can reach the cluster endpoint.")
+	}
+	if observedIP == nil || observedIP.Equal(nodeIP) {
+		return nil
+	}
+
+	cidrs := ExtractCIDRsFromNodeNetworks(v.cluster.RemoteNetworkConfig.RemoteNodeNetworks)
+	if inNetwork, err := IsIPInCIDRs(observedIP, cidrs); err == nil && inNetwork {
+		return nil
+	}
+
+	return validation.WithWarning(
+		fmt.Errorf("the cluster endpoint %s sees node traffic from %s instead of node IP %s, and %s is not in any of the remote node networks %s",
+			endpointIP, observedIP, nodeIP, observedIP, cidrs),
+		"A NAT between the node and the cluster rewrites the node source IP. "+
+			"Exempt the remote node network from NAT on the path to the cluster, for example by routing the cluster VPC through a VPN or Direct Connect, "+
+			"and ensure the control plane can reach the node IP in the remote node networks. "+
+			"See https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-networking.html")
+}
+
+// CheckIPObservedSourceIP returns the node public source IP, as echoed by checkip.amazonaws.com,
+// when the cluster endpoint is public since the node reaches both through the same egress.
+// The source IP seen by private endpoints can't be observed, so it returns nil for them.
+func CheckIPObservedSourceIP(ctx context.Context, endpointIP net.IP) (net.IP, error) {
+	if !isPublicIP(endpointIP) {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checkIPURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, checkIPURL)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return nil, err
+	}
+	return parseEchoedIP(string(body))
+}
+
+func parseEchoedIP(body string) (net.IP, error) {
+	ip := net.ParseIP(strings.TrimSpace(body))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q echoed by %s", strings.TrimSpace(body), checkIPURL)
+	}
+	return ip, nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified()
+}
@@ -0,0 +1,162 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func observed(ip string, err error) ObservedSourceIP {
+	return func(_ context.Context, _ net.IP) (net.IP, error) {
+		if err != nil {
+			return nil, err
+		}
+		return net.ParseIP(ip), nil
+	}
+}
+
+func TestNodeSourceIPValidatorRun(t *testing.T) {
+	cluster := &types.Cluster{
+		Name: aws.String("my-cluster"),
+		RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+			RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"198.51.100.0/24"}}},
+		},
+	}
+	network := &mockNetwork{
+		DNSRecords: map[string][]net.IP{
+			"abc.gr7.us-west-2.eks.amazonaws.com": {net.ParseIP("54.10.20.30")},
+		},
+	}
+
+	notObserved := func(context.Context, net.IP) (net.IP, error) {
+		t.Error("the observed source IP must not be looked up")
+		return nil, nil
+	}
+
+	tests := []struct {
+		name        string
+		cluster     *types.Cluster
+		endpoint    string
+		nodeIP      string
+		privateMode bool
+		observed    ObservedSourceIP
+		wantErr     string
+	}{
+		{
+			name:     "source IP preserved",
+			cluster:  cluster,
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			observed: observed("198.51.100.10", nil),
+		},
+		{
+			name:     "source IP rewritten inside the remote node network",
+			cluster:  cluster,
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			observed: observed("198.51.100.200", nil),
+		},
+		{
+			name:     "source IP rewritten to a public IP",
+			cluster:  cluster,
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			observed: observed("203.0.113.7", nil),
+			wantErr:  "the cluster endpoint 54.10.20.30 sees node traffic from 203.0.113.7 instead of node IP 198.51.100.10, and 203.0.113.7 is not in any of the remote node networks [198.51.100.0/24]",
+		},
+		{
+			name:     "observed source IP unknown",
+			cluster:  cluster,
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			observed: observed("", nil),
+		},
+		{
+			name:     "observed source IP lookup fails",
+			cluster:  cluster,
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			observed: observed("", errors.New("connection timed out")),
+			wantErr:  "determining the source IP seen by the cluster endpoint: connection timed out",
+		},
+		{
+			name:     "no remote network config",
+			cluster:  &types.Cluster{Name: aws.String("my-cluster")},
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			observed: observed("203.0.113.7", nil),
+		},
+		{
+			name:     "no endpoint yet",
+			cluster:  cluster,
+			observed: observed("203.0.113.7", nil),
+		},
+		{
+			name:     "private node IP is not compared",
+			cluster:  cluster,
+			endpoint: "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			nodeIP:   "10.0.0.10",
+			observed: notObserved,
+		},
+		{
+			name:        "private mode",
+			cluster:     cluster,
+			endpoint:    "https://abc.gr7.us-west-2.eks.amazonaws.com",
+			privateMode: true,
+			observed:    notObserved,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			informer := test.NewFakeInformer()
+			nodeIP := "198.51.100.10"
+			if tt.nodeIP != "" {
+				nodeIP = tt.nodeIP
+			}
+			node := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{APIServerEndpoint: tt.endpoint},
+					Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=" + nodeIP}},
+				},
+			}
+			v := NewNodeSourceIPValidator(
+				WithSourceIPCluster(tt.cluster),
+				WithSourceIPNetwork(network),
+				WithObservedSourceIP(tt.observed),
+				WithSourceIPPrivateMode(tt.privateMode),
+			)
+
+			err := v.Run(context.Background(), informer, node)
+			g.Expect(informer.Started).To(BeTrue())
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(validation.IsWarning(err)).To(BeTrue())
+			g.Expect(validation.Remediation(err)).NotTo(BeEmpty())
+		})
+	}
+}
+
+func TestCheckIPObservedSourceIPPrivateEndpoint(t *testing.T) {
+	g := NewWithT(t)
+	ip, err := CheckIPObservedSourceIP(context.Background(), net.ParseIP("172.31.10.5"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ip).To(BeNil())
+}
+
+func TestParseEchoedIP(t *testing.T) {
+	g := NewWithT(t)
+	ip, err := parseEchoedIP("203.0.113.7\n")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ip.String()).To(Equal("203.0.113.7"))
+
+	_, err = parseEchoedIP("<html>")
+	g.Expect(err).To(MatchError(ContainSubstring("invalid IP")))
+}
@@ -3,9 +3,14 @@ package network
 import (
 	"fmt"
 	"net"
+	"net/netip"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	apimachinerynet "k8s.io/apimachinery/pkg/util/net"
+
+	"github.com/aws/eks-hybrid/internal/api"
 )
 
 // Network interfaces with the host's network stack.
@@ -13,6 +18,8 @@ type Network interface {
 	LookupIP(host string) ([]net.IP, error)
 	ResolveBindAddress(bindAddress net.IP) (net.IP, error)
 	InterfaceAddrs() ([]net.Addr, error)
+	// InterfaceAddrsByName returns the addresses of the named network interface.
+	InterfaceAddrsByName(name string) ([]net.Addr, error)
 }
 
 // DefaultKubeletNetwork provides the network util functions used by kubelet.
@@ -30,6 +37,14 @@ func (u DefaultKubeletNetwork) InterfaceAddrs() ([]net.Addr, error) {
 	return net.InterfaceAddrs()
 }
 
+func (u DefaultKubeletNetwork) InterfaceAddrsByName(name string) ([]net.Addr, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return iface.Addrs()
+}
+
 // NewDefaultNetwork creates a new instance of DefaultKubeletNetwork
 func NewDefaultNetwork() Network {
 	return &DefaultKubeletNetwork{}
@@ -45,20 +60,33 @@ func ContainsIP(cidr string, ip net.IP) (bool, error) {
 	return ipnet.Contains(ip), nil
 }
 
-// IsIPInCIDRs checks if the given IP is within any of the specified CIDR blocks
+// IsIPInCIDRs checks if the given IP is within any of the specified CIDR blocks.
+// IPv4 and IPv6 CIDRs can be mixed, the IP is only matched against the CIDRs of its family
+// and it's an error when none of the CIDRs is of the IP family.
 func IsIPInCIDRs(ip net.IP, cidrs []string) (bool, error) {
-	if ip.To4() == nil {
+	if ip == nil || ip.To16() == nil {
 		return false, fmt.Errorf("error: ip is invalid")
 	}
+	ipv4 := ip.To4() != nil
 
+	familyFound := false
 	for _, cidr := range cidrs {
-		if inNetwork, err := ContainsIP(cidr, ip); err != nil {
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
 			return false, fmt.Errorf("error checking IP in CIDR %s: %w", cidr, err)
-		} else if inNetwork {
+		}
+		if (ipnet.IP.To4() != nil) != ipv4 {
+			continue
+		}
+		familyFound = true
+		if ipnet.Contains(ip) {
 			return true, nil
 		}
 	}
 
+	if len(cidrs) > 0 && !familyFound {
+		return false, fmt.Errorf("ip %s doesn't match the IP family of any of the CIDR blocks %v", ip, cidrs)
+	}
 	return false, nil
 }
 
@@ -75,6 +103,60 @@ func ExtractCIDRsFromNodeNetworks(networks []types.RemoteNodeNetwork) []string {
 	return cidrs
 }
 
+// ExtractCIDRsFromPodNetworks extracts CIDR blocks from remote pod networks
+func ExtractCIDRsFromPodNetworks(networks []types.RemotePodNetwork) []string {
+	cidrs := make([]string, 0)
+	for _, network := range networks {
+		for _, cidr := range network.Cidrs {
+			if cidr != "" {
+				cidrs = append(cidrs, cidr)
+			}
+		}
+	}
+	return cidrs
+}
+
+// ValidateRemoteNetworksDontOverlap validates that no remote node network CIDR overlaps a remote
+// pod network CIDR, which EKS requires. CIDRs of different IP families never overlap.
+func ValidateRemoteNetworksDontOverlap(config *types.RemoteNetworkConfigResponse) error {
+	if config == nil {
+		return nil
+	}
+	nodeCIDRs, err := parseCIDRs(ExtractCIDRsFromNodeNetworks(config.RemoteNodeNetworks))
+	if err != nil {
+		return fmt.Errorf("parsing remote node networks: %w", err)
+	}
+	podCIDRs, err := parseCIDRs(ExtractCIDRsFromPodNetworks(config.RemotePodNetworks))
+	if err != nil {
+		return fmt.Errorf("parsing remote pod networks: %w", err)
+	}
+
+	var overlaps []string
+	for _, nodeCIDR := range nodeCIDRs {
+		for _, podCIDR := range podCIDRs {
+			if nodeCIDR.Overlaps(podCIDR) {
+				overlaps = append(overlaps, fmt.Sprintf("remote node network %s overlaps remote pod network %s", nodeCIDR, podCIDR))
+			}
+		}
+	}
+	if len(overlaps) > 0 {
+		return fmt.Errorf("remote node and pod networks must not overlap: %s", strings.Join(overlaps, ", "))
+	}
+	return nil
+}
+
+func parseCIDRs(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
 // ExtractFlagValue extracts the value of a specific flag from kubelet arguments
 func ExtractFlagValue(args []string, flag string) string {
 	flagPrefix := "--" + flag + "="
@@ -90,18 +172,29 @@ func ExtractFlagValue(args []string, flag string) string {
 	return flagValue
 }
 
-// ExtractNodeIPFromFlags extracts the node IP from kubelet flags
+// ExtractNodeIPFromFlags extracts the node IP from kubelet flags.
+// For dual-stack nodes, where the flag holds an IPv4 and an IPv6 address, the first one
+// is returned as it's the primary node IP.
 func ExtractNodeIPFromFlags(kubeletArgs []string) (net.IP, error) {
 	ipStr := ExtractFlagValue(kubeletArgs, "node-ip")
 
 	if ipStr != "" {
-		ip := net.ParseIP(ipStr)
-		if ip == nil {
-			return nil, fmt.Errorf("invalid ip %s in --node-ip flag. only 1 IPv4 address is allowed", ipStr)
-		} else if ip.To4() == nil {
-			return nil, fmt.Errorf("invalid IPv6 address %s in --node-ip flag. only IPv4 is supported", ipStr)
+		ipStrs := strings.Split(ipStr, ",")
+		if len(ipStrs) > 2 {
+			return nil, fmt.Errorf("invalid ip %s in --node-ip flag. at most 1 IPv4 and 1 IPv6 address are allowed", ipStr)
+		}
+		var ips []net.IP
+		for _, s := range ipStrs {
+			ip := net.ParseIP(strings.TrimSpace(s))
+			if ip == nil {
+				return nil, fmt.Errorf("invalid ip %s in --node-ip flag. only 1 IPv4 and 1 IPv6 address are allowed", ipStr)
+			}
+			ips = append(ips, ip)
+		}
+		if len(ips) == 2 && (ips[0].To4() != nil) == (ips[1].To4() != nil) {
+			return nil, fmt.Errorf("invalid ip %s in --node-ip flag. dual-stack node IPs must be 1 IPv4 and 1 IPv6 address", ipStr)
 		}
-		return ip, nil
+		return ips[0], nil
 	}
 
 	//--node-ip flag not set
@@ -149,8 +242,24 @@ func ValidateNodeIP(nodeIP net.IP, network Network) error {
 	return fmt.Errorf("node IP: %q not found in the host's network interfaces", nodeIP.String())
 }
 
+// NodeIPOption configures how GetNodeIP determines the node IP.
+type NodeIPOption func(*nodeIPOptions)
+
+type nodeIPOptions struct {
+	iface string
+}
+
+// WithNodeIPInterface makes GetNodeIP take the node IP from the named interface, on nodes with
+// several interfaces where neither DNS nor the default route pick the intended one. A --node-ip
+// flag must be one of the interface IPs. An empty name keeps the default algorithm.
+func WithNodeIPInterface(name string) NodeIPOption {
+	return func(o *nodeIPOptions) {
+		o.iface = name
+	}
+}
+
 // GetNodeIP determines the node's IP address based on kubelet configuration and system information.
-func GetNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP, error) {
+func GetNodeIP(kubeletArgs []string, nodeName string, network Network, opts ...NodeIPOption) (net.IP, error) {
 	// Follows algorithm used by kubelet to assign nodeIP
 	// Implementation adapted for hybrid nodes
 	// 1) Use nodeIP if set (and not "0.0.0.0"/"::")
@@ -158,6 +267,12 @@ func GetNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP,
 	// 3) Lookup the IP from node name by DNS
 	// 4) Try to get the IP from the network interface used as default gateway
 	// Source: https://github.com/kubernetes/kubernetes/blob/master/pkg/kubelet/nodestatus/setters.go#L206
+	// When an interface is configured, its IP takes precedence over 3) and 4).
+
+	var options nodeIPOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
 
 	nodeIP, err := ExtractNodeIPFromFlags(kubeletArgs)
 	if err != nil {
@@ -166,7 +281,13 @@ func GetNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP,
 
 	var ipAddr net.IP
 
-	nodeIPSpecified := nodeIP != nil && nodeIP.To4() != nil && !nodeIP.IsUnspecified()
+	nodeIPSpecified := nodeIP != nil && !nodeIP.IsUnspecified()
+	// Like kubelet, prefer addresses of the node IP family, or IPv4 when unset.
+	preferIPv6 := nodeIP != nil && nodeIP.To4() == nil
+
+	if options.iface != "" {
+		return getInterfaceNodeIP(options.iface, nodeIP, nodeIPSpecified, preferIPv6, network)
+	}
 
 	if nodeIPSpecified {
 		ipAddr = nodeIP
@@ -176,9 +297,14 @@ func GetNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP,
 		if nodeName != "" {
 			addrs, _ := network.LookupIP(nodeName)
 			for _, addr := range addrs {
-				if err = ValidateNodeIP(addr, network); addr.To4() != nil && err == nil {
+				if ValidateNodeIP(addr, network) != nil {
+					continue
+				}
+				if (addr.To4() == nil) == preferIPv6 {
 					ipAddr = addr
 					break
+				} else if ipAddr == nil {
+					ipAddr = addr
 				}
 			}
 		}
@@ -196,6 +322,73 @@ func GetNodeIP(kubeletArgs []string, nodeName string, network Network) (net.IP,
 	return ipAddr, nil
 }
 
+// GetNodeConfigIP determines the node IP kubelet will register for node, from its kubelet flags, node
+// IP interface and, for IAM Roles Anywhere, its node name. It's the node IP all the validations check.
+func GetNodeConfigIP(node *api.NodeConfig, network Network) (net.IP, error) {
+	// If using SSM, the node name is set at initialization to the SSM instance ID,
+	// so it's only used to resolve the node IP with IAM Roles Anywhere.
+	var iamNodeName string
+	if node.IsIAMRolesAnywhere() {
+		iamNodeName = node.Status.Hybrid.NodeName
+	}
+	return GetNodeIP(node.Spec.Kubelet.Flags, iamNodeName, network, WithNodeIPInterface(node.Spec.Kubelet.NodeIPInterface))
+}
+
+// getInterfaceNodeIP returns the node IP from the addresses of iface: nodeIP when it's specified
+// and on the interface, otherwise the first valid address, preferring IPv6 ones if preferIPv6.
+func getInterfaceNodeIP(iface string, nodeIP net.IP, nodeIPSpecified, preferIPv6 bool, network Network) (net.IP, error) {
+	addrs, err := network.InterfaceAddrsByName(iface)
+	if err != nil {
+		return nil, fmt.Errorf("getting addresses of node IP interface %s: %w", iface, err)
+	}
+
+	var ipAddr net.IP
+	for _, addr := range addrs {
+		ip := addrIP(addr)
+		if ip == nil {
+			continue
+		}
+		if nodeIPSpecified {
+			if ip.Equal(nodeIP) {
+				ipAddr = ip
+				break
+			}
+			continue
+		}
+		if ValidateNodeIP(ip, network) != nil {
+			continue
+		}
+		if (ip.To4() == nil) == preferIPv6 {
+			ipAddr = ip
+			break
+		} else if ipAddr == nil {
+			ipAddr = ip
+		}
+	}
+
+	if nodeIPSpecified && ipAddr == nil {
+		return nil, fmt.Errorf("node IP %s from --node-ip flag is not an address of node IP interface %s", nodeIP, iface)
+	}
+	if ipAddr == nil {
+		return nil, fmt.Errorf("node IP interface %s has no valid IP address", iface)
+	}
+	// Confirm the host reports the interface IP, like for the other node IP sources.
+	if err := ValidateNodeIP(ipAddr, network); err != nil {
+		return nil, err
+	}
+	return ipAddr, nil
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch v := addr.(type) {
+	case *net.IPNet:
+		return v.IP
+	case *net.IPAddr:
+		return v.IP
+	}
+	return nil
+}
+
 // ValidateIPInRemoteNodeNetwork validates that the given IP is within the remote node networks
 func ValidateIPInRemoteNodeNetwork(ipAddr net.IP, remoteNodeNetwork []types.RemoteNodeNetwork) error {
 	nodeNetworkCidrs := ExtractCIDRsFromNodeNetworks(remoteNodeNetwork)
@@ -216,30 +409,125 @@ func ValidateClusterRemoteNetworkConfig(cluster *types.Cluster) error {
 	if cluster.RemoteNetworkConfig == nil {
 		return fmt.Errorf("remote network config is not set for cluster %s", *cluster.Name)
 	}
-	if cluster.RemoteNetworkConfig.RemoteNodeNetworks == nil {
+	if len(cluster.RemoteNetworkConfig.RemoteNodeNetworks) == 0 {
 		return fmt.Errorf("remote node networks not found in remote network config for cluster %s", *cluster.Name)
 	}
 	return nil
 }
 
+// MTURange is an inclusive range of acceptable network interface MTUs.
+type MTURange struct {
+	Min, Max int
+	// Name describes the range in errors, like standard or jumbo frames.
+	Name string
+}
+
+func (r MTURange) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%d-%d", r.Min, r.Max)
+	}
+	return fmt.Sprintf("%d-%d (%s)", r.Min, r.Max, r.Name)
+}
+
+// DefaultMTURanges are the MTUs supported on the path to the cluster: standard Ethernet, where 68 is
+// the minimum IPv4 MTU, and jumbo frames up to the 9001 supported by AWS.
+var DefaultMTURanges = []MTURange{
+	{Min: 68, Max: 1500, Name: "standard"},
+	{Min: 8000, Max: 9001, Name: "jumbo frames"},
+}
+
+// ParseMTURange parses an MTU range in min-max form, like 1400-1500.
+func ParseMTURange(value string) (MTURange, error) {
+	minValue, maxValue, found := strings.Cut(value, "-")
+	if !found {
+		return MTURange{}, fmt.Errorf("invalid MTU range %q, expected min-max, for example 1400-1500", value)
+	}
+	minMTU, err := strconv.Atoi(strings.TrimSpace(minValue))
+	if err != nil {
+		return MTURange{}, fmt.Errorf("invalid MTU range %q, min must be a number: %w", value, err)
+	}
+	maxMTU, err := strconv.Atoi(strings.TrimSpace(maxValue))
+	if err != nil {
+		return MTURange{}, fmt.Errorf("invalid MTU range %q, max must be a number: %w", value, err)
+	}
+	if minMTU <= 0 || minMTU > maxMTU {
+		return MTURange{}, fmt.Errorf("invalid MTU range %q, min must be positive and not greater than max", value)
+	}
+	return MTURange{Min: minMTU, Max: maxMTU}, nil
+}
+
+// NetworkMTURanges are the acceptable MTUs for the interfaces of the node IPs in a remote node network,
+// for sites with different MTUs, like a site with jumbo frames and one with standard Ethernet.
+type NetworkMTURanges struct {
+	CIDR   netip.Prefix
+	Ranges []MTURange
+}
+
+// ParseNetworkMTURange parses an MTU range for a network in cidr=min-max form, like 10.80.0.0/16=8000-9001.
+func ParseNetworkMTURange(value string) (NetworkMTURanges, error) {
+	cidr, mtuRange, found := strings.Cut(value, "=")
+	if !found {
+		return NetworkMTURanges{}, fmt.Errorf("invalid network MTU range %q, expected cidr=min-max, for example 10.80.0.0/16=8000-9001", value)
+	}
+	prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return NetworkMTURanges{}, fmt.Errorf("invalid network MTU range %q, invalid CIDR: %w", value, err)
+	}
+	r, err := ParseMTURange(mtuRange)
+	if err != nil {
+		return NetworkMTURanges{}, err
+	}
+	return NetworkMTURanges{CIDR: prefix.Masked(), Ranges: []MTURange{r}}, nil
+}
+
+// MTURangesForIP returns the MTU ranges of the most specific network containing ip, and the
+// network, or the defaults when no network contains it.
+func MTURangesForIP(ip net.IP, networks []NetworkMTURanges, defaults []MTURange) ([]MTURange, *NetworkMTURanges) {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return defaults, nil
+	}
+	addr = addr.Unmap()
+	var match *NetworkMTURanges
+	for i := range networks {
+		network := &networks[i]
+		if network.CIDR.Contains(addr) && (match == nil || network.CIDR.Bits() > match.CIDR.Bits()) {
+			match = network
+		}
+	}
+	if match == nil || len(match.Ranges) == 0 {
+		return defaults, nil
+	}
+	return match.Ranges, match
+}
+
 // ValidateMTU validates that the MTU value is within acceptable ranges
 // MTU should be <= 1500 (standard Ethernet) or between 8000-9001 (jumbo frames)
 func ValidateMTU(mtu int) error {
+	return ValidateMTUInRanges(mtu, DefaultMTURanges)
+}
+
+// ValidateMTUInRanges validates that the MTU value is within one of ranges.
+func ValidateMTUInRanges(mtu int, ranges []MTURange) error {
 	if mtu <= 0 {
 		return fmt.Errorf("MTU must be a positive value, got %d", mtu)
 	}
 
-	// Standard Ethernet MTU range (68 is minimum IPv4 MTU, 1500 is standard Ethernet)
-	if mtu >= 68 && mtu <= 1500 {
-		return nil
+	for _, r := range ranges {
+		if mtu >= r.Min && mtu <= r.Max {
+			return nil
+		}
 	}
 
-	// Jumbo frame MTU range
-	if mtu >= 8000 && mtu <= 9001 {
-		return nil
-	}
+	return fmt.Errorf("MTU %d is not in acceptable ranges: %s", mtu, joinMTURanges(ranges))
+}
 
-	return fmt.Errorf("MTU %d is not in acceptable ranges: 68-1500 (standard) or 8000-9001 (jumbo frames)", mtu)
+func joinMTURanges(ranges []MTURange) string {
+	names := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		names = append(names, r.String())
+	}
+	return strings.Join(names, " or ")
 }
 
 // FindNetworkInterfaceForIP finds the network interface that has the given IP address
@@ -286,8 +574,11 @@ func ValidateNetworkInterfaceMTUForIP(nodeIP net.IP) error {
 	if err != nil {
 		return err
 	}
+	return validateInterfaceMTU(iface, nodeIP, DefaultMTURanges)
+}
 
-	if err := ValidateMTU(iface.MTU); err != nil {
+func validateInterfaceMTU(iface *net.Interface, nodeIP net.IP, ranges []MTURange) error {
+	if err := ValidateMTUInRanges(iface.MTU, ranges); err != nil {
 		return fmt.Errorf("interface %s (IP: %s) has invalid MTU %d: %w", iface.Name, nodeIP.String(), iface.MTU, err)
 	}
 
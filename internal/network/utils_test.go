@@ -7,6 +7,9 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/test"
 )
 
 func TestContainsIP(t *testing.T) {
@@ -162,12 +165,33 @@ func TestIsIPInCIDRs(t *testing.T) {
 			wantErr:  false,
 		},
 		{
-			name:     "Invalid IP (IPv6)",
+			name:     "IPv6 IP with only IPv4 CIDRs",
 			ip:       net.ParseIP("2001:db8::1"),
 			cidrs:    []string{"10.0.0.0/24"},
 			expected: false,
 			wantErr:  true,
 		},
+		{
+			name:     "IPv6 IP in mixed CIDRs",
+			ip:       net.ParseIP("2001:db8::1"),
+			cidrs:    []string{"10.0.0.0/24", "2001:db8::/64"},
+			expected: true,
+			wantErr:  false,
+		},
+		{
+			name:     "IPv4 IP in mixed CIDRs",
+			ip:       net.ParseIP("10.0.0.1"),
+			cidrs:    []string{"2001:db8::/64", "10.0.0.0/24"},
+			expected: true,
+			wantErr:  false,
+		},
+		{
+			name:     "IPv6 IP not in IPv6 CIDR of mixed CIDRs",
+			ip:       net.ParseIP("2001:db9::1"),
+			cidrs:    []string{"10.0.0.0/24", "2001:db8::/64"},
+			expected: false,
+			wantErr:  false,
+		},
 		{
 			name:     "Invalid CIDR in list",
 			ip:       net.ParseIP("10.0.0.1"),
@@ -414,9 +438,21 @@ func TestExtractNodeIPFromFlags(t *testing.T) {
 		{
 			name:        "IPv6 address",
 			kubeletArgs: []string{"--node-ip=2001:db8::1"},
+			expected:    net.ParseIP("2001:db8::1"),
+			wantErr:     false,
+		},
+		{
+			name:        "Dual-stack addresses",
+			kubeletArgs: []string{"--node-ip=2001:db8::1,10.0.0.1"},
+			expected:    net.ParseIP("2001:db8::1"),
+			wantErr:     false,
+		},
+		{
+			name:        "Two IPv4 addresses",
+			kubeletArgs: []string{"--node-ip=10.0.0.1,10.0.0.2"},
 			expected:    nil,
 			wantErr:     true,
-			errContains: "invalid IPv6 address 2001:db8::1 in --node-ip flag",
+			errContains: "dual-stack node IPs must be 1 IPv4 and 1 IPv6 address",
 		},
 		{
 			name:        "Empty kubelet args",
@@ -708,6 +744,48 @@ func TestGetNodeIP(t *testing.T) {
 			expected: net.ParseIP("10.0.0.6"),
 			wantErr:  false,
 		},
+		{
+			name:        "Node IP from IPv6 flag",
+			kubeletArgs: []string{"--node-ip=2001:db8::10"},
+			network: &mockNetwork{
+				NetworkInterfaces: []net.Addr{
+					&net.IPNet{IP: net.ParseIP("2001:db8::10"), Mask: net.CIDRMask(64, 128)},
+				},
+			},
+			expected: net.ParseIP("2001:db8::10"),
+			wantErr:  false,
+		},
+		{
+			name:        "DNS resolution prefers IPv4 over IPv6",
+			kubeletArgs: []string{},
+			nodeName:    "test-node",
+			network: &mockNetwork{
+				DNSRecords: map[string][]net.IP{
+					"test-node": {net.ParseIP("2001:db8::11"), net.ParseIP("10.0.0.11")},
+				},
+				NetworkInterfaces: []net.Addr{
+					&net.IPNet{IP: net.ParseIP("2001:db8::11"), Mask: net.CIDRMask(64, 128)},
+					&net.IPNet{IP: net.ParseIP("10.0.0.11"), Mask: net.CIDRMask(24, 32)},
+				},
+			},
+			expected: net.ParseIP("10.0.0.11"),
+			wantErr:  false,
+		},
+		{
+			name:        "DNS resolution with only IPv6 IP",
+			kubeletArgs: []string{},
+			nodeName:    "test-node",
+			network: &mockNetwork{
+				DNSRecords: map[string][]net.IP{
+					"test-node": {net.ParseIP("2001:db8::12")},
+				},
+				NetworkInterfaces: []net.Addr{
+					&net.IPNet{IP: net.ParseIP("2001:db8::12"), Mask: net.CIDRMask(64, 128)},
+				},
+			},
+			expected: net.ParseIP("2001:db8::12"),
+			wantErr:  false,
+		},
 		{
 			name:        "DNS resolution with invalid IP - should skip",
 			kubeletArgs: []string{},
@@ -781,6 +859,184 @@ func TestGetNodeIP(t *testing.T) {
 	}
 }
 
+func TestGetNodeIPWithInterface(t *testing.T) {
+	eth0 := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("203.0.113.10"), Mask: net.CIDRMask(24, 32)},
+	}
+	eth1 := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("2001:db8::20"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("10.0.0.20"), Mask: net.CIDRMask(24, 32)},
+	}
+	lo := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.ParseIP("::1"), Mask: net.CIDRMask(128, 128)},
+	}
+	// newNetwork stubs a host with a public default route interface and a private one, where
+	// DNS and the default route resolve to the public IP.
+	newNetwork := func() *mockNetwork {
+		network := &mockNetwork{
+			DNSRecords: map[string][]net.IP{
+				"test-node": {net.ParseIP("203.0.113.10")},
+			},
+			ResolvedBindAddr: net.ParseIP("203.0.113.10"),
+			NamedInterfaces: map[string][]net.Addr{
+				"eth0": eth0,
+				"eth1": eth1,
+				"lo":   lo,
+			},
+		}
+		for _, addrs := range network.NamedInterfaces {
+			network.NetworkInterfaces = append(network.NetworkInterfaces, addrs...)
+		}
+		return network
+	}
+
+	tests := []struct {
+		name        string
+		kubeletArgs []string
+		iface       string
+		expected    net.IP
+		errContains string
+	}{
+		{
+			name:     "No interface uses DNS resolution",
+			expected: net.ParseIP("203.0.113.10"),
+		},
+		{
+			name:     "Interface IP takes precedence over DNS and default route",
+			iface:    "eth1",
+			expected: net.ParseIP("10.0.0.20"),
+		},
+		{
+			name:        "Interface with node IP flag on the interface",
+			kubeletArgs: []string{"--node-ip=2001:db8::20"},
+			iface:       "eth1",
+			expected:    net.ParseIP("2001:db8::20"),
+		},
+		{
+			name:        "Interface with unspecified IPv6 node IP flag prefers IPv6",
+			kubeletArgs: []string{"--node-ip=::"},
+			iface:       "eth1",
+			expected:    net.ParseIP("2001:db8::20"),
+		},
+		{
+			name:        "Interface with node IP flag on another interface",
+			kubeletArgs: []string{"--node-ip=203.0.113.10"},
+			iface:       "eth1",
+			errContains: "node IP 203.0.113.10 from --node-ip flag is not an address of node IP interface eth1",
+		},
+		{
+			name:        "Interface not found",
+			iface:       "eth2",
+			errContains: "getting addresses of node IP interface eth2",
+		},
+		{
+			name:        "Interface with only loopback IPs",
+			iface:       "lo",
+			errContains: "node IP interface lo has no valid IP address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			result, err := GetNodeIP(tt.kubeletArgs, "test-node", newNetwork(), WithNodeIPInterface(tt.iface))
+
+			if tt.errContains != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.errContains)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.Equal(tt.expected)).To(BeTrue(), "expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestGetNodeConfigIP(t *testing.T) {
+	network := &mockNetwork{
+		DNSRecords: map[string][]net.IP{
+			"my-node": {net.ParseIP("10.0.0.1")},
+		},
+		ResolvedBindAddr: net.ParseIP("10.0.0.2"),
+		NetworkInterfaces: []net.Addr{
+			&net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(24, 32)},
+			&net.IPNet{IP: net.ParseIP("10.0.0.2"), Mask: net.CIDRMask(24, 32)},
+			&net.IPNet{IP: net.ParseIP("10.0.0.3"), Mask: net.CIDRMask(24, 32)},
+		},
+		NamedInterfaces: map[string][]net.Addr{
+			"eth1": {&net.IPNet{IP: net.ParseIP("10.0.0.3"), Mask: net.CIDRMask(24, 32)}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		node     *api.NodeConfig
+		expected net.IP
+	}{
+		{
+			name: "IAM Roles Anywhere resolves the node name",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Hybrid: &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"}},
+				},
+				Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+			},
+			expected: net.ParseIP("10.0.0.1"),
+		},
+		{
+			name: "SSM doesn't resolve the node name",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Hybrid: &api.HybridOptions{SSM: &api.SSM{}},
+				},
+				Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+			},
+			expected: net.ParseIP("10.0.0.2"),
+		},
+		{
+			name: "node IP flag",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=10.0.0.3"}},
+					Hybrid:  &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"}},
+				},
+				Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+			},
+			expected: net.ParseIP("10.0.0.3"),
+		},
+		{
+			name: "node IP interface",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Kubelet: api.KubeletOptions{NodeIPInterface: "eth1"},
+					Hybrid:  &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"}},
+				},
+				Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+			},
+			expected: net.ParseIP("10.0.0.3"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			nodeIP, err := GetNodeConfigIP(tt.node, network)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(nodeIP.Equal(tt.expected)).To(BeTrue(), "expected %s, got %s", tt.expected, nodeIP)
+
+			// The stability validation checks the interface of the same node IP.
+			stability := NewNodeIPStabilityValidator(
+				WithStabilityNetwork(network),
+				WithInterfaceLookup(func(ip net.IP) (string, error) {
+					return "", fmt.Errorf("no interface")
+				}),
+			)
+			g.Expect(stability.Validate(tt.node)).To(MatchError(fmt.Sprintf("finding interface for node IP %s: no interface", nodeIP)))
+		})
+	}
+}
+
 func TestValidateIPInRemoteNodeNetwork(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -814,6 +1070,32 @@ func TestValidateIPInRemoteNodeNetwork(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "IPv6 IP in IPv6 remote network",
+			ipAddr: net.ParseIP("2001:db8:1::10"),
+			remoteNodeNetwork: []types.RemoteNodeNetwork{
+				{Cidrs: []string{"10.0.0.0/24", "2001:db8:1::/64"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "IPv6 IP not in IPv6 remote network",
+			ipAddr: net.ParseIP("2001:db8:2::10"),
+			remoteNodeNetwork: []types.RemoteNodeNetwork{
+				{Cidrs: []string{"2001:db8:1::/64"}},
+			},
+			wantErr:     true,
+			errContains: "node IP 2001:db8:2::10 is not in any of the remote network CIDR blocks",
+		},
+		{
+			name:   "IPv6 IP with IPv4 remote network",
+			ipAddr: net.ParseIP("2001:db8:1::10"),
+			remoteNodeNetwork: []types.RemoteNodeNetwork{
+				{Cidrs: []string{"10.0.0.0/24"}},
+			},
+			wantErr:     true,
+			errContains: "doesn't match the IP family of any of the CIDR blocks",
+		},
 	}
 
 	for _, tt := range tests {
@@ -887,6 +1169,103 @@ func TestValidateClusterRemoteNetworkConfig(t *testing.T) {
 	}
 }
 
+func TestValidateRemoteNetworksDontOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *types.RemoteNetworkConfigResponse
+		errContains []string
+	}{
+		{
+			name: "Disjoint IPv4 networks",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16", "10.81.0.0/16"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.85.0.0/16"}}},
+			},
+		},
+		{
+			name: "Adjacent IPv4 networks",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/17"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.80.128.0/17"}}},
+			},
+		},
+		{
+			name: "No remote pod networks",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+			},
+		},
+		{
+			name: "Pod network inside node network",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.85.0.0/16", "10.80.128.0/24"}}},
+			},
+			errContains: []string{"remote node network 10.80.0.0/16 overlaps remote pod network 10.80.128.0/24"},
+		},
+		{
+			name: "Node network inside pod network across network entries",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"192.168.0.0/24"}}, {Cidrs: []string{"172.16.5.0/24"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"192.168.0.0/16"}}, {Cidrs: []string{"172.16.0.0/12"}}},
+			},
+			errContains: []string{
+				"remote node network 192.168.0.0/24 overlaps remote pod network 192.168.0.0/16",
+				"remote node network 172.16.5.0/24 overlaps remote pod network 172.16.0.0/12",
+			},
+		},
+		{
+			name: "Identical CIDRs with host bits set",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.1/16"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+			},
+			errContains: []string{"remote node network 10.80.0.0/16 overlaps remote pod network 10.80.0.0/16"},
+		},
+		{
+			name: "Disjoint mixed families",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16", "2001:db8:1::/48"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.85.0.0/16", "2001:db8:2::/48"}}},
+			},
+		},
+		{
+			name: "Overlapping IPv6 in mixed families",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16", "2001:db8::/32"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.85.0.0/16", "2001:db8:2::/48"}}},
+			},
+			errContains: []string{"remote node network 2001:db8::/32 overlaps remote pod network 2001:db8:2::/48"},
+		},
+		{
+			name: "Invalid pod CIDR",
+			config: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+				RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.85.0.0"}}},
+			},
+			errContains: []string{"parsing remote pod networks: invalid CIDR 10.85.0.0"},
+		},
+		{
+			name: "No remote network config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := ValidateRemoteNetworksDontOverlap(tt.config)
+
+			if len(tt.errContains) == 0 {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			for _, contains := range tt.errContains {
+				g.Expect(err).To(MatchError(ContainSubstring(contains)))
+			}
+		})
+	}
+}
+
 func TestDefaultKubeletNetwork(t *testing.T) {
 	g := NewWithT(t)
 
@@ -913,32 +1292,7 @@ func TestDefaultKubeletNetwork(t *testing.T) {
 }
 
 // mockNetwork implements the Network interface for testing
-type mockNetwork struct {
-	DNSRecords        map[string][]net.IP
-	ResolvedBindAddr  net.IP
-	BindAddrErr       error
-	NetworkInterfaces []net.Addr
-	InterfacesErr     error
-}
-
-func (m *mockNetwork) LookupIP(host string) ([]net.IP, error) {
-	if ips, exists := m.DNSRecords[host]; exists {
-		return ips, nil
-	}
-	return nil, &net.DNSError{
-		Err:        "no such host",
-		Name:       host,
-		IsNotFound: true,
-	}
-}
-
-func (m *mockNetwork) ResolveBindAddress(bindAddress net.IP) (net.IP, error) {
-	return m.ResolvedBindAddr, m.BindAddrErr
-}
-
-func (m *mockNetwork) InterfaceAddrs() ([]net.Addr, error) {
-	return m.NetworkInterfaces, m.InterfacesErr
-}
+type mockNetwork = test.FakeNetwork
 
 func TestValidateMTU(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,201 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	k8s "github.com/aws/eks-hybrid/internal/kubernetes"
+	"github.com/aws/eks-hybrid/internal/retry"
+)
+
+const (
+	defaultDrainTimeout      = 10 * time.Minute
+	defaultDrainPollInterval = 5 * time.Second
+)
+
+// CordonOptions configures RunCordoned.
+type CordonOptions struct {
+	// Drain evicts the pods running on the node after cordoning it.
+	Drain bool
+	// DrainTimeout is the maximum time to wait for the node to be drained.
+	// Defaults to 10 minutes.
+	DrainTimeout time.Duration
+	// DrainPollInterval is the time between eviction attempts while draining.
+	// Defaults to 5 seconds.
+	DrainPollInterval time.Duration
+	// DrainGracePeriod overrides the termination grace period of the evicted pods.
+	// Zero keeps the grace period of each pod.
+	DrainGracePeriod time.Duration
+	Logger           *zap.Logger
+}
+
+// RunCordoned cordons the node, optionally drains it, and runs fn. The node is
+// uncordoned only if fn succeeds, so a node left in a broken state doesn't take
+// new workloads. A node that was already cordoned is left cordoned.
+func RunCordoned(ctx context.Context, client kubernetes.Interface, nodeName string, opts CordonOptions, fn func(context.Context) error) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	logger.Info("Cordoning node...", zap.String("node", nodeName))
+	alreadyCordoned, err := Cordon(ctx, client, nodeName)
+	if err != nil {
+		return err
+	}
+
+	if opts.Drain {
+		logger.Info("Draining node...", zap.String("node", nodeName))
+		if err := Drain(ctx, client, nodeName, opts); err != nil {
+			return err
+		}
+	}
+
+	if err := fn(ctx); err != nil {
+		logger.Warn("Leaving node cordoned after failure", zap.String("node", nodeName))
+		return err
+	}
+
+	if alreadyCordoned {
+		logger.Info("Node was cordoned before starting, leaving it cordoned", zap.String("node", nodeName))
+		return nil
+	}
+
+	logger.Info("Uncordoning node...", zap.String("node", nodeName))
+	return Uncordon(ctx, client, nodeName)
+}
+
+// CordonAndDrain cordons the node and drains it. The node is left cordoned, for
+// callers that are about to remove the workloads of the node for good.
+func CordonAndDrain(ctx context.Context, client kubernetes.Interface, nodeName string, opts CordonOptions) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	logger.Info("Cordoning node...", zap.String("node", nodeName))
+	if _, err := Cordon(ctx, client, nodeName); err != nil {
+		return err
+	}
+	logger.Info("Draining node...", zap.String("node", nodeName))
+	return Drain(ctx, client, nodeName, opts)
+}
+
+// Cordon marks the node as unschedulable. It returns true if the node was already unschedulable.
+func Cordon(ctx context.Context, client kubernetes.Interface, nodeName string) (bool, error) {
+	node, err := k8s.GetRetry(ctx, client.CoreV1().Nodes(), nodeName)
+	if err != nil {
+		return false, errors.Wrapf(err, "getting node %s", nodeName)
+	}
+	if node.Spec.Unschedulable {
+		return true, nil
+	}
+	return false, setUnschedulable(ctx, client, nodeName, true)
+}
+
+// Uncordon marks the node as schedulable.
+func Uncordon(ctx context.Context, client kubernetes.Interface, nodeName string) error {
+	return setUnschedulable(ctx, client, nodeName, false)
+}
+
+func setUnschedulable(ctx context.Context, client kubernetes.Interface, nodeName string, unschedulable bool) error {
+	patch := []byte(`{"spec":{"unschedulable":false}}`)
+	if unschedulable {
+		patch = []byte(`{"spec":{"unschedulable":true}}`)
+	}
+	if _, err := client.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return errors.Wrapf(err, "setting node %s unschedulable to %t", nodeName, unschedulable)
+	}
+	return nil
+}
+
+// Drain evicts all pods from the node except the ones that would be recreated on it,
+// like daemonset and static pods, and waits until they are gone.
+// Evictions blocked by pod disruption budgets are retried until the timeout.
+func Drain(ctx context.Context, client kubernetes.Interface, nodeName string, opts CordonOptions) error {
+	timeout := opts.DrainTimeout
+	if timeout == 0 {
+		timeout = defaultDrainTimeout
+	}
+	interval := opts.DrainPollInterval
+	if interval == 0 {
+		interval = defaultDrainPollInterval
+	}
+
+	retrier := retry.Retrier{
+		Timeout: timeout,
+		Backoff: retry.Backoff{
+			Duration: interval,
+		},
+	}
+	err := retrier.Do(ctx, func(ctx context.Context) (bool, error) {
+		pods, err := GetPodsOnNode(ctx, nodeName, client)
+		if err != nil {
+			return false, err
+		}
+		pods, err = evictablePods(pods)
+		if err != nil {
+			return false, err
+		}
+		if len(pods) == 0 {
+			return true, nil
+		}
+		for _, pod := range pods {
+			if err := evictPod(ctx, client, pod, opts.DrainGracePeriod); err != nil {
+				return false, err
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "draining node %s", nodeName)
+	}
+	return nil
+}
+
+func evictablePods(pods []corev1.Pod) ([]corev1.Pod, error) {
+	for _, filter := range getDrainedPodFilters() {
+		var err error
+		pods, err = filter(pods)
+		if err != nil {
+			return nil, errors.Wrap(err, "running filter on pods")
+		}
+	}
+	var evictable []corev1.Pod
+	for _, pod := range pods {
+		// Completed pods don't need to be evicted.
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		evictable = append(evictable, pod)
+	}
+	return evictable, nil
+}
+
+func evictPod(ctx context.Context, client kubernetes.Interface, pod corev1.Pod, gracePeriod time.Duration) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	}
+	if gracePeriod > 0 {
+		seconds := int64(gracePeriod.Seconds())
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &seconds}
+	}
+	err := client.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+	// TooManyRequests means a disruption budget is blocking the eviction, retry later.
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsTooManyRequests(err) {
+		return errors.Wrapf(err, "evicting pod %s/%s", pod.Namespace, pod.Name)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	testingk8s "k8s.io/client-go/testing"
+
+	"github.com/aws/eks-hybrid/internal/node"
+)
+
+const cordonTestNode = "my-node"
+
+func unschedulable(g *WithT, client *fake.Clientset) bool {
+	n, err := client.CoreV1().Nodes().Get(context.Background(), cordonTestNode, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	return n.Spec.Unschedulable
+}
+
+func TestRunCordoned(t *testing.T) {
+	errUpgrade := errors.New("upgrade failed")
+	testCases := []struct {
+		name              string
+		alreadyCordoned   bool
+		fnErr             error
+		wantUnschedulable bool
+	}{
+		{
+			name:              "uncordons after success",
+			wantUnschedulable: false,
+		},
+		{
+			name:              "stays cordoned after failure",
+			fnErr:             errUpgrade,
+			wantUnschedulable: true,
+		},
+		{
+			name:              "node already cordoned stays cordoned after success",
+			alreadyCordoned:   true,
+			wantUnschedulable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+			client := fake.NewSimpleClientset(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: cordonTestNode},
+				Spec:       corev1.NodeSpec{Unschedulable: tc.alreadyCordoned},
+			})
+
+			cordonedDuringFn := false
+			err := node.RunCordoned(ctx, client, cordonTestNode, node.CordonOptions{}, func(ctx context.Context) error {
+				cordonedDuringFn = unschedulable(g, client)
+				return tc.fnErr
+			})
+			if tc.fnErr != nil {
+				g.Expect(err).To(MatchError(tc.fnErr))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(cordonedDuringFn).To(BeTrue())
+			g.Expect(unschedulable(g, client)).To(Equal(tc.wantUnschedulable))
+		})
+	}
+}
+
+func TestRunCordonedNodeNotFound(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	client := fake.NewSimpleClientset()
+
+	called := false
+	err := node.RunCordoned(ctx, client, cordonTestNode, node.CordonOptions{}, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("getting node my-node")))
+	g.Expect(called).To(BeFalse())
+}
+
+func podOnNode(name string, owner *metav1.OwnerReference) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: cordonTestNode},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func TestRunCordonedWithDrain(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	controller := true
+	daemonSetOwner := &metav1.OwnerReference{Kind: "DaemonSet", Name: "ds", Controller: &controller}
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: cordonTestNode}},
+		podOnNode("app", nil),
+		podOnNode("blocked-by-pdb", nil),
+		podOnNode("ds-pod", daemonSetOwner),
+	)
+
+	evictions := map[string]int{}
+	client.PrependReactor("create", "pods", func(action testingk8s.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(testingk8s.CreateAction).GetObject().(*policyv1.Eviction)
+		evictions[eviction.Name]++
+		// The disruption budget blocks the first eviction.
+		if eviction.Name == "blocked-by-pdb" && evictions[eviction.Name] == 1 {
+			return true, nil, apierrors.NewTooManyRequests("disruption budget", 1)
+		}
+		gvr := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+		return true, nil, client.Tracker().Delete(gvr, eviction.Namespace, eviction.Name)
+	})
+
+	err := node.RunCordoned(ctx, client, cordonTestNode, node.CordonOptions{
+		Drain:             true,
+		DrainPollInterval: time.Millisecond,
+		DrainTimeout:      5 * time.Second,
+	}, func(ctx context.Context) error {
+		pods, err := client.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(pods.Items).To(HaveLen(1))
+		g.Expect(pods.Items[0].Name).To(Equal("ds-pod"))
+		return nil
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(evictions).To(Equal(map[string]int{"app": 1, "blocked-by-pdb": 2}))
+	g.Expect(unschedulable(g, client)).To(BeFalse())
+}
+
+func TestRunCordonedDrainTimeout(t *testing.T) {
+	g := NewWithT(t)
+	client := fake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: cordonTestNode}},
+		podOnNode("app", nil),
+	)
+	client.PrependReactor("create", "pods", func(action testingk8s.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewTooManyRequests("disruption budget", 1)
+	})
+
+	called := false
+	err := node.RunCordoned(context.Background(), client, cordonTestNode, node.CordonOptions{
+		Drain:             true,
+		DrainPollInterval: 10 * time.Millisecond,
+		DrainTimeout:      100 * time.Millisecond,
+	}, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	g.Expect(err).To(MatchError(ContainSubstring("draining node my-node")))
+	g.Expect(called).To(BeFalse())
+	g.Expect(unschedulable(g, client)).To(BeTrue())
+}
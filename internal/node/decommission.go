@@ -0,0 +1,69 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+)
+
+// DecommissionOptions configures Decommission.
+type DecommissionOptions struct {
+	// KubeClient and NodeName delete the Node object when set.
+	KubeClient kubernetes.Interface
+	NodeName   string
+	// EKSClient, ClusterName and RoleName delete the EKS access entry of the node IAM role when set.
+	// The access entry is shared by all the nodes using the same role.
+	EKSClient   eks.AccessEntryClient
+	ClusterName string
+	RoleName    string
+	Logger      *zap.Logger
+}
+
+// Decommission removes the node from the cluster. It must run after the kubelet is stopped, otherwise
+// the kubelet registers the node again. Resources that are already gone are skipped.
+func Decommission(ctx context.Context, opts DecommissionOptions) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	if opts.KubeClient != nil {
+		logger.Info("Deleting node from the cluster...", zap.String("node", opts.NodeName))
+		if err := DeleteNode(ctx, opts.KubeClient, opts.NodeName); err != nil {
+			return err
+		}
+	}
+
+	if opts.EKSClient != nil {
+		logger.Info("Deleting EKS access entry of the node role...", zap.String("cluster", opts.ClusterName), zap.String("role", opts.RoleName))
+		principalARN, err := eks.DeleteRoleAccessEntry(ctx, opts.EKSClient, opts.ClusterName, opts.RoleName)
+		if err != nil {
+			return err
+		}
+		if principalARN == "" {
+			logger.Info("Cluster has no access entry for the node role", zap.String("role", opts.RoleName))
+		} else {
+			logger.Info("Deleted EKS access entry", zap.String("principalArn", principalARN))
+		}
+	}
+
+	return nil
+}
+
+// DeleteNode deletes the Node object. It succeeds if the node doesn't exist.
+func DeleteNode(ctx context.Context, client kubernetes.Interface, nodeName string) error {
+	err := client.CoreV1().Nodes().Delete(ctx, nodeName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deleting node %s: %w", nodeName, err)
+	}
+	return nil
+}
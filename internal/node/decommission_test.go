@@ -0,0 +1,48 @@
+package node_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aws/eks-hybrid/internal/node"
+)
+
+func TestDecommissionDeletesNode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		objects []corev1.Node
+	}{
+		{
+			name:    "present",
+			objects: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "my-node"}}},
+		},
+		{
+			name: "already deleted",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client := fake.NewSimpleClientset()
+			for _, n := range tc.objects {
+				_, err := client.CoreV1().Nodes().Create(context.Background(), &n, metav1.CreateOptions{})
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+
+			g.Expect(node.Decommission(context.Background(), node.DecommissionOptions{
+				KubeClient: client,
+				NodeName:   "my-node",
+			})).To(Succeed())
+
+			_, err := client.CoreV1().Nodes().Get(context.Background(), "my-node", metav1.GetOptions{})
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	}
+}
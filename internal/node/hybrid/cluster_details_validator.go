@@ -0,0 +1,36 @@
+package hybrid
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// ValidateClusterDetails warns when the API server endpoint or certificate authority in the node config
+// don't belong to the cluster with the configured name, which usually means they were copied from another cluster.
+func (hnp *HybridNodeProvider) ValidateClusterDetails(ctx context.Context, informer validation.Informer, nodeConfig *api.NodeConfig) error {
+	var err error
+	if hnp.cluster == nil && hnp.awsConfig != nil {
+		// The cluster is only described when the node config is missing details, so describe it now.
+		if _, describeErr := hnp.getCluster(ctx); describeErr != nil {
+			hnp.logger.Debug("Failed to describe cluster to validate cluster details", zap.Error(describeErr))
+		}
+	}
+	if hnp.cluster == nil {
+		informer.Starting(ctx, clusterDetailsValidation, "Skipping cluster details validation due to node IAM role missing EKS DescribeCluster permission")
+		informer.Done(ctx, clusterDetailsValidation, err)
+		return nil
+	}
+
+	informer.Starting(ctx, clusterDetailsValidation, "Validating cluster details match the EKS cluster")
+	defer func() {
+		informer.Done(ctx, clusterDetailsValidation, err)
+	}()
+
+	err = eks.ValidateClusterDetailsMatch(hnp.cluster, nodeConfig.Spec.Cluster)
+	return err
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"encoding/base64"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -29,6 +27,9 @@ func (hnp *HybridNodeProvider) Enrich(ctx context.Context, opts ...configenriche
 		return err
 	}
 	hnp.nodeConfig.Status.Defaults.SandboxImage = eksRegistry.GetSandboxImage()
+	if config.SandboxImage != "" {
+		hnp.nodeConfig.Status.Defaults.SandboxImage = config.SandboxImage
+	}
 
 	hnp.logger.Info("Default options populated", zap.Reflect("defaults", hnp.nodeConfig.Status.Defaults))
 
@@ -43,20 +44,6 @@ func (hnp *HybridNodeProvider) Enrich(ctx context.Context, opts ...configenriche
 	return nil
 }
 
-// readCluster calls eks.DescribeCluster and returns the cluster
-func readCluster(ctx context.Context, awsConfig aws.Config, nodeConfig *api.NodeConfig) (*types.Cluster, error) {
-	client := eks.NewFromConfig(awsConfig)
-	input := &eks.DescribeClusterInput{
-		Name: &nodeConfig.Spec.Cluster.Name,
-	}
-	cluster, err := client.DescribeCluster(ctx, input)
-	if err != nil {
-		return nil, err
-	}
-
-	return cluster.Cluster, nil
-}
-
 func needsClusterDetails(nodeConfig *api.NodeConfig) bool {
 	return nodeConfig.Spec.Cluster.APIServerEndpoint == "" || nodeConfig.Spec.Cluster.CertificateAuthority == nil || nodeConfig.Spec.Cluster.CIDR == ""
 }
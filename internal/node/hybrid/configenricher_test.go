@@ -3,9 +3,14 @@ package hybrid_test
 import (
 	"context"
 	"encoding/base64"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
 	"testing"
 
 	aws_sdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
 	. "github.com/onsi/gomega"
@@ -14,9 +19,12 @@ import (
 
 	"github.com/aws/eks-hybrid/internal/api"
 	internalaws "github.com/aws/eks-hybrid/internal/aws"
+	internaleks "github.com/aws/eks-hybrid/internal/aws/eks"
 	"github.com/aws/eks-hybrid/internal/configenricher"
+	"github.com/aws/eks-hybrid/internal/kubernetes"
 	"github.com/aws/eks-hybrid/internal/node/hybrid"
 	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 func Test_hybridNodeProvider_Enrich(t *testing.T) {
@@ -442,3 +450,290 @@ func Test_hybridNodeProvider_Enrich(t *testing.T) {
 		})
 	}
 }
+
+func Test_hybridNodeProvider_EnrichSandboxImage(t *testing.T) {
+	g := NewWithT(t)
+	node := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{
+				Name:                 "my-cluster",
+				Region:               "us-west-2",
+				APIServerEndpoint:    "https://my-endpoint.example.com",
+				CertificateAuthority: []byte("my-ca-cert"),
+				CIDR:                 "172.0.0.0/16",
+			},
+			Hybrid: &api.HybridOptions{
+				IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"},
+			},
+		},
+	}
+	p, err := hybrid.NewHybridNodeProvider(node, []string{}, zap.NewNop())
+	g.Expect(err).To(Succeed())
+
+	g.Expect(p.Enrich(context.Background(),
+		configenricher.WithRegionConfig(&internalaws.RegionData{}),
+		configenricher.WithSandboxImage("registry.example.com/eks/pause:3.10"),
+	)).To(Succeed())
+	g.Expect(node.Status.Defaults.SandboxImage).To(Equal("registry.example.com/eks/pause:3.10"))
+}
+
+type countingDescribeClusterClient struct {
+	cluster *types.Cluster
+	calls   int
+}
+
+func (c *countingDescribeClusterClient) DescribeCluster(context.Context, *eks.DescribeClusterInput, ...func(*eks.Options)) (*eks.DescribeClusterOutput, error) {
+	c.calls++
+	return &eks.DescribeClusterOutput{Cluster: c.cluster}, nil
+}
+
+func Test_hybridNodeProvider_EnrichSharesClusterCache(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := &countingDescribeClusterClient{
+		cluster: &types.Cluster{
+			Endpoint: aws_sdk.String("https://my-endpoint.example.com"),
+			Name:     aws_sdk.String("my-cluster"),
+			Status:   types.ClusterStatusActive,
+			CertificateAuthority: &types.Certificate{
+				Data: aws_sdk.String(base64.StdEncoding.EncodeToString([]byte("my-ca-cert"))),
+			},
+			KubernetesNetworkConfig: &types.KubernetesNetworkConfigResponse{
+				ServiceIpv4Cidr: aws_sdk.String("172.0.0.0/16"),
+			},
+			RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.1.0.0/16"}}},
+			},
+		},
+	}
+	cache := internaleks.NewClusterCache(client)
+	newNode := func() *api.NodeConfig {
+		return &api.NodeConfig{
+			Spec: api.NodeConfigSpec{
+				Cluster: api.ClusterDetails{Name: "my-cluster", Region: "us-west-2"},
+				Hybrid: &api.HybridOptions{
+					IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"},
+				},
+			},
+			Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+		}
+	}
+
+	node := newNode()
+	p, err := hybrid.NewHybridNodeProvider(node, []string{}, zap.NewNop(),
+		hybrid.WithAWSConfig(&aws_sdk.Config{}),
+		hybrid.WithClusterCache(cache),
+	)
+	g.Expect(err).To(Succeed())
+	g.Expect(p.Enrich(ctx, configenricher.WithRegionConfig(&internalaws.RegionData{}))).To(Succeed())
+	g.Expect(node.Spec.Cluster.APIServerEndpoint).To(Equal("https://my-endpoint.example.com"))
+
+	details, err := kubernetes.NewClusterProviderWithCache(cache).ReadClusterDetails(ctx, newNode())
+	g.Expect(err).To(Succeed())
+	g.Expect(details.APIServerEndpoint).To(Equal("https://my-endpoint.example.com"))
+
+	cluster, err := cache.ReadCluster(ctx, newNode())
+	g.Expect(err).To(Succeed())
+	g.Expect(cluster.Name).To(Equal(aws_sdk.String("my-cluster")))
+
+	g.Expect(client.calls).To(Equal(1))
+}
+
+type recordingTransport struct {
+	transport http.RoundTripper
+	requests  []string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req.URL.Path)
+	return r.transport.RoundTrip(req)
+}
+
+func Test_hybridNodeProvider_EnrichUsesEKSHTTPClient(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	server := test.NewEKSDescribeClusterAPI(t, &eks.DescribeClusterOutput{
+		Cluster: &types.Cluster{
+			Endpoint: aws_sdk.String("https://my-endpoint.example.com"),
+			Name:     aws_sdk.String("my-cluster"),
+			Status:   types.ClusterStatusActive,
+			CertificateAuthority: &types.Certificate{
+				Data: aws_sdk.String(base64.StdEncoding.EncodeToString([]byte("my-ca-cert"))),
+			},
+			KubernetesNetworkConfig: &types.KubernetesNetworkConfigResponse{
+				ServiceIpv4Cidr: aws_sdk.String("172.0.0.0/16"),
+			},
+			RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+				RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.1.0.0/16"}}},
+			},
+		},
+	})
+	transport := &recordingTransport{transport: server.Client().Transport}
+	node := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{Name: "my-cluster", Region: "us-west-2"},
+			Hybrid: &api.HybridOptions{
+				IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"},
+			},
+		},
+		Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+	}
+
+	p, err := hybrid.NewHybridNodeProvider(node, []string{}, zap.NewNop(),
+		// The default client would fail the TLS handshake with the test server.
+		hybrid.WithAWSConfig(&aws_sdk.Config{BaseEndpoint: &server.URL}),
+		hybrid.WithEKSHTTPClient(&http.Client{Transport: transport}),
+	)
+	g.Expect(err).To(Succeed())
+	g.Expect(p.Enrich(ctx, configenricher.WithRegionConfig(&internalaws.RegionData{}))).To(Succeed())
+
+	g.Expect(node.Spec.Cluster.APIServerEndpoint).To(Equal("https://my-endpoint.example.com"))
+	g.Expect(transport.requests).To(HaveLen(1))
+}
+
+const assumeRoleResponse = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASSUMEDROLEKEY</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-token</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/eks-describe/nodeadm-init</Arn>
+      <AssumedRoleId>AROAEXAMPLE:nodeadm-init</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`
+
+const assumeRoleAccessDenied = `<ErrorResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>AccessDenied</Code>
+    <Message>User is not authorized to perform: sts:AssumeRole</Message>
+  </Error>
+  <RequestId>request-id</RequestId>
+</ErrorResponse>`
+
+var credentialRegex = regexp.MustCompile(`Credential=([^/]+)/`)
+
+// fakeSTSTransport answers STS AssumeRole requests and sends the rest to the EKS server,
+// recording the access key each EKS request is signed with.
+type fakeSTSTransport struct {
+	eks              http.RoundTripper
+	assumeRoleDenied bool
+	assumeRoleCalls  int
+	eksAccessKeys    []string
+}
+
+func (f *fakeSTSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, "/clusters/") {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(string(body), "Action=AssumeRole") {
+			return &http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		f.assumeRoleCalls++
+		status, response := http.StatusOK, assumeRoleResponse
+		if f.assumeRoleDenied {
+			status, response = http.StatusForbidden, assumeRoleAccessDenied
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"text/xml"}},
+			Body:       io.NopCloser(strings.NewReader(response)),
+			Request:    req,
+		}, nil
+	}
+	if match := credentialRegex.FindStringSubmatch(req.Header.Get("Authorization")); match != nil {
+		f.eksAccessKeys = append(f.eksAccessKeys, match[1])
+	}
+	return f.eks.RoundTrip(req)
+}
+
+func Test_hybridNodeProvider_EnrichAssumesEKSRole(t *testing.T) {
+	testCases := []struct {
+		name                string
+		roleARN             string
+		assumeRoleDenied    bool
+		wantAssumeRoleCalls int
+		wantEKSAccessKeys   []string
+		wantErr             string
+		wantRemediation     string
+	}{
+		{
+			name:              "node credentials without role",
+			wantEKSAccessKeys: []string{"NODEKEY"},
+		},
+		{
+			name:                "assumed role credentials",
+			roleARN:             "arn:aws:iam::123456789012:role/eks-describe",
+			wantAssumeRoleCalls: 1,
+			wantEKSAccessKeys:   []string{"ASSUMEDROLEKEY"},
+		},
+		{
+			name:                "role trust policy doesn't allow the node",
+			roleARN:             "arn:aws:iam::123456789012:role/eks-describe",
+			assumeRoleDenied:    true,
+			wantAssumeRoleCalls: 1,
+			wantErr:             "assuming role arn:aws:iam::123456789012:role/eks-describe for EKS API requests",
+			wantRemediation:     "Ensure the trust policy of role arn:aws:iam::123456789012:role/eks-describe allows the node credentials",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+			server := test.NewEKSDescribeClusterAPI(t, &eks.DescribeClusterOutput{
+				Cluster: &types.Cluster{
+					Endpoint: aws_sdk.String("https://my-endpoint.example.com"),
+					Name:     aws_sdk.String("my-cluster"),
+					Status:   types.ClusterStatusActive,
+					CertificateAuthority: &types.Certificate{
+						Data: aws_sdk.String(base64.StdEncoding.EncodeToString([]byte("my-ca-cert"))),
+					},
+					KubernetesNetworkConfig: &types.KubernetesNetworkConfigResponse{
+						ServiceIpv4Cidr: aws_sdk.String("172.0.0.0/16"),
+					},
+					RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+						RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.1.0.0/16"}}},
+					},
+				},
+			})
+			transport := &fakeSTSTransport{eks: server.Client().Transport, assumeRoleDenied: tc.assumeRoleDenied}
+			node := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{Name: "my-cluster", Region: "us-west-2"},
+					Hybrid: &api.HybridOptions{
+						IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"},
+					},
+				},
+				Status: api.NodeConfigStatus{Hybrid: api.HybridDetails{NodeName: "my-node"}},
+			}
+
+			p, err := hybrid.NewHybridNodeProvider(node, []string{}, zap.NewNop(),
+				hybrid.WithAWSConfig(&aws_sdk.Config{
+					Region:       "us-west-2",
+					BaseEndpoint: &server.URL,
+					Credentials:  credentials.NewStaticCredentialsProvider("NODEKEY", "node-secret", ""),
+				}),
+				hybrid.WithEKSHTTPClient(&http.Client{Transport: transport}),
+				hybrid.WithEKSAssumeRoleARN(tc.roleARN),
+			)
+			g.Expect(err).To(Succeed())
+			err = p.Enrich(ctx, configenricher.WithRegionConfig(&internalaws.RegionData{}))
+			g.Expect(transport.assumeRoleCalls).To(Equal(tc.wantAssumeRoleCalls))
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				g.Expect(validation.Remediation(err)).To(ContainSubstring(tc.wantRemediation))
+				g.Expect(transport.eksAccessKeys).To(BeEmpty())
+				return
+			}
+			g.Expect(err).To(Succeed())
+			g.Expect(node.Spec.Cluster.APIServerEndpoint).To(Equal("https://my-endpoint.example.com"))
+			g.Expect(transport.eksAccessKeys).To(Equal(tc.wantEKSAccessKeys))
+		})
+	}
+}
@@ -5,10 +5,12 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
 	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/ssm"
 )
 
 func (hnp *HybridNodeProvider) withDaemonManager() error {
@@ -35,6 +37,19 @@ func (hnp *HybridNodeProvider) GetDaemons() ([]daemon.Daemon, error) {
 	}, nil
 }
 
+// BootDaemons returns the daemons that need to be enabled for the node
+// to rejoin the cluster after a reboot.
+func BootDaemons(node *api.NodeConfig) []string {
+	daemons := []string{containerd.ContainerdDaemonName, kubelet.KubeletDaemonName}
+	if node.IsSSM() {
+		daemons = append(daemons, ssm.DaemonName())
+	}
+	if node.IsIAMRolesAnywhere() && node.Spec.Hybrid.EnableCredentialsFile {
+		daemons = append(daemons, iamrolesanywhere.DaemonName)
+	}
+	return daemons
+}
+
 func (hnp *HybridNodeProvider) PreProcessDaemon(ctx context.Context) error {
 	return nil
 }
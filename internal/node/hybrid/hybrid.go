@@ -2,35 +2,97 @@ package hybrid
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	sts_sdk "github.com/aws/aws-sdk-go-v2/service/sts"
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
 	"github.com/aws/eks-hybrid/internal/aws/sts"
+	"github.com/aws/eks-hybrid/internal/containerd"
 	"github.com/aws/eks-hybrid/internal/creds"
 	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/kubelet"
 	"github.com/aws/eks-hybrid/internal/kubernetes"
 	"github.com/aws/eks-hybrid/internal/network"
 	"github.com/aws/eks-hybrid/internal/nodeprovider"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 const (
-	awsAuthValidation           = "aws-auth-validation"
-	nodeIpValidation            = "node-ip-validation"
-	kubeletCertValidation       = "kubelet-cert-validation"
-	kubeletVersionSkew          = "kubelet-version-skew-validation"
-	ntpSyncValidation           = "ntp-sync-validation"
-	apiServerEndpointResolution = "api-server-endpoint-resolution-validation"
-	proxyValidation             = "proxy-validation"
-	nodeInactiveValidation      = "node-inactive-validation"
-	clusterAccessValidation     = "cluster-access-validation"
-	kubeletCurrentCertPath      = "/var/lib/kubelet/pki/kubelet-server-current.pem"
+	awsAuthValidation              = "aws-auth-validation"
+	nodeIpValidation               = "node-ip-validation"
+	nodeIPStabilityValidation      = "node-ip-stability-validation"
+	nodeIPRouteValidation          = "node-ip-route-validation"
+	nodeSourceIPValidation         = "node-source-ip-validation"
+	defaultRoutesValidation        = "default-routes-validation"
+	kubeletCertValidation          = "kubelet-cert-validation"
+	kubeletClientCredValidation    = "kubelet-client-credential-validation"
+	kubeletVersionSkew             = "kubelet-version-skew-validation"
+	ntpSyncValidation              = "ntp-sync-validation"
+	apiServerEndpointResolution    = "api-server-endpoint-resolution-validation"
+	proxyValidation                = "proxy-validation"
+	kubeletDNSValidation           = "kubelet-dns-validation"
+	swapValidation                 = "swap-validation"
+	nodeInactiveValidation         = "node-inactive-validation"
+	kubeletProcessValidation       = "kubelet-process-validation"
+	imageServiceValidation         = "image-service-endpoint-validation"
+	imagePullSettingsValidation    = "image-pull-settings-validation"
+	clusterAccessValidation        = "cluster-access-validation"
+	clusterDetailsValidation       = "cluster-details-validation"
+	clusterRemoteNetworkValidation = "cluster-remote-network-validation"
+	regionValidation               = "region-validation"
+	cgroupValidation               = "cgroup-validation"
+	kubeletConfigValidation        = "kubelet-config-validation"
+	clockSkewValidation            = "clock-skew-validation"
+	diskValidation                 = "disk-validation"
+	kubeletCurrentCertPath         = "/var/lib/kubelet/pki/kubelet-server-current.pem"
 )
 
+// configPhase is the init phase that writes the main containerd config and the kubelet config.
+const configPhase = "config"
+
+// Validations returns the names of the hybrid node validations, each can be skipped as an init phase.
+func Validations() []string {
+	return []string{
+		awsAuthValidation,
+		clusterRemoteNetworkValidation,
+		nodeIpValidation,
+		nodeIPStabilityValidation,
+		nodeIPRouteValidation,
+		nodeSourceIPValidation,
+		defaultRoutesValidation,
+		clockSkewValidation,
+		kubeletCertValidation,
+		kubeletClientCredValidation,
+		kubeletVersionSkew,
+		apiServerEndpointResolution,
+		proxyValidation,
+		kubeletDNSValidation,
+		swapValidation,
+		diskValidation,
+		nodeInactiveValidation,
+		kubeletProcessValidation,
+		imageServiceValidation,
+		imagePullSettingsValidation,
+		clusterAccessValidation,
+		clusterDetailsValidation,
+		regionValidation,
+		cgroupValidation,
+		kubeletConfigValidation,
+	}
+}
+
+// eksAssumeRoleSessionName is the session name of the role assumed for the EKS API requests.
+const eksAssumeRoleSessionName = "nodeadm-init"
+
 type HybridNodeProvider struct {
 	nodeConfig    *api.NodeConfig
 	validator     func(config *api.NodeConfig) error
@@ -44,6 +106,23 @@ type HybridNodeProvider struct {
 	// If not provided, defaults to kubelet.KubeletCurrentCertPath
 	certPath string
 	kubelet  Kubelet
+	// versionSkewOverride overrides the number of minor versions the kubelet can be
+	// behind the kube-apiserver. Zero keeps the Kubernetes version skew policy.
+	versionSkewOverride int
+	// privateMode is true when the node is initialized in an air-gapped environment.
+	privateMode bool
+	// clockSkewTolerance is the maximum skew between the node's clock and AWS time.
+	// Zero keeps system.DefaultClockSkewTolerance.
+	clockSkewTolerance time.Duration
+	// clusterCache shares the DescribeCluster response with other readers. It's built
+	// from awsConfig the first time the cluster is read if not provided.
+	clusterCache *eks.ClusterCache
+	// eksHTTPClient sends the EKS API requests. When nil, requests go through the
+	// node proxy if it has one, even if it's not set in the environment.
+	eksHTTPClient aws.HTTPClient
+	// eksAssumeRoleARN is the role assumed for the EKS API requests. When empty, the
+	// node credentials are used.
+	eksAssumeRoleARN string
 }
 
 type NodeProviderOpt func(*HybridNodeProvider)
@@ -82,6 +161,28 @@ func WithCluster(cluster *types.Cluster) NodeProviderOpt {
 	}
 }
 
+// WithClusterCache reads the EKS cluster from cache, sharing the DescribeCluster call with its other readers.
+func WithClusterCache(cache *eks.ClusterCache) NodeProviderOpt {
+	return func(hnp *HybridNodeProvider) {
+		hnp.clusterCache = cache
+	}
+}
+
+// WithEKSHTTPClient sets the HTTP client used for the EKS API requests, like DescribeCluster.
+func WithEKSHTTPClient(client aws.HTTPClient) NodeProviderOpt {
+	return func(hnp *HybridNodeProvider) {
+		hnp.eksHTTPClient = client
+	}
+}
+
+// WithEKSAssumeRoleARN sets a role to assume for the EKS API requests, like DescribeCluster, when the
+// node credentials are not allowed to make them.
+func WithEKSAssumeRoleARN(roleARN string) NodeProviderOpt {
+	return func(hnp *HybridNodeProvider) {
+		hnp.eksAssumeRoleARN = roleARN
+	}
+}
+
 // WithNetwork adds network util functions to the HybridNodeProvider for testing purposes.
 func WithNetwork(net network.Network) NodeProviderOpt {
 	return func(hnp *HybridNodeProvider) {
@@ -103,6 +204,30 @@ func WithKubelet(kubelet Kubelet) NodeProviderOpt {
 	}
 }
 
+// WithVersionSkewOverride overrides the number of minor versions the kubelet can be behind the
+// kube-apiserver, narrowing or widening the version skew policy. Widening it is unsupported and
+// it's clamped to MaxVersionSkewOverride.
+func WithVersionSkewOverride(skew int) NodeProviderOpt {
+	return func(hnp *HybridNodeProvider) {
+		hnp.versionSkewOverride = skew
+	}
+}
+
+// WithPrivateMode sets whether the node is initialized in private mode, pulling images from a
+// private registry in an air-gapped environment.
+func WithPrivateMode(privateMode bool) NodeProviderOpt {
+	return func(hnp *HybridNodeProvider) {
+		hnp.privateMode = privateMode
+	}
+}
+
+// WithClockSkewTolerance sets the maximum skew allowed between the node's clock and AWS time.
+func WithClockSkewTolerance(tolerance time.Duration) NodeProviderOpt {
+	return func(hnp *HybridNodeProvider) {
+		hnp.clockSkewTolerance = tolerance
+	}
+}
+
 // WithDaemonManager adds a DaemonManager to the HybridNodeProvider for testing purposes.
 func WithDaemonManager(dm daemon.DaemonManager) NodeProviderOpt {
 	return func(hnp *HybridNodeProvider) {
@@ -118,6 +243,8 @@ func (hnp *HybridNodeProvider) Logger() *zap.Logger {
 	return hnp.logger
 }
 
+// Validate runs all the hybrid node validations not skipped and returns the errors of every failing
+// one joined, each with its remediation, so all the issues can be fixed before running it again.
 func (hnp *HybridNodeProvider) Validate(ctx context.Context) error {
 	// Create logger printer for structured validation logging
 	printer := validation.NewLoggerPrinterWithLogger(hnp.logger)
@@ -135,21 +262,50 @@ func (hnp *HybridNodeProvider) Validate(ctx context.Context) error {
 
 	// Register all hybrid node validations
 	runner.Register(
+		validation.New(clusterRemoteNetworkValidation, hnp.ValidateClusterRemoteNetwork),
 		validation.New(nodeIpValidation, network.NewNetworkInterfaceValidator(
 			network.WithMTUValidation(false),
-			network.WithCluster(hnp.cluster)).Run),
+			network.WithCluster(hnp.cluster),
+			network.WithNetwork(hnp.network)).Run),
+		validation.New(nodeIPStabilityValidation, network.NewNodeIPStabilityValidator(
+			network.WithStabilityNetwork(hnp.network)).Run),
+		validation.New(nodeIPRouteValidation, network.NewNodeIPRouteValidator(
+			network.WithRouteNetwork(hnp.network)).Run),
+		validation.New(nodeSourceIPValidation, network.NewNodeSourceIPValidator(
+			network.WithSourceIPCluster(hnp.cluster),
+			network.WithSourceIPNetwork(hnp.network),
+			network.WithSourceIPPrivateMode(hnp.privateMode)).Run),
+		validation.New(defaultRoutesValidation, network.NewDefaultRoutesValidator().Run),
+		validation.New(clockSkewValidation, system.NewNTPValidator(
+			system.WithClockSkewTolerance(hnp.clockSkewTolerance)).RunClockSkew),
 		validation.New(kubeletCertValidation, kubernetes.NewKubeletCertificateValidator(
 			&hnp.nodeConfig.Spec.Cluster,
 			kubernetes.WithCertPath(hnp.certPath),
 			kubernetes.WithIgnoreDateAndNoCertErrors(true)).Run),
+		validation.New(kubeletClientCredValidation, kubernetes.NewKubeletClientCredentialValidator(
+			&hnp.nodeConfig.Spec.Cluster,
+			kubernetes.WithIgnoreClientDateAndNoCertErrors(true)).Run),
 		validation.New(kubeletVersionSkew, hnp.ValidateKubeletVersionSkew),
 		validation.New(apiServerEndpointResolution, kubernetes.ValidateAPIServerEndpointResolution),
 		validation.New(proxyValidation, network.NewProxyValidator().Run),
+		validation.New(kubeletDNSValidation, network.NewKubeletDNSValidator().Run),
+		validation.New(swapValidation, system.NewSwapKubeletValidator().Run),
+		validation.New(diskValidation, system.NewDiskValidator().Run),
 		validation.New(nodeInactiveValidation, hnp.ValidateNodeIsInactive),
+		validation.New(kubeletProcessValidation, kubelet.NewProcessValidator(kubelet.NewProcProber()).Run),
+		validation.New(imageServiceValidation, containerd.NewImageServiceValidator().Run),
+		validation.New(imagePullSettingsValidation, containerd.NewImagePullSettingsValidator(
+			containerd.WithSlowLink(hnp.privateMode)).Run),
 		validation.New(clusterAccessValidation, hnp.ValidateClusterAccess),
+		validation.New(clusterDetailsValidation, hnp.ValidateClusterDetails),
+		validation.New(regionValidation, hnp.ValidateRegion),
+		validation.New(cgroupValidation, kubelet.NewCgroupValidator(
+			kubelet.WithContainerdMainConfig(slices.Contains(hnp.skipPhases, configPhase))).Run),
+		validation.New(kubeletConfigValidation, kubelet.NewConfigDriftValidator(
+			kubelet.WithKubeletConfigOnDisk(slices.Contains(hnp.skipPhases, configPhase))).Run),
 	)
 
-	// Run all validations sequentially
+	// Run all validations sequentially, without stopping on the first failure
 	if err := runner.Sequentially(ctx, hnp.nodeConfig); err != nil {
 		hnp.logger.Error("Hybrid node validation failures detected", zap.Error(err))
 		return err
@@ -164,13 +320,53 @@ func (hnp *HybridNodeProvider) Cleanup() error {
 	return nil
 }
 
-// getCluster retrieves the Cluster object or makes a DescribeCluster call to the EKS API and caches the result if not already present
+// eksAWSConfig returns the aws config for the EKS API requests. Unless an HTTP client is configured,
+// requests go through the node proxy, which the SDK would miss when it's not in the environment.
+// If a role is configured, its credentials are used instead of the node credentials.
+func (hnp *HybridNodeProvider) eksAWSConfig() aws.Config {
+	config := hnp.awsConfig.Copy()
+	if hnp.eksHTTPClient != nil {
+		config.HTTPClient = hnp.eksHTTPClient
+	} else if proxy := network.NodeProxyConfig(); proxy.HTTPProxy != "" || proxy.HTTPSProxy != "" {
+		config.HTTPClient = network.NewProxyHTTPClient(proxy)
+	}
+	if hnp.eksAssumeRoleARN != "" {
+		// STS is called with the node credentials, through the same HTTP client.
+		config.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts_sdk.NewFromConfig(config), hnp.eksAssumeRoleARN,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = eksAssumeRoleSessionName
+			}))
+	}
+	return config
+}
+
+// assumeEKSRole assumes the role for the EKS API requests, so a failure to assume it is told
+// apart from a failure to describe the cluster.
+func (hnp *HybridNodeProvider) assumeEKSRole(ctx context.Context, config aws.Config) error {
+	if _, err := config.Credentials.Retrieve(ctx); err != nil {
+		return validation.WithRemediation(fmt.Errorf("assuming role %s for EKS API requests: %w", hnp.eksAssumeRoleARN, err),
+			fmt.Sprintf("Ensure the trust policy of role %s allows the node credentials to call sts:AssumeRole, "+
+				"and the role has eks:DescribeCluster permission on the cluster.", hnp.eksAssumeRoleARN))
+	}
+	return nil
+}
+
+// getCluster retrieves the Cluster object or reads it from the cluster cache, which only calls DescribeCluster once
 func (hnp *HybridNodeProvider) getCluster(ctx context.Context) (*types.Cluster, error) {
 	if hnp.cluster != nil {
 		return hnp.cluster, nil
 	}
 
-	cluster, err := readCluster(ctx, *hnp.awsConfig, hnp.nodeConfig)
+	if hnp.clusterCache == nil {
+		config := hnp.eksAWSConfig()
+		if hnp.eksAssumeRoleARN != "" {
+			if err := hnp.assumeEKSRole(ctx, config); err != nil {
+				return nil, err
+			}
+		}
+		hnp.clusterCache = eks.NewClusterCacheFromConfig(config)
+	}
+	cluster, err := hnp.clusterCache.Cluster(ctx, hnp.nodeConfig.Spec.Cluster.Name)
 	if err != nil {
 		return nil, err
 	}
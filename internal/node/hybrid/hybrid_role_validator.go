@@ -5,12 +5,12 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
-	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekssdk "github.com/aws/aws-sdk-go-v2/service/eks"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
 	"github.com/aws/eks-hybrid/internal/validation"
 )
 
@@ -39,7 +39,7 @@ func (hnp *HybridNodeProvider) ValidateClusterAccess(ctx context.Context, inform
 	}()
 
 	stsClient := sts.NewFromConfig(*hnp.awsConfig)
-	eksClient := eks.NewFromConfig(*hnp.awsConfig)
+	eksClient := ekssdk.NewFromConfig(*hnp.awsConfig)
 
 	getCallerIdentityOutput, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
@@ -59,13 +59,13 @@ func (hnp *HybridNodeProvider) ValidateClusterAccess(ctx context.Context, inform
 		return err
 	}
 
-	roleName, ok := extractRoleNameFromARN(parsedARN)
+	roleName, ok := eks.RoleNameFromARN(parsedARN)
 	if !ok || roleName == "" {
 		err = validation.WithRemediation(fmt.Errorf("extracting role name from ARN: %s", roleArn), accessEntryRemediation)
 		return err
 	}
 
-	accessEntries, err := fetchAllAccessEntries(ctx, eksClient, hnp.cluster.Name)
+	accessEntries, err := eks.ListAccessEntries(ctx, eksClient, *hnp.cluster.Name)
 	if err != nil {
 		err = validation.WithRemediation(fmt.Errorf("fetching access entries from cluster: %w", err), accessEntryRemediation)
 		return err
@@ -91,46 +91,3 @@ func (hnp *HybridNodeProvider) ValidateClusterAccess(ctx context.Context, inform
 
 	return nil
 }
-
-// extractRoleNameFromARN extracts the role name from an ARN
-// Returns the role name and a boolean indicating if extraction was successful
-func extractRoleNameFromARN(parsedARN arn.ARN) (string, bool) {
-	splitArn := strings.Split(parsedARN.Resource, "/")
-
-	// Handle assumed role ARN format: arn:aws:sts::123456789012:assumed-role/RoleName/session
-	if parsedARN.Service == "sts" && strings.HasPrefix(parsedARN.Resource, "assumed-role") && len(splitArn) >= 2 {
-		return splitArn[1], true
-	}
-
-	// Handle IAM role ARN format: arn:aws:iam::123456789012:role/RoleName
-	if parsedARN.Service == "iam" && strings.HasPrefix(parsedARN.Resource, "role") && len(splitArn) >= 2 {
-		return splitArn[len(splitArn)-1], true
-	}
-
-	return "", false
-}
-
-// fetchAllAccessEntries retrieves all access entries for a cluster with pagination handling
-func fetchAllAccessEntries(ctx context.Context, eksClient *eks.Client, clusterName *string) ([]string, error) {
-	accessEntries := []string{}
-	var nextToken *string
-
-	for {
-		listAccessEntriesOutput, err := eksClient.ListAccessEntries(ctx, &eks.ListAccessEntriesInput{
-			ClusterName: clusterName,
-			NextToken:   nextToken,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to list access entries: %w", err)
-		}
-
-		accessEntries = append(accessEntries, listAccessEntriesOutput.AccessEntries...)
-
-		if listAccessEntriesOutput.NextToken == nil || aws.ToString(listAccessEntriesOutput.NextToken) == "" {
-			break
-		}
-		nextToken = listAccessEntriesOutput.NextToken
-	}
-
-	return accessEntries, nil
-}
@@ -0,0 +1,148 @@
+package hybrid_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/network"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+	"github.com/aws/eks-hybrid/internal/test"
+)
+
+func TestHybridNodeProvider_ValidateNodeIP(t *testing.T) {
+	cluster := &types.Cluster{
+		Name: aws.String("my-cluster"),
+		RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+			RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+		},
+	}
+	interfaces := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.ParseIP("10.80.0.10"), Mask: net.CIDRMask(16, 32)},
+		&net.IPNet{IP: net.ParseIP("192.168.1.10"), Mask: net.CIDRMask(24, 32)},
+	}
+
+	tests := []struct {
+		name            string
+		kubeletFlags    []string
+		nodeIPInterface string
+		network         *test.FakeNetwork
+		wantErr         string
+	}{
+		{
+			name: "node IP from DNS resolution",
+			network: &test.FakeNetwork{
+				DNSRecords:        map[string][]net.IP{"my-node": {net.ParseIP("10.80.0.10")}},
+				ResolvedBindAddr:  net.ParseIP("192.168.1.10"),
+				NetworkInterfaces: interfaces,
+			},
+		},
+		{
+			name: "node IP from default gateway interface when DNS resolution fails",
+			network: &test.FakeNetwork{
+				ResolvedBindAddr:  net.ParseIP("10.80.0.10"),
+				NetworkInterfaces: interfaces,
+			},
+		},
+		{
+			name: "node IP from default gateway interface when DNS resolves a loopback IP",
+			network: &test.FakeNetwork{
+				DNSRecords:        map[string][]net.IP{"my-node": {net.ParseIP("127.0.0.1")}},
+				ResolvedBindAddr:  net.ParseIP("10.80.0.10"),
+				NetworkInterfaces: interfaces,
+			},
+		},
+		{
+			name: "node IP outside the remote node networks",
+			network: &test.FakeNetwork{
+				DNSRecords:        map[string][]net.IP{"my-node": {net.ParseIP("192.168.1.10")}},
+				NetworkInterfaces: interfaces,
+			},
+			wantErr: "node IP 192.168.1.10 is not in any of the remote network CIDR blocks",
+		},
+		{
+			name:         "node IP flag outside the remote node networks takes precedence over DNS resolution",
+			kubeletFlags: []string{"--node-ip=192.168.1.10"},
+			network: &test.FakeNetwork{
+				DNSRecords:        map[string][]net.IP{"my-node": {net.ParseIP("10.80.0.10")}},
+				NetworkInterfaces: interfaces,
+			},
+			wantErr: "node IP 192.168.1.10 is not in any of the remote network CIDR blocks",
+		},
+		{
+			name:            "node IP from the node IP interface takes precedence over DNS resolution",
+			nodeIPInterface: "eth1",
+			network: &test.FakeNetwork{
+				DNSRecords:        map[string][]net.IP{"my-node": {net.ParseIP("192.168.1.10")}},
+				NetworkInterfaces: interfaces,
+				NamedInterfaces:   map[string][]net.Addr{"eth1": interfaces[1:2]},
+			},
+		},
+		{
+			name:            "node IP interface not found",
+			nodeIPInterface: "eth2",
+			network: &test.FakeNetwork{
+				DNSRecords:        map[string][]net.IP{"my-node": {net.ParseIP("10.80.0.10")}},
+				NetworkInterfaces: interfaces,
+			},
+			wantErr: "getting addresses of node IP interface eth2",
+		},
+		{
+			name: "node IP can't be determined",
+			network: &test.FakeNetwork{
+				BindAddrErr:       errors.New("no default route"),
+				NetworkInterfaces: interfaces,
+			},
+			wantErr: "couldn't get ip address of node",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			nodeConfig := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Kubelet: api.KubeletOptions{Flags: tt.kubeletFlags, NodeIPInterface: tt.nodeIPInterface},
+					Hybrid: &api.HybridOptions{
+						IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"},
+					},
+				},
+				Status: api.NodeConfigStatus{
+					Hybrid: api.HybridDetails{NodeName: "my-node"},
+				},
+			}
+
+			hnp, err := hybrid.NewHybridNodeProvider(
+				nodeConfig,
+				skipAllValidationsExcept("node-ip-validation"),
+				zap.NewNop(),
+				hybrid.WithCluster(cluster),
+				hybrid.WithNetwork(tt.network),
+				hybrid.WithDaemonManager(&mockDaemonManager{status: daemon.DaemonStatusStopped}),
+			)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			err = hnp.Validate(context.Background())
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			}
+
+			// The validation resolves the node IP like the network validators do.
+			_, nodeIPErr := network.GetNodeConfigIP(nodeConfig, tt.network)
+			if nodeIPErr != nil {
+				g.Expect(err).To(MatchError(ContainSubstring(nodeIPErr.Error())))
+			}
+		})
+	}
+}
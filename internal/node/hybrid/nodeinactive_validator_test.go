@@ -40,6 +40,10 @@ func (m *mockDaemonManager) EnableDaemon(name string) error {
 	return nil
 }
 
+func (m *mockDaemonManager) IsDaemonEnabled(name string) (bool, error) {
+	return true, nil
+}
+
 func (m *mockDaemonManager) DisableDaemon(name string) error {
 	return nil
 }
@@ -95,14 +99,7 @@ func TestHybridNodeProvider_ValidateNodeIsInactive(t *testing.T) {
 			// Create provider with observed logger
 			hnp, err := hybrid.NewHybridNodeProvider(
 				&api.NodeConfig{},
-				[]string{
-					"node-ip-validation",
-					"kubelet-version-skew-validation",
-					"kubelet-cert-validation",
-					"api-server-endpoint-resolution-validation",
-					"proxy-validation",
-					"cluster-access-validation",
-				},
+				skipAllValidationsExcept("node-inactive-validation"),
 				observedLogger,
 				hybrid.WithDaemonManager(mockDaemon),
 			)
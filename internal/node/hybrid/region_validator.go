@@ -0,0 +1,44 @@
+package hybrid
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws/eks"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// ValidateRegion checks the regions configured for the node match the region of the cluster,
+// read from the cluster ARN or, if the cluster can't be described, from the API server endpoint.
+func (hnp *HybridNodeProvider) ValidateRegion(ctx context.Context, informer validation.Informer, nodeConfig *api.NodeConfig) error {
+	var err error
+	if hnp.cluster == nil && hnp.awsConfig != nil {
+		// Describing the cluster fails when the region is wrong, the endpoint is used instead.
+		if _, describeErr := hnp.getCluster(ctx); describeErr != nil {
+			hnp.logger.Debug("Failed to describe cluster to validate region", zap.Error(describeErr))
+		}
+	}
+	clusterRegion, err := eks.ClusterRegion(hnp.cluster, nodeConfig.Spec.Cluster.APIServerEndpoint)
+	if err == nil && clusterRegion == "" {
+		informer.Starting(ctx, regionValidation, "Skipping region validation, the cluster region can't be determined")
+		informer.Done(ctx, regionValidation, nil)
+		return nil
+	}
+
+	informer.Starting(ctx, regionValidation, "Validating the configured region matches the cluster region")
+	defer func() {
+		informer.Done(ctx, regionValidation, err)
+	}()
+	if err != nil {
+		return err
+	}
+
+	var awsConfigRegion string
+	if hnp.awsConfig != nil {
+		awsConfigRegion = hnp.awsConfig.Region
+	}
+	err = eks.ValidateRegionMatch(clusterRegion, awsConfigRegion, nodeConfig)
+	return err
+}
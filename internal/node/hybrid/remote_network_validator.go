@@ -0,0 +1,50 @@
+package hybrid
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/network"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+// ValidateClusterRemoteNetwork checks the cluster is set up for hybrid nodes, with remote node networks
+// in its remote network config that don't overlap its remote pod networks. It runs before the other validations that depend on the cluster, so
+// a cluster without hybrid networking is reported as such instead of failing later checks.
+func (hnp *HybridNodeProvider) ValidateClusterRemoteNetwork(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	if hnp.cluster == nil && hnp.awsConfig != nil {
+		// The cluster is only described when the node config is missing details, so describe it now.
+		if _, describeErr := hnp.getCluster(ctx); describeErr != nil {
+			hnp.logger.Debug("Failed to describe cluster to validate remote network config", zap.Error(describeErr))
+		}
+	}
+	if hnp.cluster == nil {
+		informer.Starting(ctx, clusterRemoteNetworkValidation, "Skipping cluster remote network validation due to node IAM role missing EKS DescribeCluster permission")
+		informer.Done(ctx, clusterRemoteNetworkValidation, err)
+		return nil
+	}
+
+	informer.Starting(ctx, clusterRemoteNetworkValidation, "Validating the EKS cluster supports hybrid nodes")
+	defer func() {
+		informer.Done(ctx, clusterRemoteNetworkValidation, err)
+	}()
+
+	if err = network.ValidateClusterRemoteNetworkConfig(hnp.cluster); err != nil {
+		err = validation.WithRemediation(err,
+			"The cluster is not set up for hybrid nodes. Enable hybrid nodes on the cluster by configuring its remote network "+
+				"with the remote node networks of your on-premises nodes, or check the node config points to the right cluster. "+
+				"See https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-cluster-create.html")
+		return err
+	}
+	if err = network.ValidateRemoteNetworksDontOverlap(hnp.cluster.RemoteNetworkConfig); err != nil {
+		err = validation.WithRemediation(err,
+			"Pods on hybrid nodes must get IPs outside the node networks. Update the cluster remote pod networks to match "+
+				"the pod CIDRs of your CNI, making sure neither they nor the CNI pod CIDRs overlap the remote node networks. "+
+				"See https://docs.aws.amazon.com/eks/latest/userguide/hybrid-nodes-networking.html")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package hybrid_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestHybridNodeProvider_ValidateClusterRemoteNetwork(t *testing.T) {
+	tests := []struct {
+		name            string
+		cluster         *types.Cluster
+		wantErr         string
+		wantRemediation string
+	}{
+		{
+			name: "cluster with remote node networks",
+			cluster: &types.Cluster{
+				Name: aws.String("my-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+				},
+			},
+		},
+		{
+			name:    "cluster without remote network config",
+			cluster: &types.Cluster{Name: aws.String("my-cluster")},
+			wantErr: "remote network config is not set for cluster my-cluster",
+		},
+		{
+			name: "cluster without remote node networks",
+			cluster: &types.Cluster{
+				Name:                aws.String("my-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{RemoteNodeNetworks: []types.RemoteNodeNetwork{}},
+			},
+			wantErr: "remote node networks not found in remote network config for cluster my-cluster",
+		},
+		{
+			name: "cluster with disjoint remote node and pod networks",
+			cluster: &types.Cluster{
+				Name: aws.String("my-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+					RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.85.0.0/16"}}},
+				},
+			},
+		},
+		{
+			name: "cluster with overlapping remote node and pod networks",
+			cluster: &types.Cluster{
+				Name: aws.String("my-cluster"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+					RemotePodNetworks:  []types.RemotePodNetwork{{Cidrs: []string{"10.80.64.0/18"}}},
+				},
+			},
+			wantErr:         "remote node network 10.80.0.0/16 overlaps remote pod network 10.80.64.0/18",
+			wantRemediation: "Update the cluster remote pod networks",
+		},
+		{
+			name: "cluster can't be described",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+			hnp, err := hybrid.NewHybridNodeProvider(&api.NodeConfig{}, nil, zap.NewNop(), hybrid.WithCluster(tt.cluster))
+			g.Expect(err).NotTo(HaveOccurred())
+			informer := test.NewFakeInformer()
+
+			err = hnp.(*hybrid.HybridNodeProvider).ValidateClusterRemoteNetwork(ctx, informer, &api.NodeConfig{})
+			g.Expect(informer.Started).To(BeTrue())
+			if tt.wantErr == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(informer.DoneWith).To(BeNil())
+				return
+			}
+			g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+			g.Expect(informer.DoneWith).To(MatchError(ContainSubstring(tt.wantErr)))
+			wantRemediation := tt.wantRemediation
+			if wantRemediation == "" {
+				wantRemediation = "Enable hybrid nodes on the cluster"
+			}
+			g.Expect(validation.Remediation(err)).To(ContainSubstring(wantRemediation))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package hybrid_test
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/node/hybrid"
+	"github.com/aws/eks-hybrid/internal/test"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestHybridNodeProvider_ValidateAggregatesErrors(t *testing.T) {
+	skipPhases := skipAllValidationsExcept("node-ip-validation", "kubelet-version-skew-validation")
+
+	tests := []struct {
+		name            string
+		skipPhases      []string
+		wantErrs        []string
+		wantRemediation []string
+	}{
+		{
+			name:       "all failing validations are reported",
+			skipPhases: skipPhases,
+			wantErrs: []string{
+				"node IP 192.168.1.10 is not in any of the remote network CIDR blocks",
+				"kubelet version v1.31.0 is newer than kube-apiserver version 1.30",
+			},
+			wantRemediation: []string{
+				"Ensure the node IP is within the configured remote network CIDR blocks.",
+				"Ensure the hybrid node's Kubernetes version follows the version skew policy of the EKS cluster.",
+			},
+		},
+		{
+			name:       "skipped validations are not run",
+			skipPhases: append(skipPhases, "kubelet-version-skew-validation"),
+			wantErrs: []string{
+				"node IP 192.168.1.10 is not in any of the remote network CIDR blocks",
+			},
+			wantRemediation: []string{
+				"Ensure the node IP is within the configured remote network CIDR blocks.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cluster := &types.Cluster{
+				Name:    aws.String("my-cluster"),
+				Version: aws.String("1.30"),
+				RemoteNetworkConfig: &types.RemoteNetworkConfigResponse{
+					RemoteNodeNetworks: []types.RemoteNodeNetwork{{Cidrs: []string{"10.80.0.0/16"}}},
+				},
+			}
+			nodeConfig := &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Kubelet: api.KubeletOptions{Flags: []string{"--node-ip=192.168.1.10"}},
+					Hybrid: &api.HybridOptions{
+						IAMRolesAnywhere: &api.IAMRolesAnywhere{NodeName: "my-node"},
+					},
+				},
+			}
+
+			hnp, err := hybrid.NewHybridNodeProvider(
+				nodeConfig,
+				tt.skipPhases,
+				zap.NewNop(),
+				hybrid.WithCluster(cluster),
+				hybrid.WithNetwork(&test.FakeNetwork{
+					NetworkInterfaces: []net.Addr{&net.IPNet{IP: net.ParseIP("192.168.1.10"), Mask: net.CIDRMask(24, 32)}},
+				}),
+				hybrid.WithKubelet(newMockKubelet("v1.31.0", nil)),
+				hybrid.WithDaemonManager(&mockDaemonManager{status: daemon.DaemonStatusStopped}),
+			)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			err = hnp.Validate(context.Background())
+			g.Expect(err).To(HaveOccurred())
+
+			errs := validation.Unwrap(err)
+			g.Expect(errs).To(HaveLen(len(tt.wantErrs)))
+			for i, e := range errs {
+				g.Expect(e.Error()).To(ContainSubstring(tt.wantErrs[i]))
+				g.Expect(validation.Remediation(e)).To(ContainSubstring(tt.wantRemediation[i]))
+			}
+		})
+	}
+}
+
+// skipAllValidationsExcept returns the phases skipping every hybrid node validation but validations.
+func skipAllValidationsExcept(validations ...string) []string {
+	var skipPhases []string
+	for _, v := range hybrid.Validations() {
+		if !slices.Contains(validations, v) {
+			skipPhases = append(skipPhases, v)
+		}
+	}
+	return skipPhases
+}
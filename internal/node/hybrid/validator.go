@@ -1,12 +1,18 @@
 package hybrid
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"regexp"
 	"strings"
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/certificate"
+	"github.com/aws/eks-hybrid/internal/containerd"
+	"github.com/aws/eks-hybrid/internal/iamrolesanywhere"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+	"github.com/aws/eks-hybrid/internal/network"
 	"github.com/aws/eks-hybrid/internal/util/file"
 	"github.com/aws/eks-hybrid/internal/validation"
 )
@@ -33,55 +39,18 @@ func extractFlagValue(args []string, flag string) string {
 	return flagValue
 }
 
+var (
+	ssmActivationIDRegex   = regexp.MustCompile(ssmActivationIDPattern)
+	ssmActivationCodeRegex = regexp.MustCompile(ssmActivationCodePattern)
+)
+
 func (hnp *HybridNodeProvider) withHybridValidators() {
 	hnp.validator = func(cfg *api.NodeConfig) error {
-		if cfg.Spec.Cluster.Name == "" {
-			return fmt.Errorf("Name is missing in cluster configuration")
-		}
-		if cfg.Spec.Cluster.Region == "" {
-			return fmt.Errorf("Region is missing in cluster configuration")
-		}
-		if hostnameOverride := extractFlagValue(cfg.Spec.Kubelet.Flags, hostnameOverrideFlag); hostnameOverride != "" {
-			return fmt.Errorf("hostname-override kubelet flag is not supported for hybrid nodes but found override: %s", hostnameOverride)
-		}
-		if !cfg.IsIAMRolesAnywhere() && !cfg.IsSSM() {
-			return fmt.Errorf("Either IAMRolesAnywhere or SSM must be provided for hybrid node configuration")
-		}
-		if cfg.IsIAMRolesAnywhere() && cfg.IsSSM() {
-			return fmt.Errorf("Only one of IAMRolesAnywhere or SSM must be provided for hybrid node configuration")
+		if errs := configFieldErrors(cfg); len(errs) > 0 {
+			return errs[0]
 		}
 		if cfg.IsIAMRolesAnywhere() {
-			if err := validateRolesAnywhereNode(cfg); err != nil {
-				return err
-			}
-		}
-		if cfg.IsSSM() {
-			if cfg.Spec.Hybrid.SSM.ActivationCode == "" {
-				return fmt.Errorf("ActivationCode is missing in hybrid ssm configuration")
-			}
-			if cfg.Spec.Hybrid.SSM.ActivationID == "" {
-				return fmt.Errorf("ActivationID is missing in hybrid ssm configuration")
-			}
-
-			// Compile the activation code pattern
-			reCode, err := regexp.Compile(ssmActivationCodePattern)
-			if err != nil {
-				return fmt.Errorf("internal error: invalid ActivationCode pattern: %v", err)
-			}
-			// Check if ActivationCode matches the pattern
-			if !reCode.MatchString(cfg.Spec.Hybrid.SSM.ActivationCode) {
-				return fmt.Errorf("invalid ActivationCode format: %s. Must be 20-250 characters", cfg.Spec.Hybrid.SSM.ActivationCode)
-			}
-
-			// Compile the regex patterns
-			reID, err := regexp.Compile(ssmActivationIDPattern)
-			if err != nil {
-				return fmt.Errorf("internal error: invalid ActivationID pattern: %v", err)
-			}
-			// Check if ActivationID matches the pattern
-			if !reID.MatchString(cfg.Spec.Hybrid.SSM.ActivationID) {
-				return fmt.Errorf("invalid ActivationID format: %s. Must be in format: ^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$", cfg.Spec.Hybrid.SSM.ActivationID)
-			}
+			return validateRolesAnywhereFiles(cfg)
 		}
 		return nil
 	}
@@ -95,45 +64,119 @@ func (hnp *HybridNodeProvider) ValidateConfig() error {
 	return nil
 }
 
-func validateRolesAnywhereNode(node *api.NodeConfig) error {
-	if node.Spec.Hybrid.IAMRolesAnywhere.RoleARN == "" {
-		return fmt.Errorf("RoleARN is missing in hybrid iam roles anywhere configuration")
+// ValidateConfigFields validates the fields of a hybrid node config without reading the files it
+// references or making network calls, so a config can be checked before it's on the node.
+// It returns every problem found, joined.
+func ValidateConfigFields(cfg *api.NodeConfig) error {
+	return errors.Join(configFieldErrors(cfg)...)
+}
+
+// configFieldErrors returns the problems of the config fields, in the order they are validated.
+func configFieldErrors(cfg *api.NodeConfig) []error {
+	var errs []error
+	if cfg.Spec.Cluster.Name == "" {
+		errs = append(errs, fmt.Errorf("Name is missing in cluster configuration"))
+	}
+	if cfg.Spec.Cluster.Region == "" {
+		errs = append(errs, fmt.Errorf("Region is missing in cluster configuration"))
 	}
-	if node.Spec.Hybrid.IAMRolesAnywhere.ProfileARN == "" {
-		return fmt.Errorf("ProfileARN is missing in hybrid iam roles anywhere configuration")
+	if cidr := cfg.Spec.Cluster.CIDR; cidr != "" {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid CIDR %s in cluster configuration: %w", cidr, err))
+		}
 	}
-	if node.Spec.Hybrid.IAMRolesAnywhere.TrustAnchorARN == "" {
-		return fmt.Errorf("TrustAnchorARN is missing in hybrid iam roles anywhere configuration")
+	if hostnameOverride := extractFlagValue(cfg.Spec.Kubelet.Flags, hostnameOverrideFlag); hostnameOverride != "" {
+		errs = append(errs, fmt.Errorf("hostname-override kubelet flag is not supported for hybrid nodes but found override: %s", hostnameOverride))
 	}
-	if node.Spec.Hybrid.IAMRolesAnywhere.NodeName == "" {
-		return fmt.Errorf("NodeName can't be empty in hybrid iam roles anywhere configuration")
+	if err := kubelet.ValidateHybridKubeletFlags(cfg); err != nil {
+		errs = append(errs, err)
 	}
-	if len(node.Spec.Hybrid.IAMRolesAnywhere.NodeName) > 64 {
-		return fmt.Errorf("NodeName can't be longer than 64 characters in hybrid iam roles anywhere configuration")
+	if _, err := network.ExtractNodeIPFromFlags(cfg.Spec.Kubelet.Flags); err != nil {
+		errs = append(errs, err)
 	}
-
-	// IAM roles anywhere certificate validation
-	if node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath == "" {
-		return fmt.Errorf("CertificatePath is missing in hybrid iam roles anywhere configuration")
+	if endpoint := cfg.Spec.Kubelet.ImageServiceEndpoint; endpoint != "" && !strings.HasPrefix(endpoint, "unix://") {
+		errs = append(errs, fmt.Errorf("imageServiceEndpoint in kubelet configuration must be a unix socket URI like unix:///run/containerd-stargz-grpc/containerd-stargz-grpc.sock, got %s", endpoint))
+	}
+	if err := containerd.ValidateImagePullOptions(cfg.Spec.Containerd); err != nil {
+		errs = append(errs, err)
+	}
+	if !cfg.IsIAMRolesAnywhere() && !cfg.IsSSM() {
+		errs = append(errs, fmt.Errorf("Either IAMRolesAnywhere or SSM must be provided for hybrid node configuration"))
+	}
+	if cfg.IsIAMRolesAnywhere() && cfg.IsSSM() {
+		errs = append(errs, fmt.Errorf("Only one of IAMRolesAnywhere or SSM must be provided for hybrid node configuration"))
 	}
-	if !file.Exists(node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath) {
-		return fmt.Errorf("IAM Roles Anywhere certificate %s not found", node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath)
+	if cfg.IsIAMRolesAnywhere() {
+		errs = append(errs, rolesAnywhereFieldErrors(cfg)...)
 	}
-	if err := certificate.Validate(node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath, nil); err != nil {
-		return addIAMRARemediation(node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath, err)
+	if cfg.IsSSM() {
+		errs = append(errs, ssmFieldErrors(cfg)...)
 	}
+	return errs
+}
 
-	// IAM roles anywhere key validation
-	if node.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath == "" {
-		return fmt.Errorf("PrivateKeyPath is missing in hybrid iam roles anywhere configuration")
+func rolesAnywhereFieldErrors(node *api.NodeConfig) []error {
+	var errs []error
+	iamRA := node.Spec.Hybrid.IAMRolesAnywhere
+	if iamRA.RoleARN == "" {
+		errs = append(errs, fmt.Errorf("RoleARN is missing in hybrid iam roles anywhere configuration"))
+	}
+	if iamRA.ProfileARN == "" {
+		errs = append(errs, fmt.Errorf("ProfileARN is missing in hybrid iam roles anywhere configuration"))
+	}
+	if iamRA.TrustAnchorARN == "" {
+		errs = append(errs, fmt.Errorf("TrustAnchorARN is missing in hybrid iam roles anywhere configuration"))
+	}
+	if iamRA.NodeName == "" {
+		errs = append(errs, fmt.Errorf("NodeName can't be empty in hybrid iam roles anywhere configuration"))
 	}
-	if !file.Exists(node.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath) {
-		return fmt.Errorf("IAM Roles Anywhere private key %s not found", node.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath)
+	if len(iamRA.NodeName) > 64 {
+		errs = append(errs, fmt.Errorf("NodeName can't be longer than 64 characters in hybrid iam roles anywhere configuration"))
 	}
+	if d := iamRA.SessionDuration; d != 0 && (d < iamrolesanywhere.MinSessionDuration || d > iamrolesanywhere.MaxSessionDuration) {
+		errs = append(errs, fmt.Errorf("SessionDuration must be between %d and %d seconds in hybrid iam roles anywhere configuration, got %d",
+			iamrolesanywhere.MinSessionDuration, iamrolesanywhere.MaxSessionDuration, d))
+	}
+	if iamRA.CertificatePath == "" {
+		errs = append(errs, fmt.Errorf("CertificatePath is missing in hybrid iam roles anywhere configuration"))
+	}
+	if iamRA.PrivateKeyPath == "" {
+		errs = append(errs, fmt.Errorf("PrivateKeyPath is missing in hybrid iam roles anywhere configuration"))
+	}
+	return errs
+}
 
+// validateRolesAnywhereFiles validates the IAM Roles Anywhere certificate and private key on the node.
+func validateRolesAnywhereFiles(node *api.NodeConfig) error {
+	certPath := node.Spec.Hybrid.IAMRolesAnywhere.CertificatePath
+	if !file.Exists(certPath) {
+		return fmt.Errorf("IAM Roles Anywhere certificate %s not found", certPath)
+	}
+	if err := certificate.Validate(certPath, nil); err != nil {
+		return addIAMRARemediation(certPath, err)
+	}
+	if keyPath := node.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath; !file.Exists(keyPath) {
+		return fmt.Errorf("IAM Roles Anywhere private key %s not found", keyPath)
+	}
 	return nil
 }
 
+func ssmFieldErrors(node *api.NodeConfig) []error {
+	var errs []error
+	ssm := node.Spec.Hybrid.SSM
+	if ssm.ActivationCode == "" {
+		errs = append(errs, fmt.Errorf("ActivationCode is missing in hybrid ssm configuration"))
+	} else if !ssmActivationCodeRegex.MatchString(ssm.ActivationCode) {
+		errs = append(errs, fmt.Errorf("invalid ActivationCode format: %s. Must be 20-250 characters", ssm.ActivationCode))
+	}
+	if ssm.ActivationID == "" {
+		errs = append(errs, fmt.Errorf("ActivationID is missing in hybrid ssm configuration"))
+	} else if !ssmActivationIDRegex.MatchString(ssm.ActivationID) {
+		errs = append(errs, fmt.Errorf("invalid ActivationID format: %s. Must be in format: %s", ssm.ActivationID, ssmActivationIDPattern))
+	}
+	return errs
+}
+
 // addIAMRARemediation adds IAM Role Anywhere specific remediation messages based on error type
 func addIAMRARemediation(certPath string, err error) error {
 	errWithContext := fmt.Errorf("validating iam-roles-anywhere certificate: %w", err)
@@ -107,6 +107,57 @@ func Test_HybridNodeProviderValidateConfig(t *testing.T) {
 			},
 			wantError: "NodeName can't be longer than 64 characters in hybrid iam roles anywhere configuration",
 		},
+		{
+			name: "session duration too short",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{
+						Region: "us-west-2",
+						Name:   "my-cluster",
+					},
+					Hybrid: &api.HybridOptions{
+						IAMRolesAnywhere: &api.IAMRolesAnywhere{
+							NodeName:        "my-node",
+							TrustAnchorARN:  "trust-anchor-arn",
+							ProfileARN:      "profile-arn",
+							RoleARN:         "role-arn",
+							SessionDuration: 60,
+						},
+					},
+				},
+			},
+			wantError: "SessionDuration must be between 900 and 43200 seconds in hybrid iam roles anywhere configuration, got 60",
+		},
+		{
+			name: "image service endpoint not a unix socket",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{
+						Region: "us-west-2",
+						Name:   "my-cluster",
+					},
+					Kubelet: api.KubeletOptions{
+						ImageServiceEndpoint: "tcp://127.0.0.1:5000",
+					},
+				},
+			},
+			wantError: "imageServiceEndpoint in kubelet configuration must be a unix socket URI like unix:///run/containerd-stargz-grpc/containerd-stargz-grpc.sock, got tcp://127.0.0.1:5000",
+		},
+		{
+			name: "invalid containerd image pull progress timeout",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{
+						Region: "us-west-2",
+						Name:   "my-cluster",
+					},
+					Containerd: api.ContainerdOptions{
+						ImagePullProgressTimeout: "-5m",
+					},
+				},
+			},
+			wantError: "imagePullProgressTimeout in containerd configuration must be positive, got -5m",
+		},
 		{
 			name: "no certificate path",
 			node: &api.NodeConfig{
@@ -218,6 +269,31 @@ func Test_HybridNodeProviderValidateConfig(t *testing.T) {
 			},
 			wantError: "hostname-override kubelet flag is not supported for hybrid nodes but found override: bad-config",
 		},
+		{
+			name: "conflicting cloud-provider",
+			node: &api.NodeConfig{
+				Spec: api.NodeConfigSpec{
+					Cluster: api.ClusterDetails{
+						Region: "us-west-2",
+						Name:   "my-cluster",
+					},
+					Hybrid: &api.HybridOptions{
+						IAMRolesAnywhere: &api.IAMRolesAnywhere{
+							NodeName:        "my-node",
+							TrustAnchorARN:  "trust-anchor-arn",
+							ProfileARN:      "profile-arn",
+							RoleARN:         "role-arn",
+							CertificatePath: certPath,
+							PrivateKeyPath:  keyPath,
+						},
+					},
+					Kubelet: api.KubeletOptions{
+						Flags: []string{"--cloud-provider=aws"},
+					},
+				},
+			},
+			wantError: "cloud-provider kubelet flag must be unset or external for hybrid nodes but found aws",
+		},
 		{
 			name: "certificate with wrong permission",
 			node: &api.NodeConfig{
@@ -473,3 +549,36 @@ func Test_HybridNodeProviderValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateConfigFields(t *testing.T) {
+	g := NewWithT(t)
+	nodeConfig := &api.NodeConfig{
+		Spec: api.NodeConfigSpec{
+			Cluster: api.ClusterDetails{
+				Name: "my-cluster",
+				CIDR: "not-a-cidr",
+			},
+			Hybrid: &api.HybridOptions{
+				IAMRolesAnywhere: &api.IAMRolesAnywhere{
+					NodeName:        "my-node",
+					RoleARN:         "arn:aws:iam::123456789010:role/mockHybridNodeRole",
+					ProfileARN:      "dummy-profile-arn",
+					TrustAnchorARN:  "dummy-trust-anchor",
+					CertificatePath: "/does/not/exist.crt",
+				},
+			},
+		},
+	}
+
+	err := hybrid.ValidateConfigFields(nodeConfig)
+	g.Expect(err).To(MatchError(ContainSubstring("Region is missing in cluster configuration")))
+	g.Expect(err).To(MatchError(ContainSubstring("invalid CIDR not-a-cidr in cluster configuration")))
+	g.Expect(err).To(MatchError(ContainSubstring("PrivateKeyPath is missing in hybrid iam roles anywhere configuration")))
+	// Files referenced by the config aren't read.
+	g.Expect(err).NotTo(MatchError(ContainSubstring("not found")))
+
+	nodeConfig.Spec.Cluster.Region = "us-west-2"
+	nodeConfig.Spec.Cluster.CIDR = "10.100.0.0/16"
+	nodeConfig.Spec.Hybrid.IAMRolesAnywhere.PrivateKeyPath = "/does/not/exist.key"
+	g.Expect(hybrid.ValidateConfigFields(nodeConfig)).To(Succeed())
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/blang/semver/v4"
+	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/validation"
@@ -19,7 +20,9 @@ type Kubelet interface {
 
 const (
 	maxVersionSkew = 3
-	remediation    = "Ensure the hybrid node's Kubernetes version follows the version skew policy of the EKS cluster. " +
+	// MaxVersionSkewOverride is the widest kubelet version skew that can be allowed with a version skew override.
+	MaxVersionSkewOverride = 5
+	remediation            = "Ensure the hybrid node's Kubernetes version follows the version skew policy of the EKS cluster. " +
 		"Update the node's Kubernetes components using 'nodeadm upgrade' or reinstall with a compatible version." +
 		" https://kubernetes.io/releases/version-skew-policy/#kubelet"
 )
@@ -37,17 +40,44 @@ func (hnp *HybridNodeProvider) ValidateKubeletVersionSkew(ctx context.Context, i
 		informer.Done(ctx, kubeletVersionSkew, err)
 	}()
 
+	if hnp.versionSkewOverride > MaxVersionSkewOverride {
+		hnp.logger.Warn("Kubelet version skew override is above the maximum, clamping it",
+			zap.Int("override", hnp.versionSkewOverride), zap.Int("maximum", MaxVersionSkewOverride))
+	}
+	if hnp.allowedVersionSkew() > maxVersionSkew {
+		hnp.logger.Warn("Kubelet version skew is widened beyond the Kubernetes version skew policy, which is unsupported",
+			zap.Int("allowedSkew", hnp.allowedVersionSkew()), zap.Int("supportedSkew", maxVersionSkew))
+	}
+
 	err = hnp.validateSkew()
 	return err
 }
 
+// allowedVersionSkew returns the maximum number of minor versions the kubelet can be behind the
+// kube-apiserver. It's the version skew policy unless overridden, clamped to MaxVersionSkewOverride.
+func (hnp *HybridNodeProvider) allowedVersionSkew() int {
+	if hnp.versionSkewOverride <= 0 {
+		return maxVersionSkew
+	}
+	return min(hnp.versionSkewOverride, MaxVersionSkewOverride)
+}
+
 func (hnp *HybridNodeProvider) validateSkew() error {
-	kubeApiServerVersion := *(hnp.cluster.Version)
 	kubeletVersion, err := hnp.kubelet.Version()
 	if err != nil {
 		err = fmt.Errorf("failed to get kubelet version: %w", err)
 		return validation.WithRemediation(err, remediation)
 	}
+	return validateVersionSkew(kubeletVersion, *(hnp.cluster.Version), hnp.allowedVersionSkew())
+}
+
+// ValidateVersionSkew validates a kubelet version against a kube-apiserver version
+// following the Kubernetes version skew policy.
+func ValidateVersionSkew(kubeletVersion, kubeApiServerVersion string) error {
+	return validateVersionSkew(kubeletVersion, kubeApiServerVersion, maxVersionSkew)
+}
+
+func validateVersionSkew(kubeletVersion, kubeApiServerVersion string, allowedSkew int) error {
 	apiServerSemver, err := parseK8sVersion(kubeApiServerVersion)
 	if err != nil {
 		err = fmt.Errorf("failed to parse kube-apiserver version %s: %w", kubeApiServerVersion, err)
@@ -66,11 +96,16 @@ func (hnp *HybridNodeProvider) validateSkew() error {
 	}
 
 	minorVersionDiff := int(apiServerSemver.Minor - kubeletSemver.Minor)
-	if minorVersionDiff > maxVersionSkew {
-		err = fmt.Errorf("kubelet version %s is too old for kube-apiserver version %s; maximum supported version skew is %d minor versions",
-			kubeletVersion, kubeApiServerVersion, maxVersionSkew)
+	if minorVersionDiff > allowedSkew {
+		err = fmt.Errorf("kubelet version %s is too old for kube-apiserver version %s; maximum allowed version skew is %d minor versions",
+			kubeletVersion, kubeApiServerVersion, allowedSkew)
 		return validation.WithRemediation(err, remediation)
 	}
+	if minorVersionDiff > maxVersionSkew {
+		err = fmt.Errorf("kubelet version %s is %d minor versions behind kube-apiserver version %s, beyond the supported version skew of %d minor versions",
+			kubeletVersion, minorVersionDiff, kubeApiServerVersion, maxVersionSkew)
+		return validation.WithWarning(err, "The version skew override allows this node to join, but running outside the version skew policy is unsupported. "+remediation)
+	}
 	return nil
 }
 
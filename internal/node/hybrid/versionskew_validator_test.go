@@ -114,14 +114,7 @@ func TestHybridNodeProvider_ValidateKubeletVersionSkew(t *testing.T) {
 			}
 			hnp, err := hybrid.NewHybridNodeProvider(
 				&api.NodeConfig{},
-				[]string{
-					"node-ip-validation",
-					"kubelet-cert-validation",
-					"api-server-endpoint-resolution-validation",
-					"proxy-validation",
-					"node-inactive-validation",
-					"aws-auth-validation",
-				},
+				skipAllValidationsExcept("kubelet-version-skew-validation"),
 				zap.NewNop(),
 				hybrid.WithCluster(tt.cluster),
 				hybrid.WithKubelet(mockKubelet),
@@ -140,6 +133,86 @@ func TestHybridNodeProvider_ValidateKubeletVersionSkew(t *testing.T) {
 	}
 }
 
+func TestHybridNodeProvider_ValidateKubeletVersionSkewOverride(t *testing.T) {
+	tests := []struct {
+		name           string
+		override       int
+		apiVersion     string
+		kubeletVersion string
+		expectedErr    string
+	}{
+		{
+			name:           "no override keeps the skew policy",
+			apiVersion:     "1.31",
+			kubeletVersion: "v1.28.0",
+		},
+		{
+			name:           "override narrows the accepted skew",
+			override:       1,
+			apiVersion:     "1.31",
+			kubeletVersion: "v1.29.0",
+			expectedErr:    "kubelet version v1.29.0 is too old for kube-apiserver version 1.31; maximum allowed version skew is 1 minor versions",
+		},
+		{
+			name:           "narrowed override accepts versions within it",
+			override:       1,
+			apiVersion:     "1.31",
+			kubeletVersion: "v1.30.0",
+		},
+		{
+			name:           "override widens the accepted skew",
+			override:       4,
+			apiVersion:     "1.31",
+			kubeletVersion: "v1.27.0",
+		},
+		{
+			name:           "widened override still rejects older versions",
+			override:       4,
+			apiVersion:     "1.31",
+			kubeletVersion: "v1.26.0",
+			expectedErr:    "maximum allowed version skew is 4 minor versions",
+		},
+		{
+			name:           "override is clamped to the maximum",
+			override:       10,
+			apiVersion:     "1.32",
+			kubeletVersion: "v1.26.0",
+			expectedErr:    "maximum allowed version skew is 5 minor versions",
+		},
+		{
+			name:           "clamped override accepts the maximum skew",
+			override:       10,
+			apiVersion:     "1.32",
+			kubeletVersion: "v1.27.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ctx := context.Background()
+
+			hnp, err := hybrid.NewHybridNodeProvider(
+				&api.NodeConfig{},
+				skipAllValidationsExcept("kubelet-version-skew-validation"),
+				zap.NewNop(),
+				hybrid.WithCluster(&types.Cluster{Version: aws.String(tt.apiVersion)}),
+				hybrid.WithKubelet(newMockKubelet(tt.kubeletVersion, nil)),
+				hybrid.WithAWSConfig(&aws.Config{Region: "us-west-2"}),
+				hybrid.WithVersionSkewOverride(tt.override),
+			)
+			g.Expect(err).To(Succeed())
+
+			err = hnp.Validate(ctx)
+			if tt.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectedErr)))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 // mockKubelet implements the Kubelet interface for testing
 type mockKubelet struct {
 	version      string
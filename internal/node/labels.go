@@ -0,0 +1,73 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/kubelet"
+)
+
+const (
+	// NodeadmVersionLabelKey is the node label with the nodeadm version used to join the node.
+	NodeadmVersionLabelKey = "eks.amazonaws.com/nodeadm-version"
+	// OSLabelKey is the node label with the OS distribution of a hybrid node, as in /etc/os-release.
+	OSLabelKey = "eks.amazonaws.com/hybrid-os"
+	// ArchLabelKey is the node label with the architecture nodeadm was built for.
+	ArchLabelKey = "eks.amazonaws.com/hybrid-arch"
+)
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// JoinLabels returns the labels recording how a hybrid node joined the cluster.
+// Empty values are omitted and the rest are sanitized to be valid label values.
+func JoinLabels(nodeConfig *api.NodeConfig, nodeadmVersion, osName, arch string) map[string]string {
+	labels := map[string]string{}
+	for key, value := range map[string]string{
+		NodeadmVersionLabelKey:             nodeadmVersion,
+		OSLabelKey:                         osName,
+		ArchLabelKey:                       arch,
+		kubelet.CredentialProviderLabelKey: string(nodeConfig.GetNodeType()),
+	} {
+		if value = sanitizeLabelValue(value); value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// sanitizeLabelValue replaces the characters not allowed in label values and
+// trims the value to the maximum label value length.
+func sanitizeLabelValue(value string) string {
+	value = invalidLabelValueChars.ReplaceAllString(value, "_")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	// Label values must start and end with an alphanumeric character.
+	return strings.Trim(value, "._-")
+}
+
+// LabelNode adds the labels to the node, overriding the value of existing keys
+// and leaving any other label untouched.
+func LabelNode(ctx context.Context, client kubernetes.Interface, nodeName string, labels map[string]string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"labels": labels,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshalling node labels patch")
+	}
+	if _, err := client.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return errors.Wrapf(err, "labeling node %s", nodeName)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package node_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/node"
+)
+
+func TestJoinLabels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		nodeConfig     *api.NodeConfig
+		nodeadmVersion string
+		osName         string
+		want           map[string]string
+	}{
+		{
+			name:           "ssm",
+			nodeConfig:     &api.NodeConfig{Spec: api.NodeConfigSpec{Hybrid: &api.HybridOptions{SSM: &api.SSM{}}}},
+			nodeadmVersion: "v1.0.6",
+			osName:         "ubuntu",
+			want: map[string]string{
+				"eks.amazonaws.com/nodeadm-version":            "v1.0.6",
+				"eks.amazonaws.com/hybrid-os":                  "ubuntu",
+				"eks.amazonaws.com/hybrid-arch":                "arm64",
+				"eks.amazonaws.com/hybrid-credential-provider": "ssm",
+			},
+		},
+		{
+			name:           "iam roles anywhere",
+			nodeConfig:     &api.NodeConfig{Spec: api.NodeConfigSpec{Hybrid: &api.HybridOptions{IAMRolesAnywhere: &api.IAMRolesAnywhere{}}}},
+			nodeadmVersion: "v1.0.6",
+			osName:         "rhel",
+			want: map[string]string{
+				"eks.amazonaws.com/nodeadm-version":            "v1.0.6",
+				"eks.amazonaws.com/hybrid-os":                  "rhel",
+				"eks.amazonaws.com/hybrid-arch":                "arm64",
+				"eks.amazonaws.com/hybrid-credential-provider": "iam-ra",
+			},
+		},
+		{
+			name:           "invalid characters are sanitized and empty values omitted",
+			nodeConfig:     &api.NodeConfig{Spec: api.NodeConfigSpec{Hybrid: &api.HybridOptions{SSM: &api.SSM{}}}},
+			nodeadmVersion: "v1.0.6-3-gabc123+dirty",
+			want: map[string]string{
+				"eks.amazonaws.com/nodeadm-version":            "v1.0.6-3-gabc123_dirty",
+				"eks.amazonaws.com/hybrid-arch":                "arm64",
+				"eks.amazonaws.com/hybrid-credential-provider": "ssm",
+			},
+		},
+		{
+			name:           "long values are truncated",
+			nodeConfig:     &api.NodeConfig{Spec: api.NodeConfigSpec{Hybrid: &api.HybridOptions{SSM: &api.SSM{}}}},
+			nodeadmVersion: strings.Repeat("a", 70),
+			osName:         "ubuntu",
+			want: map[string]string{
+				"eks.amazonaws.com/nodeadm-version":            strings.Repeat("a", 63),
+				"eks.amazonaws.com/hybrid-os":                  "ubuntu",
+				"eks.amazonaws.com/hybrid-arch":                "arm64",
+				"eks.amazonaws.com/hybrid-credential-provider": "ssm",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(node.JoinLabels(tc.nodeConfig, tc.nodeadmVersion, tc.osName, "arm64")).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestLabelNode(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-node",
+			Labels: map[string]string{
+				"kubernetes.io/hostname":            "my-node",
+				"eks.amazonaws.com/nodeadm-version": "v1.0.5",
+			},
+		},
+	})
+	nodeConfig := &api.NodeConfig{Spec: api.NodeConfigSpec{Hybrid: &api.HybridOptions{SSM: &api.SSM{}}}}
+
+	err := node.LabelNode(ctx, client, "my-node", node.JoinLabels(nodeConfig, "v1.0.6", "ubuntu", "amd64"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	n, err := client.CoreV1().Nodes().Get(ctx, "my-node", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(n.Labels).To(Equal(map[string]string{
+		"kubernetes.io/hostname":                       "my-node",
+		"eks.amazonaws.com/nodeadm-version":            "v1.0.6",
+		"eks.amazonaws.com/hybrid-os":                  "ubuntu",
+		"eks.amazonaws.com/hybrid-arch":                "amd64",
+		"eks.amazonaws.com/hybrid-credential-provider": "ssm",
+	}))
+}
+
+func TestLabelNodeNotFound(t *testing.T) {
+	g := NewWithT(t)
+	client := fake.NewSimpleClientset()
+
+	err := node.LabelNode(context.Background(), client, "my-node", map[string]string{node.OSLabelKey: "ubuntu"})
+	g.Expect(err).To(MatchError(ContainSubstring("labeling node my-node")))
+}
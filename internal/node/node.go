@@ -3,15 +3,18 @@ package node
 import (
 	"go.uber.org/zap"
 
+	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/configprovider"
 	"github.com/aws/eks-hybrid/internal/node/ec2"
 	"github.com/aws/eks-hybrid/internal/node/hybrid"
 	"github.com/aws/eks-hybrid/internal/nodeprovider"
 )
 
-func NewNodeProvider(configSource string, skipPhases []string, logger *zap.Logger) (nodeprovider.NodeProvider, error) {
-	logger.Info("Loading configuration...", zap.String("configSource", configSource))
-	provider, err := configprovider.BuildConfigProvider(configSource)
+// NewNodeProvider loads the node config from configSource, merging the config from
+// overlaySource on top of it if not empty, and returns its NodeProvider.
+func NewNodeProvider(configSource, overlaySource string, skipPhases []string, logger *zap.Logger, opts ...hybrid.NodeProviderOpt) (nodeprovider.NodeProvider, error) {
+	logger.Info("Loading configuration...", zap.String("configSource", configSource), zap.String("configOverlay", overlaySource))
+	provider, err := configprovider.BuildConfigProviderWithOverlay(configSource, overlaySource)
 	if err != nil {
 		return nil, err
 	}
@@ -19,9 +22,15 @@ func NewNodeProvider(configSource string, skipPhases []string, logger *zap.Logge
 	if err != nil {
 		return nil, err
 	}
+	return NewNodeProviderForConfig(nodeConfig, skipPhases, logger, opts...)
+}
+
+// NewNodeProviderForConfig returns the NodeProvider for an already loaded node config.
+// The hybrid options are ignored for EC2 nodes.
+func NewNodeProviderForConfig(nodeConfig *api.NodeConfig, skipPhases []string, logger *zap.Logger, opts ...hybrid.NodeProviderOpt) (nodeprovider.NodeProvider, error) {
 	if nodeConfig.IsHybridNode() {
 		logger.Info("Setting up hybrid node provider...")
-		return hybrid.NewHybridNodeProvider(nodeConfig, skipPhases, logger)
+		return hybrid.NewHybridNodeProvider(nodeConfig, skipPhases, logger, opts...)
 	}
 	logger.Info("Setting up EC2 node provider...")
 	return ec2.NewEc2NodeProvider(nodeConfig, logger)
@@ -5,16 +5,50 @@ import (
 	"fmt"
 	"time"
 
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/kubelet"
+	k8s "github.com/aws/eks-hybrid/internal/kubernetes"
 	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/retry"
 	"github.com/aws/eks-hybrid/internal/validation"
 )
 
+// RecommendedReadinessStabilityPeriod gives a node that just joined time to flap back to NotReady,
+// which can happen until the control plane's node-monitor-grace-period has elapsed.
+const RecommendedReadinessStabilityPeriod = 30 * time.Second
+
 type ActiveNodeValidator struct {
 	validateRegistration bool
 	validateReadiness    bool
+	emitJoinEvents       bool
 	timeout              time.Duration
+	stabilityPeriod      time.Duration
+	kubeconfigPath       string
+	kubeconfigContext    string
+	// validateDaemonSetPods waits for the system DaemonSet pods to run on the node once it's ready.
+	validateDaemonSetPods bool
+	// daemonSets are the DaemonSets expected to run on the node, all the ones in kube-system selecting it if empty.
+	daemonSets []string
+
+	// newClient overrides building the client from the kubeconfig, for tests.
+	newClient func() (kubernetes.Interface, error)
+	// registrationOpts configure the node registration checker, for tests.
+	registrationOpts []func(*nodeRegistrationChecker)
+	// apiServerRetrierOpts configure the API server reachability retries, for tests.
+	apiServerRetrierOpts []retry.RetrierOption
+}
+
+// ActiveNodeResult is what the active node validation found out about the node.
+type ActiveNodeResult struct {
+	NodeName string
+	// CNI is the CNI detected on the node once it's ready, or CNIUnknown if it couldn't be detected.
+	CNI      string
+	Ready    bool
+	Duration time.Duration
 }
 
 func NewActiveNodeValidator(opts ...func(*ActiveNodeValidator)) ActiveNodeValidator {
@@ -41,6 +75,42 @@ func WithNodeReadiness(validate bool) func(*ActiveNodeValidator) {
 	}
 }
 
+// WithReadinessStabilityPeriod configures how long the node needs to stay ready
+// before the readiness validation succeeds. Zero, the default, accepts the first Ready observation.
+func WithReadinessStabilityPeriod(period time.Duration) func(*ActiveNodeValidator) {
+	return func(v *ActiveNodeValidator) {
+		v.stabilityPeriod = period
+	}
+}
+
+// WithKubeconfig configures the kubeconfig file and context used to build the
+// Kubernetes client. Empty values default to the kubelet kubeconfig and its current context.
+func WithKubeconfig(path, context string) func(*ActiveNodeValidator) {
+	return func(v *ActiveNodeValidator) {
+		v.kubeconfigPath = path
+		v.kubeconfigContext = context
+	}
+}
+
+// WithJoinEvents configures the validator to emit Kubernetes events against the Node
+// object when the node registers, a CNI is detected and the node becomes ready.
+// Failures to emit events are logged and never fail the validation.
+func WithJoinEvents(emit bool) func(*ActiveNodeValidator) {
+	return func(v *ActiveNodeValidator) {
+		v.emitJoinEvents = emit
+	}
+}
+
+// WithDaemonSetPods configures the validator to wait, once the node is ready, for the pods of the
+// system DaemonSets to be scheduled and running on the node. DaemonSets are given in namespace/name
+// form, or just name for kube-system. Without DaemonSets, all the ones in kube-system selecting the node are expected.
+func WithDaemonSetPods(validate bool, daemonSets ...string) func(*ActiveNodeValidator) {
+	return func(v *ActiveNodeValidator) {
+		v.validateDaemonSetPods = validate
+		v.daemonSets = daemonSets
+	}
+}
+
 // configures the timeout for validations
 func WithTimeout(timeout time.Duration) func(*ActiveNodeValidator) {
 	return func(v *ActiveNodeValidator) {
@@ -48,11 +118,37 @@ func WithTimeout(timeout time.Duration) func(*ActiveNodeValidator) {
 	}
 }
 
+// buildClient builds the Kubernetes client from the configured kubeconfig
+// or defaults to the kubelet's kubeconfig.
+func (v ActiveNodeValidator) buildClient() (kubernetes.Interface, error) {
+	if v.newClient != nil {
+		return v.newClient()
+	}
+	if v.kubeconfigPath == "" && v.kubeconfigContext == "" {
+		return kubelet.New().BuildClient()
+	}
+	var opts []kubelet.KubeClientOption
+	if v.kubeconfigPath != "" {
+		opts = append(opts, kubelet.WithKubeconfigPath(v.kubeconfigPath))
+	}
+	if v.kubeconfigContext != "" {
+		opts = append(opts, kubelet.WithKubeconfigContext(v.kubeconfigContext))
+	}
+	return kubelet.GetKubeClientFromKubeConfig(opts...)
+}
+
 func (v ActiveNodeValidator) Run(ctx context.Context, informer validation.Informer, nodeConfig *api.NodeConfig) error {
-	var err error
+	_, err := v.Execute(ctx, informer, nodeConfig)
+	return err
+}
+
+// Execute validates the node registers and becomes ready like Run, and returns what it found
+// out about the node. The result is zero-valued if the Kubernetes client can't be built.
+func (v ActiveNodeValidator) Execute(ctx context.Context, informer validation.Informer, nodeConfig *api.NodeConfig) (result ActiveNodeResult, err error) {
 	var hostname string
 	name := "active-node-validation"
 	log := logger.FromContext(ctx)
+	start := time.Now()
 
 	// Create a context with timeout
 	ctx, cancel := context.WithTimeout(ctx, v.timeout)
@@ -63,37 +159,67 @@ func (v ActiveNodeValidator) Run(ctx context.Context, informer validation.Inform
 		informer.Done(ctx, name, err)
 	}()
 
-	// Create Kubernetes client using kubelet
-	kubeletInstance := kubelet.New()
-	k8sClient, err := kubeletInstance.BuildClient()
+	k8sClient, err := v.buildClient()
 	if err != nil {
 		err = validation.WithRemediation(err,
 			"Ensure kubelet is properly configured with valid kubeconfig and the API server is accessible.")
-		return err
+		return ActiveNodeResult{}, err
 	}
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	// Fail fast with the reason the API server can't be used instead of timing out waiting for the node.
+	if _, err = k8s.WaitForAPIServer(ctx, k8sClient, v.apiServerRetrierOpts...); err != nil {
+		return result, err
+	}
+
+	events := newJoinEventEmitter(v.emitJoinEvents, k8sClient, log)
 
 	// Node Registration validation
 	if v.validateRegistration {
-		hostname, err = waitForNodeRegistrationValidation(ctx, k8sClient, v.timeout, log)
+		hostname, err = waitForNodeRegistrationValidation(ctx, k8sClient, v.timeout, log, v.registrationOpts...)
 		if err != nil || hostname == "" {
 			if hostname == "" {
 				hostname = "null"
 			}
 			err = validation.WithRemediation(err,
 				fmt.Sprintf("Detected Hostname: %s, verify this node's network connectivity and authentication credentials.", hostname))
-			return err
+			return result, err
 		}
+		result.NodeName = hostname
+		events.registered(ctx, hostname)
 	}
 
 	// Node Readiness validation
 	if v.validateReadiness {
-		err = waitForNodeReadiness(ctx, k8sClient, hostname, v.timeout, log)
+		// Emit the CNI event as soon as the CNI shows up, so it's recorded even if the node never becomes ready.
+		err = waitForNodeReadiness(ctx, k8sClient, hostname, v.timeout, v.stabilityPeriod, log,
+			WithNodeObserver(func(*corev1.Node) { events.cniDetected(ctx, hostname) }))
 		if err != nil {
 			err = validation.WithRemediation(err,
 				"Check kubelet logs and ensure the node has joined the cluster properly.")
-			return err
+			return result, err
+		}
+		result.Ready = true
+		events.ready(ctx, hostname)
+
+		result.CNI, err = newCNIDetector(k8sClient).detect(ctx, hostname)
+		if err != nil {
+			// The node is ready, not knowing the CNI doesn't fail the validation.
+			log.Debug("Failed to detect CNI", zap.String("node", hostname), zap.Error(err))
+			err = nil
+		}
+	}
+
+	if v.validateDaemonSetPods {
+		err = NewDaemonSetPodsChecker(k8sClient, v.timeout, log, v.daemonSets...).WaitForDaemonSetPods(ctx, hostname)
+		if err != nil {
+			err = validation.WithRemediation(err,
+				"Ensure the DaemonSets tolerate the node taints and select its labels, and check the pod events with kubectl describe pod.")
+			return result, err
 		}
 	}
 
-	return nil
+	return result, nil
 }
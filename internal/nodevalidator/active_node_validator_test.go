@@ -0,0 +1,99 @@
+package nodevalidator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	k8s "github.com/aws/eks-hybrid/internal/kubernetes"
+	"github.com/aws/eks-hybrid/internal/retry"
+	"github.com/aws/eks-hybrid/internal/test"
+)
+
+func TestActiveNodeValidatorBuildClientWithKubeconfig(t *testing.T) {
+	kubeconfig := "../kubelet/testdata/multi-context-kubeconfig.yaml"
+
+	v := NewActiveNodeValidator(WithKubeconfig(kubeconfig, "other"))
+	client, err := v.buildClient()
+	require.NoError(t, err)
+	assert.NotNil(t, client)
+
+	v = NewActiveNodeValidator(WithKubeconfig(kubeconfig, "missing"))
+	_, err = v.buildClient()
+	assert.ErrorContains(t, err, "context missing not found")
+}
+
+func TestActiveNodeValidatorExecute(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionFalse},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(node, cniPod("cilium-abc", "cilium", "test-node"))
+
+	v := NewActiveNodeValidator(WithTimeout(5 * time.Second))
+	v.newClient = func() (kubernetes.Interface, error) { return client, nil }
+	v.registrationOpts = []func(*nodeRegistrationChecker){
+		withNodeName(func() (string, error) { return "test-node", nil }),
+	}
+
+	result, err := v.Execute(context.Background(), test.NewFakeInformer(), &api.NodeConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "test-node", result.NodeName)
+	assert.Equal(t, CNICilium, result.CNI)
+	assert.True(t, result.Ready)
+	assert.Positive(t, result.Duration)
+}
+
+func TestActiveNodeValidatorExecuteClientError(t *testing.T) {
+	v := NewActiveNodeValidator()
+	v.newClient = func() (kubernetes.Interface, error) { return nil, errors.New("no kubeconfig") }
+
+	result, err := v.Execute(context.Background(), test.NewFakeInformer(), &api.NodeConfig{})
+	assert.ErrorContains(t, err, "no kubeconfig")
+	assert.Zero(t, result)
+
+	// Run keeps returning the same error.
+	assert.ErrorContains(t, v.Run(context.Background(), test.NewFakeInformer(), &api.NodeConfig{}), "no kubeconfig")
+}
+
+func TestActiveNodeValidatorExecuteAPIServerForbidden(t *testing.T) {
+	server := test.NewHTTPSServerForJSON(t, http.StatusForbidden, &metav1.Status{
+		Status: metav1.StatusFailure,
+		Code:   http.StatusForbidden,
+		Reason: metav1.StatusReasonForbidden,
+	})
+
+	v := NewActiveNodeValidator(WithTimeout(5 * time.Second))
+	v.newClient = func() (kubernetes.Interface, error) {
+		return kubernetes.NewForConfig(&rest.Config{
+			Host:            server.URL,
+			BearerToken:     "token",
+			TLSClientConfig: rest.TLSClientConfig{CAData: server.CAPEM()},
+		})
+	}
+	v.apiServerRetrierOpts = []retry.RetrierOption{retry.WithBackoffDuration(time.Millisecond)}
+
+	result, err := v.Execute(context.Background(), test.NewFakeInformer(), &api.NodeConfig{})
+	reachabilityErr := &k8s.APIServerReachabilityError{}
+	require.ErrorAs(t, err, &reachabilityErr)
+	assert.Equal(t, k8s.APIServerForbidden, reachabilityErr.Failure)
+	assert.Empty(t, result.NodeName)
+	assert.False(t, result.Ready)
+}
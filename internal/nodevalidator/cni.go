@@ -0,0 +1,179 @@
+package nodevalidator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	CNICilium  = "cilium"
+	CNICalico  = "calico"
+	CNIFlannel = "flannel"
+	// CNIOther is a CNI nodeadm doesn't recognize but that configured the node network.
+	CNIOther   = "other"
+	CNIUnknown = "unknown"
+
+	cniConfDir = "/etc/cni/net.d"
+	cniBinDir  = "/opt/cni/bin"
+)
+
+// cniAgentLabels maps the k8s-app label of each supported CNI agent daemonset to its CNI name.
+var cniAgentLabels = map[string]string{
+	"cilium":      CNICilium,
+	"calico-node": CNICalico,
+	"flannel":     CNIFlannel,
+}
+
+// cniPluginBinaries maps the CNI plugin binary installed by each supported CNI to its CNI name.
+var cniPluginBinaries = map[string]string{
+	"cilium-cni": CNICilium,
+	"calico":     CNICalico,
+	"flannel":    CNIFlannel,
+}
+
+// cniDetector detects the CNI running on a node from the cluster and the node CNI dirs.
+type cniDetector struct {
+	client kubernetes.Interface
+	// confDir is the dir with the CNI network configs.
+	confDir string
+	// binDir is the dir with the CNI plugin binaries.
+	binDir string
+}
+
+// newCNIDetector returns a cniDetector for the default CNI dirs.
+func newCNIDetector(client kubernetes.Interface) *cniDetector {
+	return newCNIDetectorWithPaths(client, cniConfDir, cniBinDir)
+}
+
+func newCNIDetectorWithPaths(client kubernetes.Interface, confDir, binDir string) *cniDetector {
+	return &cniDetector{
+		client:  client,
+		confDir: confDir,
+		binDir:  binDir,
+	}
+}
+
+// DetectLocalCNI returns the CNI from the CNI configs and plugin binaries on the node, without querying the cluster.
+func DetectLocalCNI() (string, error) {
+	return newCNIDetector(nil).detectLocal()
+}
+
+// detect returns the CNI running on the node by looking for a known CNI agent pod
+// scheduled on it, falling back to the node CNI dirs and the node network condition.
+func (d *cniDetector) detect(ctx context.Context, nodeName string) (string, error) {
+	pods, err := d.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: "k8s-app in (cilium, calico-node, flannel)",
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return CNIUnknown, fmt.Errorf("listing CNI pods for node %s: %w", nodeName, err)
+	}
+	for _, pod := range pods.Items {
+		// Field selectors are not honored by every client, so double check the node.
+		if pod.Spec.NodeName != nodeName {
+			continue
+		}
+		if cni, ok := cniAgentLabels[pod.Labels["k8s-app"]]; ok {
+			return cni, nil
+		}
+	}
+
+	cni, err := d.detectLocal()
+	if err != nil {
+		return CNIUnknown, err
+	}
+	if cni != CNIUnknown {
+		return cni, nil
+	}
+
+	// Without CNI configs, a CNI that reports the node network as available is still running.
+	node, err := d.client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return CNIUnknown, fmt.Errorf("getting node %s: %w", nodeName, err)
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeNetworkUnavailable {
+			if condition.Status == corev1.ConditionFalse {
+				return CNIOther, nil
+			}
+			return CNIUnknown, nil
+		}
+	}
+	return CNIUnknown, fmt.Errorf("no CNI config found in %s and node %s doesn't report its network condition", d.confDir, nodeName)
+}
+
+// detectLocal returns the CNI from the config files, falling back to the plugin binaries
+// of the known CNIs, which are installed before the agent writes its config.
+func (d *cniDetector) detectLocal() (string, error) {
+	cni, err := d.detectFromConfigFiles()
+	if err != nil || cni != CNIUnknown {
+		return cni, err
+	}
+	return d.detectFromBinaries()
+}
+
+// detectFromConfigFiles returns the CNI from the config file names in confDir, or CNIOther
+// if there are configs of a CNI nodeadm doesn't recognize. It returns CNIUnknown without configs.
+func (d *cniDetector) detectFromConfigFiles() (string, error) {
+	entries, err := os.ReadDir(d.confDir)
+	if os.IsNotExist(err) {
+		return CNIUnknown, nil
+	} else if err != nil {
+		return CNIUnknown, fmt.Errorf("reading CNI config dir %s: %w", d.confDir, err)
+	}
+
+	cni := CNIUnknown
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if !isCNIConfigFile(entry.Name()) {
+			continue
+		}
+		name := strings.ToLower(entry.Name())
+		for _, known := range []string{CNICilium, CNICalico, CNIFlannel} {
+			if strings.Contains(name, known) {
+				return known, nil
+			}
+		}
+		cni = CNIOther
+	}
+	return cni, nil
+}
+
+// isCNIConfigFile returns true for the file extensions the container runtime loads CNI network configs from.
+func isCNIConfigFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".conflist", ".conf", ".json":
+		return true
+	}
+	return false
+}
+
+// detectFromBinaries returns the CNI whose plugin binary is in binDir, or CNIUnknown if there is none.
+// The reference plugins, like bridge or host-local, are installed with every CNI so they are ignored.
+func (d *cniDetector) detectFromBinaries() (string, error) {
+	entries, err := os.ReadDir(d.binDir)
+	if os.IsNotExist(err) {
+		return CNIUnknown, nil
+	} else if err != nil {
+		return CNIUnknown, fmt.Errorf("reading CNI bin dir %s: %w", d.binDir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if cni, ok := cniPluginBinaries[entry.Name()]; ok {
+			return cni, nil
+		}
+	}
+	return CNIUnknown, nil
+}
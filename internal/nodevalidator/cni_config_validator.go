@@ -0,0 +1,101 @@
+package nodevalidator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const cniConfigsValidation = "cni-configs"
+
+// CNIConfigValidator validates there is a single CNI network config on the node. The container
+// runtime only loads the first config in lexical order, so configs left behind by a previous
+// CNI can make pods use the wrong network.
+type CNIConfigValidator struct {
+	confDir string
+}
+
+// CNIConfigValidatorOpt configures a CNIConfigValidator.
+type CNIConfigValidatorOpt func(*CNIConfigValidator)
+
+// WithCNIConfDir overrides the dir the CNI network configs are read from.
+func WithCNIConfDir(dir string) CNIConfigValidatorOpt {
+	return func(v *CNIConfigValidator) {
+		v.confDir = dir
+	}
+}
+
+// NewCNIConfigValidator creates a new CNIConfigValidator.
+func NewCNIConfigValidator(opts ...CNIConfigValidatorOpt) *CNIConfigValidator {
+	v := &CNIConfigValidator{
+		confDir: cniConfDir,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Run returns a warning if the CNI config dir has configs for more than one network.
+func (v *CNIConfigValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, cniConfigsValidation, "Validating there is a single CNI network config")
+	defer func() {
+		informer.Done(ctx, cniConfigsValidation, err)
+	}()
+	err = v.Validate()
+	return err
+}
+
+// Validate returns a warning naming the config the container runtime selects if the
+// CNI config dir has configs for more than one network.
+func (v *CNIConfigValidator) Validate() error {
+	entries, err := os.ReadDir(v.confDir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading CNI config dir %s: %w", v.confDir, err)
+	}
+
+	var configs []string
+	networks := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isCNIConfigFile(entry.Name()) {
+			continue
+		}
+		network := cniNetworkName(filepath.Join(v.confDir, entry.Name()))
+		networks[network] = true
+		configs = append(configs, fmt.Sprintf("%s (network %s)", entry.Name(), network))
+	}
+	if len(networks) < 2 {
+		return nil
+	}
+
+	// The runtime sorts the config file names, same as the dir entries are.
+	slices.Sort(configs)
+	return validation.WithWarning(
+		fmt.Errorf("found CNI configs for %d different networks in %s, the container runtime only uses the first one, %s. Configs: %s",
+			len(networks), v.confDir, configs[0], strings.Join(configs, ", ")),
+		fmt.Sprintf("Remove the configs left behind by CNIs no longer in use from %s, usually after switching CNIs, so the runtime doesn't select the wrong one.", v.confDir),
+	)
+}
+
+// cniNetworkName returns the network name in a CNI config, falling back to the
+// file name when the config can't be read.
+func cniNetworkName(path string) string {
+	var config struct {
+		Name string `json:"name"`
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &config) != nil || config.Name == "" {
+		return filepath.Base(path)
+	}
+	return config.Name
+}
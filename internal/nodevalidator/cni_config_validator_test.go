@@ -0,0 +1,79 @@
+package nodevalidator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestCNIConfigValidator(t *testing.T) {
+	tests := []struct {
+		name        string
+		configs     map[string]string
+		wantWarning string
+	}{
+		{
+			name: "single config",
+			configs: map[string]string{
+				"05-cilium.conflist": `{"name": "cilium", "plugins": [{"type": "cilium-cni"}]}`,
+				"calico-kubeconfig":  "apiVersion: v1",
+			},
+		},
+		{
+			name: "same network in several files",
+			configs: map[string]string{
+				"10-calico.conflist": `{"name": "k8s-pod-network"}`,
+				"10-calico.conf":     `{"name": "k8s-pod-network"}`,
+			},
+		},
+		{
+			name: "leftover calico config after switching to cilium",
+			configs: map[string]string{
+				"05-cilium.conflist": `{"name": "cilium"}`,
+				"10-calico.conflist": `{"name": "k8s-pod-network"}`,
+			},
+			wantWarning: "found CNI configs for 2 different networks in %s, the container runtime only uses the first one, 05-cilium.conflist (network cilium). " +
+				"Configs: 05-cilium.conflist (network cilium), 10-calico.conflist (network k8s-pod-network)",
+		},
+		{
+			name: "leftover config sorted first",
+			configs: map[string]string{
+				"05-cilium.conflist": `{"name": "cilium"}`,
+				"00-aws.conflist":    `{"name": "aws-cni"}`,
+				"99-loopback.conf":   `not json`,
+			},
+			wantWarning: "found CNI configs for 3 different networks in %s, the container runtime only uses the first one, 00-aws.conflist (network aws-cni). " +
+				"Configs: 00-aws.conflist (network aws-cni), 05-cilium.conflist (network cilium), 99-loopback.conf (network 99-loopback.conf)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.configs {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+
+			err := NewCNIConfigValidator(WithCNIConfDir(dir)).Run(context.Background(), validation.NewPrinter(), &api.NodeConfig{})
+			if tc.wantWarning == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, validation.IsWarning(err))
+			assert.EqualError(t, err, fmt.Sprintf(tc.wantWarning, dir))
+			assert.Contains(t, validation.Remediation(err), "Remove the configs left behind")
+		})
+	}
+
+	t.Run("missing dir", func(t *testing.T) {
+		assert.NoError(t, NewCNIConfigValidator(WithCNIConfDir(filepath.Join(t.TempDir(), "net.d"))).Validate())
+	})
+}
@@ -0,0 +1,113 @@
+package nodevalidator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files []string) {
+	t.Helper()
+	for _, file := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("{}"), 0o755))
+	}
+}
+
+func TestCNIDetectorDetectFromConfigFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected string
+	}{
+		{
+			name:     "cilium",
+			files:    []string{"05-cilium.conflist"},
+			expected: CNICilium,
+		},
+		{
+			name:     "calico",
+			files:    []string{"10-calico.conflist", "calico-kubeconfig"},
+			expected: CNICalico,
+		},
+		{
+			name:     "none",
+			expected: CNIUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confDir := t.TempDir()
+			writeFiles(t, confDir, tt.files)
+
+			cni, err := newCNIDetectorWithPaths(nil, confDir, t.TempDir()).detectFromConfigFiles()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cni)
+		})
+	}
+
+	t.Run("missing dir", func(t *testing.T) {
+		cni, err := newCNIDetectorWithPaths(nil, filepath.Join(t.TempDir(), "net.d"), "").detectFromConfigFiles()
+		require.NoError(t, err)
+		assert.Equal(t, CNIUnknown, cni)
+	})
+}
+
+func TestCNIDetectorDetectFromBinaries(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected string
+	}{
+		{
+			name:     "cilium",
+			files:    []string{"cilium-cni", "loopback"},
+			expected: CNICilium,
+		},
+		{
+			name:     "calico",
+			files:    []string{"bridge", "calico", "calico-ipam", "host-local"},
+			expected: CNICalico,
+		},
+		{
+			name:     "none",
+			files:    []string{"bridge", "host-local", "loopback", "portmap"},
+			expected: CNIUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binDir := t.TempDir()
+			writeFiles(t, binDir, tt.files)
+
+			cni, err := newCNIDetectorWithPaths(nil, t.TempDir(), binDir).detectFromBinaries()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cni)
+		})
+	}
+}
+
+func TestCNIDetectorDetectLocal(t *testing.T) {
+	t.Run("configs win over binaries", func(t *testing.T) {
+		confDir, binDir := t.TempDir(), t.TempDir()
+		writeFiles(t, confDir, []string{"10-calico.conflist"})
+		writeFiles(t, binDir, []string{"cilium-cni"})
+
+		cni, err := newCNIDetectorWithPaths(nil, confDir, binDir).detectLocal()
+		require.NoError(t, err)
+		assert.Equal(t, CNICalico, cni)
+	})
+
+	t.Run("binaries without configs", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeFiles(t, binDir, []string{"cilium-cni"})
+
+		cni, err := newCNIDetectorWithPaths(nil, t.TempDir(), binDir).detectLocal()
+		require.NoError(t, err)
+		assert.Equal(t, CNICilium, cni)
+	})
+}
@@ -0,0 +1,227 @@
+package nodevalidator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+
+	k8s "github.com/aws/eks-hybrid/internal/kubernetes"
+)
+
+type daemonSetPodsChecker struct {
+	client  kubernetes.Interface
+	timeout time.Duration
+	logger  *zap.Logger
+	// daemonSets are the DaemonSets, in namespace/name form, expected to run on the node.
+	// When empty, all DaemonSets in kube-system that would schedule a pod on the node are expected.
+	daemonSets []string
+}
+
+// NewDaemonSetPodsChecker returns a checker that waits for the pods of the system DaemonSets to be
+// scheduled and running on a node. DaemonSets are given in namespace/name form, or just name for kube-system.
+func NewDaemonSetPodsChecker(client kubernetes.Interface, timeout time.Duration, logger *zap.Logger, daemonSets ...string) *daemonSetPodsChecker {
+	return &daemonSetPodsChecker{
+		client:     client,
+		timeout:    timeout,
+		logger:     logger,
+		daemonSets: daemonSets,
+	}
+}
+
+// daemonSetPodProblem is why a DaemonSet doesn't have a healthy pod on the node.
+type daemonSetPodProblem struct {
+	daemonSet string
+	problem   string
+}
+
+func (p daemonSetPodProblem) String() string {
+	return fmt.Sprintf("%s (%s)", p.daemonSet, p.problem)
+}
+
+// WaitForDaemonSetPods waits for every expected DaemonSet to have a running and ready pod on the node.
+// Without configured DaemonSets, the ones excluded from the node by their affinity or taints aren't expected.
+func (c *daemonSetPodsChecker) WaitForDaemonSetPods(ctx context.Context, nodeName string) error {
+	var missing, unhealthy []daemonSetPodProblem
+	_, err := k8s.WaitFor(ctx, c.timeout, func(ctx context.Context) (*corev1.PodList, error) {
+		expected, notFound, err := c.expectedDaemonSets(ctx, nodeName)
+		if err != nil {
+			return nil, err
+		}
+		pods, err := c.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + nodeName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing pods for node %s: %w", nodeName, err)
+		}
+		missing, unhealthy = checkDaemonSetPods(expected, pods.Items, nodeName)
+		missing = append(notFound, missing...)
+		return pods, nil
+	}, func(*corev1.PodList) bool {
+		if len(missing) > 0 || len(unhealthy) > 0 {
+			c.logger.Info("Waiting for system DaemonSet pods to run on the node", zap.String("nodeName", nodeName),
+				zap.Stringers("missing", missing), zap.Stringers("unhealthy", unhealthy))
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		var problems []string
+		if len(missing) > 0 {
+			problems = append(problems, "missing pods for "+joinProblems(missing))
+		}
+		if len(unhealthy) > 0 {
+			problems = append(problems, "unhealthy pods for "+joinProblems(unhealthy))
+		}
+		if len(problems) == 0 {
+			return fmt.Errorf("system DaemonSet pods did not run on node '%s' within timeout %v: %w", nodeName, c.timeout, err)
+		}
+		return fmt.Errorf("system DaemonSet pods did not run on node '%s' within timeout %v, %s: %w",
+			nodeName, c.timeout, strings.Join(problems, ", "), err)
+	}
+	return nil
+}
+
+// expectedDaemonSets returns the DaemonSets that should run a pod on the node,
+// and the configured DaemonSets that don't exist in the cluster.
+func (c *daemonSetPodsChecker) expectedDaemonSets(ctx context.Context, nodeName string) ([]appsv1.DaemonSet, []daemonSetPodProblem, error) {
+	if len(c.daemonSets) > 0 {
+		var expected []appsv1.DaemonSet
+		var notFound []daemonSetPodProblem
+		for _, daemonSet := range c.daemonSets {
+			namespace, name := splitDaemonSet(daemonSet)
+			ds, err := c.client.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				notFound = append(notFound, daemonSetPodProblem{daemonSet: namespace + "/" + name, problem: "DaemonSet not found"})
+				continue
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("getting DaemonSet %s/%s: %w", namespace, name, err)
+			}
+			expected = append(expected, *ds)
+		}
+		return expected, notFound, nil
+	}
+
+	node, err := c.client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting node %s: %w", nodeName, err)
+	}
+	daemonSets, err := c.client.AppsV1().DaemonSets(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing DaemonSets in %s: %w", metav1.NamespaceSystem, err)
+	}
+	var expected []appsv1.DaemonSet
+	for _, ds := range daemonSets.Items {
+		if shouldRunOnNode(ds, node) {
+			expected = append(expected, ds)
+		}
+	}
+	return expected, nil, nil
+}
+
+// daemonSetTolerations are the tolerations the DaemonSet controller adds to every DaemonSet pod.
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// shouldRunOnNode returns true if the DaemonSet controller would schedule a pod of ds on the node:
+// its node selector and required node affinity match the node and its pods tolerate the node taints.
+func shouldRunOnNode(ds appsv1.DaemonSet, node *corev1.Node) bool {
+	pod := &corev1.Pod{Spec: ds.Spec.Template.Spec}
+	if matches, err := nodeaffinity.GetRequiredNodeAffinity(pod).Match(node); err != nil || !matches {
+		return false
+	}
+
+	tolerations := append(slices.Clone(pod.Spec.Tolerations), daemonSetTolerations...)
+	if pod.Spec.HostNetwork {
+		tolerations = append(tolerations, corev1.Toleration{
+			Key: corev1.TaintNodeNetworkUnavailable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule,
+		})
+	}
+	_, untolerated := corev1helpers.FindMatchingUntoleratedTaint(node.Spec.Taints, tolerations, func(taint *corev1.Taint) bool {
+		return taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute
+	})
+	return !untolerated
+}
+
+// checkDaemonSetPods returns the DaemonSets without a pod on the node and the ones with a pod that isn't running and ready.
+func checkDaemonSetPods(daemonSets []appsv1.DaemonSet, pods []corev1.Pod, nodeName string) (missing, unhealthy []daemonSetPodProblem) {
+	for _, ds := range daemonSets {
+		name := ds.Namespace + "/" + ds.Name
+		pod := daemonSetPod(ds, pods, nodeName)
+		if pod == nil {
+			missing = append(missing, daemonSetPodProblem{daemonSet: name, problem: "no pod scheduled on the node"})
+			continue
+		}
+		if problem := podProblem(pod); problem != "" {
+			unhealthy = append(unhealthy, daemonSetPodProblem{daemonSet: name, problem: fmt.Sprintf("pod %s is %s", pod.Name, problem)})
+		}
+	}
+	return missing, unhealthy
+}
+
+// daemonSetPod returns the pod owned by the DaemonSet on the node, if any.
+func daemonSetPod(ds appsv1.DaemonSet, pods []corev1.Pod, nodeName string) *corev1.Pod {
+	for i := range pods {
+		pod := &pods[i]
+		// Field selectors are not honored by every client, so double check the node.
+		if pod.Namespace != ds.Namespace || pod.Spec.NodeName != nodeName {
+			continue
+		}
+		owner := metav1.GetControllerOf(pod)
+		if owner != nil && owner.Kind == "DaemonSet" && owner.Name == ds.Name {
+			return pod
+		}
+	}
+	return nil
+}
+
+// podProblem describes why the pod isn't running and ready, or returns empty if it is.
+func podProblem(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			return fmt.Sprintf("%s: %s", pod.Status.Phase, status.State.Waiting.Reason)
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return string(pod.Status.Phase)
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return ""
+		}
+	}
+	return "not ready"
+}
+
+// splitDaemonSet splits a DaemonSet in namespace/name form, defaulting to kube-system.
+func splitDaemonSet(daemonSet string) (namespace, name string) {
+	if namespace, name, found := strings.Cut(daemonSet, "/"); found {
+		return namespace, name
+	}
+	return metav1.NamespaceSystem, daemonSet
+}
+
+func joinProblems(problems []daemonSetPodProblem) string {
+	names := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		names = append(names, problem.String())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,215 @@
+package nodevalidator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/test"
+)
+
+func daemonSet(namespace, name string, nodeSelector map[string]string) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{NodeSelector: nodeSelector},
+			},
+		},
+	}
+}
+
+func withTolerations(ds *appsv1.DaemonSet, tolerations ...corev1.Toleration) *appsv1.DaemonSet {
+	ds.Spec.Template.Spec.Tolerations = tolerations
+	return ds
+}
+
+func withRequiredNodeAffinity(ds *appsv1.DaemonSet, key string, operator corev1.NodeSelectorOperator, values ...string) *appsv1.DaemonSet {
+	ds.Spec.Template.Spec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: key, Operator: operator, Values: values}},
+				}},
+			},
+		},
+	}
+	return ds
+}
+
+func daemonSetPodOn(namespace, daemonSet, podName, nodeName string, phase corev1.PodPhase, ready bool) *corev1.Pod {
+	controller := true
+	readyStatus := corev1.ConditionFalse
+	if ready {
+		readyStatus = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: daemonSet, Controller: &controller},
+			},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+		Status: corev1.PodStatus{
+			Phase:      phase,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+		},
+	}
+}
+
+func TestDaemonSetPodsCheckerWaitForDaemonSetPods(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-node",
+			Labels: map[string]string{"kubernetes.io/os": "linux", "eks.amazonaws.com/compute-type": "hybrid"},
+		},
+	}
+	taintedNode := node.DeepCopy()
+	taintedNode.Spec.Taints = []corev1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeNotReady, Effect: corev1.TaintEffectNoExecute},
+		{Key: "example.com/maintenance", Effect: corev1.TaintEffectPreferNoSchedule},
+	}
+	imagePullBackOff := daemonSetPodOn("kube-system", "cilium", "cilium-abc", "test-node", corev1.PodPending, false)
+	imagePullBackOff.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: "cilium-agent", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+	}
+
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		daemonSets []string
+		wantErr    []string
+		notWantErr []string
+	}{
+		{
+			name: "all daemonsets selecting the node are running",
+			objects: []runtime.Object{
+				node,
+				daemonSet("kube-system", "kube-proxy", nil),
+				daemonSet("kube-system", "cilium", map[string]string{"kubernetes.io/os": "linux"}),
+				// Doesn't select the node, so it's not expected to run on it.
+				daemonSet("kube-system", "windows-proxy", map[string]string{"kubernetes.io/os": "windows"}),
+				daemonSetPodOn("kube-system", "kube-proxy", "kube-proxy-abc", "test-node", corev1.PodRunning, true),
+				daemonSetPodOn("kube-system", "cilium", "cilium-abc", "test-node", corev1.PodRunning, true),
+			},
+		},
+		{
+			name: "pod not scheduled on the node",
+			objects: []runtime.Object{
+				node,
+				daemonSet("kube-system", "kube-proxy", nil),
+				daemonSet("kube-system", "node-exporter", nil),
+				daemonSetPodOn("kube-system", "kube-proxy", "kube-proxy-abc", "test-node", corev1.PodRunning, true),
+				// Scheduled on a different node.
+				daemonSetPodOn("kube-system", "node-exporter", "node-exporter-abc", "other-node", corev1.PodRunning, true),
+			},
+			wantErr:    []string{"missing pods for kube-system/node-exporter (no pod scheduled on the node)"},
+			notWantErr: []string{"kube-proxy", "unhealthy"},
+		},
+		{
+			name: "daemonsets excluded by node affinity or untolerated taints",
+			objects: []runtime.Object{
+				taintedNode,
+				withTolerations(daemonSet("kube-system", "kube-proxy", nil),
+					corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule}),
+				withTolerations(daemonSet("kube-system", "cilium", nil), corev1.Toleration{Operator: corev1.TolerationOpExists}),
+				withRequiredNodeAffinity(daemonSet("kube-system", "cloud-node", nil), "eks.amazonaws.com/compute-type", corev1.NodeSelectorOpNotIn, "hybrid"),
+				daemonSet("kube-system", "ebs-csi-node", nil),
+				daemonSetPodOn("kube-system", "cilium", "cilium-abc", "test-node", corev1.PodRunning, true),
+			},
+			wantErr:    []string{"missing pods for kube-system/kube-proxy (no pod scheduled on the node)"},
+			notWantErr: []string{"cilium", "cloud-node", "ebs-csi-node"},
+		},
+		{
+			name: "pods scheduled but unhealthy",
+			objects: []runtime.Object{
+				node,
+				daemonSet("kube-system", "kube-proxy", nil),
+				daemonSet("kube-system", "cilium", nil),
+				daemonSetPodOn("kube-system", "kube-proxy", "kube-proxy-abc", "test-node", corev1.PodRunning, false),
+				imagePullBackOff,
+			},
+			wantErr: []string{
+				"unhealthy pods for",
+				"kube-system/kube-proxy (pod kube-proxy-abc is not ready)",
+				"kube-system/cilium (pod cilium-abc is Pending: ImagePullBackOff)",
+			},
+			notWantErr: []string{"missing"},
+		},
+		{
+			name: "configured daemonsets",
+			objects: []runtime.Object{
+				node,
+				daemonSet("kube-system", "kube-proxy", nil),
+				daemonSet("kube-system", "unrelated", nil),
+				daemonSet("monitoring", "node-exporter", nil),
+				daemonSetPodOn("kube-system", "kube-proxy", "kube-proxy-abc", "test-node", corev1.PodRunning, true),
+			},
+			daemonSets: []string{"kube-proxy", "monitoring/node-exporter", "monitoring/fluent-bit"},
+			wantErr: []string{
+				"monitoring/fluent-bit (DaemonSet not found)",
+				"monitoring/node-exporter (no pod scheduled on the node)",
+			},
+			notWantErr: []string{"unrelated", "kube-proxy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.objects...)
+			checker := NewDaemonSetPodsChecker(client, 100*time.Millisecond, zaptest.NewLogger(t), tt.daemonSets...)
+
+			err := checker.WaitForDaemonSetPods(context.Background(), "test-node")
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+			for _, notWant := range tt.notWantErr {
+				assert.NotContains(t, err.Error(), notWant)
+			}
+		})
+	}
+}
+
+func TestActiveNodeValidatorExecuteDaemonSetPods(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	client := fake.NewSimpleClientset(node,
+		daemonSet("kube-system", "kube-proxy", nil),
+		daemonSet("kube-system", "cilium", nil),
+		daemonSetPodOn("kube-system", "kube-proxy", "kube-proxy-abc", "test-node", corev1.PodRunning, true),
+	)
+
+	v := NewActiveNodeValidator(WithTimeout(time.Second), WithDaemonSetPods(true))
+	v.newClient = func() (kubernetes.Interface, error) { return client, nil }
+	v.registrationOpts = []func(*nodeRegistrationChecker){
+		withNodeName(func() (string, error) { return "test-node", nil }),
+	}
+
+	result, err := v.Execute(context.Background(), test.NewFakeInformer(), &api.NodeConfig{})
+	assert.ErrorContains(t, err, "missing pods for kube-system/cilium (no pod scheduled on the node)")
+	assert.True(t, result.Ready)
+}
@@ -0,0 +1,66 @@
+package nodevalidator
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+
+	k8s "github.com/aws/eks-hybrid/internal/kubernetes"
+)
+
+// joinEventEmitter emits the node join milestones as Kubernetes events.
+// A disabled emitter is a no-op.
+type joinEventEmitter struct {
+	enabled  bool
+	recorder k8s.NodeEventRecorder
+	logger   *zap.Logger
+	cni      *cniDetector
+	// cniEmitted records the CNI detected event was already emitted so it's only emitted once.
+	cniEmitted bool
+}
+
+func newJoinEventEmitter(enabled bool, client kubernetes.Interface, logger *zap.Logger) *joinEventEmitter {
+	return &joinEventEmitter{
+		enabled:  enabled,
+		recorder: k8s.NewNodeEventRecorder(client, logger),
+		logger:   logger,
+		cni:      newCNIDetector(client),
+	}
+}
+
+func (e *joinEventEmitter) registered(ctx context.Context, nodeName string) {
+	if !e.enabled {
+		return
+	}
+	e.recorder.Normal(ctx, nodeName, k8s.NodeRegisteredReason, "Hybrid node registered with the cluster")
+}
+
+// cniDetected emits the CNI detected event the first time a known CNI is found running on the node.
+// It's called while waiting for the node to be ready, since a missing CNI is the usual reason for a
+// node not becoming ready and the event shows how far the join got.
+func (e *joinEventEmitter) cniDetected(ctx context.Context, nodeName string) {
+	if !e.enabled || e.cniEmitted {
+		return
+	}
+	cni, err := e.cni.detect(ctx, nodeName)
+	if err != nil {
+		e.logger.Debug("Failed to detect CNI", zap.String("node", nodeName), zap.Error(err))
+		return
+	}
+	if cni == CNIUnknown {
+		return
+	}
+	e.recorder.Normal(ctx, nodeName, k8s.NodeCNIDetectedReason, fmt.Sprintf("Detected CNI %s on hybrid node", cni))
+	e.cniEmitted = true
+}
+
+// ready emits the ready event, preceded by the CNI detected event if it wasn't emitted yet.
+func (e *joinEventEmitter) ready(ctx context.Context, nodeName string) {
+	if !e.enabled {
+		return
+	}
+	e.cniDetected(ctx, nodeName)
+	e.recorder.Normal(ctx, nodeName, k8s.NodeReadyReason, "Hybrid node is ready")
+}
@@ -0,0 +1,191 @@
+package nodevalidator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	k8s "github.com/aws/eks-hybrid/internal/kubernetes"
+)
+
+func cniPod(name, app, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "kube-system",
+			Labels:    map[string]string{"k8s-app": app},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func eventReasons(t *testing.T, client *fake.Clientset) []string {
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	reasons := make([]string, 0, len(events.Items))
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	return reasons
+}
+
+func TestDetectCNI(t *testing.T) {
+	tests := []struct {
+		name        string
+		pods        []*corev1.Pod
+		conditions  []corev1.NodeCondition
+		configFiles []string
+		expected    string
+		wantErr     string
+	}{
+		{
+			name:     "cilium",
+			pods:     []*corev1.Pod{cniPod("cilium-abc", "cilium", "test-node")},
+			expected: CNICilium,
+		},
+		{
+			name:     "calico",
+			pods:     []*corev1.Pod{cniPod("calico-node-abc", "calico-node", "test-node")},
+			expected: CNICalico,
+		},
+		{
+			name:     "flannel",
+			pods:     []*corev1.Pod{cniPod("kube-flannel-ds-abc", "flannel", "test-node")},
+			expected: CNIFlannel,
+		},
+		{
+			name:        "flannel conflist",
+			configFiles: []string{"10-flannel.conflist"},
+			expected:    CNIFlannel,
+		},
+		{
+			name:        "generic bridge conflist",
+			configFiles: []string{"10-bridge.conflist"},
+			expected:    CNIOther,
+		},
+		{
+			name:        "non config files are ignored",
+			configFiles: []string{"README"},
+			wantErr:     "no CNI config found",
+		},
+		{
+			name:       "network available without configs",
+			conditions: []corev1.NodeCondition{{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionFalse}},
+			expected:   CNIOther,
+		},
+		{
+			name:       "network unavailable without configs",
+			conditions: []corev1.NodeCondition{{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionTrue}},
+			expected:   CNIUnknown,
+		},
+		{
+			name:     "cni pod on another node",
+			pods:     []*corev1.Pod{cniPod("cilium-abc", "cilium", "other-node")},
+			expected: CNIUnknown,
+			wantErr:  "no CNI config found",
+		},
+		{
+			name:     "no cni",
+			expected: CNIUnknown,
+			wantErr:  "no CNI config found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Status:     corev1.NodeStatus{Conditions: tt.conditions},
+			}
+			client := fake.NewSimpleClientset(node)
+			for _, pod := range tt.pods {
+				_, err := client.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			confDir := t.TempDir()
+			for _, file := range tt.configFiles {
+				require.NoError(t, os.WriteFile(filepath.Join(confDir, file), []byte("{}"), 0o644))
+			}
+
+			cni, err := newCNIDetectorWithPaths(client, confDir, t.TempDir()).detect(context.Background(), "test-node")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cni)
+		})
+	}
+}
+
+func TestJoinEventEmitter(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+
+	t.Run("emits all milestones", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node, cniPod("cilium-abc", "cilium", "test-node"))
+		emitter := newJoinEventEmitter(true, client, zaptest.NewLogger(t))
+		emitter.cni = newCNIDetectorWithPaths(client, t.TempDir(), t.TempDir())
+
+		emitter.registered(context.Background(), "test-node")
+		emitter.ready(context.Background(), "test-node")
+
+		assert.ElementsMatch(t, []string{
+			k8s.NodeRegisteredReason,
+			k8s.NodeCNIDetectedReason,
+			k8s.NodeReadyReason,
+		}, eventReasons(t, client))
+	})
+
+	t.Run("skips cni event when no cni is detected", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node)
+		emitter := newJoinEventEmitter(true, client, zaptest.NewLogger(t))
+		emitter.cni = newCNIDetectorWithPaths(client, t.TempDir(), t.TempDir())
+
+		emitter.ready(context.Background(), "test-node")
+
+		assert.Equal(t, []string{k8s.NodeReadyReason}, eventReasons(t, client))
+	})
+
+	t.Run("emits cni event once", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node, cniPod("cilium-abc", "cilium", "test-node"))
+		emitter := newJoinEventEmitter(true, client, zaptest.NewLogger(t))
+		emitter.cni = newCNIDetectorWithPaths(client, t.TempDir(), t.TempDir())
+
+		emitter.cniDetected(context.Background(), "test-node")
+		emitter.cniDetected(context.Background(), "test-node")
+		emitter.ready(context.Background(), "test-node")
+
+		assert.Equal(t, []string{k8s.NodeCNIDetectedReason, k8s.NodeReadyReason}, eventReasons(t, client))
+	})
+
+	t.Run("emits cni event while node is not ready", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node, cniPod("cilium-abc", "cilium", "test-node"))
+		emitter := newJoinEventEmitter(true, client, zaptest.NewLogger(t))
+		emitter.cni = newCNIDetectorWithPaths(client, t.TempDir(), t.TempDir())
+
+		err := waitForNodeReadiness(context.Background(), client, "test-node", 100*time.Millisecond, 0, zaptest.NewLogger(t),
+			WithNodeObserver(func(*corev1.Node) { emitter.cniDetected(context.Background(), "test-node") }))
+
+		require.Error(t, err)
+		assert.Equal(t, []string{k8s.NodeCNIDetectedReason}, eventReasons(t, client))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		client := fake.NewSimpleClientset(node)
+		emitter := newJoinEventEmitter(false, client, zaptest.NewLogger(t))
+
+		emitter.registered(context.Background(), "test-node")
+		emitter.ready(context.Background(), "test-node")
+
+		assert.Empty(t, eventReasons(t, client))
+	})
+}
@@ -16,23 +16,89 @@ type nodeReadinessChecker struct {
 	client  kubernetes.Interface
 	timeout time.Duration
 	logger  *zap.Logger
+	// stabilityPeriod is how long the node needs to be observed ready without
+	// interruption before it's considered ready. Zero returns on the first Ready observation.
+	stabilityPeriod time.Duration
+	// observe is called with the node on every readiness check.
+	observe func(node *corev1.Node)
+	// extraConditions are required on top of the default readiness conditions.
+	extraConditions []RequiredNodeCondition
 }
 
-func NewNodeReadinessChecker(client kubernetes.Interface, timeout time.Duration, logger *zap.Logger) *nodeReadinessChecker {
-	return &nodeReadinessChecker{
+// RequiredNodeCondition is a node condition that needs to have Status for the node to be considered ready.
+type RequiredNodeCondition struct {
+	Type   corev1.NodeConditionType
+	Status corev1.ConditionStatus
+	// AllowMissing considers a node without the condition ready.
+	AllowMissing bool
+}
+
+var (
+	// networkAvailableCondition is only set by some CNIs, so nodes without it are considered ready.
+	networkAvailableCondition = RequiredNodeCondition{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionFalse, AllowMissing: true}
+	readyCondition            = RequiredNodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}
+)
+
+func NewNodeReadinessChecker(client kubernetes.Interface, timeout time.Duration, logger *zap.Logger, opts ...func(*nodeReadinessChecker)) *nodeReadinessChecker {
+	nrc := &nodeReadinessChecker{
 		client:  client,
 		timeout: timeout,
 		logger:  logger,
 	}
+	for _, opt := range opts {
+		opt(nrc)
+	}
+	return nrc
+}
+
+// WithStabilityPeriod requires the node to stay ready for the given duration.
+// Right after joining, a node can flap back to NotReady until the control plane's
+// node-monitor-grace-period has elapsed, so a single Ready observation is not enough.
+func WithStabilityPeriod(period time.Duration) func(*nodeReadinessChecker) {
+	return func(nrc *nodeReadinessChecker) {
+		nrc.stabilityPeriod = period
+	}
 }
 
-// WaitForNodeReadiness waits for the node to become ready
+// WithNodeObserver calls observe with the node every time its readiness is checked,
+// including while it's not ready yet.
+func WithNodeObserver(observe func(node *corev1.Node)) func(*nodeReadinessChecker) {
+	return func(nrc *nodeReadinessChecker) {
+		nrc.observe = observe
+	}
+}
+
+// WithRequiredConditions requires the node to have the given conditions, in addition to
+// the NodeReady and NetworkUnavailable conditions, before it's considered ready.
+func WithRequiredConditions(conditions ...RequiredNodeCondition) func(*nodeReadinessChecker) {
+	return func(nrc *nodeReadinessChecker) {
+		nrc.extraConditions = append(nrc.extraConditions, conditions...)
+	}
+}
+
+// WaitForNodeReadiness waits for the node to become ready and stay ready for the stability period
 func (nrc *nodeReadinessChecker) WaitForNodeReadiness(ctx context.Context, nodeName string) error {
-	// Wait for the node to be ready
+	var readySince time.Time
 	_, err := k8s.GetAndWait(ctx, nrc.timeout, nrc.client.CoreV1().Nodes(), nodeName, func(node *corev1.Node) bool {
-		return node != nil && nrc.isNodeReady(node)
+		if nrc.observe != nil {
+			nrc.observe(node)
+		}
+		if node == nil || !nrc.isNodeReady(node) {
+			if !readySince.IsZero() {
+				nrc.logger.Info("Node flapped back to NotReady, waiting for it to stabilize", zap.String("nodeName", nodeName))
+			}
+			readySince = time.Time{}
+			return false
+		}
+		if readySince.IsZero() {
+			readySince = time.Now()
+		}
+		return time.Since(readySince) >= nrc.stabilityPeriod
 	})
 	if err != nil {
+		if !readySince.IsZero() {
+			return fmt.Errorf("node '%s' did not stay ready for %v within timeout %v: %w", nodeName, nrc.stabilityPeriod, nrc.timeout, err)
+		}
 		return fmt.Errorf("node '%s' did not become ready within timeout %v: %w", nodeName, nrc.timeout, err)
 	}
 
@@ -47,29 +113,30 @@ func (nrc *nodeReadinessChecker) isNodeReady(node *corev1.Node) bool {
 		return false
 	}
 
-	// Check network availability
-	if !nrc.isNetworkAvailable(node) {
-		nrc.logger.Error("Node network is not available", zap.String("nodeName", node.Name))
-		return false
-	}
-
-	// Check basic node ready condition
-	if !nrc.hasReadyCondition(node) {
-		nrc.logger.Error("Node does not have Ready condition", zap.String("nodeName", node.Name))
-		return false
+	for _, required := range nrc.requiredConditions() {
+		if !hasCondition(node, required) {
+			nrc.logger.Error("Node condition doesn't have the required status", zap.String("nodeName", node.Name),
+				zap.String("condition", string(required.Type)), zap.String("requiredStatus", string(required.Status)))
+			return false
+		}
 	}
 
 	return true
 }
 
-// hasReadyCondition checks if the node has Ready condition set to True
-func (nrc *nodeReadinessChecker) hasReadyCondition(node *corev1.Node) bool {
+// requiredConditions returns the default readiness conditions followed by the extra ones.
+func (nrc *nodeReadinessChecker) requiredConditions() []RequiredNodeCondition {
+	return append([]RequiredNodeCondition{networkAvailableCondition, readyCondition}, nrc.extraConditions...)
+}
+
+// hasCondition checks if the node has the required condition with the required status.
+func hasCondition(node *corev1.Node, required RequiredNodeCondition) bool {
 	for _, condition := range node.Status.Conditions {
-		if condition.Type == corev1.NodeReady {
-			return condition.Status == corev1.ConditionTrue
+		if condition.Type == required.Type {
+			return condition.Status == required.Status
 		}
 	}
-	return false
+	return required.AllowMissing
 }
 
 // hasInternalIP checks if the node has an internal IP address
@@ -82,21 +149,9 @@ func (nrc *nodeReadinessChecker) hasInternalIP(node *corev1.Node) bool {
 	return false
 }
 
-// isNetworkAvailable checks if the node network is available
-func (nrc *nodeReadinessChecker) isNetworkAvailable(node *corev1.Node) bool {
-	for _, condition := range node.Status.Conditions {
-		if condition.Type == corev1.NodeNetworkUnavailable {
-			// Network is available if NetworkUnavailable condition is False
-			return condition.Status == corev1.ConditionFalse
-		}
-	}
-	// If NetworkUnavailable condition is not present, assume network is available
-	return true
-}
-
 // waitForNodeReadiness waits for node readiness
-func waitForNodeReadiness(ctx context.Context, client kubernetes.Interface, nodeName string, timeout time.Duration, logger *zap.Logger) error {
-	checker := NewNodeReadinessChecker(client, timeout, logger)
+func waitForNodeReadiness(ctx context.Context, client kubernetes.Interface, nodeName string, timeout, stabilityPeriod time.Duration, logger *zap.Logger, opts ...func(*nodeReadinessChecker)) error {
+	checker := NewNodeReadinessChecker(client, timeout, logger, append([]func(*nodeReadinessChecker){WithStabilityPeriod(stabilityPeriod)}, opts...)...)
 	err := checker.WaitForNodeReadiness(ctx, nodeName)
 	if err != nil {
 		return err
@@ -10,7 +10,9 @@ import (
 	"go.uber.org/zap/zaptest"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestNewNodeReadinessChecker(t *testing.T) {
@@ -181,16 +183,7 @@ func TestNodeReadinessChecker_IsNodeReady(t *testing.T) {
 	}
 }
 
-func TestNodeReadinessChecker_HasReadyCondition(t *testing.T) {
-	client := fake.NewSimpleClientset()
-	logger := zaptest.NewLogger(t)
-	timeout := 5 * time.Minute
-	checker := &nodeReadinessChecker{
-		client:  client,
-		timeout: timeout,
-		logger:  logger,
-	}
-
+func TestHasCondition_Ready(t *testing.T) {
 	tests := []struct {
 		name     string
 		node     *corev1.Node
@@ -265,7 +258,7 @@ func TestNodeReadinessChecker_HasReadyCondition(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			hasReady := checker.hasReadyCondition(tt.node)
+			hasReady := hasCondition(tt.node, readyCondition)
 			assert.Equal(t, tt.expected, hasReady)
 		})
 	}
@@ -373,16 +366,7 @@ func TestNodeReadinessChecker_HasInternalIP(t *testing.T) {
 	}
 }
 
-func TestNodeReadinessChecker_IsNetworkAvailable(t *testing.T) {
-	client := fake.NewSimpleClientset()
-	logger := zaptest.NewLogger(t)
-	timeout := 5 * time.Minute
-	checker := &nodeReadinessChecker{
-		client:  client,
-		timeout: timeout,
-		logger:  logger,
-	}
-
+func TestHasCondition_NetworkAvailable(t *testing.T) {
 	tests := []struct {
 		name     string
 		node     *corev1.Node
@@ -457,7 +441,7 @@ func TestNodeReadinessChecker_IsNetworkAvailable(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			isAvailable := checker.isNetworkAvailable(tt.node)
+			isAvailable := hasCondition(tt.node, networkAvailableCondition)
 			assert.Equal(t, tt.expected, isAvailable)
 		})
 	}
@@ -541,7 +525,7 @@ func TestWaitForNodeReadiness_Wrapper(t *testing.T) {
 	nodeName := "test-node"
 
 	// Test the wrapper function
-	err := waitForNodeReadiness(ctx, client, nodeName, timeout, logger)
+	err := waitForNodeReadiness(ctx, client, nodeName, timeout, 0, logger)
 	assert.Error(t, err) // Expected to fail as node doesn't exist
 }
 
@@ -587,3 +571,132 @@ func TestNodeReadinessChecker_ErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func testNode(ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+// flappingClient returns a fake clientset that serves the node readiness from states
+// on each get, repeating the last state once exhausted. It returns a pointer to the number of gets.
+func flappingClient(states ...bool) (*fake.Clientset, *int) {
+	client := fake.NewSimpleClientset()
+	gets := 0
+	client.PrependReactor("get", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		state := states[min(gets, len(states)-1)]
+		gets++
+		return true, testNode(state), nil
+	})
+	return client, &gets
+}
+
+func TestNodeReadinessChecker_WaitForNodeReadiness(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("ready on first observation without stability period", func(t *testing.T) {
+		client, gets := flappingClient(true, false)
+		checker := NewNodeReadinessChecker(client, 3*time.Second, logger)
+
+		require.NoError(t, checker.WaitForNodeReadiness(context.Background(), "test-node"))
+		assert.Equal(t, 1, *gets)
+	})
+
+	t.Run("tolerates flaps before stabilizing", func(t *testing.T) {
+		client, gets := flappingClient(true, false, true, false, true)
+		checker := NewNodeReadinessChecker(client, 5*time.Second, logger, WithStabilityPeriod(time.Second))
+
+		start := time.Now()
+		require.NoError(t, checker.WaitForNodeReadiness(context.Background(), "test-node"))
+		// The node must be observed ready for the whole period after the last flap.
+		assert.Greater(t, *gets, 5)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("fails when the node never stays ready", func(t *testing.T) {
+		states := make([]bool, 100)
+		for i := range states {
+			states[i] = i%2 == 0
+		}
+		client, _ := flappingClient(states...)
+		checker := NewNodeReadinessChecker(client, 2*time.Second, logger, WithStabilityPeriod(time.Second))
+
+		err := checker.WaitForNodeReadiness(context.Background(), "test-node")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "did not")
+	})
+}
+
+func TestNodeReadinessChecker_IsNodeReadyWithRequiredConditions(t *testing.T) {
+	const gpuReady corev1.NodeConditionType = "example.com/GPUReady"
+	readyNode := func(conditions ...corev1.NodeCondition) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Status: corev1.NodeStatus{
+				Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+				Conditions: append([]corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}, conditions...),
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		required      []RequiredNodeCondition
+		node          *corev1.Node
+		expectedReady bool
+	}{
+		{
+			name:          "no extra conditions",
+			node:          readyNode(),
+			expectedReady: true,
+		},
+		{
+			name:          "extra condition present",
+			required:      []RequiredNodeCondition{{Type: gpuReady, Status: corev1.ConditionTrue}},
+			node:          readyNode(corev1.NodeCondition{Type: gpuReady, Status: corev1.ConditionTrue}),
+			expectedReady: true,
+		},
+		{
+			name:          "extra condition absent",
+			required:      []RequiredNodeCondition{{Type: gpuReady, Status: corev1.ConditionTrue}},
+			node:          readyNode(),
+			expectedReady: false,
+		},
+		{
+			name:          "extra condition with another status",
+			required:      []RequiredNodeCondition{{Type: gpuReady, Status: corev1.ConditionTrue}},
+			node:          readyNode(corev1.NodeCondition{Type: gpuReady, Status: corev1.ConditionFalse}),
+			expectedReady: false,
+		},
+		{
+			name:          "extra condition allowed to be absent",
+			required:      []RequiredNodeCondition{{Type: gpuReady, Status: corev1.ConditionTrue, AllowMissing: true}},
+			node:          readyNode(),
+			expectedReady: true,
+		},
+		{
+			name:     "default conditions still required",
+			required: []RequiredNodeCondition{{Type: gpuReady, Status: corev1.ConditionTrue}},
+			node: readyNode(
+				corev1.NodeCondition{Type: gpuReady, Status: corev1.ConditionTrue},
+				corev1.NodeCondition{Type: corev1.NodeNetworkUnavailable, Status: corev1.ConditionTrue},
+			),
+			expectedReady: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewNodeReadinessChecker(fake.NewSimpleClientset(), time.Minute, zaptest.NewLogger(t), WithRequiredConditions(tt.required...))
+			assert.Equal(t, tt.expectedReady, checker.isNodeReady(tt.node))
+		})
+	}
+}
@@ -19,21 +19,35 @@ type nodeRegistrationChecker struct {
 	client  kubernetes.Interface
 	timeout time.Duration
 	logger  *zap.Logger
+	// nodeName returns the name the node registers with.
+	nodeName func() (string, error)
 }
 
 // NewNodeRegistrationChecker creates a new NodeRegistrationChecker
-func NewNodeRegistrationChecker(client kubernetes.Interface, timeout time.Duration, logger *zap.Logger) *nodeRegistrationChecker {
-	return &nodeRegistrationChecker{
-		client:  client,
-		timeout: timeout,
-		logger:  logger,
+func NewNodeRegistrationChecker(client kubernetes.Interface, timeout time.Duration, logger *zap.Logger, opts ...func(*nodeRegistrationChecker)) *nodeRegistrationChecker {
+	nrc := &nodeRegistrationChecker{
+		client:   client,
+		timeout:  timeout,
+		logger:   logger,
+		nodeName: kubelet.GetNodeName,
+	}
+	for _, opt := range opts {
+		opt(nrc)
+	}
+	return nrc
+}
+
+// withNodeName overrides how the node name is read, which defaults to the kubelet configuration.
+func withNodeName(nodeName func() (string, error)) func(*nodeRegistrationChecker) {
+	return func(nrc *nodeRegistrationChecker) {
+		nrc.nodeName = nodeName
 	}
 }
 
 // WaitForNodeRegistration waits for the node to register with the Kubernetes cluster
 func (nrc *nodeRegistrationChecker) WaitForNodeRegistration(ctx context.Context) (string, error) {
 	// Get the node name from kubelet configuration
-	nodeName, err := kubelet.GetNodeName()
+	nodeName, err := nrc.nodeName()
 	if err != nil {
 		return "", fmt.Errorf("failed to get node name from kubelet: %w", err)
 	}
@@ -57,8 +71,8 @@ func (nrc *nodeRegistrationChecker) WaitForNodeRegistration(ctx context.Context)
 }
 
 // waitForNodeRegistrationValidation waits for node registration
-func waitForNodeRegistrationValidation(ctx context.Context, client kubernetes.Interface, timeout time.Duration, logger *zap.Logger) (string, error) {
-	checker := NewNodeRegistrationChecker(client, timeout, logger)
+func waitForNodeRegistrationValidation(ctx context.Context, client kubernetes.Interface, timeout time.Duration, logger *zap.Logger, opts ...func(*nodeRegistrationChecker)) (string, error) {
+	checker := NewNodeRegistrationChecker(client, timeout, logger, opts...)
 	nodeName, err := checker.WaitForNodeRegistration(ctx)
 	if err != nil {
 		return "", err
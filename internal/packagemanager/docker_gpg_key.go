@@ -0,0 +1,44 @@
+package packagemanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+
+	"github.com/aws/eks-hybrid/internal/util"
+)
+
+const (
+	// ubuntuDockerGpgKeyFingerprint is the fingerprint of the Docker release signing key,
+	// published at https://docs.docker.com/engine/install/ubuntu/.
+	ubuntuDockerGpgKeyFingerprint = "9dc858229fc7dd38854ae2d88d81803c0ebfcd88"
+	// gpgKeyAttemptTimeout bounds each attempt to download a GPG key, which is a few KB.
+	gpgKeyAttemptTimeout = 30 * time.Second
+)
+
+// downloadGpgKey downloads the armored GPG key from url, retrying attempts that fail or take
+// longer than attemptTimeout, and checks its primary key has the expected fingerprint.
+func downloadGpgKey(ctx context.Context, url, fingerprint string, attemptTimeout time.Duration) ([]byte, error) {
+	data, err := util.GetHttpFile(ctx, url, util.WithAttemptTimeout(attemptTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyGpgKeyFingerprint(data, fingerprint); err != nil {
+		return nil, fmt.Errorf("verifying gpg key from %s: %w", url, err)
+	}
+	return data, nil
+}
+
+func verifyGpgKeyFingerprint(armored []byte, fingerprint string) error {
+	key, err := crypto.NewKeyFromArmored(string(armored))
+	if err != nil {
+		return fmt.Errorf("parsing gpg key: %w", err)
+	}
+	if got := key.GetFingerprint(); !strings.EqualFold(got, fingerprint) {
+		return fmt.Errorf("gpg key fingerprint %s doesn't match the expected fingerprint %s", got, fingerprint)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package packagemanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	. "github.com/onsi/gomega"
+)
+
+func generateArmoredKey(g *WithT) (string, string) {
+	key, err := crypto.PGP().KeyGeneration().
+		AddUserId("test", "test@example.com").
+		New().GenerateKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	armored, err := key.GetArmoredPublicKey()
+	g.Expect(err).NotTo(HaveOccurred())
+	return armored, key.GetFingerprint()
+}
+
+func TestDownloadGpgKey(t *testing.T) {
+	g := NewWithT(t)
+	armored, fingerprint := generateArmoredKey(g)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(armored))
+	}))
+	defer server.Close()
+
+	data, err := downloadGpgKey(context.Background(), server.URL, fingerprint, time.Second)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal(armored))
+}
+
+func TestDownloadGpgKeyWrongFingerprint(t *testing.T) {
+	g := NewWithT(t)
+	armored, _ := generateArmoredKey(g)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(armored))
+	}))
+	defer server.Close()
+
+	_, err := downloadGpgKey(context.Background(), server.URL, ubuntuDockerGpgKeyFingerprint, time.Second)
+	g.Expect(err).To(MatchError(ContainSubstring("doesn't match the expected fingerprint " + ubuntuDockerGpgKeyFingerprint)))
+}
+
+func TestDownloadGpgKeyNotAKey(t *testing.T) {
+	g := NewWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>captive portal</html>"))
+	}))
+	defer server.Close()
+
+	_, err := downloadGpgKey(context.Background(), server.URL, ubuntuDockerGpgKeyFingerprint, time.Second)
+	g.Expect(err).To(MatchError(ContainSubstring("parsing gpg key")))
+}
+
+func TestDownloadGpgKeyHangingServer(t *testing.T) {
+	g := NewWithT(t)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := downloadGpgKey(context.Background(), server.URL, ubuntuDockerGpgKeyFingerprint, 100*time.Millisecond)
+	g.Expect(err).To(MatchError(ContainSubstring("giving up after 3 attempts")))
+	g.Expect(attempts.Load()).To(BeEquivalentTo(3))
+	g.Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+}
+
+func TestDownloadGpgKeyStalledBody(t *testing.T) {
+	g := NewWithT(t)
+	armored, fingerprint := generateArmoredKey(g)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// Send the headers and part of the key, then stall.
+			_, _ = w.Write([]byte(armored[:10]))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(armored))
+	}))
+	defer server.Close()
+
+	data, err := downloadGpgKey(context.Background(), server.URL, fingerprint, 200*time.Millisecond)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal(armored))
+	g.Expect(attempts.Load()).To(BeEquivalentTo(2))
+}
+
+func TestDownloadGpgKeyCancelledContext(t *testing.T) {
+	g := NewWithT(t)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := downloadGpgKey(ctx, server.URL, ubuntuDockerGpgKeyFingerprint, time.Minute)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(attempts.Load()).To(BeEquivalentTo(1))
+}
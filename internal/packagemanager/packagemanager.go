@@ -20,6 +20,7 @@ import (
 
 const (
 	aptPackageManager  = "apt"
+	dnfPackageManager  = "dnf"
 	snapPackageManager = "snap"
 	yumPackageManager  = "yum"
 
@@ -46,7 +47,7 @@ const (
 	ssmPkgName      = "amazon-ssm-agent"
 )
 
-// DistroPackageManager defines a new package manager using apt or yum
+// DistroPackageManager defines a new package manager using apt, yum or dnf
 type DistroPackageManager struct {
 	manager             string
 	installVerb         string
@@ -54,10 +55,12 @@ type DistroPackageManager struct {
 	deleteVerb          string
 	refreshMetadataVerb string
 	dockerRepo          string
+	osInfo              system.OSInfo
 	logger              *zap.Logger
 }
 
-func New(containerdSource tracker.ContainerdSourceName, logger *zap.Logger) (*DistroPackageManager, error) {
+// New returns the package manager of the host. osInfo selects the docker repo release on Ubuntu.
+func New(containerdSource tracker.ContainerdSourceName, osInfo system.OSInfo, logger *zap.Logger) (*DistroPackageManager, error) {
 	manager, err := getOsPackageManager()
 	if err != nil {
 		return nil, err
@@ -65,6 +68,7 @@ func New(containerdSource tracker.ContainerdSourceName, logger *zap.Logger) (*Di
 
 	pm := &DistroPackageManager{
 		manager:             manager,
+		osInfo:              osInfo,
 		logger:              logger,
 		installVerb:         packageManagerInstallCmd[manager],
 		updateVerb:          packageManagerUpdateCmd[manager],
@@ -81,7 +85,7 @@ func New(containerdSource tracker.ContainerdSourceName, logger *zap.Logger) (*Di
 func (pm *DistroPackageManager) Configure(ctx context.Context) error {
 	// Add docker repos to the package manager
 	if pm.dockerRepo != "" {
-		if pm.manager == yumPackageManager {
+		if pm.manager == yumPackageManager || pm.manager == dnfPackageManager {
 			return pm.configureYumPackageManagerWithDockerRepo(ctx)
 		}
 		if pm.manager == aptPackageManager {
@@ -91,7 +95,7 @@ func (pm *DistroPackageManager) Configure(ctx context.Context) error {
 	return nil
 }
 
-// configureYumPackageManagerWithDockerRepo configures yum package manager with docker repos
+// configureYumPackageManagerWithDockerRepo configures yum or dnf package manager with docker repos
 func (pm *DistroPackageManager) configureYumPackageManagerWithDockerRepo(ctx context.Context) error {
 	// Check and remove runc if installed, as it conflicts with docker repo
 	if _, errNotFound := exec.LookPath(runcPkgName); errNotFound == nil {
@@ -132,8 +136,8 @@ func (pm *DistroPackageManager) configureAptPackageManagerWithDockerRepo(ctx con
 		return errors.Wrapf(err, "failed running commands to configure package manager")
 	}
 
-	// Download docker gpg key and write it to file
-	data, err := util.GetHttpFile(ctx, ubuntuDockerGpgKey)
+	// Download docker gpg key, verify it's docker's release key and write it to file
+	data, err := downloadGpgKey(ctx, ubuntuDockerGpgKey, ubuntuDockerGpgKeyFingerprint, gpgKeyAttemptTimeout)
 	if err != nil {
 		return errors.Wrapf(err, "downloading docker gpg key")
 	}
@@ -142,7 +146,7 @@ func (pm *DistroPackageManager) configureAptPackageManagerWithDockerRepo(ctx con
 		return err
 	}
 
-	aptDockerRepoConfig := fmt.Sprintf("deb [arch=%s signed-by=%s] %s %s stable\n", runtime.GOARCH, ubuntuDockerGpgKeyPath, ubuntuDockerRepo, system.GetVersionCodeName())
+	aptDockerRepoConfig := fmt.Sprintf("deb [arch=%s signed-by=%s] %s %s stable\n", runtime.GOARCH, ubuntuDockerGpgKeyPath, ubuntuDockerRepo, pm.osInfo.VersionCodename)
 	// Add docker repo config for ubuntu-apt to apt sources
 	if err := util.WriteFileWithDir(aptDockerRepoSourceFilePath, []byte(aptDockerRepoConfig), ubuntuDockerGpgKeyFilePerms); err != nil {
 		return err
@@ -179,8 +183,8 @@ func (pm *DistroPackageManager) uninstallDockerRepo() error {
 	}
 
 	switch pm.manager {
-	case yumPackageManager:
-		return removeRepoFile(yumDockerRepoSourceFilePath, yumPackageManager)
+	case yumPackageManager, dnfPackageManager:
+		return removeRepoFile(yumDockerRepoSourceFilePath, pm.manager)
 	case aptPackageManager:
 		if err := os.Remove(ubuntuDockerGpgKeyPath); err != nil {
 			if !os.IsNotExist(err) {
@@ -199,7 +203,7 @@ func (pm *DistroPackageManager) appendPackageVersion(packageName, version string
 		return packageName
 	}
 	switch pm.manager {
-	case yumPackageManager:
+	case yumPackageManager, dnfPackageManager:
 		return fmt.Sprintf("%s-%s", packageName, version)
 	case aptPackageManager:
 		return fmt.Sprintf("%s=%s", packageName, version)
@@ -299,10 +303,20 @@ func (pm *DistroPackageManager) Cleanup() error {
 	return nil
 }
 
+// DetectPackageManager returns the OS package manager nodeadm uses on this host.
+func DetectPackageManager() (string, error) {
+	return getOsPackageManager()
+}
+
+// lookPath is exec.LookPath, overridden in tests.
+var lookPath = exec.LookPath
+
+// getOsPackageManager returns the first supported package manager found in $PATH.
+// yum is preferred over dnf, so hosts that ship both keep using yum.
 func getOsPackageManager() (string, error) {
-	supportedManagers := []string{yumPackageManager, aptPackageManager}
+	supportedManagers := []string{yumPackageManager, dnfPackageManager, aptPackageManager}
 	for _, manager := range supportedManagers {
-		if _, err := exec.LookPath(manager); err == nil {
+		if _, err := lookPath(manager); err == nil {
 			return manager, nil
 		}
 	}
@@ -311,25 +325,30 @@ func getOsPackageManager() (string, error) {
 
 var packageManagerInstallCmd = map[string]string{
 	aptPackageManager: "install",
+	dnfPackageManager: "install",
 	yumPackageManager: "install",
 }
 
 var packageManagerUpdateCmd = map[string]string{
 	aptPackageManager: "upgrade",
+	dnfPackageManager: "upgrade",
 	yumPackageManager: "update",
 }
 
 var packageManagerDeleteCmd = map[string]string{
 	aptPackageManager: "autoremove",
+	dnfPackageManager: "remove",
 	yumPackageManager: "remove",
 }
 
 var packageManagerMetadataRefreshCmd = map[string]string{
 	aptPackageManager: "update",
+	dnfPackageManager: "makecache",
 	yumPackageManager: "makecache",
 }
 
 var managerToDockerRepoMap = map[string]string{
 	yumPackageManager: "https://download.docker.com/linux/centos/docker-ce.repo",
+	dnfPackageManager: "https://download.docker.com/linux/centos/docker-ce.repo",
 	aptPackageManager: "https://download.docker.com/linux/ubuntu",
 }
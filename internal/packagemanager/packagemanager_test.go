@@ -0,0 +1,85 @@
+package packagemanager
+
+import (
+	"os/exec"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/system"
+	"github.com/aws/eks-hybrid/internal/tracker"
+)
+
+func stubLookPath(t *testing.T, found ...string) {
+	t.Helper()
+	original := lookPath
+	t.Cleanup(func() { lookPath = original })
+	lookPath = func(file string) (string, error) {
+		for _, f := range found {
+			if f == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+}
+
+func TestGetOsPackageManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		found   []string
+		want    string
+		wantErr bool
+	}{
+		{name: "yum", found: []string{yumPackageManager}, want: yumPackageManager},
+		{name: "dnf", found: []string{dnfPackageManager}, want: dnfPackageManager},
+		{name: "apt", found: []string{aptPackageManager}, want: aptPackageManager},
+		{name: "yum and dnf prefers yum", found: []string{dnfPackageManager, yumPackageManager}, want: yumPackageManager},
+		{name: "dnf and apt prefers dnf", found: []string{aptPackageManager, dnfPackageManager}, want: dnfPackageManager},
+		{name: "none", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			stubLookPath(t, tc.found...)
+
+			manager, err := getOsPackageManager()
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(manager).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestNewDnf(t *testing.T) {
+	g := NewWithT(t)
+	stubLookPath(t, dnfPackageManager)
+
+	pm, err := New(tracker.ContainerdSourceDocker, system.OSInfo{}, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pm.manager).To(Equal(dnfPackageManager))
+	g.Expect(pm.installVerb).To(Equal("install"))
+	g.Expect(pm.updateVerb).To(Equal("upgrade"))
+	g.Expect(pm.deleteVerb).To(Equal("remove"))
+	g.Expect(pm.refreshMetadataVerb).To(Equal("makecache"))
+	g.Expect(pm.dockerRepo).To(Equal(centOsDockerRepo))
+	g.Expect(pm.appendPackageVersion(containerdDockerPkgName, "1.7.0")).To(Equal("containerd.io-1.7.0"))
+}
+
+func TestVerbMapsCoverSupportedManagers(t *testing.T) {
+	g := NewWithT(t)
+	for _, manager := range []string{yumPackageManager, dnfPackageManager, aptPackageManager} {
+		for _, verbs := range []map[string]string{
+			packageManagerInstallCmd,
+			packageManagerUpdateCmd,
+			packageManagerDeleteCmd,
+			packageManagerMetadataRefreshCmd,
+			managerToDockerRepoMap,
+		} {
+			g.Expect(verbs).To(HaveKey(manager))
+		}
+	}
+}
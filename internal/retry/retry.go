@@ -87,7 +87,11 @@ func (r *Retrier) Do(ctx context.Context, op Operation) error {
 	// (context cancelled, timeout, backoff limit reached) then we wrap the last
 	// error to give a better indication of what happened to the caller.
 	if wait.Interrupted(err) && lastErr != nil {
-		return fmt.Errorf("%s while retrying: %w", err, lastErr)
+		// Without a done context, the loop was interrupted because it ran out of steps.
+		if ctx.Err() == nil {
+			return fmt.Errorf("giving up after %d attempts while retrying: %w", r.Backoff.Steps, lastErr)
+		}
+		return fmt.Errorf("%w while retrying: %w", err, lastErr)
 	}
 
 	return err
@@ -321,3 +321,62 @@ func TestRetrier_Do_OperationTimeout(t *testing.T) {
 		g.Expect(err).To(MatchError(ContainSubstring("context deadline exceeded")))
 	})
 }
+
+func TestRetrier_Do_AttemptsExhausted(t *testing.T) {
+	g := NewWithT(t)
+	op := &mockOperation{errToReturn: errTest}
+	r := retry.Retrier{
+		Backoff: retry.Backoff{Duration: 1 * time.Millisecond, Steps: 4},
+	}
+
+	err := r.Do(context.Background(), op.run)
+	g.Expect(err).To(MatchError(fmt.Sprintf("giving up after 4 attempts while retrying: %s", errTest)))
+	g.Expect(errors.Is(err, errTest)).To(BeTrue())
+	g.Expect(op.currentAttempt).To(Equal(4))
+}
+
+func TestRetrier_Do_ContextCancelledWhileRetrying(t *testing.T) {
+	g := NewWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	attempts := 0
+	r := retry.Retrier{
+		Backoff: retry.Backoff{Duration: 1 * time.Millisecond, Steps: 10},
+	}
+
+	err := r.Do(ctx, func(ctx context.Context) (bool, error) {
+		attempts++
+		if attempts == 2 {
+			cancel()
+		}
+		return false, errTest
+	})
+	g.Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+	g.Expect(errors.Is(err, errTest)).To(BeTrue())
+	g.Expect(attempts).To(Equal(2))
+}
+
+func TestRetrier_Do_Jitter(t *testing.T) {
+	g := NewWithT(t)
+	base := 10 * time.Millisecond
+	r := retry.Retrier{
+		Backoff: retry.Backoff{Duration: base, Factor: 1, Jitter: 1, Steps: 6},
+	}
+
+	var attempts []time.Time
+	_ = r.Do(context.Background(), func(ctx context.Context) (bool, error) {
+		attempts = append(attempts, time.Now())
+		return false, errTest
+	})
+
+	g.Expect(attempts).To(HaveLen(6))
+	jittered := false
+	for i := 1; i < len(attempts); i++ {
+		wait := attempts[i].Sub(attempts[i-1])
+		g.Expect(wait).To(BeNumerically(">=", base))
+		if wait > base+base/20 {
+			jittered = true
+		}
+	}
+	g.Expect(jittered).To(BeTrue(), "waits between attempts should be jittered above the base duration")
+}
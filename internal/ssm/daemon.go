@@ -118,14 +118,19 @@ func (s *ssm) Name() string {
 	return SsmDaemonName
 }
 
+// DaemonName returns the name of the SSM agent daemon for the host OS.
+func DaemonName() string {
+	setDaemonName()
+	return SsmDaemonName
+}
+
 func setDaemonName() {
 	osToDaemonName := map[string]string{
 		system.UbuntuOsName: "snap.amazon-ssm-agent.amazon-ssm-agent",
 		system.RhelOsName:   "amazon-ssm-agent",
 		system.AmazonOsName: "amazon-ssm-agent",
 	}
-	osName := system.GetOsName()
-	if daemonName, ok := osToDaemonName[osName]; ok {
+	if daemonName, ok := osToDaemonName[system.HostOSInfo().ID]; ok {
 		SsmDaemonName = daemonName
 	}
 }
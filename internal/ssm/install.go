@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,9 +19,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/daemon"
+	"github.com/aws/eks-hybrid/internal/retry"
 	"github.com/aws/eks-hybrid/internal/tracker"
 	"github.com/aws/eks-hybrid/internal/util"
 	"github.com/aws/eks-hybrid/internal/util/cmd"
+	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 const (
@@ -35,6 +39,19 @@ const (
 	gpgConfigFilePerms = 0o755
 
 	credentialRetryMaxSleepSeconds = 60
+
+	defaultDownloadAttempts     = 3
+	defaultInstallRetryInterval = 5 * time.Second
+	defaultAgentRunningTimeout  = 2 * time.Minute
+	agentStatusBackoff          = 5 * time.Second
+
+	// partFileSuffix is appended to the installer path while it's being downloaded.
+	partFileSuffix = ".part"
+
+	armoredPublicKeyEnd = "-----END PGP PUBLIC KEY BLOCK-----"
+
+	agentNotRunningRemediation = "ssm-setup-cli reported a successful install but the SSM agent service is not running. " +
+		"Check the agent logs with 'journalctl -u %[1]s', start it with 'systemctl start %[1]s' and retry the command."
 )
 
 // Source serves an SSM installer binary for the target platform.
@@ -55,6 +72,50 @@ type InstallOptions struct {
 	Logger      *zap.Logger
 	Region      string
 	InstallRoot string
+
+	// DownloadAttempts is how many times downloading and verifying the SSM installer
+	// is attempted before failing. Defaults to 3.
+	DownloadAttempts int
+
+	// InstallRetryInterval is the wait between attempts to run the SSM installer. Defaults to 5s.
+	InstallRetryInterval time.Duration
+
+	// DaemonManager is used to verify the SSM agent is running after the install.
+	// The check is skipped when nil.
+	DaemonManager daemon.DaemonManager
+
+	// AgentRunningTimeout is how long to wait for the SSM agent to be running after the install. Defaults to 2m.
+	AgentRunningTimeout time.Duration
+
+	// PinnedFingerprints are the OpenPGP fingerprints of the keys allowed to sign the installer,
+	// configured out of band. When set, or with PinnedKeyring, the public key of the source must
+	// match one of them, so a compromised source can't supply both the installer and its signing key.
+	PinnedFingerprints []string
+
+	// PinnedKeyring is the path to a file with the armored public keys allowed to sign the installer.
+	// Their fingerprints are pinned along with PinnedFingerprints.
+	PinnedKeyring string
+}
+
+func (o InstallOptions) downloadAttempts() int {
+	if o.DownloadAttempts <= 0 {
+		return defaultDownloadAttempts
+	}
+	return o.DownloadAttempts
+}
+
+func (o InstallOptions) installRetryInterval() time.Duration {
+	if o.InstallRetryInterval <= 0 {
+		return defaultInstallRetryInterval
+	}
+	return o.InstallRetryInterval
+}
+
+func (o InstallOptions) agentRunningTimeout() time.Duration {
+	if o.AgentRunningTimeout <= 0 {
+		return defaultAgentRunningTimeout
+	}
+	return o.AgentRunningTimeout
 }
 
 func Install(ctx context.Context, opts InstallOptions) error {
@@ -72,14 +133,23 @@ func installFromSource(ctx context.Context, opts InstallOptions) error {
 		return errors.Wrapf(err, "writing gpg config file")
 	}
 
-	if err := downloadFileWithRetries(ctx, opts.Source, opts.Logger, installerPath); err != nil {
+	publicKey, err := verificationKey(opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to install ssm installer")
+	}
+
+	if err := downloadFileWithRetries(ctx, opts.Source, publicKey, opts.Logger, installerPath, opts.downloadAttempts()); err != nil {
 		return errors.Wrap(err, "failed to install ssm installer")
 	}
 
-	if err := runInstallWithRetries(ctx, installerPath, opts.Region); err != nil {
+	if err := runInstallWithRetries(ctx, installerPath, opts.Region, opts.installRetryInterval()); err != nil {
 		return errors.Wrapf(err, "failed to install ssm agent")
 	}
 
+	if err := waitForAgentRunning(ctx, opts); err != nil {
+		return err
+	}
+
 	opts.Logger.Info("Configuring SSMAgent for hybrid node...")
 	if err := configureSSMAgent(opts.InstallRoot); err != nil {
 		return fmt.Errorf("failed to configure ssm agent: %w", err)
@@ -95,48 +165,191 @@ func Upgrade(ctx context.Context, opts InstallOptions) error {
 	return nil
 }
 
-func downloadFileWithRetries(ctx context.Context, source Source, logger *zap.Logger, installerPath string) error {
-	// Retry up to 3 times to download and validate the signature of
+func downloadFileWithRetries(ctx context.Context, source Source, publicKey string, logger *zap.Logger, installerPath string, attempts int) error {
+	// Retry up to attempts times to download and validate the signature of
 	// the SSM setup cli.
-	var err error
-	for range 3 {
-		err = downloadFileTo(ctx, source, installerPath)
-		if err == nil {
-			break
+	retrier := retry.Retrier{
+		Backoff: retry.Backoff{Steps: attempts},
+	}
+	attempt := 0
+	var lastErr error
+	err := retrier.Do(ctx, func(ctx context.Context) (bool, error) {
+		attempt++
+		if lastErr = downloadFileTo(ctx, source, publicKey, installerPath); lastErr != nil {
+			logger.Error("Downloading ssm-setup-cli failed. Retrying...", zap.Int("attempt", attempt), zap.Int("maxAttempts", attempts), zap.Error(lastErr))
+			return false, lastErr
 		}
-		logger.Error("Downloading ssm-setup-cli failed. Retrying...", zap.Error(err))
+		return true, nil
+	})
+	if err != nil {
+		_ = os.Remove(installerPath + partFileSuffix)
+	}
+	if err != nil && ctx.Err() == nil {
+		// All attempts failed, the last download error is more useful than the retry summary.
+		return lastErr
 	}
 	return err
 }
 
-// Update other functions that use InstallerPath to use the parameter instead
-func downloadFileTo(ctx context.Context, source Source, installerPath string) error {
-	installer, err := source.GetSSMInstaller(ctx)
+// waitForAgentRunning waits for the SSM agent daemon to be running, since ssm-setup-cli
+// can succeed without the agent coming up, which leaves the node unable to register.
+func waitForAgentRunning(ctx context.Context, opts InstallOptions) error {
+	if opts.DaemonManager == nil {
+		return nil
+	}
+	setDaemonName()
+	ctx, cancel := context.WithTimeout(ctx, opts.agentRunningTimeout())
+	defer cancel()
+
+	opts.Logger.Info("Waiting for SSM agent to be running...", zap.String("daemon", SsmDaemonName))
+	if err := daemon.WaitForStatus(ctx, opts.Logger, opts.DaemonManager, SsmDaemonName, daemon.DaemonStatusRunning, agentStatusBackoff); err != nil {
+		remediation := fmt.Sprintf(agentNotRunningRemediation, SsmDaemonName)
+		opts.Logger.Error("SSM agent is not running after install", zap.String("remediation", remediation))
+		return validation.WithRemediation(fmt.Errorf("ssm agent is not running after install: %w", err), remediation)
+	}
+	return nil
+}
+
+// downloadFileTo streams the installer to a .part file next to installerPath, verifies its
+// signature with publicKey and renames it into place, so a truncated download never ends up at
+// installerPath. A .part file left by a previous attempt is reused if its signature is valid.
+func downloadFileTo(ctx context.Context, source Source, publicKey, installerPath string) error {
+	signature, err := getSSMInstallerSignature(ctx, source)
 	if err != nil {
-		return fmt.Errorf("getting ssm-setup-cli: %w", err)
+		return err
+	}
+
+	partPath := installerPath + partFileSuffix
+	if err := verifyInstallerFile(partPath, signature, publicKey); err == nil {
+		return installPartFile(partPath, installerPath)
+	}
+
+	if err := downloadPartFile(ctx, source, partPath); err != nil {
+		_ = os.Remove(partPath)
+		return err
+	}
+	if err := verifyInstallerFile(partPath, signature, publicKey); err != nil {
+		_ = os.Remove(partPath)
+		return fmt.Errorf("validating ssm-setup-cli signature: %w", err)
 	}
-	defer installer.Close()
 
+	// The .part file is kept if the rename fails, the next attempt reuses it without downloading again.
+	return installPartFile(partPath, installerPath)
+}
+
+func getSSMInstallerSignature(ctx context.Context, source Source) ([]byte, error) {
 	signature, err := source.GetSSMInstallerSignature(ctx)
 	if err != nil {
-		return fmt.Errorf("getting ssm-setup-cli signature: %w", err)
+		return nil, fmt.Errorf("getting ssm-setup-cli signature: %w", err)
 	}
 	defer signature.Close()
 
-	var installerBuffer bytes.Buffer
-	installerTee := io.TeeReader(installer, &installerBuffer)
+	data, err := io.ReadAll(signature)
+	if err != nil {
+		return nil, fmt.Errorf("reading ssm-setup-cli signature: %w", err)
+	}
+	return data, nil
+}
 
-	if err := validateSetupSignature(installerTee, signature, source.PublicKey()); err != nil {
-		return fmt.Errorf("validating ssm-setup-cli signature: %w", err)
+func downloadPartFile(ctx context.Context, source Source, partPath string) error {
+	installer, err := source.GetSSMInstaller(ctx)
+	if err != nil {
+		return fmt.Errorf("getting ssm-setup-cli: %w", err)
 	}
+	defer installer.Close()
 
-	if err := artifact.InstallFile(installerPath, bytes.NewReader(installerBuffer.Bytes()), 0o755); err != nil {
-		return fmt.Errorf("installing ssm-setup-cli: %w", err)
+	if err := os.MkdirAll(filepath.Dir(partPath), artifact.DefaultDirPerms); err != nil {
+		return fmt.Errorf("creating ssm-setup-cli directory: %w", err)
 	}
+	part, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", partPath, err)
+	}
+	if _, err := io.Copy(part, installer); err != nil {
+		part.Close()
+		return fmt.Errorf("downloading ssm-setup-cli: %w", err)
+	}
+	if err := part.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", partPath, err)
+	}
+	return nil
+}
+
+func verifyInstallerFile(path string, signature []byte, publicKey string) error {
+	installer, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer installer.Close()
+	return validateSetupSignature(installer, bytes.NewReader(signature), publicKey)
+}
 
+func installPartFile(partPath, installerPath string) error {
+	if err := os.Rename(partPath, installerPath); err != nil {
+		return fmt.Errorf("installing ssm-setup-cli: %w", err)
+	}
 	return nil
 }
 
+// verificationKey returns the public key of the source to verify the installer with, once it
+// matches the pinned fingerprints, if any.
+func verificationKey(opts InstallOptions) (string, error) {
+	publicKey := opts.Source.PublicKey()
+	pinned := slices.Clone(opts.PinnedFingerprints)
+	if opts.PinnedKeyring != "" {
+		keyringFingerprints, err := keyringFingerprints(opts.PinnedKeyring)
+		if err != nil {
+			return "", err
+		}
+		pinned = append(pinned, keyringFingerprints...)
+	}
+	if len(pinned) == 0 {
+		return publicKey, nil
+	}
+
+	key, err := crypto.NewKeyFromArmored(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("reading ssm-setup-cli public key: %w", err)
+	}
+	fingerprint := normalizeFingerprint(key.GetFingerprint())
+	for _, pin := range pinned {
+		if normalizeFingerprint(pin) == fingerprint {
+			return publicKey, nil
+		}
+	}
+	return "", fmt.Errorf("ssm-setup-cli public key fingerprint %s doesn't match any of the pinned fingerprints", fingerprint)
+}
+
+// keyringFingerprints returns the fingerprints of the armored public keys in the keyring file.
+func keyringFingerprints(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading pinned keyring: %w", err)
+	}
+	var fingerprints []string
+	blocks := strings.SplitAfter(string(data), armoredPublicKeyEnd)
+	for _, block := range blocks {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		key, err := crypto.NewKeyFromArmored(block)
+		if err != nil {
+			return nil, fmt.Errorf("reading pinned keyring %s: %w", path, err)
+		}
+		fingerprints = append(fingerprints, key.GetFingerprint())
+	}
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("pinned keyring %s has no public keys", path)
+	}
+	return fingerprints, nil
+}
+
+// normalizeFingerprint lowercases fingerprint and strips the separators gpg prints it with.
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(fingerprint)), "0x")
+	return strings.NewReplacer(" ", "", ":", "").Replace(fingerprint)
+}
+
 func validateSetupSignature(installer, signature io.Reader, publicKey string) error {
 	verificationKey, err := crypto.NewKeyFromArmored(publicKey)
 	if err != nil {
@@ -237,14 +450,14 @@ func uninstallPreRegisterComponents(ctx context.Context, pkgSource PkgSource) er
 	return os.RemoveAll(defaultInstallerPath)
 }
 
-func runInstallWithRetries(ctx context.Context, installerPath, region string) error {
+func runInstallWithRetries(ctx context.Context, installerPath, region string, retryInterval time.Duration) error {
 	// Sometimes install fails due to conflicts with other processes
 	// updating packages, specially when automating at machine startup.
 	// We assume errors are transient and just retry for a bit.
 	installCmdBuilder := func(ctx context.Context) *exec.Cmd {
 		return exec.CommandContext(ctx, installerPath, "-install", "-region", region, "-version", "latest")
 	}
-	return cmd.Retry(ctx, installCmdBuilder, 5*time.Second)
+	return cmd.Retry(ctx, installCmdBuilder, retryInterval)
 }
 
 func configureSSMAgent(installRoot string) error {
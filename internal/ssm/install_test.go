@@ -1,13 +1,18 @@
 package ssm_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -18,9 +23,11 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/daemon"
 	"github.com/aws/eks-hybrid/internal/ssm"
 	"github.com/aws/eks-hybrid/internal/test"
 	"github.com/aws/eks-hybrid/internal/tracker"
+	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 func generateKeyPair(t *testing.T) (string, *crypto.Key) {
@@ -205,6 +212,374 @@ func TestInstall(t *testing.T) {
 	}
 }
 
+// flakySource fails to serve the installer the first failures times.
+type flakySource struct {
+	installer []byte
+	signature []byte
+	publicKey string
+	failures  int
+	attempts  int
+	onFailure func()
+}
+
+func (s *flakySource) GetSSMInstaller(_ context.Context) (io.ReadCloser, error) {
+	s.attempts++
+	if s.attempts <= s.failures {
+		if s.onFailure != nil {
+			s.onFailure()
+		}
+		return nil, errors.New("connection reset by peer")
+	}
+	return io.NopCloser(bytes.NewReader(s.installer)), nil
+}
+
+func (s *flakySource) GetSSMInstallerSignature(_ context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.signature)), nil
+}
+
+func (s *flakySource) PublicKey() string {
+	return s.publicKey
+}
+
+func TestInstallDownloadAttempts(t *testing.T) {
+	publicKey, privateKey := generateKeyPair(t)
+	installerData := []byte("#!/bin/echo\n")
+	signature := generateSignature(t, privateKey, installerData)
+
+	tests := []struct {
+		name             string
+		downloadAttempts int
+		failures         int
+		wantAttempts     int
+		wantErr          string
+	}{
+		{
+			name:         "default attempts",
+			failures:     2,
+			wantAttempts: 3,
+		},
+		{
+			name:             "configured attempts succeed on the last one",
+			downloadAttempts: 5,
+			failures:         4,
+			wantAttempts:     5,
+		},
+		{
+			name:             "configured attempts exhausted",
+			downloadAttempts: 4,
+			failures:         10,
+			wantAttempts:     4,
+			wantErr:          "failed to install ssm installer: getting ssm-setup-cli: connection reset by peer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tmpDir := t.TempDir()
+			source := &flakySource{
+				installer: installerData,
+				signature: signature,
+				publicKey: publicKey,
+				failures:  tt.failures,
+			}
+
+			err := ssm.Install(context.Background(), ssm.InstallOptions{
+				Tracker:              &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+				Source:               source,
+				Logger:               zap.NewNop(),
+				InstallRoot:          tmpDir,
+				DownloadAttempts:     tt.downloadAttempts,
+				InstallRetryInterval: time.Millisecond,
+			})
+
+			g.Expect(source.attempts).To(Equal(tt.wantAttempts))
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(tt.wantErr))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(filepath.Join(tmpDir, "opt/ssm/ssm-setup-cli")).To(BeAnExistingFile())
+		})
+	}
+}
+
+// partialSource serves a truncated installer the first partialReads times.
+type partialSource struct {
+	flakySource
+	partialReads int
+	// readErr is returned after the truncated installer, nil simulates a connection closed cleanly.
+	readErr error
+}
+
+func (s *partialSource) GetSSMInstaller(ctx context.Context) (io.ReadCloser, error) {
+	if s.attempts < s.partialReads {
+		s.attempts++
+		truncated := bytes.NewReader(s.installer[:len(s.installer)/2])
+		if s.readErr == nil {
+			return io.NopCloser(truncated), nil
+		}
+		return io.NopCloser(io.MultiReader(truncated, failingReader{err: s.readErr})), nil
+	}
+	return s.flakySource.GetSSMInstaller(ctx)
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestInstallDownloadResumesAfterPartialDownload(t *testing.T) {
+	publicKey, privateKey := generateKeyPair(t)
+	installerData := []byte("#!/bin/echo\n")
+	signature := generateSignature(t, privateKey, installerData)
+
+	tests := []struct {
+		name    string
+		readErr error
+	}{
+		{
+			name:    "connection reset mid download",
+			readErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "truncated download fails the signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tmpDir := t.TempDir()
+			source := &partialSource{
+				flakySource:  flakySource{installer: installerData, signature: signature, publicKey: publicKey},
+				partialReads: 1,
+				readErr:      tt.readErr,
+			}
+
+			err := ssm.Install(context.Background(), ssm.InstallOptions{
+				Tracker:              &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+				Source:               source,
+				Logger:               zap.NewNop(),
+				InstallRoot:          tmpDir,
+				InstallRetryInterval: time.Millisecond,
+			})
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(source.attempts).To(Equal(2))
+			installerPath := filepath.Join(tmpDir, "opt/ssm/ssm-setup-cli")
+			g.Expect(os.ReadFile(installerPath)).To(Equal(installerData))
+			g.Expect(installerPath + ".part").NotTo(BeAnExistingFile())
+		})
+	}
+}
+
+func TestInstallDownloadReusesValidatedPartFile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	publicKey, privateKey := generateKeyPair(t)
+	installerData := []byte("#!/bin/echo\n")
+	tmpDir := t.TempDir()
+	installerPath := filepath.Join(tmpDir, "opt/ssm/ssm-setup-cli")
+	g.Expect(os.MkdirAll(filepath.Dir(installerPath), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(installerPath+".part", installerData, 0o755)).To(Succeed())
+	// The installer can't be downloaded, the .part file left by a previous attempt has to be used.
+	source := &flakySource{signature: generateSignature(t, privateKey, installerData), publicKey: publicKey, failures: 10}
+
+	err := ssm.Install(context.Background(), ssm.InstallOptions{
+		Tracker:              &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+		Source:               source,
+		Logger:               zap.NewNop(),
+		InstallRoot:          tmpDir,
+		InstallRetryInterval: time.Millisecond,
+	})
+
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(source.attempts).To(BeZero())
+	g.Expect(os.ReadFile(installerPath)).To(Equal(installerData))
+	g.Expect(installerPath + ".part").NotTo(BeAnExistingFile())
+}
+
+func TestInstallDownloadCleansUpPartFileOnFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+	publicKey, privateKey := generateKeyPair(t)
+	installerData := []byte("#!/bin/echo\n")
+	tmpDir := t.TempDir()
+	installerPath := filepath.Join(tmpDir, "opt/ssm/ssm-setup-cli")
+	g.Expect(os.MkdirAll(filepath.Dir(installerPath), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(installerPath+".part", []byte("stale"), 0o755)).To(Succeed())
+	source := &partialSource{
+		flakySource:  flakySource{installer: installerData, signature: generateSignature(t, privateKey, installerData), publicKey: publicKey},
+		partialReads: 3,
+		readErr:      io.ErrUnexpectedEOF,
+	}
+
+	err := ssm.Install(context.Background(), ssm.InstallOptions{
+		Tracker:     &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+		Source:      source,
+		Logger:      zap.NewNop(),
+		InstallRoot: tmpDir,
+	})
+
+	g.Expect(err).To(MatchError(ContainSubstring("downloading ssm-setup-cli: unexpected EOF")))
+	g.Expect(source.attempts).To(Equal(3))
+	g.Expect(installerPath).NotTo(BeAnExistingFile())
+	g.Expect(installerPath + ".part").NotTo(BeAnExistingFile())
+}
+
+func TestInstallDownloadStopsWhenContextCancelled(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &flakySource{failures: 10, onFailure: cancel}
+
+	err := ssm.Install(ctx, ssm.InstallOptions{
+		Tracker:          &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+		Source:           source,
+		Logger:           zap.NewNop(),
+		InstallRoot:      t.TempDir(),
+		DownloadAttempts: 5,
+	})
+
+	g.Expect(err).To(MatchError(context.Canceled))
+	g.Expect(source.attempts).To(Equal(1))
+}
+
+func TestInstallPinnedSigningKey(t *testing.T) {
+	publicKey, privateKey := generateKeyPair(t)
+	otherPublicKey, otherPrivateKey := generateKeyPair(t)
+	installerData := []byte("#!/bin/echo\n")
+	signature := generateSignature(t, privateKey, installerData)
+	fingerprint := strings.ToUpper(privateKey.GetFingerprint())
+
+	tests := []struct {
+		name               string
+		pinnedFingerprints []string
+		pinnedKeyring      []string
+		wantErr            string
+	}{
+		{
+			name:               "matching fingerprint",
+			pinnedFingerprints: []string{otherPrivateKey.GetFingerprint(), fingerprint},
+		},
+		{
+			name:               "matching fingerprint with separators",
+			pinnedFingerprints: []string{"0x" + strings.Join(splitEvery(fingerprint, 4), " ")},
+		},
+		{
+			name:          "source key in keyring",
+			pinnedKeyring: []string{otherPublicKey, publicKey},
+		},
+		{
+			name:               "mismatched fingerprint",
+			pinnedFingerprints: []string{otherPrivateKey.GetFingerprint()},
+			wantErr:            "doesn't match any of the pinned fingerprints",
+		},
+		{
+			name:          "source key not in keyring",
+			pinnedKeyring: []string{otherPublicKey},
+			wantErr:       "doesn't match any of the pinned fingerprints",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tmpDir := t.TempDir()
+			source := &flakySource{installer: installerData, signature: signature, publicKey: publicKey}
+			var keyringPath string
+			if len(tt.pinnedKeyring) > 0 {
+				keyringPath = filepath.Join(tmpDir, "keyring.asc")
+				g.Expect(os.WriteFile(keyringPath, []byte(strings.Join(tt.pinnedKeyring, "\n")), 0o644)).To(Succeed())
+			}
+
+			err := ssm.Install(context.Background(), ssm.InstallOptions{
+				Tracker:            &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}},
+				Source:             source,
+				Logger:             zap.NewNop(),
+				InstallRoot:        tmpDir,
+				PinnedFingerprints: tt.pinnedFingerprints,
+				PinnedKeyring:      keyringPath,
+			})
+
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				g.Expect(source.attempts).To(BeZero(), "the installer shouldn't be downloaded with an unpinned key")
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(source.attempts).To(Equal(1))
+		})
+	}
+}
+
+func splitEvery(s string, n int) []string {
+	var parts []string
+	for len(s) > n {
+		parts = append(parts, s[:n])
+		s = s[n:]
+	}
+	return append(parts, s)
+}
+
+// fakeDaemonManager reports the same status for every daemon.
+type fakeDaemonManager struct {
+	daemon.DaemonManager
+	status daemon.DaemonStatus
+}
+
+func (f *fakeDaemonManager) GetDaemonStatus(string) (daemon.DaemonStatus, error) {
+	return f.status, nil
+}
+
+func TestInstallVerifiesAgentRunning(t *testing.T) {
+	publicKey, privateKey := generateKeyPair(t)
+	installerData := []byte("#!/bin/echo\n")
+	signature := generateSignature(t, privateKey, installerData)
+
+	tests := []struct {
+		name    string
+		status  daemon.DaemonStatus
+		wantErr string
+	}{
+		{
+			name:   "agent running",
+			status: daemon.DaemonStatusRunning,
+		},
+		{
+			name:    "agent never comes up",
+			status:  daemon.DaemonStatusUnknown,
+			wantErr: "ssm agent is not running after install",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			tr := &tracker.Tracker{Artifacts: &tracker.InstalledArtifacts{}}
+
+			err := ssm.Install(context.Background(), ssm.InstallOptions{
+				Tracker:             tr,
+				Source:              &flakySource{installer: installerData, signature: signature, publicKey: publicKey},
+				Logger:              zap.NewNop(),
+				InstallRoot:         t.TempDir(),
+				DaemonManager:       &fakeDaemonManager{status: tt.status},
+				AgentRunningTimeout: 50 * time.Millisecond,
+			})
+
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				g.Expect(validation.Remediation(err)).To(ContainSubstring("systemctl start"))
+				g.Expect(tr.Artifacts.Ssm).To(BeFalse())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(tr.Artifacts.Ssm).To(BeTrue())
+		})
+	}
+}
+
 type MockSSMClient struct {
 	g                                 *GomegaWithT
 	instanceId                        string
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 
 	"go.uber.org/zap"
@@ -18,6 +20,9 @@ import (
 // down the agent from the proper region configured in the nodeConfig during init command
 const DefaultSsmInstallerRegion = "us-west-2"
 
+// ssmInstallerName is the file name of the SSM installer.
+const ssmInstallerName = "ssm-setup-cli"
+
 // The following public key expires on 2026-07-15 (July 15, 2026). Systems Manager will
 // publish a new key before the old one expires, we should migrate to that key at that time.
 // See https://docs.aws.amazon.com/systems-manager/latest/userguide/verify-agent-signature.html#verify-agent-signature-current
@@ -87,6 +92,14 @@ func WithDnsSuffix(dnsSuffix string) SSMInstallerOption {
 	}
 }
 
+// WithInstallerDir reads the SSM installer and its signature from dir, as ssm-setup-cli and
+// ssm-setup-cli.sig, instead of downloading them. The installer is downloaded when dir is empty.
+func WithInstallerDir(dir string) SSMInstallerOption {
+	return func(s *ssmInstallerSource) {
+		s.installerDir = dir
+	}
+}
+
 // SSMInstaller provides a Source that retrieves the SSM installer from the official
 // release endpoint.
 func NewSSMInstaller(logger *zap.Logger, region string, opts ...SSMInstallerOption) Source {
@@ -112,9 +125,16 @@ type ssmInstallerSource struct {
 	logger      *zap.Logger
 	buildSSMURL func() (string, error)
 	publicKey   string
+	// installerDir is a local directory the installer is read from, when set.
+	installerDir string
 }
 
 func (s ssmInstallerSource) GetSSMInstaller(ctx context.Context) (io.ReadCloser, error) {
+	if s.installerDir != "" {
+		path := filepath.Join(s.installerDir, ssmInstallerName)
+		s.logger.Info("Reading SSM installer", zap.String("path", path))
+		return os.Open(path)
+	}
 	endpoint, err := s.buildSSMURL()
 	if err != nil {
 		return nil, err
@@ -130,6 +150,9 @@ func (s ssmInstallerSource) GetSSMInstaller(ctx context.Context) (io.ReadCloser,
 }
 
 func (s ssmInstallerSource) GetSSMInstallerSignature(ctx context.Context) (io.ReadCloser, error) {
+	if s.installerDir != "" {
+		return os.Open(filepath.Join(s.installerDir, ssmInstallerName+".sig"))
+	}
 	endpoint, err := s.buildSSMURL()
 	if err != nil {
 		return nil, err
@@ -159,7 +182,7 @@ func (s ssmInstallerSource) defaultBuildSSMURL() (string, error) {
 	}
 
 	platform := fmt.Sprintf("%s_%s", variant, runtime.GOARCH)
-	return fmt.Sprintf("https://amazon-ssm-%s.s3.%s.%s/latest/%s/ssm-setup-cli", s.region, s.region, dnsSuffix, platform), nil
+	return fmt.Sprintf("https://amazon-ssm-%s.s3.%s.%s/latest/%s/%s", s.region, s.region, dnsSuffix, platform, ssmInstallerName), nil
 }
 
 // detectPlatformVariant returns a portion of the SSM installers URL that is dependent on the
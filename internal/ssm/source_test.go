@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -183,3 +185,38 @@ func TestGetSSMInstallerSignature(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSSMInstallerFromDir(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, "ssm-setup-cli"), []byte("test installer data"), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(filepath.Join(dir, "ssm-setup-cli.sig"), []byte("test signature data"), 0o644)).To(Succeed())
+
+	source := ssm.NewSSMInstaller(zap.NewNop(), "test-region",
+		ssm.WithURLBuilder(func() (string, error) {
+			return "", errors.New("the installer must not be downloaded")
+		}),
+		ssm.WithInstallerDir(dir),
+	)
+
+	installer, err := source.GetSSMInstaller(context.Background())
+	g.Expect(err).NotTo(HaveOccurred())
+	defer installer.Close()
+	g.Expect(io.ReadAll(installer)).To(BeEquivalentTo("test installer data"))
+
+	signature, err := source.GetSSMInstallerSignature(context.Background())
+	g.Expect(err).NotTo(HaveOccurred())
+	defer signature.Close()
+	g.Expect(io.ReadAll(signature)).To(BeEquivalentTo("test signature data"))
+}
+
+func TestGetSSMInstallerFromDirMissingSignature(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := t.TempDir()
+	g.Expect(os.WriteFile(filepath.Join(dir, "ssm-setup-cli"), []byte("test installer data"), 0o755)).To(Succeed())
+
+	source := ssm.NewSSMInstaller(zap.NewNop(), "test-region", ssm.WithInstallerDir(dir))
+
+	_, err := source.GetSSMInstallerSignature(context.Background())
+	g.Expect(err).To(MatchError(os.ErrNotExist))
+}
@@ -0,0 +1,85 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/aws"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	// DefaultClockSkewTolerance is the maximum clock skew allowed by AWS SigV4.
+	DefaultClockSkewTolerance = 5 * time.Minute
+	awsServerTimeTimeout      = 10 * time.Second
+	clockSkewValidation       = "clock-skew"
+)
+
+// RunClockSkew validates the node's clock against the time reported by AWS, independently of
+// the NTP daemon status: a daemon synchronized to a bad source still reports being in sync.
+func (v *NTPValidator) RunClockSkew(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, clockSkewValidation, "Validating node clock skew against AWS time")
+	defer func() {
+		informer.Done(ctx, clockSkewValidation, err)
+	}()
+	if err = v.ValidateAWSClockSkew(ctx, node.Spec.Cluster.Region); err != nil {
+		err = addNTPRemediation(err)
+		return err
+	}
+	return nil
+}
+
+// ValidateAWSClockSkew compares the node's clock against the time reported by AWS
+// and fails if the skew is beyond the tolerance, by default what SigV4 signed requests tolerate.
+// If AWS can't be reached the skew can't be measured and no error is returned,
+// network access is verified by other validations.
+func (v *NTPValidator) ValidateAWSClockSkew(ctx context.Context, region string) error {
+	before := v.now()
+	serverTime, err := v.awsServerTime(ctx, region)
+	if err != nil {
+		return nil
+	}
+	after := v.now()
+	// Compare against the middle of the request to discount the round trip.
+	local := before.Add(after.Sub(before) / 2)
+
+	skew := local.Sub(serverTime)
+	if skew.Abs() > v.clockSkewTolerance {
+		direction := "ahead of"
+		if skew < 0 {
+			direction = "behind"
+		}
+		return &ClockSkewError{baseError{
+			message: fmt.Sprintf("node clock is %s %s AWS time, more than the allowed %s", skew.Abs().Round(time.Second), direction, v.clockSkewTolerance),
+		}}
+	}
+	return nil
+}
+
+// awsServerTime reads the Date header from a request to the regional STS endpoint.
+// Any response, including errors, carries the server time.
+func awsServerTime(ctx context.Context, region string) (time.Time, error) {
+	partition := aws.GetPartitionFromRegionFallback(region)
+	endpoint := "https://" + aws.GetServiceEndpointForPartition("sts", region, partition)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	client := &http.Client{Timeout: awsServerTimeTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading time from %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return time.Time{}, fmt.Errorf("response from %s has no Date header", endpoint)
+	}
+	return http.ParseTime(date)
+}
@@ -0,0 +1,179 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestNTPValidator_ValidateAWSClockSkew(t *testing.T) {
+	serverTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		localTime     time.Time
+		serverErr     error
+		errorContains string
+	}{
+		{
+			name:      "in sync",
+			localTime: serverTime,
+		},
+		{
+			name:      "ahead within tolerance",
+			localTime: serverTime.Add(4 * time.Minute),
+		},
+		{
+			name:      "behind within tolerance",
+			localTime: serverTime.Add(-4*time.Minute - 59*time.Second),
+		},
+		{
+			name:          "ahead beyond tolerance",
+			localTime:     serverTime.Add(10 * time.Minute),
+			errorContains: "node clock is 10m0s ahead of AWS time, more than the allowed 5m0s",
+		},
+		{
+			name:          "behind beyond tolerance",
+			localTime:     serverTime.Add(-2 * time.Hour),
+			errorContains: "node clock is 2h0m0s behind AWS time",
+		},
+		{
+			name:      "aws unreachable",
+			localTime: serverTime.Add(time.Hour),
+			serverErr: errors.New("dial tcp: i/o timeout"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var region string
+			validator := NewNTPValidator(
+				WithClock(func() time.Time { return tt.localTime }),
+				WithAWSServerTime(func(_ context.Context, r string) (time.Time, error) {
+					region = r
+					return serverTime, tt.serverErr
+				}),
+			)
+
+			err := validator.ValidateAWSClockSkew(context.Background(), "us-west-2")
+
+			assert.Equal(t, "us-west-2", region)
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errorContains)
+			var skewErr *ClockSkewError
+			assert.ErrorAs(t, err, &skewErr)
+		})
+	}
+}
+
+func TestNTPValidator_ValidateAWSClockSkewDiscountsRoundTrip(t *testing.T) {
+	serverTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// A slow response shouldn't be counted as skew: the server time is
+	// compared against the middle of the request.
+	clock := []time.Time{serverTime.Add(-4 * time.Minute), serverTime.Add(4 * time.Minute)}
+	validator := NewNTPValidator(
+		WithClock(func() time.Time {
+			now := clock[0]
+			clock = clock[1:]
+			return now
+		}),
+		WithAWSServerTime(func(context.Context, string) (time.Time, error) {
+			return serverTime, nil
+		}),
+	)
+
+	assert.NoError(t, validator.ValidateAWSClockSkew(context.Background(), "us-west-2"))
+}
+
+func TestAddNTPRemediation_ClockSkew(t *testing.T) {
+	serverTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validator := NewNTPValidator(
+		WithClock(func() time.Time { return serverTime.Add(time.Hour) }),
+		WithAWSServerTime(func(context.Context, string) (time.Time, error) {
+			return serverTime, nil
+		}),
+	)
+
+	err := addNTPRemediation(validator.ValidateAWSClockSkew(context.Background(), "us-west-2"))
+
+	assert.ErrorContains(t, err, "ahead of AWS time")
+	assert.Contains(t, validation.Remediation(err), "RequestTimeTooSkewed")
+}
+
+func TestNTPValidator_ValidateAWSClockSkewTolerance(t *testing.T) {
+	serverTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		tolerance     time.Duration
+		skew          time.Duration
+		errorContains string
+	}{
+		{
+			name:          "narrower tolerance",
+			tolerance:     30 * time.Second,
+			skew:          time.Minute,
+			errorContains: "node clock is 1m0s ahead of AWS time, more than the allowed 30s",
+		},
+		{
+			name:      "wider tolerance",
+			tolerance: 15 * time.Minute,
+			skew:      -10 * time.Minute,
+		},
+		{
+			name:          "zero keeps the default",
+			skew:          6 * time.Minute,
+			errorContains: "more than the allowed 5m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewNTPValidator(
+				WithClockSkewTolerance(tt.tolerance),
+				WithClock(func() time.Time { return serverTime.Add(tt.skew) }),
+				WithAWSServerTime(func(context.Context, string) (time.Time, error) {
+					return serverTime, nil
+				}),
+			)
+
+			err := validator.ValidateAWSClockSkew(context.Background(), "us-west-2")
+
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errorContains)
+		})
+	}
+}
+
+func TestNTPValidator_RunClockSkew(t *testing.T) {
+	serverTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validator := NewNTPValidator(
+		WithClock(func() time.Time { return serverTime.Add(-3 * time.Hour) }),
+		WithAWSServerTime(func(context.Context, string) (time.Time, error) {
+			return serverTime, nil
+		}),
+	)
+	informer := &mockInformer{}
+	node := &api.NodeConfig{}
+	node.Spec.Cluster.Region = "us-west-2"
+
+	err := validator.RunClockSkew(context.Background(), informer, node)
+
+	assert.ErrorContains(t, err, "node clock is 3h0m0s behind AWS time")
+	assert.Contains(t, validation.Remediation(err), "RequestTimeTooSkewed")
+	assert.True(t, informer.startingCalled, "Starting should be called")
+	assert.True(t, informer.doneCalled, "Done should be called")
+	assert.Equal(t, err, informer.lastError)
+}
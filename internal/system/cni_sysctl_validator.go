@@ -0,0 +1,190 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/util"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	cniSysctlConfFile = "98-nodeadm-cni.conf"
+	procSysDir        = "/proc/sys"
+)
+
+var cniSysctlConfPath = path.Join(sysctlConfDir, cniSysctlConfFile)
+
+// cniSysctl is a kernel parameter a CNI needs set to one of the allowed values to work.
+type cniSysctl struct {
+	key     string
+	allowed []string
+	// fix is the value applied when the current value is not allowed.
+	fix string
+}
+
+var ipForwardSysctl = cniSysctl{key: "net.ipv4.ip_forward", allowed: []string{"1"}, fix: "1"}
+
+// cniRequiredSysctls maps the detected CNI name to the sysctls it needs.
+// CNIs not in the map only need IP forwarding.
+var cniRequiredSysctls = map[string][]cniSysctl{
+	// Cilium does its own source validation and strict reverse path filtering drops its traffic.
+	"cilium": {
+		ipForwardSysctl,
+		{key: "net.ipv4.conf.all.rp_filter", allowed: []string{"0"}, fix: "0"},
+		{key: "net.ipv4.conf.default.rp_filter", allowed: []string{"0"}, fix: "0"},
+	},
+	// Felix refuses to start with loose reverse path filtering since it allows workloads to spoof IPs.
+	"calico": {
+		ipForwardSysctl,
+		{key: "net.ipv4.conf.all.rp_filter", allowed: []string{"0", "1"}, fix: "1"},
+	},
+	// Flannel bridges pod traffic, which bypasses iptables unless bridged traffic is sent through it.
+	"flannel": {
+		ipForwardSysctl,
+		{key: "net.bridge.bridge-nf-call-iptables", allowed: []string{"1"}, fix: "1"},
+	},
+}
+
+// CNISysctlValidator validates the sysctls required by the CNI running on the node.
+type CNISysctlValidator struct {
+	detectCNI  func() (string, error)
+	readSysctl func(key string) (string, error)
+	fix        bool
+	applyFix   func(sysctls map[string]string) error
+}
+
+// CNISysctlValidatorOpt configures a CNISysctlValidator.
+type CNISysctlValidatorOpt func(*CNISysctlValidator)
+
+// WithSysctlReader overrides how the current value of a sysctl is read.
+func WithSysctlReader(read func(key string) (string, error)) CNISysctlValidatorOpt {
+	return func(v *CNISysctlValidator) {
+		v.readSysctl = read
+	}
+}
+
+// WithSysctlFix makes the validator apply and persist the required sysctls when any doesn't have an allowed value.
+func WithSysctlFix(fix bool) CNISysctlValidatorOpt {
+	return func(v *CNISysctlValidator) {
+		v.fix = fix
+	}
+}
+
+// WithSysctlApplier overrides how the fixed sysctls are persisted and applied.
+func WithSysctlApplier(apply func(sysctls map[string]string) error) CNISysctlValidatorOpt {
+	return func(v *CNISysctlValidator) {
+		v.applyFix = apply
+	}
+}
+
+// NewCNISysctlValidator creates a new CNISysctlValidator that checks the sysctls of the CNI returned by detectCNI.
+func NewCNISysctlValidator(detectCNI func() (string, error), opts ...CNISysctlValidatorOpt) *CNISysctlValidator {
+	v := &CNISysctlValidator{
+		detectCNI:  detectCNI,
+		readSysctl: readProcSysctl,
+		applyFix:   persistCNISysctls,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Run validates the sysctls required by the detected CNI, applying them first if fix is enabled.
+func (v *CNISysctlValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, "cni-sysctls", "Validating sysctls required by the CNI")
+	defer func() {
+		informer.Done(ctx, "cni-sysctls", err)
+	}()
+
+	cni, err := v.detectCNI()
+	if err != nil {
+		err = fmt.Errorf("detecting CNI: %w", err)
+		return err
+	}
+
+	issues, desired, err := v.checkSysctls(requiredSysctls(cni))
+	if err != nil {
+		return err
+	}
+
+	if len(issues) > 0 && v.fix {
+		if err = v.applyFix(desired); err != nil {
+			err = fmt.Errorf("applying sysctls required by the %s CNI: %w", cni, err)
+			return err
+		}
+		// Confirm the kernel took the new values, another sysctl file could still override them.
+		if issues, _, err = v.checkSysctls(requiredSysctls(cni)); err != nil {
+			return err
+		}
+	}
+
+	if len(issues) > 0 {
+		err = validation.WithRemediation(fmt.Errorf("sysctls required by the %s CNI are not set:\n%s", cni, strings.Join(issues, "\n")),
+			fmt.Sprintf("Set the above sysctls in a file under %s and run 'sysctl --system', or run 'nodeadm debug' with --fix.", sysctlConfDir),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// checkSysctls returns a description of each sysctl without an allowed value and the
+// value each sysctl should have, keeping the current value when it's already allowed.
+func (v *CNISysctlValidator) checkSysctls(sysctls []cniSysctl) ([]string, map[string]string, error) {
+	var issues []string
+	desired := map[string]string{}
+	for _, sysctl := range sysctls {
+		value, err := v.readSysctl(sysctl.key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading sysctl %s: %w", sysctl.key, err)
+		}
+		if slices.Contains(sysctl.allowed, value) {
+			desired[sysctl.key] = value
+			continue
+		}
+		issues = append(issues, fmt.Sprintf("        %d. %s is %s, expected %s", len(issues)+1, sysctl.key, value, strings.Join(sysctl.allowed, " or ")))
+		desired[sysctl.key] = sysctl.fix
+	}
+	return issues, desired, nil
+}
+
+func requiredSysctls(cni string) []cniSysctl {
+	if sysctls, ok := cniRequiredSysctls[cni]; ok {
+		return sysctls
+	}
+	return []cniSysctl{ipForwardSysctl}
+}
+
+func readProcSysctl(key string) (string, error) {
+	value, err := os.ReadFile(path.Join(procSysDir, strings.ReplaceAll(key, ".", "/")))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// persistCNISysctls writes the sysctls to the nodeadm CNI sysctl file and reloads them.
+// The file sorts before 99-nodeadm.conf, so the base nodeadm sysctls still take precedence.
+func persistCNISysctls(sysctls map[string]string) error {
+	if err := util.WriteFileWithDir(cniSysctlConfPath, []byte(formatSysctls(sysctls)), nodeadmSysctlFilePerm); err != nil {
+		return err
+	}
+	return reloadSysctl()
+}
+
+func formatSysctls(sysctls map[string]string) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(sysctls)) {
+		fmt.Fprintf(&b, "%s=%s\n", key, sysctls[key])
+	}
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestCNISysctlValidator_Run(t *testing.T) {
+	tests := []struct {
+		name          string
+		cni           string
+		sysctls       map[string]string
+		fix           bool
+		expectError   bool
+		errorContains string
+		expectApplied map[string]string
+	}{
+		{
+			name: "cilium with rp_filter disabled",
+			cni:  "cilium",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":             "1",
+				"net.ipv4.conf.all.rp_filter":     "0",
+				"net.ipv4.conf.default.rp_filter": "0",
+			},
+		},
+		{
+			name: "cilium with strict rp_filter",
+			cni:  "cilium",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":             "1",
+				"net.ipv4.conf.all.rp_filter":     "1",
+				"net.ipv4.conf.default.rp_filter": "0",
+			},
+			expectError:   true,
+			errorContains: "net.ipv4.conf.all.rp_filter is 1, expected 0",
+		},
+		{
+			name: "calico with strict rp_filter",
+			cni:  "calico",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":         "1",
+				"net.ipv4.conf.all.rp_filter": "1",
+			},
+		},
+		{
+			name: "calico with loose rp_filter",
+			cni:  "calico",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":         "1",
+				"net.ipv4.conf.all.rp_filter": "2",
+			},
+			expectError:   true,
+			errorContains: "net.ipv4.conf.all.rp_filter is 2, expected 0 or 1",
+		},
+		{
+			name: "flannel without bridged traffic through iptables",
+			cni:  "flannel",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":                "1",
+				"net.bridge.bridge-nf-call-iptables": "0",
+			},
+			expectError:   true,
+			errorContains: "net.bridge.bridge-nf-call-iptables is 0, expected 1",
+		},
+		{
+			name: "unknown cni without ip forwarding",
+			cni:  "unknown",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":         "0",
+				"net.ipv4.conf.all.rp_filter": "2",
+			},
+			expectError:   true,
+			errorContains: "net.ipv4.ip_forward is 0, expected 1",
+		},
+		{
+			name: "fix applies all required cilium sysctls",
+			cni:  "cilium",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":             "1",
+				"net.ipv4.conf.all.rp_filter":     "1",
+				"net.ipv4.conf.default.rp_filter": "1",
+			},
+			fix: true,
+			expectApplied: map[string]string{
+				"net.ipv4.ip_forward":             "1",
+				"net.ipv4.conf.all.rp_filter":     "0",
+				"net.ipv4.conf.default.rp_filter": "0",
+			},
+		},
+		{
+			name: "fix keeps allowed calico rp_filter",
+			cni:  "calico",
+			sysctls: map[string]string{
+				"net.ipv4.ip_forward":         "0",
+				"net.ipv4.conf.all.rp_filter": "0",
+			},
+			fix: true,
+			expectApplied: map[string]string{
+				"net.ipv4.ip_forward":         "1",
+				"net.ipv4.conf.all.rp_filter": "0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var applied map[string]string
+			validator := NewCNISysctlValidator(
+				func() (string, error) { return tt.cni, nil },
+				WithSysctlReader(func(key string) (string, error) {
+					value, ok := tt.sysctls[key]
+					if !ok {
+						return "", errors.New("not found")
+					}
+					return value, nil
+				}),
+				WithSysctlFix(tt.fix),
+				WithSysctlApplier(func(sysctls map[string]string) error {
+					applied = sysctls
+					for key, value := range sysctls {
+						tt.sysctls[key] = value
+					}
+					return nil
+				}),
+			)
+
+			informer := &mockInformer{}
+			err := validator.Run(context.Background(), informer, &api.NodeConfig{})
+
+			assert.True(t, informer.startingCalled)
+			assert.True(t, informer.doneCalled)
+			assert.Equal(t, tt.expectApplied, applied)
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+				assert.NotEmpty(t, validation.Remediation(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCNISysctlValidator_RunFixNotApplied(t *testing.T) {
+	validator := NewCNISysctlValidator(
+		func() (string, error) { return "calico", nil },
+		WithSysctlReader(func(key string) (string, error) { return "2", nil }),
+		WithSysctlFix(true),
+		// Simulates another sysctl file overriding the persisted values.
+		WithSysctlApplier(func(map[string]string) error { return nil }),
+	)
+
+	err := validator.Run(context.Background(), &mockInformer{}, &api.NodeConfig{})
+	assert.ErrorContains(t, err, "sysctls required by the calico CNI are not set")
+}
+
+func TestCNISysctlValidator_RunDetectError(t *testing.T) {
+	validator := NewCNISysctlValidator(func() (string, error) { return "", errors.New("permission denied") })
+
+	err := validator.Run(context.Background(), &mockInformer{}, &api.NodeConfig{})
+	assert.ErrorContains(t, err, "detecting CNI: permission denied")
+}
+
+func TestFormatSysctls(t *testing.T) {
+	assert.Equal(t, "net.ipv4.conf.all.rp_filter=0\nnet.ipv4.ip_forward=1\n", formatSysctls(map[string]string{
+		"net.ipv4.ip_forward":         "1",
+		"net.ipv4.conf.all.rp_filter": "0",
+	}))
+}
@@ -0,0 +1,161 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const (
+	gib = 1024 * 1024 * 1024
+
+	diskValidation = "disk-validation"
+)
+
+// diskRequirement is the free space and inodes kubelet and containerd need on the filesystem holding path.
+type diskRequirement struct {
+	path      string
+	minBytes  uint64
+	minInodes uint64
+}
+
+// diskRequirements cover the container images and logs under /var and the pod volumes under
+// /var/lib/kubelet. Images are made of many small files, so a filesystem can run out of inodes
+// long before it runs out of space.
+var diskRequirements = []diskRequirement{
+	{path: "/var", minBytes: 4 * gib, minInodes: 100_000},
+	{path: "/var/lib/kubelet", minBytes: 1 * gib, minInodes: 50_000},
+}
+
+// filesystemStats are the capacity attributes of the filesystem holding a path.
+type filesystemStats struct {
+	// id identifies the filesystem, paths on the same filesystem have the same id.
+	id             string
+	availableBytes uint64
+	// totalInodes is 0 for filesystems that allocate inodes dynamically, like btrfs.
+	totalInodes uint64
+	freeInodes  uint64
+}
+
+// DiskValidator validates the filesystems kubelet and containerd write to have enough free
+// space and inodes.
+type DiskValidator struct {
+	statfs       func(path string) (filesystemStats, error)
+	requirements []diskRequirement
+}
+
+// NewDiskValidator creates a new DiskValidator.
+func NewDiskValidator() *DiskValidator {
+	return &DiskValidator{
+		statfs:       statfsStats,
+		requirements: diskRequirements,
+	}
+}
+
+// Run validates the free space and inodes of the filesystems under /var.
+func (v *DiskValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+	var err error
+	informer.Starting(ctx, diskValidation, "Validating free disk space and inodes")
+	defer func() {
+		informer.Done(ctx, diskValidation, err)
+	}()
+	err = v.Validate()
+	return err
+}
+
+// Validate checks each required path's filesystem has the minimum free space and inodes.
+// Paths on the same filesystem are checked against their combined requirement.
+func (v *DiskValidator) Validate() error {
+	type filesystem struct {
+		paths       []string
+		stats       filesystemStats
+		requirement diskRequirement
+	}
+	var filesystems []*filesystem
+	byID := map[string]*filesystem{}
+	for _, requirement := range v.requirements {
+		// /var/lib/kubelet doesn't exist until kubelet is installed, the closest existing
+		// parent is on the filesystem it will be created on.
+		path, err := closestExistingParent(requirement.path)
+		if err != nil {
+			return err
+		}
+		stats, err := v.statfs(path)
+		if err != nil {
+			return fmt.Errorf("reading filesystem stats of %s: %w", path, err)
+		}
+		fs, ok := byID[stats.id]
+		if !ok {
+			fs = &filesystem{stats: stats}
+			byID[stats.id] = fs
+			filesystems = append(filesystems, fs)
+		}
+		fs.paths = append(fs.paths, requirement.path)
+		fs.requirement.minBytes += requirement.minBytes
+		fs.requirement.minInodes += requirement.minInodes
+	}
+
+	var issues []string
+	for _, fs := range filesystems {
+		paths := strings.Join(fs.paths, ", ")
+		if fs.stats.availableBytes < fs.requirement.minBytes {
+			issues = append(issues, fmt.Sprintf("the filesystem of %s has %s available, %s short of the required %s",
+				paths, formatBytes(fs.stats.availableBytes), formatBytes(fs.requirement.minBytes-fs.stats.availableBytes), formatBytes(fs.requirement.minBytes)))
+		}
+		if fs.stats.totalInodes > 0 && fs.stats.freeInodes < fs.requirement.minInodes {
+			issues = append(issues, fmt.Sprintf("the filesystem of %s has %d free inodes, %d short of the required %d",
+				paths, fs.stats.freeInodes, fs.requirement.minInodes-fs.stats.freeInodes, fs.requirement.minInodes))
+		}
+	}
+	if len(issues) > 0 {
+		return validation.WithRemediation(fmt.Errorf("insufficient disk capacity: %s", strings.Join(issues, "; ")),
+			"Free up space and inodes by removing unused files under /var, like old logs and container images, or grow the filesystem. "+
+				"Check inode usage with 'df -i'. A filesystem formatted with few inodes needs to be recreated with more, like with 'mkfs.ext4 -i'.",
+		)
+	}
+	return nil
+}
+
+func closestExistingParent(path string) (string, error) {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", fmt.Errorf("no existing directory found for %s", path)
+		}
+		path = parent
+	}
+}
+
+func formatBytes(bytes uint64) string {
+	return fmt.Sprintf("%.1fGiB", float64(bytes)/gib)
+}
+
+// statfsStats reads the capacity of the filesystem holding path from the kernel.
+func statfsStats(path string) (filesystemStats, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return filesystemStats{}, err
+	}
+	// Not every filesystem reports an fsid, the device number of path identifies its filesystem instead.
+	var pathStat syscall.Stat_t
+	if err := syscall.Stat(path, &pathStat); err != nil {
+		return filesystemStats{}, err
+	}
+	return filesystemStats{
+		id:             fmt.Sprintf("%d", pathStat.Dev),
+		availableBytes: stat.Bavail * uint64(stat.Bsize),
+		totalInodes:    stat.Files,
+		freeInodes:     stat.Ffree,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestDiskValidator_Validate(t *testing.T) {
+	root := t.TempDir()
+	varDir := filepath.Join(root, "var")
+	kubeletDir := filepath.Join(varDir, "lib/kubelet")
+	assert.NoError(t, os.MkdirAll(varDir, 0o755))
+
+	plenty := filesystemStats{id: "root", availableBytes: 100 * gib, totalInodes: 10_000_000, freeInodes: 5_000_000}
+
+	tests := []struct {
+		name          string
+		stats         map[string]filesystemStats
+		statsErr      error
+		errorContains []string
+	}{
+		{
+			name:  "enough space and inodes on a shared filesystem",
+			stats: map[string]filesystemStats{varDir: plenty},
+		},
+		{
+			name: "low inodes but enough space",
+			stats: map[string]filesystemStats{
+				varDir: {id: "root", availableBytes: 100 * gib, totalInodes: 1_000_000, freeInodes: 20_000},
+			},
+			errorContains: []string{
+				"the filesystem of " + varDir + ", " + kubeletDir + " has 20000 free inodes, 130000 short of the required 150000",
+			},
+		},
+		{
+			name: "low space but enough inodes",
+			stats: map[string]filesystemStats{
+				varDir: {id: "root", availableBytes: 3 * gib, totalInodes: 1_000_000, freeInodes: 500_000},
+			},
+			errorContains: []string{"has 3.0GiB available, 2.0GiB short of the required 5.0GiB"},
+		},
+		{
+			name: "filesystem without an inode limit",
+			stats: map[string]filesystemStats{
+				varDir: {id: "root", availableBytes: 100 * gib},
+			},
+		},
+		{
+			name: "low inodes on the kubelet filesystem",
+			stats: map[string]filesystemStats{
+				varDir:     plenty,
+				kubeletDir: {id: "kubelet", availableBytes: 100 * gib, totalInodes: 100_000, freeInodes: 10_000},
+			},
+			errorContains: []string{"the filesystem of " + kubeletDir + " has 10000 free inodes, 40000 short of the required 50000"},
+		},
+		{
+			name: "low space and inodes",
+			stats: map[string]filesystemStats{
+				varDir: {id: "root", availableBytes: gib, totalInodes: 100_000, freeInodes: 1_000},
+			},
+			errorContains: []string{"1.0GiB available", "1000 free inodes"},
+		},
+		{
+			name:          "error reading stats",
+			statsErr:      errors.New("permission denied"),
+			errorContains: []string{"reading filesystem stats of " + varDir + ": permission denied"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.stats[kubeletDir]; ok {
+				assert.NoError(t, os.MkdirAll(kubeletDir, 0o755))
+				t.Cleanup(func() { os.RemoveAll(filepath.Dir(kubeletDir)) })
+			}
+			validator := NewDiskValidator()
+			validator.requirements = []diskRequirement{
+				{path: varDir, minBytes: 4 * gib, minInodes: 100_000},
+				{path: kubeletDir, minBytes: gib, minInodes: 50_000},
+			}
+			validator.statfs = func(path string) (filesystemStats, error) {
+				return tt.stats[path], tt.statsErr
+			}
+
+			err := validator.Validate()
+
+			if len(tt.errorContains) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, contains := range tt.errorContains {
+				assert.ErrorContains(t, err, contains)
+			}
+			if tt.statsErr == nil {
+				assert.Contains(t, validation.Remediation(err), "df -i")
+			}
+		})
+	}
+}
+
+func TestDiskValidator_Run(t *testing.T) {
+	validator := NewDiskValidator()
+	validator.requirements = []diskRequirement{{path: t.TempDir(), minBytes: gib, minInodes: 50_000}}
+	validator.statfs = func(string) (filesystemStats, error) {
+		return filesystemStats{id: "root", availableBytes: 10 * gib, totalInodes: 100_000, freeInodes: 100}, nil
+	}
+	informer := &mockInformer{}
+
+	err := validator.Run(context.Background(), informer, &api.NodeConfig{})
+
+	assert.ErrorContains(t, err, "49900 short of the required 50000")
+	assert.True(t, informer.startingCalled, "Starting should be called")
+	assert.True(t, informer.doneCalled, "Done should be called")
+	assert.Equal(t, err, informer.lastError)
+}
+
+func TestStatfsStats(t *testing.T) {
+	stats, err := statfsStats(t.TempDir())
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, stats.id)
+	assert.NotZero(t, stats.availableBytes)
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/aws/eks-hybrid/internal/api"
 	"github.com/aws/eks-hybrid/internal/validation"
@@ -18,7 +19,39 @@ var localhostReferenceIDs = []string{
 }
 
 // NTPValidator validates NTP synchronization status
-type NTPValidator struct{}
+type NTPValidator struct {
+	now           func() time.Time
+	awsServerTime func(ctx context.Context, region string) (time.Time, error)
+	// clockSkewTolerance is the maximum skew between the node's clock and AWS time.
+	clockSkewTolerance time.Duration
+}
+
+// NTPValidatorOpt allows to configure the NTPValidator.
+type NTPValidatorOpt func(*NTPValidator)
+
+// WithClock sets the function used to read the node's clock.
+func WithClock(now func() time.Time) NTPValidatorOpt {
+	return func(v *NTPValidator) {
+		v.now = now
+	}
+}
+
+// WithAWSServerTime sets the function used to read the current time from AWS.
+func WithAWSServerTime(serverTime func(ctx context.Context, region string) (time.Time, error)) NTPValidatorOpt {
+	return func(v *NTPValidator) {
+		v.awsServerTime = serverTime
+	}
+}
+
+// WithClockSkewTolerance sets the maximum skew allowed between the node's clock and AWS time.
+// Zero keeps DefaultClockSkewTolerance.
+func WithClockSkewTolerance(tolerance time.Duration) NTPValidatorOpt {
+	return func(v *NTPValidator) {
+		if tolerance > 0 {
+			v.clockSkewTolerance = tolerance
+		}
+	}
+}
 
 type baseError struct {
 	message string
@@ -44,13 +77,26 @@ type TimedatectlSynchronizationError struct {
 	baseError
 }
 
+type ClockSkewError struct {
+	baseError
+}
+
 // NewNTPValidator creates a new NTP validator
-func NewNTPValidator() *NTPValidator {
-	return &NTPValidator{}
+func NewNTPValidator(opts ...NTPValidatorOpt) *NTPValidator {
+	v := &NTPValidator{
+		now:                time.Now,
+		awsServerTime:      awsServerTime,
+		clockSkewTolerance: DefaultClockSkewTolerance,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
-// Run validates NTP synchronization
-func (v *NTPValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+// Run validates NTP synchronization and, when the node config has a region,
+// the clock skew against AWS.
+func (v *NTPValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
 	var err error
 	informer.Starting(ctx, "ntp-sync", "Validating NTP synchronization status")
 	defer func() {
@@ -61,6 +107,13 @@ func (v *NTPValidator) Run(ctx context.Context, informer validation.Informer, _
 		return err
 	}
 
+	if node != nil && node.Spec.Cluster.Region != "" {
+		if err = v.ValidateAWSClockSkew(ctx, node.Spec.Cluster.Region); err != nil {
+			err = addNTPRemediation(err)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -164,6 +217,11 @@ func addNTPRemediation(err error) error {
 		return validation.WithRemediation(err,
 			"Ensure the hybrid node is synchronized with NTP by running `timedatectl set-ntp true`.",
 		)
+	case *ClockSkewError:
+		return validation.WithRemediation(err,
+			"AWS rejects signed requests from clocks skewed by more than 5 minutes with SignatureDoesNotMatch or RequestTimeTooSkewed errors. "+
+				"Ensure the hybrid node is synchronized with NTP and the system clock and timezone are set correctly.",
+		)
 	}
 	return errWithContext
 }
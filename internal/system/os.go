@@ -1,6 +1,11 @@
 package system
 
-import "github.com/go-ini/ini"
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-ini/ini"
+)
 
 const (
 	UbuntuOsName = "ubuntu"
@@ -8,18 +13,51 @@ const (
 	AmazonOsName = "amzn"
 
 	UbuntuResolvConfPath = "/run/systemd/resolve/resolv.conf"
+
+	osReleasePath = "/etc/os-release"
 )
 
-// GetOsName reads the /etc/os-release file and returns the os name
-func GetOsName() string {
-	cfg, _ := ini.Load("/etc/os-release")
-	if cfg != nil {
-		return cfg.Section("").Key("ID").String()
+// OSInfo identifies the distribution of the host, as described by its os-release file.
+type OSInfo struct {
+	// ID is the lower case identifier of the distribution, like ubuntu, rhel or amzn.
+	ID              string
+	VersionID       string
+	VersionCodename string
+}
+
+// OSReleaseReader returns the content of an os-release file.
+type OSReleaseReader func() ([]byte, error)
+
+// HostOSReleaseReader reads the os-release file of the host.
+func HostOSReleaseReader() ([]byte, error) {
+	return os.ReadFile(osReleasePath)
+}
+
+// ReadOSInfo reads the os-release file returned by read into an OSInfo.
+func ReadOSInfo(read OSReleaseReader) (OSInfo, error) {
+	data, err := read()
+	if err != nil {
+		return OSInfo{}, fmt.Errorf("reading os-release: %w", err)
+	}
+	return ParseOSRelease(data)
+}
+
+// ParseOSRelease parses the content of an os-release file.
+func ParseOSRelease(data []byte) (OSInfo, error) {
+	cfg, err := ini.Load(data)
+	if err != nil {
+		return OSInfo{}, fmt.Errorf("parsing os-release: %w", err)
 	}
-	return ""
+	section := cfg.Section("")
+	return OSInfo{
+		ID:              section.Key("ID").String(),
+		VersionID:       section.Key("VERSION_ID").String(),
+		VersionCodename: section.Key("VERSION_CODENAME").String(),
+	}, nil
 }
 
-func GetVersionCodeName() string {
-	cfg, _ := ini.Load("/etc/os-release")
-	return cfg.Section("").Key("VERSION_CODENAME").String()
+// HostOSInfo returns the OSInfo of the host, or a zero OSInfo if its os-release file can't be read.
+func HostOSInfo() OSInfo {
+	info, _ := ReadOSInfo(HostOSReleaseReader)
+	return info
 }
@@ -0,0 +1,105 @@
+package system
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	tests := []struct {
+		name      string
+		osRelease string
+		expected  OSInfo
+	}{
+		{
+			name: "AL2023",
+			osRelease: `NAME="Amazon Linux"
+VERSION="2023"
+ID="amzn"
+ID_LIKE="fedora"
+VERSION_ID="2023"
+PLATFORM_ID="platform:al2023"
+PRETTY_NAME="Amazon Linux 2023.6.20241212"
+`,
+			expected: OSInfo{
+				ID:        AmazonOsName,
+				VersionID: "2023",
+			},
+		},
+		{
+			name: "RHEL 9",
+			osRelease: `NAME="Red Hat Enterprise Linux"
+VERSION="9.4 (Plow)"
+ID="rhel"
+ID_LIKE="fedora"
+VERSION_ID="9.4"
+PRETTY_NAME="Red Hat Enterprise Linux 9.4 (Plow)"
+`,
+			expected: OSInfo{
+				ID:        RhelOsName,
+				VersionID: "9.4",
+			},
+		},
+		{
+			name: "Ubuntu 22.04",
+			osRelease: `PRETTY_NAME="Ubuntu 22.04.4 LTS"
+NAME="Ubuntu"
+VERSION_ID="22.04"
+VERSION="22.04.4 LTS (Jammy Jellyfish)"
+VERSION_CODENAME=jammy
+ID=ubuntu
+ID_LIKE=debian
+UBUNTU_CODENAME=jammy
+`,
+			expected: OSInfo{
+				ID:              UbuntuOsName,
+				VersionID:       "22.04",
+				VersionCodename: "jammy",
+			},
+		},
+		{
+			name: "SLES 15",
+			osRelease: `NAME="SLES"
+VERSION="15-SP5"
+VERSION_ID="15.5"
+PRETTY_NAME="SUSE Linux Enterprise Server 15 SP5"
+ID="sles"
+ID_LIKE="suse"
+`,
+			expected: OSInfo{
+				ID:        "sles",
+				VersionID: "15.5",
+			},
+		},
+		{
+			name: "Bottlerocket",
+			osRelease: `NAME=Bottlerocket
+ID=bottlerocket
+VERSION="1.20.0 (aws-k8s-1.29)"
+PRETTY_NAME="Bottlerocket OS 1.20.0 (aws-k8s-1.29)"
+VARIANT_ID=aws-k8s-1.29
+VERSION_ID=1.20.0
+`,
+			expected: OSInfo{
+				ID:        "bottlerocket",
+				VersionID: "1.20.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ReadOSInfo(func() ([]byte, error) { return []byte(tt.osRelease), nil })
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+func TestReadOSInfoReaderError(t *testing.T) {
+	_, err := ReadOSInfo(func() ([]byte, error) { return nil, errors.New("permission denied") })
+	assert.ErrorContains(t, err, "reading os-release: permission denied")
+}
@@ -34,11 +34,7 @@ func NewPortsAspect(cfg *api.NodeConfig, logger *zap.Logger) SystemAspect {
 }
 
 func NewFirewallManager() firewall.Manager {
-	osName := GetOsName()
-	if osName == UbuntuOsName {
-		return firewall.NewUncomplicatedFirewall()
-	}
-	return firewall.NewFirewalld()
+	return firewall.Detect(HostOSInfo().ID == UbuntuOsName)
 }
 
 func (s *portsAspect) Name() string {
@@ -52,7 +48,9 @@ func (s *portsAspect) Setup() error {
 		s.logger.Info("Skip setting firewall rules")
 		return nil
 	}
-	if firewallEnabled {
+	if firewallEnabled && firewall.ReadOnly(s.firewallManager) {
+		s.warnClosedPorts()
+	} else if firewallEnabled {
 		s.logger.Info("Allowing port on firewall", zap.Reflect("kubelet-server-port", kubeletServePort))
 		if err = s.firewallManager.AllowTcpPort(kubeletServePort); err != nil {
 			return err
@@ -75,3 +73,23 @@ func (s *portsAspect) Setup() error {
 	}
 	return nil
 }
+
+// warnClosedPorts warns about the node ports a firewall backend nodeadm only reads doesn't accept,
+// the operator has to open them in the ruleset nodeadm leaves untouched.
+func (s *portsAspect) warnClosedPorts() {
+	backend := firewall.Backend(s.firewallManager)
+	for _, port := range []string{kubeletServePort, kubeProxyHealthzPort, nodePortStartRangePort} {
+		open, err := s.firewallManager.IsPortOpen(port, "tcp")
+		if err != nil {
+			s.logger.Warn("Failed to check firewall port", zap.String("backend", backend), zap.String("port", port), zap.Error(err))
+			continue
+		}
+		if !open {
+			s.logger.Warn("Firewall doesn't accept node port, nodeadm doesn't change its rules",
+				zap.String("backend", backend),
+				zap.String("port", port),
+				zap.String("remediation", fmt.Sprintf("Accept TCP ports %s, %s and %s-%s in the %s input rules.",
+					kubeletServePort, kubeProxyHealthzPort, nodePortStartRangePort, nodePortEndRangePort, backend)))
+		}
+	}
+}
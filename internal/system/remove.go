@@ -0,0 +1,130 @@
+package system
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const mountInfoPath = "/proc/self/mountinfo"
+
+// Mounter lists and unmounts the file systems mounted on the host.
+type Mounter interface {
+	// MountPoints returns the paths file systems are mounted at.
+	MountPoints() ([]string, error)
+	// Unmount unmounts the file system mounted at target. A lazy unmount
+	// detaches the file system even if it's busy.
+	Unmount(target string, lazy bool) error
+}
+
+// SafeRemoveAll removes path and its children like os.RemoveAll, but refuses to when a file
+// system is mounted at or under path, since os.RemoveAll would delete the mounted content.
+// With unmount, those file systems are unmounted first instead, lazily if lazy is set.
+func SafeRemoveAll(path string, unmount, lazy bool) error {
+	return safeRemoveAll(procMounter{}, path, unmount, lazy)
+}
+
+func safeRemoveAll(mounter Mounter, path string, unmount, lazy bool) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", path, err)
+	}
+
+	mounts, err := mountsUnder(mounter, resolved)
+	if err != nil {
+		return err
+	}
+	if len(mounts) > 0 && !unmount {
+		return fmt.Errorf("refusing to remove %s, file systems are mounted under it: %s", path, strings.Join(mounts, ", "))
+	}
+	for _, mount := range mounts {
+		if err := mounter.Unmount(mount, lazy); err != nil {
+			return fmt.Errorf("unmounting %s before removing %s: %w", mount, path, err)
+		}
+	}
+
+	return os.RemoveAll(path)
+}
+
+// mountsUnder returns the mount points at or under dir, deepest first so they can be unmounted in order.
+func mountsUnder(mounter Mounter, dir string) ([]string, error) {
+	mountPoints, err := mounter.MountPoints()
+	if err != nil {
+		return nil, fmt.Errorf("listing mount points: %w", err)
+	}
+	dir = filepath.Clean(dir)
+	var mounts []string
+	for _, mountPoint := range mountPoints {
+		mountPoint = filepath.Clean(mountPoint)
+		if mountPoint == dir || strings.HasPrefix(mountPoint, dir+string(filepath.Separator)) {
+			mounts = append(mounts, mountPoint)
+		}
+	}
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return strings.Count(mounts[i], string(filepath.Separator)) > strings.Count(mounts[j], string(filepath.Separator))
+	})
+	return mounts, nil
+}
+
+// procMounter reads the mount points from /proc/self/mountinfo.
+type procMounter struct{}
+
+func (procMounter) MountPoints() ([]string, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseMountInfo(f)
+}
+
+func (procMounter) Unmount(target string, lazy bool) error {
+	flags := 0
+	if lazy {
+		flags = syscall.MNT_DETACH
+	}
+	return syscall.Unmount(target, flags)
+}
+
+// parseMountInfo returns the mount points, the fifth field of each mountinfo line.
+// Spaces and other special characters are escaped as octal in mountinfo.
+func parseMountInfo(r io.Reader) ([]string, error) {
+	var mountPoints []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mountPoints = append(mountPoints, unescapeMountInfo(fields[4]))
+	}
+	return mountPoints, scanner.Err()
+}
+
+func unescapeMountInfo(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// MockMounter reports fixed mount points and records the unmounts.
+type MockMounter struct {
+	mountPoints []string
+	unmountErr  error
+	unmounted   []string
+	lazy        bool
+}
+
+func (m *MockMounter) MountPoints() ([]string, error) {
+	return m.mountPoints, nil
+}
+
+func (m *MockMounter) Unmount(target string, lazy bool) error {
+	if m.unmountErr != nil {
+		return m.unmountErr
+	}
+	m.unmounted = append(m.unmounted, target)
+	m.lazy = lazy
+	return nil
+}
+
+func TestSafeRemoveAll(t *testing.T) {
+	testCases := []struct {
+		name          string
+		mountPoints   func(dir string) []string
+		unmount       bool
+		unmountErr    error
+		wantErr       string
+		wantUnmounted func(dir string) []string
+		wantRemoved   bool
+	}{
+		{
+			name:        "no mount points",
+			mountPoints: func(dir string) []string { return []string{"/", "/proc", dir + "-sibling"} },
+			wantRemoved: true,
+		},
+		{
+			name:        "refuses with a mount point under the directory",
+			mountPoints: func(dir string) []string { return []string{"/", filepath.Join(dir, "data")} },
+			wantErr:     "refusing to remove",
+		},
+		{
+			name:        "refuses when the directory is a mount point",
+			mountPoints: func(dir string) []string { return []string{dir} },
+			wantErr:     "refusing to remove",
+		},
+		{
+			name: "unmounts deepest first",
+			mountPoints: func(dir string) []string {
+				return []string{dir, filepath.Join(dir, "data", "nested"), filepath.Join(dir, "data")}
+			},
+			unmount: true,
+			wantUnmounted: func(dir string) []string {
+				return []string{filepath.Join(dir, "data", "nested"), filepath.Join(dir, "data"), dir}
+			},
+			wantRemoved: true,
+		},
+		{
+			name:        "unmount fails",
+			mountPoints: func(dir string) []string { return []string{filepath.Join(dir, "data")} },
+			unmount:     true,
+			unmountErr:  errors.New("device busy"),
+			wantErr:     "device busy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dir := filepath.Join(t.TempDir(), "eks")
+			g.Expect(os.MkdirAll(filepath.Join(dir, "data"), 0o755)).To(Succeed())
+			g.Expect(os.WriteFile(filepath.Join(dir, "data", "file"), []byte("data"), 0o644)).To(Succeed())
+			mounter := &MockMounter{mountPoints: tc.mountPoints(dir), unmountErr: tc.unmountErr}
+
+			err := safeRemoveAll(mounter, dir, tc.unmount, false)
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				g.Expect(filepath.Join(dir, "data", "file")).To(BeAnExistingFile())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.wantUnmounted != nil {
+				g.Expect(mounter.unmounted).To(Equal(tc.wantUnmounted(dir)))
+			}
+			_, statErr := os.Stat(dir)
+			g.Expect(os.IsNotExist(statErr)).To(Equal(tc.wantRemoved))
+		})
+	}
+}
+
+func TestSafeRemoveAllMissingPath(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(safeRemoveAll(&MockMounter{}, filepath.Join(t.TempDir(), "missing"), false, false)).To(Succeed())
+}
+
+func TestParseMountInfo(t *testing.T) {
+	g := NewWithT(t)
+	mountInfo := `22 1 259:1 / / rw,relatime shared:1 - ext4 /dev/root rw
+35 22 0:31 / /etc/eks/my\040data rw,relatime shared:2 - tmpfs tmpfs rw
+`
+	mountPoints, err := parseMountInfo(strings.NewReader(mountInfo))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mountPoints).To(Equal([]string{"/", "/etc/eks/my data"}))
+}
@@ -37,6 +37,12 @@ func (s *swapAspect) Name() string {
 }
 
 func (s *swapAspect) Setup() error {
+	// Leave swap alone when kubelet is configured to run with it.
+	if swapConfig, err := kubeletSwapConfigFor(s.nodeConfig); err == nil && swapConfig.toleratesSwap() {
+		s.logger.Info("Kubelet is configured with failSwapOn false, skipping disabling swap")
+		return nil
+	}
+
 	swapfiles, err := getSwapfilePaths()
 	if err != nil {
 		return err
@@ -0,0 +1,118 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+const swapKubeletRemediation = "Run 'sudo swapoff -a' and remove swap entries from /etc/fstab to disable swap, " +
+	"or configure kubelet to run with swap by setting failSwapOn to false and memorySwap.swapBehavior in the kubelet config. " +
+	"See https://kubernetes.io/docs/concepts/cluster-administration/swap-memory-management/"
+
+// SwapKubeletValidator validates before nodeadm init that either swap is disabled
+// or kubelet is configured to start with swap enabled.
+type SwapKubeletValidator struct {
+	activeSwaps func() ([]*swap, error)
+}
+
+// NewSwapKubeletValidator creates a new SwapKubeletValidator
+func NewSwapKubeletValidator() *SwapKubeletValidator {
+	return &SwapKubeletValidator{activeSwaps: getSwapfilePaths}
+}
+
+// Run validates the swap configuration is compatible with the kubelet config
+func (v *SwapKubeletValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
+	var err error
+	name := "swap-validation"
+	informer.Starting(ctx, name, "Validating swap configuration for kubelet")
+	defer func() {
+		informer.Done(ctx, name, err)
+	}()
+	err = v.Validate(node)
+	return err
+}
+
+// Validate checks active swap against the kubelet swap configuration.
+func (v *SwapKubeletValidator) Validate(node *api.NodeConfig) error {
+	swaps, err := v.activeSwaps()
+	if err != nil {
+		return fmt.Errorf("getting active swaps: %w", err)
+	}
+	if len(swaps) == 0 {
+		return nil
+	}
+
+	swapConfig, err := kubeletSwapConfigFor(node)
+	if err != nil {
+		return validation.WithRemediation(err, "Ensure failSwapOn is a boolean and memorySwap is an object in the kubelet config.")
+	}
+
+	if swapConfig.toleratesSwap() {
+		return swapConfig.validateSwapBehavior()
+	}
+
+	var partitions, files []string
+	for _, s := range swaps {
+		if s.swapType == swapTypePartition {
+			partitions = append(partitions, s.filePath)
+		} else {
+			files = append(files, s.filePath)
+		}
+	}
+	if len(partitions) > 0 {
+		return validation.WithRemediation(
+			fmt.Errorf("partition swap %s is active and kubelet is not configured to run with swap", strings.Join(partitions, ", ")),
+			swapKubeletRemediation)
+	}
+	return validation.WithWarning(
+		fmt.Errorf("file swap %s is active and kubelet is not configured to run with swap, nodeadm will disable it", strings.Join(files, ", ")),
+		swapKubeletRemediation)
+}
+
+// kubeletSwapConfig holds the kubelet config fields that control swap support.
+type kubeletSwapConfig struct {
+	failSwapOn   *bool
+	swapBehavior string
+}
+
+// toleratesSwap returns true if kubelet is configured to start with swap enabled.
+func (c kubeletSwapConfig) toleratesSwap() bool {
+	return c.failSwapOn != nil && !*c.failSwapOn
+}
+
+// validateSwapBehavior warns when kubelet runs with swap enabled without an explicit
+// swap behavior for pods.
+func (c kubeletSwapConfig) validateSwapBehavior() error {
+	if c.swapBehavior == "" {
+		return validation.WithWarning(
+			fmt.Errorf("swap is active and kubelet failSwapOn is false, but memorySwap.swapBehavior is not set"),
+			"Set memorySwap.swapBehavior in the kubelet config to NoSwap or LimitedSwap to make the swap behavior for pods explicit.")
+	}
+	return nil
+}
+
+func kubeletSwapConfigFor(node *api.NodeConfig) (kubeletSwapConfig, error) {
+	var config kubeletSwapConfig
+	if raw, ok := node.Spec.Kubelet.Config["failSwapOn"]; ok {
+		var failSwapOn bool
+		if err := json.Unmarshal(raw.Raw, &failSwapOn); err != nil {
+			return config, fmt.Errorf("parsing kubelet config failSwapOn: %w", err)
+		}
+		config.failSwapOn = &failSwapOn
+	}
+	if raw, ok := node.Spec.Kubelet.Config["memorySwap"]; ok {
+		var memorySwap struct {
+			SwapBehavior string `json:"swapBehavior"`
+		}
+		if err := json.Unmarshal(raw.Raw, &memorySwap); err != nil {
+			return config, fmt.Errorf("parsing kubelet config memorySwap: %w", err)
+		}
+		config.swapBehavior = memorySwap.SwapBehavior
+	}
+	return config, nil
+}
@@ -0,0 +1,135 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func swapNodeConfig(kubeletConfig map[string]string) *api.NodeConfig {
+	node := &api.NodeConfig{}
+	if kubeletConfig != nil {
+		node.Spec.Kubelet.Config = api.InlineDocument{}
+		for k, v := range kubeletConfig {
+			node.Spec.Kubelet.Config[k] = runtime.RawExtension{Raw: []byte(v)}
+		}
+	}
+	return node
+}
+
+func TestSwapKubeletValidator_Validate(t *testing.T) {
+	fileSwap := &swap{filePath: "/swapfile", swapType: swapTypeFile}
+	partitionSwap := &swap{filePath: "/dev/sda2", swapType: swapTypePartition}
+
+	tests := []struct {
+		name          string
+		swaps         []*swap
+		swapsErr      error
+		kubeletConfig map[string]string
+		expectWarning bool
+		errorContains string
+	}{
+		{
+			name: "no swap",
+		},
+		{
+			name:          "no swap ignores invalid kubelet config",
+			kubeletConfig: map[string]string{"failSwapOn": `"no"`},
+		},
+		{
+			name:          "partition swap with default kubelet config",
+			swaps:         []*swap{partitionSwap},
+			errorContains: "partition swap /dev/sda2 is active and kubelet is not configured to run with swap",
+		},
+		{
+			name:          "partition swap with failSwapOn true",
+			swaps:         []*swap{fileSwap, partitionSwap},
+			kubeletConfig: map[string]string{"failSwapOn": "true"},
+			errorContains: "partition swap /dev/sda2 is active",
+		},
+		{
+			name:          "file swap with default kubelet config",
+			swaps:         []*swap{fileSwap},
+			expectWarning: true,
+			errorContains: "file swap /swapfile is active and kubelet is not configured to run with swap, nodeadm will disable it",
+		},
+		{
+			name:  "partition swap tolerated by kubelet",
+			swaps: []*swap{partitionSwap},
+			kubeletConfig: map[string]string{
+				"failSwapOn": "false",
+				"memorySwap": `{"swapBehavior":"LimitedSwap"}`,
+			},
+		},
+		{
+			name:          "failSwapOn false without swap behavior",
+			swaps:         []*swap{fileSwap},
+			kubeletConfig: map[string]string{"failSwapOn": "false"},
+			expectWarning: true,
+			errorContains: "memorySwap.swapBehavior is not set",
+		},
+		{
+			name:          "memorySwap without failSwapOn false",
+			swaps:         []*swap{partitionSwap},
+			kubeletConfig: map[string]string{"memorySwap": `{"swapBehavior":"LimitedSwap"}`},
+			errorContains: "kubelet is not configured to run with swap",
+		},
+		{
+			name:          "invalid failSwapOn",
+			swaps:         []*swap{fileSwap},
+			kubeletConfig: map[string]string{"failSwapOn": `"no"`},
+			errorContains: "parsing kubelet config failSwapOn",
+		},
+		{
+			name:          "invalid memorySwap",
+			swaps:         []*swap{fileSwap},
+			kubeletConfig: map[string]string{"failSwapOn": "false", "memorySwap": `"LimitedSwap"`},
+			errorContains: "parsing kubelet config memorySwap",
+		},
+		{
+			name:          "error reading swaps",
+			swapsErr:      errors.New("permission denied"),
+			errorContains: "getting active swaps: permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewSwapKubeletValidator()
+			validator.activeSwaps = func() ([]*swap, error) { return tt.swaps, tt.swapsErr }
+
+			err := validator.Validate(swapNodeConfig(tt.kubeletConfig))
+
+			if tt.errorContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errorContains)
+			assert.Equal(t, tt.expectWarning, validation.IsWarning(err))
+			if tt.swapsErr == nil {
+				assert.NotEmpty(t, validation.Remediation(err))
+			}
+		})
+	}
+}
+
+func TestSwapKubeletValidator_Run(t *testing.T) {
+	validator := NewSwapKubeletValidator()
+	validator.activeSwaps = func() ([]*swap, error) {
+		return []*swap{{filePath: "/dev/sda2", swapType: swapTypePartition}}, nil
+	}
+	informer := &mockInformer{}
+
+	err := validator.Run(context.Background(), informer, swapNodeConfig(nil))
+
+	assert.Error(t, err)
+	assert.True(t, informer.startingCalled, "Starting should be called")
+	assert.True(t, informer.doneCalled, "Done should be called")
+	assert.Equal(t, err, informer.lastError)
+}
@@ -9,26 +9,42 @@ import (
 )
 
 // SwapValidator validates swap configuration in nodeadm debug
-type SwapValidator struct{}
+type SwapValidator struct {
+	activeSwaps func() ([]*swap, error)
+}
 
 // NewSwapValidator creates a new SwapValidator
 func NewSwapValidator() *SwapValidator {
-	return &SwapValidator{}
+	return &SwapValidator{activeSwaps: getSwapfilePaths}
 }
 
 // Run validates the swap configuration
-func (v *SwapValidator) Run(ctx context.Context, informer validation.Informer, _ *api.NodeConfig) error {
+func (v *SwapValidator) Run(ctx context.Context, informer validation.Informer, node *api.NodeConfig) error {
 	var err error
 	informer.Starting(ctx, "swap", "Validating swap configuration")
 	defer func() {
 		informer.Done(ctx, "swap", err)
 	}()
 
-	swapfiles, err := getSwapfilePaths()
+	swapfiles, err := v.activeSwaps()
 	if err != nil {
 		err = fmt.Errorf("getting swapfile paths : %w", err)
 		return err
 	}
+	if len(swapfiles) == 0 {
+		return nil
+	}
+
+	// When kubelet is configured to run with swap, init leaves swap enabled.
+	swapConfig, err := kubeletSwapConfigFor(node)
+	if err != nil {
+		err = validation.WithRemediation(err, "Ensure failSwapOn is a boolean and memorySwap is an object in the kubelet config.")
+		return err
+	}
+	if swapConfig.toleratesSwap() {
+		err = swapConfig.validateSwapBehavior()
+		return err
+	}
 
 	// Check for partition-type swap that would cause init to fail
 	hasPartitionSwap, err := partitionSwapExists(swapfiles)
@@ -6,7 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
-	"github.com/aws/eks-hybrid/internal/api"
+	"github.com/aws/eks-hybrid/internal/validation"
 )
 
 // mockInformer implements validation.Informer for testing
@@ -26,31 +26,65 @@ func (m *mockInformer) Done(ctx context.Context, name string, err error) {
 }
 
 func TestSwapValidator_Run(t *testing.T) {
+	fileSwap := &swap{filePath: "/swapfile", swapType: swapTypeFile}
+	partitionSwap := &swap{filePath: "/dev/sda2", swapType: swapTypePartition}
+
 	tests := []struct {
 		name          string
-		setupMockSwap func() // Function to set up mock swap state
+		swaps         []*swap
+		kubeletConfig map[string]string
 		expectError   bool
+		expectWarning bool
 		errorContains string
 	}{
 		{
-			name:          "no swap present",
-			setupMockSwap: func() {}, // No setup needed for no swap
+			name:        "no swap present",
+			expectError: false,
+		},
+		{
+			name:          "partition swap",
+			swaps:         []*swap{partitionSwap},
+			expectError:   true,
+			errorContains: "partition swap detected on host",
+		},
+		{
+			name:          "file swap left enabled",
+			swaps:         []*swap{fileSwap},
+			expectError:   true,
+			errorContains: "swap still active on host",
+		},
+		{
+			name:          "swap tolerated by kubelet",
+			swaps:         []*swap{fileSwap, partitionSwap},
+			kubeletConfig: map[string]string{"failSwapOn": "false", "memorySwap": `{"swapBehavior":"LimitedSwap"}`},
 			expectError:   false,
 		},
+		{
+			name:          "swap tolerated by kubelet without swap behavior",
+			swaps:         []*swap{partitionSwap},
+			kubeletConfig: map[string]string{"failSwapOn": "false"},
+			expectError:   true,
+			expectWarning: true,
+			errorContains: "memorySwap.swapBehavior is not set",
+		},
+		{
+			name:          "swap with failSwapOn true",
+			swaps:         []*swap{fileSwap},
+			kubeletConfig: map[string]string{"failSwapOn": "true"},
+			expectError:   true,
+			errorContains: "swap still active on host",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			validator := NewSwapValidator()
+			validator.activeSwaps = func() ([]*swap, error) { return tt.swaps, nil }
 			informer := &mockInformer{}
-			nodeConfig := &api.NodeConfig{}
+			nodeConfig := swapNodeConfig(tt.kubeletConfig)
 			ctx := context.Background()
 
-			if tt.setupMockSwap != nil {
-				tt.setupMockSwap()
-			}
-
 			// Execute
 			err := validator.Run(ctx, informer, nodeConfig)
 
@@ -64,6 +98,7 @@ func TestSwapValidator_Run(t *testing.T) {
 					assert.Contains(t, err.Error(), tt.errorContains)
 				}
 				assert.Error(t, informer.lastError)
+				assert.Equal(t, tt.expectWarning, validation.IsWarning(err))
 			} else {
 				assert.NoError(t, err)
 				assert.NoError(t, informer.lastError)
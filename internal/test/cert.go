@@ -28,7 +28,7 @@ func GenerateCA(g *WithT) ([]byte, *x509.Certificate, *ecdsa.PrivateKey) {
 		IsCA:                  true,
 		BasicConstraintsValid: true,
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
 	}
 
 	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
@@ -48,18 +48,28 @@ func GenerateCA(g *WithT) ([]byte, *x509.Certificate, *ecdsa.PrivateKey) {
 
 // GenerateKubeletCert creates a new kubelet certificate signed by the given CA
 func GenerateKubeletCert(g *WithT, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, validFrom, validTo time.Time) []byte {
+	return generateLeafCert(g, issuer, issuerKey, "test-kubelet", validFrom, validTo, x509.ExtKeyUsageServerAuth)
+}
+
+// GenerateKubeletClientCert creates a new kubelet client certificate signed by the given CA,
+// like the one kubelet authenticates to the API server with
+func GenerateKubeletClientCert(g *WithT, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, validFrom, validTo time.Time) []byte {
+	return generateLeafCert(g, issuer, issuerKey, "system:node:test-kubelet", validFrom, validTo, x509.ExtKeyUsageClientAuth)
+}
+
+func generateLeafCert(g *WithT, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey, commonName string, validFrom, validTo time.Time, usage x509.ExtKeyUsage) []byte {
 	cert := &x509.Certificate{
 		SerialNumber: big.NewInt(2025),
 		Subject: pkix.Name{
 			Organization: []string{"Test Kubelet"},
-			CommonName:   "test-kubelet",
+			CommonName:   commonName,
 		},
 		NotBefore:             validFrom,
 		NotAfter:              validTo,
 		IsCA:                  false,
 		BasicConstraintsValid: true,
 		KeyUsage:              x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
 	}
 
 	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
@@ -76,3 +86,37 @@ func GenerateKubeletCert(g *WithT, issuer *x509.Certificate, issuerKey *ecdsa.Pr
 
 	return certPEM.Bytes()
 }
+
+// GenerateIntermediateCA creates a new intermediate CA certificate signed by the given CA and returns
+// the PEM encoded certificate, the parsed certificate, and the private key
+func GenerateIntermediateCA(g *WithT, issuer *x509.Certificate, issuerKey *ecdsa.PrivateKey) ([]byte, *x509.Certificate, *ecdsa.PrivateKey) {
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2026),
+		Subject: pkix.Name{
+			Organization: []string{"Test CA"},
+			CommonName:   "test-intermediate-ca",
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().AddDate(5, 0, 0),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, cert, issuer, &privateKey.PublicKey, issuerKey)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	parsed, err := x509.ParseCertificate(certBytes)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	certPEM := new(bytes.Buffer)
+	g.Expect(pem.Encode(certPEM, &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
+	})).NotTo(HaveOccurred())
+
+	return certPEM.Bytes(), parsed, privateKey
+}
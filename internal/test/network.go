@@ -0,0 +1,48 @@
+package test
+
+import (
+	"fmt"
+	"net"
+)
+
+// FakeNetwork is a fake implementation of network.Network that resolves host names
+// and addresses from its fields instead of the host network.
+type FakeNetwork struct {
+	// DNSRecords are the IPs LookupIP returns for each host, other hosts are not found.
+	DNSRecords map[string][]net.IP
+	// ResolvedBindAddr is the IP ResolveBindAddress returns, like the IP of the default route interface.
+	ResolvedBindAddr net.IP
+	BindAddrErr      error
+	// NetworkInterfaces are the addresses of all the host interfaces returned by InterfaceAddrs.
+	NetworkInterfaces []net.Addr
+	InterfacesErr     error
+	// NamedInterfaces are the addresses of each interface returned by InterfaceAddrsByName.
+	NamedInterfaces map[string][]net.Addr
+}
+
+func (f *FakeNetwork) LookupIP(host string) ([]net.IP, error) {
+	if ips, exists := f.DNSRecords[host]; exists {
+		return ips, nil
+	}
+	return nil, &net.DNSError{
+		Err:        "no such host",
+		Name:       host,
+		IsNotFound: true,
+	}
+}
+
+func (f *FakeNetwork) ResolveBindAddress(bindAddress net.IP) (net.IP, error) {
+	return f.ResolvedBindAddr, f.BindAddrErr
+}
+
+func (f *FakeNetwork) InterfaceAddrs() ([]net.Addr, error) {
+	return f.NetworkInterfaces, f.InterfacesErr
+}
+
+func (f *FakeNetwork) InterfaceAddrsByName(name string) ([]net.Addr, error) {
+	addrs, ok := f.NamedInterfaces[name]
+	if !ok {
+		return nil, fmt.Errorf("route ip+net: no such network interface")
+	}
+	return addrs, nil
+}
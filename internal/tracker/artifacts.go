@@ -5,11 +5,14 @@ import (
 	"io/fs"
 	"os"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
 	"github.com/aws/eks-hybrid/internal/artifact"
+	"github.com/aws/eks-hybrid/internal/system"
 	"github.com/aws/eks-hybrid/internal/util"
 )
 
@@ -25,6 +28,19 @@ const trackerFile = "/opt/nodeadm/tracker"
 
 type Tracker struct {
 	Artifacts *InstalledArtifacts
+	// SkippedValidations are the validations skipped the last time the node was initialized or upgraded.
+	SkippedValidations *SkippedValidations `json:"SkippedValidations,omitempty"`
+
+	// mu guards Artifacts in Add and Save, components can be installed concurrently.
+	mu sync.Mutex
+}
+
+// SkippedValidations records the validations bypassed by a command, so operators know
+// the node joined with reduced checks.
+type SkippedValidations struct {
+	Command     string
+	Validations []string
+	Time        time.Time
 }
 
 type InstalledArtifacts struct {
@@ -35,12 +51,19 @@ type InstalledArtifacts struct {
 	ImageCredentialProvider bool
 	Kubectl                 bool
 	Kubelet                 bool
-	Ssm                     bool
-	Iptables                bool
+	// KubeletVersion is the version the installed kubelet binary reported when it was verified.
+	KubeletVersion string `json:",omitempty"`
+	Ssm            bool
+	Iptables       bool
+	// SandboxImage is the pause image configured at install, init configures containerd
+	// and kubelet with it instead of the EKS default.
+	SandboxImage string `json:",omitempty"`
 }
 
-// Add adds a components as installed to the tracker
+// Add adds a components as installed to the tracker. It is safe for concurrent use.
 func (tracker *Tracker) Add(componentName string) error {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
 	switch componentName {
 	case artifact.CniPlugins:
 		tracker.Artifacts.CniPlugins = true
@@ -64,30 +87,96 @@ func (tracker *Tracker) Add(componentName string) error {
 	return nil
 }
 
-// Save() saves the tracker to file
+// SetKubeletVersion records the verified version of the installed kubelet. It is safe for concurrent use.
+func (tracker *Tracker) SetKubeletVersion(version string) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.Artifacts.KubeletVersion = version
+}
+
+// SetSkippedValidations records the validations skipped by command. It clears the
+// previous record when no validation was skipped.
+func (tracker *Tracker) SetSkippedValidations(command string, validations []string, now time.Time) {
+	if len(validations) == 0 {
+		tracker.SkippedValidations = nil
+		return
+	}
+	tracker.SkippedValidations = &SkippedValidations{
+		Command:     command,
+		Validations: validations,
+		Time:        now.UTC(),
+	}
+}
+
+// RecordSkippedValidations saves the validations skipped by command to the tracker.
+// Nothing is recorded when nodeadm components are not installed.
+func RecordSkippedValidations(command string, validations []string) error {
+	return recordSkippedValidations(trackerFile, command, validations, time.Now())
+}
+
+func recordSkippedValidations(file, command string, validations []string, now time.Time) error {
+	tracker, err := readTracker(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	tracker.SetSkippedValidations(command, validations, now)
+	return tracker.saveTo(file)
+}
+
+// Save() saves the tracker to file. It writes a consistent snapshot of the tracker,
+// components added concurrently are either fully in it or not at all.
 func (tracker *Tracker) Save() error {
-	// ensure containerd source is populated with none/distro/docker
-	containerdSource, err := ContainerdSource(string(tracker.Artifacts.Containerd))
+	return tracker.saveTo(trackerFile)
+}
+
+func (tracker *Tracker) saveTo(file string) error {
+	data, err := tracker.marshal()
 	if err != nil {
 		return err
 	}
-	tracker.Artifacts.Containerd = containerdSource
-	data, err := yaml.Marshal(tracker)
+
+	return util.WriteFileWithDir(file, data, 0o644)
+}
+
+func (tracker *Tracker) marshal() ([]byte, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	// ensure containerd source is populated with none/distro/docker
+	containerdSource, err := ContainerdSource(string(tracker.Artifacts.Containerd))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	tracker.Artifacts.Containerd = containerdSource
+	return yaml.Marshal(tracker)
+}
 
-	return util.WriteFileWithDir(trackerFile, data, 0o644)
+// Clear removes the tracker directory. It refuses to if file systems are mounted under it,
+// unless unmount is set, in which case they are unmounted first.
+func Clear(unmount bool) error {
+	return system.SafeRemoveAll(Dir(), unmount, false)
 }
 
-func Clear() error {
-	return os.RemoveAll(path.Dir(trackerFile))
+// Dir returns the directory the tracker is stored in, which Clear removes.
+func Dir() string {
+	return path.Dir(trackerFile)
+}
+
+// File returns the path of the tracker file.
+func File() string {
+	return trackerFile
 }
 
 // GetInstalledArtifacts reads the tracker file and returns the current
 // installed artifacts
 func GetInstalledArtifacts() (*Tracker, error) {
-	yamlFileData, err := os.ReadFile(trackerFile)
+	return readTracker(trackerFile)
+}
+
+func readTracker(file string) (*Tracker, error) {
+	yamlFileData, err := os.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
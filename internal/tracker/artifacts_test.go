@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/artifact"
+)
+
+func TestRecordSkippedValidations(t *testing.T) {
+	g := NewWithT(t)
+	file := filepath.Join(t.TempDir(), "tracker")
+	installed := &Tracker{Artifacts: &InstalledArtifacts{Kubelet: true, Containerd: ContainerdSourceDistro}}
+	g.Expect(installed.saveTo(file)).To(Succeed())
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	g.Expect(recordSkippedValidations(file, "init", []string{"node-ip-validation", "swap-validation"}, now)).To(Succeed())
+
+	got, err := readTracker(file)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.Artifacts.Kubelet).To(BeTrue())
+	g.Expect(got.Artifacts.Containerd).To(Equal(ContainerdSourceDistro))
+	g.Expect(got.SkippedValidations).To(Equal(&SkippedValidations{
+		Command:     "init",
+		Validations: []string{"node-ip-validation", "swap-validation"},
+		Time:        now,
+	}))
+
+	g.Expect(recordSkippedValidations(file, "upgrade", nil, now)).To(Succeed())
+	got, err = readTracker(file)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.SkippedValidations).To(BeNil())
+}
+
+func TestRecordSkippedValidationsNotInstalled(t *testing.T) {
+	g := NewWithT(t)
+	file := filepath.Join(t.TempDir(), "tracker")
+
+	g.Expect(recordSkippedValidations(file, "init", []string{"swap-validation"}, time.Now())).To(Succeed())
+
+	_, err := os.Stat(file)
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
+func TestTrackerAddConcurrently(t *testing.T) {
+	g := NewWithT(t)
+	file := filepath.Join(t.TempDir(), "tracker")
+	tracker := &Tracker{Artifacts: &InstalledArtifacts{Containerd: ContainerdSourceDistro}}
+	components := []string{
+		artifact.CniPlugins,
+		artifact.IamAuthenticator,
+		artifact.IamRolesAnywhere,
+		artifact.ImageCredentialProvider,
+		artifact.Kubectl,
+		artifact.Kubelet,
+		artifact.Ssm,
+		artifact.Iptables,
+	}
+
+	var wg sync.WaitGroup
+	for _, component := range components {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			g.Expect(tracker.Add(component)).To(Succeed())
+		}()
+		// Saving while components are added must not race with Add.
+		go func() {
+			defer wg.Done()
+			g.Expect(tracker.saveTo(file)).To(Succeed())
+		}()
+	}
+	wg.Wait()
+
+	g.Expect(*tracker.Artifacts).To(Equal(InstalledArtifacts{
+		Containerd:              ContainerdSourceDistro,
+		CniPlugins:              true,
+		IamAuthenticator:        true,
+		IamRolesAnywhere:        true,
+		ImageCredentialProvider: true,
+		Kubectl:                 true,
+		Kubelet:                 true,
+		Ssm:                     true,
+		Iptables:                true,
+	}))
+}
+
+func TestTrackerSaveFormat(t *testing.T) {
+	g := NewWithT(t)
+	file := filepath.Join(t.TempDir(), "tracker")
+	tracker := &Tracker{Artifacts: &InstalledArtifacts{Kubelet: true}}
+
+	g.Expect(tracker.saveTo(file)).To(Succeed())
+
+	data, err := os.ReadFile(file)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(data)).To(Equal(`Artifacts:
+  CniPlugins: false
+  Containerd: none
+  IamAuthenticator: false
+  IamRolesAnywhere: false
+  ImageCredentialProvider: false
+  Iptables: false
+  Kubectl: false
+  Kubelet: true
+  Ssm: false
+`))
+}
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/aws/eks-hybrid/internal/logger"
+	"github.com/aws/eks-hybrid/internal/retry"
 )
 
 // Builder builds a exec.Cmd. Each invocation should return a new instance
@@ -16,23 +17,25 @@ import (
 type Builder func(context.Context) *exec.Cmd
 
 // Retry runs the command until it succeeds or the context is cancelled.
-// The backoff duration is the time to wait between retries.
+// The backoff duration is the time to wait between retries, with a 10% jitter
+// so nodes provisioned together don't retry in lockstep.
 func Retry(ctx context.Context, newCmd Builder, backoff time.Duration) error {
 	log := logger.FromContext(ctx)
-	var err error
-	for {
-		var out []byte
+	retrier := retry.Retrier{
+		Backoff: retry.Backoff{
+			Duration: backoff,
+			Factor:   1,
+			Jitter:   0.1,
+		},
+	}
+	return retrier.Do(ctx, func(ctx context.Context) (bool, error) {
 		cmd := newCmd(ctx)
-		out, err = cmd.CombinedOutput()
+		out, err := cmd.CombinedOutput()
 		if err == nil {
-			return nil
+			return true, nil
 		}
 		err = fmt.Errorf("running command %s: %s [Err %s]", cmd.Args, out, err)
 		log.Info("Command failed, retrying", zap.Duration("backoff", backoff), zap.Error(err))
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("%s: %w", ctx.Err(), err)
-		case <-time.After(backoff):
-		}
-	}
+		return false, err
+	})
 }
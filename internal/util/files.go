@@ -20,6 +20,35 @@ func WriteFileWithDir(filePath string, data []byte, perm fs.FileMode) error {
 	return os.WriteFile(filePath, data, perm)
 }
 
+// WriteFileAtomic writes data to a temporary file in the directory of filePath and renames it,
+// so readers never see a partially written file. Parent directories are created if needed.
+func WriteFileAtomic(filePath string, data []byte, perm fs.FileMode) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
 // IsFilePathExists checks whether specific file path exists
 func IsFilePathExists(filePath string) (bool, error) {
 	_, err := os.Stat(filePath)
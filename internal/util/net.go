@@ -11,14 +11,36 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/aws/eks-hybrid/cmd/nodeadm/version"
+	"github.com/aws/eks-hybrid/internal/retry"
 )
 
 const userAgentHeader = "User-Agent"
 
 var userAgent = fmt.Sprintf("nodeadm/%s (%s/%s)", version.GitVersion, runtime.GOOS, runtime.GOARCH)
 
-func GetHttpFile(ctx context.Context, uri string) ([]byte, error) {
-	reader, err := GetHttpFileReader(ctx, uri)
+// HttpOption configures how a file is downloaded.
+type HttpOption func(*retryHttpClient)
+
+// WithAttemptTimeout bounds each download attempt. GetHttpFile reads the body within the
+// attempt, so a server hanging before or while sending the body is retried instead of
+// stalling the download.
+func WithAttemptTimeout(timeout time.Duration) HttpOption {
+	return func(hc *retryHttpClient) {
+		hc.client = &http.Client{Timeout: timeout}
+		hc.attemptTimeout = timeout
+	}
+}
+
+func GetHttpFile(ctx context.Context, uri string, opts ...HttpOption) ([]byte, error) {
+	hc := newRetryableHttpClient(2*time.Second, 3)
+	for _, opt := range opts {
+		opt(hc)
+	}
+	if hc.attemptTimeout != 0 {
+		return getHttpFileWithAttemptTimeout(ctx, uri, hc)
+	}
+
+	reader, err := GetHttpFileReader(ctx, uri, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +54,26 @@ func GetHttpFile(ctx context.Context, uri string) ([]byte, error) {
 	return data, nil
 }
 
-func GetHttpFileReader(ctx context.Context, uri string) (io.ReadCloser, error) {
+// getHttpFileWithAttemptTimeout downloads uri, retrying attempts that fail or don't read the
+// whole body within the attempt timeout of hc.
+func getHttpFileWithAttemptTimeout(ctx context.Context, uri string, hc *retryHttpClient) ([]byte, error) {
+	retrier := retry.Retrier{
+		OperationTimeout: hc.attemptTimeout,
+		Backoff:          retry.Backoff{Steps: hc.maxRetries},
+	}
+	var data []byte
+	err := retrier.Do(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		data, err = hc.get(ctx, uri)
+		return err == nil, err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed reading file from url: %s", uri)
+	}
+	return data, nil
+}
+
+func GetHttpFileReader(ctx context.Context, uri string, opts ...HttpOption) (io.ReadCloser, error) {
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed creating request from url: %s", uri)
@@ -40,6 +81,9 @@ func GetHttpFileReader(ctx context.Context, uri string) (io.ReadCloser, error) {
 	request.Header.Add(userAgentHeader, userAgent)
 
 	httpRetryClient := newRetryableHttpClient(2*time.Second, 3)
+	for _, opt := range opts {
+		opt(httpRetryClient)
+	}
 	resp, err := httpRetryClient.Do(request)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed reading file from url: %s", uri)
@@ -48,12 +92,16 @@ func GetHttpFileReader(ctx context.Context, uri string) (io.ReadCloser, error) {
 }
 
 type retryHttpClient struct {
+	client     *http.Client
 	backoff    time.Duration
 	maxRetries int
+	// attemptTimeout bounds each attempt of GetHttpFile, including reading the body.
+	attemptTimeout time.Duration
 }
 
 func newRetryableHttpClient(backoff time.Duration, maxRetries int) *retryHttpClient {
 	return &retryHttpClient{
+		client:     http.DefaultClient,
 		backoff:    backoff,
 		maxRetries: maxRetries,
 	}
@@ -64,11 +112,15 @@ func (hc *retryHttpClient) Do(req *http.Request) (*http.Response, error) {
 	var err error
 
 	for range hc.maxRetries {
-		resp, err = http.DefaultClient.Do(req)
+		resp, err = hc.client.Do(req)
 		if err != nil {
+			if req.Context().Err() != nil {
+				break
+			}
 			continue
 		}
 		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
 			err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 			continue
 		}
@@ -76,3 +128,21 @@ func (hc *retryHttpClient) Do(req *http.Request) (*http.Response, error) {
 	}
 	return nil, fmt.Errorf("max retries achieved for http request: %s : %w", req.Host, err)
 }
+
+// get downloads uri once, reading the whole body.
+func (hc *retryHttpClient) get(ctx context.Context, uri string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add(userAgentHeader, userAgent)
+	resp, err := hc.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
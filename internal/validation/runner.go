@@ -37,6 +37,29 @@ type Informer interface {
 	Done(ctx context.Context, name string, err error)
 }
 
+type informerKey struct{}
+
+// WithInformer returns a copy of ctx carrying informer. Runners called with the
+// returned context report their validations to informer as well as their own informer.
+func WithInformer(ctx context.Context, informer Informer) context.Context {
+	return context.WithValue(ctx, informerKey{}, informer)
+}
+
+// multiInformer reports to all its informers.
+type multiInformer []Informer
+
+func (m multiInformer) Starting(ctx context.Context, name, message string) {
+	for _, informer := range m {
+		informer.Starting(ctx, name, message)
+	}
+}
+
+func (m multiInformer) Done(ctx context.Context, name string, err error) {
+	for _, informer := range m {
+		informer.Done(ctx, name, err)
+	}
+}
+
 // RunnerConfig holds the configuration for the Runner.
 type RunnerConfig struct {
 	skipValidations []string
@@ -82,8 +105,13 @@ func (r *Runner[O]) Sequentially(ctx context.Context, obj O) error {
 	copyObj := obj.DeepCopy()
 	var errs []error
 
+	informer := r.informer
+	if ctxInformer, ok := ctx.Value(informerKey{}).(Informer); ok {
+		informer = multiInformer{r.informer, ctxInformer}
+	}
+
 	for _, validation := range r.validations {
-		err := validation.Validate(ctx, r.informer, copyObj)
+		err := validation.Validate(ctx, informer, copyObj)
 		if err != nil {
 			unwrappedErrs := Unwrap(err)
 			for _, e := range unwrappedErrs {
@@ -165,6 +165,28 @@ func TestRunnerWithSkipValidations(t *testing.T) {
 	g.Expect(r.Sequentially(ctx, config)).To(Succeed())
 }
 
+func TestRunnerSequentiallyReportsToContextInformer(t *testing.T) {
+	g := NewWithT(t)
+	recorder := validation.NewStatusRecorder()
+	ctx := validation.WithInformer(context.Background(), recorder)
+	r := validation.NewRunner[*nodeConfig](validation.NewPrinter())
+	r.Register(
+		validation.New("max-pods", func(ctx context.Context, informer validation.Informer, config *nodeConfig) error {
+			informer.Starting(ctx, "max-pods", "Validating max pods")
+			err := errors.New("maxPods can't be 0")
+			informer.Done(ctx, "max-pods", err)
+			return err
+		}),
+	)
+
+	g.Expect(r.Sequentially(ctx, &nodeConfig{})).To(MatchError("maxPods can't be 0"))
+	status := recorder.Status()
+	g.Expect(status.Healthy).To(BeFalse())
+	g.Expect(status.Checks).To(HaveLen(1))
+	g.Expect(status.Checks[0].Name).To(Equal("max-pods"))
+	g.Expect(status.Checks[0].Message).To(Equal("Validating max pods"))
+}
+
 type nodeConfig struct {
 	maxPods int
 	name    string
@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	CheckPassed  = "passed"
+	CheckWarning = "warning"
+	CheckFailed  = "failed"
+)
+
+// CheckStatus is the result of a single validation.
+type CheckStatus struct {
+	Name        string    `json:"name"`
+	Message     string    `json:"message,omitempty"`
+	Status      string    `json:"status"`
+	Errors      []string  `json:"errors,omitempty"`
+	Remediation []string  `json:"remediation,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// Status is the result of a run of validations.
+type Status struct {
+	// Healthy is true when no validation failed. Warnings don't make the status unhealthy.
+	Healthy bool          `json:"healthy"`
+	Time    time.Time     `json:"time"`
+	Checks  []CheckStatus `json:"checks"`
+}
+
+// StatusRecorder is an informer that records the result of each validation
+// so they can be reported as a Status.
+type StatusRecorder struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	messages map[string]string
+	checks   []CheckStatus
+}
+
+var _ Informer = (*StatusRecorder)(nil)
+
+// NewStatusRecorder creates a new StatusRecorder.
+func NewStatusRecorder() *StatusRecorder {
+	return &StatusRecorder{
+		now:      time.Now,
+		messages: map[string]string{},
+	}
+}
+
+// Starting records the message of a validation to report it with its result.
+func (r *StatusRecorder) Starting(ctx context.Context, name, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages[name] = message
+}
+
+// Done records the result of a validation.
+func (r *StatusRecorder) Done(ctx context.Context, name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	check := CheckStatus{
+		Name:    name,
+		Message: r.messages[name],
+		Status:  CheckPassed,
+		Time:    r.now(),
+	}
+	if err != nil {
+		check.Status = CheckWarning
+		for _, e := range Unwrap(err) {
+			if !IsWarning(e) {
+				check.Status = CheckFailed
+			}
+			check.Errors = append(check.Errors, e.Error())
+			if remediation := Remediation(e); remediation != "" {
+				check.Remediation = append(check.Remediation, remediation)
+			}
+		}
+	}
+	r.checks = append(r.checks, check)
+}
+
+// Status returns the results recorded so far.
+func (r *StatusRecorder) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := Status{
+		Healthy: true,
+		Time:    r.now(),
+		Checks:  append([]CheckStatus(nil), r.checks...),
+	}
+	for _, check := range r.checks {
+		if check.Status == CheckFailed {
+			status.Healthy = false
+		}
+	}
+	return status
+}
@@ -0,0 +1,44 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/aws/eks-hybrid/internal/validation"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	recorder := validation.NewStatusRecorder()
+
+	recorder.Starting(ctx, "passing", "Validating passing")
+	recorder.Done(ctx, "passing", nil)
+	recorder.Starting(ctx, "warning", "Validating warning")
+	recorder.Done(ctx, "warning", validation.WithWarning(errors.New("clock drifting"), "Check NTP"))
+
+	status := recorder.Status()
+	g.Expect(status.Healthy).To(BeTrue())
+	g.Expect(status.Checks).To(HaveLen(2))
+	g.Expect(status.Checks[0].Name).To(Equal("passing"))
+	g.Expect(status.Checks[0].Message).To(Equal("Validating passing"))
+	g.Expect(status.Checks[0].Status).To(Equal(validation.CheckPassed))
+	g.Expect(status.Checks[1].Status).To(Equal(validation.CheckWarning))
+	g.Expect(status.Checks[1].Errors).To(ConsistOf("clock drifting"))
+	g.Expect(status.Checks[1].Remediation).To(ConsistOf("Check NTP"))
+
+	recorder.Starting(ctx, "failing", "Validating failing")
+	recorder.Done(ctx, "failing", errors.Join(
+		validation.WithRemediation(errors.New("node not ready"), "Check the CNI"),
+		validation.WithWarning(errors.New("cert expires soon"), "Rotate the cert"),
+	))
+
+	status = recorder.Status()
+	g.Expect(status.Healthy).To(BeFalse())
+	g.Expect(status.Checks[2].Status).To(Equal(validation.CheckFailed))
+	g.Expect(status.Checks[2].Errors).To(ConsistOf("node not ready", "cert expires soon"))
+	g.Expect(status.Checks[2].Remediation).To(ConsistOf("Check the CNI", "Rotate the cert"))
+}
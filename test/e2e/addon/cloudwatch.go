@@ -21,6 +21,9 @@ import (
 type CloudWatchAddon struct {
 	Addon
 	PodIdentityRoleArn string
+
+	// completedSteps are the setup steps that succeeded, skipped when SetupCwAddon runs again.
+	completedSteps map[string]bool
 }
 
 const (
@@ -31,6 +34,14 @@ const (
 	cloudwatchCheckInterval    = 15 * time.Second
 )
 
+// CloudWatch addon setup steps, in the order they run.
+const (
+	cloudwatchStepCleanupLogGroups = "cleanup-log-groups"
+	cloudwatchStepCreate           = "create-addon"
+	cloudwatchStepWaitActive       = "wait-active"
+	cloudwatchStepWaitComponents   = "wait-components"
+)
+
 // NewCloudWatchAddon creates a new CloudWatch Observability addon instance
 func NewCloudWatchAddon(cluster, roleArn string) CloudWatchAddon {
 	addon := Addon{
@@ -51,6 +62,7 @@ func NewCloudWatchAddon(cluster, roleArn string) CloudWatchAddon {
 	return CloudWatchAddon{
 		Addon:              addon,
 		PodIdentityRoleArn: roleArn,
+		completedSteps:     map[string]bool{},
 	}
 }
 
@@ -177,37 +189,109 @@ func latestAddonVersion(ctx context.Context, eksClient *eks.Client, addonName st
 	return *out.Addons[0].AddonVersions[0].AddonVersion, nil
 }
 
-// SetupCwAddon handles CloudWatch addon installation and setup
+// SetupCwAddon handles CloudWatch addon installation and setup. The steps that succeed are recorded
+// on the addon, so when a step fails, calling SetupCwAddon again resumes from the failed step instead
+// of repeating the ones already done. The returned error is a *SetupStepsError when a step fails.
 func (cw *CloudWatchAddon) SetupCwAddon(ctx context.Context, eksClient *eks.Client, k8sClient clientgo.Interface, cwLogsClient *cloudwatchlogs.Client, logger logr.Logger) error {
 	logger.Info("Setting up CloudWatch addon for mixed mode", "cluster", cw.Cluster)
 
-	// Use the latest available version so Pod Identity associations are more likely to be supported.
-	latestVersion, err := latestAddonVersion(ctx, eksClient, cw.Name)
-	if err != nil {
-		logger.Info("Could not determine latest addon version, using EKS default", "error", err.Error())
-	} else {
-		logger.Info("Using latest addon version", "version", latestVersion)
-		cw.Version = latestVersion
+	steps := []setupStep{
+		{
+			name: cloudwatchStepCleanupLogGroups,
+			run: func(ctx context.Context) error {
+				// Clean up existing log groups for fresh test environment
+				if err := cw.cleanupLogGroups(ctx, cwLogsClient, logger); err != nil {
+					logger.Info("Failed to cleanup old log groups - continuing", "error", err.Error())
+				}
+				return nil
+			},
+		},
+		{
+			name: cloudwatchStepCreate,
+			run: func(ctx context.Context) error {
+				// Use the latest available version so Pod Identity associations are more likely to be supported.
+				latestVersion, err := latestAddonVersion(ctx, eksClient, cw.Name)
+				if err != nil {
+					logger.Info("Could not determine latest addon version, using EKS default", "error", err.Error())
+				} else {
+					logger.Info("Using latest addon version", "version", latestVersion)
+					cw.Version = latestVersion
+				}
+				if err := cw.Create(ctx, eksClient, logger); err != nil {
+					return fmt.Errorf("creating CloudWatch addon: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: cloudwatchStepWaitActive,
+			run: func(ctx context.Context) error {
+				if err := cw.WaitUntilActive(ctx, eksClient, logger); err != nil {
+					return fmt.Errorf("waiting for addon to become active: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: cloudwatchStepWaitComponents,
+			run: func(ctx context.Context) error {
+				if err := cw.WaitForComponents(ctx, k8sClient, logger); err != nil {
+					return fmt.Errorf("waiting for CloudWatch components: %w", err)
+				}
+				return nil
+			},
+		},
 	}
 
-	// Clean up existing log groups for fresh test environment
-	if err := cw.cleanupLogGroups(ctx, cwLogsClient, logger); err != nil {
-		logger.Info("Failed to cleanup old log groups - continuing", "error", err.Error())
+	if cw.completedSteps == nil {
+		cw.completedSteps = map[string]bool{}
 	}
-
-	if err := cw.Create(ctx, eksClient, logger); err != nil {
-		return fmt.Errorf("creating CloudWatch addon: %w", err)
+	if err := runSetupSteps(ctx, logger, cw.completedSteps, steps); err != nil {
+		return err
 	}
 
-	if err := cw.WaitUntilActive(ctx, eksClient, logger); err != nil {
-		return fmt.Errorf("waiting for addon to become active: %w", err)
-	}
+	logger.Info("CloudWatch addon setup completed successfully")
+	return nil
+}
 
-	if err := cw.WaitForComponents(ctx, k8sClient, logger); err != nil {
-		return fmt.Errorf("waiting for CloudWatch components: %w", err)
-	}
+// SetupStepsError reports which addon setup steps succeeded and which one failed.
+type SetupStepsError struct {
+	// Succeeded are the steps completed so far, including those completed by previous runs.
+	Succeeded []string
+	Failed    string
+	Err       error
+}
 
-	logger.Info("CloudWatch addon setup completed successfully")
+func (e *SetupStepsError) Error() string {
+	return fmt.Sprintf("setup step %s failed (succeeded: [%s]): %v", e.Failed, strings.Join(e.Succeeded, ", "), e.Err)
+}
+
+func (e *SetupStepsError) Unwrap() error {
+	return e.Err
+}
+
+// setupStep is a step of an addon setup. Steps must be safe to run again after they fail.
+type setupStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runSetupSteps runs the steps in order, skipping the ones already in completed and recording
+// the ones that succeed. It stops at the first step that fails.
+func runSetupSteps(ctx context.Context, logger logr.Logger, completed map[string]bool, steps []setupStep) error {
+	var succeeded []string
+	for _, step := range steps {
+		if completed[step.name] {
+			logger.Info("Skipping addon setup step completed by a previous run", "step", step.name)
+			succeeded = append(succeeded, step.name)
+			continue
+		}
+		if err := step.run(ctx); err != nil {
+			return &SetupStepsError{Succeeded: succeeded, Failed: step.name, Err: err}
+		}
+		completed[step.name] = true
+		succeeded = append(succeeded, step.name)
+	}
 	return nil
 }
 
@@ -0,0 +1,70 @@
+package addon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+)
+
+// recordingSteps returns steps named after names that record their runs and fail once
+// when named failOnce.
+func recordingSteps(names []string, failOnce string, runs *[]string) []setupStep {
+	failed := false
+	steps := make([]setupStep, 0, len(names))
+	for _, name := range names {
+		steps = append(steps, setupStep{
+			name: name,
+			run: func(context.Context) error {
+				*runs = append(*runs, name)
+				if name == failOnce && !failed {
+					failed = true
+					return errors.New("throttled")
+				}
+				return nil
+			},
+		})
+	}
+	return steps
+}
+
+func TestRunSetupStepsResumesFromFailedStep(t *testing.T) {
+	names := []string{cloudwatchStepCleanupLogGroups, cloudwatchStepCreate, cloudwatchStepWaitActive, cloudwatchStepWaitComponents}
+
+	for i, failing := range names {
+		t.Run(failing, func(t *testing.T) {
+			g := NewWithT(t)
+			var runs []string
+			completed := map[string]bool{}
+			steps := recordingSteps(names, failing, &runs)
+
+			err := runSetupSteps(context.Background(), logr.Discard(), completed, steps)
+
+			var stepsErr *SetupStepsError
+			g.Expect(errors.As(err, &stepsErr)).To(BeTrue())
+			g.Expect(stepsErr.Failed).To(Equal(failing))
+			g.Expect(stepsErr.Succeeded).To(HaveExactElements(names[:i]))
+			g.Expect(err).To(MatchError(ContainSubstring("throttled")))
+			g.Expect(runs).To(Equal(names[:i+1]))
+
+			runs = nil
+			g.Expect(runSetupSteps(context.Background(), logr.Discard(), completed, steps)).To(Succeed())
+			g.Expect(runs).To(Equal(names[i:]), "the completed steps shouldn't run again")
+			g.Expect(completed).To(HaveLen(len(names)))
+		})
+	}
+}
+
+func TestRunSetupStepsAllCompleted(t *testing.T) {
+	g := NewWithT(t)
+	names := []string{cloudwatchStepCreate, cloudwatchStepWaitActive}
+	var runs []string
+	completed := map[string]bool{cloudwatchStepCreate: true, cloudwatchStepWaitActive: true}
+
+	err := runSetupSteps(context.Background(), logr.Discard(), completed, recordingSteps(names, "", &runs))
+
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(runs).To(BeEmpty())
+}